@@ -0,0 +1,113 @@
+// cmd/audit.go
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	mathrand "math/rand/v2"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func (c *AuditSampleCmd) Run(ctx *Context) error {
+	if c.N < 1 {
+		return invalidf("--n must be at least 1")
+	}
+
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
+	if err != nil {
+		return lookupError("poll", err)
+	}
+
+	votes, err := ctx.Queries.ListVotesByCategory(context.Background(), cat.ID)
+	if err != nil {
+		return dbError(err)
+	}
+	// Invalidated ballots aren't counted, so there's nothing to confirm
+	votes = slices.DeleteFunc(votes, func(v db.Vote) bool { return v.Invalid != "" })
+	if len(votes) == 0 {
+		return invalidf("%s has no ballots to sample", cat.Name)
+	}
+
+	options, err := ctx.Queries.ListOptionsByCategory(context.Background(), cat.ID)
+	if err != nil {
+		return dbError(err)
+	}
+	names := make(map[int64]string, len(options))
+	for _, o := range options {
+		names[o.ID] = o.Name
+	}
+
+	// Print the seed so anyone can rerun the same draw and check it
+	seed := c.Seed
+	if seed == "" {
+		seed = rand.Text()[:8]
+	}
+	picks := samplePicks(seed, len(votes), c.N)
+
+	fmt.Printf("Audit sample for: %s (%d of %s)\n", cat.Name, len(picks), tally.Plural(int64(len(votes)), "ballot"))
+	fmt.Printf("Seed: %s\n\n", seed)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "#\tRECEIPT\tCHOICES"
+	if c.Names {
+		header += "\tVOTER"
+	}
+	fmt.Fprintln(w, header)
+
+	// One query for every ballot's choices rather than one per pick
+	rows, err := ctx.Queries.ListSelectionsByCategory(context.Background(), cat.ID)
+	if err != nil {
+		return dbError(err)
+	}
+	byVote := make(map[int64][]db.ListSelectionsByCategoryRow)
+	for _, row := range rows {
+		byVote[row.VoteID] = append(byVote[row.VoteID], row)
+	}
+
+	for i, pick := range picks {
+		vote := votes[pick]
+		selections := byVote[vote.ID]
+		receipt := db.Receipt(vote, selections)
+
+		choices := make([]string, len(selections))
+		for j, sel := range selections {
+			choices[j] = names[sel.OptionID]
+			if sel.Rank.Valid {
+				choices[j] = fmt.Sprintf("%d. %s", sel.Rank.Int64, names[sel.OptionID])
+			}
+		}
+
+		row := fmt.Sprintf("%d\t%s\t%s", i+1, receipt, strings.Join(choices, ", "))
+		if c.Names {
+			row += "\t" + ctx.Nicknames.Reveal(vote.Nickname)
+		}
+		fmt.Fprintln(w, row)
+	}
+	return w.Flush()
+}
+
+func (c *AuditSampleCmd) Help() string {
+	return `Voters see a receipt code after voting. Ask the voters behind the sampled
+receipts to confirm the choices listed are the ones they made. Anyone with
+the database can rerun the command with the printed seed to check the draw.
+
+Examples:
+  votigo audit sample 1
+  votigo audit sample 1 --n 5 --seed finals-2025
+  votigo audit sample 1 --names`
+}
+
+// samplePicks chooses up to n of total indexes, deterministically for seed
+func samplePicks(seed string, total, n int) []int {
+	key := sha256.Sum256([]byte(seed))
+	r := mathrand.New(mathrand.NewChaCha8(key))
+	return r.Perm(total)[:min(n, total)]
+}
@@ -0,0 +1,173 @@
+// cmd/completion.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// Completion scripts delegate to the hidden __complete command, so
+// suggestions always match the binary's flags and the polls in the database.
+var completionScripts = map[string]string{
+	"bash": `_%[1]s() {
+    local IFS=$'\n'
+    COMPREPLY=($(%[1]s __complete -- "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null | cut -f1))
+}
+complete -o default -F _%[1]s %[1]s
+`,
+	"zsh": `#compdef %[1]s
+_%[1]s() {
+    local -a items
+    local line
+    for line in "${(@f)$(%[1]s __complete -- "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -n $line ]] && items+=("${${line%%%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+    done
+    _describe '%[1]s' items
+}
+compdef _%[1]s %[1]s
+`,
+	"fish": `function __%[1]s_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    %[1]s __complete -- $tokens[2..-1] 2>/dev/null
+end
+complete -c %[1]s -f -a '(__%[1]s_complete)'
+`,
+}
+
+func (c *CompletionCmd) skipsDatabase() {}
+func (c *CompleteCmd) skipsDatabase()   {}
+
+func (c *CompletionCmd) Run(kctx *kong.Context) error {
+	fmt.Printf(completionScripts[c.Shell], kctx.Model.Name)
+	return nil
+}
+
+func (c *CompletionCmd) Help() string {
+	return `Examples:
+  source <(votigo completion bash)
+  votigo completion zsh > "${fpath[1]}/_votigo"
+  votigo completion fish > ~/.config/fish/completions/votigo.fish`
+}
+
+// categoryArgs are positional arguments completed with poll IDs
+var categoryArgs = map[string]bool{"poll": true}
+
+// Run prints one candidate per line for the last word, optionally followed
+// by a tab and a description. Words are everything typed after the program
+// name; the last is the (possibly empty) word being completed.
+func (c *CompleteCmd) Run(cli *CLI, kctx *kong.Context) error {
+	words := c.Words
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+	dbPath := cli.DB
+
+	node := kctx.Model.Node
+	positional := 0
+	for i := 0; i < len(words)-1; i++ {
+		w := words[i]
+		if strings.HasPrefix(w, "-") {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(w, "-"), "=")
+			flag := findFlag(node, name)
+			if flag == nil || flag.IsBool() || hasValue {
+				if flag != nil && flag.Name == "db" {
+					dbPath = value
+				}
+				continue
+			}
+			// The flag's value is the next word
+			if i+1 < len(words)-1 {
+				i++
+				if flag.Name == "db" {
+					dbPath = words[i]
+				}
+			}
+			continue
+		}
+		if child := findCommand(node, w); child != nil {
+			node, positional = child, 0
+			continue
+		}
+		positional++
+	}
+
+	out := func(candidate, description string) {
+		if strings.HasPrefix(candidate, current) {
+			fmt.Printf("%s\t%s\n", candidate, description)
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		for n := node; n != nil; n = n.Parent {
+			for _, f := range n.Flags {
+				if !f.Hidden {
+					out("--"+f.Name, f.Help)
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, child := range node.Children {
+		if !child.Hidden {
+			out(child.Name, child.Help)
+		}
+	}
+
+	if positional < len(node.Positional) && categoryArgs[node.Positional[positional].Name] {
+		for _, cat := range listCategoriesForCompletion(dbPath) {
+			out(strconv.FormatInt(cat.ID, 10), fmt.Sprintf("%s (%s)", cat.Name, cat.Status))
+		}
+	}
+	return nil
+}
+
+// listCategoriesForCompletion reads polls without creating or migrating the
+// database; completion must never leave a votigo.db behind.
+func listCategoriesForCompletion(path string) []db.Category {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	conn, err := db.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	categories, _ := db.New(conn).ListCategories(context.Background())
+	return categories
+}
+
+// findFlag looks up a long flag by name on node or any of its parents
+func findFlag(node *kong.Node, name string) *kong.Flag {
+	for n := node; n != nil; n = n.Parent {
+		for _, f := range n.Flags {
+			if f.Name == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// findCommand returns the child command called name, matching aliases too
+func findCommand(node *kong.Node, name string) *kong.Node {
+	for _, child := range node.Children {
+		if child.Name == name {
+			return child
+		}
+		for _, alias := range child.Aliases {
+			if alias == name {
+				return child
+			}
+		}
+	}
+	return nil
+}
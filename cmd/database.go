@@ -0,0 +1,129 @@
+// cmd/database.go
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func (c *DatabaseCheckCmd) Run(ctx *Context) error {
+	problems, err := db.Check(context.Background(), ctx.DB)
+	if err != nil {
+		return dbError(err)
+	}
+	if len(problems) == 0 {
+		ctx.say("No problems found\n")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROBLEM\tFIX")
+	for _, p := range problems {
+		fix := p.Fix
+		if fix == "" {
+			fix = "none (restore a backup)"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", p, fix)
+	}
+	w.Flush()
+
+	if !c.Repair {
+		return &ExitError{Code: ExitProblems, Err: fmt.Errorf("found %s; run with --repair to fix them", tally.Plural(int64(len(problems)), "problem"))}
+	}
+	fixed, err := db.Repair(context.Background(), ctx.DB, problems, db.ActorCLI)
+	if err != nil {
+		return dbError(err)
+	}
+	if left := len(problems) - fixed; left > 0 {
+		return &ExitError{Code: ExitProblems, Err: fmt.Errorf("fixed %d, but %s can't be repaired", fixed, tally.Plural(int64(left), "problem"))}
+	}
+	ctx.say("\nFixed %s\n", tally.Plural(int64(fixed), "problem"))
+	return nil
+}
+
+func (c *DatabaseCheckCmd) Help() string {
+	return `Checks the database file for damage, rows that refer to a poll, vote or
+option that no longer exists, and ballot choices voting would have refused
+(an option from another poll, or a rank past the poll's max rank). Exits
+with 5 if it finds any. --repair deletes or clears the broken rows in one
+transaction; damage to the file itself needs a backup.
+
+Examples:
+  votigo db check
+  votigo db check --repair
+  votigo --db backups/votigo-2024-06-01.db db check`
+}
+
+func (c *DatabaseExportCmd) Run(ctx *Context) error {
+	archive, err := db.ExportArchive(context.Background(), ctx.DB)
+	if err != nil {
+		return dbError(err)
+	}
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if c.File == "" || c.File == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(c.File, data, 0o600); err != nil {
+		return err
+	}
+	ctx.say("Exported %s and %s to %s\n",
+		tally.Plural(int64(len(archive.Tables["categories"])), "poll"), tally.Plural(int64(len(archive.Tables["votes"])), "ballot"), c.File)
+	return nil
+}
+
+func (c *DatabaseExportCmd) Help() string {
+	return `Writes the whole database as JSON: polls, options, ballots and their
+history, the audit log and settings. Nicknames are written as stored, so an
+encrypted database's archive needs the same --db-key to read.
+
+Examples:
+  votigo db export votigo-2024.json
+  votigo db export | gzip > votigo-2024.json.gz`
+}
+
+func (c *DatabaseImportCmd) Run(ctx *Context) error {
+	var data []byte
+	var err error
+	if c.File == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(c.File)
+	}
+	if err != nil {
+		return err
+	}
+	archive, err := db.ReadArchive(data)
+	if err != nil {
+		return invalid(err)
+	}
+
+	if err := db.ImportArchive(context.Background(), ctx.DB, archive); err != nil {
+		return dbError(err)
+	}
+	ctx.say("Imported %s and %s\n",
+		tally.Plural(int64(len(archive.Tables["categories"])), "poll"), tally.Plural(int64(len(archive.Tables["votes"])), "ballot"))
+	return nil
+}
+
+func (c *DatabaseImportCmd) Help() string {
+	return `Loads an archive written by db export into a database with no polls or
+ballots yet, keeping IDs so voter links and receipts still work. Archives
+from older versions of votigo are brought up to date on the way in.
+
+Examples:
+  votigo --db fresh.db db import votigo-2024.json
+  gunzip -c votigo-2024.json.gz | votigo --db fresh.db db import -`
+}
@@ -0,0 +1,186 @@
+// cmd/demo.go
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	mathrand "math/rand/v2"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/web"
+)
+
+// demoDSN names a shared in-memory database. Every connection in the pool
+// sees the same one, unlike ":memory:", and it vanishes with the last.
+const demoDSN = "file:/votigo-demo?vfs=memdb"
+
+// demoPoll is a poll `votigo demo` creates, with its options in order of
+// popularity
+type demoPoll struct {
+	Settings web.CategorySettings
+	Options  []string
+	Voters   int
+	Status   string // draft, open or closed
+}
+
+var demoPolls = []demoPoll{
+	{
+		Settings: web.CategorySettings{Name: "Best Game of the Night", VoteType: "single", ShowResults: "live", Color: "green", Icon: "🕹️"},
+		Options:  []string{"Street Fighter II", "Pac-Man", "Galaga", "Donkey Kong", "Tetris"},
+		Voters:   34,
+		Status:   "open",
+	},
+	{
+		Settings: web.CategorySettings{Name: "Top 3 Maps", VoteType: "ranked", MaxRank: 3, ShowResults: "live", Color: "blue"},
+		Options:  []string{"de_dust2", "Facing Worlds", "Blood Gulch", "The Facility", "Q3DM17"},
+		Voters:   27,
+		Status:   "open",
+	},
+	{
+		Settings: web.CategorySettings{Name: "Snacks for Next Time", VoteType: "approval", ShowResults: "after_close", Color: "amber", Icon: "🍕"},
+		Options:  []string{"Pizza", "Nachos", "Energy drinks", "Fruit", "Pretzels"},
+		Voters:   41,
+		Status:   "closed",
+	},
+	{
+		Settings: web.CategorySettings{Name: "Best Cosplay", VoteType: "single", ShowResults: "after_close", Color: "pink"},
+		Options:  []string{"Samus", "Link", "Chun-Li", "Master Chief"},
+		Status:   "draft",
+	},
+}
+
+func (c *DemoCmd) skipsDatabase() {}
+
+func (c *DemoCmd) Run(ctx *Context) error {
+	db.QuietMigrations()
+	conn, err := db.Open(demoDSN)
+	if err != nil {
+		return dbError(err)
+	}
+	defer conn.Close()
+
+	// Hold one connection for as long as we serve, so the pool closing idle
+	// ones never drops the database
+	keep, err := conn.Conn(context.Background())
+	if err != nil {
+		return dbError(err)
+	}
+	defer keep.Close()
+
+	if err := db.Migrate(conn); err != nil {
+		return dbError(err)
+	}
+	if err := seedDemo(context.Background(), conn); err != nil {
+		return dbError(err)
+	}
+
+	password := rand.Text()[:12]
+	server, err := web.NewServer(conn, password, web.UIMode(c.UI))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Demo event at http://localhost:%d%s\n", c.Port, web.HomeURL())
+	fmt.Printf("Admin at http://localhost:%d/admin, user admin, password %s\n", c.Port, password)
+	fmt.Println("Nothing is saved: everything is gone when you press Ctrl-C.")
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return server.Start(sigCtx, c.Port)
+}
+
+func (c *DemoCmd) Help() string {
+	return `Serves a throwaway event from memory: a few polls of each vote type, open,
+closed and still in draft, with made-up votes already cast. The admin
+password is random and printed at startup. Nothing touches --db.
+
+Examples:
+  votigo demo
+  votigo demo --port 8080 --ui legacy`
+}
+
+// seedDemo creates demoPolls with votes from made-up players. The votes are
+// the same every run; favourites win, but not by a landslide.
+func seedDemo(ctx context.Context, conn *sql.DB) error {
+	rng := mathrand.New(mathrand.NewChaCha8([32]byte{'v', 'o', 't', 'i', 'g', 'o'}))
+	q := db.New(conn)
+
+	for _, p := range demoPolls {
+		settings := p.Settings
+		if err := settings.Normalize(); err != nil {
+			return err
+		}
+		if err := settings.AssignSlug(ctx, q, 0); err != nil {
+			return err
+		}
+		cat, err := q.CreateCategory(ctx, settings.CreateParams())
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int64, len(p.Options))
+		weights := make([]float64, len(p.Options))
+		for i, name := range p.Options {
+			opt, err := q.CreateOption(ctx, db.CreateOptionParams{
+				CategoryID: cat.ID,
+				Name:       name,
+				SortOrder:  sql.NullInt64{Int64: int64(i), Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+			ids[i] = opt.ID
+			weights[i] = 1 / float64(i+1)
+		}
+
+		if p.Status == "draft" {
+			continue
+		}
+		if _, err := db.OpenCategory(ctx, conn, cat.ID, db.ActorCLI); err != nil {
+			return err
+		}
+		err = db.InTx(ctx, conn, func(q *db.Queries) error {
+			for v := range p.Voters {
+				vote, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: fmt.Sprintf("player%02d", v+1)})
+				if err != nil {
+					return err
+				}
+				ranking := drawRanking(rng, weights)
+				var picks []int64
+				switch settings.VoteType {
+				case "single":
+					picks = ranking[:1]
+				case "approval":
+					picks = ranking[:1+rng.IntN(3)]
+				case "ranked":
+					picks = ranking[:settings.MaxRank]
+				}
+				for i, pick := range picks {
+					var rank sql.NullInt64
+					if settings.VoteType == "ranked" {
+						rank = sql.NullInt64{Int64: int64(i + 1), Valid: true}
+					}
+					err := q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: ids[pick-1], Rank: rank})
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if p.Status == "closed" {
+			if _, err := db.CloseCategory(ctx, conn, cat.ID, db.ActorCLI); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
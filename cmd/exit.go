@@ -0,0 +1,85 @@
+// cmd/exit.go
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// Exit codes let scripts tell failures apart without parsing messages.
+// Command-line usage errors exit with 80, as reported by kong.
+const (
+	ExitOK         = 0
+	ExitFailure    = 1 // anything not covered below
+	ExitNotFound   = 2 // the poll, option or voter does not exist
+	ExitValidation = 3 // the request was understood but not allowed
+	ExitDatabase   = 4 // the database could not be opened, migrated or written
+	ExitProblems   = 5 // db check found problems in the database, or lint misconfiguration
+)
+
+// ExitError attaches an exit code to an error. It implements kong's
+// ExitCoder, so FatalIfErrorf exits with Code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+func (e *ExitError) Unwrap() error { return e.Err }
+func (e *ExitError) ExitCode() int { return e.Code }
+
+// notFound reports a missing poll, option or voter
+func notFound(format string, args ...any) error {
+	return &ExitError{Code: ExitNotFound, Err: fmt.Errorf(format, args...)}
+}
+
+// invalid reports a request that breaks a rule, such as opening a poll with
+// no options
+func invalid(err error) error {
+	return &ExitError{Code: ExitValidation, Err: err}
+}
+
+// invalidf is invalid with a formatted message
+func invalidf(format string, args ...any) error {
+	return invalid(fmt.Errorf(format, args...))
+}
+
+// dbError reports a failure talking to the database. Missing rows are
+// reported as not found, and conflicts or a poll that isn't open as
+// validation errors, so callers can wrap queries without classifying their
+// errors themselves.
+func dbError(err error) error {
+	if err == nil {
+		return nil
+	}
+	err = db.Classify(err)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return &ExitError{Code: ExitNotFound, Err: err}
+	case errors.Is(err, db.ErrConflict), errors.Is(err, db.ErrClosed):
+		return &ExitError{Code: ExitValidation, Err: err}
+	}
+	return &ExitError{Code: ExitDatabase, Err: err}
+}
+
+// lookupError reports a failed lookup of a poll or option: not found if it
+// doesn't exist, otherwise a database failure
+func lookupError(what string, err error) error {
+	if errors.Is(err, db.ErrNotFound) {
+		return notFound("%s not found: %w", what, err)
+	}
+	return dbError(err)
+}
+
+// CommandError strips kong's usage wrapper from err when a hook failed with
+// an ExitError, so main reports it as a command failure with its own code
+// instead of printing usage and exiting 1
+func CommandError(err error) error {
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		return exit
+	}
+	return err
+}
@@ -3,86 +3,121 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/notify"
+	"github.com/palm-arcade/votigo/internal/tally"
 )
 
 func (c *OpenCmd) Run(ctx *Context) error {
-	// Check poll exists
-	cat, err := ctx.Queries.GetCategory(context.Background(), c.CategoryID)
-	if err != nil {
-		return fmt.Errorf("poll not found: %w", err)
-	}
-
-	// Check has options
-	count, err := ctx.Queries.CountOptionsByCategory(context.Background(), c.CategoryID)
+	cat, err := openPoll(ctx, c.Poll.ID)
 	if err != nil {
 		return err
 	}
-	if count == 0 {
-		return fmt.Errorf("cannot open poll with no options")
-	}
 
-	err = ctx.Queries.UpdateCategoryStatus(context.Background(), db.UpdateCategoryStatusParams{
-		Status: "open",
-		ID:     c.CategoryID,
-	})
+	ctx.say("Opened voting for: %s\n", cat.Name)
+	ctx.announce(cat, notify.EventOpened)
+	return nil
+}
+
+func (c *OpenCmd) Help() string {
+	return `Examples:
+  votigo open 1`
+}
+
+func (c *CloseCmd) Run(ctx *Context) error {
+	cat, seeded, err := closePoll(ctx, c.Poll.ID)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Opened voting for: %s\n", cat.Name)
+	ctx.say("Closed voting for: %s\n", cat.Name)
+	for _, p := range seeded {
+		ctx.say("Seeded %s into: %s\n", tally.Plural(int64(len(p.Options)), "option"), p.Category.Name)
+	}
+	ctx.announce(cat, notify.EventClosed, notify.EventResults)
 	return nil
 }
 
-func (c *CloseCmd) Run(ctx *Context) error {
-	cat, err := ctx.Queries.GetCategory(context.Background(), c.CategoryID)
-	if err != nil {
-		return fmt.Errorf("poll not found: %w", err)
-	}
+func (c *CloseCmd) Help() string {
+	return `Draft polls set to open after this one (poll edit --after) are seeded
+with its top options.
+
+Examples:
+  votigo close 1`
+}
 
-	err = ctx.Queries.UpdateCategoryStatus(context.Background(), db.UpdateCategoryStatusParams{
-		Status: "closed",
-		ID:     c.CategoryID,
-	})
+func (c *ReopenCmd) Run(ctx *Context) error {
+	cat, err := reopenPoll(ctx, c.Poll.ID)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Closed voting for: %s\n", cat.Name)
+	ctx.say("Reopened voting for: %s\n", cat.Name)
+	ctx.announce(cat, notify.EventOpened)
 	return nil
 }
 
-func (c *ReopenCmd) Run(ctx *Context) error {
-	// Check poll exists
-	cat, err := ctx.Queries.GetCategory(context.Background(), c.CategoryID)
-	if err != nil {
-		return fmt.Errorf("poll not found: %w", err)
-	}
+func (c *ReopenCmd) Help() string {
+	return `Examples:
+  votigo reopen 1`
+}
 
-	// Check poll is closed
-	if cat.Status != "closed" {
-		return fmt.Errorf("cannot reopen poll: status is %q (must be closed)", cat.Status)
-	}
+// openPoll opens voting for a poll that has options and records it in the
+// audit log. The open, close and reopen commands share these helpers with
+// the TUI.
+func openPoll(ctx *Context, id int64) (db.Category, error) {
+	cat, err := db.OpenCategory(context.Background(), ctx.DB, id, db.ActorCLI)
+	return cat, transitionError(cat, err)
+}
 
-	// Check has options
-	count, err := ctx.Queries.CountOptionsByCategory(context.Background(), c.CategoryID)
+// closePoll closes voting for a poll and records it in the audit log, then
+// seeds the draft polls that open after it with its top options
+func closePoll(ctx *Context, id int64) (db.Category, []db.SeededPoll, error) {
+	cat, err := db.CloseCategory(context.Background(), ctx.DB, id, db.ActorCLI)
 	if err != nil {
-		return err
-	}
-	if count == 0 {
-		return fmt.Errorf("cannot reopen poll with no options")
+		return cat, nil, transitionError(cat, err)
 	}
 
-	err = ctx.Queries.UpdateCategoryStatus(context.Background(), db.UpdateCategoryStatusParams{
-		Status: "open",
-		ID:     c.CategoryID,
-	})
+	seeded, err := ctx.Queries.SeedDependents(context.Background(), cat)
+	for _, p := range seeded {
+		for _, o := range p.Options {
+			ctx.audit(db.AuditOptionSeed, p.Category.ID, fmt.Sprintf("%s (from %s)", o.Name, cat.Name))
+		}
+	}
 	if err != nil {
-		return err
+		return cat, seeded, dbError(fmt.Errorf("closed %s, but seeding the polls that open after it failed: %w", cat.Name, err))
 	}
+	return cat, seeded, nil
+}
 
-	fmt.Printf("Reopened voting for: %s\n", cat.Name)
-	return nil
+// reopenPoll opens voting again for a closed poll and records it in the
+// audit log
+func reopenPoll(ctx *Context, id int64) (db.Category, error) {
+	cat, err := db.ReopenCategory(context.Background(), ctx.DB, id, db.ActorCLI)
+	return cat, transitionError(cat, err)
+}
+
+// transitionError reports why cat couldn't be opened, closed or reopened
+func transitionError(cat db.Category, err error) error {
+	var waiting *db.WaitingError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, db.ErrNotFound):
+		return lookupError("poll", err)
+	case errors.Is(err, db.ErrNoOptions):
+		return invalidf("cannot open poll with no options")
+	case errors.Is(err, db.ErrStarted):
+		return invalidf("cannot open poll: status is %q (must be draft)", cat.Status)
+	case errors.Is(err, db.ErrNotOpen):
+		return invalidf("cannot close poll: status is %q (must be open)", cat.Status)
+	case errors.Is(err, db.ErrNotClosed):
+		return invalidf("cannot reopen poll: status is %q (must be closed)", cat.Status)
+	case errors.As(err, &waiting):
+		return invalidf("%s opens after %s (#%d), which is still %s", cat.Name, waiting.Prev.Name, waiting.Prev.ID, waiting.Prev.Status)
+	}
+	return dbError(err)
 }
@@ -0,0 +1,43 @@
+// cmd/lint.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func (c *LintCmd) Run(ctx *Context) error {
+	warnings, err := ctx.Queries.Lint(context.Background(), time.Now())
+	if err != nil {
+		return dbError(err)
+	}
+	if len(warnings) == 0 {
+		ctx.say("No problems found\n")
+		return nil
+	}
+
+	for _, w := range warnings {
+		fmt.Println(w)
+	}
+	return &ExitError{Code: ExitProblems, Err: fmt.Errorf("found %s", tally.Plural(int64(len(warnings)), "problem"))}
+}
+
+func (c *LintCmd) Help() string {
+	return `Runs the checks behind the admin dashboard's warnings over every poll that
+isn't archived and every stored setting, printing one line per problem.
+Exits with 5 if it finds any, so a pre-event checklist script can stop on
+them.
+
+Polls are flagged when they are open with fewer than two options, ranked
+with a max rank above their option count, past their planned closing time
+while still open or a draft, or open and closing within 15 minutes without a
+vote. Settings are flagged when their stored value isn't valid for their
+type.
+
+Examples:
+  votigo lint
+  votigo lint || exit 1`
+}
@@ -2,50 +2,86 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/oembed"
+	"github.com/palm-arcade/votigo/internal/plugin"
+	"github.com/palm-arcade/votigo/internal/tally"
+	"github.com/palm-arcade/votigo/internal/web"
 )
 
 func (c *OptionAddCmd) Run(ctx *Context) error {
+	if !web.ValidImageURL(c.Image) {
+		return invalidf("image must be an uploaded image or an http(s) URL")
+	}
+
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		return invalidf("option name is empty")
+	}
+
 	// Verify poll exists
-	cat, err := ctx.Queries.GetCategory(context.Background(), c.CategoryID)
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
 	if err != nil {
-		return fmt.Errorf("poll not found: %w", err)
+		return lookupError("poll", err)
 	}
 
 	// Get current count for sort_order
-	count, err := ctx.Queries.CountOptionsByCategory(context.Background(), c.CategoryID)
+	count, err := ctx.Queries.CountOptionsByCategory(context.Background(), c.Poll.ID)
 	if err != nil {
-		return err
+		return dbError(err)
 	}
 
 	opt, err := ctx.Queries.CreateOption(context.Background(), db.CreateOptionParams{
-		CategoryID: c.CategoryID,
-		Name:       c.Name,
+		CategoryID: c.Poll.ID,
+		Name:       name,
 		SortOrder:  sql.NullInt64{Int64: count, Valid: true},
 	})
-	if err != nil {
-		return err
+	if err := db.Classify(err); errors.Is(err, db.ErrConflict) {
+		return invalidf("%s already has an option named %q", cat.Name, name)
+	} else if err != nil {
+		return dbError(err)
+	}
+	if c.Image != "" {
+		err := ctx.Queries.SetOptionImage(context.Background(), db.SetOptionImageParams{Image: c.Image, ID: opt.ID})
+		if err != nil {
+			return dbError(err)
+		}
 	}
 
+	ctx.audit(db.AuditOptionAdd, cat.ID, opt.Name)
+	if ctx.Quiet {
+		fmt.Println(opt.ID)
+		return nil
+	}
 	fmt.Printf("Added option #%d to %s: %s\n", opt.ID, cat.Name, opt.Name)
 	return nil
 }
 
+func (c *OptionAddCmd) Help() string {
+	return `Examples:
+  votigo option add 1 "Tetris"
+  votigo option add 1 "Street Fighter II"
+  votigo option add 1 "Doom" --image https://example.com/doom.png`
+}
+
 func (c *OptionListCmd) Run(ctx *Context) error {
-	cat, err := ctx.Queries.GetCategory(context.Background(), c.CategoryID)
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
 	if err != nil {
-		return fmt.Errorf("poll not found: %w", err)
+		return lookupError("poll", err)
 	}
 
-	options, err := ctx.Queries.ListOptionsByCategory(context.Background(), c.CategoryID)
+	options, err := ctx.Queries.ListOptionVotesByCategory(context.Background(), c.Poll.ID)
 	if err != nil {
-		return err
+		return dbError(err)
 	}
 
 	fmt.Printf("Options for: %s\n\n", cat.Name)
@@ -56,26 +92,262 @@ func (c *OptionListCmd) Run(ctx *Context) error {
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME")
+	fmt.Fprintln(w, "ID\tNAME\tVOTES\tSTATUS")
 	for _, opt := range options {
-		fmt.Fprintf(w, "%d\t%s\n", opt.ID, opt.Name)
+		status := "active"
+		if opt.RetiredAt.Valid {
+			status = "retired"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", opt.ID, opt.Name, opt.Votes, status)
 	}
 	w.Flush()
 
 	return nil
 }
 
+func (c *OptionListCmd) Help() string {
+	return `Examples:
+  votigo option list 1`
+}
+
 func (c *OptionRemoveCmd) Run(ctx *Context) error {
-	opt, err := ctx.Queries.GetOption(context.Background(), c.OptionID)
+	opt, err := ctx.Queries.Option(context.Background(), c.OptionID)
+	if err != nil {
+		return lookupError("option", err)
+	}
+
+	// Deleting an option deletes the votes for it; retiring keeps them
+	votes, err := ctx.Queries.CountSelectionsByOption(context.Background(), c.OptionID)
 	if err != nil {
-		return fmt.Errorf("option not found: %w", err)
+		return dbError(err)
+	}
+	if votes > 0 && !c.Force {
+		return invalidf("option %q has %d vote(s): use `votigo option retire %d` to hide it from ballots and keep them, or --force to delete them", opt.Name, votes, opt.ID)
 	}
 
 	err = ctx.Queries.DeleteOption(context.Background(), c.OptionID)
+	if err != nil {
+		return dbError(err)
+	}
+
+	detail := opt.Name
+	if votes > 0 {
+		detail = fmt.Sprintf("%s (%d votes)", opt.Name, votes)
+	}
+	ctx.audit(db.AuditOptionRemove, opt.CategoryID, detail)
+	ctx.say("Removed option: %s\n", opt.Name)
+	return nil
+}
+
+func (c *OptionRemoveCmd) Help() string {
+	return `Options that already have votes are only removed with --force, which
+deletes those votes too. Use 'option retire' to keep them instead.
+
+Examples:
+  votigo option list 1    # find the option ID
+  votigo option remove 4
+  votigo option remove 4 --force`
+}
+
+func (c *OptionRetireCmd) Run(ctx *Context) error {
+	opt, err := ctx.Queries.Option(context.Background(), c.OptionID)
+	if err != nil {
+		return lookupError("option", err)
+	}
+	if opt.RetiredAt.Valid {
+		ctx.say("Option already retired: %s\n", opt.Name)
+		return nil
+	}
+
+	if err := ctx.Queries.RetireOption(context.Background(), c.OptionID); err != nil {
+		return dbError(err)
+	}
+
+	ctx.audit(db.AuditOptionRetire, opt.CategoryID, opt.Name)
+	ctx.say("Retired option: %s (hidden from ballots, votes kept)\n", opt.Name)
+	return nil
+}
+
+func (c *OptionRetireCmd) Help() string {
+	return `Examples:
+  votigo option retire 4`
+}
+
+func (c *OptionSeedCmd) Run(ctx *Context) error {
+	if c.Top < 1 {
+		return invalidf("--top must be at least 1")
+	}
+
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
+	if err != nil {
+		return lookupError("poll", err)
+	}
+	source, err := resolvePoll(ctx, c.From, os.Stdin, os.Stderr)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Removed option: %s\n", opt.Name)
+	added, err := ctx.Queries.SeedFromResults(context.Background(), cat, source, c.Top)
+	for _, o := range added {
+		ctx.audit(db.AuditOptionSeed, cat.ID, fmt.Sprintf("%s (from %s)", o.Name, source.Name))
+	}
+	if errors.Is(err, db.ErrSeedTargetNotDraft) || errors.Is(err, db.ErrSeedSourceOpen) {
+		return invalid(err)
+	}
+	if err != nil {
+		return dbError(err)
+	}
+
+	if len(added) == 0 {
+		ctx.say("Nothing to seed: %s already has the top options of %s\n", cat.Name, source.Name)
+		return nil
+	}
+	for _, o := range added {
+		ctx.say("Seeded option #%d into %s: %s\n", o.ID, cat.Name, o.Name)
+	}
+	return nil
+}
+
+func (c *OptionSeedCmd) Help() string {
+	return `Options are ranked as the results page ranks them. Options with no votes,
+retired options and names the poll already has are skipped.
+
+Examples:
+  votigo option seed "Runoff" --from "Best Game" --top 2
+  votigo option seed 5 --from 1`
+}
+
+func (c *OptionImportCmd) Run(ctx *Context) error {
+	src, err := plugin.NewOptionSource(c.Source)
+	if err != nil {
+		return invalid(err)
+	}
+	names, err := src.Options(context.Background())
+	if err != nil {
+		return invalid(fmt.Errorf("failed to read options: %w", err))
+	}
+
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
+	if err != nil {
+		return lookupError("poll", err)
+	}
+	existing, err := ctx.Queries.ListOptionsByCategory(context.Background(), cat.ID)
+	if err != nil {
+		return dbError(err)
+	}
+	have := make(map[string]bool, len(existing))
+	for _, o := range existing {
+		have[strings.ToLower(o.Name)] = true
+	}
+
+	var added []db.Option
+	err = db.InTx(context.Background(), ctx.DB, func(q *db.Queries) error {
+		count := int64(len(existing))
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			if name == "" || have[strings.ToLower(name)] {
+				continue
+			}
+			have[strings.ToLower(name)] = true
+			opt, err := q.CreateOption(context.Background(), db.CreateOptionParams{
+				CategoryID: cat.ID,
+				Name:       name,
+				SortOrder:  sql.NullInt64{Int64: count, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+			if err := q.RecordAudit(context.Background(), db.ActorCLI, db.AuditOptionAdd, cat.ID, opt.Name); err != nil {
+				return err
+			}
+			added = append(added, opt)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return dbError(err)
+	}
+
+	if len(added) == 0 {
+		ctx.say("Nothing to import: %s already has every option\n", cat.Name)
+		return nil
+	}
+	for _, o := range added {
+		ctx.say("Added option #%d to %s: %s\n", o.ID, cat.Name, o.Name)
+	}
 	return nil
 }
+
+func (c *OptionImportCmd) Help() string {
+	return `Adds each option the source lists that the poll doesn't have yet, in the
+source's order, so importing again after the source grows adds only the new
+ones. A text file has one option per line; blank lines and lines starting
+with # are skipped. Plugins built in (see plugins.go) can add other sources.
+
+Examples:
+  votigo option import 1 maps.txt
+  votigo option import "Best Map" file=maps.txt`
+}
+
+func (c *OptionDetailsCmd) Run(ctx *Context) error {
+	opt, err := ctx.Queries.Option(context.Background(), c.OptionID)
+	if err != nil {
+		return lookupError("option", err)
+	}
+
+	details := web.DetailsOf(opt)
+	if c.Clear {
+		details = web.OptionDetails{}
+	}
+	if c.Blurb != "" {
+		// A shell can't easily pass a blank line; accept \n written out
+		details.Blurb = strings.ReplaceAll(c.Blurb, `\n`, "\n")
+	}
+	if c.Video != "" {
+		details.Video = c.Video
+	}
+	if len(c.Screenshot) > 0 {
+		details.Screenshots = c.Screenshot
+	}
+	if err := details.Normalize(); err != nil {
+		return invalid(err)
+	}
+	if c.Preview {
+		err := web.RefreshPreview(context.Background(), ctx.Queries, oembed.New(), details.Video)
+		if errors.Is(err, oembed.ErrUnavailable) {
+			return invalid(err)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: no preview for %s: %v\n", details.Video, err)
+		}
+	}
+
+	if err := ctx.Queries.SetOptionDetails(context.Background(), details.Params(opt.ID)); err != nil {
+		return dbError(err)
+	}
+	ctx.audit(db.AuditOptionDetails, opt.CategoryID, opt.Name)
+	if details.Empty() {
+		ctx.say("Removed the details of %s\n", opt.Name)
+		return nil
+	}
+	ctx.say("Set the details of %s: %s, %s, %s\n", opt.Name,
+		tally.Plural(int64(len([]rune(details.Blurb))), "character"),
+		cmp.Or(details.Video, "no video"),
+		tally.Plural(int64(len(details.Screenshots)), "screenshot"))
+	return nil
+}
+
+func (c *OptionDetailsCmd) Help() string {
+	return `Voters open an option's details from the ballot before choosing. Flags
+given replace what's there; the rest is kept unless --clear is given.
+YouTube and Vimeo videos play in the ballot, with the title and thumbnail
+the video site gives when they're set; other links open in a new tab.
+Without internet the details are still set, just without a preview.
+
+Examples:
+  votigo option details 4 --blurb "A 1993 platformer from Gremlin.\n\nPlay it at table 3."
+  votigo option details 4 --video https://www.youtube.com/watch?v=dQw4w9WgXcQ
+  votigo option details 4 --screenshot https://example.com/zool2-1.png --screenshot /images/zool2-2.png
+  votigo option details 4 --clear`
+}
@@ -4,17 +4,21 @@ package cmd
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/web"
 )
 
 func (c *PollListCmd) Run(ctx *Context) error {
 	categories, err := ctx.Queries.ListCategories(context.Background())
 	if err != nil {
-		return err
+		return dbError(err)
 	}
 
 	if len(categories) == 0 {
@@ -22,33 +26,506 @@ func (c *PollListCmd) Run(ctx *Context) error {
 		return nil
 	}
 
+	stats, err := ctx.Queries.ListCategoryVoteStats(context.Background())
+	if err != nil {
+		return dbError(err)
+	}
+	votes := make(map[int64]int64, len(stats))
+	for _, st := range stats {
+		votes[st.CategoryID] = st.Votes
+	}
+
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tTYPE\tSTATUS")
+	fmt.Fprintln(w, "ID\tNAME\tTYPE\tSTATUS\tVOTES")
 	for _, cat := range categories {
-		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", cat.ID, cat.Name, cat.VoteType, cat.Status)
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\n", cat.ID, cat.Name, cat.VoteType, cat.Status, votes[cat.ID])
 	}
 	w.Flush()
 
 	return nil
 }
 
-func (c *PollCreateCmd) Run(ctx *Context) error {
-	var maxRank sql.NullInt64
-	if c.Type == "ranked" {
-		maxRank = sql.NullInt64{Int64: int64(c.MaxRank), Valid: true}
-	}
+func (c *PollListCmd) Help() string {
+	return `Examples:
+  votigo poll list`
+}
 
-	cat, err := ctx.Queries.CreateCategory(context.Background(), db.CreateCategoryParams{
+func (c *PollCreateCmd) Run(ctx *Context) error {
+	settings := web.CategorySettings{
 		Name:        c.Name,
 		VoteType:    c.Type,
-		Status:      "draft",
 		ShowResults: "after_close",
-		MaxRank:     maxRank,
-	})
+		MaxRank:     int64(c.MaxRank),
+		Color:       c.Color,
+		Icon:        c.Icon,
+		SeedTopN:    c.SeedTop,
+		Slug:        c.Slug,
+		Skin:        c.Skin,
+		RevealSound: c.RevealSound,
+		VotedWall:   c.VotedWall,
+		Eligibility: c.Eligibility,
+		Weights:     c.Weights,
+		Method:      c.Method,
+		Judges:      c.Judges,
+		JudgeWeight: c.JudgeWeight,
+	}
+	if c.CSSFile != "" {
+		css, err := readCSSFile(c.CSSFile)
+		if err != nil {
+			return err
+		}
+		settings.CustomCSS = css
+	}
+	if c.After != "" {
+		prev, err := resolvePoll(ctx, c.After, os.Stdin, os.Stderr)
+		if err != nil {
+			return err
+		}
+		settings.DependsOn = prev.ID
+	}
+	if c.OpensAt != "" {
+		opensAt, err := parsePlannedTime(c.OpensAt, "opening")
+		if err != nil {
+			return invalid(err)
+		}
+		settings.OpensAt = opensAt
+	}
+	if c.ClosesAt != "" {
+		closesAt, err := parsePlannedTime(c.ClosesAt, "closing")
+		if err != nil {
+			return invalid(err)
+		}
+		settings.ClosesAt = closesAt
+	}
+	if err := settings.Normalize(); err != nil {
+		return invalid(err)
+	}
+	if err := settings.CheckDependency(context.Background(), ctx.Queries, 0); err != nil {
+		return invalid(err)
+	}
+	if err := settings.AssignSlug(context.Background(), ctx.Queries, 0); err != nil {
+		return invalid(err)
+	}
+
+	cat, err := ctx.Queries.CreateCategory(context.Background(), settings.CreateParams())
+	if err != nil {
+		return dbError(err)
+	}
+
+	ctx.audit(db.AuditCategoryCreate, cat.ID, cat.Name)
+	if ctx.Quiet {
+		fmt.Println(cat.ID)
+		return nil
+	}
+	fmt.Printf("Created poll #%d: %s (%s) at %s\n", cat.ID, cat.Name, cat.VoteType, web.VoteURL(cat.Ref()))
+	return nil
+}
+
+func (c *PollCreateCmd) Help() string {
+	return `Examples:
+  votigo poll create "Best Game"
+  votigo poll create "Top 3 Maps" --type ranked --max-rank 3
+  votigo poll create "Snacks" --type approval --color amber --icon 🍕
+  votigo poll create "Grand Champion" --after "Best Game" --seed-top 3
+  votigo poll create "Best Cosplay" --opens-at 20:00 --closes-at 21:30
+  votigo poll create "Best Soundtrack" --slug ost
+  votigo poll create "Best Pixel Art" --skin crt --css-file pixel.css
+  votigo poll create "Crowd Favourite" --eligibility "!crew"`
+}
+
+// readCSSFile reads a poll's custom CSS for --css-file
+func readCSSFile(path string) (string, error) {
+	css, err := os.ReadFile(path)
+	if err != nil {
+		return "", invalidf("reading custom CSS: %w", err)
+	}
+	return string(css), nil
+}
+
+func (c *PollEditCmd) Run(ctx *Context) error {
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
+	if err != nil {
+		return lookupError("poll", err)
+	}
+
+	// Start from the current settings so only the given flags change
+	settings := web.SettingsOf(cat)
+	changed := false
+	set := func(field *string, flag *string) {
+		if flag != nil {
+			*field, changed = *flag, true
+		}
+	}
+	set(&settings.Name, c.Name)
+	set(&settings.Slug, c.Slug)
+	set(&settings.VoteType, c.Type)
+	set(&settings.ShowResults, c.ShowResults)
+	set(&settings.Color, c.Color)
+	set(&settings.Icon, c.Icon)
+	set(&settings.Skin, c.Skin)
+	set(&settings.RevealSound, c.RevealSound)
+	set(&settings.VotedWall, c.VotedWall)
+	set(&settings.Eligibility, c.Eligibility)
+	set(&settings.Weights, c.Weights)
+	set(&settings.Method, c.Method)
+	set(&settings.Judges, c.Judges)
+	if c.CSSFile != nil {
+		settings.CustomCSS, changed = "", true
+		if *c.CSSFile != "" {
+			css, err := readCSSFile(*c.CSSFile)
+			if err != nil {
+				return err
+			}
+			settings.CustomCSS = css
+		}
+	}
+	if c.MaxRank != nil {
+		settings.MaxRank, changed = *c.MaxRank, true
+	}
+	if c.After != nil {
+		settings.DependsOn, changed = 0, true
+		if *c.After == "" {
+			settings.SeedTopN = 0
+		} else {
+			prev, err := resolvePoll(ctx, *c.After, os.Stdin, os.Stderr)
+			if err != nil {
+				return err
+			}
+			settings.DependsOn = prev.ID
+		}
+	}
+	if c.SeedTop != nil {
+		settings.SeedTopN, changed = *c.SeedTop, true
+	}
+	if c.JudgeWeight != nil {
+		settings.JudgeWeight, changed = *c.JudgeWeight, true
+	}
+	if c.OpensAt != nil {
+		settings.OpensAt, changed = time.Time{}, true
+		if *c.OpensAt != "" {
+			opensAt, err := parsePlannedTime(*c.OpensAt, "opening")
+			if err != nil {
+				return invalid(err)
+			}
+			settings.OpensAt = opensAt
+		}
+	}
+	if c.ClosesAt != nil {
+		settings.ClosesAt, changed = time.Time{}, true
+		if *c.ClosesAt != "" {
+			closesAt, err := parsePlannedTime(*c.ClosesAt, "closing")
+			if err != nil {
+				return invalid(err)
+			}
+			settings.ClosesAt = closesAt
+		}
+	}
+	if !changed {
+		return invalidf("nothing to change: pass at least one of --name, --slug, --type, --show-results, --max-rank, --color, --icon, --after, --seed-top, --opens-at, --closes-at, --skin, --css-file, --reveal-sound")
+	}
+
+	if err := settings.Normalize(); err != nil {
+		return invalid(err)
+	}
+	if err := settings.CheckDependency(context.Background(), ctx.Queries, cat.ID); err != nil {
+		return invalid(err)
+	}
+	if err := settings.AssignSlug(context.Background(), ctx.Queries, cat.ID); err != nil {
+		return invalid(err)
+	}
+
+	if err := ctx.Queries.UpdateCategory(context.Background(), settings.UpdateParams(cat.ID)); err != nil {
+		return dbError(err)
+	}
+
+	ctx.audit(db.AuditCategoryUpdate, cat.ID, settings.Name)
+	ctx.say("Updated poll #%d: %s (%s, results %s)\n", cat.ID, settings.Name, settings.VoteType, settings.ShowResults)
+	return nil
+}
+
+func (c *PollEditCmd) Help() string {
+	return `Examples:
+  votigo poll edit 1 --name "Best Platformer"
+  votigo poll edit 1 --slug platformer
+  votigo poll edit 1 --slug ""                   # make one from the name
+  votigo poll edit 1 --type ranked --max-rank 5
+  votigo poll edit 1 --show-results live
+  votigo poll edit 5 --after 1 --seed-top 3     # opens once poll 1 closes
+  votigo poll edit 5 --after ""                  # open any time
+  votigo poll edit 1 --opens-at "2026-03-14 20:00"
+  votigo poll edit 1 --closes-at "2026-03-14 21:30"
+  votigo category edit 1 --color "" --icon ""    # remove the label
+  votigo poll edit 1 --skin neon --css-file ""   # neon, without custom CSS
+  votigo poll edit 1 --reveal-sound /sounds/drumroll.mp3
+  votigo poll edit 1 --eligibility "player|caster !crew"  # tag attendees at /admin/roster
+  votigo poll edit 1 --weights "jury=3"
+  votigo poll edit 1 --method borda              # or a plugin's, see plugins.go
+  votigo poll edit 1 --judges jury --judge-weight 50  # jury and audience half each`
+}
+
+// pollDetail is the JSON form of `poll show`
+type pollDetail struct {
+	ID          int64           `json:"id"`
+	Name        string          `json:"name"`
+	Slug        string          `json:"slug,omitempty"`
+	ShortCode   string          `json:"short_code"`
+	VoteType    string          `json:"vote_type"`
+	Status      string          `json:"status"`
+	ShowResults string          `json:"show_results"`
+	MaxRank     *int64          `json:"max_rank,omitempty"`
+	Color       string          `json:"color,omitempty"`
+	Icon        string          `json:"icon,omitempty"`
+	Skin        string          `json:"skin,omitempty"`
+	CustomCSS   bool            `json:"custom_css"`
+	RevealSound string          `json:"reveal_sound,omitempty"`
+	VotedWall   string          `json:"voted_wall,omitempty"`
+	Eligibility string          `json:"eligibility,omitempty"`
+	Weights     string          `json:"weights,omitempty"`
+	Method      string          `json:"method,omitempty"`
+	Judges      string          `json:"judges,omitempty"`
+	JudgeWeight int64           `json:"judge_weight,omitempty"`
+	OpensAfter  *pollRefDetail  `json:"opens_after,omitempty"`
+	OpensAt     *time.Time      `json:"opens_at,omitempty"`
+	ClosesAt    *time.Time      `json:"closes_at,omitempty"`
+	RunoffOf    *int64          `json:"runoff_of,omitempty"`
+	CreatedAt   *time.Time      `json:"created_at,omitempty"`
+	Votes       int64           `json:"votes"`
+	Options     []pollOption    `json:"options"`
+	History     []statusHistory `json:"status_history"`
+}
+
+// pollRefDetail identifies the poll another one opens after
+type pollRefDetail struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	SeedTopN int64  `json:"seed_top_n,omitempty"`
+}
+
+type pollOption struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	SortOrder  *int64     `json:"sort_order,omitempty"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+	SeededFrom *int64     `json:"seeded_from,omitempty"`
+}
+
+type statusHistory struct {
+	Action string     `json:"action"`
+	Actor  string     `json:"actor"`
+	At     *time.Time `json:"at,omitempty"`
+}
+
+func (c *PollShowCmd) Run(ctx *Context) error {
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
 	if err != nil {
-		return err
+		return lookupError("poll", err)
+	}
+
+	votes, err := ctx.Queries.CountVotesByCategory(context.Background(), cat.ID)
+	if err != nil {
+		return dbError(err)
+	}
+
+	options, err := ctx.Queries.ListOptionsByCategory(context.Background(), cat.ID)
+	if err != nil {
+		return dbError(err)
+	}
+
+	events, err := ctx.Queries.ListCategoryStatusHistory(context.Background(), sql.NullInt64{Int64: cat.ID, Valid: true})
+	if err != nil {
+		return dbError(err)
+	}
+
+	detail := pollDetail{
+		ID:          cat.ID,
+		Name:        cat.Name,
+		Slug:        cat.Slug.String,
+		ShortCode:   cat.ShortCode(),
+		VoteType:    cat.VoteType,
+		Status:      cat.Status,
+		ShowResults: cat.ShowResults,
+		MaxRank:     nullInt(cat.MaxRank),
+		Color:       cat.Color,
+		Icon:        cat.Icon,
+		Skin:        cat.Skin,
+		CustomCSS:   cat.CustomCss != "",
+		RevealSound: cat.RevealSound,
+		VotedWall:   cat.VotedWall,
+		Eligibility: cat.Eligibility,
+		Weights:     cat.Weights,
+		Method:      cat.Method,
+		Judges:      cat.Judges,
+		RunoffOf:    nullInt(cat.RunoffOf),
+		OpensAt:     nullTime(cat.OpensAt),
+		ClosesAt:    nullTime(cat.ClosesAt),
+		CreatedAt:   nullTime(cat.CreatedAt),
+		Votes:       votes,
+		Options:     []pollOption{},
+		History:     []statusHistory{},
+	}
+	if cat.Judged() {
+		detail.JudgeWeight = cat.JudgeWeight
+	}
+	if cat.DependsOn.Valid {
+		prev, err := ctx.Queries.Category(context.Background(), cat.DependsOn.Int64)
+		if err != nil {
+			return dbError(err)
+		}
+		detail.OpensAfter = &pollRefDetail{ID: prev.ID, Name: prev.Name, Status: prev.Status, SeedTopN: cat.SeedTopN}
+	}
+	for _, o := range options {
+		detail.Options = append(detail.Options, pollOption{ID: o.ID, Name: o.Name, SortOrder: nullInt(o.SortOrder), RetiredAt: nullTime(o.RetiredAt), SeededFrom: nullInt(o.SeededFrom)})
+	}
+	for _, e := range events {
+		detail.History = append(detail.History, statusHistory{
+			Action: strings.TrimPrefix(e.Action, "category."),
+			Actor:  e.Actor,
+			At:     nullTime(e.CreatedAt),
+		})
 	}
 
-	fmt.Printf("Created poll #%d: %s (%s)\n", cat.ID, cat.Name, cat.VoteType)
+	if c.Format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(detail)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Poll #%d:\t%s\n", detail.ID, detail.Name)
+	if detail.Slug != "" {
+		fmt.Fprintf(w, "Slug:\t%s\n", detail.Slug)
+	}
+	fmt.Fprintf(w, "Short link:\t%s\n", web.ShortLinkURL(detail.ShortCode))
+	voteType := detail.VoteType
+	if detail.MaxRank != nil {
+		voteType += fmt.Sprintf(" (rank top %d)", *detail.MaxRank)
+	}
+	fmt.Fprintf(w, "Type:\t%s\n", voteType)
+	fmt.Fprintf(w, "Status:\t%s\n", detail.Status)
+	fmt.Fprintf(w, "Results:\t%s\n", detail.ShowResults)
+	if label := strings.TrimSpace(detail.Icon + " " + detail.Color); label != "" {
+		fmt.Fprintf(w, "Label:\t%s\n", label)
+	}
+	if detail.Skin != "" || detail.CustomCSS {
+		skin := detail.Skin
+		if skin == "" {
+			skin = "default"
+		}
+		if detail.CustomCSS {
+			skin += " + custom CSS"
+		}
+		fmt.Fprintf(w, "Skin:\t%s\n", skin)
+	}
+	if detail.RevealSound != "" {
+		fmt.Fprintf(w, "Reveal sound:\t%s\n", detail.RevealSound)
+	}
+	if detail.VotedWall != "" {
+		fmt.Fprintf(w, "Voted wall:\t%s\n", detail.VotedWall)
+	}
+	if detail.Eligibility != "" {
+		fmt.Fprintf(w, "Who can vote:\t%s\n", detail.Eligibility)
+	}
+	if detail.Weights != "" {
+		fmt.Fprintf(w, "Ballot weights:\t%s\n", detail.Weights)
+	}
+	if detail.Method != "" {
+		fmt.Fprintf(w, "Counted by:\t%s\n", detail.Method)
+	}
+	if detail.Judges != "" {
+		fmt.Fprintf(w, "Judges:\t%s, deciding %d%% (audience %d%%)\n", detail.Judges, detail.JudgeWeight, 100-detail.JudgeWeight)
+	}
+	if after := detail.OpensAfter; after != nil {
+		line := fmt.Sprintf("#%d %s (%s)", after.ID, after.Name, after.Status)
+		if after.SeedTopN > 0 {
+			line += fmt.Sprintf(", seeded with its top %d", after.SeedTopN)
+		}
+		fmt.Fprintf(w, "Opens after:\t%s\n", line)
+	}
+	if detail.OpensAt != nil {
+		fmt.Fprintf(w, "Opens at:\t%s (planned)\n", formatTime(detail.OpensAt))
+	}
+	if detail.ClosesAt != nil {
+		fmt.Fprintf(w, "Closes at:\t%s (planned)\n", formatTime(detail.ClosesAt))
+	}
+	if detail.RunoffOf != nil {
+		fmt.Fprintf(w, "Runoff of:\t#%d\n", *detail.RunoffOf)
+	}
+	fmt.Fprintf(w, "Created:\t%s\n", formatTime(detail.CreatedAt))
+	fmt.Fprintf(w, "Votes:\t%d\n", detail.Votes)
+	w.Flush()
+
+	fmt.Println("\nOptions:")
+	if len(detail.Options) == 0 {
+		fmt.Println("  No options yet.")
+	} else {
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  ID\tNAME\tSORT")
+		for _, o := range detail.Options {
+			sort := "-"
+			if o.SortOrder != nil {
+				sort = fmt.Sprint(*o.SortOrder)
+			}
+			name := o.Name
+			if o.RetiredAt != nil {
+				name += " (retired)"
+			}
+			fmt.Fprintf(w, "  %d\t%s\t%s\n", o.ID, name, sort)
+		}
+		w.Flush()
+	}
+
+	fmt.Println("\nStatus history:")
+	if len(detail.History) == 0 {
+		fmt.Println("  No status changes recorded.")
+	} else {
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  WHEN\tACTION\tBY")
+		for _, h := range detail.History {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", formatTime(h.At), h.Action, h.Actor)
+		}
+		w.Flush()
+	}
 	return nil
 }
+
+func (c *PollShowCmd) Help() string {
+	return `Examples:
+  votigo poll show 1
+  votigo poll show 1 --format json | jq '.options[].name'`
+}
+
+func nullInt(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// parsePlannedTime reads a planned opening or closing time in local time,
+// either as "15:04" for today or as "2006-01-02 15:04"
+func parsePlannedTime(s, what string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("15:04", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s time %q: use HH:MM or YYYY-MM-DD HH:MM", what, s)
+	}
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.Local), nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
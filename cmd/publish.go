@@ -0,0 +1,34 @@
+// cmd/publish.go
+package cmd
+
+import (
+	"context"
+
+	"github.com/palm-arcade/votigo/internal/publish"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func (c *PublishCmd) Run(ctx *Context) error {
+	polls, err := publish.Site(context.Background(), ctx.Queries, c.Out)
+	if err != nil {
+		return err
+	}
+	ctx.say("Published %s to %s\n", tally.Plural(int64(len(polls)), "poll"), c.Out)
+	return nil
+}
+
+func (c *PublishCmd) Help() string {
+	return `Writes the results of every closed or archived poll as a static website,
+to host on the club webpage after the event; it needs no votigo server. Each
+poll gets a page with its results table and results card, and index.html
+lists them all under the event_name setting. Open polls, drafts and voter
+nicknames are left out.
+
+Links are relative, so the site can be uploaded to any path or opened
+straight from disk. Publishing again overwrites the pages it wrote before
+and leaves other files in the directory alone.
+
+Examples:
+  votigo publish
+  votigo publish --out ./site && rsync -r site/ club.example.org:www/lan-2026/`
+}
@@ -0,0 +1,71 @@
+// cmd/purge.go
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func (c *PurgeCmd) Run(ctx *Context) error {
+	if c.Days < 0 {
+		return invalidf("--days must be at least 1")
+	}
+	retention := time.Duration(c.Days) * 24 * time.Hour
+	if retention == 0 {
+		var err error
+		if retention, err = ctx.Queries.BallotRetention(context.Background()); err != nil {
+			return dbError(err)
+		}
+		if retention == 0 {
+			return invalidf("ballots are kept forever; set %s or pass --days", db.SettingBallotRetentionDays)
+		}
+	}
+	now := time.Now().UTC()
+
+	if c.DryRun {
+		polls, err := ctx.Queries.ListCategoriesToPurge(context.Background(), sql.NullTime{Time: now.Add(-retention), Valid: true})
+		if err != nil {
+			return dbError(err)
+		}
+		for _, cat := range polls {
+			votes, err := ctx.Queries.CountVotesByCategory(context.Background(), cat.ID)
+			if err != nil {
+				return dbError(err)
+			}
+			fmt.Printf("%d\t%s\t%s\n", cat.ID, cat.Name, tally.Plural(votes, "ballot"))
+		}
+		ctx.say("Would purge %s\n", tally.Plural(int64(len(polls)), "poll"))
+		return nil
+	}
+
+	purged, err := db.PurgeExpiredBallots(context.Background(), ctx.DB, db.ActorCLI, retention, now)
+	for _, p := range purged {
+		ctx.say("Purged %s from %s\n", tally.Plural(p.Ballots, "ballot"), p.Category.Name)
+	}
+	if err != nil {
+		return dbError(err)
+	}
+	ctx.say("Purged %s\n", tally.Plural(int64(len(purged)), "poll"))
+	return nil
+}
+
+func (c *PurgeCmd) Help() string {
+	return `Deletes the ballots of polls that closed longer ago than the
+ballot_retention_days setting, or --days, keeping their results. Each poll's
+tally is saved first, so results pages, cards and votigo results show what
+they did before; vote events in the audit log are kept with the nickname
+replaced by [purged]. A purged poll can't be reopened or recounted.
+
+votigo serve does the same every hour while ballot_retention_days is set, so
+this is for purging on demand or from cron when no server is running.
+
+Examples:
+  votigo settings set ballot_retention_days 30
+  votigo purge --dry-run
+  votigo purge --days 7`
+}
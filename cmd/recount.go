@@ -0,0 +1,128 @@
+// cmd/recount.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func (c *RecountCmd) Run(ctx *Context) error {
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
+	if err != nil {
+		return lookupError("poll", err)
+	}
+
+	if _, ok := tally.Methods[c.Method]; !ok && c.Method != "stv" {
+		return invalidf("unknown counting method %q (available: %s, stv)", c.Method, strings.Join(tally.MethodNames(), ", "))
+	}
+	if c.Seats < 1 {
+		return invalidf("--seats must be at least 1")
+	}
+	if c.Seats > 1 && c.Method != "stv" {
+		return invalidf("--seats only applies to --method stv")
+	}
+
+	options, err := ctx.Queries.ListOptionsByCategory(context.Background(), cat.ID)
+	if err != nil {
+		return dbError(err)
+	}
+
+	ballots, err := ctx.Queries.WeightedBallots(context.Background(), cat)
+	if err != nil {
+		return dbError(err)
+	}
+	if len(ballots) == 0 {
+		purged, err := ctx.Queries.Purged(context.Background(), cat.ID)
+		if err != nil {
+			return dbError(err)
+		}
+		if purged {
+			return invalidf("%s's ballots were purged; only its results are kept", cat.Name)
+		}
+		return invalidf("%s has no ballots to recount", cat.Name)
+	}
+	if (c.Method == "irv" || c.Method == "stv") && cat.VoteType == "approval" {
+		return invalidf("%s needs ranked or single-choice ballots; %s is an approval poll", c.Method, cat.Name)
+	}
+
+	recorded, err := ctx.Queries.Tally(context.Background(), cat)
+	if err != nil {
+		return dbError(err)
+	}
+	method := tally.Methods[c.Method]
+	if c.Method == "stv" {
+		method = tally.STV(c.Seats)
+	}
+	result := method(db.TallyOptions(options), ballots)
+
+	names := make(map[int64]string, len(options))
+	for _, o := range options {
+		names[o.ID] = o.Name
+	}
+	recordedPlace := make(map[int64]int, len(recorded))
+	recordedScore := make(map[int64]string, len(recorded))
+	for i, row := range recorded {
+		recordedPlace[row.ID], recordedScore[row.ID] = i+1, row.Label
+	}
+
+	fmt.Printf("Recount for: %s (%s, %s)\n\n", cat.Name, cat.VoteType, tally.Plural(int64(len(ballots)), "ballot"))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "OPTION\tRECORDED\t\t%s\n", strings.ToUpper(c.Method))
+	for i, row := range result.Ranking {
+		fmt.Fprintf(w, "%s\t#%d\t%s\t#%d\t%s\n", names[row.OptionID],
+			recordedPlace[row.OptionID], recordedScore[row.OptionID], i+1, row.Label)
+	}
+	w.Flush()
+
+	if len(result.Notes) > 0 {
+		fmt.Println()
+		for _, note := range result.Notes {
+			fmt.Println(note)
+		}
+	}
+
+	fmt.Println()
+	if c.Seats > 1 {
+		elected := make([]string, 0, c.Seats)
+		for _, row := range result.Ranking[:min(c.Seats, len(result.Ranking))] {
+			elected = append(elected, names[row.OptionID])
+		}
+		fmt.Printf("Elected: %s\n", strings.Join(elected, ", "))
+		return nil
+	}
+	recordedWinner, winner := recorded[0].ID, result.Ranking[0].OptionID
+	if recordedWinner == winner {
+		fmt.Printf("Same winner: %s\n", names[winner])
+	} else {
+		fmt.Printf("Different winner: %s recorded, %s by %s\n", names[recordedWinner], names[winner], c.Method)
+	}
+	return nil
+}
+
+func (c *RecountCmd) Help() string {
+	return `Recomputes a poll's results from the stored ballots and compares them with
+the recorded result. Nothing is changed.
+
+  borda      n-1 points for a first choice, n-2 for second, ... (n = options)
+  irv        instant runoff: drop the last option until one has a majority
+  condorcet  head-to-head wins; ranks by wins minus losses
+  stv        single transferable vote electing --seats winners; votes over
+             the quota pass on to the next choices
+  elo        rates options like chess players, each ballot playing every
+             pair of options it ranks
+
+Plugins built in (see plugins.go) can add more. Any method but stv can also
+be a poll's own counting method, set with votigo poll edit --method.
+
+Examples:
+  votigo recount 1 --method irv
+  votigo recount 1 --method stv --seats 3
+  votigo recount "best game" --method condorcet`
+}
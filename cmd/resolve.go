@@ -0,0 +1,107 @@
+// cmd/resolve.go
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// PollRef is a poll argument given as an ID, a slug or by name. Names may be
+// a unique prefix or a close enough match ("votigo open 'best pixel'"). It
+// is resolved to an ID once the database is open.
+type PollRef struct {
+	ID  int64
+	ref string
+}
+
+func (p *PollRef) Decode(dctx *kong.DecodeContext) error {
+	return dctx.Scan.PopValueInto("poll", &p.ref)
+}
+
+// AfterApply runs after CLI.AfterApply has opened the database
+func (p *PollRef) AfterApply(ctx *Context) error {
+	if p.ref == "" {
+		return nil
+	}
+	cat, err := resolvePoll(ctx, p.ref, os.Stdin, os.Stderr)
+	if err != nil {
+		return err
+	}
+	p.ID = cat.ID
+	return nil
+}
+
+// resolvePoll finds the poll ref names. Matching tries, in order: the ID,
+// the slug, the exact name, a name prefix, a substring, then fuzzy matches (letters
+// in order, or a couple of typos). The first tier with any match wins; if it
+// has several, an interactive user picks one and anyone else gets an error.
+func resolvePoll(ctx *Context, ref string, in io.Reader, out io.Writer) (db.Category, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		cat, err := ctx.Queries.Category(context.Background(), id)
+		if errors.Is(err, db.ErrNotFound) {
+			return cat, notFound("poll #%d not found", id)
+		}
+		if err != nil {
+			return cat, dbError(err)
+		}
+		return cat, nil
+	}
+	if cat, err := ctx.Queries.GetCategoryBySlug(context.Background(), sql.NullString{String: ref, Valid: true}); err == nil {
+		return cat, nil
+	}
+
+	categories, err := ctx.Queries.ListCategories(context.Background())
+	if err != nil {
+		return db.Category{}, dbError(err)
+	}
+
+	matches := db.MatchCategories(categories, ref)
+	switch {
+	case len(matches) == 0:
+		return db.Category{}, notFound("no poll matches %q (see `votigo poll list`)", ref)
+	case len(matches) == 1:
+		return matches[0], nil
+	case !isInteractive(in):
+		names := make([]string, len(matches))
+		for i, m := range matches {
+			names[i] = fmt.Sprintf("#%d %s", m.ID, m.Name)
+		}
+		return db.Category{}, invalidf("%q matches several polls (%s); use the ID", ref, strings.Join(names, ", "))
+	}
+
+	fmt.Fprintf(out, "%q matches several polls:\n", ref)
+	for i, m := range matches {
+		fmt.Fprintf(out, "  %d) #%d %s (%s)\n", i+1, m.ID, m.Name, m.Status)
+	}
+	r := bufio.NewReader(in)
+	for {
+		fmt.Fprintf(out, "Choose 1-%d: ", len(matches))
+		line, err := r.ReadString('\n')
+		if n, convErr := strconv.Atoi(strings.TrimSpace(line)); convErr == nil && n >= 1 && n <= len(matches) {
+			return matches[n-1], nil
+		}
+		if err != nil {
+			return db.Category{}, invalidf("no poll chosen")
+		}
+	}
+}
+
+// isInteractive reports whether in is a terminal someone can answer from
+func isInteractive(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -2,82 +2,100 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
-	"database/sql"
 	"fmt"
 	"os"
 	"text/tabwriter"
 
 	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/tally"
 )
 
+// placeLabel is a RANK cell, marking places shared by tied options
+func placeLabel(p tally.Place) string {
+	if p.Tied {
+		return fmt.Sprintf("%d TIE", p.Rank)
+	}
+	return fmt.Sprint(p.Rank)
+}
+
 func (c *ResultsCmd) Run(ctx *Context) error {
-	cat, err := ctx.Queries.GetCategory(context.Background(), c.CategoryID)
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
 	if err != nil {
-		return fmt.Errorf("poll not found: %w", err)
+		return lookupError("poll", err)
 	}
 
-	voteCount, err := ctx.Queries.CountVotesByCategory(context.Background(), c.CategoryID)
+	voteCount, err := ctx.Queries.CountVotesByCategory(context.Background(), c.Poll.ID)
 	if err != nil {
-		return err
+		return dbError(err)
 	}
 
 	fmt.Printf("Results for: %s (%d votes)\n\n", cat.Name, voteCount)
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 
-	if cat.VoteType == "ranked" {
-		maxRank := sql.NullInt64{Int64: 3, Valid: true}
-		if cat.MaxRank.Valid {
-			maxRank = cat.MaxRank
-		}
-
-		results, err := ctx.Queries.TallyRanked(context.Background(), db.TallyRankedParams{
-			MaxRank:    maxRank,
-			CategoryID: c.CategoryID,
-		})
+	results, err := ctx.Queries.Tally(context.Background(), cat)
+	if err != nil {
+		return dbError(err)
+	}
+	places := db.Places(results, voteCount)
+	switch {
+	case cat.Judged():
+		scores, err := ctx.Queries.JudgeScores(context.Background(), cat)
 		if err != nil {
-			return err
+			return dbError(err)
 		}
-
-		fmt.Fprintln(w, "RANK\tOPTION\tPOINTS\t1ST PLACE")
+		fmt.Fprintf(w, "RANK\tOPTION\tJUDGES (%d%%)\tAUDIENCE (%d%%)\tTOTAL\n", cat.JudgeWeight, 100-cat.JudgeWeight)
 		for i, r := range results {
-			// Points is interface{} due to COALESCE, convert to int64
-			points := int64(0)
-			if r.Points != nil {
-				switch v := r.Points.(type) {
-				case int64:
-					points = v
-				case float64:
-					points = int64(v)
-				}
-			}
-			fmt.Fprintf(w, "%d\t%s\t%d\t%d\n", i+1, r.Name, points, r.FirstPlaceVotes)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", placeLabel(places[i]), r.Name, cmp.Or(scores[r.ID].Jury.Label, "-"), cmp.Or(scores[r.ID].Audience.Label, "-"), r.Label)
 		}
-	} else {
-		results, err := ctx.Queries.TallySimple(context.Background(), c.CategoryID)
-		if err != nil {
-			return err
+	case cat.VoteType == "ranked":
+		fmt.Fprintln(w, "RANK\tOPTION\tPOINTS\t1ST PLACE")
+		for i, r := range results {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", placeLabel(places[i]), r.Name, r.Score, r.FirstPlace)
 		}
-
+	default:
 		fmt.Fprintln(w, "RANK\tOPTION\tVOTES")
 		for i, r := range results {
-			fmt.Fprintf(w, "%d\t%s\t%d\n", i+1, r.Name, r.Votes)
+			fmt.Fprintf(w, "%s\t%s\t%d\n", placeLabel(places[i]), r.Name, r.Score)
 		}
 	}
 
 	w.Flush()
 
+	narrow, err := ctx.Queries.SettingInt(context.Background(), db.SettingNarrowMargin)
+	if err != nil {
+		return dbError(err)
+	}
+	if m, ok := db.WinningMargin(cat, results, voteCount, narrow); ok {
+		verb := "Leading"
+		if cat.Finished() {
+			verb = "Won"
+		}
+		fmt.Printf("\n%s %s", verb, m)
+		if m.Narrow {
+			fmt.Print(" - NARROW WIN")
+		}
+		fmt.Println()
+	}
+
 	if c.ShowVoters {
 		fmt.Println("\nVoters:")
-		voters, err := ctx.Queries.ListVotersByCategory(context.Background(), c.CategoryID)
+		voters, err := ctx.Queries.ListVotersByCategory(context.Background(), c.Poll.ID)
 		if err != nil {
-			return err
+			return dbError(err)
 		}
 		for _, v := range voters {
-			fmt.Printf("  - %s\n", v)
+			fmt.Printf("  - %s\n", ctx.Nicknames.Reveal(v))
 		}
 	}
 
 	return nil
 }
+
+func (c *ResultsCmd) Help() string {
+	return `Examples:
+  votigo results 1
+  votigo results 1 --show-voters`
+}
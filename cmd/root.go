@@ -1,95 +1,527 @@
 package cmd
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/alecthomas/kong"
 	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/notify"
 )
 
 // Context passed to all commands
 type Context struct {
-	DB      *sql.DB
-	Queries *db.Queries
+	DB        *sql.DB
+	DBPath    string // from --db
+	Queries   *db.Queries
+	Nicknames *db.NicknameCipher // nil unless the database is encrypted
+	Notifier  notify.Notifier    // nil unless a chat integration is configured
+	Quiet     bool               // set by --quiet
+
+	// loadNotifier builds the notifier afresh from the flags and
+	// --notify-file, for serve to reload it
+	loadNotifier func() (notify.Notifier, error)
+}
+
+// say prints a confirmation message unless --quiet is set. Output a command
+// was asked for, like a list or results table, is printed regardless.
+func (c *Context) say(format string, args ...any) {
+	if !c.Quiet {
+		fmt.Printf(format, args...)
+	}
+}
+
+// audit records a CLI action in the audit log, warning on failure
+func (c *Context) audit(action string, categoryID int64, detail string) {
+	err := c.Queries.RecordAudit(context.Background(), db.ActorCLI, action, categoryID, detail)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record audit event: %v\n", err)
+	}
+}
+
+// announce sends lifecycle events for a poll to the configured notifier,
+// warning on failure
+func (c *Context) announce(cat db.Category, types ...notify.EventType) {
+	if err := c.sendEvents(cat, types...); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
+
+// sendEvents delivers lifecycle events for a poll, returning every failure
+func (c *Context) sendEvents(cat db.Category, types ...notify.EventType) error {
+	if c.Notifier == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var errs []error
+	for _, typ := range types {
+		ev, err := notify.NewEvent(ctx, c.Queries, typ, cat)
+		if err == nil {
+			err = c.Notifier.Notify(ctx, ev)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to send %s notification: %w", typ, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 type CLI struct {
-	DB string `help:"Path to database file" default:"votigo.db" type:"path"`
+	DB    string `help:"Path to database file" default:"votigo.db" type:"path"`
+	DBKey string `name:"db-key" help:"Passphrase encrypting voter nicknames at rest (set once to encrypt an existing database)" env:"VOTIGO_DB_KEY"`
+	Quiet bool   `short:"q" help:"Only print errors and requested data; create commands print just the new ID"`
 
-	Serve   ServeCmd   `cmd:"" help:"Start the web server"`
-	Poll    PollCmd    `cmd:"" help:"Manage voting polls"`
-	Option  OptionCmd  `cmd:"" help:"Manage poll options"`
-	Open    OpenCmd    `cmd:"" help:"Open voting for a poll"`
-	Close   CloseCmd   `cmd:"" help:"Close voting for a poll"`
-	Reopen  ReopenCmd  `cmd:"" help:"Reopen voting for a closed poll"`
-	Results ResultsCmd `cmd:"" help:"Show results for a poll"`
+	SlackWebhook string   `help:"Slack incoming webhook URL for poll announcements" env:"VOTIGO_SLACK_WEBHOOK"`
+	Notify       []string `help:"Announce polls to BACKEND=TARGET (irc, matrix, slack, discord, exec); repeatable" env:"VOTIGO_NOTIFY" placeholder:"BACKEND=TARGET"`
+	NotifyFile   string   `help:"Also announce to each BACKEND=TARGET line of this file; serve re-reads it on SIGHUP" env:"VOTIGO_NOTIFY_FILE" type:"path"`
+
+	Serve    ServeCmd    `cmd:"" help:"Start the web server"`
+	Poll     PollCmd     `cmd:"" aliases:"category" help:"Manage voting polls"`
+	Option   OptionCmd   `cmd:"" help:"Manage poll options"`
+	Open     OpenCmd     `cmd:"" help:"Open voting for a poll"`
+	Close    CloseCmd    `cmd:"" help:"Close voting for a poll"`
+	Reopen   ReopenCmd   `cmd:"" help:"Reopen voting for a closed poll"`
+	Results  ResultsCmd  `cmd:"" help:"Show results for a poll"`
+	Recount  RecountCmd  `cmd:"" help:"Recompute a poll's results with another counting method"`
+	Simulate SimulateCmd `cmd:"" help:"Compare counting methods on synthetic ballots"`
+	Demo     DemoCmd     `cmd:"" help:"Serve a throwaway event with sample polls and votes, kept in memory"`
+	Runoff   RunoffCmd   `cmd:"" help:"Create a runoff for a poll that ended in a tie or without a majority"`
+	Votes    VotesCmd    `cmd:"" help:"Inspect and manage recorded votes"`
+	Voters   VotersCmd   `cmd:"" help:"Manage voter data"`
+	Audit    AuditCmd    `cmd:"" help:"Spot-check stored ballots"`
+	Tui      TuiCmd      `cmd:"" help:"Interactive dashboard with live vote counts"`
+	Settings SettingsCmd `cmd:"" help:"Show and change runtime settings"`
+	Season   SeasonCmd   `cmd:"" help:"Score events toward a season leaderboard"`
+	Database DatabaseCmd `cmd:"" name:"db" help:"Check, repair, export and import the database"`
+	Lint     LintCmd     `cmd:"" help:"Check polls and settings for misconfiguration"`
+	Publish  PublishCmd  `cmd:"" help:"Write the results of finished polls as a static website"`
+	Purge    PurgeCmd    `cmd:"" help:"Delete the ballots of polls finished longer than the retention period, keeping their results"`
+
+	Completion CompletionCmd `cmd:"" help:"Print a shell completion script"`
+	Complete   CompleteCmd   `cmd:"" name:"__complete" hidden:"" help:"List completions for the words typed so far"`
 }
 
 // Placeholder commands - will be implemented in later tasks
 type ServeCmd struct {
 	Port          int    `help:"Port to listen on" default:"5000"`
-	AdminPassword string `help:"Password for admin interface" required:""`
+	AdminPassword string `help:"Password for admin interface (made up and stored on first run if omitted)" env:"VOTIGO_ADMIN_PASSWORD"`
 	UI            string `help:"UI style" enum:"modern,legacy" default:"modern"`
+	HighContrast  bool   `help:"Start with the high-contrast theme (can be toggled from the admin dashboard)"`
+
+	RequestTimeout time.Duration `help:"How long a request may take before it is cancelled" default:"15s"`
+	DrainTimeout   time.Duration `help:"On shutdown, how long to let in-flight requests finish" default:"10s"`
+
+	AccessLog        string `help:"Write an access log in combined format to this file" type:"path"`
+	AccessLogMaxSize int64  `help:"Rotate the access log when it reaches this many MB" default:"50"`
+	AccessLogKeep    int    `help:"How many rotated access logs to keep" default:"5"`
+
+	ReadConn bool `help:"Serve results from a separate read-only connection, with the database in WAL mode" default:"true" negatable:""`
+
+	CleanupEvery time.Duration `help:"How often to purge expired sessions and idempotency keys (0 to disable)" default:"1h"`
+	ArchiveAfter time.Duration `help:"Archive polls once they have been closed this long (0 to disable)" default:"24h"`
+	VacuumEvery  time.Duration `help:"How often to compact the database while no poll is open (0 to disable)" default:"24h"`
+	BackupDir    string        `help:"Snapshot the database into this directory" type:"path"`
+	BackupEvery  time.Duration `help:"How often to snapshot the database into --backup-dir" default:"1h"`
+	BackupKeep   int           `help:"How many database snapshots to keep" default:"24"`
+
+	SoundDir string `help:"Keep audio cues uploaded from the admin settings page in this directory" type:"path"`
+	ImageDir string `help:"Keep option images uploaded from the admin poll page in this directory" type:"path"`
+
+	MediaPreviews bool `help:"Check options' YouTube and Vimeo links and fetch their titles and thumbnails when saved" default:"true" negatable:""`
+
+	TemplateDir string `help:"Parse templates from this directory instead of the built-in ones, again on SIGHUP (for development)" type:"existingdir"`
+
+	Tenants string `help:"Serve more events, chosen by host name, as listed in this JSON file (see --help)" type:"existingfile"`
+}
+
+type CompletionCmd struct {
+	Shell string `arg:"" enum:"bash,zsh,fish" help:"Shell to generate completions for (bash, zsh, fish)"`
+}
+
+type CompleteCmd struct {
+	Words []string `arg:"" optional:"" passthrough:"" help:"Words after the program name; the last is being completed"`
+}
+
+type TuiCmd struct {
+	Refresh time.Duration `help:"How often to refresh vote counts" default:"2s"`
 }
 
 type PollCmd struct {
 	List   PollListCmd   `cmd:"" help:"List all polls"`
 	Create PollCreateCmd `cmd:"" help:"Create a new poll"`
+	Edit   PollEditCmd   `cmd:"" help:"Change a poll's name, slug, type, results visibility, label or skin"`
+	Show   PollShowCmd   `cmd:"" help:"Show a poll's settings, options, vote count and status history"`
 }
 
 type PollListCmd struct{}
 type PollCreateCmd struct {
-	Name    string `arg:"" help:"Poll name"`
-	Type    string `help:"Vote type: single, ranked, approval" default:"single" enum:"single,ranked,approval"`
-	MaxRank int    `help:"Max rank for ranked voting" default:"3"`
+	Name        string `arg:"" help:"Poll name"`
+	Type        string `help:"Vote type: single, ranked, approval" default:"single" enum:"single,ranked,approval"`
+	MaxRank     int    `help:"Max rank for ranked voting" default:"3"`
+	Color       string `help:"Label color: green, amber, red, blue, purple, pink, cyan"`
+	Icon        string `help:"Label icon (emoji) shown next to the poll name"`
+	After       string `help:"Poll (ID or name) that must close before this one can open"`
+	SeedTop     int64  `help:"When the --after poll closes, copy in its top N options"`
+	OpensAt     string `help:"Planned opening time shown in the home page schedule: HH:MM today or YYYY-MM-DD HH:MM"`
+	ClosesAt    string `help:"Planned closing time shown on info screens: HH:MM today or YYYY-MM-DD HH:MM"`
+	Slug        string `help:"URL slug voters see, as in /vote/best-game (default: made from the name)"`
+	Skin        string `help:"Look of the ballot and results pages: crt, neon, paper"`
+	CSSFile     string `name:"css-file" help:"File of custom CSS added to the ballot and results pages (up to 4 KB)" type:"path"`
+	RevealSound string `help:"Audio cue the ceremony display plays on reveal: /sounds/<name> or an http(s) URL"`
+	VotedWall   string `help:"List who has voted beside the ballot: names, avatars (default: hidden)"`
+	Eligibility string `help:"Who can vote, by roster tags, e.g. \"participant !crew\" (default: everyone)"`
+	Weights     string `help:"Count ballots from some roster tags more than once, e.g. \"jury=3\" (default: everyone once)"`
+	Method      string `help:"Counting method for the published result, from votigo recount's (default: votes, or points when ranked)"`
+	Judges      string `help:"Roster tag of judges scored apart from the audience, e.g. jury (default: no judges)"`
+	JudgeWeight int64  `help:"Percent of the result the judges decide" default:"50"`
+}
+
+type PollEditCmd struct {
+	Poll        PollRef `arg:"" help:"Poll ID or name"`
+	Name        *string `help:"New poll name"`
+	Type        *string `help:"Vote type: single, ranked, approval"`
+	ShowResults *string `help:"When voters see results: live, after_close"`
+	MaxRank     *int64  `help:"Max rank for ranked voting"`
+	Color       *string `help:"Label color: green, amber, red, blue, purple, pink, cyan (empty to remove)"`
+	Icon        *string `help:"Label icon (empty to remove)"`
+	After       *string `help:"Poll (ID or name) that must close before this one can open (empty to remove)"`
+	SeedTop     *int64  `help:"When the --after poll closes, copy in its top N options (0 for none)"`
+	OpensAt     *string `help:"Planned opening time: HH:MM today or YYYY-MM-DD HH:MM (empty to remove)"`
+	ClosesAt    *string `help:"Planned closing time: HH:MM today or YYYY-MM-DD HH:MM (empty to remove)"`
+	Slug        *string `help:"URL slug voters see (empty to make one from the name)"`
+	Skin        *string `help:"Look of the ballot and results pages: crt, neon, paper (empty for the default)"`
+	CSSFile     *string `name:"css-file" help:"File of custom CSS added to the ballot and results pages (empty to remove)" type:"path"`
+	RevealSound *string `help:"Audio cue the ceremony display plays on reveal (empty to remove)"`
+	VotedWall   *string `help:"List who has voted beside the ballot: names, avatars (empty to hide)"`
+	Eligibility *string `help:"Who can vote, by roster tags, e.g. \"participant !crew\" (empty for everyone)"`
+	Weights     *string `help:"Count ballots from some roster tags more than once, e.g. \"jury=3\" (empty for everyone once)"`
+	Method      *string `help:"Counting method for the published result, from votigo recount's (empty for votes, or points when ranked)"`
+	Judges      *string `help:"Roster tag of judges scored apart from the audience (empty for no judges)"`
+	JudgeWeight *int64  `help:"Percent of the result the judges decide"`
+}
+
+type PollShowCmd struct {
+	Poll   PollRef `arg:"" help:"Poll ID or name"`
+	Format string  `help:"Output format: table, json" enum:"table,json" default:"table"`
 }
 
 type OptionCmd struct {
-	Add    OptionAddCmd    `cmd:"" help:"Add option to poll"`
-	List   OptionListCmd   `cmd:"" help:"List options in poll"`
-	Remove OptionRemoveCmd `cmd:"" help:"Remove an option"`
+	Add     OptionAddCmd     `cmd:"" help:"Add option to poll"`
+	List    OptionListCmd    `cmd:"" help:"List options in poll"`
+	Remove  OptionRemoveCmd  `cmd:"" help:"Remove an option"`
+	Retire  OptionRetireCmd  `cmd:"" help:"Hide an option from ballots, keeping its votes in the results"`
+	Seed    OptionSeedCmd    `cmd:"" help:"Copy the top options of a closed poll into a draft one"`
+	Import  OptionImportCmd  `cmd:"" help:"Add options read from a file or a plugin's option source"`
+	Details OptionDetailsCmd `cmd:"" help:"Set the blurb, video and screenshots voters can open from the ballot"`
 }
 
 type OptionAddCmd struct {
-	CategoryID int64  `arg:"" help:"Poll ID"`
-	Name       string `arg:"" help:"Option name"`
+	Poll  PollRef `arg:"" help:"Poll ID or name"`
+	Name  string  `arg:"" help:"Option name"`
+	Image string  `help:"Image shown with the option: /images/<name> or an http(s) URL"`
 }
 type OptionListCmd struct {
-	CategoryID int64 `arg:"" help:"Poll ID"`
+	Poll PollRef `arg:"" help:"Poll ID or name"`
 }
 type OptionRemoveCmd struct {
 	OptionID int64 `arg:"" help:"Option ID"`
+	Force    bool  `help:"Remove the option even if it has votes, deleting them too"`
+}
+
+type OptionRetireCmd struct {
+	OptionID int64 `arg:"" help:"Option ID"`
+}
+
+type OptionSeedCmd struct {
+	Poll PollRef `arg:"" help:"Draft poll ID or name to add options to"`
+	From string  `required:"" help:"Closed poll (ID or name) whose results to copy from"`
+	Top  int64   `help:"Number of top places to copy; ties for the last place are all copied" default:"3"`
+}
+
+type OptionDetailsCmd struct {
+	OptionID   int64    `arg:"" help:"Option ID"`
+	Blurb      string   `help:"What the option is; a blank line starts a new paragraph"`
+	Video      string   `help:"http(s) link to a trailer or gameplay video"`
+	Screenshot []string `sep:"none" help:"Screenshot: /images/<name> or an http(s) URL (repeatable)"`
+	Clear      bool     `help:"Remove the details first; with no other flags, remove them all"`
+	Preview    bool     `help:"Check YouTube and Vimeo videos can play and fetch their title and thumbnail" default:"true" negatable:""`
+}
+
+type OptionImportCmd struct {
+	Poll   PollRef `arg:"" help:"Poll ID or name to add options to"`
+	Source string  `arg:"" help:"Where to read options: a text file, one per line, or SOURCE=TARGET (e.g. file=maps.txt)"`
 }
 
 type OpenCmd struct {
-	CategoryID int64 `arg:"" help:"Poll ID to open"`
+	Poll PollRef `arg:"" help:"Poll ID or name to open"`
 }
 
 type CloseCmd struct {
-	CategoryID int64 `arg:"" help:"Poll ID to close"`
+	Poll PollRef `arg:"" help:"Poll ID or name to close"`
 }
 
 type ReopenCmd struct {
-	CategoryID int64 `arg:"" help:"Poll ID to reopen"`
+	Poll PollRef `arg:"" help:"Poll ID or name to reopen"`
 }
 
 type ResultsCmd struct {
-	CategoryID int64 `arg:"" help:"Poll ID"`
-	ShowVoters bool  `help:"Show voter nicknames"`
+	Poll       PollRef `arg:"" help:"Poll ID or name"`
+	ShowVoters bool    `help:"Show voter nicknames"`
+}
+
+type RecountCmd struct {
+	Poll   PollRef `arg:"" help:"Poll ID or name"`
+	Method string  `help:"Counting method: borda, irv, condorcet, stv, elo, or one a plugin adds" required:""`
+	Seats  int     `help:"Winners to elect with stv" default:"1"`
+}
+
+type SimulateCmd struct {
+	Voters       int    `help:"Number of simulated voters" default:"200"`
+	Options      int    `help:"Number of options (2-26)" default:"5"`
+	Distribution string `help:"How preferences are spread: uniform, zipf, polarized" enum:"uniform,zipf,polarized" default:"zipf"`
+	MaxRank      int64  `help:"Choices kept on ranked ballots" default:"3"`
+	Seed         string `help:"Seed for the random ballots; the same seed gives the same ballots (random if omitted)"`
+}
+
+type DemoCmd struct {
+	Port int    `help:"Port to listen on" default:"5000"`
+	UI   string `help:"UI style" enum:"modern,legacy" default:"modern"`
+}
+
+type RunoffCmd struct {
+	Poll PollRef `arg:"" help:"Closed single-choice poll ID or name"`
+}
+
+type VotesCmd struct {
+	History      VotesHistoryCmd      `cmd:"" help:"Show how voters changed their ballots"`
+	PurgeHistory VotesPurgeHistoryCmd `cmd:"" help:"Delete previous ballot versions for privacy"`
+	Export       VotesExportCmd       `cmd:"" help:"Write anonymized ballots as CSV or JSON for analysis"`
+	Ballots      VotesBallotsCmd      `cmd:"" help:"List a poll's ballots with where they came from and whether they count"`
+	Invalidate   VotesInvalidateCmd   `cmd:"" help:"Stop counting ballots cast outside the official window or from banned voters"`
+	Restore      VotesRestoreCmd      `cmd:"" help:"Count an invalidated ballot again"`
+}
+
+type VotesHistoryCmd struct {
+	Poll PollRef `arg:"" help:"Poll ID or name"`
+}
+
+type VotesPurgeHistoryCmd struct {
+	Poll PollRef `arg:"" optional:"" help:"Poll ID or name (omit with --all)"`
+	All  bool    `help:"Purge history for every poll"`
+}
+
+type VotesExportCmd struct {
+	Polls  []string `arg:"" optional:"" help:"Polls (ID or name) to export (default: every closed or archived poll)"`
+	Format string   `help:"Output format: csv, json" enum:"csv,json" default:"csv"`
+	Out    string   `short:"o" help:"File to write (stdout if omitted or -)" type:"path"`
+}
+
+type VotesBallotsCmd struct {
+	Poll PollRef `arg:"" help:"Poll ID or name"`
+}
+
+type VotesInvalidateCmd struct {
+	Poll     PollRef  `arg:"" help:"Poll ID or name"`
+	Before   string   `help:"Invalidate ballots cast before this time (HH:MM or YYYY-MM-DD HH:MM)"`
+	After    string   `help:"Invalidate ballots cast at or after this time (HH:MM or YYYY-MM-DD HH:MM)"`
+	Address  []string `help:"Invalidate ballots from this address or range, e.g. 10.0.0.0/24 (repeatable)"`
+	Nickname []string `help:"Invalidate this voter's ballot (repeatable)"`
+	Reason   string   `required:"" help:"Why, recorded with the ballots and in the audit log"`
+}
+
+type VotesRestoreCmd struct {
+	Poll   PollRef `arg:"" help:"Poll ID or name"`
+	Ballot int64   `arg:"" help:"Ballot number (see votes ballots)"`
+}
+
+type SettingsCmd struct {
+	Get SettingsGetCmd `cmd:"" help:"Show one setting, or all of them"`
+	Set SettingsSetCmd `cmd:"" help:"Change a setting"`
+}
+
+type SettingsGetCmd struct {
+	Key string `arg:"" optional:"" help:"Setting key (omit to list all)"`
+}
+
+type SettingsSetCmd struct {
+	Key   string `arg:"" help:"Setting key"`
+	Value string `arg:"" help:"New value"`
+}
+
+type SeasonCmd struct {
+	Show     SeasonShowCmd     `cmd:"" help:"Show the season leaderboard and its events"`
+	Finalize SeasonFinalizeCmd `cmd:"" help:"Score finished polls toward the season and archive them"`
+	Remove   SeasonRemoveCmd   `cmd:"" help:"Take a finalized event out of the season"`
+}
+
+type SeasonShowCmd struct{}
+
+type SeasonFinalizeCmd struct {
+	Name string `help:"Event name (default: the event_name setting)"`
+}
+
+type SeasonRemoveCmd struct {
+	Event int64 `arg:"" help:"Event ID, as listed by season show"`
+}
+
+type AuditCmd struct {
+	Sample AuditSampleCmd `cmd:"" help:"Pick random ballots to verify against voter receipts"`
+}
+
+type AuditSampleCmd struct {
+	Poll  PollRef `arg:"" help:"Poll ID or name"`
+	N     int     `name:"n" help:"Number of ballots to sample" default:"10"`
+	Seed  string  `help:"Seed for the random pick; the same seed picks the same ballots (random if omitted)"`
+	Names bool    `help:"Show voter nicknames next to receipts"`
+}
+
+type DatabaseCmd struct {
+	Check  DatabaseCheckCmd  `cmd:"" help:"Report damage, broken references and invalid ballots"`
+	Export DatabaseExportCmd `cmd:"" help:"Write the database as a JSON archive"`
+	Import DatabaseImportCmd `cmd:"" help:"Load a JSON archive into an empty database"`
+}
+
+type LintCmd struct{}
+
+type PublishCmd struct {
+	Out string `short:"o" help:"Directory to write the site to" default:"site" type:"path"`
+}
+
+type PurgeCmd struct {
+	Days   int  `help:"Purge polls closed at least this many days ago, instead of the ballot_retention_days setting"`
+	DryRun bool `help:"List the polls that would be purged without deleting anything"`
+}
+
+type DatabaseCheckCmd struct {
+	Repair bool `help:"Delete or clear the rows at fault"`
+}
+
+type DatabaseExportCmd struct {
+	File string `arg:"" optional:"" help:"File to write (stdout if omitted or -)" type:"path"`
+}
+
+type DatabaseImportCmd struct {
+	File string `arg:"" help:"Archive to read (- for stdin)"`
+}
+
+type VotersCmd struct {
+	Forget VotersForgetCmd `cmd:"" help:"Delete all ballots cast by a voter and anonymize their audit trail"`
+	Import VotersImportCmd `cmd:"" help:"Sync the attendee roster from the registration system"`
+	Tag    VotersTagCmd    `cmd:"" help:"Set an attendee's roster tags"`
+}
+
+type VotersImportCmd struct {
+	Source string `arg:"" help:"Where to read the roster: a CSV file, or SOURCE=TARGET (e.g. csv=registrations.csv)"`
+}
+
+type VotersTagCmd struct {
+	Nickname string   `arg:"" help:"Attendee nickname (case-insensitive)"`
+	Tags     []string `arg:"" optional:"" help:"Tags to give them, replacing any they have (none to clear)"`
+}
+
+type VotersForgetCmd struct {
+	Nickname string `arg:"" help:"Voter nickname (case-insensitive)"`
 }
 
 // AfterApply opens database connection
-func (c *CLI) AfterApply(ctx *Context) error {
+func (c *CLI) AfterApply(ctx *Context, kctx *kong.Context) error {
+	ctx.Quiet = c.Quiet
+	if c.Quiet {
+		db.QuietMigrations()
+	}
+
+	// Commands like completion must work without creating votigo.db
+	if _, ok := kctx.Selected().Target.Addr().Interface().(interface{ skipsDatabase() }); ok {
+		return nil
+	}
+
+	notifier, err := c.notifier()
+	if err != nil {
+		return invalid(err)
+	}
+
 	conn, err := db.Open(c.DB)
 	if err != nil {
-		return err
+		return dbError(err)
 	}
 
 	if err := db.Migrate(conn); err != nil {
 		conn.Close()
-		return err
+		return dbError(err)
+	}
+
+	nicknames, err := db.SetupEncryption(context.Background(), conn, c.DBKey)
+	if err != nil {
+		conn.Close()
+		if errors.Is(err, db.ErrDatabaseEncrypted) || errors.Is(err, db.ErrWrongPassphrase) {
+			return invalid(err)
+		}
+		return dbError(err)
 	}
 
 	ctx.DB = conn
+	ctx.DBPath = c.DB
 	ctx.Queries = db.New(conn)
+	ctx.Nicknames = nicknames
+	ctx.Notifier = notifier
+	ctx.loadNotifier = c.notifier
 	return nil
 }
+
+// notifier builds the announcement notifier from --notify, --notify-file
+// and --slack-webhook, or returns nil if none are configured
+func (c *CLI) notifier() (notify.Notifier, error) {
+	var all notify.Multi
+	if c.SlackWebhook != "" {
+		all = append(all, notify.NewSlack(c.SlackWebhook))
+	}
+	specs := c.Notify
+	if c.NotifyFile != "" {
+		lines, err := readNotifyFile(c.NotifyFile)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(slices.Clip(specs), lines...)
+	}
+	for _, spec := range specs {
+		n, err := notify.New(spec)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, n)
+	}
+
+	switch len(all) {
+	case 0:
+		return nil, nil
+	case 1:
+		return all[0], nil
+	}
+	return all, nil
+}
+
+// readNotifyFile reads the BACKEND=TARGET lines of a --notify-file,
+// skipping blank lines and # comments
+func readNotifyFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var specs []string
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			specs = append(specs, line)
+		}
+	}
+	return specs, nil
+}
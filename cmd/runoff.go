@@ -0,0 +1,81 @@
+// cmd/runoff.go
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func (c *RunoffCmd) Run(ctx *Context) error {
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
+	if err != nil {
+		return lookupError("poll", err)
+	}
+
+	existing, err := ctx.Queries.GetRunoff(context.Background(), sql.NullInt64{Int64: cat.ID, Valid: true})
+	if err == nil {
+		if ctx.Quiet {
+			fmt.Println(existing.ID)
+			return nil
+		}
+		fmt.Printf("%s already has a runoff: #%d %s (%s)\n", cat.Name, existing.ID, existing.Name, existing.Status)
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return dbError(err)
+	}
+
+	reason, options, err := ctx.Queries.RunoffCandidates(context.Background(), cat)
+	if err != nil {
+		return dbError(err)
+	}
+	if reason == "" {
+		return invalidf("no runoff needed: %s is not a closed single-choice poll that ended in a tie or without a majority", cat.Name)
+	}
+
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback()
+
+	runoff, added, err := ctx.Queries.WithTx(tx).CreateRunoff(context.Background(), cat, options)
+	if err != nil {
+		return dbError(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return dbError(err)
+	}
+
+	ctx.audit(db.AuditCategoryRunoff, cat.ID, fmt.Sprintf("%s (%s)", runoff.Name, reason))
+	ctx.audit(db.AuditCategoryCreate, runoff.ID, runoff.Name)
+	for _, o := range added {
+		ctx.audit(db.AuditOptionSeed, runoff.ID, fmt.Sprintf("%s (from %s)", o.Name, cat.Name))
+	}
+
+	if ctx.Quiet {
+		fmt.Println(runoff.ID)
+		return nil
+	}
+	fmt.Printf("%s: %s\n", cat.Name, reason)
+	fmt.Printf("Created poll #%d: %s (draft) with %s\n", runoff.ID, runoff.Name, tally.Plural(int64(len(added)), "option"))
+	for _, o := range added {
+		fmt.Printf("  %s\n", o.Name)
+	}
+	return nil
+}
+
+func (c *RunoffCmd) Help() string {
+	return `A closed single-choice poll needs a runoff when options tie for first, or
+when the leader has no majority (the runoff is then between the top two).
+The runoff is created as a draft; open it when ready.
+
+Examples:
+  votigo runoff "best game"
+  votigo open $(votigo -q runoff 1)`
+}
@@ -0,0 +1,131 @@
+// cmd/season.go
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func (c *SeasonShowCmd) Run(ctx *Context) error {
+	events, standings, err := ctx.Queries.Season(context.Background())
+	if err != nil {
+		return dbError(err)
+	}
+	season, err := ctx.Queries.SettingValue(context.Background(), db.SettingSeason)
+	if err != nil {
+		return dbError(err)
+	}
+
+	if len(events) == 0 {
+		ctx.say("No events finalized yet; run `votigo season finalize` when an event ends\n")
+		return nil
+	}
+	if !ctx.Quiet {
+		fmt.Printf("%s: %s\n\n", cmp.Or(season, "Season"), tally.Plural(int64(len(events)), "event"))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := []string{"RANK", "NAME"}
+	for _, e := range events {
+		header = append(header, strings.ToUpper(e.Name))
+	}
+	fmt.Fprintln(w, strings.Join(append(header, "WINS", "POINTS"), "\t"))
+	for _, s := range standings {
+		row := []string{fmt.Sprint(s.Rank), s.Name}
+		for _, p := range s.Events {
+			row = append(row, fmt.Sprint(p))
+		}
+		row = append(row, fmt.Sprint(s.Wins), fmt.Sprint(s.Points))
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !ctx.Quiet {
+		fmt.Println("\nEvents:")
+		for _, e := range events {
+			finalized := ""
+			if e.FinalizedAt.Valid {
+				finalized = " (finalized " + e.FinalizedAt.Time.Local().Format("Jan 2 2006") + ")"
+			}
+			fmt.Printf("  #%d %s%s\n", e.ID, e.Name, finalized)
+		}
+	}
+	return nil
+}
+
+func (c *SeasonShowCmd) Help() string {
+	return `Names are matched across events ignoring case. Ranked by points, then by
+first places.
+
+Examples:
+  votigo season show`
+}
+
+func (c *SeasonFinalizeCmd) Run(ctx *Context) error {
+	values, err := ctx.Queries.SettingValues(context.Background())
+	if err != nil {
+		return dbError(err)
+	}
+	name := cmp.Or(strings.TrimSpace(c.Name), values[db.SettingEventName])
+	if name == "" {
+		return invalidf("name the event with --name, or set event_name")
+	}
+	points, err := db.ParseSeasonPoints(values[db.SettingSeasonPoints])
+	if err != nil {
+		return invalid(err)
+	}
+
+	event, polls, err := db.FinalizeEvent(context.Background(), ctx.DB, name, points, db.ActorCLI)
+	if err != nil {
+		return dbError(err)
+	}
+
+	if ctx.Quiet {
+		fmt.Println(event.ID)
+		return nil
+	}
+	fmt.Printf("Finalized event #%d: %s, scoring %s\n", event.ID, event.Name, tally.Plural(int64(len(polls)), "poll"))
+	for _, cat := range polls {
+		fmt.Printf("  %s\n", cat.Name)
+	}
+	return nil
+}
+
+func (c *SeasonFinalizeCmd) Help() string {
+	return `Records where every option placed in each closed or archived poll not
+scored yet, and the points the season_points setting awards for that place,
+then archives the polls. Polls decided by a runoff are scored through their
+runoff. Polls still open are scored with the event they finish in.
+
+Examples:
+  votigo settings set season "Palm Arcade 2026"
+  votigo season finalize --name "Winter LAN"`
+}
+
+func (c *SeasonRemoveCmd) Run(ctx *Context) error {
+	events, err := ctx.Queries.ListSeasonEvents(context.Background())
+	if err != nil {
+		return dbError(err)
+	}
+	i := slices.IndexFunc(events, func(e db.SeasonEvent) bool { return e.ID == c.Event })
+	if i < 0 {
+		return notFound("event #%d not found (see `votigo season show`)", c.Event)
+	}
+	if _, err := ctx.Queries.DeleteSeasonEvent(context.Background(), c.Event); err != nil {
+		return dbError(err)
+	}
+
+	ctx.audit(db.AuditSeasonRemove, 0, events[i].Name)
+	ctx.say("Removed event #%d: %s. Its polls will be scored with the next event finalized.\n", c.Event, events[i].Name)
+	return nil
+}
@@ -2,14 +2,377 @@
 package cmd
 
 import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/palm-arcade/votigo/internal/accesslog"
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/oembed"
 	"github.com/palm-arcade/votigo/internal/web"
 )
 
+// site is what differs between the events one serve process hosts
+type site struct {
+	conn          *sql.DB
+	path          string
+	nicknames     *db.NicknameCipher
+	adminPassword string
+	adminHash     *db.PasswordHash // checked instead of adminPassword when set
+	ui            string
+	highContrast  bool
+	soundDir      string
+	imageDir      string
+	backupDir     string
+}
+
+// tenant is an event in a --tenants file, served instead of --db to the
+// host names it lists
+type tenant struct {
+	Hosts         []string `json:"hosts"`
+	DB            string   `json:"db"`
+	DBKey         string   `json:"db_key"`
+	AdminPassword string   `json:"admin_password"`
+	UI            string   `json:"ui"`
+	HighContrast  bool     `json:"high_contrast"`
+	SoundDir      string   `json:"sound_dir"`
+	ImageDir      string   `json:"image_dir"`
+}
+
 func (c *ServeCmd) Run(ctx *Context) error {
-	server, err := web.NewServer(ctx.DB, c.AdminPassword, web.UIMode(c.UI))
+	var shared []web.Option
+	if c.AccessLog != "" {
+		accessLog, err := accesslog.Open(c.AccessLog, c.AccessLogMaxSize<<20, c.AccessLogKeep)
+		if err != nil {
+			return err
+		}
+		defer accessLog.Close()
+		shared = append(shared, web.WithAccessLog(accessLog))
+	}
+
+	def := site{
+		conn:          ctx.DB,
+		path:          ctx.DBPath,
+		nicknames:     ctx.Nicknames,
+		adminPassword: c.AdminPassword,
+		ui:            c.UI,
+		highContrast:  c.HighContrast,
+		soundDir:      c.SoundDir,
+		imageDir:      c.ImageDir,
+		backupDir:     c.BackupDir,
+	}
+	if c.AdminPassword == "" {
+		hash, err := bootstrapAdmin(ctx)
+		if err != nil {
+			return err
+		}
+		def.adminHash = &hash
+	}
+	server, err := c.newServer(ctx, def, shared)
 	if err != nil {
 		return err
 	}
 
-	return server.Start(c.Port)
+	// Ctrl-C or a service manager stopping us drains requests first
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if c.Tenants == "" {
+		go reloadOnHangup(sigCtx, ctx, []*web.Server{server})
+		return server.Start(sigCtx, c.Port)
+	}
+
+	tenants, err := c.loadTenants(ctx, server, shared)
+	if err != nil {
+		return err
+	}
+	go reloadOnHangup(sigCtx, ctx, tenants.Servers())
+	return tenants.Start(sigCtx, c.Port)
+}
+
+// reloadOnHangup reloads servers on every SIGHUP until ctx is done: settings,
+// templates from --template-dir, and the notifiers from --notify and
+// --notify-file. Nothing restarts, so voters' pages and event streams stay
+// connected through it.
+func reloadOnHangup(ctx context.Context, cli *Context, servers []*web.Server) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+
+		log.Print("Reloading on SIGHUP")
+		notifier, err := cli.loadNotifier()
+		if err != nil {
+			log.Printf("Keeping the current notifiers: %v", err)
+		}
+		for _, s := range servers {
+			if err == nil {
+				s.SetNotifier(notifier)
+			}
+			if err := s.Reload(); err != nil {
+				log.Printf("Keeping the current templates: %v", err)
+			}
+		}
+	}
+}
+
+// newServer builds the web server for one event, adding the options every
+// event shares
+func (c *ServeCmd) newServer(ctx *Context, s site, shared []web.Option) (*web.Server, error) {
+	opts := append([]web.Option{
+		web.WithHighContrast(s.highContrast),
+		web.WithNicknameCipher(s.nicknames),
+		web.WithNotifier(ctx.Notifier),
+		web.WithTimeouts(web.Timeouts{Handler: c.RequestTimeout, Drain: c.DrainTimeout}),
+		web.WithJobs(web.Jobs{
+			CleanupEvery: c.CleanupEvery,
+			ArchiveEvery: web.DefaultJobs.ArchiveEvery,
+			ArchiveAfter: c.ArchiveAfter,
+			PurgeEvery:   web.DefaultJobs.PurgeEvery,
+			VacuumEvery:  c.VacuumEvery,
+			BackupEvery:  c.BackupEvery,
+			BackupDir:    s.backupDir,
+			BackupKeep:   c.BackupKeep,
+		}),
+	}, shared...)
+	if s.adminHash != nil {
+		opts = append(opts, web.WithAdminPasswordHash(*s.adminHash))
+	}
+	if c.TemplateDir != "" {
+		opts = append(opts, web.WithTemplateDir(c.TemplateDir))
+	}
+	if s.soundDir != "" {
+		opts = append(opts, web.WithSoundDir(s.soundDir))
+	}
+	if s.imageDir != "" {
+		opts = append(opts, web.WithImageDir(s.imageDir))
+	}
+	if c.MediaPreviews {
+		opts = append(opts, web.WithMediaPreviews(oembed.New()))
+	}
+
+	// Projector refreshes read through their own handle so they never wait
+	// on ballots being written
+	if c.ReadConn {
+		if err := db.EnableWAL(s.conn); err != nil {
+			return nil, dbError(err)
+		}
+		reads, err := db.OpenReadOnly(s.path)
+		if err != nil {
+			return nil, dbError(err)
+		}
+		opts = append(opts, web.WithReadDB(reads))
+	}
+
+	return web.NewServer(s.conn, s.adminPassword, web.UIMode(s.ui), opts...)
+}
+
+// loadTenants opens every event in the --tenants file and routes their host
+// names to them, with def serving the rest. Databases stay open until the
+// process exits.
+func (c *ServeCmd) loadTenants(ctx *Context, def *web.Server, shared []web.Option) (*web.Tenants, error) {
+	data, err := os.ReadFile(c.Tenants)
+	if err != nil {
+		return nil, err
+	}
+	var list []tenant
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, invalidf("%s: %v", c.Tenants, err)
+	}
+
+	// Relative paths are relative to the file, so it can move with them
+	path := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(filepath.Dir(c.Tenants), p)
+	}
+
+	tenants := web.NewTenants(def)
+	claimed := make(map[string]bool)
+	for i, t := range list {
+		switch {
+		case len(t.Hosts) == 0:
+			return nil, invalidf("%s: event %d lists no hosts", c.Tenants, i+1)
+		case t.DB == "":
+			return nil, invalidf("%s: event %d has no db", c.Tenants, i+1)
+		case t.AdminPassword == "":
+			return nil, invalidf("%s: event %d has no admin_password", c.Tenants, i+1)
+		case t.UI != "" && t.UI != string(web.UIModeModern) && t.UI != string(web.UIModeLegacy):
+			return nil, invalidf("%s: event %d: ui must be modern or legacy", c.Tenants, i+1)
+		}
+		for _, host := range t.Hosts {
+			// Hosts route ignoring case and port (see web.TenantHost), so
+			// Vote.LAN and vote.lan:8080 are the same host
+			if claimed[web.TenantHost(host)] {
+				return nil, invalidf("%s: %s is listed twice", c.Tenants, host)
+			}
+			claimed[web.TenantHost(host)] = true
+		}
+
+		s := site{
+			path:          path(t.DB),
+			adminPassword: t.AdminPassword,
+			ui:            t.UI,
+			highContrast:  t.HighContrast,
+			soundDir:      path(t.SoundDir),
+			imageDir:      path(t.ImageDir),
+		}
+		if s.ui == "" {
+			s.ui = c.UI
+		}
+		if c.BackupDir != "" {
+			s.backupDir = filepath.Join(c.BackupDir, t.Hosts[0])
+		}
+		if s.conn, s.nicknames, err = openTenant(s.path, t.DBKey); err != nil {
+			return nil, fmt.Errorf("%s: %w", t.Hosts[0], err)
+		}
+		server, err := c.newServer(ctx, s, shared)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.Hosts[0], err)
+		}
+		tenants.Add(server, t.Hosts...)
+	}
+	return tenants, nil
+}
+
+// openTenant opens and migrates an event's database the way the CLI opens
+// --db
+func openTenant(path, key string) (*sql.DB, *db.NicknameCipher, error) {
+	conn, err := db.Open(path)
+	if err != nil {
+		return nil, nil, dbError(err)
+	}
+	if err := db.Migrate(conn); err != nil {
+		conn.Close()
+		return nil, nil, dbError(err)
+	}
+	nicknames, err := db.SetupEncryption(context.Background(), conn, key)
+	if err != nil {
+		conn.Close()
+		if errors.Is(err, db.ErrDatabaseEncrypted) || errors.Is(err, db.ErrWrongPassphrase) {
+			return nil, nil, invalid(err)
+		}
+		return nil, nil, dbError(err)
+	}
+	return conn, nicknames, nil
+}
+
+// firstRunEventName names the event a first run sets up, until an admin
+// renames it on the settings page
+const firstRunEventName = "LAN Party"
+
+// errNotFirstRun refuses to make up an admin password for a database that
+// was set up some other way
+var errNotFirstRun = errors.New("--admin-password is required: this database already has polls but no stored admin password")
+
+// bootstrapAdmin returns the stored admin password hash, for serving
+// without --admin-password. On the first run against an empty database
+// there is none yet, so it makes up a password, prints it this once, stores
+// only its hash and names the event: a fresh container comes up ready to
+// use with no flags at all.
+func bootstrapAdmin(ctx *Context) (db.PasswordHash, error) {
+	bg := context.Background()
+	hash, err := ctx.Queries.AdminPassword(bg)
+	if !errors.Is(err, db.ErrNotFound) {
+		return hash, dbError(err)
+	}
+
+	password := rand.Text()[:16]
+	generated, err := db.HashPassword(password)
+	if err != nil {
+		return hash, err
+	}
+	created := false
+	err = db.InTx(bg, ctx.DB, func(q *db.Queries) error {
+		// Another server sharing the database may have got here first
+		if hash, err = q.AdminPassword(bg); !errors.Is(err, db.ErrNotFound) {
+			return err
+		}
+		empty, err := q.Empty(bg)
+		if err != nil {
+			return err
+		}
+		if !empty {
+			return invalid(errNotFirstRun)
+		}
+		hash, created = generated, true
+		if err := q.SetAdminPassword(bg, hash); err != nil {
+			return err
+		}
+		name, err := q.SettingValue(bg, db.SettingEventName)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			if _, err := q.SetSetting(bg, db.SettingEventName, firstRunEventName); err != nil {
+				return err
+			}
+		}
+		return q.RecordAudit(bg, db.ActorServer, db.AuditAdminPassword, 0, "generated on first run")
+	})
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		return hash, err
+	}
+	if err != nil {
+		return hash, dbError(err)
+	}
+	if !created {
+		return hash, nil
+	}
+
+	fmt.Println("First run: created the admin login for this database")
+	fmt.Println("  user:     admin")
+	fmt.Printf("  password: %s\n", password)
+	fmt.Println("This is the only time the password is shown; only its hash is stored.")
+	fmt.Println("Start with --admin-password (or VOTIGO_ADMIN_PASSWORD) to use your own instead.")
+	return hash, nil
+}
+
+func (c *ServeCmd) Help() string {
+	return `Without --admin-password, the first start on an empty database makes up an
+admin password, prints it once and stores its hash in the database, so later
+starts need no password flag either. Giving --admin-password always wins.
+
+Examples:
+  votigo serve
+  votigo serve --admin-password hunter2
+  votigo serve --port 8080 --ui legacy --admin-password hunter2
+  votigo serve --high-contrast --admin-password hunter2
+  votigo serve --request-timeout 30s --drain-timeout 5s --admin-password hunter2
+  votigo serve --access-log /var/log/votigo/access.log --admin-password hunter2
+  votigo serve --backup-dir /var/backups/votigo --backup-every 30m --admin-password hunter2
+  votigo serve --no-read-conn --admin-password hunter2
+  votigo serve --archive-after 0 --vacuum-every 0 --admin-password hunter2
+  votigo serve --sound-dir ./sounds --admin-password hunter2
+  votigo serve --tenants rooms.json --admin-password hunter2
+  votigo serve --template-dir ./templates --admin-password hunter2
+
+Sending the server SIGHUP (kill -HUP) reloads settings, the --notify-file
+and, with --template-dir, templates, without dropping connected clients.
+
+A --tenants file lists more events to serve from the same port, each with
+its own database and admin password, picked by the host name voters use.
+Other host names get the --db event. Paths are relative to the file.
+
+  [
+    {"hosts": ["retro.lan"], "db": "retro.db", "admin_password": "pac",
+     "ui": "legacy", "high_contrast": true},
+    {"hosts": ["fps.lan", "10.0.0.3"], "db": "fps.db", "admin_password": "rail",
+     "db_key": "optional passphrase", "sound_dir": "fps-sounds", "image_dir": "fps-images"}
+  ]`
 }
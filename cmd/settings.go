@@ -0,0 +1,70 @@
+// cmd/settings.go
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+func (c *SettingsGetCmd) Run(ctx *Context) error {
+	if c.Key != "" {
+		value, err := ctx.Queries.SettingValue(context.Background(), c.Key)
+		if errors.Is(err, db.ErrUnknownSetting) {
+			return notFound("%w (see `votigo settings get`)", err)
+		}
+		if err != nil {
+			return dbError(err)
+		}
+		fmt.Println(value)
+		return nil
+	}
+
+	values, err := ctx.Queries.SettingValues(context.Background())
+	if err != nil {
+		return dbError(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tTYPE\tDESCRIPTION")
+	for _, spec := range db.SettingSpecs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", spec.Key, values[spec.Key], spec.Kind, spec.Help)
+	}
+	return w.Flush()
+}
+
+func (c *SettingsGetCmd) Help() string {
+	return `Examples:
+  votigo settings get
+  votigo settings get high_contrast`
+}
+
+func (c *SettingsSetCmd) Run(ctx *Context) error {
+	spec, err := db.LookupSetting(c.Key)
+	if err != nil {
+		return notFound("%w (see `votigo settings get`)", err)
+	}
+	if _, err := spec.Parse(c.Value); err != nil {
+		return invalid(err)
+	}
+
+	value, err := ctx.Queries.SetSetting(context.Background(), c.Key, c.Value)
+	if err != nil {
+		return dbError(err)
+	}
+
+	ctx.audit(db.AuditSettingUpdate, 0, c.Key+"="+value)
+	ctx.say("Set %s = %s\n", c.Key, value)
+	return nil
+}
+
+func (c *SettingsSetCmd) Help() string {
+	return `A running server picks up the change within a few seconds.
+
+Examples:
+  votigo settings set high_contrast on`
+}
@@ -0,0 +1,204 @@
+// cmd/simulate.go
+package cmd
+
+import (
+	"cmp"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	mathrand "math/rand/v2"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+// simulatedMethod counts one kind of ballot a poll could collect
+type simulatedMethod struct {
+	Name    string
+	Ballots string // the vote type whose ballots it counts
+	Count   tally.Method
+}
+
+// simulatedMethods are compared by `votigo simulate`: what each vote type
+// records, then the recount methods on ranked ballots
+func simulatedMethods(maxRank int64) []simulatedMethod {
+	return []simulatedMethod{
+		{"single", "single", tally.Simple},
+		{"approval", "approval", tally.Simple},
+		{"ranked", "ranked", tally.Points(maxRank)},
+		{"borda", "ranked", tally.Borda},
+		{"irv", "ranked", tally.IRV},
+		{"condorcet", "ranked", tally.Condorcet},
+		{"elo", "ranked", tally.Elo},
+	}
+}
+
+func (c *SimulateCmd) skipsDatabase() {}
+
+func (c *SimulateCmd) Run() error {
+	if c.Voters < 1 {
+		return invalidf("--voters must be at least 1")
+	}
+	if c.Options < 2 || c.Options > 26 {
+		return invalidf("--options must be between 2 and 26")
+	}
+	if c.MaxRank < 1 {
+		return invalidf("--max-rank must be at least 1")
+	}
+
+	// Print the seed so a surprising run can be repeated
+	seed := c.Seed
+	if seed == "" {
+		seed = rand.Text()[:8]
+	}
+	key := sha256.Sum256([]byte(seed))
+	rng := mathrand.New(mathrand.NewChaCha8(key))
+
+	options := make([]tally.Option, c.Options)
+	for i := range options {
+		options[i] = tally.Option{ID: int64(i + 1), Name: "Option " + string(rune('A'+i))}
+	}
+	names := make(map[int64]string, len(options))
+	for _, o := range options {
+		names[o.ID] = o.Name
+	}
+	preferences := simulatePreferences(rng, c.Distribution, c.Voters, c.Options)
+	ballots := map[string][]tally.Ballot{}
+	for _, p := range preferences {
+		ballots["single"] = append(ballots["single"], tally.Ballot{p[0]: 1})
+		approved := tally.Ballot{}
+		for _, id := range p[:1+rng.IntN(max(c.Options/2, 1))] {
+			approved[id] = 1
+		}
+		ballots["approval"] = append(ballots["approval"], approved)
+		ranked := tally.Ballot{}
+		for i, id := range p[:min(int(c.MaxRank), len(p))] {
+			ranked[id] = int64(i + 1)
+		}
+		ballots["ranked"] = append(ballots["ranked"], ranked)
+	}
+
+	fmt.Printf("Simulated %s choosing among %d options, %s preferences\n", tally.Plural(int64(c.Voters), "voter"), c.Options, c.Distribution)
+	fmt.Printf("Seed: %s\n\n", seed)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tBALLOTS\tWINNER\t\tRUNNER-UP\t")
+	winners := map[int64][]string{}
+	var order []int64
+	var notes []string
+	for _, m := range simulatedMethods(c.MaxRank) {
+		result := m.Count(options, ballots[m.Ballots])
+		first, second := result.Ranking[0], result.Ranking[1]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", m.Name, m.Ballots,
+			names[first.OptionID], first.Label, names[second.OptionID], second.Label)
+
+		if _, ok := winners[first.OptionID]; !ok {
+			order = append(order, first.OptionID)
+		}
+		winners[first.OptionID] = append(winners[first.OptionID], m.Name)
+		if m.Name == "condorcet" {
+			notes = append(notes, result.Notes...)
+		}
+	}
+	w.Flush()
+
+	fmt.Println()
+	for _, note := range notes {
+		fmt.Println(note)
+	}
+	if len(order) == 1 {
+		fmt.Printf("Every method agrees: %s wins\n", names[order[0]])
+		return nil
+	}
+	fmt.Println("Methods disagree:")
+	for _, id := range order {
+		fmt.Printf("  %s wins under %s\n", names[id], strings.Join(winners[id], ", "))
+	}
+	return nil
+}
+
+func (c *SimulateCmd) Help() string {
+	return `Generates ballots for an imaginary poll and counts them every way votigo
+can, to show how much the choice of vote type matters. Nothing is stored.
+
+Each voter ranks every option. Single-choice ballots keep their favourite,
+approval ballots approve their top one or more, and ranked ballots keep their
+top --max-rank choices.
+
+  uniform    every order is equally likely: a close, noisy race
+  zipf       option A is the favourite, B half as popular, C a third, ...
+  polarized  two camps near either end of a spectrum of options, so the
+             middle options are many voters' second choice
+
+Examples:
+  votigo simulate --voters 200 --distribution zipf
+  votigo simulate --distribution polarized --options 5 --seed demo`
+}
+
+// simulatePreferences ranks every option for each voter, best first
+func simulatePreferences(rng *mathrand.Rand, distribution string, voters, n int) [][]int64 {
+	// Zipf popularity: the k-th option is 1/k as popular as the first
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1 / float64(i+1)
+	}
+
+	prefs := make([][]int64, voters)
+	for v := range prefs {
+		switch distribution {
+		case "zipf":
+			prefs[v] = drawRanking(rng, weights)
+		case "polarized":
+			// Options sit evenly along a spectrum and voters cluster near
+			// either end, a slightly bigger camp at the start. Each voter
+			// ranks options by how close they sit.
+			ideal := 0.2 + rng.NormFloat64()*0.15
+			if rng.Float64() >= 0.55 {
+				ideal = 0.8 + rng.NormFloat64()*0.15
+			}
+			prefs[v] = make([]int64, n)
+			for i := range prefs[v] {
+				prefs[v][i] = int64(i + 1)
+			}
+			distance := func(id int64) float64 { return math.Abs(float64(id-1)/float64(n-1) - ideal) }
+			slices.SortFunc(prefs[v], func(a, b int64) int { return cmp.Compare(distance(a), distance(b)) })
+		default:
+			prefs[v] = make([]int64, n)
+			for i, p := range rng.Perm(n) {
+				prefs[v][i] = int64(p + 1)
+			}
+		}
+	}
+	return prefs
+}
+
+// drawRanking orders options by drawing them one at a time, each in
+// proportion to its weight among those left (a Plackett-Luce ranking).
+// Option IDs are positions in weights, from 1.
+func drawRanking(rng *mathrand.Rand, weights []float64) []int64 {
+	left := slices.Clone(weights)
+	ranking := make([]int64, 0, len(weights))
+	for range weights {
+		var total float64
+		for _, w := range left {
+			total += w
+		}
+		pick, x := -1, rng.Float64()*total
+		for i, w := range left {
+			if w == 0 {
+				continue
+			}
+			pick = i
+			if x -= w; x < 0 {
+				break
+			}
+		}
+		left[pick] = 0
+		ranking = append(ranking, int64(pick+1))
+	}
+	return ranking
+}
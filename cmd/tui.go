@@ -0,0 +1,285 @@
+// cmd/tui.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/notify"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func (c *TuiCmd) Run(ctx *Context) error {
+	m := &tuiModel{ctx: ctx, refresh: c.Refresh, showResults: true}
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+func (c *TuiCmd) Help() string {
+	return `Examples:
+  votigo tui
+  votigo tui --refresh 5s`
+}
+
+var (
+	tuiTitle    = lipgloss.NewStyle().Bold(true)
+	tuiHeader   = lipgloss.NewStyle().Bold(true).Underline(true)
+	tuiSelected = lipgloss.NewStyle().Reverse(true)
+	tuiDim      = lipgloss.NewStyle().Faint(true)
+	tuiError    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	tuiBar      = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+	tuiStatus   = map[string]lipgloss.Style{
+		"open":   lipgloss.NewStyle().Foreground(lipgloss.Color("10")),
+		"closed": lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+		"draft":  lipgloss.NewStyle().Faint(true),
+	}
+)
+
+// tuiModel is the state of the `votigo tui` dashboard. It polls the database
+// rather than subscribing to changes, since votes arrive through the web
+// server in another process.
+type tuiModel struct {
+	ctx         *Context
+	refresh     time.Duration
+	polls       []db.ListCategoryVoteCountsRow
+	selected    int64 // poll ID, so the cursor survives reordering
+	results     notify.Event
+	showResults bool
+	busy        bool
+	message     string
+	err         error // from the last action
+	loadErr     error
+	loadedAt    time.Time
+}
+
+// tuiDataMsg carries a fresh snapshot of the polls and the selected tally
+type tuiDataMsg struct {
+	polls   []db.ListCategoryVoteCountsRow
+	results notify.Event
+	err     error
+}
+
+// tuiActionMsg reports the outcome of an open/close/reopen keypress
+type tuiActionMsg struct {
+	message string
+	err     error
+}
+
+type tuiTickMsg time.Time
+
+func (m *tuiModel) Init() tea.Cmd {
+	return tea.Batch(m.load(), m.tick())
+}
+
+func (m *tuiModel) tick() tea.Cmd {
+	return tea.Tick(m.refresh, func(t time.Time) tea.Msg { return tuiTickMsg(t) })
+}
+
+// load reads the poll list and, when the results pane is shown, the tally
+// for the selected poll
+func (m *tuiModel) load() tea.Cmd {
+	selected, showResults := m.selected, m.showResults
+	return func() tea.Msg {
+		ctx := context.Background()
+		polls, err := m.ctx.Queries.ListCategoryVoteCounts(ctx)
+		if err != nil {
+			return tuiDataMsg{err: err}
+		}
+		msg := tuiDataMsg{polls: polls}
+
+		if selected == 0 && len(polls) > 0 {
+			selected = polls[0].ID
+		}
+		if showResults && selected != 0 {
+			cat, err := m.ctx.Queries.Category(ctx, selected)
+			if err == nil {
+				msg.results, err = notify.NewEvent(ctx, m.ctx.Queries, notify.EventResults, cat)
+			}
+			msg.err = err
+		}
+		return msg
+	}
+}
+
+// action runs a lifecycle change on the selected poll and announces it like
+// the equivalent CLI command
+func (m *tuiModel) action(key string) tea.Cmd {
+	id := m.selected
+	return func() tea.Msg {
+		var (
+			cat    db.Category
+			seeded []db.SeededPoll
+			err    error
+			verb   string
+			events []notify.EventType
+		)
+		switch key {
+		case "o":
+			cat, err = openPoll(m.ctx, id)
+			verb, events = "Opened", []notify.EventType{notify.EventOpened}
+		case "c":
+			cat, seeded, err = closePoll(m.ctx, id)
+			verb, events = "Closed", []notify.EventType{notify.EventClosed, notify.EventResults}
+		case "r":
+			cat, err = reopenPoll(m.ctx, id)
+			verb, events = "Reopened", []notify.EventType{notify.EventOpened}
+		}
+		if err != nil {
+			return tuiActionMsg{err: err}
+		}
+		// The status change stands even if announcing it fails
+		msg := tuiActionMsg{message: fmt.Sprintf("%s voting for: %s", verb, cat.Name)}
+		for _, p := range seeded {
+			msg.message += fmt.Sprintf("; seeded %s into %s", tally.Plural(int64(len(p.Options)), "option"), p.Category.Name)
+		}
+		msg.err = m.ctx.sendEvents(cat, events...)
+		return msg
+	}
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			m.move(-1)
+			return m, m.load()
+		case "down", "j":
+			m.move(1)
+			return m, m.load()
+		case "enter", "tab":
+			m.showResults = !m.showResults
+			return m, m.load()
+		case "o", "c", "r":
+			if m.selected == 0 || m.busy {
+				return m, nil
+			}
+			m.busy = true
+			m.message, m.err = "Working...", nil
+			return m, m.action(msg.String())
+		}
+
+	case tuiTickMsg:
+		return m, tea.Batch(m.load(), m.tick())
+
+	case tuiDataMsg:
+		m.loadErr = msg.err
+		if msg.err != nil {
+			return m, nil
+		}
+		m.polls, m.loadedAt = msg.polls, time.Now()
+		if m.index() < 0 {
+			m.selected = 0
+			if len(m.polls) > 0 {
+				m.selected = m.polls[0].ID
+			}
+		}
+		// A load started before the cursor moved carries another poll's tally
+		if msg.results.CategoryID == m.selected {
+			m.results = msg.results
+		}
+
+	case tuiActionMsg:
+		m.busy = false
+		m.message, m.err = msg.message, msg.err
+		return m, m.load()
+	}
+	return m, nil
+}
+
+// index returns the position of the selected poll, or -1
+func (m *tuiModel) index() int {
+	for i, p := range m.polls {
+		if p.ID == m.selected {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *tuiModel) move(delta int) {
+	if len(m.polls) == 0 {
+		return
+	}
+	i := min(max(m.index()+delta, 0), len(m.polls)-1)
+	m.selected = m.polls[i].ID
+}
+
+func (m *tuiModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(tuiTitle.Render(fmt.Sprintf("Votigo: %d polls", len(m.polls))))
+	if !m.loadedAt.IsZero() {
+		b.WriteString(tuiDim.Render("  updated " + m.loadedAt.Format("15:04:05")))
+	}
+	b.WriteString("\n\n")
+
+	if len(m.polls) == 0 {
+		b.WriteString("No polls found. Create one with `votigo poll create`.\n")
+	} else {
+		b.WriteString(tuiHeader.Render(fmt.Sprintf("%-4s %-28s %-9s %-7s %6s", "ID", "POLL", "TYPE", "STATUS", "VOTES")))
+		b.WriteString("\n")
+		for _, p := range m.polls {
+			status := fmt.Sprintf("%-7s", p.Status)
+			if style, ok := tuiStatus[p.Status]; ok && p.ID != m.selected {
+				status = style.Render(status)
+			}
+			row := fmt.Sprintf("%-4d %-28s %-9s %s %6d", p.ID, truncate(p.Name, 28), p.VoteType, status, p.VoteCount)
+			if p.ID == m.selected {
+				row = tuiSelected.Render(row)
+			}
+			b.WriteString(row + "\n")
+		}
+	}
+
+	if m.showResults && m.results.CategoryID != 0 {
+		b.WriteString("\n")
+		b.WriteString(tuiTitle.Render(fmt.Sprintf("Results: %s (%s)", m.results.Category, tally.Plural(m.results.TotalVotes, "ballot"))))
+		b.WriteString("\n")
+
+		var top int64
+		for _, r := range m.results.Results {
+			top = max(top, r.Score)
+		}
+		for i, r := range m.results.Results {
+			bar := 0
+			if top > 0 {
+				bar = int(r.Score * 20 / top)
+			}
+			fmt.Fprintf(&b, "%2d. %-24s %s%s %s\n", i+1, truncate(r.Name, 24),
+				tuiBar.Render(strings.Repeat("█", bar)), strings.Repeat(" ", 20-bar), tally.Plural(r.Score, m.results.ScoreUnit()))
+		}
+		if len(m.results.Results) == 0 {
+			b.WriteString(tuiDim.Render("No options yet") + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	if m.message != "" {
+		b.WriteString(m.message + "\n")
+	}
+	for _, err := range []error{m.loadErr, m.err} {
+		if err != nil {
+			b.WriteString(tuiError.Render("Error: "+err.Error()) + "\n")
+		}
+	}
+	b.WriteString(tuiDim.Render("↑/↓ select  o open  c close  r reopen  enter results  q quit"))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
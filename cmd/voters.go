@@ -0,0 +1,167 @@
+// cmd/voters.go
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/roster"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func (c *VotersForgetCmd) Run(ctx *Context) error {
+	// Nicknames are stored lowercased, matching the web vote form
+	nickname := strings.ToLower(strings.TrimSpace(c.Nickname))
+	if nickname == "" {
+		return invalidf("nickname is required")
+	}
+
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback()
+
+	qtx := ctx.Queries.WithTx(tx)
+
+	ballots, err := qtx.ForgetVoter(context.Background(), ctx.Nicknames.Seal(nickname))
+	if err != nil {
+		return dbError(err)
+	}
+
+	// The nickname itself is deliberately left out of the audit detail
+	detail := fmt.Sprintf("%d ballots", ballots)
+	if err := qtx.RecordAudit(context.Background(), db.ActorCLI, db.AuditVoterForget, 0, detail); err != nil {
+		return dbError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(err)
+	}
+
+	ctx.say("Forgot %s: removed %d ballot(s)\n", nickname, ballots)
+	return nil
+}
+
+func (c *VotersForgetCmd) Help() string {
+	return `Ballots in archived polls are kept, without the nickname, as those
+results are final.
+
+Examples:
+  votigo voters forget PlayerOne`
+}
+
+func (c *VotersImportCmd) Run(ctx *Context) error {
+	src, err := roster.New(c.Source)
+	if err != nil {
+		return invalid(err)
+	}
+	attendees, err := src.Attendees(context.Background())
+	if err != nil {
+		return invalid(fmt.Errorf("failed to read the roster: %w", err))
+	}
+
+	seats := make(map[string]string, len(attendees))
+	tags := make(map[string][]string)
+	var errs []error
+	for _, a := range attendees {
+		nickname := ctx.Nicknames.Seal(a.Nickname)
+		seats[nickname] = a.Seat
+		if a.Tags == nil {
+			continue
+		}
+		parsed, err := db.ParseTags(strings.Join(a.Tags, " "))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", a.Nickname, err))
+			continue
+		}
+		tags[nickname] = parsed
+	}
+	if err := errors.Join(errs...); err != nil {
+		return invalid(fmt.Errorf("failed to read the roster: %w", err))
+	}
+
+	var changes db.RosterChanges
+	err = db.InTx(context.Background(), ctx.DB, func(q *db.Queries) error {
+		if changes, err = q.SyncRoster(context.Background(), seats); err != nil {
+			return err
+		}
+		if changes.Retagged, err = q.SyncRosterTags(context.Background(), tags); err != nil {
+			return err
+		}
+		return q.RecordAudit(context.Background(), db.ActorCLI, db.AuditRosterSync, 0, changes.String())
+	})
+	if err != nil {
+		return dbError(err)
+	}
+
+	ctx.say("Synced %s: %s\n", tally.Plural(int64(len(attendees)), "attendee"), changes)
+	return nil
+}
+
+func (c *VotersImportCmd) Help() string {
+	return `Replaces the roster with the attendees the registration system lists,
+mapping their nicknames to seats for the turnout views. Attendees no longer
+listed are removed; ballots are never touched. Run it again whenever
+registrations change.
+
+A CSV export needs a header row with a nickname column and, usually, a
+seat column. A tags or group column gives attendees their roster tags,
+replacing any set in admin; without one, tags are left as they are. Other
+columns are ignored.
+
+Examples:
+  votigo voters import registrations.csv
+  votigo voters import csv=exports/attendees.csv`
+}
+
+func (c *VotersTagCmd) Run(ctx *Context) error {
+	nickname := strings.ToLower(strings.TrimSpace(c.Nickname))
+	if nickname == "" {
+		return invalidf("nickname is required")
+	}
+	tags, err := db.ParseTags(strings.Join(c.Tags, " "))
+	if err != nil {
+		return invalid(err)
+	}
+
+	attendee, err := ctx.Queries.GetAttendeeByNickname(context.Background(), ctx.Nicknames.Seal(nickname))
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound("%s isn't on the roster; import it with votigo voters import", nickname)
+	}
+	if err != nil {
+		return dbError(err)
+	}
+	err = db.InTx(context.Background(), ctx.DB, func(q *db.Queries) error {
+		if err := q.SetAttendeeTags(context.Background(), attendee.ID, tags); err != nil {
+			return err
+		}
+		detail := fmt.Sprintf("attendee %d: %s", attendee.ID, strings.Join(tags, " "))
+		return q.RecordAudit(context.Background(), db.ActorCLI, db.AuditRosterTags, 0, detail)
+	})
+	if err != nil {
+		return dbError(err)
+	}
+
+	if len(tags) == 0 {
+		ctx.say("Cleared %s's tags\n", nickname)
+	} else {
+		ctx.say("Tagged %s: %s\n", nickname, strings.Join(tags, " "))
+	}
+	return nil
+}
+
+func (c *VotersTagCmd) Help() string {
+	return `Replaces an attendee's roster tags, which polls' --eligibility and
+--weights go by. Admin → Roster does the same in the browser; a roster import
+with a tags column overwrites them.
+
+Examples:
+  votigo voters tag PlayerOne participant
+  votigo voters tag Organizer crew,caster
+  votigo voters tag PlayerOne   # clear their tags`
+}
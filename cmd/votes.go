@@ -0,0 +1,305 @@
+// cmd/votes.go
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/palm-arcade/votigo/internal/dataset"
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func (c *VotesHistoryCmd) Run(ctx *Context) error {
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
+	if err != nil {
+		return lookupError("poll", err)
+	}
+
+	churn, err := ctx.Queries.GetVoteChurn(context.Background(), c.Poll.ID)
+	if err != nil {
+		return dbError(err)
+	}
+
+	fmt.Printf("Vote changes for: %s\n", cat.Name)
+	fmt.Printf("%d of %d voters changed their vote (%d changes)\n\n", churn.ChangedVoters, churn.Voters, churn.Changes)
+
+	rows, err := ctx.Queries.ListVoteHistoryByCategory(context.Background(), c.Poll.ID)
+	if err != nil {
+		return dbError(err)
+	}
+	if len(rows) == 0 {
+		fmt.Println("No previous ballots recorded.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VOTER\tVERSION\tREPLACED\tCHOICES")
+
+	// Rows are ordered by voter then version; print one line per version
+	for i := 0; i < len(rows); {
+		row := rows[i]
+		var choices []string
+		for ; i < len(rows) && rows[i].Nickname == row.Nickname && rows[i].Version == row.Version; i++ {
+			choices = append(choices, rows[i].OptionName)
+		}
+		replaced := ""
+		if row.ReplacedAt.Valid {
+			replaced = row.ReplacedAt.Time.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\tv%d\t%s\t%s\n", ctx.Nicknames.Reveal(row.Nickname), row.Version, replaced, strings.Join(choices, ", "))
+	}
+	return w.Flush()
+}
+
+func (c *VotesHistoryCmd) Help() string {
+	return `Examples:
+  votigo votes history 1`
+}
+
+func (c *VotesPurgeHistoryCmd) Run(ctx *Context) error {
+	if c.All == (c.Poll.ID != 0) {
+		return invalidf("specify either a poll ID or --all")
+	}
+
+	var categories []db.Category
+	if c.All {
+		all, err := ctx.Queries.ListCategories(context.Background())
+		if err != nil {
+			return dbError(err)
+		}
+		categories = all
+	} else {
+		cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
+		if err != nil {
+			return lookupError("poll", err)
+		}
+		categories = []db.Category{cat}
+	}
+
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		return dbError(err)
+	}
+	defer tx.Rollback()
+
+	qtx := ctx.Queries.WithTx(tx)
+
+	// Versions are reset too, since the count alone shows who flip-flopped
+	var total int64
+	for _, cat := range categories {
+		n, err := qtx.PurgeVoteHistoryByCategory(context.Background(), cat.ID)
+		if err != nil {
+			return dbError(err)
+		}
+		if err := qtx.ResetVoteVersionsByCategory(context.Background(), cat.ID); err != nil {
+			return dbError(err)
+		}
+		// Conflicts name versions that are gone now
+		if err := qtx.DeleteVoteConflictsByCategory(context.Background(), cat.ID); err != nil {
+			return dbError(err)
+		}
+		if err := qtx.RecordAudit(context.Background(), db.ActorCLI, db.AuditHistoryPurge, cat.ID, ""); err != nil {
+			return dbError(err)
+		}
+		total += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(err)
+	}
+
+	ctx.say("Purged %d previous selections from %d poll(s)\n", total, len(categories))
+	return nil
+}
+
+func (c *VotesPurgeHistoryCmd) Help() string {
+	return `Examples:
+  votigo votes purge-history 1
+  votigo votes purge-history --all`
+}
+
+func (c *VotesExportCmd) Run(ctx *Context) error {
+	var polls []db.Category
+	for _, ref := range c.Polls {
+		cat, err := resolvePoll(ctx, ref, os.Stdin, os.Stderr)
+		if err != nil {
+			return err
+		}
+		polls = append(polls, cat)
+	}
+	if len(c.Polls) == 0 {
+		var err error
+		if polls, err = dataset.Finished(context.Background(), ctx.Queries); err != nil {
+			return dbError(err)
+		}
+	}
+	ds, err := dataset.Build(context.Background(), ctx.Queries, polls)
+	if err != nil {
+		return dbError(err)
+	}
+
+	var out io.Writer = os.Stdout
+	toFile := c.Out != "" && c.Out != "-"
+	if toFile {
+		f, err := os.OpenFile(c.Out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	write := dataset.WriteCSV
+	if c.Format == "json" {
+		write = dataset.WriteJSON
+	}
+	if err := write(out, ds); err != nil {
+		return err
+	}
+
+	if toFile {
+		ballots := 0
+		for _, p := range ds.Polls {
+			ballots += len(p.Ballots)
+		}
+		ctx.say("Exported %s from %s to %s\n", tally.Plural(int64(ballots), "ballot"), tally.Plural(int64(len(ds.Polls)), "poll"), c.Out)
+	}
+	return nil
+}
+
+func (c *VotesExportCmd) Help() string {
+	return `Writes ballots for analysis with nothing that identifies a voter: each
+voter gets a random ID, new for every export but the same across its polls,
+and ballots carry no nickname, address, station or time. Previous ballot
+versions, roster tags and test ballots are left out. The CSV has one row per
+choice; the README documents both formats.
+
+Without polls named, every closed or archived poll is exported.
+
+Examples:
+  votigo votes export -o ballots.csv
+  votigo votes export "Best Game" "Best Demo" --format json -o ballots.json`
+}
+
+func (c *VotesBallotsCmd) Run(ctx *Context) error {
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
+	if err != nil {
+		return lookupError("poll", err)
+	}
+	ballots, err := ctx.Queries.ListVoteOrigins(context.Background(), cat.ID)
+	if err != nil {
+		return dbError(err)
+	}
+	if len(ballots) == 0 {
+		ctx.say("%s has no ballots\n", cat.Name)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BALLOT\tVOTER\tCAST\tADDRESS\tSTATUS")
+	for _, b := range ballots {
+		cast := ""
+		if b.CreatedAt.Valid {
+			cast = b.CreatedAt.Time.Local().Format("2006-01-02 15:04:05")
+		}
+		status := "counted"
+		if b.Invalid != "" {
+			status = "invalid: " + b.Invalid
+		} else if b.CountedUntil.Valid {
+			status = "counted as of " + b.CountedUntil.Time.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", b.ID, ctx.Nicknames.Reveal(b.Nickname), cast, b.Address, status)
+	}
+	return w.Flush()
+}
+
+func (c *VotesBallotsCmd) Help() string {
+	return `The address is where the voter's last ballot came from, as recorded in the
+audit log; ballots cast from the CLI or before addresses were recorded have
+none.
+
+Examples:
+  votigo votes ballots 1`
+}
+
+func (c *VotesInvalidateCmd) Run(ctx *Context) error {
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
+	if err != nil {
+		return lookupError("poll", err)
+	}
+
+	var filter db.BallotFilter
+	if c.Before != "" {
+		if filter.From, err = parsePlannedTime(c.Before, "--before"); err != nil {
+			return invalid(err)
+		}
+	}
+	if c.After != "" {
+		if filter.To, err = parsePlannedTime(c.After, "--after"); err != nil {
+			return invalid(err)
+		}
+	}
+	if filter.Addresses, err = db.ParseAddresses(strings.Join(c.Address, " ")); err != nil {
+		return invalid(err)
+	}
+	for _, n := range c.Nickname {
+		// Nicknames are stored lowercased, matching the web vote form
+		if n = strings.ToLower(strings.TrimSpace(n)); n != "" {
+			filter.Nicknames = append(filter.Nicknames, ctx.Nicknames.Seal(n))
+		}
+	}
+	if filter.Empty() {
+		return invalidf("say which ballots with --before, --after, --address or --nickname")
+	}
+
+	n, cut, err := db.InvalidateBallots(context.Background(), ctx.DB, cat.ID, filter, c.Reason, db.ActorCLI)
+	if err != nil {
+		return dbError(err)
+	}
+	ctx.say("Invalidated %s in %s\n", tally.Plural(int64(n), "ballot"), cat.Name)
+	if cut > 0 {
+		ctx.say("Counting the ballot %s had at --after\n", tally.Plural(int64(cut), "voter"))
+	}
+	return nil
+}
+
+func (c *VotesInvalidateCmd) Help() string {
+	return `Invalid ballots are kept, with the reason, but left out of the results and
+vote counts. A ballot matching any of the options is invalidated; ones
+already invalid are left alone. Voting again doesn't make a ballot count
+again: restore it with votes restore. A voter who changed their ballot
+after --after counts with the one they had then instead, until restored.
+
+Examples:
+  votigo votes invalidate 1 --before 18:00 --after 22:00 --reason "outside the official window"
+  votigo votes invalidate 1 --address 10.0.4.0/24 --reason "ballot stuffing from the lab"
+  votigo votes invalidate 1 --nickname mallory --reason "banned"`
+}
+
+func (c *VotesRestoreCmd) Run(ctx *Context) error {
+	cat, err := ctx.Queries.Category(context.Background(), c.Poll.ID)
+	if err != nil {
+		return lookupError("poll", err)
+	}
+	if err := db.RestoreBallot(context.Background(), ctx.DB, cat.ID, c.Ballot, db.ActorCLI); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return notFound("ballot #%d not found in %s (see `votigo votes ballots`)", c.Ballot, cat.Name)
+		}
+		return dbError(err)
+	}
+	ctx.say("Ballot #%d counts again in %s\n", c.Ballot, cat.Name)
+	return nil
+}
+
+func (c *VotesRestoreCmd) Help() string {
+	return `Counts an invalid ballot again, or counts a ballot held at the official
+window's end (see votes invalidate) as it stands now.
+
+Examples:
+  votigo votes restore 1 42`
+}
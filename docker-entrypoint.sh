@@ -1,11 +1,9 @@
 #!/bin/bash
 set -e
 
-# Check required environment variable
-if [ -z "$ADMIN_PASSWORD" ]; then
-    echo "Error: ADMIN_PASSWORD environment variable is required"
-    exit 1
-fi
+# Without ADMIN_PASSWORD, the first start on an empty database makes one up,
+# prints it once and stores its hash (see votigo serve --help)
+export VOTIGO_ADMIN_PASSWORD="$ADMIN_PASSWORD"
 
 # Handle graceful shutdown
 cleanup() {
@@ -16,15 +14,21 @@ cleanup() {
 }
 trap cleanup SIGTERM SIGINT
 
+# Start modern server on port 8001 in background
+echo "Starting modern server on port 8001..."
+./votigo serve --db /data/votigo.db --port 8001 --ui modern &
+MODERN_PID=$!
+
+# On a first run, let the modern server store the generated password before
+# the legacy one looks for it
+if [ -z "$ADMIN_PASSWORD" ]; then
+    sleep 2
+fi
+
 # Start legacy server on port 8000 in background
 echo "Starting legacy server on port 8000..."
-./votigo serve --db /data/votigo.db --port 8000 --ui legacy --admin-password "$ADMIN_PASSWORD" &
+./votigo serve --db /data/votigo.db --port 8000 --ui legacy &
 LEGACY_PID=$!
 
-# Start modern server on port 8001 in foreground
-echo "Starting modern server on port 8001..."
-./votigo serve --db /data/votigo.db --port 8001 --ui modern --admin-password "$ADMIN_PASSWORD" &
-MODERN_PID=$!
-
 # Wait for both processes
 wait $LEGACY_PID $MODERN_PID
@@ -0,0 +1,84 @@
+// Package accesslog writes the HTTP access log to a file that rotates by
+// size, so it can't fill the disk over a long event.
+package accesslog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// File is an append-only log file. When a write would take it past
+// MaxBytes it is renamed to path.1 (older copies shift to path.2 and so on,
+// keeping Keep of them) and a fresh file is started.
+type File struct {
+	path     string
+	maxBytes int64
+	keep     int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// Open opens path for appending. maxBytes of 0 never rotates; keep is how
+// many rotated files to keep, at least one.
+func Open(path string, maxBytes int64, keep int) (*File, error) {
+	l := &File{path: path, maxBytes: maxBytes, keep: max(keep, 1)}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *File) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open access log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("open access log: %w", err)
+	}
+	l.f, l.size = f, info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if it would not fit. Each call should be
+// one whole line so lines never straddle two files.
+func (l *File) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxBytes > 0 && l.size > 0 && l.size+int64(len(p)) > l.maxBytes {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// rotate shifts path.N-1 to path.N down to path to path.1, dropping the
+// oldest, and reopens path
+func (l *File) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return fmt.Errorf("rotate access log: %w", err)
+	}
+	for i := l.keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		return fmt.Errorf("rotate access log: %w", err)
+	}
+	return l.open()
+}
+
+// Close closes the current file
+func (l *File) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
@@ -0,0 +1,61 @@
+package accesslog_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/palm-arcade/votigo/internal/accesslog"
+)
+
+func TestFileRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	l, err := accesslog.Open(path, 20, 2)
+	if err != nil {
+		t.Fatalf("failed to open log: %v", err)
+	}
+	defer l.Close()
+
+	// Each line is 10 bytes, so every third line starts a new file
+	for _, line := range []string{"line 0001\n", "line 0002\n", "line 0003\n", "line 0004\n", "line 0005\n", "line 0006\n", "line 0007\n"} {
+		if _, err := l.Write([]byte(line)); err != nil {
+			t.Fatalf("failed to write: %v", err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "line 0007\n",
+		path + ".1": "line 0005\nline 0006\n",
+		path + ".2": "line 0003\nline 0004\n",
+	}
+	for file, content := range want {
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		if string(got) != content {
+			t.Errorf("%s: expected %q, got %q", filepath.Base(file), content, got)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("expected only two rotated files to be kept")
+	}
+}
+
+func TestFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	os.WriteFile(path, []byte("earlier\n"), 0o644)
+
+	l, err := accesslog.Open(path, 0, 1)
+	if err != nil {
+		t.Fatalf("failed to open log: %v", err)
+	}
+	l.Write([]byte("later\n"))
+	l.Close()
+
+	got, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(got), "earlier\n") || !strings.HasSuffix(string(got), "later\n") {
+		t.Errorf("expected the existing log to be appended to, got %q", got)
+	}
+}
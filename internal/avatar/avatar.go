@@ -0,0 +1,114 @@
+// Package avatar draws identicons: small symmetric pixel-art tiles derived
+// from a nickname, so voters can be told apart at a glance.
+//
+// An avatar is named by an ID, a keyed hash of the nickname. IDs are stable
+// for a database but reveal nothing about the nickname without its salt, so
+// pages may show avatars where they mustn't show names.
+package avatar
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Avatars are a grid of cells, mirrored left to right, inside a one-cell
+// border
+const (
+	grid     = 5
+	cellSize = 12
+	Size     = (grid + 2) * cellSize
+)
+
+// idLen is how many hex digits an ID has
+const idLen = 16
+
+// background matches the arcade theme's panels
+var background = color.RGBA{0x17, 0x17, 0x17, 0xff}
+
+// ID returns the avatar ID for nickname
+func ID(salt []byte, nickname string) string {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(nickname))
+	return hex.EncodeToString(mac.Sum(nil))[:idLen]
+}
+
+// Valid reports whether id is one ID could have returned
+func Valid(id string) bool {
+	if len(id) != idLen {
+		return false
+	}
+	for _, r := range id {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Render draws the avatar for id as a PNG
+func Render(w io.Writer, id string) error {
+	return png.Encode(w, Draw(id))
+}
+
+// Draw draws the avatar for id: a colour from its first bytes, and cells
+// switched on by its remaining bits
+func Draw(id string) *image.RGBA {
+	bits, _ := hex.DecodeString(id)
+	for len(bits) < idLen/2 {
+		bits = append(bits, 0)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, Size, Size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	hue := float64(uint16(bits[0])<<8|uint16(bits[1])) / 65536 * 360
+	fg := image.NewUniform(hsl(hue, 0.65, 0.55))
+
+	// Three columns of five cells decide the tile; the last two mirror the
+	// first two
+	n := 0
+	for col := range (grid + 1) / 2 {
+		for row := range grid {
+			on := bits[2+n/8]&(1<<(n%8)) != 0
+			n++
+			if !on {
+				continue
+			}
+			for _, c := range []int{col, grid - 1 - col} {
+				x, y := (c+1)*cellSize, (row+1)*cellSize
+				draw.Draw(img, image.Rect(x, y, x+cellSize, y+cellSize), fg, image.Point{}, draw.Src)
+			}
+		}
+	}
+	return img
+}
+
+// hsl converts a hue in degrees, saturation and lightness to RGB
+func hsl(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g = c, x
+	case h < 120:
+		r, g = x, c
+	case h < 180:
+		g, b = c, x
+	case h < 240:
+		g, b = x, c
+	case h < 300:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	return color.RGBA{uint8((r + m) * 255), uint8((g + m) * 255), uint8((b + m) * 255), 0xff}
+}
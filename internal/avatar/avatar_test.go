@@ -0,0 +1,58 @@
+package avatar_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/palm-arcade/votigo/internal/avatar"
+)
+
+func TestID(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	alice := avatar.ID(salt, "alice")
+	if !avatar.Valid(alice) {
+		t.Fatalf("ID returned an invalid ID %q", alice)
+	}
+	if avatar.ID(salt, "alice") != alice {
+		t.Error("expected the same nickname to get the same ID")
+	}
+	if avatar.ID(salt, "bob") == alice {
+		t.Error("expected different nicknames to get different IDs")
+	}
+	if avatar.ID([]byte("another salt"), "alice") == alice {
+		t.Error("expected another salt to give another ID")
+	}
+
+	for _, id := range []string{"", "alice", "0123456789ABCDEF", "0123456789abcdef0", "../../etc/passwd"} {
+		if avatar.Valid(id) {
+			t.Errorf("Valid(%q) = true", id)
+		}
+	}
+}
+
+func TestDraw(t *testing.T) {
+	img := avatar.Draw("0123456789abcdef")
+	if b := img.Bounds(); b.Dx() != avatar.Size || b.Dy() != avatar.Size {
+		t.Fatalf("expected a %dpx square, got %v", avatar.Size, b)
+	}
+	// Tiles mirror left to right
+	for y := range avatar.Size {
+		for x := range avatar.Size / 2 {
+			if img.At(x, y) != img.At(avatar.Size-1-x, y) {
+				t.Fatalf("expected a symmetric tile, (%d,%d) differs", x, y)
+			}
+		}
+	}
+	if bytes.Equal(img.Pix, avatar.Draw("fedcba9876543210").Pix) {
+		t.Error("expected different IDs to draw different tiles")
+	}
+
+	var buf bytes.Buffer
+	if err := avatar.Render(&buf, "0123456789abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Errorf("Render wrote an unreadable PNG: %v", err)
+	}
+}
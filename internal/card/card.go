@@ -0,0 +1,214 @@
+// Package card draws share-ready results cards: a PNG with the poll, its
+// winner and a podium, sized for link previews and chat embeds.
+package card
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+// Cards are the size link previews use
+const (
+	Width  = 1200
+	Height = 630
+)
+
+// margin is the space kept clear around the card's edges
+const margin = 60
+
+// Colours of the arcade theme
+var (
+	background = color.RGBA{0x0a, 0x0a, 0x0a, 0xff}
+	panel      = color.RGBA{0x17, 0x17, 0x17, 0xff}
+	muted      = color.RGBA{0x73, 0x73, 0x73, 0xff}
+	text       = color.RGBA{0xf5, 0xf5, 0xf5, 0xff}
+	green      = color.RGBA{0x22, 0xc5, 0x5e, 0xff}
+	amber      = color.RGBA{0xf5, 0x9e, 0x0b, 0xff}
+	silver     = color.RGBA{0xa3, 0xa3, 0xa3, 0xff}
+	bronze     = color.RGBA{0xb4, 0x53, 0x09, 0xff}
+)
+
+// Card is what a results card shows
+type Card struct {
+	Brand      string // the event, across the top; "Votigo" if empty
+	Title      string // the poll
+	Unit       string // what Score counts, e.g. "vote" or "point"
+	TotalVotes int64
+	Results    []Result // best first
+	Live       bool     // voting is still open, so the top result only leads
+}
+
+// Result is one option's standing. Place is shared by results that tie;
+// 0 takes the result's position in Card.Results.
+type Result struct {
+	Name  string
+	Score int64
+	Place int
+}
+
+// place returns the place of the i-th result
+func (c Card) place(i int) int {
+	if p := c.Results[i].Place; p > 0 {
+		return p
+	}
+	return i + 1
+}
+
+// winners returns the names of the results sharing first place
+func (c Card) winners() []string {
+	var names []string
+	for i, r := range c.Results {
+		if c.place(i) != 1 {
+			break
+		}
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// Render draws c as a PNG
+func Render(w io.Writer, c Card) error {
+	return png.Encode(w, Draw(c))
+}
+
+// Draw draws c
+func Draw(c Card) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	fill(img, img.Bounds(), background)
+	frame(img, img.Bounds().Inset(16), 4, green)
+
+	brand := c.Brand
+	if brand == "" {
+		brand = "Votigo"
+	}
+	drawText(img, margin, 56, fit(strings.ToUpper(brand), 3, Width-2*margin), 3, muted)
+	drawText(img, margin, 100, fit(c.Title, 6, Width-2*margin), 6, amber)
+
+	if len(c.Results) == 0 {
+		drawText(img, margin, 230, "No votes were cast", 5, text)
+	} else {
+		winners := c.winners()
+		label := "WINNER"
+		switch {
+		case c.Live && len(winners) > 1:
+			label = "TIED FOR THE LEAD"
+		case c.Live:
+			label = "LEADING"
+		case len(winners) > 1:
+			label = "TIE"
+		}
+		drawText(img, margin, 190, label, 3, green)
+		drawText(img, margin, 226, fit(strings.Join(winners, " & "), 8, Width-2*margin), 8, text)
+		podium(img, c)
+	}
+
+	drawText(img, margin, Height-52, tally.Plural(c.TotalVotes, "ballot"), 3, muted)
+	return img
+}
+
+// podium draws the top three as steps, first in the middle. Steps are
+// numbered and coloured by place, so a tie shares the medal.
+func podium(img *image.RGBA, c Card) {
+	const (
+		base  = Height - 70
+		width = 300
+		gap   = 40
+		left  = (Width - 3*width - 2*gap) / 2
+	)
+	steps := []struct {
+		place  int
+		x      int
+		height int
+	}{
+		{2, left, 110},
+		{1, left + width + gap, 150},
+		{3, left + 2*(width+gap), 80},
+	}
+	medals := map[int]color.RGBA{1: amber, 2: silver, 3: bronze}
+
+	unit := c.Unit
+	if unit == "" {
+		unit = "vote"
+	}
+	for _, step := range steps {
+		if step.place > len(c.Results) {
+			continue
+		}
+		r := c.Results[step.place-1]
+		colour := medals[c.place(step.place-1)]
+		top := base - step.height
+		fill(img, image.Rect(step.x, top, step.x+width, base), panel)
+		fill(img, image.Rect(step.x, top, step.x+width, top+6), colour)
+
+		place := fmt.Sprint(c.place(step.place - 1))
+		drawText(img, step.x+(width-textWidth(place, 5))/2, top+20, place, 5, colour)
+		score := tally.Plural(r.Score, unit)
+		if step.height >= 110 {
+			drawText(img, step.x+(width-textWidth(score, 2))/2, top+70, score, 2, muted)
+		}
+
+		name := fit(r.Name, 3, width)
+		drawText(img, step.x+(width-textWidth(name, 3))/2, top-36, name, 3, text)
+	}
+}
+
+// fill paints r in c
+func fill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// frame outlines r with a border thickness pixels wide
+func frame(img *image.RGBA, r image.Rectangle, thickness int, c color.Color) {
+	fill(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+thickness), c)
+	fill(img, image.Rect(r.Min.X, r.Max.Y-thickness, r.Max.X, r.Max.Y), c)
+	fill(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+thickness, r.Max.Y), c)
+	fill(img, image.Rect(r.Max.X-thickness, r.Min.Y, r.Max.X, r.Max.Y), c)
+}
+
+// textWidth is how many pixels s spans drawn at scale
+func textWidth(s string, scale int) int {
+	n := len([]rune(s))
+	if n == 0 {
+		return 0
+	}
+	return (n*6 - 1) * scale
+}
+
+// fit shortens s with an ellipsis until it spans at most width pixels at
+// scale
+func fit(s string, scale, width int) string {
+	if textWidth(s, scale) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", scale) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ") + "..."
+}
+
+// drawText draws s with its top left corner at x, y, each font pixel scale
+// pixels square
+func drawText(img *image.RGBA, x, y int, s string, scale int, c color.Color) {
+	src := image.NewUniform(c)
+	for _, r := range s {
+		for row, bits := range glyph(r) {
+			for col := range 5 {
+				if bits&(1<<(4-col)) == 0 {
+					continue
+				}
+				px := x + col*scale
+				py := y + row*scale
+				draw.Draw(img, image.Rect(px, py, px+scale, py+scale), src, image.Point{}, draw.Src)
+			}
+		}
+		x += 6 * scale
+	}
+}
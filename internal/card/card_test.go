@@ -0,0 +1,48 @@
+package card_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/palm-arcade/votigo/internal/card"
+)
+
+func TestRender(t *testing.T) {
+	for _, c := range []card.Card{
+		{Title: "Best Game", Unit: "vote", TotalVotes: 3, Results: []card.Result{{Name: "Doom", Score: 2}, {Name: "Tetris", Score: 1}}},
+		{Title: "Best Game", Results: nil},
+		{Brand: "Pålm Arcade LAN ☃", Title: string(bytes.Repeat([]byte("Very long poll name "), 20)), Unit: "point",
+			Results: []card.Result{{Name: "Street Fighter II", Score: 9}, {Name: "Doom", Score: 6}, {Name: "Tetris", Score: 3}, {Name: "Pong", Score: 0}}},
+	} {
+		var buf bytes.Buffer
+		if err := card.Render(&buf, c); err != nil {
+			t.Fatalf("Render(%q): %v", c.Title, err)
+		}
+		cfg, err := png.DecodeConfig(&buf)
+		if err != nil {
+			t.Fatalf("Render(%q) wrote an unreadable PNG: %v", c.Title, err)
+		}
+		if cfg.Width != card.Width || cfg.Height != card.Height {
+			t.Errorf("Render(%q) drew %dx%d, want %dx%d", c.Title, cfg.Width, cfg.Height, card.Width, card.Height)
+		}
+	}
+}
+
+func TestDrawShowsWinner(t *testing.T) {
+	// A poll without votes says so where the winner would be
+	blank := card.Draw(card.Card{Title: "Best Game"})
+	winner := card.Draw(card.Card{Title: "Best Game", Results: []card.Result{{Name: "Doom", Score: 1}}})
+	if bytes.Equal(blank.Pix, winner.Pix) {
+		t.Error("expected a card with a winner to differ from one without votes")
+	}
+}
+
+func TestDrawShowsTie(t *testing.T) {
+	// Sharing first place names both and colours the second step gold
+	won := card.Draw(card.Card{Title: "Best Game", Results: []card.Result{{Name: "Doom", Score: 2}, {Name: "Quake", Score: 2}}})
+	tied := card.Draw(card.Card{Title: "Best Game", Results: []card.Result{{Name: "Doom", Score: 2, Place: 1}, {Name: "Quake", Score: 2, Place: 1}}})
+	if bytes.Equal(won.Pix, tied.Pix) {
+		t.Error("expected a tie for first to be drawn differently from a win")
+	}
+}
@@ -0,0 +1,201 @@
+// Package dataset exports ballots for analysis with nothing that identifies
+// a voter: each voter gets a random ID, new for every export, and ballots
+// carry no nickname, address, station or time. The formats are documented
+// in the README under "Ballot dataset" and versioned by Version.
+package dataset
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// Format and Version identify the JSON export; Version goes up when a
+// field changes meaning or goes away, not when one is added
+const (
+	Format  = "votigo-ballots"
+	Version = 1
+)
+
+// CSVHeader names the CSV export's columns: one row per choice on a ballot
+var CSVHeader = []string{"poll_id", "poll", "vote_type", "voter", "option_id", "option", "rank"}
+
+// Dataset is the JSON export
+type Dataset struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	Polls   []Poll `json:"polls"`
+}
+
+// Poll is one poll's options and ballots. MaxRank is set for ranked polls.
+type Poll struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	VoteType string   `json:"vote_type"`
+	MaxRank  int64    `json:"max_rank,omitempty"`
+	Options  []Option `json:"options"`
+	Ballots  []Ballot `json:"ballots"`
+}
+
+// Option is a choice on a poll's ballot
+type Option struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Ballot is one voter's current ballot. Voter is the same on every poll the
+// voter took part in, so ballots can be compared across polls, but means
+// nothing outside this export.
+type Ballot struct {
+	Voter   string   `json:"voter"`
+	Choices []Choice `json:"choices"`
+}
+
+// Choice is an option picked on a ballot. Rank is 1 for first and is only
+// set on ranked polls.
+type Choice struct {
+	Option int64 `json:"option"`
+	Rank   int64 `json:"rank,omitempty"`
+}
+
+// Build exports the ballots of polls, in the order given. Ballots are
+// sorted by voter ID, so the order they were cast in is lost too. A draft's
+// test ballots are left out, and ballots deleted by a purge are gone, so
+// those polls have none.
+func Build(ctx context.Context, q *db.Queries, polls []db.Category) (Dataset, error) {
+	ds := Dataset{Format: Format, Version: Version, Polls: []Poll{}}
+	voters := make(map[string]string) // stored nickname to random ID
+	taken := make(map[string]bool)
+	voterID := func(nickname string) string {
+		if id, ok := voters[nickname]; ok {
+			return id
+		}
+		id := randomID()
+		for taken[id] {
+			id = randomID()
+		}
+		voters[nickname], taken[id] = id, true
+		return id
+	}
+
+	for _, cat := range polls {
+		p := Poll{ID: cat.ID, Name: cat.Name, VoteType: cat.VoteType, Options: []Option{}, Ballots: []Ballot{}}
+		if cat.VoteType == "ranked" {
+			p.MaxRank = 3
+			if cat.MaxRank.Valid {
+				p.MaxRank = cat.MaxRank.Int64
+			}
+		}
+		options, err := q.ListOptionsByCategory(ctx, cat.ID)
+		if err != nil {
+			return ds, fmt.Errorf("%s: %w", cat.Name, err)
+		}
+		for _, o := range options {
+			p.Options = append(p.Options, Option{ID: o.ID, Name: o.Name})
+		}
+		if cat.Testing() {
+			ds.Polls = append(ds.Polls, p)
+			continue
+		}
+
+		votes, err := q.ListVotesByCategory(ctx, cat.ID)
+		if err != nil {
+			return ds, fmt.Errorf("%s: %w", cat.Name, err)
+		}
+		selections, err := q.ListSelectionsByCategory(ctx, cat.ID)
+		if err != nil {
+			return ds, fmt.Errorf("%s: %w", cat.Name, err)
+		}
+		choices := make(map[int64][]Choice, len(votes))
+		for _, s := range selections {
+			c := Choice{Option: s.OptionID}
+			if cat.VoteType == "ranked" && s.Rank.Valid {
+				c.Rank = s.Rank.Int64
+			}
+			choices[s.VoteID] = append(choices[s.VoteID], c)
+		}
+		for _, v := range votes {
+			if len(choices[v.ID]) == 0 {
+				continue
+			}
+			p.Ballots = append(p.Ballots, Ballot{Voter: voterID(v.Nickname), Choices: choices[v.ID]})
+		}
+		slices.SortFunc(p.Ballots, func(a, b Ballot) int { return strings.Compare(a.Voter, b.Voter) })
+		ds.Polls = append(ds.Polls, p)
+	}
+	return ds, nil
+}
+
+// Finished lists the polls exported by default: those closed or archived,
+// oldest first. Open polls would give away results before they are in.
+func Finished(ctx context.Context, q *db.Queries) ([]db.Category, error) {
+	categories, err := q.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var finished []db.Category
+	for _, cat := range categories {
+		if cat.Finished() {
+			finished = append(finished, cat)
+		}
+	}
+	slices.SortFunc(finished, func(a, b db.Category) int { return cmp.Compare(a.ID, b.ID) })
+	return finished, nil
+}
+
+// randomID is a voter ID with no relation to the voter
+func randomID() string {
+	return "v" + strings.ToLower(rand.Text()[:10])
+}
+
+// WriteJSON writes ds as indented JSON
+func WriteJSON(w io.Writer, ds Dataset) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ds)
+}
+
+// WriteCSV writes ds as CSV with CSVHeader's columns, one row per choice.
+// rank is empty on single-choice and approval polls.
+func WriteCSV(w io.Writer, ds Dataset) error {
+	cw := csv.NewWriter(w)
+	cw.Write(CSVHeader)
+	for _, p := range ds.Polls {
+		names := make(map[int64]string, len(p.Options))
+		for _, o := range p.Options {
+			names[o.ID] = o.Name
+		}
+		for _, b := range p.Ballots {
+			for _, c := range b.Choices {
+				rank := ""
+				if c.Rank > 0 {
+					rank = strconv.FormatInt(c.Rank, 10)
+				}
+				cw.Write([]string{
+					strconv.FormatInt(p.ID, 10), csvSafe(p.Name), p.VoteType, b.Voter,
+					strconv.FormatInt(c.Option, 10), csvSafe(names[c.Option]), rank,
+				})
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvSafe keeps a poll or option name from being read as a formula when the
+// export is opened in a spreadsheet
+func csvSafe(s string) string {
+	if s != "" && (s[0] == '=' || s[0] == '+' || s[0] == '-' || s[0] == '@') {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+package dataset_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/palm-arcade/votigo/internal/dataset"
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+func TestBuild(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := t.Context()
+	q := db.New(conn)
+
+	// poll creates a poll with options Doom and Quake; ballots maps
+	// nicknames to the options they pick, in rank order
+	poll := func(name, voteType, status string, ballots map[string][]string) db.Category {
+		t.Helper()
+		cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{
+			Name: name, VoteType: voteType, Status: status, ShowResults: "live",
+			MaxRank: sql.NullInt64{Int64: 2, Valid: voteType == "ranked"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make(map[string]int64)
+		for _, option := range []string{"Doom", "Quake"} {
+			opt, err := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: option})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids[option] = opt.ID
+		}
+		for nickname, picks := range ballots {
+			vote, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: nickname})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, option := range picks {
+				rank := sql.NullInt64{Int64: int64(i + 1), Valid: voteType == "ranked"}
+				if err := q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: ids[option], Rank: rank}); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		return cat
+	}
+	poll("Best FPS", "single", "closed", map[string][]string{"ace": {"Doom"}, "bob": {"Quake"}})
+	poll("Best Map", "ranked", "archived", map[string][]string{"ace": {"Quake", "Doom"}})
+	poll("Live Poll", "single", "open", map[string][]string{"ace": {"Doom"}})
+
+	polls, err := dataset.Finished(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polls) != 2 || polls[0].Name != "Best FPS" || polls[1].Name != "Best Map" {
+		t.Fatalf("expected the two finished polls in order, got %v", polls)
+	}
+	ds, err := dataset.Build(ctx, q, polls)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := dataset.WriteJSON(&out, ds); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "ace") || strings.Contains(out.String(), "bob") {
+		t.Fatalf("expected no nicknames in the export, got:\n%s", out.String())
+	}
+	var read dataset.Dataset
+	if err := json.Unmarshal(out.Bytes(), &read); err != nil {
+		t.Fatal(err)
+	}
+	if read.Format != dataset.Format || read.Version != dataset.Version || len(read.Polls) != 2 {
+		t.Fatalf("unexpected dataset %+v", read)
+	}
+	fps, maps := read.Polls[0], read.Polls[1]
+	if len(fps.Ballots) != 2 || len(maps.Ballots) != 1 || maps.MaxRank != 2 {
+		t.Fatalf("unexpected polls %+v", read.Polls)
+	}
+	// ace's ranked ballot has the same ID as one of the single-choice ones
+	ace := maps.Ballots[0]
+	if ace.Voter != fps.Ballots[0].Voter && ace.Voter != fps.Ballots[1].Voter {
+		t.Errorf("expected a voter's ID to be the same across polls, got %+v and %+v", ace, fps.Ballots)
+	}
+	if fps.Ballots[0].Voter == fps.Ballots[1].Voter {
+		t.Error("expected each voter their own ID")
+	}
+	if len(ace.Choices) != 2 || ace.Choices[0].Rank != 1 || ace.Choices[1].Rank != 2 || fps.Ballots[0].Choices[0].Rank != 0 {
+		t.Errorf("expected ranks on ranked ballots only, got %+v and %+v", ace, fps.Ballots[0])
+	}
+
+	// A fresh export gives fresh IDs
+	again, err := dataset.Build(ctx, q, polls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Polls[1].Ballots[0].Voter == ace.Voter {
+		t.Error("expected voter IDs to change between exports")
+	}
+
+	out.Reset()
+	if err := dataset.WriteCSV(&out, ds); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 || strings.Join(rows[0], ",") != strings.Join(dataset.CSVHeader, ",") {
+		t.Fatalf("expected a header and a row per choice, got %v", rows)
+	}
+	if last := rows[4]; last[1] != "Best Map" || last[3] != ace.Voter || last[5] != "Doom" || last[6] != "2" {
+		t.Errorf("unexpected row %v", last)
+	}
+}
@@ -0,0 +1,324 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/palm-arcade/votigo/migrations"
+	"github.com/pressly/goose/v3"
+)
+
+// ArchiveFormat is the version of the Archive layout itself, as opposed to
+// the schema of the tables inside it. It only changes if the envelope does.
+const ArchiveFormat = 1
+
+// Archive is a whole database as JSON: polls, options, ballots, history,
+// the audit log and settings, as rows keyed by column name. Schema is the
+// migration version of the database it came from. Nicknames stay as they
+// are stored, so an encrypted database's archive needs the same key.
+type Archive struct {
+	Format     int                         `json:"format"`
+	Schema     int64                       `json:"schema"`
+	ExportedAt time.Time                   `json:"exported_at"`
+	Tables     map[string][]map[string]any `json:"tables"`
+}
+
+// Reasons ImportArchive refuses an archive
+var (
+	ErrArchiveTooNew = errors.New("the archive was made by a newer version of votigo")
+	ErrNotEmpty      = errors.New("the database already has polls or ballots")
+)
+
+// archiveSkip are tables left out of archives: migration bookkeeping,
+// state only useful to a running server, and the key observer links are
+// signed with, so restoring an archive doesn't bring back links revoked since
+var archiveSkip = []string{"goose_db_version", "sessions", "idempotency_keys", "observer_key"}
+
+// ReadArchive decodes an archive, keeping whole numbers exact
+func ReadArchive(data []byte) (Archive, error) {
+	var a Archive
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&a); err != nil {
+		return a, fmt.Errorf("not a votigo archive: %w", err)
+	}
+	if a.Format == 0 || a.Tables == nil {
+		return a, errors.New("not a votigo archive: format or tables missing")
+	}
+	return a, nil
+}
+
+// ExportArchive dumps every table worth keeping from conn
+func ExportArchive(ctx context.Context, conn *sql.DB) (Archive, error) {
+	schema, err := goose.GetDBVersionContext(ctx, conn)
+	if err != nil {
+		return Archive{}, err
+	}
+	tables, err := dumpTables(ctx, conn)
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{Format: ArchiveFormat, Schema: schema, ExportedAt: time.Now().UTC(), Tables: tables}, nil
+}
+
+// ImportArchive loads a into conn, which must be migrated and have no polls
+// or ballots yet. IDs are kept, so links and receipts still work. An archive
+// from an older schema is converted first by loading it into a scratch
+// database at its version and running the migrations since, the same ones
+// that upgraded databases in place, Go backfills included.
+func ImportArchive(ctx context.Context, conn *sql.DB, a Archive) error {
+	if a.Format > ArchiveFormat {
+		return &Error{Kind: ErrConflict, Err: fmt.Errorf("%w (archive format %d)", ErrArchiveTooNew, a.Format)}
+	}
+	schema, err := goose.GetDBVersionContext(ctx, conn)
+	if err != nil {
+		return err
+	}
+	switch {
+	case a.Schema > schema:
+		return &Error{Kind: ErrConflict, Err: fmt.Errorf("%w (schema %d, this one reads up to %d)", ErrArchiveTooNew, a.Schema, schema)}
+	case a.Schema < schema:
+		if a, err = UpgradeArchive(ctx, a, schema); err != nil {
+			return fmt.Errorf("convert archive from schema %d: %w", a.Schema, err)
+		}
+	}
+
+	return InTx(ctx, conn, func(q *Queries) error {
+		empty, err := q.Empty(ctx)
+		if err != nil {
+			return err
+		}
+		if !empty {
+			return &Error{Kind: ErrConflict, Err: ErrNotEmpty}
+		}
+		// Rows go in table by table, so a poll may arrive before the poll it
+		// opens after; the references are checked once everything is in
+		if _, err := q.db.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON"); err != nil {
+			return err
+		}
+		return loadTables(ctx, q.db, a.Tables)
+	})
+}
+
+// Empty reports whether the database has no polls and no ballots yet, as
+// when it was just created
+func (q *Queries) Empty(ctx context.Context) (bool, error) {
+	var n int64
+	err := q.db.QueryRowContext(ctx, "SELECT (SELECT COUNT(*) FROM categories) + (SELECT COUNT(*) FROM votes)").Scan(&n)
+	return n == 0, err
+}
+
+// UpgradeArchive converts a to the given schema version by loading it into
+// a scratch in-memory database at a's version and migrating that
+func UpgradeArchive(ctx context.Context, a Archive, schema int64) (Archive, error) {
+	// Without foreign keys, as migrations that rebuild a table would
+	// otherwise cascade deletes to the rows that refer to it
+	scratch, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return a, err
+	}
+	defer scratch.Close()
+	scratch.SetMaxOpenConns(1) // each connection would get its own database
+
+	p, err := goose.NewProvider(goose.DialectSQLite3, scratch, migrations.FS)
+	if err != nil {
+		return a, err
+	}
+	if _, err := p.UpTo(ctx, a.Schema); err != nil {
+		return a, err
+	}
+	err = InTx(ctx, scratch, func(q *Queries) error {
+		return loadTables(ctx, q.db, a.Tables)
+	})
+	if err != nil {
+		return a, err
+	}
+	if _, err := p.UpTo(ctx, schema); err != nil {
+		return a, err
+	}
+
+	tables, err := dumpTables(ctx, scratch)
+	if err != nil {
+		return a, err
+	}
+	return Archive{Format: ArchiveFormat, Schema: schema, ExportedAt: a.ExportedAt, Tables: tables}, nil
+}
+
+// column is a table column as PRAGMA table_info describes it
+type column struct {
+	name, kind string
+}
+
+// archiveValue is how a column is selected for an archive. Timestamps are
+// read as the text stored, not parsed, so they go back exactly as they were,
+// and blobs are hex.
+func (c column) archiveValue() string {
+	switch strings.ToUpper(c.kind) {
+	case "DATETIME", "DATE", "TIMESTAMP":
+		return fmt.Sprintf("CAST(%q AS TEXT)", c.name)
+	case "BLOB":
+		return fmt.Sprintf("hex(%q)", c.name)
+	}
+	return fmt.Sprintf("%q", c.name)
+}
+
+// tableColumns lists a table's columns, none for a table that doesn't exist
+func tableColumns(ctx context.Context, conn DBTX, table string) ([]column, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT name, type FROM pragma_table_info(?) ORDER BY cid", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []column
+	for rows.Next() {
+		var c column
+		if err := rows.Scan(&c.name, &c.kind); err != nil {
+			return nil, err
+		}
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+// dumpTables reads every table but those in archiveSkip
+func dumpTables(ctx context.Context, conn DBTX) (map[string][]map[string]any, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if !slices.Contains(archiveSkip, name) {
+			names = append(names, name)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tables := make(map[string][]map[string]any, len(names))
+	for _, name := range names {
+		cols, err := tableColumns(ctx, conn, name)
+		if err != nil {
+			return nil, err
+		}
+		selects := make([]string, len(cols))
+		for i, c := range cols {
+			selects[i] = c.archiveValue()
+		}
+		rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %q ORDER BY rowid", strings.Join(selects, ", "), name))
+		if err != nil {
+			return nil, err
+		}
+		tables[name] = []map[string]any{}
+		for rows.Next() {
+			values := make([]any, len(cols))
+			ptrs := make([]any, len(cols))
+			for i := range values {
+				ptrs[i] = &values[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			row := make(map[string]any, len(cols))
+			for i, c := range cols {
+				row[c.name] = values[i]
+			}
+			tables[name] = append(tables[name], row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// loadTables inserts archived rows, replacing rows with the same key so
+// singletons a migration created, like the avatar salt, take the archive's
+// values
+func loadTables(ctx context.Context, conn DBTX, tables map[string][]map[string]any) error {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	// Triggers bump a poll's results_version as its options and ballots go
+	// in, so polls go in last to keep the archived value
+	if i := slices.Index(names, "categories"); i >= 0 {
+		names = append(slices.Delete(names, i, i+1), "categories")
+	}
+
+	for _, name := range names {
+		cols, err := tableColumns(ctx, conn, name)
+		if err != nil {
+			return err
+		}
+		if len(cols) == 0 || slices.Contains(archiveSkip, name) {
+			return fmt.Errorf("unknown table %q", name)
+		}
+		kinds := make(map[string]string, len(cols))
+		for _, c := range cols {
+			kinds[c.name] = strings.ToUpper(c.kind)
+		}
+
+		for i, row := range tables[name] {
+			keys := make([]string, 0, len(row))
+			for key := range row {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+
+			quoted := make([]string, len(keys))
+			args := make([]any, len(keys))
+			for j, key := range keys {
+				kind, ok := kinds[key]
+				if !ok {
+					return fmt.Errorf("%s row %d: unknown column %q", name, i+1, key)
+				}
+				quoted[j] = fmt.Sprintf("%q", key)
+				if args[j], err = columnValue(kind, row[key]); err != nil {
+					return fmt.Errorf("%s row %d: %s: %w", name, i+1, key, err)
+				}
+			}
+			query := fmt.Sprintf("INSERT OR REPLACE INTO %q (%s) VALUES (%s)",
+				name, strings.Join(quoted, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", "))
+			if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+				return fmt.Errorf("%s row %d: %w", name, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// columnValue turns a value decoded from JSON back into what the column
+// stored
+func columnValue(kind string, v any) (any, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	case string:
+		if kind == "BLOB" {
+			return hex.DecodeString(v)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Audit actors for events not attributed to a voter nickname
+const (
+	ActorAdmin   = "admin"
+	ActorCLI     = "cli"
+	ActorServer  = "server"  // background jobs, such as auto-archiving
+	ActorStation = "station" // a kiosk pairing itself
+)
+
+// Audit actions recorded in audit_events
+const (
+	AuditVote            = "vote"
+	AuditTestVote        = "vote.test"
+	AuditCategoryCreate  = "category.create"
+	AuditCategoryUpdate  = "category.update"
+	AuditCategoryOpen    = "category.open"
+	AuditCategoryClose   = "category.close"
+	AuditCategoryReopen  = "category.reopen"
+	AuditCategoryArchive = "category.archive"
+	AuditCategoryRunoff  = "category.runoff"
+	AuditOptionAdd       = "option.add"
+	AuditOptionRemove    = "option.remove"
+	AuditOptionRetire    = "option.retire"
+	AuditOptionSeed      = "option.seed"
+	AuditOptionImage     = "option.image"
+	AuditOptionDetails   = "option.details"
+	AuditSettingUpdate   = "setting.update"
+	AuditHistoryPurge    = "history.purge"
+	AuditVoterForget     = "voter.forget"
+	AuditRosterSync      = "roster.sync"
+	AuditRosterTags      = "roster.tags"
+	AuditBroadcast       = "broadcast"
+	AuditSoundUpload     = "sound.upload"
+	AuditSoundDelete     = "sound.delete"
+	AuditDatabaseRepair  = "database.repair"
+	AuditAdminPassword   = "admin.password"
+	AuditStationAdd      = "station.add"
+	AuditStationPair     = "station.pair"
+	AuditStationRevoke   = "station.revoke"
+	AuditVoteResolve     = "vote.resolve"
+	AuditTestMode        = "category.test_mode"
+	AuditTestPurge       = "category.test_purge"
+	AuditBallotPurge     = "category.ballot_purge"
+	AuditObserverLink    = "observer.link"
+	AuditObserverRevoke  = "observer.revoke"
+	AuditSeasonFinalize  = "season.finalize"
+	AuditSeasonRemove    = "season.remove"
+	AuditBallotInvalid   = "ballot.invalidate"
+	AuditBallotRestore   = "ballot.restore"
+)
+
+// AuditOrigin is where an audited request came from: the kiosk station it
+// was made at (0 for none) and the client address
+type AuditOrigin struct {
+	StationID int64
+	Address   string
+}
+
+// RecordAudit appends an event to the audit log. A categoryID of 0 is stored as NULL.
+func (q *Queries) RecordAudit(ctx context.Context, actor, action string, categoryID int64, detail string) error {
+	return q.RecordAuditFrom(ctx, AuditOrigin{}, actor, action, categoryID, detail)
+}
+
+// RecordAuditFrom is RecordAudit for an event whose origin is known, such
+// as a ballot. A StationID of 0, not at any station, is stored as NULL.
+func (q *Queries) RecordAuditFrom(ctx context.Context, from AuditOrigin, actor, action string, categoryID int64, detail string) error {
+	return q.CreateAuditEvent(ctx, CreateAuditEventParams{
+		Actor:      actor,
+		Action:     action,
+		CategoryID: sql.NullInt64{Int64: categoryID, Valid: categoryID != 0},
+		Detail:     detail,
+		StationID:  sql.NullInt64{Int64: from.StationID, Valid: from.StationID != 0},
+		Address:    from.Address,
+	})
+}
@@ -0,0 +1,194 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Problem is something wrong with the database that Check found. Table and
+// RowID are empty for damage to the file itself.
+type Problem struct {
+	Table  string
+	RowID  int64
+	Detail string
+	Fix    string // what Repair does about it; empty if it can't
+
+	repair string // statement Repair runs, with RowID as its argument
+}
+
+func (p Problem) String() string {
+	if p.Table == "" {
+		return "database: " + p.Detail
+	}
+	return fmt.Sprintf("%s #%d: %s", p.Table, p.RowID, p.Detail)
+}
+
+// Check looks for damage to the database file (PRAGMA integrity_check),
+// rows that refer to something that no longer exists, and ballots that
+// break the rules voting enforces. Damage to the file stops the other
+// checks, as their results couldn't be trusted.
+func Check(ctx context.Context, conn *sql.DB) ([]Problem, error) {
+	problems, err := checkIntegrity(ctx, conn)
+	if err != nil || len(problems) > 0 {
+		return problems, err
+	}
+	for _, check := range []func(context.Context, *sql.DB) ([]Problem, error){CheckReferences, checkSelections} {
+		found, err := check(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, found...)
+	}
+	return problems, nil
+}
+
+// Repair fixes the problems it can, in one transaction, and records the
+// repair in the audit log as actor. It returns how many it fixed.
+func Repair(ctx context.Context, conn *sql.DB, problems []Problem, actor string) (int, error) {
+	fixed := 0
+	err := InTx(ctx, conn, func(q *Queries) error {
+		for _, p := range problems {
+			if p.repair == "" {
+				continue
+			}
+			if _, err := q.db.ExecContext(ctx, p.repair, p.RowID); err != nil {
+				return fmt.Errorf("repair %s: %w", p, err)
+			}
+			fixed++
+		}
+		if fixed == 0 {
+			return nil
+		}
+		return q.RecordAudit(ctx, actor, AuditDatabaseRepair, 0, fmt.Sprintf("fixed %d of %d problems", fixed, len(problems)))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return fixed, nil
+}
+
+// checkIntegrity reports damage to the database file itself, which only
+// restoring a backup can fix
+func checkIntegrity(ctx context.Context, conn *sql.DB) ([]Problem, error) {
+	rows, err := conn.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []Problem
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return nil, err
+		}
+		if msg != "ok" {
+			problems = append(problems, Problem{Detail: msg})
+		}
+	}
+	return problems, rows.Err()
+}
+
+// CheckReferences lists rows that refer to a poll, vote or option that no
+// longer exists. Open enforces foreign keys, so these only turn up in
+// databases written without them, by older versions or other tools. The fix
+// is what the foreign key would have done: delete the row, or clear the
+// reference if it is optional.
+func CheckReferences(ctx context.Context, conn *sql.DB) ([]Problem, error) {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT c."table", c.rowid, c.parent, f."from", f.on_delete
+		FROM pragma_foreign_key_check AS c
+		JOIN pragma_foreign_key_list(c."table") AS f ON f.id = c.fkid AND f.seq = 0
+		ORDER BY c."table", c.rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type orphan struct {
+		Problem
+		parent, column, onDelete string
+	}
+	var orphans []orphan
+	for rows.Next() {
+		var o orphan
+		if err := rows.Scan(&o.Table, &o.RowID, &o.parent, &o.column, &o.onDelete); err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	problems := make([]Problem, len(orphans))
+	for i, o := range orphans {
+		// Names come from the schema, not from input, so quoting is enough
+		var ref int64
+		query := fmt.Sprintf(`SELECT %q FROM %q WHERE rowid = ?`, o.column, o.Table)
+		if err := conn.QueryRowContext(ctx, query, o.RowID).Scan(&ref); err != nil {
+			return nil, err
+		}
+		o.Detail = fmt.Sprintf("%s %d is missing from %s", o.column, ref, o.parent)
+		if strings.EqualFold(o.onDelete, "SET NULL") {
+			o.Fix = "clear " + o.column
+			o.repair = fmt.Sprintf(`UPDATE %q SET %q = NULL WHERE rowid = ?`, o.Table, o.column)
+		} else {
+			o.Fix = "delete the row"
+			o.repair = fmt.Sprintf(`DELETE FROM %q WHERE rowid = ?`, o.Table)
+		}
+		problems[i] = o.Problem
+	}
+	return problems, nil
+}
+
+// checkSelections finds ballot choices voting would have refused: an option
+// from another poll, or a rank a ranked poll doesn't allow (missing, below
+// 1 or past max_rank). The fix deletes the choice; the rest of the ballot
+// stands.
+func checkSelections(ctx context.Context, conn *sql.DB) ([]Problem, error) {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT vs.id, v.id, v.category_id, o.category_id, c.vote_type, vs.rank, COALESCE(c.max_rank, 3)
+		FROM vote_selections vs
+		JOIN votes v ON v.id = vs.vote_id
+		JOIN options o ON o.id = vs.option_id
+		JOIN categories c ON c.id = v.category_id
+		ORDER BY vs.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []Problem
+	for rows.Next() {
+		var (
+			id, voteID, category, optionCategory, maxRank int64
+			voteType                                      string
+			rank                                          sql.NullInt64
+		)
+		if err := rows.Scan(&id, &voteID, &category, &optionCategory, &voteType, &rank, &maxRank); err != nil {
+			return nil, err
+		}
+
+		var detail string
+		switch {
+		case optionCategory != category:
+			detail = fmt.Sprintf("vote %d in poll %d picks an option from poll %d", voteID, category, optionCategory)
+		case voteType != "ranked":
+		case !rank.Valid:
+			detail = fmt.Sprintf("vote %d in ranked poll %d has a choice without a rank", voteID, category)
+		case rank.Int64 < 1 || rank.Int64 > maxRank:
+			detail = fmt.Sprintf("vote %d in poll %d ranks a choice %d, outside 1-%d", voteID, category, rank.Int64, maxRank)
+		}
+		if detail != "" {
+			problems = append(problems, Problem{
+				Table: "vote_selections", RowID: id, Detail: detail,
+				Fix: "delete the choice", repair: `DELETE FROM vote_selections WHERE rowid = ?`,
+			})
+		}
+	}
+	return problems, rows.Err()
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ConflictWindow is how soon after a ballot a re-vote for the same nickname
+// from another station or address is taken for two devices fighting over
+// one voter rather than a change of mind
+const ConflictWindow = 10 * time.Second
+
+// Which ballot of a conflict an admin kept
+const (
+	KeptFirst  = "first"
+	KeptSecond = "second"
+)
+
+// Why ResolveConflict refuses. They come wrapped as ErrConflict.
+var (
+	ErrConflictResolved = errors.New("the conflict has already been resolved")
+	ErrVotedSince       = errors.New("the voter has voted again since")
+)
+
+// Resolved reports whether an admin has kept one of the conflict's ballots
+func (c VoteConflict) Resolved() bool {
+	return c.ResolvedAt.Valid
+}
+
+// RecordRevoteConflict records a conflict if vote, just re-cast from from,
+// replaced a ballot cast from another station or address less than
+// ConflictWindow ago. Call it after UpsertVote and before the new ballot's
+// audit event is recorded, as the previous event is where the earlier
+// ballot's origin is kept.
+func (q *Queries) RecordRevoteConflict(ctx context.Context, vote Vote, from AuditOrigin) (bool, error) {
+	if vote.Version < 2 {
+		return false, nil
+	}
+	prev, err := q.GetLastVoteOrigin(ctx, GetLastVoteOriginParams{
+		CategoryID: sql.NullInt64{Int64: vote.CategoryID, Valid: true},
+		Actor:      vote.Nickname,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !prev.CreatedAt.Valid || time.Since(prev.CreatedAt.Time) >= ConflictWindow {
+		return false, nil
+	}
+	if prev.StationID.Int64 == from.StationID && prev.Address == from.Address {
+		return false, nil
+	}
+	return true, q.CreateVoteConflict(ctx, CreateVoteConflictParams{
+		VoteID:          vote.ID,
+		Version:         vote.Version,
+		FirstStationID:  prev.StationID,
+		FirstAddress:    prev.Address,
+		SecondStationID: sql.NullInt64{Int64: from.StationID, Valid: from.StationID != 0},
+		SecondAddress:   from.Address,
+	})
+}
+
+// ResolveConflict settles conflict id by keeping one of its ballots
+// (KeptFirst or KeptSecond) and returns it. The second ballot is the one
+// standing, so keeping it only closes the conflict; keeping the first
+// restores it as a new version of the vote, and the second goes to the
+// vote's history like any replaced ballot. A missing conflict is
+// ErrNotFound; one already resolved, whose voter has voted again since, or
+// in an archived poll (ErrArchived) is ErrConflict. Call it inside a
+// transaction (see InTx).
+func (q *Queries) ResolveConflict(ctx context.Context, id int64, kept string) (VoteConflict, error) {
+	c, err := q.GetVoteConflict(ctx, id)
+	if err != nil {
+		return c, Classify(err)
+	}
+	if c.Resolved() {
+		return c, &Error{Kind: ErrConflict, Err: ErrConflictResolved}
+	}
+	vote, err := q.GetVote(ctx, c.VoteID)
+	if err != nil {
+		return c, Classify(err)
+	}
+	if err := q.checkNotArchived(ctx, vote.CategoryID); err != nil {
+		return c, err
+	}
+
+	if kept == KeptFirst {
+		if vote.Version != c.Version {
+			return c, &Error{Kind: ErrConflict, Err: ErrVotedSince}
+		}
+		if err := q.BumpVoteVersion(ctx, vote.ID); err != nil {
+			return c, fmt.Errorf("bump version: %w", err)
+		}
+		if err := q.ArchiveVoteSelections(ctx, vote.ID); err != nil {
+			return c, fmt.Errorf("archive selections: %w", err)
+		}
+		if err := q.DeleteVoteSelections(ctx, vote.ID); err != nil {
+			return c, fmt.Errorf("clear selections: %w", err)
+		}
+		err = q.RestoreVoteSelections(ctx, RestoreVoteSelectionsParams{VoteID: vote.ID, Version: c.Version - 1})
+		if err != nil {
+			return c, fmt.Errorf("restore selections: %w", err)
+		}
+	}
+
+	if err := q.ResolveVoteConflict(ctx, ResolveVoteConflictParams{Kept: kept, ID: id}); err != nil {
+		return c, err
+	}
+	return q.GetVoteConflict(ctx, id)
+}
@@ -2,28 +2,73 @@ package db
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/palm-arcade/votigo/migrations"
 	"github.com/pressly/goose/v3"
 	_ "modernc.org/sqlite"
 )
 
+// Open opens the database at dsn with foreign keys enforced, so deleting a
+// poll, vote or option cascades to the rows that refer to it
 func Open(dsn string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", dsn)
+	// The pragma goes in the DSN because it only applies to the connection
+	// that runs it, and the pool opens more as it needs them
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("sqlite", dsn+sep+"_pragma=foreign_keys(1)")
 	if err != nil {
 		return nil, err
 	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// ErrInMemory is returned by OpenReadOnly for an in-memory database, which
+// only the connection that created it can see
+var ErrInMemory = errors.New("in-memory databases can't be opened twice")
 
-	// Enable foreign keys
-	_, err = db.Exec("PRAGMA foreign_keys = ON")
+// OpenReadOnly opens a second handle on the database file at path that can
+// only read, so queries like the results tallies don't queue behind writes.
+// Pair it with EnableWAL on the writing handle; otherwise readers and the
+// writer still lock each other out.
+func OpenReadOnly(path string) (*sql.DB, error) {
+	if path == "" || path == ":memory:" || strings.Contains(path, "mode=memory") {
+		return nil, ErrInMemory
+	}
+	uri := "file:" + strings.NewReplacer("%", "%25", "#", "%23").Replace(strings.TrimPrefix(path, "file:"))
+	db, err := sql.Open("sqlite", uri+"?mode=ro&_pragma=query_only(1)")
 	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
 		db.Close()
 		return nil, err
 	}
-
 	return db, nil
 }
 
+// EnableWAL switches the database to write-ahead logging, which lets reads
+// carry on while a write is in progress. The mode is stored in the file, so
+// it sticks for every later connection.
+func EnableWAL(db *sql.DB) error {
+	var mode string
+	if err := db.QueryRow("PRAGMA journal_mode = WAL").Scan(&mode); err != nil {
+		return err
+	}
+	if !strings.EqualFold(mode, "wal") {
+		return fmt.Errorf("database stayed in %s journal mode", mode)
+	}
+	return nil
+}
+
 func Migrate(db *sql.DB) error {
 	goose.SetBaseFS(migrations.FS)
 
@@ -33,3 +78,8 @@ func Migrate(db *sql.DB) error {
 
 	return goose.Up(db, ".")
 }
+
+// QuietMigrations stops Migrate from logging the migrations it applies
+func QuietMigrations() {
+	goose.SetLogger(goose.NopLogger())
+}
@@ -0,0 +1,206 @@
+package db
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Nicknames are encrypted deterministically (a synthetic IV derived from an
+// HMAC of the plaintext), so equal nicknames encrypt to equal values and the
+// UNIQUE(category_id, nickname) constraint and nickname lookups keep working.
+// This hides who voted for what if the database file leaks; it does not hide
+// how many ballots one (unknown) voter cast.
+const (
+	sealedPrefix     = "enc1:"
+	kdfIterations    = 600_000
+	encryptionCheck  = "votigo"
+	encryptionSaltSz = 16
+)
+
+var (
+	// ErrDatabaseEncrypted means the database holds encrypted nicknames but no
+	// passphrase was given.
+	ErrDatabaseEncrypted = errors.New("database is encrypted: a passphrase is required")
+	// ErrWrongPassphrase means the passphrase does not match the database.
+	ErrWrongPassphrase = errors.New("wrong database passphrase")
+)
+
+// NicknameCipher encrypts voter nicknames at rest. A nil *NicknameCipher is
+// valid and stores nicknames in plain text.
+type NicknameCipher struct {
+	block  cipher.Block
+	macKey []byte
+}
+
+func newNicknameCipher(passphrase string, salt []byte) (*NicknameCipher, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, kdfIterations, 64)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key[:32])
+	if err != nil {
+		return nil, err
+	}
+	return &NicknameCipher{block: block, macKey: key[32:]}, nil
+}
+
+// Seal encrypts a (normalized) nickname for storage
+func (c *NicknameCipher) Seal(nickname string) string {
+	if c == nil {
+		return nickname
+	}
+	iv := c.syntheticIV([]byte(nickname))
+	out := make([]byte, len(iv)+len(nickname))
+	copy(out, iv)
+	cipher.NewCTR(c.block, iv).XORKeyStream(out[len(iv):], []byte(nickname))
+	return sealedPrefix + base64.RawURLEncoding.EncodeToString(out)
+}
+
+// Open decrypts a stored nickname. Values that were never sealed, such as
+// ForgottenActor, are returned unchanged.
+func (c *NicknameCipher) Open(stored string) (string, error) {
+	raw, ok := strings.CutPrefix(stored, sealedPrefix)
+	if !ok {
+		return stored, nil
+	}
+	if c == nil {
+		return "", ErrDatabaseEncrypted
+	}
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil || len(data) < aes.BlockSize {
+		return "", fmt.Errorf("malformed encrypted nickname")
+	}
+	iv, ct := data[:aes.BlockSize], data[aes.BlockSize:]
+	plain := make([]byte, len(ct))
+	cipher.NewCTR(c.block, iv).XORKeyStream(plain, ct)
+	if !hmac.Equal(iv, c.syntheticIV(plain)) {
+		return "", ErrWrongPassphrase
+	}
+	return string(plain), nil
+}
+
+// Reveal is Open for display purposes: undecryptable values are masked
+func (c *NicknameCipher) Reveal(stored string) string {
+	nickname, err := c.Open(stored)
+	if err != nil {
+		return "[encrypted]"
+	}
+	return nickname
+}
+
+func (c *NicknameCipher) syntheticIV(plain []byte) []byte {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write(plain)
+	return mac.Sum(nil)[:aes.BlockSize]
+}
+
+// SetupEncryption returns the nickname cipher for the database. With an
+// empty passphrase it returns nil unless the database is already encrypted.
+// The first time a passphrase is given, existing nicknames are encrypted in
+// place (in-flight idempotency keys are simply dropped).
+func SetupEncryption(ctx context.Context, conn *sql.DB, passphrase string) (*NicknameCipher, error) {
+	q := New(conn)
+
+	meta, err := q.GetEncryptionMeta(ctx)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if passphrase == "" {
+			return nil, nil
+		}
+		return enableEncryption(ctx, conn, passphrase)
+	case err != nil:
+		return nil, err
+	case passphrase == "":
+		return nil, ErrDatabaseEncrypted
+	}
+
+	c, err := newNicknameCipher(passphrase, meta.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if check, err := c.Open(meta.CheckValue); err != nil || check != encryptionCheck {
+		return nil, ErrWrongPassphrase
+	}
+	return c, nil
+}
+
+func enableEncryption(ctx context.Context, conn *sql.DB, passphrase string) (*NicknameCipher, error) {
+	salt := make([]byte, encryptionSaltSz)
+	rand.Read(salt)
+
+	c, err := newNicknameCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := New(tx)
+
+	nicknames, err := qtx.ListVoteNicknames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nicknames {
+		err := qtx.RenameVoter(ctx, RenameVoterParams{NewNickname: c.Seal(n), OldNickname: n})
+		if err != nil {
+			return nil, fmt.Errorf("encrypt nickname: %w", err)
+		}
+	}
+
+	attendees, err := qtx.ListAttendees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attendees {
+		err := qtx.RenameAttendee(ctx, RenameAttendeeParams{NewNickname: c.Seal(a.Nickname), OldNickname: a.Nickname})
+		if err != nil {
+			return nil, fmt.Errorf("encrypt roster nickname: %w", err)
+		}
+	}
+
+	actors, err := qtx.ListVoteAuditActors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range actors {
+		if a == ForgottenActor {
+			continue
+		}
+		_, err := qtx.AnonymizeVoteAuditEvents(ctx, AnonymizeVoteAuditEventsParams{Replacement: c.Seal(a), Actor: a})
+		if err != nil {
+			return nil, fmt.Errorf("encrypt audit actor: %w", err)
+		}
+	}
+
+	if err := qtx.DeleteAllIdempotencyKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	err = qtx.CreateEncryptionMeta(ctx, CreateEncryptionMetaParams{
+		Salt:       salt,
+		CheckValue: c.Seal(encryptionCheck),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
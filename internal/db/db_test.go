@@ -1,9 +1,24 @@
 package db_test
 
 import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/migrations"
+	"github.com/pressly/goose/v3"
 )
 
 func TestOpen(t *testing.T) {
@@ -43,3 +58,1854 @@ func TestMigrate(t *testing.T) {
 		t.Fatalf("categories table not found: %v", err)
 	}
 }
+
+func TestMigrateBackfillsSlugs(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+
+	// Stop before the Go backfill, with polls the SQL one in 00015 skipped
+	goose.SetBaseFS(migrations.FS)
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := goose.UpTo(conn, ".", 24); err != nil {
+		t.Fatalf("failed to migrate to 24: %v", err)
+	}
+	for _, stmt := range []string{
+		"INSERT INTO categories (name, vote_type, slug) VALUES ('Best Game', 'single', 'best-game')",
+		"INSERT INTO categories (name, vote_type) VALUES ('Best  Game!', 'single')",
+		"INSERT INTO categories (name, vote_type) VALUES ('Pokémon Cup', 'single')",
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	cats, err := db.New(conn).ListCategories(t.Context())
+	if err != nil {
+		t.Fatalf("failed to list categories: %v", err)
+	}
+	var got []string
+	for _, c := range cats {
+		got = append(got, c.Slug.String)
+	}
+	if want := []string{"best-game", "best-game-2", "pok-mon-cup"}; !slices.Equal(got, want) {
+		t.Errorf("slugs = %q, want %q", got, want)
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	if _, err := db.OpenReadOnly(":memory:"); !errors.Is(err, db.ErrInMemory) {
+		t.Errorf("expected ErrInMemory, got %v", err)
+	}
+
+	// # and % mean something in the file: URI the read-only handle opens
+	path := filepath.Join(t.TempDir(), "votes #1 %25.db")
+	conn, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if err := db.EnableWAL(conn); err != nil {
+		t.Fatalf("failed to enable WAL: %v", err)
+	}
+
+	reads, err := db.OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("failed to open read-only: %v", err)
+	}
+	defer reads.Close()
+
+	// Reads see committed writes, even with a write transaction open
+	ctx := context.Background()
+	cat, err := db.New(conn).CreateCategory(ctx, db.CreateCategoryParams{Name: "Best Game", VoteType: "single", Status: "open", ShowResults: "live"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := conn.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("UPDATE categories SET name = 'Renamed'"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.New(reads).GetCategory(ctx, cat.ID)
+	if err != nil || got.Name != "Best Game" {
+		t.Errorf("expected to read the committed poll during a write, got %q, %v", got.Name, err)
+	}
+
+	if _, err := reads.Exec("DELETE FROM categories"); err == nil {
+		t.Error("expected the read-only handle to refuse writes")
+	}
+}
+
+func TestDeleteIdempotencyKeysBefore(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	q := db.New(conn)
+	cat, err := q.CreateCategory(t.Context(), db.CreateCategoryParams{
+		Name: "Poll", VoteType: "single", Status: "open", ShowResults: "live",
+	})
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+
+	claimed, err := q.ClaimIdempotencyKey(t.Context(), db.ClaimIdempotencyKeyParams{Key: "k", CategoryID: cat.ID, Nickname: "p"})
+	if err != nil || claimed != 1 {
+		t.Fatalf("expected key to be claimed, got %d, %v", claimed, err)
+	}
+	claimed, _ = q.ClaimIdempotencyKey(t.Context(), db.ClaimIdempotencyKeyParams{Key: "k", CategoryID: cat.ID, Nickname: "p"})
+	if claimed != 0 {
+		t.Fatalf("expected second claim to be a no-op, got %d", claimed)
+	}
+
+	// Nothing is older than an hour ago
+	past := sql.NullTime{Time: time.Now().UTC().Add(-time.Hour), Valid: true}
+	if n, _ := q.DeleteIdempotencyKeysBefore(t.Context(), past); n != 0 {
+		t.Errorf("expected fresh key to survive, deleted %d", n)
+	}
+
+	future := sql.NullTime{Time: time.Now().UTC().Add(time.Hour), Valid: true}
+	if n, _ := q.DeleteIdempotencyKeysBefore(t.Context(), future); n != 1 {
+		t.Errorf("expected expired key to be deleted, deleted %d", n)
+	}
+}
+
+func TestSetupEncryption(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	q := db.New(conn)
+	cat, err := q.CreateCategory(t.Context(), db.CreateCategoryParams{
+		Name: "Poll", VoteType: "single", Status: "open", ShowResults: "live",
+	})
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+
+	// Without a passphrase nothing changes
+	plain, err := db.SetupEncryption(t.Context(), conn, "")
+	if err != nil || plain != nil {
+		t.Fatalf("expected no cipher for an unencrypted database, got %v, %v", plain, err)
+	}
+
+	// A vote cast before encryption is turned on
+	if _, err := q.UpsertVote(t.Context(), db.UpsertVoteParams{CategoryID: cat.ID, Nickname: "alice"}); err != nil {
+		t.Fatalf("failed to vote: %v", err)
+	}
+	if err := q.UpsertAttendee(t.Context(), db.UpsertAttendeeParams{Nickname: "alice", Seat: "A1"}); err != nil {
+		t.Fatalf("failed to add attendee: %v", err)
+	}
+
+	c, err := db.SetupEncryption(t.Context(), conn, "hunter2")
+	if err != nil {
+		t.Fatalf("failed to enable encryption: %v", err)
+	}
+
+	voters, _ := q.ListVotersByCategory(t.Context(), cat.ID)
+	if len(voters) != 1 || voters[0] == "alice" {
+		t.Fatalf("expected existing nickname to be encrypted, got %v", voters)
+	}
+	if got, err := c.Open(voters[0]); err != nil || got != "alice" {
+		t.Errorf("expected to decrypt alice, got %q, %v", got, err)
+	}
+	if c.Seal("alice") != voters[0] {
+		t.Error("expected deterministic encryption so lookups still match")
+	}
+	if attendees, _ := q.ListAttendees(t.Context()); len(attendees) != 1 || attendees[0].Nickname != voters[0] {
+		t.Errorf("expected the roster encrypted to match the ballot, got %v", attendees)
+	}
+
+	if _, err := db.SetupEncryption(t.Context(), conn, ""); err != db.ErrDatabaseEncrypted {
+		t.Errorf("expected ErrDatabaseEncrypted, got %v", err)
+	}
+	if _, err := db.SetupEncryption(t.Context(), conn, "wrong"); err != db.ErrWrongPassphrase {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	again, err := db.SetupEncryption(t.Context(), conn, "hunter2")
+	if err != nil || again.Seal("alice") != voters[0] {
+		t.Errorf("expected reopening with the passphrase to give the same cipher, got %v", err)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	ctx := context.Background()
+	q := db.New(conn)
+
+	on, err := q.SettingBool(ctx, db.SettingHighContrast)
+	if err != nil || on {
+		t.Fatalf("expected high contrast to default to off, got %v (%v)", on, err)
+	}
+
+	value, err := q.SetSetting(ctx, db.SettingHighContrast, "on")
+	if err != nil {
+		t.Fatalf("failed to set setting: %v", err)
+	}
+	if value != "true" {
+		t.Errorf("expected value stored as %q, got %q", "true", value)
+	}
+	if on, _ := q.SettingBool(ctx, db.SettingHighContrast); !on {
+		t.Error("expected high contrast to be on")
+	}
+
+	if _, err := q.SetSetting(ctx, db.SettingHighContrast, "maybe"); err == nil {
+		t.Error("expected an invalid bool to be rejected")
+	}
+	if _, err := q.SetSetting(ctx, "no_such_setting", "1"); !errors.Is(err, db.ErrUnknownSetting) {
+		t.Errorf("expected ErrUnknownSetting, got %v", err)
+	}
+
+	values, err := q.SettingValues(ctx)
+	if err != nil {
+		t.Fatalf("failed to list settings: %v", err)
+	}
+	if values[db.SettingHighContrast] != "true" {
+		t.Errorf("expected listed value true, got %q", values[db.SettingHighContrast])
+	}
+
+	if at, err := q.SettingTime(ctx, db.SettingCeremonyAt); err != nil || !at.IsZero() {
+		t.Errorf("expected no ceremony time by default, got %v (%v)", at, err)
+	}
+	want := time.Date(2026, 10, 17, 21, 0, 0, 0, time.Local)
+	for _, input := range []string{"2026-10-17 21:00", "2026-10-17T21:00", want.Format(time.RFC3339)} {
+		value, err := q.SetSetting(ctx, db.SettingCeremonyAt, input)
+		if err != nil {
+			t.Fatalf("failed to set %q: %v", input, err)
+		}
+		if value != want.Format(time.RFC3339) {
+			t.Errorf("expected %q stored as %q, got %q", input, want.Format(time.RFC3339), value)
+		}
+	}
+	if at, _ := q.SettingTime(ctx, db.SettingCeremonyAt); !at.Equal(want) {
+		t.Errorf("expected the ceremony at %v, got %v", want, at)
+	}
+	if _, err := q.SetSetting(ctx, db.SettingCeremonyAt, "tonight"); err == nil {
+		t.Error("expected an invalid time to be rejected")
+	}
+}
+
+func TestMatchCategories(t *testing.T) {
+	polls := []db.Category{
+		{ID: 1, Name: "Best Pixel Art"},
+		{ID: 2, Name: "Best Game"},
+		{ID: 3, Name: "Worst Game"},
+	}
+
+	tests := []struct {
+		ref  string
+		want []int64
+	}{
+		{"best game", []int64{2}}, // exact beats substring
+		{"best", []int64{1, 2}},   // prefix
+		{"game", []int64{2, 3}},   // substring
+		{"bpa", []int64{1}},       // letters in order
+		{"best gmae", []int64{2}}, // typo
+		{"trophy", nil},
+	}
+	for _, tt := range tests {
+		var got []int64
+		for _, c := range db.MatchCategories(polls, tt.ref) {
+			got = append(got, c.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("MatchCategories(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueSlug(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	q := db.New(conn)
+	cat, err := q.CreateCategory(t.Context(), db.CreateCategoryParams{
+		Name: "Best Game", VoteType: "single", Status: "open", ShowResults: "live",
+		Slug: sql.NullString{String: "best-game", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+
+	if slug, _ := q.UniqueSlug(t.Context(), "Best Game", cat.ID); slug != "best-game" {
+		t.Errorf("expected a category to keep its own slug, got %q", slug)
+	}
+	if slug, _ := q.UniqueSlug(t.Context(), "Best Game", 0); slug != "best-game-2" {
+		t.Errorf("expected a numbered slug, got %q", slug)
+	}
+
+	for _, ref := range []string{"best-game", strconv.FormatInt(cat.ID, 10)} {
+		got, err := q.CategoryByRef(t.Context(), ref)
+		if err != nil || got.ID != cat.ID {
+			t.Errorf("CategoryByRef(%q) = %d, %v", ref, got.ID, err)
+		}
+	}
+	if _, err := q.CategoryByRef(t.Context(), "nope"); !errors.Is(err, db.ErrNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected ErrNotFound for an unknown slug, got %v", err)
+	}
+	if cat.Ref() != "best-game" {
+		t.Errorf("expected Ref to prefer the slug, got %q", cat.Ref())
+	}
+}
+
+func TestErrors(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	q := db.New(conn)
+	params := db.CreateCategoryParams{
+		Name: "Best Game", VoteType: "single", Status: "closed", ShowResults: "live",
+		Slug: sql.NullString{String: "best-game", Valid: true},
+	}
+	cat, err := q.CreateCategory(t.Context(), params)
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+
+	if _, err := q.Category(t.Context(), cat.ID+1); !errors.Is(err, db.ErrNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected ErrNotFound for a missing poll, got %v", err)
+	}
+	if _, err := q.Option(t.Context(), 1); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing option, got %v", err)
+	}
+
+	_, err = q.CreateCategory(t.Context(), params)
+	if err = db.Classify(err); !errors.Is(err, db.ErrConflict) {
+		t.Errorf("expected ErrConflict for a taken slug, got %v", err)
+	}
+	for _, name := range []string{"Tetris", "TETRIS"} {
+		_, err = q.CreateOption(t.Context(), db.CreateOptionParams{CategoryID: cat.ID, Name: name})
+	}
+	if err = db.Classify(err); !errors.Is(err, db.ErrConflict) {
+		t.Errorf("expected ErrConflict for an option name differing only in case, got %v", err)
+	}
+	if db.Classify(nil) != nil {
+		t.Error("expected Classify to leave nil alone")
+	}
+	if other := errors.New("disk full"); db.Classify(other) != other {
+		t.Error("expected Classify to leave other errors alone")
+	}
+
+	if err := cat.CheckOpen(); !errors.Is(err, db.ErrClosed) {
+		t.Errorf("expected ErrClosed for a closed poll, got %v", err)
+	}
+	cat.Status = "open"
+	if err := cat.CheckOpen(); err != nil {
+		t.Errorf("expected an open poll to pass, got %v", err)
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	ctx := t.Context()
+	q := db.New(conn)
+	first, err := q.CreateCategory(ctx, db.CreateCategoryParams{Name: "Heats", VoteType: "single", Status: "draft", ShowResults: "live"})
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+	cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{
+		Name: "Final", VoteType: "single", Status: "draft", ShowResults: "live",
+		DependsOn: sql.NullInt64{Int64: first.ID, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+	audits := func() int {
+		var n int
+		conn.QueryRow("SELECT COUNT(*) FROM audit_events WHERE category_id = ?", cat.ID).Scan(&n)
+		return n
+	}
+
+	if _, err := db.OpenCategory(ctx, conn, cat.ID, db.ActorCLI); !errors.Is(err, db.ErrNoOptions) || !errors.Is(err, db.ErrConflict) {
+		t.Errorf("expected ErrNoOptions opening an empty poll, got %v", err)
+	}
+	if _, err := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "Doom"}); err != nil {
+		t.Fatalf("failed to create option: %v", err)
+	}
+	var waiting *db.WaitingError
+	if _, err := db.OpenCategory(ctx, conn, cat.ID, db.ActorCLI); !errors.As(err, &waiting) || waiting.Prev.ID != first.ID {
+		t.Errorf("expected a WaitingError on %d, got %v", first.ID, err)
+	}
+	if got, _ := q.Category(ctx, cat.ID); got.Status != "draft" || audits() != 0 {
+		t.Errorf("expected refused opens to change nothing, got status %q and %d audit events", got.Status, audits())
+	}
+
+	if err := q.UpdateCategoryStatus(ctx, db.UpdateCategoryStatusParams{ID: first.ID, Status: "open"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CloseCategory(ctx, conn, first.ID, db.ActorCLI); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	opened, err := db.OpenCategory(ctx, conn, cat.ID, db.ActorCLI)
+	if err != nil || opened.Status != "open" {
+		t.Fatalf("expected the poll to open, got %q, %v", opened.Status, err)
+	}
+	if _, err := db.ReopenCategory(ctx, conn, cat.ID, db.ActorCLI); !errors.Is(err, db.ErrNotClosed) {
+		t.Errorf("expected ErrNotClosed reopening an open poll, got %v", err)
+	}
+	if _, err := db.OpenCategory(ctx, conn, cat.ID, db.ActorCLI); !errors.Is(err, db.ErrStarted) || !errors.Is(err, db.ErrConflict) {
+		t.Errorf("expected ErrStarted opening an open poll, got %v", err)
+	}
+	if closed, err := db.CloseCategory(ctx, conn, cat.ID, db.ActorCLI); err != nil || closed.Status != "closed" {
+		t.Fatalf("expected the poll to close, got %q, %v", closed.Status, err)
+	}
+	if _, err := db.ReopenCategory(ctx, conn, cat.ID, db.ActorCLI); err != nil {
+		t.Errorf("failed to reopen: %v", err)
+	}
+	if audits() != 3 {
+		t.Errorf("expected open, close and reopen in the audit log, got %d events", audits())
+	}
+
+	// Only a draft opens and only an open poll closes
+	if _, err := db.CloseCategory(ctx, conn, cat.ID, db.ActorCLI); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	if _, err := db.CloseCategory(ctx, conn, cat.ID, db.ActorCLI); !errors.Is(err, db.ErrNotOpen) || !errors.Is(err, db.ErrConflict) {
+		t.Errorf("expected ErrNotOpen closing a closed poll, got %v", err)
+	}
+	if _, err := db.OpenCategory(ctx, conn, cat.ID, db.ActorCLI); !errors.Is(err, db.ErrStarted) {
+		t.Errorf("expected ErrStarted opening a closed poll, got %v", err)
+	}
+	if err := q.ArchiveCategory(ctx, cat.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.OpenCategory(ctx, conn, cat.ID, db.ActorCLI); !errors.Is(err, db.ErrStarted) {
+		t.Errorf("expected ErrStarted opening an archived poll, got %v", err)
+	}
+	if _, err := db.CloseCategory(ctx, conn, cat.ID, db.ActorCLI); !errors.Is(err, db.ErrNotOpen) {
+		t.Errorf("expected ErrNotOpen closing an archived poll, got %v", err)
+	}
+	if got, _ := q.Category(ctx, cat.ID); got.Status != "archived" || audits() != 4 {
+		t.Errorf("expected refused transitions to change nothing, got status %q and %d audit events", got.Status, audits())
+	}
+
+	if _, err := db.OpenCategory(ctx, conn, 999, db.ActorCLI); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing poll, got %v", err)
+	}
+}
+
+func TestPurgeExpiredBallots(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	ctx := t.Context()
+	q := db.New(conn)
+	poll := func(name string, closedAgo time.Duration, voters ...string) db.Category {
+		t.Helper()
+		cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{Name: name, VoteType: "single", Status: "closed", ShowResults: "live"})
+		if err != nil {
+			t.Fatalf("failed to create category: %v", err)
+		}
+		doom, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "Doom"})
+		quake, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "Quake"})
+		for i, nickname := range voters {
+			vote, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: nickname})
+			if err != nil {
+				t.Fatalf("failed to create vote: %v", err)
+			}
+			option := doom.ID
+			if i == len(voters)-1 {
+				option = quake.ID
+			}
+			if err := q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: option}); err != nil {
+				t.Fatalf("failed to add selection: %v", err)
+			}
+			if err := q.RecordAudit(ctx, nickname, db.AuditVote, cat.ID, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := conn.Exec("INSERT INTO audit_events (actor, action, category_id, created_at) VALUES ('admin', ?, ?, ?)",
+			db.AuditCategoryClose, cat.ID, time.Now().Add(-closedAgo).UTC()); err != nil {
+			t.Fatal(err)
+		}
+		return cat
+	}
+	old := poll("Old", 40*24*time.Hour, "alice", "bob", "carol")
+	recent := poll("Recent", 2*24*time.Hour, "alice")
+	before, err := q.Tally(ctx, old)
+	if err != nil {
+		t.Fatalf("failed to tally: %v", err)
+	}
+
+	if retention, err := q.BallotRetention(ctx); err != nil || retention != 0 {
+		t.Errorf("expected ballots kept forever by default, got %v, %v", retention, err)
+	}
+	if _, err := q.SetSetting(ctx, db.SettingBallotRetentionDays, "30"); err != nil {
+		t.Fatal(err)
+	}
+	retention, err := q.BallotRetention(ctx)
+	if err != nil || retention != 30*24*time.Hour {
+		t.Fatalf("expected 30 days, got %v, %v", retention, err)
+	}
+
+	purged, err := db.PurgeExpiredBallots(ctx, conn, db.ActorServer, retention, time.Now())
+	if err != nil {
+		t.Fatalf("failed to purge: %v", err)
+	}
+	if len(purged) != 1 || purged[0].Category.ID != old.ID || purged[0].Ballots != 3 {
+		t.Fatalf("expected the old poll's 3 ballots purged, got %+v", purged)
+	}
+	if ballots, _ := q.Ballots(ctx, old.ID); len(ballots) != 0 {
+		t.Errorf("expected no ballots left, got %v", ballots)
+	}
+	if ballots, _ := q.Ballots(ctx, recent.ID); len(ballots) != 1 {
+		t.Errorf("expected the recent poll's ballot kept, got %v", ballots)
+	}
+	after, err := q.Tally(ctx, old)
+	if err != nil {
+		t.Fatalf("failed to tally: %v", err)
+	}
+	if !slices.Equal(after, before) {
+		t.Errorf("expected the results kept, got %+v, want %+v", after, before)
+	}
+	if votes, _ := q.CountVotesByCategory(ctx, old.ID); votes != 3 {
+		t.Errorf("expected 3 votes still counted, got %d", votes)
+	}
+
+	var actors []string
+	rows, err := conn.Query("SELECT actor FROM audit_events WHERE category_id = ? AND action = ? ORDER BY id", old.ID, db.AuditVote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var actor string
+		rows.Scan(&actor)
+		actors = append(actors, actor)
+	}
+	rows.Close()
+	if !slices.Equal(actors, []string{db.PurgedActor, db.PurgedActor, db.PurgedActor}) {
+		t.Errorf("expected the vote events anonymized, got %v", actors)
+	}
+	var actor, detail string
+	conn.QueryRow("SELECT actor, detail FROM audit_events WHERE category_id = ? AND action = ?", old.ID, db.AuditBallotPurge).Scan(&actor, &detail)
+	if actor != db.ActorServer || detail != "3 ballot(s), closed for over 30 day(s)" {
+		t.Errorf("expected the purge audited, got %q %q", actor, detail)
+	}
+	if problems, err := db.Check(ctx, conn); err != nil || len(problems) != 0 {
+		t.Errorf("expected a purged poll to check clean, got %v, %v", problems, err)
+	}
+
+	if again, err := db.PurgeExpiredBallots(ctx, conn, db.ActorServer, retention, time.Now()); err != nil || len(again) != 0 {
+		t.Errorf("expected nothing left to purge, got %+v, %v", again, err)
+	}
+	if _, err := db.ReopenCategory(ctx, conn, old.ID, db.ActorCLI); !errors.Is(err, db.ErrPurged) || !errors.Is(err, db.ErrConflict) {
+		t.Errorf("expected ErrPurged reopening a purged poll, got %v", err)
+	}
+	if _, err := db.OpenCategory(ctx, conn, old.ID, db.ActorCLI); !errors.Is(err, db.ErrStarted) {
+		t.Errorf("expected ErrStarted opening a purged poll, got %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	conn, err := db.Open(filepath.Join(t.TempDir(), "votigo.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	// Every pooled connection enforces foreign keys, not just the first
+	ctx := t.Context()
+	conns := make([]*sql.Conn, 3)
+	for i := range conns {
+		if conns[i], err = conn.Conn(ctx); err != nil {
+			t.Fatalf("failed to get a connection: %v", err)
+		}
+		var on bool
+		if err := conns[i].QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&on); err != nil || !on {
+			t.Errorf("expected foreign keys on connection %d, got %v, %v", i, on, err)
+		}
+	}
+
+	if problems, err := db.CheckReferences(ctx, conn); err != nil || len(problems) != 0 {
+		t.Fatalf("expected no problems in a new database, got %v, %v", problems, err)
+	}
+
+	// Break references the way a connection without foreign keys could
+	orphans := conns[0]
+	for _, stmt := range []string{
+		"PRAGMA foreign_keys = OFF",
+		"INSERT INTO options (id, category_id, name) VALUES (7, 42, 'Ghost')",
+		"INSERT INTO vote_selections (id, vote_id, option_id) VALUES (3, 99, 7)",
+	} {
+		if _, err := orphans.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	for _, c := range conns {
+		c.Close()
+	}
+
+	problems, err := db.CheckReferences(ctx, conn)
+	if err != nil {
+		t.Fatalf("failed to check: %v", err)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	want := []string{
+		"options #7: category_id 42 is missing from categories",
+		"vote_selections #3: vote_id 99 is missing from votes",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+
+	// Check adds ballots voting would have refused, and Repair fixes it all
+	q := db.New(conn)
+	cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{
+		Name: "Best Game", VoteType: "ranked", Status: "open", ShowResults: "live",
+		MaxRank: sql.NullInt64{Int64: 2, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+	opt, err := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "Doom"})
+	if err != nil {
+		t.Fatalf("failed to create option: %v", err)
+	}
+	vote, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: "alice"})
+	if err != nil {
+		t.Fatalf("failed to create vote: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, "INSERT INTO vote_selections (vote_id, option_id, rank) VALUES (?, ?, 5)", vote.ID, opt.ID); err != nil {
+		t.Fatalf("failed to add selection: %v", err)
+	}
+	problems, err = db.Check(ctx, conn)
+	if err != nil || len(problems) != 3 {
+		t.Fatalf("expected 3 problems, got %v, %v", problems, err)
+	}
+	if !strings.Contains(problems[2].Detail, "outside 1-2") || problems[2].Fix == "" {
+		t.Errorf("expected a fixable out of range rank, got %+v", problems[2])
+	}
+	if fixed, err := db.Repair(ctx, conn, problems, db.ActorCLI); err != nil || fixed != 3 {
+		t.Fatalf("expected 3 problems fixed, got %d, %v", fixed, err)
+	}
+	if problems, err := db.Check(ctx, conn); err != nil || len(problems) != 0 {
+		t.Errorf("expected no problems after repair, got %v, %v", problems, err)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	migrated := func() *sql.DB {
+		t.Helper()
+		conn, err := db.Open(":memory:")
+		if err != nil {
+			t.Fatalf("failed to open db: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		conn.SetMaxOpenConns(1)
+		if err := db.Migrate(conn); err != nil {
+			t.Fatalf("failed to migrate: %v", err)
+		}
+		return conn
+	}
+	ctx := t.Context()
+
+	// An archive from schema 14 goes through every migration since: slugs,
+	// merged duplicate options and orphans dropped
+	data, err := os.ReadFile("testdata/archive-schema-14.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := db.ReadArchive(data)
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	conn := migrated()
+	if err := db.ImportArchive(ctx, conn, old); err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	q := db.New(conn)
+	game, _ := q.Category(ctx, 1)
+	cup, _ := q.Category(ctx, 2)
+	if game.Slug.String != "best-game" || cup.Slug.String != "pok-mon-cup" || cup.DependsOn.Int64 != game.ID {
+		t.Errorf("expected both polls with slugs and their dependency, got %+v and %+v", game, cup)
+	}
+	if game.CreatedAt.Time.Format(time.DateTime) != "2024-06-01 18:00:00" {
+		t.Errorf("expected created_at to survive, got %v", game.CreatedAt)
+	}
+	options, _ := q.ListOptionsByCategory(ctx, 1)
+	if len(options) != 2 || options[0].Name != "Tetris" {
+		t.Errorf("expected Tetris and tetris merged, got %+v", options)
+	}
+	ballots, _ := q.Ballots(ctx, 1)
+	if len(ballots) != 2 || ballots[0][1] != 1 || ballots[1][2] != 1 {
+		t.Errorf("expected alice's best rank for Tetris and bob's vote, got %v", ballots)
+	}
+	if problems, err := db.Check(ctx, conn); err != nil || len(problems) != 0 {
+		t.Errorf("expected an imported archive to check clean, got %v, %v", problems, err)
+	}
+	if err := db.ImportArchive(ctx, conn, old); !errors.Is(err, db.ErrNotEmpty) {
+		t.Errorf("expected ErrNotEmpty importing twice, got %v", err)
+	}
+
+	// A current archive round-trips exactly
+	exported, err := db.ExportArchive(ctx, conn)
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	first, _ := json.Marshal(exported.Tables)
+	again, err := db.ReadArchive(mustMarshal(t, exported))
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	copied := migrated()
+	if err := db.ImportArchive(ctx, copied, again); err != nil {
+		t.Fatalf("failed to import export: %v", err)
+	}
+	reexported, err := db.ExportArchive(ctx, copied)
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if second, _ := json.Marshal(reexported.Tables); string(first) != string(second) {
+		t.Errorf("round trip changed the data:\n%s\n%s", first, second)
+	}
+
+	newer := exported
+	newer.Schema++
+	if err := db.ImportArchive(ctx, migrated(), newer); !errors.Is(err, db.ErrArchiveTooNew) {
+		t.Errorf("expected ErrArchiveTooNew, got %v", err)
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestShortCode(t *testing.T) {
+	seen := map[string]bool{}
+	for _, id := range []int64{1, 2, 3, 42, 923520, 923521, 1 << 40} {
+		code := db.ShortCode(id)
+		if id < 923521 && len(code) != 4 {
+			t.Errorf("expected a four-character code for %d, got %q", id, code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q for %d", code, id)
+		}
+		seen[code] = true
+
+		if got, ok := db.ShortCodeID(strings.ToUpper(code)); !ok || got != id {
+			t.Errorf("ShortCodeID(%q) = %d, %v, want %d", code, got, ok, id)
+		}
+	}
+
+	for _, code := range []string{"", "abc", "ab0c", "il1o", "2222222"} {
+		if id, ok := db.ShortCodeID(code); ok {
+			t.Errorf("expected ShortCodeID(%q) to fail, got %d", code, id)
+		}
+	}
+}
+
+func TestWinningMargin(t *testing.T) {
+	single := db.Category{VoteType: "single"}
+	tallied := func(scores ...int64) []db.TalliedOption {
+		out := make([]db.TalliedOption, len(scores))
+		for i, s := range scores {
+			out[i].Score = s
+		}
+		return out
+	}
+
+	m, ok := db.WinningMargin(single, tallied(21, 20, 0), 41, 1)
+	if !ok || m.Lead != 1 || !m.Narrow || m.String() != "by 1 vote (2% of 41 ballots)" {
+		t.Errorf("expected a narrow one-vote win, got %+v %q, %v", m, m, ok)
+	}
+	if m, ok := db.WinningMargin(single, tallied(30, 11), 41, 1); !ok || m.Narrow || m.Percent() != 46 {
+		t.Errorf("expected a clear win, got %+v, %v", m, ok)
+	}
+	if m, ok := db.WinningMargin(single, tallied(5), 5, 1); !ok || m.Lead != 5 {
+		t.Errorf("expected a lone option to lead by all its votes, got %+v, %v", m, ok)
+	}
+	if _, ok := db.WinningMargin(single, tallied(20, 20), 40, 1); ok {
+		t.Error("expected no margin while first place is tied")
+	}
+	if _, ok := db.WinningMargin(single, tallied(0, 0), 0, 1); ok {
+		t.Error("expected no margin before anyone voted")
+	}
+	for _, cat := range []db.Category{
+		{VoteType: "ranked"},
+		{VoteType: "single", Method: "irv"},
+		{VoteType: "single", Judges: "jury", JudgeWeight: 50},
+	} {
+		if _, ok := db.WinningMargin(cat, tallied(21, 20), 41, 1); ok {
+			t.Errorf("expected no margin for a %s poll counted by %q", cat.VoteType, cat.Method)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	ctx := t.Context()
+	q := db.New(conn)
+	now := time.Now()
+	poll := func(p db.CreateCategoryParams, options ...string) db.Category {
+		t.Helper()
+		p.ShowResults = "live"
+		cat, err := q.CreateCategory(ctx, p)
+		if err != nil {
+			t.Fatalf("failed to create category: %v", err)
+		}
+		for _, name := range options {
+			if _, err := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: name}); err != nil {
+				t.Fatalf("failed to create option: %v", err)
+			}
+		}
+		return cat
+	}
+	at := func(d time.Duration) sql.NullTime { return sql.NullTime{Time: now.Add(d), Valid: true} }
+
+	lonely := poll(db.CreateCategoryParams{Name: "Lonely", VoteType: "single", Status: "open"}, "Doom")
+	ranked := poll(db.CreateCategoryParams{Name: "Ranked", VoteType: "ranked", Status: "draft", MaxRank: sql.NullInt64{Int64: 3, Valid: true}}, "Doom", "Quake")
+	late := poll(db.CreateCategoryParams{Name: "Late", VoteType: "single", Status: "draft", ClosesAt: at(-time.Hour)}, "Doom", "Quake")
+	quiet := poll(db.CreateCategoryParams{Name: "Quiet", VoteType: "single", Status: "open", ClosesAt: at(5 * time.Minute)}, "Doom", "Quake")
+	busy := poll(db.CreateCategoryParams{Name: "Busy", VoteType: "single", Status: "open", ClosesAt: at(5 * time.Minute)}, "Doom", "Quake")
+	poll(db.CreateCategoryParams{Name: "Later", VoteType: "single", Status: "open", ClosesAt: at(time.Hour)}, "Doom", "Quake")
+	poll(db.CreateCategoryParams{Name: "Done", VoteType: "ranked", Status: "closed", MaxRank: sql.NullInt64{Int64: 5, Valid: true}, ClosesAt: at(-time.Hour)}, "Doom")
+	unplugged := poll(db.CreateCategoryParams{Name: "Unplugged", VoteType: "single", Status: "closed", Method: "house-rules"}, "Doom", "Quake")
+	if _, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: busy.ID, Nickname: "ACE"}); err != nil {
+		t.Fatalf("failed to vote: %v", err)
+	}
+	// Written around SetSetting, as a hand edit of the database would be
+	for key, value := range map[string]string{db.SettingLANOnly: "maybe", db.SettingEventName: "LAN 2026", "retired_setting": "x"} {
+		if err := q.UpsertSetting(ctx, db.UpsertSettingParams{Key: key, Value: value}); err != nil {
+			t.Fatalf("failed to store setting: %v", err)
+		}
+	}
+
+	warnings, err := q.Lint(ctx, now)
+	if err != nil {
+		t.Fatalf("failed to lint: %v", err)
+	}
+	var got []string
+	for _, w := range warnings {
+		got = append(got, cmp.Or(w.Setting, strconv.FormatInt(w.CategoryID, 10)))
+	}
+	want := []string{strconv.FormatInt(lonely.ID, 10), strconv.FormatInt(ranked.ID, 10), strconv.FormatInt(late.ID, 10), strconv.FormatInt(quiet.ID, 10), strconv.FormatInt(unplugged.ID, 10), db.SettingLANOnly}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected warnings for %v, got %v", want, warnings)
+	}
+	if s := warnings[1].String(); !strings.Contains(s, "Ranked") || !strings.Contains(s, "3 ranks") {
+		t.Errorf("expected the ranked warning to name the poll and its ranks, got %q", s)
+	}
+	if s := warnings[4].String(); !strings.Contains(s, "house-rules") || !strings.Contains(s, "counted by votes") {
+		t.Errorf("expected the method warning to name the method and the fallback, got %q", s)
+	}
+	if s := warnings[5].String(); !strings.Contains(s, "lan_only") || !strings.Contains(s, `"maybe"`) {
+		t.Errorf("expected the setting warning to name the key and value, got %q", s)
+	}
+}
+
+func TestSyncRoster(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	ctx := t.Context()
+	sync := func(seats map[string]string) db.RosterChanges {
+		t.Helper()
+		var changes db.RosterChanges
+		err := db.InTx(ctx, conn, func(q *db.Queries) error {
+			var err error
+			changes, err = q.SyncRoster(ctx, seats)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("failed to sync: %v", err)
+		}
+		return changes
+	}
+	q := db.New(conn)
+	roster := func() map[string]string {
+		attendees, err := q.ListAttendees(ctx)
+		if err != nil {
+			t.Fatalf("failed to list attendees: %v", err)
+		}
+		seats := make(map[string]string)
+		for _, a := range attendees {
+			seats[a.Nickname] = a.Seat
+		}
+		return seats
+	}
+
+	if got := sync(map[string]string{"ace": "A1", "bob": "A2"}); got != (db.RosterChanges{Added: 2}) {
+		t.Errorf("expected two added, got %v", got)
+	}
+	want := map[string]string{"ace": "A3", "cat": "B1"}
+	if got := sync(want); got != (db.RosterChanges{Added: 1, Moved: 1, Removed: 1}) {
+		t.Errorf("expected one of each, got %v", got)
+	}
+	if got := roster(); !maps.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if _, err := q.ForgetVoter(ctx, "cat"); err != nil {
+		t.Fatalf("failed to forget: %v", err)
+	}
+	if got := roster(); !maps.Equal(got, map[string]string{"ace": "A3"}) {
+		t.Errorf("expected a forgotten voter off the roster, got %v", got)
+	}
+
+	// Tags from the source replace those set in admin, for the attendees it
+	// lists tags for
+	sync(map[string]string{"ace": "A3", "bob": "B1"})
+	attendees, err := q.ListAttendees(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range attendees {
+		if err := q.SetAttendeeTags(ctx, a.ID, []string{"crew"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	syncTags := func(tags map[string][]string) int {
+		t.Helper()
+		var n int
+		err := db.InTx(ctx, conn, func(q *db.Queries) error {
+			var err error
+			n, err = q.SyncRosterTags(ctx, tags)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("failed to sync tags: %v", err)
+		}
+		return n
+	}
+	if n := syncTags(map[string][]string{"ace": {"crew"}, "bob": {"participant"}}); n != 1 {
+		t.Errorf("expected only bob retagged, got %d", n)
+	}
+	for nickname, want := range map[string][]string{"ace": {"crew"}, "bob": {"participant"}} {
+		if got, _ := q.ListTagsByNickname(ctx, nickname); !slices.Equal(got, want) {
+			t.Errorf("expected %s tagged %v, got %v", nickname, want, got)
+		}
+	}
+	if n := syncTags(map[string][]string{"ace": {}}); n != 1 {
+		t.Errorf("expected ace's tags cleared, got %d retagged", n)
+	}
+	if got, _ := q.ListTagsByNickname(ctx, "bob"); !slices.Equal(got, []string{"participant"}) {
+		t.Errorf("expected bob's tags kept when the source doesn't list them, got %v", got)
+	}
+	if s := (db.RosterChanges{Added: 1, Retagged: 2}).String(); s != "1 added, 0 moved, 0 removed, 2 retagged" {
+		t.Errorf("unexpected summary %q", s)
+	}
+}
+
+func TestEligibility(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+		tags map[string]string // tags → the refusal, "" if they may vote
+	}{
+		{"", "", map[string]string{"": "", "crew": ""}},
+		{"Participant", "participant", map[string]string{
+			"participant":      "",
+			"":                 "Only attendees tagged participant can vote in this poll",
+			"crew participant": "",
+		}},
+		{"!crew player|caster", "player|caster !crew", map[string]string{
+			"caster":      "",
+			"player crew": "Attendees tagged crew can't vote in this poll",
+			"guest":       "Only attendees tagged player or caster can vote in this poll",
+		}},
+	}
+	for _, tt := range tests {
+		e, err := db.ParseEligibility(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseEligibility(%q): %v", tt.expr, err)
+		}
+		if got := e.String(); got != tt.want {
+			t.Errorf("ParseEligibility(%q).String() = %q, want %q", tt.expr, got, tt.want)
+		}
+		for tags, want := range tt.tags {
+			got := ""
+			if err := e.Check(strings.Fields(tags)); err != nil {
+				got = err.Error()
+			}
+			if got != want {
+				t.Errorf("%q with tags %q: got %q, want %q", tt.expr, tags, got, want)
+			}
+		}
+	}
+	for _, expr := range []string{"player|", "!", "crew!", "a/b"} {
+		if _, err := db.ParseEligibility(expr); err == nil || !strings.HasPrefix(err.Error(), "Eligibility: ") {
+			t.Errorf("ParseEligibility(%q): expected an error for the admin, got %v", expr, err)
+		}
+	}
+	if tags, err := db.ParseTags("Crew, player crew"); err != nil || !slices.Equal(tags, []string{"crew", "player"}) {
+		t.Errorf("expected tags sorted without duplicates, got %v, %v", tags, err)
+	}
+
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := t.Context()
+	q := db.New(conn)
+	if err := q.UpsertAttendee(ctx, db.UpsertAttendeeParams{Nickname: "ace", Seat: "A1"}); err != nil {
+		t.Fatal(err)
+	}
+	attendees, err := q.ListAttendees(ctx)
+	if err != nil || len(attendees) != 1 {
+		t.Fatalf("expected one attendee, got %v, %v", attendees, err)
+	}
+	if err := q.SetAttendeeTags(ctx, attendees[0].ID, []string{"crew", "player"}); err != nil {
+		t.Fatalf("failed to tag: %v", err)
+	}
+	cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{Name: "Fan Pick", VoteType: "single", Status: "open", ShowResults: "live", Eligibility: "player !crew"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var refused *db.IneligibleError
+	if err := q.CheckEligible(ctx, cat, "ace"); !errors.As(err, &refused) || refused.Excluded != "crew" {
+		t.Errorf("expected crew refused, got %v", err)
+	}
+	if err := q.CheckEligible(ctx, cat, "stranger"); !errors.As(err, &refused) {
+		t.Errorf("expected a voter off the roster refused, got %v", err)
+	}
+	if err := q.SetAttendeeTags(ctx, attendees[0].ID, []string{"player"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.CheckEligible(ctx, cat, "ace"); err != nil {
+		t.Errorf("expected a player let through, got %v", err)
+	}
+}
+
+func TestTagWeights(t *testing.T) {
+	w, err := db.ParseTagWeights(" Jury=3 judge=2 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.String(); got != "judge=2 jury=3" {
+		t.Errorf("expected weights sorted by tag, got %q", got)
+	}
+	for tags, want := range map[string]int64{"": 1, "crew": 1, "judge": 2, "judge jury": 3} {
+		if got := w.Weight(strings.Fields(tags)); got != want {
+			t.Errorf("Weight(%q) = %d, want %d", tags, got, want)
+		}
+	}
+	for _, expr := range []string{"jury", "jury=0", "jury=1.5", "jury=101", "jury=2 jury=3", "a/b=2"} {
+		if _, err := db.ParseTagWeights(expr); err == nil || !strings.HasPrefix(err.Error(), "Weights: ") {
+			t.Errorf("ParseTagWeights(%q): expected an error for the admin, got %v", expr, err)
+		}
+	}
+
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := t.Context()
+	q := db.New(conn)
+	cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{Name: "Best Game", VoteType: "single", Status: "open", ShowResults: "live", Weights: "jury=3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "A"})
+	b, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "B"})
+	for nickname, option := range map[string]int64{"ace": a.ID, "bob": b.ID, "cat": b.ID} {
+		vote, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: nickname})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: option}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	winner := func() (string, int64) {
+		t.Helper()
+		tallied, err := q.Tally(ctx, cat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tallied[0].Name, tallied[0].Score
+	}
+	if name, votes := winner(); name != "B" || votes != 2 {
+		t.Errorf("expected B to win 2-1 with nobody on the jury, got %s with %d", name, votes)
+	}
+
+	if err := q.UpsertAttendee(ctx, db.UpsertAttendeeParams{Nickname: "ace"}); err != nil {
+		t.Fatal(err)
+	}
+	ace, err := q.GetAttendeeByNickname(ctx, "ace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, _ := q.Category(ctx, cat.ID)
+	if err := q.SetAttendeeTags(ctx, ace.ID, []string{"jury"}); err != nil {
+		t.Fatal(err)
+	}
+	if name, votes := winner(); name != "A" || votes != 3 {
+		t.Errorf("expected the juror's ballot to count three times, got %s with %d", name, votes)
+	}
+	if after, _ := q.Category(ctx, cat.ID); after.ResultsVersion == before.ResultsVersion {
+		t.Error("expected retagging to change a weighted poll's results version")
+	}
+	if ballots, _ := q.Ballots(ctx, cat.ID); len(ballots) != 3 {
+		t.Errorf("expected Ballots unweighted, got %d", len(ballots))
+	}
+}
+
+func TestObservers(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := t.Context()
+	q := db.New(conn)
+
+	now := time.Unix(1_700_000_000, 0)
+	want := db.Observer{Label: "Sponsor: Pizza Palace", Expires: now.Add(48 * time.Hour)}
+	token, err := q.SignObserver(ctx, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := q.ObserverByToken(ctx, token, now)
+	if err != nil || got.Label != want.Label || !got.Expires.Equal(want.Expires) {
+		t.Fatalf("expected %+v back, got %+v, %v", want, got, err)
+	}
+
+	forged := strings.Replace(token, strconv.FormatInt(want.Expires.Unix(), 10), strconv.FormatInt(want.Expires.Add(time.Hour).Unix(), 10), 1)
+	for name, bad := range map[string]string{
+		"empty":    "",
+		"unsigned": token[:strings.LastIndexByte(token, '.')],
+		"forged":   forged,
+		"garbled":  token + "!",
+	} {
+		if _, err := q.ObserverByToken(ctx, bad, now); !errors.Is(err, db.ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound, got %v", name, err)
+		}
+	}
+	if _, err := q.ObserverByToken(ctx, token, want.Expires); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected an expired link refused, got %v", err)
+	}
+
+	if err := q.RevokeObservers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.ObserverByToken(ctx, token, now); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected a revoked link refused, got %v", err)
+	}
+}
+
+func TestJudgeScores(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := t.Context()
+	q := db.New(conn)
+	cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{Name: "Best Cosplay", VoteType: "single", Status: "open", ShowResults: "live", Judges: "jury", JudgeWeight: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "A"})
+	b, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "B"})
+	for nickname, option := range map[string]int64{"judy": a.ID, "jules": a.ID, "ace": a.ID, "bob": b.ID, "cat": b.ID, "dan": b.ID} {
+		vote, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: nickname})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: option}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, nickname := range []string{"judy", "jules"} {
+		if err := q.UpsertAttendee(ctx, db.UpsertAttendeeParams{Nickname: nickname}); err != nil {
+			t.Fatal(err)
+		}
+		judge, err := q.GetAttendeeByNickname(ctx, nickname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := q.SetAttendeeTags(ctx, judge.ID, []string{"jury"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cat, _ = q.Category(ctx, cat.ID)
+	standings := func() []string {
+		t.Helper()
+		tallied, err := q.Tally(ctx, cat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, o := range tallied {
+			got = append(got, o.Name+" "+o.Label)
+		}
+		return got
+	}
+	// Judges all for A: 50 + 50 * 1/4 against B's 50 * 3/4
+	if got, want := standings(), []string{"A 62.5 points", "B 37.5 points"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	scores, err := q.JudgeScores(ctx, cat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := scores[a.ID]; got.Jury.Label != "2 votes" || got.Audience.Label != "1 vote" {
+		t.Errorf("expected A on 2 judges' votes and 1 from the audience, got %+v", got)
+	}
+	if got := scores[b.ID]; got.Jury.Score != 0 || got.Audience.Score != 3 {
+		t.Errorf("expected B on the audience's 3 votes alone, got %+v", got)
+	}
+
+	settings := db.UpdateCategoryParams{ID: cat.ID, Name: cat.Name, VoteType: cat.VoteType, ShowResults: cat.ShowResults, Judges: "jury", JudgeWeight: 20}
+	if err := q.UpdateCategory(ctx, settings); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := q.Category(ctx, cat.ID)
+	if after.ResultsVersion == cat.ResultsVersion {
+		t.Error("expected a new judge weight to change the results version")
+	}
+	cat = after
+	if got, want := standings(), []string{"B 60.0 points", "A 40.0 points"}; !slices.Equal(got, want) {
+		t.Errorf("expected the audience to carry it at 20%% judges, got %v", got)
+	}
+}
+
+func TestSeason(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := t.Context()
+	q := db.New(conn)
+
+	// poll creates a poll with an option per vote count, casting that many
+	// ballots for it
+	voter := 0
+	poll := func(name, status string, votes map[string]int) db.Category {
+		t.Helper()
+		cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{Name: name, VoteType: "single", Status: status, ShowResults: "live"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, option := range slices.Sorted(maps.Keys(votes)) {
+			o, err := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: option})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range votes[option] {
+				voter++
+				vote, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: "voter" + strconv.Itoa(voter)})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: o.ID}); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		return cat
+	}
+	standings := func() []string {
+		t.Helper()
+		_, season, err := q.Season(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, s := range season {
+			got = append(got, fmt.Sprintf("%d %s %d/%d %v", s.Rank, s.Name, s.Points, s.Wins, s.Events))
+		}
+		return got
+	}
+
+	points, err := db.ParseSeasonPoints("3, 2 1")
+	if err != nil || !slices.Equal(points, []int64{3, 2, 1}) {
+		t.Fatalf("expected 3 2 1, got %v (%v)", points, err)
+	}
+	for _, bad := range []string{"", "3 two 1", "3 -1"} {
+		if _, err := db.ParseSeasonPoints(bad); err == nil {
+			t.Errorf("expected %q to be refused", bad)
+		}
+	}
+	if _, err := q.SetSetting(ctx, db.SettingSeasonPoints, "1st"); err == nil {
+		t.Error("expected the season_points setting to refuse 1st")
+	}
+
+	game := poll("Best Game", "closed", map[string]int{"A": 2, "B": 1, "C": 0})
+	poll("Best Map", "closed", map[string]int{"X": 1, "Y": 1})
+	tied := poll("Best Mod", "closed", map[string]int{"M": 1, "N": 1})
+	runoff := poll("Best Mod (runoff)", "draft", map[string]int{"M": 0, "N": 0})
+	if err := q.SetCategoryRunoffOf(ctx, db.SetCategoryRunoffOfParams{RunoffOf: sql.NullInt64{Int64: tied.ID, Valid: true}, ID: runoff.ID}); err != nil {
+		t.Fatal(err)
+	}
+	open := poll("Best Snack", "open", map[string]int{"Chips": 1})
+
+	event, scored, err := db.FinalizeEvent(ctx, conn, "LAN 1", points, db.ActorCLI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, cat := range scored {
+		names = append(names, cat.Name)
+	}
+	if want := []string{"Best Game", "Best Map"}; !slices.Equal(names, want) {
+		t.Errorf("expected %v scored, leaving the runoff's poll and the open one, got %v", want, names)
+	}
+	if event.Name != "LAN 1" {
+		t.Errorf("expected the event to be called LAN 1, got %q", event.Name)
+	}
+	if cat, _ := q.Category(ctx, game.ID); cat.Status != "archived" {
+		t.Errorf("expected a scored poll to be archived, got %s", cat.Status)
+	}
+	// X and Y tie for first; C got no votes, so earns nothing for third
+	want := []string{"1 A 3/1 [3]", "1 X 3/1 [3]", "1 Y 3/1 [3]", "4 B 2/0 [2]"}
+	if got := standings(); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if _, _, err := db.FinalizeEvent(ctx, conn, "LAN 1 again", points, db.ActorCLI); !errors.Is(err, db.ErrNothingToFinalize) {
+		t.Errorf("expected nothing left to finalize, got %v", err)
+	}
+
+	if err := q.UpdateCategoryStatus(ctx, db.UpdateCategoryStatusParams{Status: "closed", ID: open.ID}); err != nil {
+		t.Fatal(err)
+	}
+	poll("Best Game 2", "closed", map[string]int{"a": 1})
+	second, _, err := db.FinalizeEvent(ctx, conn, "LAN 2", points, db.ActorCLI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Names match ignoring case and take their latest spelling
+	want = []string{"1 a 6/2 [3 3]", "2 Chips 3/1 [0 3]", "2 X 3/1 [3 0]", "2 Y 3/1 [3 0]", "5 B 2/0 [2 0]"}
+	if got := standings(); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if n, err := q.DeleteSeasonEvent(ctx, second.ID); err != nil || n != 1 {
+		t.Fatalf("failed to remove the event: %d, %v", n, err)
+	}
+	pending, err := q.ListUnscoredCategories(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Errorf("expected the removed event's 2 polls to wait to be scored again, got %d", len(pending))
+	}
+}
+
+func TestForgetVoter(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := t.Context()
+	q := db.New(conn)
+
+	voted := map[string]db.Category{}
+	for _, status := range []string{"open", "archived"} {
+		cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{Name: "Best Game " + status, VoteType: "single", Status: status, ShowResults: "live"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		doom, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "Doom"})
+		for _, nickname := range []string{"alice", "bob"} {
+			castAt(t, conn, q, cat.ID, nickname, doom.ID, time.Now())
+		}
+		voted[status] = cat
+	}
+
+	removed, err := q.ForgetVoter(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("expected only the open poll's ballot removed, got %d", removed)
+	}
+	if n, _ := q.CountVotesByCategory(ctx, voted["open"].ID); n != 1 {
+		t.Errorf("expected 1 ballot left in the open poll, got %d", n)
+	}
+
+	// An archived poll's results are final: the ballot stays, but not the name
+	if n, _ := q.CountVotesByCategory(ctx, voted["archived"].ID); n != 2 {
+		t.Errorf("expected both ballots kept in the archived poll, got %d", n)
+	}
+	votes, err := q.ListVotesByCategory(ctx, voted["archived"].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range votes {
+		if v.Nickname == "alice" {
+			t.Error("expected the archived ballot anonymized")
+		}
+	}
+	if _, err := q.GetVoteByNickname(ctx, db.GetVoteByNicknameParams{CategoryID: voted["archived"].ID, Nickname: "bob"}); err != nil {
+		t.Errorf("expected bob's archived ballot left alone, got %v", err)
+	}
+}
+
+// castAt casts nickname's ballot for option in a poll the way the vote
+// form does, replacing any they had, as if at
+func castAt(t *testing.T, conn *sql.DB, q *db.Queries, categoryID int64, nickname string, option int64, at time.Time) {
+	t.Helper()
+	ctx := t.Context()
+	vote, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: categoryID, Nickname: nickname})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vote.Version > 1 {
+		if err := q.ArchiveVoteSelections(ctx, vote.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Exec("UPDATE vote_selection_history SET replaced_at = ? WHERE vote_id = ? AND version = ?", at, vote.ID, vote.Version-1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.DeleteVoteSelections(ctx, vote.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: option}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec("UPDATE votes SET created_at = ? WHERE id = ?", at, vote.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.RecordAuditFrom(ctx, db.AuditOrigin{}, nickname, db.AuditVote, categoryID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec("UPDATE audit_events SET created_at = ? WHERE id = (SELECT MAX(id) FROM audit_events)", at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTallyBetween(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := t.Context()
+	q := db.New(conn)
+	cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{Name: "Best Game", VoteType: "single", Status: "open", ShowResults: "live", Weights: "crew=3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doom, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "Doom"})
+	quake, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "Quake"})
+
+	// Doom leads by evening; overnight stragglers hand it to Quake
+	evening := time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC)
+	cutoff := evening.Add(2 * time.Hour)
+	for i, ballot := range []struct {
+		option int64
+		at     time.Time
+	}{
+		{doom.ID, evening},
+		{doom.ID, evening.Add(time.Hour)},
+		{quake.ID, evening.Add(90 * time.Minute)},
+		{quake.ID, cutoff},
+		{quake.ID, cutoff.Add(5 * time.Hour)},
+	} {
+		vote, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: "voter" + strconv.Itoa(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: ballot.option}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Exec("UPDATE votes SET created_at = ? WHERE id = ?", ballot.at, vote.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	standings := func(from, to time.Time) string {
+		t.Helper()
+		tallied, votes, err := q.TallyBetween(ctx, cat, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, o := range tallied {
+			got = append(got, o.Name+" "+o.Label)
+		}
+		return fmt.Sprintf("%d: %s", votes, strings.Join(got, ", "))
+	}
+	if got, want := standings(time.Time{}, cutoff), "3: Doom 2 votes, Quake 1 vote"; got != want {
+		t.Errorf("before the cutoff: expected %q, got %q", want, got)
+	}
+	if got, want := standings(cutoff, time.Time{}), "2: Quake 2 votes, Doom 0 votes"; got != want {
+		t.Errorf("from the cutoff: expected %q, got %q", want, got)
+	}
+	if got, want := standings(time.Time{}, time.Time{}), "5: Quake 3 votes, Doom 2 votes"; got != want {
+		t.Errorf("all ballots: expected %q, got %q", want, got)
+	}
+
+	// A voter who changed their mind overnight counts as they voted by then
+	castAt(t, conn, q, cat.ID, "late changer", doom.ID, evening.Add(30*time.Minute))
+	castAt(t, conn, q, cat.ID, "late changer", quake.ID, cutoff.Add(time.Hour))
+	if got, want := standings(time.Time{}, cutoff), "4: Doom 3 votes, Quake 1 vote"; got != want {
+		t.Errorf("before the cutoff, after a change: expected %q, got %q", want, got)
+	}
+	if got, want := standings(cutoff, time.Time{}), "3: Quake 3 votes, Doom 0 votes"; got != want {
+		t.Errorf("from the cutoff, after a change: expected %q, got %q", want, got)
+	}
+	if got, want := standings(evening.Add(45*time.Minute), cutoff), "2: Doom 1 vote, Quake 1 vote"; got != want {
+		t.Errorf("inside the evening, after a change: expected %q, got %q", want, got)
+	}
+	if got, want := standings(time.Time{}, time.Time{}), "6: Quake 4 votes, Doom 2 votes"; got != want {
+		t.Errorf("all ballots, after a change: expected %q, got %q", want, got)
+	}
+
+	// Weights still apply to the ballots in the window
+	if err := q.UpsertAttendee(ctx, db.UpsertAttendeeParams{Nickname: "voter0"}); err != nil {
+		t.Fatal(err)
+	}
+	crew, err := q.GetAttendeeByNickname(ctx, "voter0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.SetAttendeeTags(ctx, crew.ID, []string{"crew"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := standings(time.Time{}, cutoff), "4: Doom 5 votes, Quake 1 vote"; got != want {
+		t.Errorf("weighted, before the cutoff: expected %q, got %q", want, got)
+	}
+}
+
+func TestInvalidateBallots(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := t.Context()
+	q := db.New(conn)
+	cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{Name: "Best Game", VoteType: "single", Status: "open", ShowResults: "live"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doom, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "Doom"})
+	quake, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "Quake"})
+
+	opens := time.Date(2025, 10, 17, 18, 0, 0, 0, time.UTC)
+	closes := opens.Add(4 * time.Hour)
+	votes := map[string]int64{}
+	for _, ballot := range []struct {
+		nickname string
+		option   int64
+		at       time.Time
+		address  string
+	}{
+		{"early", quake.ID, opens.Add(-time.Minute), "10.0.0.1"},
+		{"alice", doom.ID, opens.Add(time.Hour), "10.0.0.2"},
+		{"bob", doom.ID, opens.Add(2 * time.Hour), "10.0.0.3"},
+		{"stuffer1", quake.ID, opens.Add(2 * time.Hour), "10.0.9.4"},
+		{"stuffer2", quake.ID, opens.Add(2 * time.Hour), "10.0.9.5"},
+		{"mallory", quake.ID, opens.Add(3 * time.Hour), "10.0.0.6"},
+		{"late", quake.ID, closes, "10.0.0.7"},
+	} {
+		vote, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: ballot.nickname})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: ballot.option}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Exec("UPDATE votes SET created_at = ? WHERE id = ?", ballot.at, vote.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := q.RecordAuditFrom(ctx, db.AuditOrigin{Address: ballot.address}, ballot.nickname, db.AuditVote, cat.ID, ""); err != nil {
+			t.Fatal(err)
+		}
+		votes[ballot.nickname] = vote.ID
+	}
+	// carol voted in the window and changed their ballot after it closed
+	castAt(t, conn, q, cat.ID, "carol", doom.ID, opens.Add(time.Hour))
+	castAt(t, conn, q, cat.ID, "carol", quake.ID, closes.Add(time.Hour))
+
+	standings := func() string {
+		t.Helper()
+		tallied, err := q.Tally(ctx, cat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count, err := q.CountVotesByCategory(ctx, cat.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, o := range tallied {
+			got = append(got, o.Name+" "+o.Label)
+		}
+		return fmt.Sprintf("%d: %s", count, strings.Join(got, ", "))
+	}
+	if got, want := standings(), "8: Quake 6 votes, Doom 2 votes"; got != want {
+		t.Fatalf("before invalidating: expected %q, got %q", want, got)
+	}
+
+	if _, _, err := db.InvalidateBallots(ctx, conn, cat.ID, db.BallotFilter{}, "nothing", db.ActorAdmin); !errors.Is(err, db.ErrNoBallotFilter) {
+		t.Errorf("expected an empty filter to be refused, got %v", err)
+	}
+	if _, _, err := db.InvalidateBallots(ctx, conn, cat.ID, db.BallotFilter{From: opens}, " ", db.ActorAdmin); !errors.Is(err, db.ErrConflict) {
+		t.Errorf("expected a missing reason to be refused, got %v", err)
+	}
+
+	window := db.BallotFilter{From: opens, To: closes}
+	n, cut, err := db.InvalidateBallots(ctx, conn, cat.ID, window, "outside the official window", db.ActorAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || cut != 1 {
+		t.Errorf("expected the early and late ballots invalidated and carol's counted until the window closed, got %d and %d", n, cut)
+	}
+	if n, cut, err = db.InvalidateBallots(ctx, conn, cat.ID, window, "outside the official window", db.ActorAdmin); err != nil || n != 0 || cut != 0 {
+		t.Errorf("expected invalidating the window again to change nothing, got %d, %d, %v", n, cut, err)
+	}
+	carol, err := q.GetVoteByNickname(ctx, db.GetVoteByNicknameParams{CategoryID: cat.ID, Nickname: "carol"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !carol.CountedUntil.Valid || !carol.CountedUntil.Time.Equal(closes) || carol.Version != 2 {
+		t.Errorf("expected carol's ballot kept as cast and counted until %v, got version %d until %v", closes, carol.Version, carol.CountedUntil)
+	}
+	lab, err := db.ParseAddresses("10.0.9.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, _, err = db.InvalidateBallots(ctx, conn, cat.ID, db.BallotFilter{Addresses: lab, Nicknames: []string{"mallory"}, To: closes}, "banned", db.ActorAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("expected both lab ballots and mallory's invalidated, leaving the late one as it was; got %d", n)
+	}
+	if got, want := standings(), "3: Doom 3 votes, Quake 0 votes"; got != want {
+		t.Errorf("after invalidating: expected %q, got %q", want, got)
+	}
+	late, err := q.GetVote(ctx, votes["late"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if late.Invalid != "outside the official window" {
+		t.Errorf("expected the late ballot to keep its first reason, got %q", late.Invalid)
+	}
+
+	// Voting again doesn't launder a ballot
+	if _, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: "mallory"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := standings(), "3: Doom 3 votes, Quake 0 votes"; got != want {
+		t.Errorf("after mallory voted again: expected %q, got %q", want, got)
+	}
+
+	if err := db.RestoreBallot(ctx, conn, cat.ID, votes["early"], db.ActorAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := standings(), "4: Doom 3 votes, Quake 1 vote"; got != want {
+		t.Errorf("after restoring the early ballot: expected %q, got %q", want, got)
+	}
+	// Restoring carol's ballot counts the one they have now
+	if err := db.RestoreBallot(ctx, conn, cat.ID, carol.ID, db.ActorAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := standings(), "4: Doom 2 votes, Quake 2 votes"; got != want {
+		t.Errorf("after restoring carol's ballot: expected %q, got %q", want, got)
+	}
+	if err := db.RestoreBallot(ctx, conn, cat.ID+1, votes["late"], db.ActorAdmin); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expected a ballot from another poll to be not found, got %v", err)
+	}
+
+	// An archived poll's results are final
+	if err := q.UpdateCategoryStatus(ctx, db.UpdateCategoryStatusParams{ID: cat.ID, Status: "archived"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := db.InvalidateBallots(ctx, conn, cat.ID, db.BallotFilter{Nicknames: []string{"alice"}}, "late change", db.ActorAdmin); !errors.Is(err, db.ErrArchived) || !errors.Is(err, db.ErrConflict) {
+		t.Errorf("expected invalidating in an archived poll refused, got %v", err)
+	}
+	if err := db.RestoreBallot(ctx, conn, cat.ID, votes["late"], db.ActorAdmin); !errors.Is(err, db.ErrArchived) {
+		t.Errorf("expected restoring in an archived poll refused, got %v", err)
+	}
+	if got, want := standings(), "4: Doom 2 votes, Quake 2 votes"; got != want {
+		t.Errorf("after archiving: expected %q, got %q", want, got)
+	}
+
+	var details []string
+	rows, err := conn.Query("SELECT detail FROM audit_events WHERE category_id = ? AND action IN (?, ?) ORDER BY id", cat.ID, db.AuditBallotInvalid, db.AuditBallotRestore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var detail string
+		rows.Scan(&detail)
+		details = append(details, detail)
+	}
+	want := []string{
+		"2 ballots: outside the official window; 1 changed after the window count as they stood at its end",
+		"3 ballots: banned",
+		fmt.Sprintf("ballot #%d, invalid for: outside the official window", votes["early"]),
+		fmt.Sprintf("ballot #%d, counted until %s", carol.ID, closes.Format(time.RFC3339)),
+	}
+	if !slices.Equal(details, want) {
+		t.Errorf("expected audit details %q, got %q", want, details)
+	}
+}
+
+func TestOptionDetails(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := t.Context()
+	q := db.New(conn)
+	cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{Name: "Best Game", VoteType: "single", Status: "open", ShowResults: "live"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt, err := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "Doom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opt.HasDetails() || len(opt.ScreenshotURLs()) != 0 {
+		t.Fatalf("expected a new option without details, got %+v", opt)
+	}
+
+	if err := q.SetOptionDetails(ctx, db.SetOptionDetailsParams{
+		Blurb:       "Rip and tear.",
+		Screenshots: "/images/e1m1.png\n\nhttps://example.com/e1m2.png\n",
+		ID:          opt.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	opt, err = q.GetOption(ctx, opt.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opt.HasDetails() || opt.Blurb != "Rip and tear." {
+		t.Errorf("expected the details saved, got %+v", opt)
+	}
+	if want := []string{"/images/e1m1.png", "https://example.com/e1m2.png"}; !slices.Equal(opt.ScreenshotURLs(), want) {
+		t.Errorf("expected screenshots %q, got %q", want, opt.ScreenshotURLs())
+	}
+
+	ballot, err := q.ListBallotOptionsByCategory(ctx, cat.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ballot) != 1 || !ballot[0].HasDetails() {
+		t.Errorf("expected the ballot to carry the details, got %+v", ballot)
+	}
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrDependencyCycle is returned by CheckDependency when a poll would end up
+// waiting on itself
+var ErrDependencyCycle = errors.New("polls would wait on each other and never open")
+
+// Errors from SeedFromResults
+var (
+	ErrSeedTargetNotDraft = errors.New("options can only be seeded into a draft poll")
+	ErrSeedSourceOpen     = errors.New("options can only be seeded from a poll that has closed")
+)
+
+// Finished reports whether voting on cat is over, which is what a poll
+// depending on it waits for
+func (c Category) Finished() bool {
+	return c.Status == "closed" || c.Status == "archived"
+}
+
+// CheckDependency checks that category id (0 for one not created yet) can
+// open after dependsOn: the poll must exist (ErrNotFound) and must not
+// already wait, directly or through others, on id.
+func (q *Queries) CheckDependency(ctx context.Context, id, dependsOn int64) error {
+	seen := map[int64]bool{}
+	for next := dependsOn; next != 0; {
+		if next == id || seen[next] {
+			return ErrDependencyCycle
+		}
+		seen[next] = true
+
+		cat, err := q.Category(ctx, next)
+		if err != nil {
+			return err
+		}
+		next = cat.DependsOn.Int64
+	}
+	return nil
+}
+
+// WaitingOn returns the poll cat opens after, if that poll hasn't finished
+func (q *Queries) WaitingOn(ctx context.Context, cat Category) (Category, bool, error) {
+	if !cat.DependsOn.Valid {
+		return Category{}, false, nil
+	}
+	prev, err := q.Category(ctx, cat.DependsOn.Int64)
+	if errors.Is(err, ErrNotFound) {
+		return Category{}, false, nil
+	}
+	if err != nil {
+		return Category{}, false, err
+	}
+	return prev, !prev.Finished(), nil
+}
+
+// TopOptions returns the options in the top n places of cat's result, by
+// votes or ranked points, including any tied with the last of them.
+// Retired options and options nobody voted for are left out.
+func (q *Queries) TopOptions(ctx context.Context, cat Category, n int64) ([]Option, error) {
+	ranking, err := q.Tally(ctx, cat)
+	if err != nil {
+		return nil, err
+	}
+
+	var top []Option
+	cutoff := int64(-1)
+	for _, r := range ranking {
+		if r.RetiredAt.Valid || r.Score == 0 {
+			continue
+		}
+		if int64(len(top)) >= n && r.Score < cutoff {
+			break
+		}
+		top = append(top, r.Option)
+		cutoff = r.Score
+	}
+	return top, nil
+}
+
+// SeedFromResults copies the top n options of the finished poll source into
+// the draft poll target (see TopOptions and SeedOptions). It is how a runoff
+// or final round is filled from earlier results.
+func (q *Queries) SeedFromResults(ctx context.Context, target, source Category, n int64) ([]Option, error) {
+	switch {
+	case target.Status != "draft":
+		return nil, ErrSeedTargetNotDraft
+	case !source.Finished():
+		return nil, ErrSeedSourceOpen
+	}
+	top, err := q.TopOptions(ctx, source, n)
+	if err != nil {
+		return nil, err
+	}
+	return q.SeedOptions(ctx, target, top)
+}
+
+// SeedOptions adds each of from to cat, after its existing options, unless
+// cat already has an option of the same name, ignoring case. Each new option
+// records the option it was copied from. It returns the options added.
+func (q *Queries) SeedOptions(ctx context.Context, cat Category, from []Option) ([]Option, error) {
+	count, err := q.CountOptionsByCategory(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []Option
+	for _, src := range from {
+		opt, err := q.CreateSeededOption(ctx, CreateSeededOptionParams{
+			CategoryID: cat.ID,
+			Name:       src.Name,
+			SortOrder:  sql.NullInt64{Int64: count + int64(len(added)), Valid: true},
+			SeededFrom: sql.NullInt64{Int64: src.ID, Valid: true},
+			Image:      src.Image,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue // the name is taken
+		}
+		if err != nil {
+			return added, fmt.Errorf("add %q: %w", src.Name, err)
+		}
+		added = append(added, opt)
+	}
+	return added, nil
+}
+
+// SeededPoll is a poll SeedDependents added options to
+type SeededPoll struct {
+	Category Category
+	Options  []Option
+}
+
+// SeedDependents seeds every draft poll that opens after cat and asks for
+// its top options, now that cat has finished. Polls already open are left
+// alone so a ballot never changes under a voter.
+func (q *Queries) SeedDependents(ctx context.Context, cat Category) ([]SeededPoll, error) {
+	dependents, err := q.ListDependentCategories(ctx, sql.NullInt64{Int64: cat.ID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var seeded []SeededPoll
+	for _, dep := range dependents {
+		if dep.Status != "draft" || dep.SeedTopN <= 0 {
+			continue
+		}
+		added, err := q.SeedFromResults(ctx, dep, cat, dep.SeedTopN)
+		if err != nil {
+			return seeded, fmt.Errorf("seed %s: %w", dep.Name, err)
+		}
+		if len(added) > 0 {
+			seeded = append(seeded, SeededPoll{Category: dep, Options: added})
+		}
+	}
+	return seeded, nil
+}
@@ -0,0 +1,14 @@
+package db
+
+import "strings"
+
+// HasDetails reports whether the option has more for voters to see than
+// its name and image: a blurb, a video or screenshots
+func (o Option) HasDetails() bool {
+	return o.Blurb != "" || o.Video != "" || o.Screenshots != ""
+}
+
+// ScreenshotURLs lists the option's screenshots, stored one per line
+func (o Option) ScreenshotURLs() []string {
+	return strings.Fields(o.Screenshots)
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Eligibility is who may vote in a poll, by the tags attendees have on the
+// roster. It is written as terms separated by spaces, all of which must
+// hold: a tag the voter needs, several joined by | when any of them will
+// do, or a tag the voter mustn't have, prefixed with !. "participant" is
+// tournament participants only, "!crew" everyone but crew and "player|caster
+// !crew" players and casters who aren't crew. The empty expression lets
+// everyone vote. A voter who isn't on the roster has no tags.
+type Eligibility struct {
+	Require [][]string // each term's tags, any one of which the voter needs
+	Exclude []string   // tags the voter mustn't have
+}
+
+// IneligibleError refuses a ballot from a voter that Eligibility leaves
+// out. Its message is shown to the voter.
+type IneligibleError struct {
+	Need     []string // a term the voter's tags didn't satisfy; nil if Excluded
+	Excluded string   // the tag that excluded the voter
+}
+
+func (e *IneligibleError) Error() string {
+	if e.Excluded != "" {
+		return fmt.Sprintf("Attendees tagged %s can't vote in this poll", e.Excluded)
+	}
+	return fmt.Sprintf("Only attendees tagged %s can vote in this poll", strings.Join(e.Need, " or "))
+}
+
+// NormalizeTag lowercases a roster tag and checks it is letters, digits,
+// hyphens and underscores. Errors are phrased for showing to an admin.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", errors.New("Tags can't be empty")
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return "", fmt.Errorf("%q isn't a tag: use letters, digits, - and _", tag)
+		}
+	}
+	return tag, nil
+}
+
+// ParseTags reads a list of roster tags separated by spaces or commas,
+// returning them normalized, sorted and without duplicates
+func ParseTags(s string) ([]string, error) {
+	var tags []string
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		tag, err := NormalizeTag(field)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags), nil
+}
+
+// ParseEligibility reads an eligibility expression. Errors are phrased for
+// showing to an admin.
+func ParseEligibility(expr string) (Eligibility, error) {
+	var e Eligibility
+	for _, term := range strings.Fields(expr) {
+		if name, ok := strings.CutPrefix(term, "!"); ok {
+			tag, err := NormalizeTag(name)
+			if err != nil {
+				return e, fmt.Errorf("Eligibility: %w", err)
+			}
+			e.Exclude = append(e.Exclude, tag)
+			continue
+		}
+		var anyOf []string
+		for _, name := range strings.Split(term, "|") {
+			tag, err := NormalizeTag(name)
+			if err != nil {
+				return e, fmt.Errorf("Eligibility: %w", err)
+			}
+			anyOf = append(anyOf, tag)
+		}
+		e.Require = append(e.Require, anyOf)
+	}
+	return e, nil
+}
+
+// String writes e back as an expression, the form it is stored in
+func (e Eligibility) String() string {
+	terms := make([]string, 0, len(e.Require)+len(e.Exclude))
+	for _, anyOf := range e.Require {
+		terms = append(terms, strings.Join(anyOf, "|"))
+	}
+	for _, tag := range e.Exclude {
+		terms = append(terms, "!"+tag)
+	}
+	return strings.Join(terms, " ")
+}
+
+// Check returns an IneligibleError if a voter with tags may not vote
+func (e Eligibility) Check(tags []string) error {
+	for _, tag := range e.Exclude {
+		if slices.Contains(tags, tag) {
+			return &IneligibleError{Excluded: tag}
+		}
+	}
+	for _, anyOf := range e.Require {
+		if !slices.ContainsFunc(anyOf, func(tag string) bool { return slices.Contains(tags, tag) }) {
+			return &IneligibleError{Need: anyOf}
+		}
+	}
+	return nil
+}
+
+// CheckEligible returns an IneligibleError if the voter with nickname, as
+// stored (see NicknameCipher.Seal), may not vote in cat, going by their tags
+// on the roster now
+func (q *Queries) CheckEligible(ctx context.Context, cat Category, nickname string) error {
+	if cat.Eligibility == "" {
+		return nil
+	}
+	e, err := ParseEligibility(cat.Eligibility)
+	if err != nil {
+		return err
+	}
+	tags, err := q.ListTagsByNickname(ctx, nickname)
+	if err != nil {
+		return err
+	}
+	return e.Check(tags)
+}
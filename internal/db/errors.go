@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Kinds of failure callers tell apart with errors.Is, whichever query they
+// came from. Errors from this package's own methods are already classified;
+// wrap errors from the generated queries with Classify.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflicts with an existing record")
+	ErrClosed   = errors.New("voting is not open")
+)
+
+// Error is a failure classified as ErrNotFound, ErrConflict or ErrClosed.
+// It keeps the error it classifies, so errors.Is(err, sql.ErrNoRows) still
+// holds for a missing row.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string   { return e.Err.Error() }
+func (e *Error) Unwrap() []error { return []error{e.Kind, e.Err} }
+
+// Classify wraps err in an Error when it is a missing row or a broken
+// uniqueness constraint. Other errors, and ones already classified, are
+// returned as they are.
+func Classify(err error) error {
+	var classified *Error
+	switch {
+	case err == nil, errors.As(err, &classified):
+		return err
+	case errors.Is(err, sql.ErrNoRows):
+		return &Error{Kind: ErrNotFound, Err: err}
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return &Error{Kind: ErrConflict, Err: err}
+		}
+	}
+	return err
+}
+
+// Category looks up a category by ID. A missing one is ErrNotFound.
+func (q *Queries) Category(ctx context.Context, id int64) (Category, error) {
+	cat, err := q.GetCategory(ctx, id)
+	return cat, Classify(err)
+}
+
+// Option looks up an option by ID. A missing one is ErrNotFound.
+func (q *Queries) Option(ctx context.Context, id int64) (Option, error) {
+	opt, err := q.GetOption(ctx, id)
+	return opt, Classify(err)
+}
+
+// CheckOpen returns ErrClosed unless c is taking votes
+func (c Category) CheckOpen() error {
+	if c.Status != "open" {
+		return &Error{Kind: ErrClosed, Err: fmt.Errorf("%s is %s: %w", c.Name, c.Status, ErrClosed)}
+	}
+	return nil
+}
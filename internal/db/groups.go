@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+// GroupResult is how the attendees with one roster tag took part in a poll
+type GroupResult struct {
+	Tag       string
+	Attendees int64           // attendees with the tag
+	Voted     int64           // those of them who voted
+	Tally     []TalliedOption // their ballots counted on their own; nil unless asked for or nobody voted
+}
+
+// Winner is the option the group's ballots put first, if they were tallied
+func (g GroupResult) Winner() (TalliedOption, bool) {
+	if len(g.Tally) == 0 {
+		return TalliedOption{}, false
+	}
+	return g.Tally[0], true
+}
+
+// GroupResults breaks cat's turnout down by roster tag, in tag order. With
+// tallies, each group's ballots are also counted on their own the way the
+// poll is, once each whatever its weights, to compare how the groups voted.
+// A voter with several tags counts in each of their groups.
+func (q *Queries) GroupResults(ctx context.Context, cat Category, tallies bool) ([]GroupResult, error) {
+	rows, err := q.ListTagTurnout(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]GroupResult, len(rows))
+	for i, row := range rows {
+		groups[i] = GroupResult{Tag: row.Tag, Attendees: row.Attendees, Voted: row.Voted}
+	}
+	if !tallies {
+		return groups, nil
+	}
+
+	ballots, voteIDs, err := q.ballots(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	voteTags, err := q.ListVoteTagsByCategory(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[int64]int, len(voteIDs)) // vote ID to position in ballots
+	for i, id := range voteIDs {
+		index[id] = i
+	}
+	byTag := make(map[string][]tally.Ballot)
+	for _, row := range voteTags {
+		if i, ok := index[row.VoteID]; ok {
+			byTag[row.Tag] = append(byTag[row.Tag], ballots[i])
+		}
+	}
+
+	options, err := q.ListOptionsByCategory(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]Option, len(options))
+	for _, o := range options {
+		byID[o.ID] = o
+	}
+	for i := range groups {
+		b := byTag[groups[i].Tag]
+		if len(b) == 0 {
+			continue
+		}
+		result := cat.TallyMethod()(TallyOptions(options), b)
+		groups[i].Tally = make([]TalliedOption, len(result.Ranking))
+		for j, s := range result.Ranking {
+			groups[i].Tally[j] = TalliedOption{byID[s.OptionID], s}
+		}
+	}
+	return groups, nil
+}
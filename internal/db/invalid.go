@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrNoBallotFilter refuses to invalidate ballots without saying which.
+// It comes wrapped as ErrConflict, as does a missing reason.
+var ErrNoBallotFilter = errors.New("give an official window, addresses or nicknames to invalidate")
+
+// ErrArchived refuses to change the ballots of an archived poll, whose
+// results are published as final and cached as such. It comes wrapped as
+// ErrConflict.
+var ErrArchived = errors.New("the poll is archived, so its ballots can no longer change")
+
+// checkNotArchived returns ErrArchived for an archived poll
+func (q *Queries) checkNotArchived(ctx context.Context, categoryID int64) error {
+	cat, err := q.Category(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+	if cat.Status == "archived" {
+		return &Error{Kind: ErrConflict, Err: ErrArchived}
+	}
+	return nil
+}
+
+// BallotFilter picks out the ballots to invalidate. A ballot matches when
+// the one its voter had just before To (or now, for a zero To) was cast
+// before From, or they hadn't voted by To, as TallyBetween counts them; a
+// zero time leaves that end open. It also matches when its vote came from
+// one of Addresses, or when it was cast under one of Nicknames, given as
+// stored (see NicknameCipher.Seal). Any one criterion is enough.
+type BallotFilter struct {
+	From, To  time.Time
+	Addresses []netip.Prefix
+	Nicknames []string
+}
+
+// Empty reports whether the filter matches nothing
+func (f BallotFilter) Empty() bool {
+	return f.From.IsZero() && f.To.IsZero() && len(f.Addresses) == 0 && len(f.Nicknames) == 0
+}
+
+// match reports whether the ballot matches the filter, given every ballot
+// its voter has had (see castBallots)
+func (f BallotFilter) match(b ListVoteOriginsRow, cast []castBallot) bool {
+	if !f.From.IsZero() || !f.To.IsZero() {
+		standing, ok := standingAt(cast, f.To)
+		if !ok || (!f.From.IsZero() && standing.at.Before(f.From)) {
+			return true
+		}
+	}
+	if slices.Contains(f.Nicknames, b.Nickname) {
+		return true
+	}
+	if addr, err := netip.ParseAddr(b.Address); err == nil {
+		addr = addr.Unmap()
+		for _, p := range f.Addresses {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseAddresses reads client addresses separated by spaces, commas or
+// newlines, each a single address like 10.0.0.7 or a range like
+// 10.0.0.0/24
+func ParseAddresses(s string) ([]netip.Prefix, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t' })
+	prefixes := make([]netip.Prefix, 0, len(fields))
+	for _, f := range fields {
+		if p, err := netip.ParsePrefix(f); err == nil {
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(f)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an address like 10.0.0.7 or a range like 10.0.0.0/24", f)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// InvalidateBallots marks the ballots in a poll that filter matches
+// invalid, for reason, in one transaction, and records it in the audit log
+// as actor. Invalid ballots are kept but no longer counted, and stay
+// invalid if their voter votes again; RestoreBallot counts one again.
+// Ballots already invalid are left as they are, and an archived poll's
+// are ErrArchived. A voter who cast their ballot inside the window and
+// changed it at or after To is marked to count until To instead, with the
+// ballot they had then; their later ballots are kept, and RestoreBallot
+// counts the current one again. It returns how many ballots it marked
+// invalid and how many it marked to count until To.
+func InvalidateBallots(ctx context.Context, conn *sql.DB, categoryID int64, filter BallotFilter, reason, actor string) (marked, cut int, err error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return 0, 0, &Error{Kind: ErrConflict, Err: errors.New("a reason is required")}
+	}
+	if filter.Empty() {
+		return 0, 0, &Error{Kind: ErrConflict, Err: ErrNoBallotFilter}
+	}
+
+	err = InTx(ctx, conn, func(q *Queries) error {
+		if err := q.checkNotArchived(ctx, categoryID); err != nil {
+			return err
+		}
+		ballots, err := q.ListVoteOrigins(ctx, categoryID)
+		if err != nil {
+			return err
+		}
+		cast, err := q.castBallots(ctx, categoryID)
+		if err != nil {
+			return err
+		}
+		for _, b := range ballots {
+			if b.Invalid != "" {
+				continue
+			}
+			if filter.match(b, cast[b.ID]) {
+				if err := q.SetVoteInvalid(ctx, SetVoteInvalidParams{Invalid: reason, ID: b.ID}); err != nil {
+					return err
+				}
+				marked++
+				continue
+			}
+			if filter.To.IsZero() || b.CountedUntil.Valid {
+				continue
+			}
+			kept, _ := standingAt(cast[b.ID], filter.To)
+			current := cast[b.ID][len(cast[b.ID])-1]
+			if kept.version == current.version || maps.Equal(kept.ballot, current.ballot) {
+				continue
+			}
+			if err := q.SetVoteCountedUntil(ctx, SetVoteCountedUntilParams{CountedUntil: sql.NullTime{Time: filter.To, Valid: true}, ID: b.ID}); err != nil {
+				return err
+			}
+			cut++
+		}
+		if marked == 0 && cut == 0 {
+			return nil
+		}
+		detail := fmt.Sprintf("%d ballots: %s", marked, reason)
+		if cut > 0 {
+			detail += fmt.Sprintf("; %d changed after the window count as they stood at its end", cut)
+		}
+		return q.RecordAudit(ctx, actor, AuditBallotInvalid, categoryID, detail)
+	})
+	return marked, cut, err
+}
+
+// RestoreBallot counts an invalidated ballot again, or a ballot counted
+// until its official window's end as it stands now, and records it in the
+// audit log as actor. A ballot not in categoryID is ErrNotFound, and one
+// in an archived poll ErrArchived.
+func RestoreBallot(ctx context.Context, conn *sql.DB, categoryID, voteID int64, actor string) error {
+	return InTx(ctx, conn, func(q *Queries) error {
+		if err := q.checkNotArchived(ctx, categoryID); err != nil {
+			return err
+		}
+		vote, err := q.GetVote(ctx, voteID)
+		if err == nil && vote.CategoryID != categoryID {
+			err = sql.ErrNoRows
+		}
+		if err != nil {
+			return Classify(err)
+		}
+		if vote.CountedUntil.Valid {
+			if err := q.SetVoteCountedUntil(ctx, SetVoteCountedUntilParams{ID: voteID}); err != nil {
+				return err
+			}
+			if vote.Invalid == "" {
+				return q.RecordAudit(ctx, actor, AuditBallotRestore, categoryID, fmt.Sprintf("ballot #%d, counted until %s", voteID, vote.CountedUntil.Time.Format(time.RFC3339)))
+			}
+		}
+		if vote.Invalid == "" {
+			return nil
+		}
+		if err := q.SetVoteInvalid(ctx, SetVoteInvalidParams{ID: voteID}); err != nil {
+			return err
+		}
+		return q.RecordAudit(ctx, actor, AuditBallotRestore, categoryID, fmt.Sprintf("ballot #%d, invalid for: %s", voteID, vote.Invalid))
+	})
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"slices"
+
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+// DefaultJudgeWeight is the percentage of a judged poll's result its judges
+// decide unless the admin picks another split
+const DefaultJudgeWeight = 50
+
+// JudgeScore is how a judged poll's judges and its audience each placed an
+// option, counted apart the way the poll is
+type JudgeScore struct {
+	Jury     tally.Standing
+	Audience tally.Standing
+}
+
+// Judged reports whether the poll is scored by judges as well as its
+// audience. Voters with the Judges roster tag are the judges; everyone
+// else is the audience.
+func (c Category) Judged() bool {
+	return c.Judges != ""
+}
+
+// JudgeScores counts a judged poll's judges and audience apart, by option
+// ID, for showing beside the combined result Tally ranks by. A poll whose
+// ballots were purged has no scores left.
+func (q *Queries) JudgeScores(ctx context.Context, cat Category) (map[int64]JudgeScore, error) {
+	options, err := q.ListOptionsByCategory(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	ballots, voteIDs, err := q.ballots(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	jury, audience, err := q.judgedResults(ctx, cat, options, ballots, voteIDs)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[int64]JudgeScore, len(options))
+	for _, s := range jury.Ranking {
+		score := scores[s.OptionID]
+		score.Jury = s
+		scores[s.OptionID] = score
+	}
+	for _, s := range audience.Ranking {
+		score := scores[s.OptionID]
+		score.Audience = s
+		scores[s.OptionID] = score
+	}
+	return scores, nil
+}
+
+// judgedResults splits cat's ballots between voters with its judges' tag
+// and everyone else, going by their tags now, and counts each side the way
+// cat is counted. Tag weights apply within each side. Each of ballots is
+// from the vote with the matching ID in voteIDs.
+func (q *Queries) judgedResults(ctx context.Context, cat Category, options []Option, ballots []tally.Ballot, voteIDs []int64) (jury, audience tally.Result, err error) {
+	weights, err := ParseTagWeights(cat.Weights)
+	if err != nil {
+		return jury, audience, err
+	}
+	tags, err := q.voteTags(ctx, cat.ID)
+	if err != nil {
+		return jury, audience, err
+	}
+
+	var juryBallots, audienceBallots []tally.Ballot
+	for i, b := range ballots {
+		voter := tags[voteIDs[i]]
+		side := &audienceBallots
+		if slices.Contains(voter, cat.Judges) {
+			side = &juryBallots
+		}
+		for range weights.Weight(voter) {
+			*side = append(*side, b)
+		}
+	}
+	method, tallied := cat.TallyMethod(), TallyOptions(options)
+	return method(tallied, juryBallots), method(tallied, audienceBallots), nil
+}
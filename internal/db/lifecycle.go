@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Reasons OpenCategory, CloseCategory and ReopenCategory refuse a poll.
+// They come wrapped as ErrConflict.
+var (
+	ErrNoOptions = errors.New("the poll has no options to vote on")
+	ErrStarted   = errors.New("only a draft poll can be opened")
+	ErrNotOpen   = errors.New("only an open poll can be closed")
+	ErrNotClosed = errors.New("only a closed poll can be reopened")
+)
+
+// notFrom is why transition refuses a poll not in the status it moves from
+var notFrom = map[string]error{"draft": ErrStarted, "open": ErrNotOpen, "closed": ErrNotClosed}
+
+// WaitingError refuses to open a poll set to open after another that hasn't
+// finished yet. It comes wrapped as ErrConflict.
+type WaitingError struct {
+	Prev Category
+}
+
+func (e *WaitingError) Error() string {
+	return fmt.Sprintf("the poll opens after %s, which is still %s", e.Prev.Name, e.Prev.Status)
+}
+
+// InTx runs fn with Queries bound to a new transaction on conn, committing
+// if fn succeeds and rolling back otherwise
+func InTx(ctx context.Context, conn *sql.DB, fn func(q *Queries) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(New(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// OpenCategory opens voting for category id and records it in the audit log
+// as actor. The poll must be a draft (ErrStarted), needs an option on the
+// ballot and mustn't be waiting for another to close; all are checked in
+// the transaction that opens it, so an option deleted meanwhile can't leave
+// an empty poll open. Test ballots cast
+// while it was a draft are deleted in the same transaction.
+func OpenCategory(ctx context.Context, conn *sql.DB, id int64, actor string) (Category, error) {
+	return transition(ctx, conn, id, actor, AuditCategoryOpen, "draft", "open", func(ctx context.Context, q *Queries, cat Category) error {
+		if err := checkOpenable(ctx, q, cat); err != nil {
+			return err
+		}
+		return purgeTestBallots(ctx, q, cat, actor)
+	})
+}
+
+// ReopenCategory opens voting again for a closed poll, with the same checks
+// as OpenCategory. A poll whose ballots were purged stays closed.
+func ReopenCategory(ctx context.Context, conn *sql.DB, id int64, actor string) (Category, error) {
+	return transition(ctx, conn, id, actor, AuditCategoryReopen, "closed", "open", func(ctx context.Context, q *Queries, cat Category) error {
+		purged, err := q.Purged(ctx, cat.ID)
+		if err != nil {
+			return err
+		}
+		if purged {
+			return &Error{Kind: ErrConflict, Err: ErrPurged}
+		}
+		return checkOpenable(ctx, q, cat)
+	})
+}
+
+// CloseCategory closes voting for category id and records it in the audit
+// log as actor. Only an open poll closes (ErrNotOpen). Seeding the polls that open after it (SeedDependents) is
+// left to the caller, so closing stands even if seeding fails.
+func CloseCategory(ctx context.Context, conn *sql.DB, id int64, actor string) (Category, error) {
+	return transition(ctx, conn, id, actor, AuditCategoryClose, "open", "closed", nil)
+}
+
+// checkOpenable refuses to open a poll with nothing to vote on, or one still
+// waiting for the poll it opens after
+func checkOpenable(ctx context.Context, q *Queries, cat Category) error {
+	count, err := q.CountBallotOptionsByCategory(ctx, cat.ID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return &Error{Kind: ErrConflict, Err: ErrNoOptions}
+	}
+	prev, waiting, err := q.WaitingOn(ctx, cat)
+	if err != nil {
+		return err
+	}
+	if waiting {
+		return &Error{Kind: ErrConflict, Err: &WaitingError{Prev: prev}}
+	}
+	return nil
+}
+
+// transition moves category id from status from to status in one
+// transaction: it loads the poll, refuses it if it isn't in from (see
+// notFrom), runs check on it, updates the status and records action in the
+// audit log. The poll is returned with its new status.
+func transition(ctx context.Context, conn *sql.DB, id int64, actor, action, from, status string,
+	check func(ctx context.Context, q *Queries, cat Category) error) (Category, error) {
+
+	var cat Category
+	err := InTx(ctx, conn, func(q *Queries) error {
+		var err error
+		if cat, err = q.Category(ctx, id); err != nil {
+			return err
+		}
+		if cat.Status != from {
+			return &Error{Kind: ErrConflict, Err: notFrom[from]}
+		}
+		if check != nil {
+			if err := check(ctx, q, cat); err != nil {
+				return err
+			}
+		}
+		err = q.UpdateCategoryStatus(ctx, UpdateCategoryStatusParams{Status: status, ID: id})
+		if err != nil {
+			return err
+		}
+		cat.Status = status
+		return q.RecordAudit(ctx, actor, action, id, "")
+	})
+	return cat, err
+}
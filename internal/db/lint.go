@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+// LintClosingSoon is how near its planned closing time a poll without a
+// single vote is warned about
+const LintClosingSoon = 15 * time.Minute
+
+// Warning is a poll or setting set up in a way that is likely to go wrong
+// on the night, found by Lint. Setting is empty for a poll's warnings and
+// CategoryID 0 for a setting's.
+type Warning struct {
+	CategoryID int64
+	Category   string // poll name
+	Setting    string // setting key
+	Detail     string
+}
+
+func (w Warning) String() string {
+	if w.Setting != "" {
+		return fmt.Sprintf("setting %s: %s", w.Setting, w.Detail)
+	}
+	return fmt.Sprintf("%s (#%d): %s", w.Category, w.CategoryID, w.Detail)
+}
+
+// Lint checks every poll that isn't archived as of now, then the settings.
+// A poll is warned about when it is open with fewer than two options to
+// choose from, ranked and asking for more ranks than it has options, still
+// to close after its planned closing time, or open and closing soon
+// (LintClosingSoon) without a vote, or, closed too, counted by a method no
+// longer registered (see Category.TallyMethod); a setting when its stored
+// value doesn't parse as its kind, as it was changed outside votigo settings
+// or the admin settings page. Poll warnings come first, in poll order. It runs the same
+// four queries however many polls there are, as the dashboard calls it on
+// every load.
+func (q *Queries) Lint(ctx context.Context, now time.Time) ([]Warning, error) {
+	categories, err := q.ListCategoriesExcludeArchived(ctx)
+	if err != nil {
+		return nil, err
+	}
+	optionCounts, err := q.ListCategoryOptionCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	voteStats, err := q.ListCategoryVoteStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	options := make(map[int64]int64, len(optionCounts))
+	for _, row := range optionCounts {
+		options[row.CategoryID] = row.BallotOptions
+	}
+	votes := make(map[int64]int64, len(voteStats))
+	for _, row := range voteStats {
+		votes[row.CategoryID] = row.Votes
+	}
+
+	var warnings []Warning
+	for _, cat := range categories {
+		for _, detail := range lintCategory(cat, options[cat.ID], votes[cat.ID], now) {
+			warnings = append(warnings, Warning{CategoryID: cat.ID, Category: cat.Name, Detail: detail})
+		}
+	}
+
+	settings, err := q.ListSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, setting := range settings {
+		spec, err := LookupSetting(setting.Key)
+		if err != nil {
+			continue // removed by a newer version; SettingValues ignores it too
+		}
+		if _, err := spec.Parse(setting.Value); err != nil {
+			warnings = append(warnings, Warning{Setting: spec.Key, Detail: fmt.Sprintf("stored value %q is not a valid %s", setting.Value, spec.Kind)})
+		}
+	}
+	return warnings, nil
+}
+
+// lintCategory returns what is wrong with one poll, given how many options
+// it has on the ballot and how many votes it has
+func lintCategory(cat Category, options, votes int64, now time.Time) []string {
+	var found []string
+	if _, ok := tally.Methods[cat.Method]; cat.Method != "" && !ok {
+		found = append(found, fmt.Sprintf("counted by %s, which no plugin provides, so it is counted by %s", cat.Method, cat.DefaultMethod()))
+	}
+	if cat.Status == "closed" {
+		return found
+	}
+
+	if cat.Status == "open" && options < 2 {
+		found = append(found, fmt.Sprintf("open with %d option(s) to choose from", options))
+	}
+	if cat.VoteType == "ranked" && cat.MaxRank.Valid && cat.MaxRank.Int64 > options {
+		found = append(found, fmt.Sprintf("asks for %d ranks but has %d option(s)", cat.MaxRank.Int64, options))
+	}
+	if !cat.ClosesAt.Valid {
+		return found
+	}
+	closesIn := cat.ClosesAt.Time.Sub(now)
+	switch {
+	case closesIn < 0:
+		found = append(found, "planned to close at "+cat.ClosesAt.Time.Local().Format("Jan 2 15:04")+", which has passed")
+	case cat.Status == "open" && closesIn <= LintClosingSoon && votes == 0:
+		found = append(found, fmt.Sprintf("closes in %s without a vote", closesIn.Round(time.Minute)))
+	}
+	return found
+}
@@ -0,0 +1,74 @@
+package db
+
+import "strings"
+
+// MatchCategories finds the polls a name refers to, trying in order: the
+// exact name, a name prefix, a substring, then fuzzy matches (letters in
+// order, or a couple of typos). Matching ignores case. Only the polls in the
+// first tier with any match are returned.
+func MatchCategories(categories []Category, ref string) []Category {
+	query := strings.ToLower(strings.TrimSpace(ref))
+	tiers := []func(name string) bool{
+		func(name string) bool { return name == query },
+		func(name string) bool { return strings.HasPrefix(name, query) },
+		func(name string) bool { return strings.Contains(name, query) },
+		func(name string) bool {
+			return isSubsequence(query, name) || levenshtein(query, name) <= min(2, len([]rune(query))/4)
+		},
+	}
+
+	for _, match := range tiers {
+		var found []Category
+		for _, cat := range categories {
+			if match(strings.ToLower(cat.Name)) {
+				found = append(found, cat)
+			}
+		}
+		if len(found) > 0 {
+			return found
+		}
+	}
+	return nil
+}
+
+// isSubsequence reports whether the letters of query appear in s in order,
+// ignoring spaces in query ("bpa" matches "best pixel art")
+func isSubsequence(query, s string) bool {
+	rest := []rune(s)
+	for _, q := range query {
+		if q == ' ' {
+			continue
+		}
+		i := 0
+		for i < len(rest) && rest[i] != q {
+			i++
+		}
+		if i == len(rest) {
+			return false
+		}
+		rest = rest[i+1:]
+	}
+	return true
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
@@ -6,35 +6,203 @@ package db
 
 import (
 	"database/sql"
+	"time"
 )
 
-type Category struct {
-	ID          int64         `json:"id"`
-	Name        string        `json:"name"`
-	VoteType    string        `json:"vote_type"`
-	Status      string        `json:"status"`
-	ShowResults string        `json:"show_results"`
-	MaxRank     sql.NullInt64 `json:"max_rank"`
-	CreatedAt   sql.NullTime  `json:"created_at"`
+type AdminCredential struct {
+	ID        int64        `json:"id"`
+	Salt      []byte       `json:"salt"`
+	Hash      []byte       `json:"hash"`
+	CreatedAt sql.NullTime `json:"created_at"`
 }
 
-type Option struct {
+type Attendee struct {
+	ID       int64        `json:"id"`
+	Nickname string       `json:"nickname"`
+	Seat     string       `json:"seat"`
+	SyncedAt sql.NullTime `json:"synced_at"`
+}
+
+type AttendeeTag struct {
+	AttendeeID int64  `json:"attendee_id"`
+	Tag        string `json:"tag"`
+}
+
+type AuditEvent struct {
 	ID         int64         `json:"id"`
-	CategoryID int64         `json:"category_id"`
-	Name       string        `json:"name"`
-	SortOrder  sql.NullInt64 `json:"sort_order"`
+	Actor      string        `json:"actor"`
+	Action     string        `json:"action"`
+	CategoryID sql.NullInt64 `json:"category_id"`
+	Detail     string        `json:"detail"`
+	CreatedAt  sql.NullTime  `json:"created_at"`
+	StationID  sql.NullInt64 `json:"station_id"`
+	Address    string        `json:"address"`
 }
 
-type Vote struct {
+type AvatarSalt struct {
+	ID   int64  `json:"id"`
+	Salt []byte `json:"salt"`
+}
+
+type BallotPurge struct {
+	CategoryID int64        `json:"category_id"`
+	Votes      int64        `json:"votes"`
+	PurgedAt   sql.NullTime `json:"purged_at"`
+}
+
+type Category struct {
+	ID             int64          `json:"id"`
+	Name           string         `json:"name"`
+	VoteType       string         `json:"vote_type"`
+	Status         string         `json:"status"`
+	ShowResults    string         `json:"show_results"`
+	MaxRank        sql.NullInt64  `json:"max_rank"`
+	CreatedAt      sql.NullTime   `json:"created_at"`
+	Color          string         `json:"color"`
+	Icon           string         `json:"icon"`
+	DependsOn      sql.NullInt64  `json:"depends_on"`
+	SeedTopN       int64          `json:"seed_top_n"`
+	RunoffOf       sql.NullInt64  `json:"runoff_of"`
+	ClosesAt       sql.NullTime   `json:"closes_at"`
+	Slug           sql.NullString `json:"slug"`
+	OpensAt        sql.NullTime   `json:"opens_at"`
+	Skin           string         `json:"skin"`
+	CustomCss      string         `json:"custom_css"`
+	RevealSound    string         `json:"reveal_sound"`
+	VotedWall      string         `json:"voted_wall"`
+	ResultsVersion int64          `json:"results_version"`
+	TestMode       bool           `json:"test_mode"`
+	Eligibility    string         `json:"eligibility"`
+	Weights        string         `json:"weights"`
+	Method         string         `json:"method"`
+	Judges         string         `json:"judges"`
+	JudgeWeight    int64          `json:"judge_weight"`
+}
+
+type EncryptionMeta struct {
 	ID         int64        `json:"id"`
+	Salt       []byte       `json:"salt"`
+	CheckValue string       `json:"check_value"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Key        string       `json:"key"`
 	CategoryID int64        `json:"category_id"`
 	Nickname   string       `json:"nickname"`
 	CreatedAt  sql.NullTime `json:"created_at"`
 }
 
+type MediaPreview struct {
+	Link      string       `json:"link"`
+	Provider  string       `json:"provider"`
+	Title     string       `json:"title"`
+	Author    string       `json:"author"`
+	Thumbnail string       `json:"thumbnail"`
+	FetchedAt sql.NullTime `json:"fetched_at"`
+}
+
+type ObserverKey struct {
+	ID  int64  `json:"id"`
+	Key []byte `json:"key"`
+}
+
+type Option struct {
+	ID          int64         `json:"id"`
+	CategoryID  int64         `json:"category_id"`
+	Name        string        `json:"name"`
+	SortOrder   sql.NullInt64 `json:"sort_order"`
+	RetiredAt   sql.NullTime  `json:"retired_at"`
+	SeededFrom  sql.NullInt64 `json:"seeded_from"`
+	Image       string        `json:"image"`
+	Blurb       string        `json:"blurb"`
+	Video       string        `json:"video"`
+	Screenshots string        `json:"screenshots"`
+}
+
+type ResultSnapshot struct {
+	CategoryID int64  `json:"category_id"`
+	OptionID   int64  `json:"option_id"`
+	Position   int64  `json:"position"`
+	Score      int64  `json:"score"`
+	FirstPlace int64  `json:"first_place"`
+	Label      string `json:"label"`
+}
+
+type SeasonEvent struct {
+	ID          int64        `json:"id"`
+	Name        string       `json:"name"`
+	FinalizedAt sql.NullTime `json:"finalized_at"`
+}
+
+type SeasonPoint struct {
+	EventID    int64  `json:"event_id"`
+	CategoryID int64  `json:"category_id"`
+	Poll       string `json:"poll"`
+	Place      int64  `json:"place"`
+	Name       string `json:"name"`
+	Points     int64  `json:"points"`
+}
+
+type Session struct {
+	ID        string       `json:"id"`
+	Data      string       `json:"data"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type Setting struct {
+	Key       string       `json:"key"`
+	Value     string       `json:"value"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
+type Station struct {
+	ID          int64        `json:"id"`
+	Name        string       `json:"name"`
+	PairingHash []byte       `json:"pairing_hash"`
+	DeviceHash  []byte       `json:"device_hash"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	PairedAt    sql.NullTime `json:"paired_at"`
+	RevokedAt   sql.NullTime `json:"revoked_at"`
+}
+
+type Vote struct {
+	ID           int64        `json:"id"`
+	CategoryID   int64        `json:"category_id"`
+	Nickname     string       `json:"nickname"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+	Version      int64        `json:"version"`
+	Remote       bool         `json:"remote"`
+	Invalid      string       `json:"invalid"`
+	CountedUntil sql.NullTime `json:"counted_until"`
+}
+
+type VoteConflict struct {
+	ID              int64         `json:"id"`
+	VoteID          int64         `json:"vote_id"`
+	Version         int64         `json:"version"`
+	FirstStationID  sql.NullInt64 `json:"first_station_id"`
+	FirstAddress    string        `json:"first_address"`
+	SecondStationID sql.NullInt64 `json:"second_station_id"`
+	SecondAddress   string        `json:"second_address"`
+	CreatedAt       sql.NullTime  `json:"created_at"`
+	ResolvedAt      sql.NullTime  `json:"resolved_at"`
+	Kept            string        `json:"kept"`
+}
+
 type VoteSelection struct {
 	ID       int64         `json:"id"`
 	VoteID   int64         `json:"vote_id"`
 	OptionID int64         `json:"option_id"`
 	Rank     sql.NullInt64 `json:"rank"`
 }
+
+type VoteSelectionHistory struct {
+	ID         int64         `json:"id"`
+	VoteID     int64         `json:"vote_id"`
+	Version    int64         `json:"version"`
+	OptionID   int64         `json:"option_id"`
+	Rank       sql.NullInt64 `json:"rank"`
+	ReplacedAt sql.NullTime  `json:"replaced_at"`
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxObserverLabel keeps observer labels short enough for links and the
+// audit log
+const MaxObserverLabel = 60
+
+// Observer is who an observer link was made for, such as a sponsor or a
+// judge, and when it stops working
+type Observer struct {
+	Label   string
+	Expires time.Time
+}
+
+// observerSignature signs an observer link's payload. It is truncated to
+// 128 bits to keep links short enough for a QR code.
+func observerSignature(key []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)[:16]
+}
+
+// SignObserver returns the token of an observer link for o. Nothing is
+// stored: the token carries o and a signature with the observer key, so it
+// can't be changed or forged, and works until o.Expires or RevokeObservers.
+func (q *Queries) SignObserver(ctx context.Context, o Observer) (string, error) {
+	key, err := q.GetObserverKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	payload := strconv.FormatInt(o.Expires.Unix(), 10) + "." + base64.RawURLEncoding.EncodeToString([]byte(o.Label))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(observerSignature(key, payload)), nil
+}
+
+// ObserverByToken returns who an observer link token was made for. A token
+// that is malformed, wrongly signed, expired as of now or signed before
+// RevokeObservers is ErrNotFound.
+func (q *Queries) ObserverByToken(ctx context.Context, token string, now time.Time) (Observer, error) {
+	var o Observer
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return o, ErrNotFound
+	}
+	payload := token[:i]
+	sig, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil {
+		return o, ErrNotFound
+	}
+	key, err := q.GetObserverKey(ctx)
+	if err != nil {
+		return o, err
+	}
+	if !hmac.Equal(sig, observerSignature(key, payload)) {
+		return o, ErrNotFound
+	}
+
+	expires, label, _ := strings.Cut(payload, ".")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return o, ErrNotFound
+	}
+	name, err := base64.RawURLEncoding.DecodeString(label)
+	if err != nil {
+		return o, ErrNotFound
+	}
+	o = Observer{Label: string(name), Expires: time.Unix(unix, 0)}
+	if !now.Before(o.Expires) {
+		return o, ErrNotFound
+	}
+	return o, nil
+}
+
+// RevokeObservers replaces the observer key, so every observer link made
+// so far stops working
+func (q *Queries) RevokeObservers(ctx context.Context) error {
+	return q.RotateObserverKey(ctx)
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// PasswordHash is the admin password as stored: salted PBKDF2, so a server
+// can be started without the password on its command line and a leaked
+// database doesn't give it away
+type PasswordHash struct {
+	Salt []byte
+	Hash []byte
+}
+
+// HashPassword hashes password with a fresh salt
+func HashPassword(password string) (PasswordHash, error) {
+	salt := make([]byte, encryptionSaltSz)
+	if _, err := rand.Read(salt); err != nil {
+		return PasswordHash{}, err
+	}
+	hash, err := pbkdf2.Key(sha256.New, password, salt, kdfIterations, 32)
+	if err != nil {
+		return PasswordHash{}, err
+	}
+	return PasswordHash{Salt: salt, Hash: hash}, nil
+}
+
+// Matches reports whether password is the one h was made from. It is slow
+// on purpose; callers checking every request should remember a match.
+func (h PasswordHash) Matches(password string) bool {
+	hash, err := pbkdf2.Key(sha256.New, password, h.Salt, kdfIterations, len(h.Hash))
+	return err == nil && subtle.ConstantTimeCompare(hash, h.Hash) == 1
+}
+
+// AdminPassword returns the stored admin password hash. It is ErrNotFound
+// until SetAdminPassword is called.
+func (q *Queries) AdminPassword(ctx context.Context) (PasswordHash, error) {
+	c, err := q.GetAdminCredentials(ctx)
+	if err != nil {
+		return PasswordHash{}, Classify(err)
+	}
+	return PasswordHash{Salt: c.Salt, Hash: c.Hash}, nil
+}
+
+// SetAdminPassword replaces the stored admin password hash. Call it inside
+// a transaction (see InTx) so the old hash isn't lost if the new one fails.
+func (q *Queries) SetAdminPassword(ctx context.Context, h PasswordHash) error {
+	if err := q.DeleteAdminCredentials(ctx); err != nil {
+		return err
+	}
+	return q.CreateAdminCredentials(ctx, CreateAdminCredentialsParams{Salt: h.Salt, Hash: h.Hash})
+}
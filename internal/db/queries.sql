@@ -3,13 +3,19 @@
 -- Category queries
 
 -- name: CreateCategory :one
-INSERT INTO categories (name, vote_type, status, show_results, max_rank)
-VALUES (?, ?, ?, ?, ?)
+INSERT INTO categories (name, vote_type, status, show_results, max_rank, color, icon, depends_on, seed_top_n, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, eligibility, weights, method, judges, judge_weight)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: GetCategory :one
 SELECT * FROM categories WHERE id = ?;
 
+-- name: GetCategoryBySlug :one
+SELECT * FROM categories WHERE slug = ?;
+
+-- name: CountCategoriesWithSlug :one
+SELECT COUNT(*) FROM categories WHERE slug = ? AND id != ?;
+
 -- name: ListCategories :many
 SELECT * FROM categories ORDER BY created_at DESC;
 
@@ -19,20 +25,105 @@ SELECT * FROM categories WHERE status = 'open' ORDER BY created_at DESC;
 -- name: ListCategoriesExcludeArchived :many
 SELECT * FROM categories WHERE status != 'archived' ORDER BY id;
 
+-- name: ListRecentlyClosedCategories :many
+SELECT * FROM categories
+WHERE status = 'closed'
+ORDER BY (
+  SELECT MAX(created_at) FROM audit_events
+  WHERE audit_events.category_id = categories.id AND audit_events.action = 'category.close'
+) DESC, id DESC
+LIMIT ?;
+
+-- name: ListCategoriesClosedBefore :many
+SELECT * FROM categories
+WHERE status = 'closed'
+  AND id IN (
+    SELECT category_id FROM audit_events
+    WHERE action = 'category.close' AND created_at < sqlc.arg(closed_before)
+  )
+  AND id NOT IN (
+    SELECT category_id FROM audit_events
+    WHERE action = 'category.close' AND created_at >= sqlc.arg(closed_before) AND category_id IS NOT NULL
+  )
+ORDER BY id;
+
+-- name: ListCategoriesToPurge :many
+SELECT * FROM categories
+WHERE status IN ('closed', 'archived')
+  AND id NOT IN (SELECT category_id FROM ballot_purges)
+  AND id IN (
+    SELECT category_id FROM audit_events
+    WHERE action = 'category.close' AND created_at < sqlc.arg(closed_before)
+  )
+  AND id NOT IN (
+    SELECT category_id FROM audit_events
+    WHERE action = 'category.close' AND created_at >= sqlc.arg(closed_before) AND category_id IS NOT NULL
+  )
+ORDER BY id;
+
 -- name: ListCategoriesWithResults :many
 SELECT * FROM categories
 WHERE (show_results = 'live' AND status = 'open')
    OR (show_results = 'after_close' AND status = 'closed')
 ORDER BY id;
 
+-- name: ListCategoryVoteCounts :many
+SELECT c.id, c.name, c.vote_type, c.status, COUNT(v.id) AS vote_count
+FROM categories c
+LEFT JOIN votes v ON v.category_id = c.id
+WHERE c.status != 'archived'
+GROUP BY c.id
+ORDER BY c.id;
+
+-- name: ListCategoryVoteStats :many
+SELECT c.id AS category_id, COUNT(v.id) AS votes, CAST(COALESCE(SUM(v.remote), 0) AS INTEGER) AS remote_votes
+FROM categories c
+LEFT JOIN votes v ON v.category_id = c.id
+GROUP BY c.id
+ORDER BY c.id;
+
+-- name: ListCategoryOptionCounts :many
+SELECT c.id AS category_id, COUNT(o.id) AS ballot_options
+FROM categories c
+LEFT JOIN options o ON o.category_id = c.id AND o.retired_at IS NULL
+GROUP BY c.id
+ORDER BY c.id;
+
+-- name: ListLatestVoteEvents :many
+SELECT * FROM audit_events
+WHERE id IN (
+  SELECT MAX(id) FROM audit_events
+  WHERE action = 'vote' AND category_id IS NOT NULL
+  GROUP BY category_id
+)
+ORDER BY category_id;
+
 -- name: ArchiveCategory :exec
 UPDATE categories SET status = 'archived' WHERE id = ?;
 
 -- name: UpdateCategoryStatus :exec
 UPDATE categories SET status = ? WHERE id = ?;
 
+-- name: SetCategoryTestMode :exec
+UPDATE categories SET test_mode = ? WHERE id = ?;
+
+-- name: DeleteVotesByCategory :execrows
+DELETE FROM votes WHERE category_id = ?;
+
+-- name: DeleteIdempotencyKeysByCategory :exec
+DELETE FROM idempotency_keys WHERE category_id = ?;
+
 -- name: UpdateCategory :exec
-UPDATE categories SET name = ?, vote_type = ?, show_results = ?, max_rank = ? WHERE id = ?;
+UPDATE categories SET name = ?, vote_type = ?, show_results = ?, max_rank = ?, color = ?, icon = ?, depends_on = ?, seed_top_n = ?, closes_at = ?, slug = ?, opens_at = ?, skin = ?, custom_css = ?, reveal_sound = ?, voted_wall = ?, eligibility = ?, weights = ?, method = ?, judges = ?, judge_weight = ? WHERE id = ?;
+
+-- name: ListDependentCategories :many
+SELECT * FROM categories WHERE depends_on = ? ORDER BY id;
+
+-- name: SetCategoryRunoffOf :exec
+UPDATE categories SET runoff_of = ? WHERE id = ?;
+
+-- name: GetRunoff :one
+SELECT * FROM categories WHERE runoff_of = ? ORDER BY id DESC LIMIT 1;
 
 -- name: DeleteCategory :exec
 DELETE FROM categories WHERE id = ?;
@@ -44,29 +135,85 @@ INSERT INTO options (category_id, name, sort_order)
 VALUES (?, ?, ?)
 RETURNING *;
 
+-- name: CreateSeededOption :one
+INSERT INTO options (category_id, name, sort_order, seeded_from, image)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (category_id, name COLLATE NOCASE) DO NOTHING
+RETURNING *;
+
 -- name: GetOption :one
 SELECT * FROM options WHERE id = ?;
 
 -- name: ListOptionsByCategory :many
 SELECT * FROM options WHERE category_id = ? ORDER BY sort_order, id;
 
+-- name: ListBallotOptionsByCategory :many
+SELECT * FROM options WHERE category_id = ? AND retired_at IS NULL ORDER BY sort_order, id;
+
+-- name: ListOptionVotesByCategory :many
+SELECT o.id, o.category_id, o.name, o.sort_order, o.retired_at, o.seeded_from, o.image,
+       sc.name AS seeded_from_poll, COUNT(vs.id) AS votes
+FROM options o
+LEFT JOIN vote_selections vs ON vs.option_id = o.id
+LEFT JOIN options so ON so.id = o.seeded_from
+LEFT JOIN categories sc ON sc.id = so.category_id
+WHERE o.category_id = ?
+GROUP BY o.id
+ORDER BY o.sort_order, o.id;
+
 -- name: DeleteOption :exec
 DELETE FROM options WHERE id = ?;
 
+-- name: RetireOption :exec
+UPDATE options SET retired_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: SetOptionDetails :exec
+UPDATE options SET blurb = ?, video = ?, screenshots = ? WHERE id = ?;
+
+-- name: SetOptionImage :exec
+UPDATE options SET image = ? WHERE id = ?;
+
+-- name: CountOptionsByImage :one
+SELECT COUNT(*) FROM options WHERE image = ?;
+
 -- name: CountOptionsByCategory :one
 SELECT COUNT(*) FROM options WHERE category_id = ?;
 
+-- name: CountBallotOptionsByCategory :one
+SELECT COUNT(*) FROM options WHERE category_id = ? AND retired_at IS NULL;
+
+-- name: CountSelectionsByOption :one
+SELECT COUNT(*) FROM vote_selections WHERE option_id = ?;
+
 -- Vote queries
 
 -- name: UpsertVote :one
-INSERT INTO votes (category_id, nickname)
-VALUES (?, ?)
-ON CONFLICT(category_id, nickname) DO UPDATE SET created_at = CURRENT_TIMESTAMP
+INSERT INTO votes (category_id, nickname, remote)
+VALUES (?, ?, ?)
+ON CONFLICT(category_id, nickname) DO UPDATE SET created_at = CURRENT_TIMESTAMP, version = version + 1, remote = excluded.remote
 RETURNING *;
 
+-- name: GetVote :one
+SELECT * FROM votes WHERE id = ?;
+
 -- name: GetVoteByNickname :one
 SELECT * FROM votes WHERE category_id = ? AND nickname = ?;
 
+-- name: ListVotesByCategory :many
+SELECT * FROM votes WHERE category_id = ? ORDER BY id;
+
+-- name: ListParticipation :many
+SELECT v.nickname, v.category_id, v.created_at FROM votes v
+JOIN categories c ON c.id = v.category_id
+WHERE c.status != 'draft'
+ORDER BY v.nickname, v.category_id;
+
+-- name: ListStartedCategoryIDs :many
+SELECT id FROM categories WHERE status != 'draft' ORDER BY id;
+
+-- name: ListSelectionsByVote :many
+SELECT option_id, rank FROM vote_selections WHERE vote_id = ? ORDER BY rank, option_id;
+
 -- name: DeleteVoteSelections :exec
 DELETE FROM vote_selections WHERE vote_id = ?;
 
@@ -74,16 +221,141 @@ DELETE FROM vote_selections WHERE vote_id = ?;
 INSERT INTO vote_selections (vote_id, option_id, rank)
 VALUES (?, ?, ?);
 
+-- Ballots purged under the retention policy still count
 -- name: CountVotesByCategory :one
-SELECT COUNT(*) FROM votes WHERE category_id = ?;
+SELECT CAST(COUNT(votes.id) + COALESCE(MAX(ballot_purges.votes), 0) AS INTEGER) AS count
+FROM categories
+LEFT JOIN votes ON votes.category_id = categories.id AND votes.invalid = ''
+LEFT JOIN ballot_purges ON ballot_purges.category_id = categories.id
+WHERE categories.id = sqlc.arg(category_id);
+
+-- name: ListVoteOrigins :many
+SELECT v.id, v.nickname, v.created_at, v.invalid, v.counted_until,
+       CAST(COALESCE((
+         SELECT a.address FROM audit_events a
+         WHERE a.action = 'vote' AND a.category_id = v.category_id AND a.actor = v.nickname
+         ORDER BY a.id DESC LIMIT 1
+       ), '') AS TEXT) AS address
+FROM votes v
+WHERE v.category_id = ?
+ORDER BY v.created_at, v.id;
+
+-- name: ListVoteTimesByCategory :many
+SELECT actor, created_at FROM audit_events
+WHERE action = 'vote' AND category_id = ?
+ORDER BY id;
+
+-- name: SetVoteInvalid :exec
+UPDATE votes SET invalid = ? WHERE id = ?;
+
+-- name: SetVoteCountedUntil :exec
+UPDATE votes SET counted_until = ? WHERE id = ?;
 
 -- name: ListVotersByCategory :many
 SELECT nickname FROM votes WHERE category_id = ? ORDER BY created_at;
 
+-- Vote history queries
+
+-- name: ArchiveVoteSelections :exec
+INSERT INTO vote_selection_history (vote_id, version, option_id, rank)
+SELECT vs.vote_id, v.version - 1, vs.option_id, vs.rank
+FROM vote_selections vs
+JOIN votes v ON v.id = vs.vote_id
+WHERE vs.vote_id = ?;
+
+-- name: ListVoteHistoryByCategory :many
+SELECT v.nickname, h.version, h.rank, o.name AS option_name, h.replaced_at
+FROM vote_selection_history h
+JOIN votes v ON v.id = h.vote_id
+JOIN options o ON o.id = h.option_id
+WHERE v.category_id = ?
+ORDER BY v.nickname, h.version, h.rank, o.sort_order, o.id;
+
+-- name: GetVoteChurn :one
+SELECT COUNT(*) AS voters,
+       COUNT(CASE WHEN version > 1 THEN 1 END) AS changed_voters,
+       CAST(COALESCE(SUM(version - 1), 0) AS INTEGER) AS changes
+FROM votes
+WHERE category_id = ?;
+
+-- name: PurgeVoteHistoryByCategory :execrows
+DELETE FROM vote_selection_history
+WHERE vote_id IN (SELECT id FROM votes WHERE category_id = ?);
+
+-- name: ResetVoteVersionsByCategory :exec
+UPDATE votes SET version = 1 WHERE category_id = ?;
+
+-- Vote conflict queries
+
+-- name: GetLastVoteOrigin :one
+SELECT station_id, address, created_at FROM audit_events
+WHERE action = 'vote' AND category_id = ? AND actor = ?
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: CreateVoteConflict :exec
+INSERT INTO vote_conflicts (vote_id, version, first_station_id, first_address, second_station_id, second_address)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetVoteConflict :one
+SELECT * FROM vote_conflicts WHERE id = ?;
+
+-- name: ListOpenVoteConflicts :many
+SELECT c.id, c.vote_id, c.version, v.nickname, v.version AS current_version, c.created_at,
+       c.first_address, CAST(COALESCE(fs.name, '') AS TEXT) AS first_station,
+       c.second_address, CAST(COALESCE(ss.name, '') AS TEXT) AS second_station
+FROM vote_conflicts c
+JOIN votes v ON v.id = c.vote_id
+LEFT JOIN stations fs ON fs.id = c.first_station_id
+LEFT JOIN stations ss ON ss.id = c.second_station_id
+WHERE v.category_id = ? AND c.resolved_at IS NULL
+ORDER BY c.id;
+
+-- name: ResolveVoteConflict :exec
+UPDATE vote_conflicts SET resolved_at = CURRENT_TIMESTAMP, kept = ? WHERE id = ?;
+
+-- name: DeleteVoteConflictsByCategory :exec
+DELETE FROM vote_conflicts
+WHERE vote_id IN (SELECT id FROM votes WHERE category_id = ?);
+
+-- name: ListVoteChoices :many
+SELECT o.name FROM vote_selections vs
+JOIN options o ON o.id = vs.option_id
+WHERE vs.vote_id = ?
+ORDER BY vs.rank, o.sort_order, o.id;
+
+-- name: ListVoteVersionChoices :many
+SELECT o.name FROM vote_selection_history h
+JOIN options o ON o.id = h.option_id
+WHERE h.vote_id = ? AND h.version = ?
+ORDER BY h.rank, o.sort_order, o.id;
+
+-- name: BumpVoteVersion :exec
+UPDATE votes SET version = version + 1 WHERE id = ?;
+
+-- name: RestoreVoteSelections :exec
+INSERT INTO vote_selections (vote_id, option_id, rank)
+SELECT vote_id, option_id, rank FROM vote_selection_history
+WHERE vote_id = ? AND version = ?;
+
 -- Tally queries
 
+-- name: ListSelectionsByCategory :many
+SELECT vs.vote_id, vs.option_id, vs.rank
+FROM vote_selections vs
+JOIN votes v ON v.id = vs.vote_id
+WHERE v.category_id = ? AND v.invalid = ''
+ORDER BY vs.vote_id, vs.rank, vs.id;
+
+-- name: ListSelectionHistoryByCategory :many
+SELECT h.vote_id, h.version, h.option_id, h.rank, h.replaced_at
+FROM vote_selection_history h
+JOIN votes v ON v.id = h.vote_id
+WHERE v.category_id = ? AND v.invalid = ''
+ORDER BY h.vote_id, h.version, h.rank, h.id;
+
 -- name: TallySimple :many
-SELECT o.id, o.name, COUNT(vs.id) as votes
+SELECT o.id, o.name, o.image, COUNT(vs.id) as votes
 FROM options o
 LEFT JOIN vote_selections vs ON vs.option_id = o.id
 WHERE o.category_id = sqlc.arg(category_id)
@@ -91,7 +363,7 @@ GROUP BY o.id
 ORDER BY votes DESC, o.sort_order, o.id;
 
 -- name: TallyRanked :many
-SELECT o.id, o.name,
+SELECT o.id, o.name, o.image,
        COALESCE(SUM(sqlc.arg(max_rank) - vs.rank + 1), 0) as points,
        COUNT(CASE WHEN vs.rank = 1 THEN 1 END) as first_place_votes
 FROM options o
@@ -99,3 +371,309 @@ LEFT JOIN vote_selections vs ON vs.option_id = o.id
 WHERE o.category_id = sqlc.arg(category_id)
 GROUP BY o.id
 ORDER BY points DESC, first_place_votes DESC, o.sort_order, o.id;
+
+-- Audit queries
+
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (actor, action, category_id, detail, station_id, address)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ListRecentAdminActions :many
+SELECT a.id, a.actor, a.action, a.category_id, a.detail, a.created_at, c.name AS category_name
+FROM audit_events a
+LEFT JOIN categories c ON c.id = a.category_id
+WHERE a.action != 'vote'
+ORDER BY a.id DESC
+LIMIT ?;
+
+-- name: ListCategoryStatusHistory :many
+SELECT * FROM audit_events
+WHERE category_id = ?
+  AND action IN ('category.create', 'category.open', 'category.close', 'category.reopen', 'category.archive')
+ORDER BY id;
+
+-- name: ListVotesPerMinute :many
+SELECT CAST(strftime('%H:%M', created_at) AS TEXT) AS minute, COUNT(*) AS votes
+FROM audit_events
+WHERE action = 'vote' AND created_at >= datetime('now', '-10 minutes')
+GROUP BY minute
+ORDER BY MIN(id);
+
+-- name: ListBallotOrigins :many
+SELECT a.created_at, a.station_id, CAST(COALESCE(s.name, '') AS TEXT) AS station_name, a.address
+FROM audit_events a
+LEFT JOIN stations s ON s.id = a.station_id
+WHERE a.action = 'vote' AND a.created_at IS NOT NULL
+ORDER BY a.created_at, a.id;
+
+-- Idempotency queries
+
+-- name: ClaimIdempotencyKey :execrows
+INSERT INTO idempotency_keys (key, category_id, nickname)
+VALUES (?, ?, ?)
+ON CONFLICT(key) DO NOTHING;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys WHERE key = ?;
+
+-- name: DeleteIdempotencyKeysBefore :execrows
+DELETE FROM idempotency_keys WHERE created_at < ?;
+
+-- Voter queries
+
+-- name: DeleteVoteSelectionsByNickname :exec
+DELETE FROM vote_selections
+WHERE vote_id IN (SELECT v.id FROM votes v JOIN categories c ON c.id = v.category_id WHERE v.nickname = ? AND c.status != 'archived');
+
+-- name: DeleteVoteHistoryByNickname :exec
+DELETE FROM vote_selection_history
+WHERE vote_id IN (SELECT v.id FROM votes v JOIN categories c ON c.id = v.category_id WHERE v.nickname = ? AND c.status != 'archived');
+
+-- name: DeleteVotesByNickname :execrows
+DELETE FROM votes
+WHERE nickname = ? AND category_id NOT IN (SELECT id FROM categories WHERE status = 'archived');
+
+-- name: AnonymizeArchivedVotes :execrows
+UPDATE votes SET nickname = sqlc.arg(replacement) || ' #' || id
+WHERE nickname = sqlc.arg(nickname)
+  AND category_id IN (SELECT id FROM categories WHERE status = 'archived');
+
+-- name: DeleteIdempotencyKeysByNickname :exec
+DELETE FROM idempotency_keys WHERE nickname = ?;
+
+-- name: AnonymizeVoteAuditEvents :execrows
+UPDATE audit_events SET actor = sqlc.arg(replacement)
+WHERE actor = sqlc.arg(actor) AND action = 'vote';
+
+-- Retention queries
+
+-- name: CreateBallotPurge :exec
+INSERT INTO ballot_purges (category_id, votes) VALUES (?, ?);
+
+-- name: GetBallotPurge :one
+SELECT * FROM ballot_purges WHERE category_id = ?;
+
+-- name: CreateResultSnapshot :exec
+INSERT INTO result_snapshots (category_id, option_id, position, score, first_place, label)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ListResultSnapshot :many
+SELECT option_id, score, first_place, label FROM result_snapshots
+WHERE category_id = ?
+ORDER BY position;
+
+-- name: AnonymizeVoteAuditEventsByCategory :execrows
+UPDATE audit_events SET actor = sqlc.arg(replacement)
+WHERE category_id = sqlc.arg(category_id) AND action = 'vote';
+
+-- Encryption queries
+
+-- name: GetEncryptionMeta :one
+SELECT * FROM encryption_meta WHERE id = 1;
+
+-- name: CreateEncryptionMeta :exec
+INSERT INTO encryption_meta (salt, check_value)
+VALUES (?, ?);
+
+-- name: ListVoteNicknames :many
+SELECT nickname FROM votes GROUP BY nickname;
+
+-- name: ListVoteAuditActors :many
+SELECT actor FROM audit_events WHERE action = 'vote' GROUP BY actor;
+
+-- name: RenameVoter :exec
+UPDATE votes SET nickname = sqlc.arg(new_nickname) WHERE nickname = sqlc.arg(old_nickname);
+
+-- name: RenameAttendee :exec
+UPDATE attendees SET nickname = sqlc.arg(new_nickname) WHERE nickname = sqlc.arg(old_nickname);
+
+-- name: DeleteAllIdempotencyKeys :exec
+DELETE FROM idempotency_keys;
+
+-- Avatar queries
+
+-- name: GetAvatarSalt :one
+SELECT salt FROM avatar_salt WHERE id = 1;
+
+-- Observer queries
+
+-- name: GetObserverKey :one
+SELECT key FROM observer_key WHERE id = 1;
+
+-- name: RotateObserverKey :exec
+UPDATE observer_key SET key = randomblob(32) WHERE id = 1;
+
+-- Media preview queries
+
+-- name: GetMediaPreview :one
+SELECT * FROM media_previews WHERE link = ?;
+
+-- name: UpsertMediaPreview :exec
+INSERT INTO media_previews (link, provider, title, author, thumbnail)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(link) DO UPDATE SET provider = excluded.provider, title = excluded.title,
+  author = excluded.author, thumbnail = excluded.thumbnail, fetched_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteMediaPreview :exec
+DELETE FROM media_previews WHERE link = ?;
+
+-- Season queries
+
+-- name: CreateSeasonEvent :one
+INSERT INTO season_events (name) VALUES (?) RETURNING *;
+
+-- name: ListSeasonEvents :many
+SELECT * FROM season_events ORDER BY id;
+
+-- name: DeleteSeasonEvent :execrows
+DELETE FROM season_events WHERE id = ?;
+
+-- name: CreateSeasonPoints :exec
+INSERT INTO season_points (event_id, category_id, poll, place, name, points)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ListSeasonPoints :many
+SELECT * FROM season_points ORDER BY event_id, category_id, place;
+
+-- name: ListUnscoredCategories :many
+SELECT * FROM categories
+WHERE status IN ('closed', 'archived')
+  AND id IN (SELECT category_id FROM options)
+  AND id NOT IN (SELECT category_id FROM season_points)
+  AND id NOT IN (SELECT runoff_of FROM categories WHERE runoff_of IS NOT NULL)
+ORDER BY id;
+
+-- Admin password queries
+
+-- name: GetAdminCredentials :one
+SELECT * FROM admin_credentials WHERE id = 1;
+
+-- name: CreateAdminCredentials :exec
+INSERT INTO admin_credentials (salt, hash)
+VALUES (?, ?);
+
+-- name: DeleteAdminCredentials :exec
+DELETE FROM admin_credentials;
+
+-- Station queries
+
+-- name: CreateStation :one
+INSERT INTO stations (name, pairing_hash)
+VALUES (?, ?)
+RETURNING *;
+
+-- name: ListStations :many
+SELECT * FROM stations ORDER BY name;
+
+-- name: GetStation :one
+SELECT * FROM stations WHERE id = ?;
+
+-- name: GetStationByPairingHash :one
+SELECT * FROM stations WHERE pairing_hash = ?;
+
+-- name: GetStationByDeviceHash :one
+SELECT * FROM stations WHERE device_hash = ?;
+
+-- name: PairStation :exec
+UPDATE stations SET pairing_hash = NULL, device_hash = ?, paired_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: ResetStationPairing :exec
+UPDATE stations SET pairing_hash = ?, device_hash = NULL, paired_at = NULL, revoked_at = NULL
+WHERE id = ?;
+
+-- name: RevokeStation :execrows
+UPDATE stations SET pairing_hash = NULL, revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND revoked_at IS NULL;
+
+-- Settings queries
+
+-- name: GetSetting :one
+SELECT * FROM settings WHERE key = ?;
+
+-- name: ListSettings :many
+SELECT * FROM settings ORDER BY key;
+
+-- name: UpsertSetting :exec
+INSERT INTO settings (key, value)
+VALUES (?, ?)
+ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteSetting :exec
+DELETE FROM settings WHERE key = ?;
+
+-- Session queries
+
+-- name: GetSession :one
+SELECT * FROM sessions WHERE id = ? AND expires_at > ?;
+
+-- name: UpsertSession :exec
+INSERT INTO sessions (id, data, expires_at)
+VALUES (?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET data = excluded.data, expires_at = excluded.expires_at;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions WHERE id = ?;
+
+-- name: DeleteSessionsExpiredBefore :execrows
+DELETE FROM sessions WHERE expires_at <= ?;
+
+-- Roster queries
+
+-- name: ListAttendees :many
+SELECT * FROM attendees ORDER BY seat, nickname;
+
+-- name: UpsertAttendee :exec
+INSERT INTO attendees (nickname, seat)
+VALUES (?, ?)
+ON CONFLICT(nickname) DO UPDATE SET seat = excluded.seat, synced_at = CURRENT_TIMESTAMP;
+
+-- name: ListSeatTurnout :many
+SELECT a.nickname, a.seat, COUNT(v.id) AS votes
+FROM attendees a
+LEFT JOIN votes v ON v.nickname = a.nickname AND v.category_id = ?
+GROUP BY a.id
+ORDER BY a.seat, a.nickname;
+
+-- name: DeleteAttendee :exec
+DELETE FROM attendees WHERE nickname = ?;
+
+-- name: GetAttendee :one
+SELECT * FROM attendees WHERE id = ?;
+
+-- name: ListAttendeeTags :many
+SELECT attendee_id, tag FROM attendee_tags ORDER BY attendee_id, tag;
+
+-- name: ListTagsByNickname :many
+SELECT t.tag FROM attendee_tags t
+JOIN attendees a ON a.id = t.attendee_id
+WHERE a.nickname = ?
+ORDER BY t.tag;
+
+-- name: GetAttendeeByNickname :one
+SELECT * FROM attendees WHERE nickname = ?;
+
+-- name: ListVoteTagsByCategory :many
+SELECT v.id AS vote_id, t.tag FROM votes v
+JOIN attendees a ON a.nickname = v.nickname
+JOIN attendee_tags t ON t.attendee_id = a.id
+WHERE v.category_id = ?
+ORDER BY v.id, t.tag;
+
+-- name: ListTagTurnout :many
+SELECT t.tag, CAST(COUNT(*) AS INTEGER) AS attendees, CAST(COUNT(v.id) AS INTEGER) AS voted
+FROM attendee_tags t
+JOIN attendees a ON a.id = t.attendee_id
+LEFT JOIN votes v ON v.nickname = a.nickname AND v.category_id = sqlc.arg(category_id)
+GROUP BY t.tag
+ORDER BY t.tag;
+
+-- name: CountOffRosterVotes :one
+SELECT CAST(COUNT(*) AS INTEGER) AS count FROM votes
+WHERE category_id = ? AND nickname NOT IN (SELECT nickname FROM attendees);
+
+-- name: DeleteAttendeeTags :exec
+DELETE FROM attendee_tags WHERE attendee_id = ?;
+
+-- name: AddAttendeeTag :exec
+INSERT INTO attendee_tags (attendee_id, tag) VALUES (?, ?);
@@ -8,8 +8,78 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const addAttendeeTag = `-- name: AddAttendeeTag :exec
+INSERT INTO attendee_tags (attendee_id, tag) VALUES (?, ?)
+`
+
+type AddAttendeeTagParams struct {
+	AttendeeID int64  `json:"attendee_id"`
+	Tag        string `json:"tag"`
+}
+
+func (q *Queries) AddAttendeeTag(ctx context.Context, arg AddAttendeeTagParams) error {
+	_, err := q.db.ExecContext(ctx, addAttendeeTag, arg.AttendeeID, arg.Tag)
+	return err
+}
+
+const anonymizeArchivedVotes = `-- name: AnonymizeArchivedVotes :execrows
+UPDATE votes SET nickname = ?1 || ' #' || id
+WHERE nickname = ?2
+  AND category_id IN (SELECT id FROM categories WHERE status = 'archived')
+`
+
+type AnonymizeArchivedVotesParams struct {
+	Replacement string `json:"replacement"`
+	Nickname    string `json:"nickname"`
+}
+
+func (q *Queries) AnonymizeArchivedVotes(ctx context.Context, arg AnonymizeArchivedVotesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, anonymizeArchivedVotes, arg.Replacement, arg.Nickname)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const anonymizeVoteAuditEvents = `-- name: AnonymizeVoteAuditEvents :execrows
+UPDATE audit_events SET actor = ?1
+WHERE actor = ?2 AND action = 'vote'
+`
+
+type AnonymizeVoteAuditEventsParams struct {
+	Replacement string `json:"replacement"`
+	Actor       string `json:"actor"`
+}
+
+func (q *Queries) AnonymizeVoteAuditEvents(ctx context.Context, arg AnonymizeVoteAuditEventsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, anonymizeVoteAuditEvents, arg.Replacement, arg.Actor)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const anonymizeVoteAuditEventsByCategory = `-- name: AnonymizeVoteAuditEventsByCategory :execrows
+UPDATE audit_events SET actor = ?1
+WHERE category_id = ?2 AND action = 'vote'
+`
+
+type AnonymizeVoteAuditEventsByCategoryParams struct {
+	Replacement string        `json:"replacement"`
+	CategoryID  sql.NullInt64 `json:"category_id"`
+}
+
+func (q *Queries) AnonymizeVoteAuditEventsByCategory(ctx context.Context, arg AnonymizeVoteAuditEventsByCategoryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, anonymizeVoteAuditEventsByCategory, arg.Replacement, arg.CategoryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const archiveCategory = `-- name: ArchiveCategory :exec
 UPDATE categories SET status = 'archived' WHERE id = ?
 `
@@ -19,6 +89,91 @@ func (q *Queries) ArchiveCategory(ctx context.Context, id int64) error {
 	return err
 }
 
+const archiveVoteSelections = `-- name: ArchiveVoteSelections :exec
+
+INSERT INTO vote_selection_history (vote_id, version, option_id, rank)
+SELECT vs.vote_id, v.version - 1, vs.option_id, vs.rank
+FROM vote_selections vs
+JOIN votes v ON v.id = vs.vote_id
+WHERE vs.vote_id = ?
+`
+
+// Vote history queries
+func (q *Queries) ArchiveVoteSelections(ctx context.Context, voteID int64) error {
+	_, err := q.db.ExecContext(ctx, archiveVoteSelections, voteID)
+	return err
+}
+
+const bumpVoteVersion = `-- name: BumpVoteVersion :exec
+UPDATE votes SET version = version + 1 WHERE id = ?
+`
+
+func (q *Queries) BumpVoteVersion(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, bumpVoteVersion, id)
+	return err
+}
+
+const claimIdempotencyKey = `-- name: ClaimIdempotencyKey :execrows
+
+INSERT INTO idempotency_keys (key, category_id, nickname)
+VALUES (?, ?, ?)
+ON CONFLICT(key) DO NOTHING
+`
+
+type ClaimIdempotencyKeyParams struct {
+	Key        string `json:"key"`
+	CategoryID int64  `json:"category_id"`
+	Nickname   string `json:"nickname"`
+}
+
+// Idempotency queries
+func (q *Queries) ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimIdempotencyKey, arg.Key, arg.CategoryID, arg.Nickname)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countBallotOptionsByCategory = `-- name: CountBallotOptionsByCategory :one
+SELECT COUNT(*) FROM options WHERE category_id = ? AND retired_at IS NULL
+`
+
+func (q *Queries) CountBallotOptionsByCategory(ctx context.Context, categoryID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countBallotOptionsByCategory, categoryID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countCategoriesWithSlug = `-- name: CountCategoriesWithSlug :one
+SELECT COUNT(*) FROM categories WHERE slug = ? AND id != ?
+`
+
+type CountCategoriesWithSlugParams struct {
+	Slug sql.NullString `json:"slug"`
+	ID   int64          `json:"id"`
+}
+
+func (q *Queries) CountCategoriesWithSlug(ctx context.Context, arg CountCategoriesWithSlugParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCategoriesWithSlug, arg.Slug, arg.ID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countOffRosterVotes = `-- name: CountOffRosterVotes :one
+SELECT CAST(COUNT(*) AS INTEGER) AS count FROM votes
+WHERE category_id = ? AND nickname NOT IN (SELECT nickname FROM attendees)
+`
+
+func (q *Queries) CountOffRosterVotes(ctx context.Context, categoryID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOffRosterVotes, categoryID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countOptionsByCategory = `-- name: CountOptionsByCategory :one
 SELECT COUNT(*) FROM options WHERE category_id = ?
 `
@@ -30,10 +185,38 @@ func (q *Queries) CountOptionsByCategory(ctx context.Context, categoryID int64)
 	return count, err
 }
 
+const countOptionsByImage = `-- name: CountOptionsByImage :one
+SELECT COUNT(*) FROM options WHERE image = ?
+`
+
+func (q *Queries) CountOptionsByImage(ctx context.Context, image string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOptionsByImage, image)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSelectionsByOption = `-- name: CountSelectionsByOption :one
+SELECT COUNT(*) FROM vote_selections WHERE option_id = ?
+`
+
+func (q *Queries) CountSelectionsByOption(ctx context.Context, optionID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSelectionsByOption, optionID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countVotesByCategory = `-- name: CountVotesByCategory :one
-SELECT COUNT(*) FROM votes WHERE category_id = ?
+
+SELECT CAST(COUNT(votes.id) + COALESCE(MAX(ballot_purges.votes), 0) AS INTEGER) AS count
+FROM categories
+LEFT JOIN votes ON votes.category_id = categories.id AND votes.invalid = ''
+LEFT JOIN ballot_purges ON ballot_purges.category_id = categories.id
+WHERE categories.id = ?1
 `
 
+// Ballots purged under the retention policy still count
 func (q *Queries) CountVotesByCategory(ctx context.Context, categoryID int64) (int64, error) {
 	row := q.db.QueryRowContext(ctx, countVotesByCategory, categoryID)
 	var count int64
@@ -41,20 +224,95 @@ func (q *Queries) CountVotesByCategory(ctx context.Context, categoryID int64) (i
 	return count, err
 }
 
+const createAdminCredentials = `-- name: CreateAdminCredentials :exec
+INSERT INTO admin_credentials (salt, hash)
+VALUES (?, ?)
+`
+
+type CreateAdminCredentialsParams struct {
+	Salt []byte `json:"salt"`
+	Hash []byte `json:"hash"`
+}
+
+func (q *Queries) CreateAdminCredentials(ctx context.Context, arg CreateAdminCredentialsParams) error {
+	_, err := q.db.ExecContext(ctx, createAdminCredentials, arg.Salt, arg.Hash)
+	return err
+}
+
+const createAuditEvent = `-- name: CreateAuditEvent :exec
+
+INSERT INTO audit_events (actor, action, category_id, detail, station_id, address)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateAuditEventParams struct {
+	Actor      string        `json:"actor"`
+	Action     string        `json:"action"`
+	CategoryID sql.NullInt64 `json:"category_id"`
+	Detail     string        `json:"detail"`
+	StationID  sql.NullInt64 `json:"station_id"`
+	Address    string        `json:"address"`
+}
+
+// Audit queries
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error {
+	_, err := q.db.ExecContext(ctx, createAuditEvent,
+		arg.Actor,
+		arg.Action,
+		arg.CategoryID,
+		arg.Detail,
+		arg.StationID,
+		arg.Address,
+	)
+	return err
+}
+
+const createBallotPurge = `-- name: CreateBallotPurge :exec
+
+INSERT INTO ballot_purges (category_id, votes) VALUES (?, ?)
+`
+
+type CreateBallotPurgeParams struct {
+	CategoryID int64 `json:"category_id"`
+	Votes      int64 `json:"votes"`
+}
+
+// Retention queries
+func (q *Queries) CreateBallotPurge(ctx context.Context, arg CreateBallotPurgeParams) error {
+	_, err := q.db.ExecContext(ctx, createBallotPurge, arg.CategoryID, arg.Votes)
+	return err
+}
+
 const createCategory = `-- name: CreateCategory :one
 
 
-INSERT INTO categories (name, vote_type, status, show_results, max_rank)
-VALUES (?, ?, ?, ?, ?)
-RETURNING id, name, vote_type, status, show_results, max_rank, created_at
+INSERT INTO categories (name, vote_type, status, show_results, max_rank, color, icon, depends_on, seed_top_n, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, eligibility, weights, method, judges, judge_weight)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight
 `
 
 type CreateCategoryParams struct {
-	Name        string        `json:"name"`
-	VoteType    string        `json:"vote_type"`
-	Status      string        `json:"status"`
-	ShowResults string        `json:"show_results"`
-	MaxRank     sql.NullInt64 `json:"max_rank"`
+	Name        string         `json:"name"`
+	VoteType    string         `json:"vote_type"`
+	Status      string         `json:"status"`
+	ShowResults string         `json:"show_results"`
+	MaxRank     sql.NullInt64  `json:"max_rank"`
+	Color       string         `json:"color"`
+	Icon        string         `json:"icon"`
+	DependsOn   sql.NullInt64  `json:"depends_on"`
+	SeedTopN    int64          `json:"seed_top_n"`
+	ClosesAt    sql.NullTime   `json:"closes_at"`
+	Slug        sql.NullString `json:"slug"`
+	OpensAt     sql.NullTime   `json:"opens_at"`
+	Skin        string         `json:"skin"`
+	CustomCss   string         `json:"custom_css"`
+	RevealSound string         `json:"reveal_sound"`
+	VotedWall   string         `json:"voted_wall"`
+	Eligibility string         `json:"eligibility"`
+	Weights     string         `json:"weights"`
+	Method      string         `json:"method"`
+	Judges      string         `json:"judges"`
+	JudgeWeight int64          `json:"judge_weight"`
 }
 
 // Queries for sqlc code generation
@@ -66,6 +324,22 @@ func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams)
 		arg.Status,
 		arg.ShowResults,
 		arg.MaxRank,
+		arg.Color,
+		arg.Icon,
+		arg.DependsOn,
+		arg.SeedTopN,
+		arg.ClosesAt,
+		arg.Slug,
+		arg.OpensAt,
+		arg.Skin,
+		arg.CustomCss,
+		arg.RevealSound,
+		arg.VotedWall,
+		arg.Eligibility,
+		arg.Weights,
+		arg.Method,
+		arg.Judges,
+		arg.JudgeWeight,
 	)
 	var i Category
 	err := row.Scan(
@@ -76,15 +350,49 @@ func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams)
 		&i.ShowResults,
 		&i.MaxRank,
 		&i.CreatedAt,
+		&i.Color,
+		&i.Icon,
+		&i.DependsOn,
+		&i.SeedTopN,
+		&i.RunoffOf,
+		&i.ClosesAt,
+		&i.Slug,
+		&i.OpensAt,
+		&i.Skin,
+		&i.CustomCss,
+		&i.RevealSound,
+		&i.VotedWall,
+		&i.ResultsVersion,
+		&i.TestMode,
+		&i.Eligibility,
+		&i.Weights,
+		&i.Method,
+		&i.Judges,
+		&i.JudgeWeight,
 	)
 	return i, err
 }
 
+const createEncryptionMeta = `-- name: CreateEncryptionMeta :exec
+INSERT INTO encryption_meta (salt, check_value)
+VALUES (?, ?)
+`
+
+type CreateEncryptionMetaParams struct {
+	Salt       []byte `json:"salt"`
+	CheckValue string `json:"check_value"`
+}
+
+func (q *Queries) CreateEncryptionMeta(ctx context.Context, arg CreateEncryptionMetaParams) error {
+	_, err := q.db.ExecContext(ctx, createEncryptionMeta, arg.Salt, arg.CheckValue)
+	return err
+}
+
 const createOption = `-- name: CreateOption :one
 
 INSERT INTO options (category_id, name, sort_order)
 VALUES (?, ?, ?)
-RETURNING id, category_id, name, sort_order
+RETURNING id, category_id, name, sort_order, retired_at, seeded_from, image, blurb, video, screenshots
 `
 
 type CreateOptionParams struct {
@@ -102,10 +410,174 @@ func (q *Queries) CreateOption(ctx context.Context, arg CreateOptionParams) (Opt
 		&i.CategoryID,
 		&i.Name,
 		&i.SortOrder,
+		&i.RetiredAt,
+		&i.SeededFrom,
+		&i.Image,
+		&i.Blurb,
+		&i.Video,
+		&i.Screenshots,
+	)
+	return i, err
+}
+
+const createResultSnapshot = `-- name: CreateResultSnapshot :exec
+INSERT INTO result_snapshots (category_id, option_id, position, score, first_place, label)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateResultSnapshotParams struct {
+	CategoryID int64  `json:"category_id"`
+	OptionID   int64  `json:"option_id"`
+	Position   int64  `json:"position"`
+	Score      int64  `json:"score"`
+	FirstPlace int64  `json:"first_place"`
+	Label      string `json:"label"`
+}
+
+func (q *Queries) CreateResultSnapshot(ctx context.Context, arg CreateResultSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, createResultSnapshot,
+		arg.CategoryID,
+		arg.OptionID,
+		arg.Position,
+		arg.Score,
+		arg.FirstPlace,
+		arg.Label,
+	)
+	return err
+}
+
+const createSeasonEvent = `-- name: CreateSeasonEvent :one
+
+INSERT INTO season_events (name) VALUES (?) RETURNING id, name, finalized_at
+`
+
+// Season queries
+func (q *Queries) CreateSeasonEvent(ctx context.Context, name string) (SeasonEvent, error) {
+	row := q.db.QueryRowContext(ctx, createSeasonEvent, name)
+	var i SeasonEvent
+	err := row.Scan(&i.ID, &i.Name, &i.FinalizedAt)
+	return i, err
+}
+
+const createSeasonPoints = `-- name: CreateSeasonPoints :exec
+INSERT INTO season_points (event_id, category_id, poll, place, name, points)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateSeasonPointsParams struct {
+	EventID    int64  `json:"event_id"`
+	CategoryID int64  `json:"category_id"`
+	Poll       string `json:"poll"`
+	Place      int64  `json:"place"`
+	Name       string `json:"name"`
+	Points     int64  `json:"points"`
+}
+
+func (q *Queries) CreateSeasonPoints(ctx context.Context, arg CreateSeasonPointsParams) error {
+	_, err := q.db.ExecContext(ctx, createSeasonPoints,
+		arg.EventID,
+		arg.CategoryID,
+		arg.Poll,
+		arg.Place,
+		arg.Name,
+		arg.Points,
+	)
+	return err
+}
+
+const createSeededOption = `-- name: CreateSeededOption :one
+INSERT INTO options (category_id, name, sort_order, seeded_from, image)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (category_id, name COLLATE NOCASE) DO NOTHING
+RETURNING id, category_id, name, sort_order, retired_at, seeded_from, image, blurb, video, screenshots
+`
+
+type CreateSeededOptionParams struct {
+	CategoryID int64         `json:"category_id"`
+	Name       string        `json:"name"`
+	SortOrder  sql.NullInt64 `json:"sort_order"`
+	SeededFrom sql.NullInt64 `json:"seeded_from"`
+	Image      string        `json:"image"`
+}
+
+func (q *Queries) CreateSeededOption(ctx context.Context, arg CreateSeededOptionParams) (Option, error) {
+	row := q.db.QueryRowContext(ctx, createSeededOption,
+		arg.CategoryID,
+		arg.Name,
+		arg.SortOrder,
+		arg.SeededFrom,
+		arg.Image,
+	)
+	var i Option
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Name,
+		&i.SortOrder,
+		&i.RetiredAt,
+		&i.SeededFrom,
+		&i.Image,
+		&i.Blurb,
+		&i.Video,
+		&i.Screenshots,
+	)
+	return i, err
+}
+
+const createStation = `-- name: CreateStation :one
+
+INSERT INTO stations (name, pairing_hash)
+VALUES (?, ?)
+RETURNING id, name, pairing_hash, device_hash, created_at, paired_at, revoked_at
+`
+
+type CreateStationParams struct {
+	Name        string `json:"name"`
+	PairingHash []byte `json:"pairing_hash"`
+}
+
+// Station queries
+func (q *Queries) CreateStation(ctx context.Context, arg CreateStationParams) (Station, error) {
+	row := q.db.QueryRowContext(ctx, createStation, arg.Name, arg.PairingHash)
+	var i Station
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.PairingHash,
+		&i.DeviceHash,
+		&i.CreatedAt,
+		&i.PairedAt,
+		&i.RevokedAt,
 	)
 	return i, err
 }
 
+const createVoteConflict = `-- name: CreateVoteConflict :exec
+INSERT INTO vote_conflicts (vote_id, version, first_station_id, first_address, second_station_id, second_address)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateVoteConflictParams struct {
+	VoteID          int64         `json:"vote_id"`
+	Version         int64         `json:"version"`
+	FirstStationID  sql.NullInt64 `json:"first_station_id"`
+	FirstAddress    string        `json:"first_address"`
+	SecondStationID sql.NullInt64 `json:"second_station_id"`
+	SecondAddress   string        `json:"second_address"`
+}
+
+func (q *Queries) CreateVoteConflict(ctx context.Context, arg CreateVoteConflictParams) error {
+	_, err := q.db.ExecContext(ctx, createVoteConflict,
+		arg.VoteID,
+		arg.Version,
+		arg.FirstStationID,
+		arg.FirstAddress,
+		arg.SecondStationID,
+		arg.SecondAddress,
+	)
+	return err
+}
+
 const createVoteSelection = `-- name: CreateVoteSelection :exec
 INSERT INTO vote_selections (vote_id, option_id, rank)
 VALUES (?, ?, ?)
@@ -122,6 +594,42 @@ func (q *Queries) CreateVoteSelection(ctx context.Context, arg CreateVoteSelecti
 	return err
 }
 
+const deleteAdminCredentials = `-- name: DeleteAdminCredentials :exec
+DELETE FROM admin_credentials
+`
+
+func (q *Queries) DeleteAdminCredentials(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAdminCredentials)
+	return err
+}
+
+const deleteAllIdempotencyKeys = `-- name: DeleteAllIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+`
+
+func (q *Queries) DeleteAllIdempotencyKeys(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllIdempotencyKeys)
+	return err
+}
+
+const deleteAttendee = `-- name: DeleteAttendee :exec
+DELETE FROM attendees WHERE nickname = ?
+`
+
+func (q *Queries) DeleteAttendee(ctx context.Context, nickname string) error {
+	_, err := q.db.ExecContext(ctx, deleteAttendee, nickname)
+	return err
+}
+
+const deleteAttendeeTags = `-- name: DeleteAttendeeTags :exec
+DELETE FROM attendee_tags WHERE attendee_id = ?
+`
+
+func (q *Queries) DeleteAttendeeTags(ctx context.Context, attendeeID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAttendeeTags, attendeeID)
+	return err
+}
+
 const deleteCategory = `-- name: DeleteCategory :exec
 DELETE FROM categories WHERE id = ?
 `
@@ -131,73 +639,339 @@ func (q *Queries) DeleteCategory(ctx context.Context, id int64) error {
 	return err
 }
 
-const deleteOption = `-- name: DeleteOption :exec
-DELETE FROM options WHERE id = ?
+const deleteIdempotencyKeysBefore = `-- name: DeleteIdempotencyKeysBefore :execrows
+DELETE FROM idempotency_keys WHERE created_at < ?
 `
 
-func (q *Queries) DeleteOption(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteOption, id)
-	return err
+func (q *Queries) DeleteIdempotencyKeysBefore(ctx context.Context, createdAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteIdempotencyKeysBefore, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const deleteVoteSelections = `-- name: DeleteVoteSelections :exec
-DELETE FROM vote_selections WHERE vote_id = ?
+const deleteIdempotencyKeysByCategory = `-- name: DeleteIdempotencyKeysByCategory :exec
+DELETE FROM idempotency_keys WHERE category_id = ?
 `
 
-func (q *Queries) DeleteVoteSelections(ctx context.Context, voteID int64) error {
-	_, err := q.db.ExecContext(ctx, deleteVoteSelections, voteID)
+func (q *Queries) DeleteIdempotencyKeysByCategory(ctx context.Context, categoryID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKeysByCategory, categoryID)
 	return err
 }
 
-const getCategory = `-- name: GetCategory :one
-SELECT id, name, vote_type, status, show_results, max_rank, created_at FROM categories WHERE id = ?
+const deleteIdempotencyKeysByNickname = `-- name: DeleteIdempotencyKeysByNickname :exec
+DELETE FROM idempotency_keys WHERE nickname = ?
 `
 
-func (q *Queries) GetCategory(ctx context.Context, id int64) (Category, error) {
-	row := q.db.QueryRowContext(ctx, getCategory, id)
-	var i Category
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.VoteType,
-		&i.Status,
-		&i.ShowResults,
-		&i.MaxRank,
-		&i.CreatedAt,
-	)
-	return i, err
+func (q *Queries) DeleteIdempotencyKeysByNickname(ctx context.Context, nickname string) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKeysByNickname, nickname)
+	return err
 }
 
-const getOption = `-- name: GetOption :one
-SELECT id, category_id, name, sort_order FROM options WHERE id = ?
+const deleteMediaPreview = `-- name: DeleteMediaPreview :exec
+DELETE FROM media_previews WHERE link = ?
 `
 
-func (q *Queries) GetOption(ctx context.Context, id int64) (Option, error) {
-	row := q.db.QueryRowContext(ctx, getOption, id)
-	var i Option
-	err := row.Scan(
-		&i.ID,
-		&i.CategoryID,
-		&i.Name,
-		&i.SortOrder,
-	)
-	return i, err
+func (q *Queries) DeleteMediaPreview(ctx context.Context, link string) error {
+	_, err := q.db.ExecContext(ctx, deleteMediaPreview, link)
+	return err
 }
 
-const getVoteByNickname = `-- name: GetVoteByNickname :one
-SELECT id, category_id, nickname, created_at FROM votes WHERE category_id = ? AND nickname = ?
+const deleteOption = `-- name: DeleteOption :exec
+DELETE FROM options WHERE id = ?
 `
 
-type GetVoteByNicknameParams struct {
-	CategoryID int64  `json:"category_id"`
-	Nickname   string `json:"nickname"`
+func (q *Queries) DeleteOption(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteOption, id)
+	return err
 }
 
-func (q *Queries) GetVoteByNickname(ctx context.Context, arg GetVoteByNicknameParams) (Vote, error) {
-	row := q.db.QueryRowContext(ctx, getVoteByNickname, arg.CategoryID, arg.Nickname)
-	var i Vote
+const deleteSeasonEvent = `-- name: DeleteSeasonEvent :execrows
+DELETE FROM season_events WHERE id = ?
+`
+
+func (q *Queries) DeleteSeasonEvent(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSeasonEvent, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteSession = `-- name: DeleteSession :exec
+DELETE FROM sessions WHERE id = ?
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteSession, id)
+	return err
+}
+
+const deleteSessionsExpiredBefore = `-- name: DeleteSessionsExpiredBefore :execrows
+DELETE FROM sessions WHERE expires_at <= ?
+`
+
+func (q *Queries) DeleteSessionsExpiredBefore(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSessionsExpiredBefore, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteSetting = `-- name: DeleteSetting :exec
+DELETE FROM settings WHERE key = ?
+`
+
+func (q *Queries) DeleteSetting(ctx context.Context, key string) error {
+	_, err := q.db.ExecContext(ctx, deleteSetting, key)
+	return err
+}
+
+const deleteVoteConflictsByCategory = `-- name: DeleteVoteConflictsByCategory :exec
+DELETE FROM vote_conflicts
+WHERE vote_id IN (SELECT id FROM votes WHERE category_id = ?)
+`
+
+func (q *Queries) DeleteVoteConflictsByCategory(ctx context.Context, categoryID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteVoteConflictsByCategory, categoryID)
+	return err
+}
+
+const deleteVoteHistoryByNickname = `-- name: DeleteVoteHistoryByNickname :exec
+DELETE FROM vote_selection_history
+WHERE vote_id IN (SELECT v.id FROM votes v JOIN categories c ON c.id = v.category_id WHERE v.nickname = ? AND c.status != 'archived')
+`
+
+func (q *Queries) DeleteVoteHistoryByNickname(ctx context.Context, nickname string) error {
+	_, err := q.db.ExecContext(ctx, deleteVoteHistoryByNickname, nickname)
+	return err
+}
+
+const deleteVoteSelections = `-- name: DeleteVoteSelections :exec
+DELETE FROM vote_selections WHERE vote_id = ?
+`
+
+func (q *Queries) DeleteVoteSelections(ctx context.Context, voteID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteVoteSelections, voteID)
+	return err
+}
+
+const deleteVoteSelectionsByNickname = `-- name: DeleteVoteSelectionsByNickname :exec
+
+DELETE FROM vote_selections
+WHERE vote_id IN (SELECT v.id FROM votes v JOIN categories c ON c.id = v.category_id WHERE v.nickname = ? AND c.status != 'archived')
+`
+
+// Voter queries
+func (q *Queries) DeleteVoteSelectionsByNickname(ctx context.Context, nickname string) error {
+	_, err := q.db.ExecContext(ctx, deleteVoteSelectionsByNickname, nickname)
+	return err
+}
+
+const deleteVotesByCategory = `-- name: DeleteVotesByCategory :execrows
+DELETE FROM votes WHERE category_id = ?
+`
+
+func (q *Queries) DeleteVotesByCategory(ctx context.Context, categoryID int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteVotesByCategory, categoryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteVotesByNickname = `-- name: DeleteVotesByNickname :execrows
+DELETE FROM votes
+WHERE nickname = ? AND category_id NOT IN (SELECT id FROM categories WHERE status = 'archived')
+`
+
+func (q *Queries) DeleteVotesByNickname(ctx context.Context, nickname string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteVotesByNickname, nickname)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getAdminCredentials = `-- name: GetAdminCredentials :one
+
+SELECT id, salt, hash, created_at FROM admin_credentials WHERE id = 1
+`
+
+// Admin password queries
+func (q *Queries) GetAdminCredentials(ctx context.Context) (AdminCredential, error) {
+	row := q.db.QueryRowContext(ctx, getAdminCredentials)
+	var i AdminCredential
+	err := row.Scan(
+		&i.ID,
+		&i.Salt,
+		&i.Hash,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAttendee = `-- name: GetAttendee :one
+SELECT id, nickname, seat, synced_at FROM attendees WHERE id = ?
+`
+
+func (q *Queries) GetAttendee(ctx context.Context, id int64) (Attendee, error) {
+	row := q.db.QueryRowContext(ctx, getAttendee, id)
+	var i Attendee
+	err := row.Scan(
+		&i.ID,
+		&i.Nickname,
+		&i.Seat,
+		&i.SyncedAt,
+	)
+	return i, err
+}
+
+const getAttendeeByNickname = `-- name: GetAttendeeByNickname :one
+SELECT id, nickname, seat, synced_at FROM attendees WHERE nickname = ?
+`
+
+func (q *Queries) GetAttendeeByNickname(ctx context.Context, nickname string) (Attendee, error) {
+	row := q.db.QueryRowContext(ctx, getAttendeeByNickname, nickname)
+	var i Attendee
+	err := row.Scan(
+		&i.ID,
+		&i.Nickname,
+		&i.Seat,
+		&i.SyncedAt,
+	)
+	return i, err
+}
+
+const getAvatarSalt = `-- name: GetAvatarSalt :one
+
+SELECT salt FROM avatar_salt WHERE id = 1
+`
+
+// Avatar queries
+func (q *Queries) GetAvatarSalt(ctx context.Context) ([]byte, error) {
+	row := q.db.QueryRowContext(ctx, getAvatarSalt)
+	var salt []byte
+	err := row.Scan(&salt)
+	return salt, err
+}
+
+const getBallotPurge = `-- name: GetBallotPurge :one
+SELECT category_id, votes, purged_at FROM ballot_purges WHERE category_id = ?
+`
+
+func (q *Queries) GetBallotPurge(ctx context.Context, categoryID int64) (BallotPurge, error) {
+	row := q.db.QueryRowContext(ctx, getBallotPurge, categoryID)
+	var i BallotPurge
+	err := row.Scan(&i.CategoryID, &i.Votes, &i.PurgedAt)
+	return i, err
+}
+
+const getCategory = `-- name: GetCategory :one
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories WHERE id = ?
+`
+
+func (q *Queries) GetCategory(ctx context.Context, id int64) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategory, id)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.VoteType,
+		&i.Status,
+		&i.ShowResults,
+		&i.MaxRank,
+		&i.CreatedAt,
+		&i.Color,
+		&i.Icon,
+		&i.DependsOn,
+		&i.SeedTopN,
+		&i.RunoffOf,
+		&i.ClosesAt,
+		&i.Slug,
+		&i.OpensAt,
+		&i.Skin,
+		&i.CustomCss,
+		&i.RevealSound,
+		&i.VotedWall,
+		&i.ResultsVersion,
+		&i.TestMode,
+		&i.Eligibility,
+		&i.Weights,
+		&i.Method,
+		&i.Judges,
+		&i.JudgeWeight,
+	)
+	return i, err
+}
+
+const getCategoryBySlug = `-- name: GetCategoryBySlug :one
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories WHERE slug = ?
+`
+
+func (q *Queries) GetCategoryBySlug(ctx context.Context, slug sql.NullString) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategoryBySlug, slug)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.VoteType,
+		&i.Status,
+		&i.ShowResults,
+		&i.MaxRank,
+		&i.CreatedAt,
+		&i.Color,
+		&i.Icon,
+		&i.DependsOn,
+		&i.SeedTopN,
+		&i.RunoffOf,
+		&i.ClosesAt,
+		&i.Slug,
+		&i.OpensAt,
+		&i.Skin,
+		&i.CustomCss,
+		&i.RevealSound,
+		&i.VotedWall,
+		&i.ResultsVersion,
+		&i.TestMode,
+		&i.Eligibility,
+		&i.Weights,
+		&i.Method,
+		&i.Judges,
+		&i.JudgeWeight,
+	)
+	return i, err
+}
+
+const getEncryptionMeta = `-- name: GetEncryptionMeta :one
+
+SELECT id, salt, check_value, created_at FROM encryption_meta WHERE id = 1
+`
+
+// Encryption queries
+func (q *Queries) GetEncryptionMeta(ctx context.Context) (EncryptionMeta, error) {
+	row := q.db.QueryRowContext(ctx, getEncryptionMeta)
+	var i EncryptionMeta
 	err := row.Scan(
 		&i.ID,
+		&i.Salt,
+		&i.CheckValue,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT key, category_id, nickname, created_at FROM idempotency_keys WHERE key = ?
+`
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKey, key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
 		&i.CategoryID,
 		&i.Nickname,
 		&i.CreatedAt,
@@ -205,28 +979,2026 @@ func (q *Queries) GetVoteByNickname(ctx context.Context, arg GetVoteByNicknamePa
 	return i, err
 }
 
-const listCategories = `-- name: ListCategories :many
-SELECT id, name, vote_type, status, show_results, max_rank, created_at FROM categories ORDER BY created_at DESC
+const getLastVoteOrigin = `-- name: GetLastVoteOrigin :one
+
+SELECT station_id, address, created_at FROM audit_events
+WHERE action = 'vote' AND category_id = ? AND actor = ?
+ORDER BY id DESC
+LIMIT 1
+`
+
+type GetLastVoteOriginParams struct {
+	CategoryID sql.NullInt64 `json:"category_id"`
+	Actor      string        `json:"actor"`
+}
+
+type GetLastVoteOriginRow struct {
+	StationID sql.NullInt64 `json:"station_id"`
+	Address   string        `json:"address"`
+	CreatedAt sql.NullTime  `json:"created_at"`
+}
+
+// Vote conflict queries
+func (q *Queries) GetLastVoteOrigin(ctx context.Context, arg GetLastVoteOriginParams) (GetLastVoteOriginRow, error) {
+	row := q.db.QueryRowContext(ctx, getLastVoteOrigin, arg.CategoryID, arg.Actor)
+	var i GetLastVoteOriginRow
+	err := row.Scan(&i.StationID, &i.Address, &i.CreatedAt)
+	return i, err
+}
+
+const getMediaPreview = `-- name: GetMediaPreview :one
+
+SELECT link, provider, title, author, thumbnail, fetched_at FROM media_previews WHERE link = ?
+`
+
+// Media preview queries
+func (q *Queries) GetMediaPreview(ctx context.Context, link string) (MediaPreview, error) {
+	row := q.db.QueryRowContext(ctx, getMediaPreview, link)
+	var i MediaPreview
+	err := row.Scan(
+		&i.Link,
+		&i.Provider,
+		&i.Title,
+		&i.Author,
+		&i.Thumbnail,
+		&i.FetchedAt,
+	)
+	return i, err
+}
+
+const getObserverKey = `-- name: GetObserverKey :one
+
+SELECT key FROM observer_key WHERE id = 1
+`
+
+// Observer queries
+func (q *Queries) GetObserverKey(ctx context.Context) ([]byte, error) {
+	row := q.db.QueryRowContext(ctx, getObserverKey)
+	var key []byte
+	err := row.Scan(&key)
+	return key, err
+}
+
+const getOption = `-- name: GetOption :one
+SELECT id, category_id, name, sort_order, retired_at, seeded_from, image, blurb, video, screenshots FROM options WHERE id = ?
+`
+
+func (q *Queries) GetOption(ctx context.Context, id int64) (Option, error) {
+	row := q.db.QueryRowContext(ctx, getOption, id)
+	var i Option
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Name,
+		&i.SortOrder,
+		&i.RetiredAt,
+		&i.SeededFrom,
+		&i.Image,
+		&i.Blurb,
+		&i.Video,
+		&i.Screenshots,
+	)
+	return i, err
+}
+
+const getRunoff = `-- name: GetRunoff :one
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories WHERE runoff_of = ? ORDER BY id DESC LIMIT 1
+`
+
+func (q *Queries) GetRunoff(ctx context.Context, runoffOf sql.NullInt64) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getRunoff, runoffOf)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.VoteType,
+		&i.Status,
+		&i.ShowResults,
+		&i.MaxRank,
+		&i.CreatedAt,
+		&i.Color,
+		&i.Icon,
+		&i.DependsOn,
+		&i.SeedTopN,
+		&i.RunoffOf,
+		&i.ClosesAt,
+		&i.Slug,
+		&i.OpensAt,
+		&i.Skin,
+		&i.CustomCss,
+		&i.RevealSound,
+		&i.VotedWall,
+		&i.ResultsVersion,
+		&i.TestMode,
+		&i.Eligibility,
+		&i.Weights,
+		&i.Method,
+		&i.Judges,
+		&i.JudgeWeight,
+	)
+	return i, err
+}
+
+const getSession = `-- name: GetSession :one
+
+SELECT id, data, expires_at, created_at FROM sessions WHERE id = ? AND expires_at > ?
+`
+
+type GetSessionParams struct {
+	ID        string    `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Session queries
+func (q *Queries) GetSession(ctx context.Context, arg GetSessionParams) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSession, arg.ID, arg.ExpiresAt)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Data,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSetting = `-- name: GetSetting :one
+
+SELECT key, value, updated_at FROM settings WHERE key = ?
+`
+
+// Settings queries
+func (q *Queries) GetSetting(ctx context.Context, key string) (Setting, error) {
+	row := q.db.QueryRowContext(ctx, getSetting, key)
+	var i Setting
+	err := row.Scan(&i.Key, &i.Value, &i.UpdatedAt)
+	return i, err
+}
+
+const getStation = `-- name: GetStation :one
+SELECT id, name, pairing_hash, device_hash, created_at, paired_at, revoked_at FROM stations WHERE id = ?
+`
+
+func (q *Queries) GetStation(ctx context.Context, id int64) (Station, error) {
+	row := q.db.QueryRowContext(ctx, getStation, id)
+	var i Station
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.PairingHash,
+		&i.DeviceHash,
+		&i.CreatedAt,
+		&i.PairedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getStationByDeviceHash = `-- name: GetStationByDeviceHash :one
+SELECT id, name, pairing_hash, device_hash, created_at, paired_at, revoked_at FROM stations WHERE device_hash = ?
+`
+
+func (q *Queries) GetStationByDeviceHash(ctx context.Context, deviceHash []byte) (Station, error) {
+	row := q.db.QueryRowContext(ctx, getStationByDeviceHash, deviceHash)
+	var i Station
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.PairingHash,
+		&i.DeviceHash,
+		&i.CreatedAt,
+		&i.PairedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getStationByPairingHash = `-- name: GetStationByPairingHash :one
+SELECT id, name, pairing_hash, device_hash, created_at, paired_at, revoked_at FROM stations WHERE pairing_hash = ?
+`
+
+func (q *Queries) GetStationByPairingHash(ctx context.Context, pairingHash []byte) (Station, error) {
+	row := q.db.QueryRowContext(ctx, getStationByPairingHash, pairingHash)
+	var i Station
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.PairingHash,
+		&i.DeviceHash,
+		&i.CreatedAt,
+		&i.PairedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getVote = `-- name: GetVote :one
+SELECT id, category_id, nickname, created_at, version, remote, invalid, counted_until FROM votes WHERE id = ?
+`
+
+func (q *Queries) GetVote(ctx context.Context, id int64) (Vote, error) {
+	row := q.db.QueryRowContext(ctx, getVote, id)
+	var i Vote
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Nickname,
+		&i.CreatedAt,
+		&i.Version,
+		&i.Remote,
+		&i.Invalid,
+		&i.CountedUntil,
+	)
+	return i, err
+}
+
+const getVoteByNickname = `-- name: GetVoteByNickname :one
+SELECT id, category_id, nickname, created_at, version, remote, invalid, counted_until FROM votes WHERE category_id = ? AND nickname = ?
+`
+
+type GetVoteByNicknameParams struct {
+	CategoryID int64  `json:"category_id"`
+	Nickname   string `json:"nickname"`
+}
+
+func (q *Queries) GetVoteByNickname(ctx context.Context, arg GetVoteByNicknameParams) (Vote, error) {
+	row := q.db.QueryRowContext(ctx, getVoteByNickname, arg.CategoryID, arg.Nickname)
+	var i Vote
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Nickname,
+		&i.CreatedAt,
+		&i.Version,
+		&i.Remote,
+		&i.Invalid,
+		&i.CountedUntil,
+	)
+	return i, err
+}
+
+const getVoteChurn = `-- name: GetVoteChurn :one
+SELECT COUNT(*) AS voters,
+       COUNT(CASE WHEN version > 1 THEN 1 END) AS changed_voters,
+       CAST(COALESCE(SUM(version - 1), 0) AS INTEGER) AS changes
+FROM votes
+WHERE category_id = ?
+`
+
+type GetVoteChurnRow struct {
+	Voters        int64 `json:"voters"`
+	ChangedVoters int64 `json:"changed_voters"`
+	Changes       int64 `json:"changes"`
+}
+
+func (q *Queries) GetVoteChurn(ctx context.Context, categoryID int64) (GetVoteChurnRow, error) {
+	row := q.db.QueryRowContext(ctx, getVoteChurn, categoryID)
+	var i GetVoteChurnRow
+	err := row.Scan(&i.Voters, &i.ChangedVoters, &i.Changes)
+	return i, err
+}
+
+const getVoteConflict = `-- name: GetVoteConflict :one
+SELECT id, vote_id, version, first_station_id, first_address, second_station_id, second_address, created_at, resolved_at, kept FROM vote_conflicts WHERE id = ?
+`
+
+func (q *Queries) GetVoteConflict(ctx context.Context, id int64) (VoteConflict, error) {
+	row := q.db.QueryRowContext(ctx, getVoteConflict, id)
+	var i VoteConflict
+	err := row.Scan(
+		&i.ID,
+		&i.VoteID,
+		&i.Version,
+		&i.FirstStationID,
+		&i.FirstAddress,
+		&i.SecondStationID,
+		&i.SecondAddress,
+		&i.CreatedAt,
+		&i.ResolvedAt,
+		&i.Kept,
+	)
+	return i, err
+}
+
+const listAttendeeTags = `-- name: ListAttendeeTags :many
+SELECT attendee_id, tag FROM attendee_tags ORDER BY attendee_id, tag
+`
+
+func (q *Queries) ListAttendeeTags(ctx context.Context) ([]AttendeeTag, error) {
+	rows, err := q.db.QueryContext(ctx, listAttendeeTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AttendeeTag{}
+	for rows.Next() {
+		var i AttendeeTag
+		if err := rows.Scan(&i.AttendeeID, &i.Tag); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAttendees = `-- name: ListAttendees :many
+
+SELECT id, nickname, seat, synced_at FROM attendees ORDER BY seat, nickname
+`
+
+// Roster queries
+func (q *Queries) ListAttendees(ctx context.Context) ([]Attendee, error) {
+	rows, err := q.db.QueryContext(ctx, listAttendees)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Attendee{}
+	for rows.Next() {
+		var i Attendee
+		if err := rows.Scan(
+			&i.ID,
+			&i.Nickname,
+			&i.Seat,
+			&i.SyncedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBallotOptionsByCategory = `-- name: ListBallotOptionsByCategory :many
+SELECT id, category_id, name, sort_order, retired_at, seeded_from, image, blurb, video, screenshots FROM options WHERE category_id = ? AND retired_at IS NULL ORDER BY sort_order, id
+`
+
+func (q *Queries) ListBallotOptionsByCategory(ctx context.Context, categoryID int64) ([]Option, error) {
+	rows, err := q.db.QueryContext(ctx, listBallotOptionsByCategory, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Option{}
+	for rows.Next() {
+		var i Option
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Name,
+			&i.SortOrder,
+			&i.RetiredAt,
+			&i.SeededFrom,
+			&i.Image,
+			&i.Blurb,
+			&i.Video,
+			&i.Screenshots,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBallotOrigins = `-- name: ListBallotOrigins :many
+SELECT a.created_at, a.station_id, CAST(COALESCE(s.name, '') AS TEXT) AS station_name, a.address
+FROM audit_events a
+LEFT JOIN stations s ON s.id = a.station_id
+WHERE a.action = 'vote' AND a.created_at IS NOT NULL
+ORDER BY a.created_at, a.id
+`
+
+type ListBallotOriginsRow struct {
+	CreatedAt   sql.NullTime  `json:"created_at"`
+	StationID   sql.NullInt64 `json:"station_id"`
+	StationName string        `json:"station_name"`
+	Address     string        `json:"address"`
+}
+
+func (q *Queries) ListBallotOrigins(ctx context.Context) ([]ListBallotOriginsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBallotOrigins)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListBallotOriginsRow{}
+	for rows.Next() {
+		var i ListBallotOriginsRow
+		if err := rows.Scan(
+			&i.CreatedAt,
+			&i.StationID,
+			&i.StationName,
+			&i.Address,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategories = `-- name: ListCategories :many
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories ORDER BY created_at DESC
+`
+
+func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategories)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.VoteType,
+			&i.Status,
+			&i.ShowResults,
+			&i.MaxRank,
+			&i.CreatedAt,
+			&i.Color,
+			&i.Icon,
+			&i.DependsOn,
+			&i.SeedTopN,
+			&i.RunoffOf,
+			&i.ClosesAt,
+			&i.Slug,
+			&i.OpensAt,
+			&i.Skin,
+			&i.CustomCss,
+			&i.RevealSound,
+			&i.VotedWall,
+			&i.ResultsVersion,
+			&i.TestMode,
+			&i.Eligibility,
+			&i.Weights,
+			&i.Method,
+			&i.Judges,
+			&i.JudgeWeight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoriesClosedBefore = `-- name: ListCategoriesClosedBefore :many
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories
+WHERE status = 'closed'
+  AND id IN (
+    SELECT category_id FROM audit_events
+    WHERE action = 'category.close' AND created_at < ?1
+  )
+  AND id NOT IN (
+    SELECT category_id FROM audit_events
+    WHERE action = 'category.close' AND created_at >= ?1 AND category_id IS NOT NULL
+  )
+ORDER BY id
+`
+
+func (q *Queries) ListCategoriesClosedBefore(ctx context.Context, closedBefore sql.NullTime) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategoriesClosedBefore, closedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.VoteType,
+			&i.Status,
+			&i.ShowResults,
+			&i.MaxRank,
+			&i.CreatedAt,
+			&i.Color,
+			&i.Icon,
+			&i.DependsOn,
+			&i.SeedTopN,
+			&i.RunoffOf,
+			&i.ClosesAt,
+			&i.Slug,
+			&i.OpensAt,
+			&i.Skin,
+			&i.CustomCss,
+			&i.RevealSound,
+			&i.VotedWall,
+			&i.ResultsVersion,
+			&i.TestMode,
+			&i.Eligibility,
+			&i.Weights,
+			&i.Method,
+			&i.Judges,
+			&i.JudgeWeight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoriesExcludeArchived = `-- name: ListCategoriesExcludeArchived :many
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories WHERE status != 'archived' ORDER BY id
+`
+
+func (q *Queries) ListCategoriesExcludeArchived(ctx context.Context) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategoriesExcludeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.VoteType,
+			&i.Status,
+			&i.ShowResults,
+			&i.MaxRank,
+			&i.CreatedAt,
+			&i.Color,
+			&i.Icon,
+			&i.DependsOn,
+			&i.SeedTopN,
+			&i.RunoffOf,
+			&i.ClosesAt,
+			&i.Slug,
+			&i.OpensAt,
+			&i.Skin,
+			&i.CustomCss,
+			&i.RevealSound,
+			&i.VotedWall,
+			&i.ResultsVersion,
+			&i.TestMode,
+			&i.Eligibility,
+			&i.Weights,
+			&i.Method,
+			&i.Judges,
+			&i.JudgeWeight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoriesToPurge = `-- name: ListCategoriesToPurge :many
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories
+WHERE status IN ('closed', 'archived')
+  AND id NOT IN (SELECT category_id FROM ballot_purges)
+  AND id IN (
+    SELECT category_id FROM audit_events
+    WHERE action = 'category.close' AND created_at < ?1
+  )
+  AND id NOT IN (
+    SELECT category_id FROM audit_events
+    WHERE action = 'category.close' AND created_at >= ?1 AND category_id IS NOT NULL
+  )
+ORDER BY id
+`
+
+func (q *Queries) ListCategoriesToPurge(ctx context.Context, closedBefore sql.NullTime) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategoriesToPurge, closedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.VoteType,
+			&i.Status,
+			&i.ShowResults,
+			&i.MaxRank,
+			&i.CreatedAt,
+			&i.Color,
+			&i.Icon,
+			&i.DependsOn,
+			&i.SeedTopN,
+			&i.RunoffOf,
+			&i.ClosesAt,
+			&i.Slug,
+			&i.OpensAt,
+			&i.Skin,
+			&i.CustomCss,
+			&i.RevealSound,
+			&i.VotedWall,
+			&i.ResultsVersion,
+			&i.TestMode,
+			&i.Eligibility,
+			&i.Weights,
+			&i.Method,
+			&i.Judges,
+			&i.JudgeWeight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoriesWithResults = `-- name: ListCategoriesWithResults :many
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories
+WHERE (show_results = 'live' AND status = 'open')
+   OR (show_results = 'after_close' AND status = 'closed')
+ORDER BY id
+`
+
+func (q *Queries) ListCategoriesWithResults(ctx context.Context) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategoriesWithResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.VoteType,
+			&i.Status,
+			&i.ShowResults,
+			&i.MaxRank,
+			&i.CreatedAt,
+			&i.Color,
+			&i.Icon,
+			&i.DependsOn,
+			&i.SeedTopN,
+			&i.RunoffOf,
+			&i.ClosesAt,
+			&i.Slug,
+			&i.OpensAt,
+			&i.Skin,
+			&i.CustomCss,
+			&i.RevealSound,
+			&i.VotedWall,
+			&i.ResultsVersion,
+			&i.TestMode,
+			&i.Eligibility,
+			&i.Weights,
+			&i.Method,
+			&i.Judges,
+			&i.JudgeWeight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoryOptionCounts = `-- name: ListCategoryOptionCounts :many
+SELECT c.id AS category_id, COUNT(o.id) AS ballot_options
+FROM categories c
+LEFT JOIN options o ON o.category_id = c.id AND o.retired_at IS NULL
+GROUP BY c.id
+ORDER BY c.id
+`
+
+type ListCategoryOptionCountsRow struct {
+	CategoryID    int64 `json:"category_id"`
+	BallotOptions int64 `json:"ballot_options"`
+}
+
+func (q *Queries) ListCategoryOptionCounts(ctx context.Context) ([]ListCategoryOptionCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCategoryOptionCounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListCategoryOptionCountsRow{}
+	for rows.Next() {
+		var i ListCategoryOptionCountsRow
+		if err := rows.Scan(&i.CategoryID, &i.BallotOptions); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoryStatusHistory = `-- name: ListCategoryStatusHistory :many
+SELECT id, actor, action, category_id, detail, created_at, station_id, address FROM audit_events
+WHERE category_id = ?
+  AND action IN ('category.create', 'category.open', 'category.close', 'category.reopen', 'category.archive')
+ORDER BY id
+`
+
+func (q *Queries) ListCategoryStatusHistory(ctx context.Context, categoryID sql.NullInt64) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listCategoryStatusHistory, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditEvent{}
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Actor,
+			&i.Action,
+			&i.CategoryID,
+			&i.Detail,
+			&i.CreatedAt,
+			&i.StationID,
+			&i.Address,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoryVoteCounts = `-- name: ListCategoryVoteCounts :many
+SELECT c.id, c.name, c.vote_type, c.status, COUNT(v.id) AS vote_count
+FROM categories c
+LEFT JOIN votes v ON v.category_id = c.id
+WHERE c.status != 'archived'
+GROUP BY c.id
+ORDER BY c.id
+`
+
+type ListCategoryVoteCountsRow struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	VoteType  string `json:"vote_type"`
+	Status    string `json:"status"`
+	VoteCount int64  `json:"vote_count"`
+}
+
+func (q *Queries) ListCategoryVoteCounts(ctx context.Context) ([]ListCategoryVoteCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCategoryVoteCounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListCategoryVoteCountsRow{}
+	for rows.Next() {
+		var i ListCategoryVoteCountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.VoteType,
+			&i.Status,
+			&i.VoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoryVoteStats = `-- name: ListCategoryVoteStats :many
+SELECT c.id AS category_id, COUNT(v.id) AS votes, CAST(COALESCE(SUM(v.remote), 0) AS INTEGER) AS remote_votes
+FROM categories c
+LEFT JOIN votes v ON v.category_id = c.id
+GROUP BY c.id
+ORDER BY c.id
+`
+
+type ListCategoryVoteStatsRow struct {
+	CategoryID  int64 `json:"category_id"`
+	Votes       int64 `json:"votes"`
+	RemoteVotes int64 `json:"remote_votes"`
+}
+
+func (q *Queries) ListCategoryVoteStats(ctx context.Context) ([]ListCategoryVoteStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCategoryVoteStats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListCategoryVoteStatsRow{}
+	for rows.Next() {
+		var i ListCategoryVoteStatsRow
+		if err := rows.Scan(&i.CategoryID, &i.Votes, &i.RemoteVotes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDependentCategories = `-- name: ListDependentCategories :many
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories WHERE depends_on = ? ORDER BY id
+`
+
+func (q *Queries) ListDependentCategories(ctx context.Context, dependsOn sql.NullInt64) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listDependentCategories, dependsOn)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.VoteType,
+			&i.Status,
+			&i.ShowResults,
+			&i.MaxRank,
+			&i.CreatedAt,
+			&i.Color,
+			&i.Icon,
+			&i.DependsOn,
+			&i.SeedTopN,
+			&i.RunoffOf,
+			&i.ClosesAt,
+			&i.Slug,
+			&i.OpensAt,
+			&i.Skin,
+			&i.CustomCss,
+			&i.RevealSound,
+			&i.VotedWall,
+			&i.ResultsVersion,
+			&i.TestMode,
+			&i.Eligibility,
+			&i.Weights,
+			&i.Method,
+			&i.Judges,
+			&i.JudgeWeight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLatestVoteEvents = `-- name: ListLatestVoteEvents :many
+SELECT id, actor, action, category_id, detail, created_at, station_id, address FROM audit_events
+WHERE id IN (
+  SELECT MAX(id) FROM audit_events
+  WHERE action = 'vote' AND category_id IS NOT NULL
+  GROUP BY category_id
+)
+ORDER BY category_id
+`
+
+func (q *Queries) ListLatestVoteEvents(ctx context.Context) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listLatestVoteEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditEvent{}
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Actor,
+			&i.Action,
+			&i.CategoryID,
+			&i.Detail,
+			&i.CreatedAt,
+			&i.StationID,
+			&i.Address,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenCategories = `-- name: ListOpenCategories :many
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories WHERE status = 'open' ORDER BY created_at DESC
+`
+
+func (q *Queries) ListOpenCategories(ctx context.Context) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenCategories)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.VoteType,
+			&i.Status,
+			&i.ShowResults,
+			&i.MaxRank,
+			&i.CreatedAt,
+			&i.Color,
+			&i.Icon,
+			&i.DependsOn,
+			&i.SeedTopN,
+			&i.RunoffOf,
+			&i.ClosesAt,
+			&i.Slug,
+			&i.OpensAt,
+			&i.Skin,
+			&i.CustomCss,
+			&i.RevealSound,
+			&i.VotedWall,
+			&i.ResultsVersion,
+			&i.TestMode,
+			&i.Eligibility,
+			&i.Weights,
+			&i.Method,
+			&i.Judges,
+			&i.JudgeWeight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenVoteConflicts = `-- name: ListOpenVoteConflicts :many
+SELECT c.id, c.vote_id, c.version, v.nickname, v.version AS current_version, c.created_at,
+       c.first_address, CAST(COALESCE(fs.name, '') AS TEXT) AS first_station,
+       c.second_address, CAST(COALESCE(ss.name, '') AS TEXT) AS second_station
+FROM vote_conflicts c
+JOIN votes v ON v.id = c.vote_id
+LEFT JOIN stations fs ON fs.id = c.first_station_id
+LEFT JOIN stations ss ON ss.id = c.second_station_id
+WHERE v.category_id = ? AND c.resolved_at IS NULL
+ORDER BY c.id
+`
+
+type ListOpenVoteConflictsRow struct {
+	ID             int64        `json:"id"`
+	VoteID         int64        `json:"vote_id"`
+	Version        int64        `json:"version"`
+	Nickname       string       `json:"nickname"`
+	CurrentVersion int64        `json:"current_version"`
+	CreatedAt      sql.NullTime `json:"created_at"`
+	FirstAddress   string       `json:"first_address"`
+	FirstStation   string       `json:"first_station"`
+	SecondAddress  string       `json:"second_address"`
+	SecondStation  string       `json:"second_station"`
+}
+
+func (q *Queries) ListOpenVoteConflicts(ctx context.Context, categoryID int64) ([]ListOpenVoteConflictsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenVoteConflicts, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOpenVoteConflictsRow{}
+	for rows.Next() {
+		var i ListOpenVoteConflictsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.VoteID,
+			&i.Version,
+			&i.Nickname,
+			&i.CurrentVersion,
+			&i.CreatedAt,
+			&i.FirstAddress,
+			&i.FirstStation,
+			&i.SecondAddress,
+			&i.SecondStation,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOptionVotesByCategory = `-- name: ListOptionVotesByCategory :many
+SELECT o.id, o.category_id, o.name, o.sort_order, o.retired_at, o.seeded_from, o.image,
+       sc.name AS seeded_from_poll, COUNT(vs.id) AS votes
+FROM options o
+LEFT JOIN vote_selections vs ON vs.option_id = o.id
+LEFT JOIN options so ON so.id = o.seeded_from
+LEFT JOIN categories sc ON sc.id = so.category_id
+WHERE o.category_id = ?
+GROUP BY o.id
+ORDER BY o.sort_order, o.id
+`
+
+type ListOptionVotesByCategoryRow struct {
+	ID             int64          `json:"id"`
+	CategoryID     int64          `json:"category_id"`
+	Name           string         `json:"name"`
+	SortOrder      sql.NullInt64  `json:"sort_order"`
+	RetiredAt      sql.NullTime   `json:"retired_at"`
+	SeededFrom     sql.NullInt64  `json:"seeded_from"`
+	Image          string         `json:"image"`
+	SeededFromPoll sql.NullString `json:"seeded_from_poll"`
+	Votes          int64          `json:"votes"`
+}
+
+func (q *Queries) ListOptionVotesByCategory(ctx context.Context, categoryID int64) ([]ListOptionVotesByCategoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOptionVotesByCategory, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOptionVotesByCategoryRow{}
+	for rows.Next() {
+		var i ListOptionVotesByCategoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Name,
+			&i.SortOrder,
+			&i.RetiredAt,
+			&i.SeededFrom,
+			&i.Image,
+			&i.SeededFromPoll,
+			&i.Votes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOptionsByCategory = `-- name: ListOptionsByCategory :many
+SELECT id, category_id, name, sort_order, retired_at, seeded_from, image, blurb, video, screenshots FROM options WHERE category_id = ? ORDER BY sort_order, id
+`
+
+func (q *Queries) ListOptionsByCategory(ctx context.Context, categoryID int64) ([]Option, error) {
+	rows, err := q.db.QueryContext(ctx, listOptionsByCategory, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Option{}
+	for rows.Next() {
+		var i Option
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Name,
+			&i.SortOrder,
+			&i.RetiredAt,
+			&i.SeededFrom,
+			&i.Image,
+			&i.Blurb,
+			&i.Video,
+			&i.Screenshots,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listParticipation = `-- name: ListParticipation :many
+SELECT v.nickname, v.category_id, v.created_at FROM votes v
+JOIN categories c ON c.id = v.category_id
+WHERE c.status != 'draft'
+ORDER BY v.nickname, v.category_id
+`
+
+type ListParticipationRow struct {
+	Nickname   string       `json:"nickname"`
+	CategoryID int64        `json:"category_id"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) ListParticipation(ctx context.Context) ([]ListParticipationRow, error) {
+	rows, err := q.db.QueryContext(ctx, listParticipation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListParticipationRow{}
+	for rows.Next() {
+		var i ListParticipationRow
+		if err := rows.Scan(&i.Nickname, &i.CategoryID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecentAdminActions = `-- name: ListRecentAdminActions :many
+SELECT a.id, a.actor, a.action, a.category_id, a.detail, a.created_at, c.name AS category_name
+FROM audit_events a
+LEFT JOIN categories c ON c.id = a.category_id
+WHERE a.action != 'vote'
+ORDER BY a.id DESC
+LIMIT ?
+`
+
+type ListRecentAdminActionsRow struct {
+	ID           int64          `json:"id"`
+	Actor        string         `json:"actor"`
+	Action       string         `json:"action"`
+	CategoryID   sql.NullInt64  `json:"category_id"`
+	Detail       string         `json:"detail"`
+	CreatedAt    sql.NullTime   `json:"created_at"`
+	CategoryName sql.NullString `json:"category_name"`
+}
+
+func (q *Queries) ListRecentAdminActions(ctx context.Context, limit int64) ([]ListRecentAdminActionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRecentAdminActions, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRecentAdminActionsRow{}
+	for rows.Next() {
+		var i ListRecentAdminActionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Actor,
+			&i.Action,
+			&i.CategoryID,
+			&i.Detail,
+			&i.CreatedAt,
+			&i.CategoryName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecentlyClosedCategories = `-- name: ListRecentlyClosedCategories :many
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories
+WHERE status = 'closed'
+ORDER BY (
+  SELECT MAX(created_at) FROM audit_events
+  WHERE audit_events.category_id = categories.id AND audit_events.action = 'category.close'
+) DESC, id DESC
+LIMIT ?
+`
+
+func (q *Queries) ListRecentlyClosedCategories(ctx context.Context, limit int64) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listRecentlyClosedCategories, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.VoteType,
+			&i.Status,
+			&i.ShowResults,
+			&i.MaxRank,
+			&i.CreatedAt,
+			&i.Color,
+			&i.Icon,
+			&i.DependsOn,
+			&i.SeedTopN,
+			&i.RunoffOf,
+			&i.ClosesAt,
+			&i.Slug,
+			&i.OpensAt,
+			&i.Skin,
+			&i.CustomCss,
+			&i.RevealSound,
+			&i.VotedWall,
+			&i.ResultsVersion,
+			&i.TestMode,
+			&i.Eligibility,
+			&i.Weights,
+			&i.Method,
+			&i.Judges,
+			&i.JudgeWeight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listResultSnapshot = `-- name: ListResultSnapshot :many
+SELECT option_id, score, first_place, label FROM result_snapshots
+WHERE category_id = ?
+ORDER BY position
+`
+
+type ListResultSnapshotRow struct {
+	OptionID   int64  `json:"option_id"`
+	Score      int64  `json:"score"`
+	FirstPlace int64  `json:"first_place"`
+	Label      string `json:"label"`
+}
+
+func (q *Queries) ListResultSnapshot(ctx context.Context, categoryID int64) ([]ListResultSnapshotRow, error) {
+	rows, err := q.db.QueryContext(ctx, listResultSnapshot, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListResultSnapshotRow{}
+	for rows.Next() {
+		var i ListResultSnapshotRow
+		if err := rows.Scan(
+			&i.OptionID,
+			&i.Score,
+			&i.FirstPlace,
+			&i.Label,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeasonEvents = `-- name: ListSeasonEvents :many
+SELECT id, name, finalized_at FROM season_events ORDER BY id
+`
+
+func (q *Queries) ListSeasonEvents(ctx context.Context) ([]SeasonEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listSeasonEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SeasonEvent{}
+	for rows.Next() {
+		var i SeasonEvent
+		if err := rows.Scan(&i.ID, &i.Name, &i.FinalizedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeasonPoints = `-- name: ListSeasonPoints :many
+SELECT event_id, category_id, poll, place, name, points FROM season_points ORDER BY event_id, category_id, place
+`
+
+func (q *Queries) ListSeasonPoints(ctx context.Context) ([]SeasonPoint, error) {
+	rows, err := q.db.QueryContext(ctx, listSeasonPoints)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SeasonPoint{}
+	for rows.Next() {
+		var i SeasonPoint
+		if err := rows.Scan(
+			&i.EventID,
+			&i.CategoryID,
+			&i.Poll,
+			&i.Place,
+			&i.Name,
+			&i.Points,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeatTurnout = `-- name: ListSeatTurnout :many
+SELECT a.nickname, a.seat, COUNT(v.id) AS votes
+FROM attendees a
+LEFT JOIN votes v ON v.nickname = a.nickname AND v.category_id = ?
+GROUP BY a.id
+ORDER BY a.seat, a.nickname
+`
+
+type ListSeatTurnoutRow struct {
+	Nickname string `json:"nickname"`
+	Seat     string `json:"seat"`
+	Votes    int64  `json:"votes"`
+}
+
+func (q *Queries) ListSeatTurnout(ctx context.Context, categoryID int64) ([]ListSeatTurnoutRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSeatTurnout, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSeatTurnoutRow{}
+	for rows.Next() {
+		var i ListSeatTurnoutRow
+		if err := rows.Scan(&i.Nickname, &i.Seat, &i.Votes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSelectionHistoryByCategory = `-- name: ListSelectionHistoryByCategory :many
+SELECT h.vote_id, h.version, h.option_id, h.rank, h.replaced_at
+FROM vote_selection_history h
+JOIN votes v ON v.id = h.vote_id
+WHERE v.category_id = ? AND v.invalid = ''
+ORDER BY h.vote_id, h.version, h.rank, h.id
+`
+
+type ListSelectionHistoryByCategoryRow struct {
+	VoteID     int64         `json:"vote_id"`
+	Version    int64         `json:"version"`
+	OptionID   int64         `json:"option_id"`
+	Rank       sql.NullInt64 `json:"rank"`
+	ReplacedAt sql.NullTime  `json:"replaced_at"`
+}
+
+func (q *Queries) ListSelectionHistoryByCategory(ctx context.Context, categoryID int64) ([]ListSelectionHistoryByCategoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSelectionHistoryByCategory, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSelectionHistoryByCategoryRow{}
+	for rows.Next() {
+		var i ListSelectionHistoryByCategoryRow
+		if err := rows.Scan(
+			&i.VoteID,
+			&i.Version,
+			&i.OptionID,
+			&i.Rank,
+			&i.ReplacedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSelectionsByCategory = `-- name: ListSelectionsByCategory :many
+
+SELECT vs.vote_id, vs.option_id, vs.rank
+FROM vote_selections vs
+JOIN votes v ON v.id = vs.vote_id
+WHERE v.category_id = ? AND v.invalid = ''
+ORDER BY vs.vote_id, vs.rank, vs.id
+`
+
+type ListSelectionsByCategoryRow struct {
+	VoteID   int64         `json:"vote_id"`
+	OptionID int64         `json:"option_id"`
+	Rank     sql.NullInt64 `json:"rank"`
+}
+
+// Tally queries
+func (q *Queries) ListSelectionsByCategory(ctx context.Context, categoryID int64) ([]ListSelectionsByCategoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSelectionsByCategory, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSelectionsByCategoryRow{}
+	for rows.Next() {
+		var i ListSelectionsByCategoryRow
+		if err := rows.Scan(&i.VoteID, &i.OptionID, &i.Rank); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSelectionsByVote = `-- name: ListSelectionsByVote :many
+SELECT option_id, rank FROM vote_selections WHERE vote_id = ? ORDER BY rank, option_id
+`
+
+type ListSelectionsByVoteRow struct {
+	OptionID int64         `json:"option_id"`
+	Rank     sql.NullInt64 `json:"rank"`
+}
+
+func (q *Queries) ListSelectionsByVote(ctx context.Context, voteID int64) ([]ListSelectionsByVoteRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSelectionsByVote, voteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSelectionsByVoteRow{}
+	for rows.Next() {
+		var i ListSelectionsByVoteRow
+		if err := rows.Scan(&i.OptionID, &i.Rank); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSettings = `-- name: ListSettings :many
+SELECT key, value, updated_at FROM settings ORDER BY key
+`
+
+func (q *Queries) ListSettings(ctx context.Context) ([]Setting, error) {
+	rows, err := q.db.QueryContext(ctx, listSettings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Setting{}
+	for rows.Next() {
+		var i Setting
+		if err := rows.Scan(&i.Key, &i.Value, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStartedCategoryIDs = `-- name: ListStartedCategoryIDs :many
+SELECT id FROM categories WHERE status != 'draft' ORDER BY id
+`
+
+func (q *Queries) ListStartedCategoryIDs(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listStartedCategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStations = `-- name: ListStations :many
+SELECT id, name, pairing_hash, device_hash, created_at, paired_at, revoked_at FROM stations ORDER BY name
+`
+
+func (q *Queries) ListStations(ctx context.Context) ([]Station, error) {
+	rows, err := q.db.QueryContext(ctx, listStations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Station{}
+	for rows.Next() {
+		var i Station
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.PairingHash,
+			&i.DeviceHash,
+			&i.CreatedAt,
+			&i.PairedAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagTurnout = `-- name: ListTagTurnout :many
+SELECT t.tag, CAST(COUNT(*) AS INTEGER) AS attendees, CAST(COUNT(v.id) AS INTEGER) AS voted
+FROM attendee_tags t
+JOIN attendees a ON a.id = t.attendee_id
+LEFT JOIN votes v ON v.nickname = a.nickname AND v.category_id = ?1
+GROUP BY t.tag
+ORDER BY t.tag
+`
+
+type ListTagTurnoutRow struct {
+	Tag       string `json:"tag"`
+	Attendees int64  `json:"attendees"`
+	Voted     int64  `json:"voted"`
+}
+
+func (q *Queries) ListTagTurnout(ctx context.Context, categoryID int64) ([]ListTagTurnoutRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTagTurnout, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTagTurnoutRow{}
+	for rows.Next() {
+		var i ListTagTurnoutRow
+		if err := rows.Scan(&i.Tag, &i.Attendees, &i.Voted); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagsByNickname = `-- name: ListTagsByNickname :many
+SELECT t.tag FROM attendee_tags t
+JOIN attendees a ON a.id = t.attendee_id
+WHERE a.nickname = ?
+ORDER BY t.tag
+`
+
+func (q *Queries) ListTagsByNickname(ctx context.Context, nickname string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listTagsByNickname, nickname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		items = append(items, tag)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnscoredCategories = `-- name: ListUnscoredCategories :many
+SELECT id, name, vote_type, status, show_results, max_rank, created_at, color, icon, depends_on, seed_top_n, runoff_of, closes_at, slug, opens_at, skin, custom_css, reveal_sound, voted_wall, results_version, test_mode, eligibility, weights, method, judges, judge_weight FROM categories
+WHERE status IN ('closed', 'archived')
+  AND id IN (SELECT category_id FROM options)
+  AND id NOT IN (SELECT category_id FROM season_points)
+  AND id NOT IN (SELECT runoff_of FROM categories WHERE runoff_of IS NOT NULL)
+ORDER BY id
+`
+
+func (q *Queries) ListUnscoredCategories(ctx context.Context) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listUnscoredCategories)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.VoteType,
+			&i.Status,
+			&i.ShowResults,
+			&i.MaxRank,
+			&i.CreatedAt,
+			&i.Color,
+			&i.Icon,
+			&i.DependsOn,
+			&i.SeedTopN,
+			&i.RunoffOf,
+			&i.ClosesAt,
+			&i.Slug,
+			&i.OpensAt,
+			&i.Skin,
+			&i.CustomCss,
+			&i.RevealSound,
+			&i.VotedWall,
+			&i.ResultsVersion,
+			&i.TestMode,
+			&i.Eligibility,
+			&i.Weights,
+			&i.Method,
+			&i.Judges,
+			&i.JudgeWeight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVoteAuditActors = `-- name: ListVoteAuditActors :many
+SELECT actor FROM audit_events WHERE action = 'vote' GROUP BY actor
+`
+
+func (q *Queries) ListVoteAuditActors(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listVoteAuditActors)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var actor string
+		if err := rows.Scan(&actor); err != nil {
+			return nil, err
+		}
+		items = append(items, actor)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVoteChoices = `-- name: ListVoteChoices :many
+SELECT o.name FROM vote_selections vs
+JOIN options o ON o.id = vs.option_id
+WHERE vs.vote_id = ?
+ORDER BY vs.rank, o.sort_order, o.id
+`
+
+func (q *Queries) ListVoteChoices(ctx context.Context, voteID int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listVoteChoices, voteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVoteHistoryByCategory = `-- name: ListVoteHistoryByCategory :many
+SELECT v.nickname, h.version, h.rank, o.name AS option_name, h.replaced_at
+FROM vote_selection_history h
+JOIN votes v ON v.id = h.vote_id
+JOIN options o ON o.id = h.option_id
+WHERE v.category_id = ?
+ORDER BY v.nickname, h.version, h.rank, o.sort_order, o.id
+`
+
+type ListVoteHistoryByCategoryRow struct {
+	Nickname   string        `json:"nickname"`
+	Version    int64         `json:"version"`
+	Rank       sql.NullInt64 `json:"rank"`
+	OptionName string        `json:"option_name"`
+	ReplacedAt sql.NullTime  `json:"replaced_at"`
+}
+
+func (q *Queries) ListVoteHistoryByCategory(ctx context.Context, categoryID int64) ([]ListVoteHistoryByCategoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listVoteHistoryByCategory, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListVoteHistoryByCategoryRow{}
+	for rows.Next() {
+		var i ListVoteHistoryByCategoryRow
+		if err := rows.Scan(
+			&i.Nickname,
+			&i.Version,
+			&i.Rank,
+			&i.OptionName,
+			&i.ReplacedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVoteNicknames = `-- name: ListVoteNicknames :many
+SELECT nickname FROM votes GROUP BY nickname
+`
+
+func (q *Queries) ListVoteNicknames(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listVoteNicknames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var nickname string
+		if err := rows.Scan(&nickname); err != nil {
+			return nil, err
+		}
+		items = append(items, nickname)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVoteOrigins = `-- name: ListVoteOrigins :many
+SELECT v.id, v.nickname, v.created_at, v.invalid, v.counted_until,
+       CAST(COALESCE((
+         SELECT a.address FROM audit_events a
+         WHERE a.action = 'vote' AND a.category_id = v.category_id AND a.actor = v.nickname
+         ORDER BY a.id DESC LIMIT 1
+       ), '') AS TEXT) AS address
+FROM votes v
+WHERE v.category_id = ?
+ORDER BY v.created_at, v.id
+`
+
+type ListVoteOriginsRow struct {
+	ID           int64        `json:"id"`
+	Nickname     string       `json:"nickname"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+	Invalid      string       `json:"invalid"`
+	CountedUntil sql.NullTime `json:"counted_until"`
+	Address      string       `json:"address"`
+}
+
+func (q *Queries) ListVoteOrigins(ctx context.Context, categoryID int64) ([]ListVoteOriginsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listVoteOrigins, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListVoteOriginsRow{}
+	for rows.Next() {
+		var i ListVoteOriginsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Nickname,
+			&i.CreatedAt,
+			&i.Invalid,
+			&i.CountedUntil,
+			&i.Address,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVoteTagsByCategory = `-- name: ListVoteTagsByCategory :many
+SELECT v.id AS vote_id, t.tag FROM votes v
+JOIN attendees a ON a.nickname = v.nickname
+JOIN attendee_tags t ON t.attendee_id = a.id
+WHERE v.category_id = ?
+ORDER BY v.id, t.tag
 `
 
-func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
-	rows, err := q.db.QueryContext(ctx, listCategories)
+type ListVoteTagsByCategoryRow struct {
+	VoteID int64  `json:"vote_id"`
+	Tag    string `json:"tag"`
+}
+
+func (q *Queries) ListVoteTagsByCategory(ctx context.Context, categoryID int64) ([]ListVoteTagsByCategoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listVoteTagsByCategory, categoryID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Category{}
+	items := []ListVoteTagsByCategoryRow{}
 	for rows.Next() {
-		var i Category
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.VoteType,
-			&i.Status,
-			&i.ShowResults,
-			&i.MaxRank,
-			&i.CreatedAt,
-		); err != nil {
+		var i ListVoteTagsByCategoryRow
+		if err := rows.Scan(&i.VoteID, &i.Tag); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -240,28 +3012,27 @@ func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
 	return items, nil
 }
 
-const listCategoriesExcludeArchived = `-- name: ListCategoriesExcludeArchived :many
-SELECT id, name, vote_type, status, show_results, max_rank, created_at FROM categories WHERE status != 'archived' ORDER BY id
+const listVoteTimesByCategory = `-- name: ListVoteTimesByCategory :many
+SELECT actor, created_at FROM audit_events
+WHERE action = 'vote' AND category_id = ?
+ORDER BY id
 `
 
-func (q *Queries) ListCategoriesExcludeArchived(ctx context.Context) ([]Category, error) {
-	rows, err := q.db.QueryContext(ctx, listCategoriesExcludeArchived)
+type ListVoteTimesByCategoryRow struct {
+	Actor     string       `json:"actor"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) ListVoteTimesByCategory(ctx context.Context, categoryID sql.NullInt64) ([]ListVoteTimesByCategoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listVoteTimesByCategory, categoryID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Category{}
+	items := []ListVoteTimesByCategoryRow{}
 	for rows.Next() {
-		var i Category
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.VoteType,
-			&i.Status,
-			&i.ShowResults,
-			&i.MaxRank,
-			&i.CreatedAt,
-		); err != nil {
+		var i ListVoteTimesByCategoryRow
+		if err := rows.Scan(&i.Actor, &i.CreatedAt); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -275,34 +3046,31 @@ func (q *Queries) ListCategoriesExcludeArchived(ctx context.Context) ([]Category
 	return items, nil
 }
 
-const listCategoriesWithResults = `-- name: ListCategoriesWithResults :many
-SELECT id, name, vote_type, status, show_results, max_rank, created_at FROM categories
-WHERE (show_results = 'live' AND status = 'open')
-   OR (show_results = 'after_close' AND status = 'closed')
-ORDER BY id
+const listVoteVersionChoices = `-- name: ListVoteVersionChoices :many
+SELECT o.name FROM vote_selection_history h
+JOIN options o ON o.id = h.option_id
+WHERE h.vote_id = ? AND h.version = ?
+ORDER BY h.rank, o.sort_order, o.id
 `
 
-func (q *Queries) ListCategoriesWithResults(ctx context.Context) ([]Category, error) {
-	rows, err := q.db.QueryContext(ctx, listCategoriesWithResults)
+type ListVoteVersionChoicesParams struct {
+	VoteID  int64 `json:"vote_id"`
+	Version int64 `json:"version"`
+}
+
+func (q *Queries) ListVoteVersionChoices(ctx context.Context, arg ListVoteVersionChoicesParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listVoteVersionChoices, arg.VoteID, arg.Version)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Category{}
+	items := []string{}
 	for rows.Next() {
-		var i Category
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.VoteType,
-			&i.Status,
-			&i.ShowResults,
-			&i.MaxRank,
-			&i.CreatedAt,
-		); err != nil {
+		var name string
+		if err := rows.Scan(&name); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, name)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -313,31 +3081,23 @@ func (q *Queries) ListCategoriesWithResults(ctx context.Context) ([]Category, er
 	return items, nil
 }
 
-const listOpenCategories = `-- name: ListOpenCategories :many
-SELECT id, name, vote_type, status, show_results, max_rank, created_at FROM categories WHERE status = 'open' ORDER BY created_at DESC
+const listVotersByCategory = `-- name: ListVotersByCategory :many
+SELECT nickname FROM votes WHERE category_id = ? ORDER BY created_at
 `
 
-func (q *Queries) ListOpenCategories(ctx context.Context) ([]Category, error) {
-	rows, err := q.db.QueryContext(ctx, listOpenCategories)
+func (q *Queries) ListVotersByCategory(ctx context.Context, categoryID int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listVotersByCategory, categoryID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Category{}
+	items := []string{}
 	for rows.Next() {
-		var i Category
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.VoteType,
-			&i.Status,
-			&i.ShowResults,
-			&i.MaxRank,
-			&i.CreatedAt,
-		); err != nil {
+		var nickname string
+		if err := rows.Scan(&nickname); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, nickname)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -348,24 +3108,28 @@ func (q *Queries) ListOpenCategories(ctx context.Context) ([]Category, error) {
 	return items, nil
 }
 
-const listOptionsByCategory = `-- name: ListOptionsByCategory :many
-SELECT id, category_id, name, sort_order FROM options WHERE category_id = ? ORDER BY sort_order, id
+const listVotesByCategory = `-- name: ListVotesByCategory :many
+SELECT id, category_id, nickname, created_at, version, remote, invalid, counted_until FROM votes WHERE category_id = ? ORDER BY id
 `
 
-func (q *Queries) ListOptionsByCategory(ctx context.Context, categoryID int64) ([]Option, error) {
-	rows, err := q.db.QueryContext(ctx, listOptionsByCategory, categoryID)
+func (q *Queries) ListVotesByCategory(ctx context.Context, categoryID int64) ([]Vote, error) {
+	rows, err := q.db.QueryContext(ctx, listVotesByCategory, categoryID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Option{}
+	items := []Vote{}
 	for rows.Next() {
-		var i Option
+		var i Vote
 		if err := rows.Scan(
 			&i.ID,
 			&i.CategoryID,
-			&i.Name,
-			&i.SortOrder,
+			&i.Nickname,
+			&i.CreatedAt,
+			&i.Version,
+			&i.Remote,
+			&i.Invalid,
+			&i.CountedUntil,
 		); err != nil {
 			return nil, err
 		}
@@ -380,23 +3144,32 @@ func (q *Queries) ListOptionsByCategory(ctx context.Context, categoryID int64) (
 	return items, nil
 }
 
-const listVotersByCategory = `-- name: ListVotersByCategory :many
-SELECT nickname FROM votes WHERE category_id = ? ORDER BY created_at
+const listVotesPerMinute = `-- name: ListVotesPerMinute :many
+SELECT CAST(strftime('%H:%M', created_at) AS TEXT) AS minute, COUNT(*) AS votes
+FROM audit_events
+WHERE action = 'vote' AND created_at >= datetime('now', '-10 minutes')
+GROUP BY minute
+ORDER BY MIN(id)
 `
 
-func (q *Queries) ListVotersByCategory(ctx context.Context, categoryID int64) ([]string, error) {
-	rows, err := q.db.QueryContext(ctx, listVotersByCategory, categoryID)
+type ListVotesPerMinuteRow struct {
+	Minute string `json:"minute"`
+	Votes  int64  `json:"votes"`
+}
+
+func (q *Queries) ListVotesPerMinute(ctx context.Context) ([]ListVotesPerMinuteRow, error) {
+	rows, err := q.db.QueryContext(ctx, listVotesPerMinute)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []string{}
+	items := []ListVotesPerMinuteRow{}
 	for rows.Next() {
-		var nickname string
-		if err := rows.Scan(&nickname); err != nil {
+		var i ListVotesPerMinuteRow
+		if err := rows.Scan(&i.Minute, &i.Votes); err != nil {
 			return nil, err
 		}
-		items = append(items, nickname)
+		items = append(items, i)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -407,8 +3180,240 @@ func (q *Queries) ListVotersByCategory(ctx context.Context, categoryID int64) ([
 	return items, nil
 }
 
+const pairStation = `-- name: PairStation :exec
+UPDATE stations SET pairing_hash = NULL, device_hash = ?, paired_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type PairStationParams struct {
+	DeviceHash []byte `json:"device_hash"`
+	ID         int64  `json:"id"`
+}
+
+func (q *Queries) PairStation(ctx context.Context, arg PairStationParams) error {
+	_, err := q.db.ExecContext(ctx, pairStation, arg.DeviceHash, arg.ID)
+	return err
+}
+
+const purgeVoteHistoryByCategory = `-- name: PurgeVoteHistoryByCategory :execrows
+DELETE FROM vote_selection_history
+WHERE vote_id IN (SELECT id FROM votes WHERE category_id = ?)
+`
+
+func (q *Queries) PurgeVoteHistoryByCategory(ctx context.Context, categoryID int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeVoteHistoryByCategory, categoryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const renameAttendee = `-- name: RenameAttendee :exec
+UPDATE attendees SET nickname = ?1 WHERE nickname = ?2
+`
+
+type RenameAttendeeParams struct {
+	NewNickname string `json:"new_nickname"`
+	OldNickname string `json:"old_nickname"`
+}
+
+func (q *Queries) RenameAttendee(ctx context.Context, arg RenameAttendeeParams) error {
+	_, err := q.db.ExecContext(ctx, renameAttendee, arg.NewNickname, arg.OldNickname)
+	return err
+}
+
+const renameVoter = `-- name: RenameVoter :exec
+UPDATE votes SET nickname = ?1 WHERE nickname = ?2
+`
+
+type RenameVoterParams struct {
+	NewNickname string `json:"new_nickname"`
+	OldNickname string `json:"old_nickname"`
+}
+
+func (q *Queries) RenameVoter(ctx context.Context, arg RenameVoterParams) error {
+	_, err := q.db.ExecContext(ctx, renameVoter, arg.NewNickname, arg.OldNickname)
+	return err
+}
+
+const resetStationPairing = `-- name: ResetStationPairing :exec
+UPDATE stations SET pairing_hash = ?, device_hash = NULL, paired_at = NULL, revoked_at = NULL
+WHERE id = ?
+`
+
+type ResetStationPairingParams struct {
+	PairingHash []byte `json:"pairing_hash"`
+	ID          int64  `json:"id"`
+}
+
+func (q *Queries) ResetStationPairing(ctx context.Context, arg ResetStationPairingParams) error {
+	_, err := q.db.ExecContext(ctx, resetStationPairing, arg.PairingHash, arg.ID)
+	return err
+}
+
+const resetVoteVersionsByCategory = `-- name: ResetVoteVersionsByCategory :exec
+UPDATE votes SET version = 1 WHERE category_id = ?
+`
+
+func (q *Queries) ResetVoteVersionsByCategory(ctx context.Context, categoryID int64) error {
+	_, err := q.db.ExecContext(ctx, resetVoteVersionsByCategory, categoryID)
+	return err
+}
+
+const resolveVoteConflict = `-- name: ResolveVoteConflict :exec
+UPDATE vote_conflicts SET resolved_at = CURRENT_TIMESTAMP, kept = ? WHERE id = ?
+`
+
+type ResolveVoteConflictParams struct {
+	Kept string `json:"kept"`
+	ID   int64  `json:"id"`
+}
+
+func (q *Queries) ResolveVoteConflict(ctx context.Context, arg ResolveVoteConflictParams) error {
+	_, err := q.db.ExecContext(ctx, resolveVoteConflict, arg.Kept, arg.ID)
+	return err
+}
+
+const restoreVoteSelections = `-- name: RestoreVoteSelections :exec
+INSERT INTO vote_selections (vote_id, option_id, rank)
+SELECT vote_id, option_id, rank FROM vote_selection_history
+WHERE vote_id = ? AND version = ?
+`
+
+type RestoreVoteSelectionsParams struct {
+	VoteID  int64 `json:"vote_id"`
+	Version int64 `json:"version"`
+}
+
+func (q *Queries) RestoreVoteSelections(ctx context.Context, arg RestoreVoteSelectionsParams) error {
+	_, err := q.db.ExecContext(ctx, restoreVoteSelections, arg.VoteID, arg.Version)
+	return err
+}
+
+const retireOption = `-- name: RetireOption :exec
+UPDATE options SET retired_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+func (q *Queries) RetireOption(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, retireOption, id)
+	return err
+}
+
+const revokeStation = `-- name: RevokeStation :execrows
+UPDATE stations SET pairing_hash = NULL, revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeStation(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeStation, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rotateObserverKey = `-- name: RotateObserverKey :exec
+UPDATE observer_key SET key = randomblob(32) WHERE id = 1
+`
+
+func (q *Queries) RotateObserverKey(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, rotateObserverKey)
+	return err
+}
+
+const setCategoryRunoffOf = `-- name: SetCategoryRunoffOf :exec
+UPDATE categories SET runoff_of = ? WHERE id = ?
+`
+
+type SetCategoryRunoffOfParams struct {
+	RunoffOf sql.NullInt64 `json:"runoff_of"`
+	ID       int64         `json:"id"`
+}
+
+func (q *Queries) SetCategoryRunoffOf(ctx context.Context, arg SetCategoryRunoffOfParams) error {
+	_, err := q.db.ExecContext(ctx, setCategoryRunoffOf, arg.RunoffOf, arg.ID)
+	return err
+}
+
+const setCategoryTestMode = `-- name: SetCategoryTestMode :exec
+UPDATE categories SET test_mode = ? WHERE id = ?
+`
+
+type SetCategoryTestModeParams struct {
+	TestMode bool  `json:"test_mode"`
+	ID       int64 `json:"id"`
+}
+
+func (q *Queries) SetCategoryTestMode(ctx context.Context, arg SetCategoryTestModeParams) error {
+	_, err := q.db.ExecContext(ctx, setCategoryTestMode, arg.TestMode, arg.ID)
+	return err
+}
+
+const setOptionDetails = `-- name: SetOptionDetails :exec
+UPDATE options SET blurb = ?, video = ?, screenshots = ? WHERE id = ?
+`
+
+type SetOptionDetailsParams struct {
+	Blurb       string `json:"blurb"`
+	Video       string `json:"video"`
+	Screenshots string `json:"screenshots"`
+	ID          int64  `json:"id"`
+}
+
+func (q *Queries) SetOptionDetails(ctx context.Context, arg SetOptionDetailsParams) error {
+	_, err := q.db.ExecContext(ctx, setOptionDetails,
+		arg.Blurb,
+		arg.Video,
+		arg.Screenshots,
+		arg.ID,
+	)
+	return err
+}
+
+const setOptionImage = `-- name: SetOptionImage :exec
+UPDATE options SET image = ? WHERE id = ?
+`
+
+type SetOptionImageParams struct {
+	Image string `json:"image"`
+	ID    int64  `json:"id"`
+}
+
+func (q *Queries) SetOptionImage(ctx context.Context, arg SetOptionImageParams) error {
+	_, err := q.db.ExecContext(ctx, setOptionImage, arg.Image, arg.ID)
+	return err
+}
+
+const setVoteCountedUntil = `-- name: SetVoteCountedUntil :exec
+UPDATE votes SET counted_until = ? WHERE id = ?
+`
+
+type SetVoteCountedUntilParams struct {
+	CountedUntil sql.NullTime `json:"counted_until"`
+	ID           int64        `json:"id"`
+}
+
+func (q *Queries) SetVoteCountedUntil(ctx context.Context, arg SetVoteCountedUntilParams) error {
+	_, err := q.db.ExecContext(ctx, setVoteCountedUntil, arg.CountedUntil, arg.ID)
+	return err
+}
+
+const setVoteInvalid = `-- name: SetVoteInvalid :exec
+UPDATE votes SET invalid = ? WHERE id = ?
+`
+
+type SetVoteInvalidParams struct {
+	Invalid string `json:"invalid"`
+	ID      int64  `json:"id"`
+}
+
+func (q *Queries) SetVoteInvalid(ctx context.Context, arg SetVoteInvalidParams) error {
+	_, err := q.db.ExecContext(ctx, setVoteInvalid, arg.Invalid, arg.ID)
+	return err
+}
+
 const tallyRanked = `-- name: TallyRanked :many
-SELECT o.id, o.name,
+SELECT o.id, o.name, o.image,
        COALESCE(SUM(?1 - vs.rank + 1), 0) as points,
        COUNT(CASE WHEN vs.rank = 1 THEN 1 END) as first_place_votes
 FROM options o
@@ -426,6 +3431,7 @@ type TallyRankedParams struct {
 type TallyRankedRow struct {
 	ID              int64       `json:"id"`
 	Name            string      `json:"name"`
+	Image           string      `json:"image"`
 	Points          interface{} `json:"points"`
 	FirstPlaceVotes int64       `json:"first_place_votes"`
 }
@@ -442,6 +3448,7 @@ func (q *Queries) TallyRanked(ctx context.Context, arg TallyRankedParams) ([]Tal
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
+			&i.Image,
 			&i.Points,
 			&i.FirstPlaceVotes,
 		); err != nil {
@@ -459,8 +3466,7 @@ func (q *Queries) TallyRanked(ctx context.Context, arg TallyRankedParams) ([]Tal
 }
 
 const tallySimple = `-- name: TallySimple :many
-
-SELECT o.id, o.name, COUNT(vs.id) as votes
+SELECT o.id, o.name, o.image, COUNT(vs.id) as votes
 FROM options o
 LEFT JOIN vote_selections vs ON vs.option_id = o.id
 WHERE o.category_id = ?1
@@ -471,10 +3477,10 @@ ORDER BY votes DESC, o.sort_order, o.id
 type TallySimpleRow struct {
 	ID    int64  `json:"id"`
 	Name  string `json:"name"`
+	Image string `json:"image"`
 	Votes int64  `json:"votes"`
 }
 
-// Tally queries
 func (q *Queries) TallySimple(ctx context.Context, categoryID int64) ([]TallySimpleRow, error) {
 	rows, err := q.db.QueryContext(ctx, tallySimple, categoryID)
 	if err != nil {
@@ -484,7 +3490,12 @@ func (q *Queries) TallySimple(ctx context.Context, categoryID int64) ([]TallySim
 	items := []TallySimpleRow{}
 	for rows.Next() {
 		var i TallySimpleRow
-		if err := rows.Scan(&i.ID, &i.Name, &i.Votes); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Image,
+			&i.Votes,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -499,15 +3510,31 @@ func (q *Queries) TallySimple(ctx context.Context, categoryID int64) ([]TallySim
 }
 
 const updateCategory = `-- name: UpdateCategory :exec
-UPDATE categories SET name = ?, vote_type = ?, show_results = ?, max_rank = ? WHERE id = ?
+UPDATE categories SET name = ?, vote_type = ?, show_results = ?, max_rank = ?, color = ?, icon = ?, depends_on = ?, seed_top_n = ?, closes_at = ?, slug = ?, opens_at = ?, skin = ?, custom_css = ?, reveal_sound = ?, voted_wall = ?, eligibility = ?, weights = ?, method = ?, judges = ?, judge_weight = ? WHERE id = ?
 `
 
 type UpdateCategoryParams struct {
-	Name        string        `json:"name"`
-	VoteType    string        `json:"vote_type"`
-	ShowResults string        `json:"show_results"`
-	MaxRank     sql.NullInt64 `json:"max_rank"`
-	ID          int64         `json:"id"`
+	Name        string         `json:"name"`
+	VoteType    string         `json:"vote_type"`
+	ShowResults string         `json:"show_results"`
+	MaxRank     sql.NullInt64  `json:"max_rank"`
+	Color       string         `json:"color"`
+	Icon        string         `json:"icon"`
+	DependsOn   sql.NullInt64  `json:"depends_on"`
+	SeedTopN    int64          `json:"seed_top_n"`
+	ClosesAt    sql.NullTime   `json:"closes_at"`
+	Slug        sql.NullString `json:"slug"`
+	OpensAt     sql.NullTime   `json:"opens_at"`
+	Skin        string         `json:"skin"`
+	CustomCss   string         `json:"custom_css"`
+	RevealSound string         `json:"reveal_sound"`
+	VotedWall   string         `json:"voted_wall"`
+	Eligibility string         `json:"eligibility"`
+	Weights     string         `json:"weights"`
+	Method      string         `json:"method"`
+	Judges      string         `json:"judges"`
+	JudgeWeight int64          `json:"judge_weight"`
+	ID          int64          `json:"id"`
 }
 
 func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) error {
@@ -516,6 +3543,22 @@ func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams)
 		arg.VoteType,
 		arg.ShowResults,
 		arg.MaxRank,
+		arg.Color,
+		arg.Icon,
+		arg.DependsOn,
+		arg.SeedTopN,
+		arg.ClosesAt,
+		arg.Slug,
+		arg.OpensAt,
+		arg.Skin,
+		arg.CustomCss,
+		arg.RevealSound,
+		arg.VotedWall,
+		arg.Eligibility,
+		arg.Weights,
+		arg.Method,
+		arg.Judges,
+		arg.JudgeWeight,
 		arg.ID,
 	)
 	return err
@@ -535,28 +3578,108 @@ func (q *Queries) UpdateCategoryStatus(ctx context.Context, arg UpdateCategorySt
 	return err
 }
 
-const upsertVote = `-- name: UpsertVote :one
+const upsertAttendee = `-- name: UpsertAttendee :exec
+INSERT INTO attendees (nickname, seat)
+VALUES (?, ?)
+ON CONFLICT(nickname) DO UPDATE SET seat = excluded.seat, synced_at = CURRENT_TIMESTAMP
+`
 
-INSERT INTO votes (category_id, nickname)
+type UpsertAttendeeParams struct {
+	Nickname string `json:"nickname"`
+	Seat     string `json:"seat"`
+}
+
+func (q *Queries) UpsertAttendee(ctx context.Context, arg UpsertAttendeeParams) error {
+	_, err := q.db.ExecContext(ctx, upsertAttendee, arg.Nickname, arg.Seat)
+	return err
+}
+
+const upsertMediaPreview = `-- name: UpsertMediaPreview :exec
+INSERT INTO media_previews (link, provider, title, author, thumbnail)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(link) DO UPDATE SET provider = excluded.provider, title = excluded.title,
+  author = excluded.author, thumbnail = excluded.thumbnail, fetched_at = CURRENT_TIMESTAMP
+`
+
+type UpsertMediaPreviewParams struct {
+	Link      string `json:"link"`
+	Provider  string `json:"provider"`
+	Title     string `json:"title"`
+	Author    string `json:"author"`
+	Thumbnail string `json:"thumbnail"`
+}
+
+func (q *Queries) UpsertMediaPreview(ctx context.Context, arg UpsertMediaPreviewParams) error {
+	_, err := q.db.ExecContext(ctx, upsertMediaPreview,
+		arg.Link,
+		arg.Provider,
+		arg.Title,
+		arg.Author,
+		arg.Thumbnail,
+	)
+	return err
+}
+
+const upsertSession = `-- name: UpsertSession :exec
+INSERT INTO sessions (id, data, expires_at)
+VALUES (?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET data = excluded.data, expires_at = excluded.expires_at
+`
+
+type UpsertSessionParams struct {
+	ID        string    `json:"id"`
+	Data      string    `json:"data"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) UpsertSession(ctx context.Context, arg UpsertSessionParams) error {
+	_, err := q.db.ExecContext(ctx, upsertSession, arg.ID, arg.Data, arg.ExpiresAt)
+	return err
+}
+
+const upsertSetting = `-- name: UpsertSetting :exec
+INSERT INTO settings (key, value)
 VALUES (?, ?)
-ON CONFLICT(category_id, nickname) DO UPDATE SET created_at = CURRENT_TIMESTAMP
-RETURNING id, category_id, nickname, created_at
+ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP
+`
+
+type UpsertSettingParams struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (q *Queries) UpsertSetting(ctx context.Context, arg UpsertSettingParams) error {
+	_, err := q.db.ExecContext(ctx, upsertSetting, arg.Key, arg.Value)
+	return err
+}
+
+const upsertVote = `-- name: UpsertVote :one
+
+INSERT INTO votes (category_id, nickname, remote)
+VALUES (?, ?, ?)
+ON CONFLICT(category_id, nickname) DO UPDATE SET created_at = CURRENT_TIMESTAMP, version = version + 1, remote = excluded.remote
+RETURNING id, category_id, nickname, created_at, version, remote, invalid, counted_until
 `
 
 type UpsertVoteParams struct {
 	CategoryID int64  `json:"category_id"`
 	Nickname   string `json:"nickname"`
+	Remote     bool   `json:"remote"`
 }
 
 // Vote queries
 func (q *Queries) UpsertVote(ctx context.Context, arg UpsertVoteParams) (Vote, error) {
-	row := q.db.QueryRowContext(ctx, upsertVote, arg.CategoryID, arg.Nickname)
+	row := q.db.QueryRowContext(ctx, upsertVote, arg.CategoryID, arg.Nickname, arg.Remote)
 	var i Vote
 	err := row.Scan(
 		&i.ID,
 		&i.CategoryID,
 		&i.Nickname,
 		&i.CreatedAt,
+		&i.Version,
+		&i.Remote,
+		&i.Invalid,
+		&i.CountedUntil,
 	)
 	return i, err
 }
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// BallotReceipt returns the receipt code shown to a voter after they vote,
+// e.g. "3F9A-C21B-77D0". It is derived from the vote and its current
+// selections, so a random audit can confirm with a voter that the ballot
+// stored under their receipt is the one they cast. Voting again changes it.
+func (q *Queries) BallotReceipt(ctx context.Context, vote Vote) (string, error) {
+	rows, err := q.ListSelectionsByVote(ctx, vote.ID)
+	if err != nil {
+		return "", err
+	}
+	selections := make([]ListSelectionsByCategoryRow, len(rows))
+	for i, row := range rows {
+		selections[i] = ListSelectionsByCategoryRow{VoteID: vote.ID, OptionID: row.OptionID, Rank: row.Rank}
+	}
+	return Receipt(vote, selections), nil
+}
+
+// Receipt computes vote's receipt code from its selections, for callers
+// that loaded a whole category's selections at once. Selections may come in
+// any order.
+func Receipt(vote Vote, selections []ListSelectionsByCategoryRow) string {
+	selections = slices.Clone(selections)
+	slices.SortFunc(selections, func(a, b ListSelectionsByCategoryRow) int {
+		return cmp.Or(cmp.Compare(a.Rank.Int64, b.Rank.Int64), cmp.Compare(a.OptionID, b.OptionID))
+	})
+
+	h := sha256.New()
+	h.Write([]byte("votigo-receipt-v1"))
+	for _, n := range []int64{vote.CategoryID, vote.ID, vote.Version} {
+		binary.Write(h, binary.BigEndian, n)
+	}
+	for _, sel := range selections {
+		binary.Write(h, binary.BigEndian, sel.OptionID)
+		binary.Write(h, binary.BigEndian, sel.Rank.Int64)
+	}
+
+	code := strings.ToUpper(fmt.Sprintf("%x", h.Sum(nil)[:6]))
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12]
+}
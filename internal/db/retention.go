@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PurgedActor replaces voters' nicknames in the vote events of a poll whose
+// ballots were purged, like ForgottenActor does for one voter
+const PurgedActor = "[purged]"
+
+// ErrPurged refuses to reopen a poll whose ballots were purged. It comes
+// wrapped as ErrConflict.
+var ErrPurged = errors.New("the poll's ballots were purged under the retention policy")
+
+// Purge is a poll whose ballots PurgeExpiredBallots deleted
+type Purge struct {
+	Category Category
+	Ballots  int64
+}
+
+// BallotRetention returns how long a finished poll keeps its ballots, or 0
+// if they are kept forever
+func (q *Queries) BallotRetention(ctx context.Context) (time.Duration, error) {
+	days, err := q.SettingInt(ctx, SettingBallotRetentionDays)
+	if err != nil || days <= 0 {
+		return 0, err
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// Purged reports whether cat's ballots were purged, leaving only a snapshot
+// of its results
+func (q *Queries) Purged(ctx context.Context, categoryID int64) (bool, error) {
+	_, err := q.GetBallotPurge(ctx, categoryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PurgeBallots snapshots cat's results, then deletes its ballots, previous
+// ballot versions included, and anonymizes its vote events in the audit
+// log. The purge is recorded in the audit log as actor, with detail saying
+// why. Call it on a Queries bound to a transaction (see InTx) so a poll is
+// never left with neither ballots nor results.
+func (q *Queries) PurgeBallots(ctx context.Context, cat Category, actor, detail string) (int64, error) {
+	tallied, err := q.Tally(ctx, cat)
+	if err != nil {
+		return 0, fmt.Errorf("tally: %w", err)
+	}
+	votes, err := q.CountVotesByCategory(ctx, cat.ID)
+	if err != nil {
+		return 0, err
+	}
+	if err := q.CreateBallotPurge(ctx, CreateBallotPurgeParams{CategoryID: cat.ID, Votes: votes}); err != nil {
+		return 0, fmt.Errorf("record purge: %w", err)
+	}
+	for i, o := range tallied {
+		err := q.CreateResultSnapshot(ctx, CreateResultSnapshotParams{
+			CategoryID: cat.ID,
+			OptionID:   o.ID,
+			Position:   int64(i),
+			Score:      o.Score,
+			FirstPlace: o.FirstPlace,
+			Label:      o.Label,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("snapshot results: %w", err)
+		}
+	}
+
+	ballots, err := q.DeleteVotesByCategory(ctx, cat.ID)
+	if err != nil {
+		return 0, fmt.Errorf("delete ballots: %w", err)
+	}
+	if err := q.DeleteIdempotencyKeysByCategory(ctx, cat.ID); err != nil {
+		return 0, fmt.Errorf("delete idempotency keys: %w", err)
+	}
+	_, err = q.AnonymizeVoteAuditEventsByCategory(ctx, AnonymizeVoteAuditEventsByCategoryParams{
+		Replacement: PurgedActor,
+		CategoryID:  sql.NullInt64{Int64: cat.ID, Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("anonymize audit events: %w", err)
+	}
+	detail = fmt.Sprintf("%d ballot(s), %s", ballots, detail)
+	return ballots, q.RecordAudit(ctx, actor, AuditBallotPurge, cat.ID, detail)
+}
+
+// PurgeExpiredBallots purges the ballots of every poll that closed longer
+// than retention before now and hasn't been purged yet, each in its own
+// transaction, recording them in the audit log as actor. It returns the
+// polls purged, including those before one that failed.
+func PurgeExpiredBallots(ctx context.Context, conn *sql.DB, actor string, retention time.Duration, now time.Time) ([]Purge, error) {
+	polls, err := New(conn).ListCategoriesToPurge(ctx, sql.NullTime{Time: now.Add(-retention), Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("list finished polls: %w", err)
+	}
+	detail := fmt.Sprintf("closed for over %d day(s)", int64(retention/(24*time.Hour)))
+	var purged []Purge
+	for _, cat := range polls {
+		var n int64
+		err := InTx(ctx, conn, func(q *Queries) error {
+			var err error
+			n, err = q.PurgeBallots(ctx, cat, actor, detail)
+			return err
+		})
+		if err != nil {
+			return purged, fmt.Errorf("purge %s: %w", cat.Name, err)
+		}
+		purged = append(purged, Purge{Category: cat, Ballots: n})
+	}
+	return purged, nil
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// RosterChanges counts what SyncRoster did
+type RosterChanges struct {
+	Added    int
+	Moved    int // attendees whose seat changed
+	Removed  int
+	Retagged int // attendees whose tags changed; see SyncRosterTags
+}
+
+func (c RosterChanges) String() string {
+	s := fmt.Sprintf("%d added, %d moved, %d removed", c.Added, c.Moved, c.Removed)
+	if c.Retagged > 0 {
+		s += fmt.Sprintf(", %d retagged", c.Retagged)
+	}
+	return s
+}
+
+// SyncRoster makes the roster match seats, which maps each attendee's
+// nickname, as stored (see NicknameCipher.Seal), to their seat: attendees
+// not in seats are removed and the rest added or given their new seat.
+// Ballots are untouched. Call it inside a transaction (see InTx) so the
+// roster is never half synced.
+func (q *Queries) SyncRoster(ctx context.Context, seats map[string]string) (RosterChanges, error) {
+	var changes RosterChanges
+	current, err := q.ListAttendees(ctx)
+	if err != nil {
+		return changes, err
+	}
+	known := make(map[string]string, len(current))
+	for _, a := range current {
+		known[a.Nickname] = a.Seat
+		if _, ok := seats[a.Nickname]; ok {
+			continue
+		}
+		if err := q.DeleteAttendee(ctx, a.Nickname); err != nil {
+			return changes, fmt.Errorf("remove attendee: %w", err)
+		}
+		changes.Removed++
+	}
+
+	for nickname, seat := range seats {
+		if err := q.UpsertAttendee(ctx, UpsertAttendeeParams{Nickname: nickname, Seat: seat}); err != nil {
+			return changes, fmt.Errorf("add attendee: %w", err)
+		}
+		switch old, ok := known[nickname]; {
+		case !ok:
+			changes.Added++
+		case old != seat:
+			changes.Moved++
+		}
+	}
+	return changes, nil
+}
+
+// SyncRosterTags gives attendees the tags a roster source lists, replacing
+// any set in admin. tags maps nicknames, as stored, to tags normalized with
+// ParseTags; attendees not in it keep theirs. It returns how many
+// attendees' tags changed. Call it inside the transaction that ran
+// SyncRoster, so every nickname is on the roster.
+func (q *Queries) SyncRosterTags(ctx context.Context, tags map[string][]string) (int, error) {
+	attendees, err := q.ListAttendees(ctx)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := q.ListAttendeeTags(ctx)
+	if err != nil {
+		return 0, err
+	}
+	current := make(map[int64][]string)
+	for _, row := range rows {
+		current[row.AttendeeID] = append(current[row.AttendeeID], row.Tag)
+	}
+
+	retagged := 0
+	for _, a := range attendees {
+		want, ok := tags[a.Nickname]
+		if !ok || slices.Equal(want, current[a.ID]) {
+			continue
+		}
+		if err := q.SetAttendeeTags(ctx, a.ID, want); err != nil {
+			return retagged, fmt.Errorf("tag attendee %d: %w", a.ID, err)
+		}
+		retagged++
+	}
+	return retagged, nil
+}
+
+// SetAttendeeTags replaces the tags of attendee id, which polls'
+// eligibility rules go by. Tags must already be normalized (see ParseTags).
+// Call it inside a transaction (see InTx) so the attendee never has half
+// their tags.
+func (q *Queries) SetAttendeeTags(ctx context.Context, id int64, tags []string) error {
+	if err := q.DeleteAttendeeTags(ctx, id); err != nil {
+		return fmt.Errorf("clear tags: %w", err)
+	}
+	for _, tag := range tags {
+		if err := q.AddAttendeeTag(ctx, AddAttendeeTagParams{AttendeeID: id, Tag: tag}); err != nil {
+			return fmt.Errorf("add tag: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Reasons RunoffCandidates gives for a poll needing a runoff
+const (
+	RunoffTie        = "tie"
+	RunoffNoMajority = "no majority"
+)
+
+// RunoffCandidates reports whether a finished single-choice poll needs a
+// runoff and between which options: those tied for first, or if the leader
+// has no majority, the top two (with any tied for second). The reason is
+// empty when the poll has a clear winner. Retired options are left out.
+func (q *Queries) RunoffCandidates(ctx context.Context, cat Category) (string, []Option, error) {
+	if cat.VoteType != "single" || !cat.Finished() {
+		return "", nil, nil
+	}
+
+	rows, err := q.Tally(ctx, cat)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Ballot options by votes, most first; total counts every vote cast,
+	// including for options since retired
+	type tallied struct {
+		Option
+		votes int64
+	}
+	var ranked []tallied
+	var total int64
+	for _, row := range rows {
+		total += row.Score
+		if !row.RetiredAt.Valid && row.Score > 0 {
+			ranked = append(ranked, tallied{row.Option, row.Score})
+		}
+	}
+	if len(ranked) < 2 {
+		return "", nil, nil
+	}
+
+	// within returns the leading options down to the given vote count
+	within := func(votes int64) []Option {
+		var out []Option
+		for _, t := range ranked {
+			if t.votes < votes {
+				break
+			}
+			out = append(out, t.Option)
+		}
+		return out
+	}
+
+	switch {
+	case ranked[0].votes == ranked[1].votes:
+		return RunoffTie, within(ranked[0].votes), nil
+	case ranked[0].votes*2 <= total:
+		return RunoffNoMajority, within(ranked[1].votes), nil
+	}
+	return "", nil, nil
+}
+
+// CreateRunoff creates a draft single-choice poll between options, linked
+// to cat as its runoff and labelled like it. Call it on a Queries bound to a
+// transaction (see WithTx) so a runoff never exists without its options.
+func (q *Queries) CreateRunoff(ctx context.Context, cat Category, options []Option) (Category, []Option, error) {
+	name := cat.Name + " (runoff)"
+	slug, err := q.UniqueSlug(ctx, name, 0)
+	if err != nil {
+		return Category{}, nil, err
+	}
+	runoff, err := q.CreateCategory(ctx, CreateCategoryParams{
+		Name:        name,
+		VoteType:    "single",
+		Status:      "draft",
+		ShowResults: cat.ShowResults,
+		Color:       cat.Color,
+		Icon:        cat.Icon,
+		Slug:        sql.NullString{String: slug, Valid: true},
+		Skin:        cat.Skin,
+		CustomCss:   cat.CustomCss,
+		RevealSound: cat.RevealSound,
+		VotedWall:   cat.VotedWall,
+		Eligibility: cat.Eligibility,
+		Weights:     cat.Weights,
+		Method:      cat.Method,
+		Judges:      cat.Judges,
+		JudgeWeight: cat.JudgeWeight,
+	})
+	if err != nil {
+		return runoff, nil, fmt.Errorf("create poll: %w", err)
+	}
+
+	runoff.RunoffOf = sql.NullInt64{Int64: cat.ID, Valid: true}
+	err = q.SetCategoryRunoffOf(ctx, SetCategoryRunoffOfParams{RunoffOf: runoff.RunoffOf, ID: runoff.ID})
+	if err != nil {
+		return runoff, nil, fmt.Errorf("link poll: %w", err)
+	}
+
+	added, err := q.SeedOptions(ctx, runoff, options)
+	return runoff, added, err
+}
@@ -8,7 +8,26 @@ CREATE TABLE categories (
   status        TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'open', 'closed', 'archived')),
   show_results  TEXT NOT NULL DEFAULT 'after_close' CHECK (show_results IN ('live', 'after_close')),
   max_rank      INTEGER,
-  created_at    DATETIME DEFAULT CURRENT_TIMESTAMP
+  created_at    DATETIME DEFAULT CURRENT_TIMESTAMP,
+  color         TEXT NOT NULL DEFAULT '',
+  icon          TEXT NOT NULL DEFAULT '',
+  depends_on    INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+  seed_top_n    INTEGER NOT NULL DEFAULT 0,
+  runoff_of     INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+  closes_at     DATETIME,
+  slug          TEXT,
+  opens_at      DATETIME,
+  skin          TEXT NOT NULL DEFAULT '',
+  custom_css    TEXT NOT NULL DEFAULT '',
+  reveal_sound  TEXT NOT NULL DEFAULT '',
+  voted_wall    TEXT NOT NULL DEFAULT '',
+  results_version INTEGER NOT NULL DEFAULT 0, -- bumped by triggers whenever the tally could change
+  test_mode   BOOLEAN NOT NULL DEFAULT FALSE, -- admins may cast throwaway ballots while a draft
+  eligibility TEXT NOT NULL DEFAULT '', -- roster tags a voter needs or mustn't have (db.Eligibility)
+  weights     TEXT NOT NULL DEFAULT '', -- ballots counted more than once by roster tag (db.TagWeights)
+  method      TEXT NOT NULL DEFAULT '', -- counting method from tally.Methods; empty for votes or points by vote type
+  judges      TEXT NOT NULL DEFAULT '', -- roster tag whose ballots are scored apart from the audience's; empty when not judged
+  judge_weight INTEGER NOT NULL DEFAULT 50 -- percent of a judged poll's result the judges decide
 );
 
 CREATE TABLE options (
@@ -16,6 +35,12 @@ CREATE TABLE options (
   category_id INTEGER NOT NULL,
   name        TEXT NOT NULL,
   sort_order  INTEGER DEFAULT 0,
+  retired_at  DATETIME,
+  seeded_from INTEGER REFERENCES options(id) ON DELETE SET NULL,
+  image       TEXT NOT NULL DEFAULT '',
+  blurb       TEXT NOT NULL DEFAULT '',
+  video       TEXT NOT NULL DEFAULT '', -- http(s) link to a trailer or gameplay video
+  screenshots TEXT NOT NULL DEFAULT '', -- image URLs, one per line
   FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
 );
 
@@ -24,6 +49,10 @@ CREATE TABLE votes (
   category_id INTEGER NOT NULL,
   nickname    TEXT NOT NULL,
   created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+  version     INTEGER NOT NULL DEFAULT 1,
+  remote      BOOLEAN NOT NULL DEFAULT FALSE,
+  invalid     TEXT NOT NULL DEFAULT '', -- why an admin invalidated the ballot (db.InvalidateBallots); empty while it counts
+  counted_until DATETIME, -- count the ballot as it stood then, the official window's end (db.InvalidateBallots); NULL counts the current one
   UNIQUE(category_id, nickname),
   FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
 );
@@ -37,8 +66,230 @@ CREATE TABLE vote_selections (
   FOREIGN KEY (option_id) REFERENCES options(id) ON DELETE CASCADE
 );
 
+CREATE TABLE vote_selection_history (
+  id          INTEGER PRIMARY KEY,
+  vote_id     INTEGER NOT NULL,
+  version     INTEGER NOT NULL,
+  option_id   INTEGER NOT NULL,
+  rank        INTEGER,
+  replaced_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (vote_id) REFERENCES votes(id) ON DELETE CASCADE,
+  FOREIGN KEY (option_id) REFERENCES options(id) ON DELETE CASCADE
+);
+
+CREATE TABLE vote_conflicts (
+  id                INTEGER PRIMARY KEY,
+  vote_id           INTEGER NOT NULL,
+  version           INTEGER NOT NULL, -- the later ballot; the earlier is version - 1 in history
+  first_station_id  INTEGER,
+  first_address     TEXT NOT NULL DEFAULT '',
+  second_station_id INTEGER,
+  second_address    TEXT NOT NULL DEFAULT '',
+  created_at        DATETIME DEFAULT CURRENT_TIMESTAMP,
+  resolved_at       DATETIME,
+  kept              TEXT NOT NULL DEFAULT '', -- 'first' or 'second' once resolved
+  FOREIGN KEY (vote_id) REFERENCES votes(id) ON DELETE CASCADE
+);
+
+CREATE TABLE audit_events (
+  id          INTEGER PRIMARY KEY,
+  actor       TEXT NOT NULL,
+  action      TEXT NOT NULL,
+  category_id INTEGER,
+  detail      TEXT NOT NULL DEFAULT '',
+  created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+  station_id  INTEGER, -- stations are never deleted, only revoked
+  address     TEXT NOT NULL DEFAULT '', -- client address, for ballots
+  FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL
+);
+
+CREATE TABLE idempotency_keys (
+  key         TEXT PRIMARY KEY,
+  category_id INTEGER NOT NULL,
+  nickname    TEXT NOT NULL,
+  created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE TABLE encryption_meta (
+  id          INTEGER PRIMARY KEY CHECK (id = 1),
+  salt        BLOB NOT NULL,
+  check_value TEXT NOT NULL,
+  created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE avatar_salt (
+  id   INTEGER PRIMARY KEY CHECK (id = 1),
+  salt BLOB NOT NULL
+);
+
+CREATE TABLE observer_key (
+  id  INTEGER PRIMARY KEY CHECK (id = 1),
+  key BLOB NOT NULL -- signs observer links (db.SignObserver); replaced to revoke them all
+);
+
+-- Options' video titles and thumbnails from oEmbed, keyed by the video
+-- site's own link (oembed.Video.Canonical)
+CREATE TABLE media_previews (
+  link       TEXT PRIMARY KEY,
+  provider   TEXT NOT NULL,
+  title      TEXT NOT NULL DEFAULT '',
+  author     TEXT NOT NULL DEFAULT '',
+  thumbnail  TEXT NOT NULL DEFAULT '',
+  fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE season_events (
+  id           INTEGER PRIMARY KEY,
+  name         TEXT NOT NULL,
+  finalized_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Where each option placed when an event was finalized (db.FinalizeEvent).
+-- No foreign key to categories: the season outlives the polls.
+CREATE TABLE season_points (
+  event_id    INTEGER NOT NULL REFERENCES season_events(id) ON DELETE CASCADE,
+  category_id INTEGER NOT NULL,
+  poll        TEXT NOT NULL,
+  place       INTEGER NOT NULL,
+  name        TEXT NOT NULL,
+  points      INTEGER NOT NULL
+);
+CREATE INDEX idx_season_points_category ON season_points(category_id);
+
+CREATE TABLE admin_credentials (
+  id         INTEGER PRIMARY KEY CHECK (id = 1),
+  salt       BLOB NOT NULL,
+  hash       BLOB NOT NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE stations (
+  id           INTEGER PRIMARY KEY,
+  name         TEXT NOT NULL UNIQUE COLLATE NOCASE,
+  pairing_hash BLOB UNIQUE,
+  device_hash  BLOB UNIQUE,
+  created_at   DATETIME DEFAULT CURRENT_TIMESTAMP,
+  paired_at    DATETIME,
+  revoked_at   DATETIME
+);
+
+CREATE TABLE attendees (
+  id        INTEGER PRIMARY KEY,
+  nickname  TEXT NOT NULL UNIQUE,
+  seat      TEXT NOT NULL DEFAULT '',
+  synced_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE attendee_tags (
+  attendee_id INTEGER NOT NULL,
+  tag         TEXT NOT NULL,
+  PRIMARY KEY (attendee_id, tag),
+  FOREIGN KEY (attendee_id) REFERENCES attendees(id) ON DELETE CASCADE
+);
+
+CREATE TABLE ballot_purges (
+  category_id INTEGER PRIMARY KEY,
+  votes       INTEGER NOT NULL,
+  purged_at   DATETIME DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE TABLE result_snapshots (
+  category_id INTEGER NOT NULL,
+  option_id   INTEGER NOT NULL,
+  position    INTEGER NOT NULL,
+  score       INTEGER NOT NULL,
+  first_place INTEGER NOT NULL,
+  label       TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (category_id, option_id),
+  FOREIGN KEY (category_id) REFERENCES ballot_purges(category_id) ON DELETE CASCADE,
+  FOREIGN KEY (option_id) REFERENCES options(id) ON DELETE CASCADE
+);
+
+CREATE TABLE settings (
+  key        TEXT PRIMARY KEY,
+  value      TEXT NOT NULL,
+  updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE sessions (
+  id         TEXT PRIMARY KEY,
+  data       TEXT NOT NULL DEFAULT '{}',
+  expires_at DATETIME NOT NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Indexes for query performance
 CREATE INDEX idx_options_category ON options(category_id);
+CREATE UNIQUE INDEX idx_options_category_name ON options(category_id, name COLLATE NOCASE);
 CREATE INDEX idx_votes_category ON votes(category_id);
 CREATE INDEX idx_vote_selections_vote ON vote_selections(vote_id);
 CREATE INDEX idx_vote_selections_option ON vote_selections(option_id);
+CREATE INDEX idx_vote_selection_history_vote ON vote_selection_history(vote_id);
+CREATE INDEX idx_audit_events_created ON audit_events(created_at);
+CREATE INDEX idx_idempotency_keys_created ON idempotency_keys(created_at);
+CREATE INDEX idx_sessions_expires ON sessions(expires_at);
+CREATE UNIQUE INDEX idx_categories_slug ON categories(slug);
+
+-- Triggers bumping categories.results_version
+
+CREATE TRIGGER votes_insert_results_version AFTER INSERT ON votes
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.category_id;
+END;
+
+CREATE TRIGGER votes_update_results_version AFTER UPDATE ON votes
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.category_id;
+END;
+
+CREATE TRIGGER votes_delete_results_version AFTER DELETE ON votes
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = OLD.category_id;
+END;
+
+CREATE TRIGGER vote_selections_insert_results_version AFTER INSERT ON vote_selections
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = (SELECT category_id FROM votes WHERE id = NEW.vote_id);
+END;
+
+CREATE TRIGGER vote_selections_update_results_version AFTER UPDATE ON vote_selections
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = (SELECT category_id FROM votes WHERE id = NEW.vote_id);
+END;
+
+CREATE TRIGGER vote_selections_delete_results_version AFTER DELETE ON vote_selections
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = (SELECT category_id FROM votes WHERE id = OLD.vote_id);
+END;
+
+CREATE TRIGGER options_insert_results_version AFTER INSERT ON options
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.category_id;
+END;
+
+CREATE TRIGGER options_update_results_version AFTER UPDATE ON options
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.category_id;
+END;
+
+CREATE TRIGGER options_delete_results_version AFTER DELETE ON options
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = OLD.category_id;
+END;
+
+CREATE TRIGGER categories_update_results_version AFTER UPDATE OF vote_type, max_rank, weights, method, judges, judge_weight ON categories
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER attendee_tags_insert_results_version AFTER INSERT ON attendee_tags
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE weights != '' OR judges != '';
+END;
+
+CREATE TRIGGER attendee_tags_delete_results_version AFTER DELETE ON attendee_tags
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE weights != '' OR judges != '';
+END;
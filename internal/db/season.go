@@ -0,0 +1,168 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrNothingToFinalize means no finished poll is waiting to be scored for
+// the season
+var ErrNothingToFinalize = errors.New("no finished polls are waiting to be scored")
+
+// ParseSeasonPoints reads the season_points setting: the points first
+// place earns, then second and so on, separated by spaces or commas.
+// Places past the end of the list earn nothing.
+func ParseSeasonPoints(s string) ([]int64, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 {
+		return nil, errors.New("season_points must give first place something, e.g. 3 2 1")
+	}
+	points := make([]int64, len(fields))
+	for i, f := range fields {
+		n, err := strconv.ParseInt(f, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("season_points must be whole numbers like 3 2 1, not %q", f)
+		}
+		points[i] = n
+	}
+	return points, nil
+}
+
+// FinalizeEvent ends an event for the season in one transaction: it
+// records, under name, where every option placed in each finished poll not
+// scored yet and what that place earns from points (first place earning
+// points[0]), archives those polls and records it in the audit log as
+// actor. A poll decided by a
+// runoff is left for its runoff to score, and options nobody voted for earn
+// nothing. The polls scored are returned with the event.
+func FinalizeEvent(ctx context.Context, conn *sql.DB, name string, points []int64, actor string) (SeasonEvent, []Category, error) {
+	var event SeasonEvent
+	var polls []Category
+	err := InTx(ctx, conn, func(q *Queries) error {
+		var err error
+		if polls, err = q.ListUnscoredCategories(ctx); err != nil {
+			return err
+		}
+		if len(polls) == 0 {
+			return &Error{Kind: ErrConflict, Err: ErrNothingToFinalize}
+		}
+		if event, err = q.CreateSeasonEvent(ctx, name); err != nil {
+			return err
+		}
+
+		for _, cat := range polls {
+			tallied, err := q.Tally(ctx, cat)
+			if err != nil {
+				return err
+			}
+			votes, err := q.CountVotesByCategory(ctx, cat.ID)
+			if err != nil {
+				return err
+			}
+			for i, place := range Places(tallied, votes) {
+				var earned int64
+				if place.Rank <= len(points) && tallied[i].Score > 0 {
+					earned = points[place.Rank-1]
+				}
+				err := q.CreateSeasonPoints(ctx, CreateSeasonPointsParams{
+					EventID:    event.ID,
+					CategoryID: cat.ID,
+					Poll:       cat.Name,
+					Place:      int64(place.Rank),
+					Name:       tallied[i].Option.Name,
+					Points:     earned,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if cat.Status != "archived" {
+				if err := q.ArchiveCategory(ctx, cat.ID); err != nil {
+					return err
+				}
+				if err := q.RecordAudit(ctx, actor, AuditCategoryArchive, cat.ID, ""); err != nil {
+					return err
+				}
+			}
+		}
+		return q.RecordAudit(ctx, actor, AuditSeasonFinalize, 0, fmt.Sprintf("%s (%d polls)", name, len(polls)))
+	})
+	return event, polls, err
+}
+
+// SeasonStanding is one name's total across the season. Events holds the
+// points earned at each event, in the order Season returns them; Wins
+// counts first places.
+type SeasonStanding struct {
+	Rank   int
+	Name   string
+	Points int64
+	Wins   int64
+	Events []int64
+}
+
+// Season adds up the points every finalized event awarded, by option name
+// ignoring case and spelled as it was most recently. Names with no points
+// are left out. Most points ranks first, then most wins; names level on
+// both share a rank.
+func (q *Queries) Season(ctx context.Context) ([]SeasonEvent, []SeasonStanding, error) {
+	events, err := q.ListSeasonEvents(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	column := make(map[int64]int, len(events))
+	for i, e := range events {
+		column[e.ID] = i
+	}
+	rows, err := q.ListSeasonPoints(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byName := map[string]*SeasonStanding{}
+	var standings []*SeasonStanding
+	for _, row := range rows {
+		if row.Points == 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(row.Name))
+		s, ok := byName[key]
+		if !ok {
+			s = &SeasonStanding{Events: make([]int64, len(events))}
+			byName[key] = s
+			standings = append(standings, s)
+		}
+		s.Name = row.Name
+		s.Points += row.Points
+		s.Events[column[row.EventID]] += row.Points
+		if row.Place == 1 {
+			s.Wins++
+		}
+	}
+
+	ranked := make([]SeasonStanding, len(standings))
+	for i, s := range standings {
+		ranked[i] = *s
+	}
+	slices.SortStableFunc(ranked, func(a, b SeasonStanding) int {
+		return cmp.Or(
+			cmp.Compare(b.Points, a.Points),
+			cmp.Compare(b.Wins, a.Wins),
+			cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)),
+		)
+	})
+	for i := range ranked {
+		if i > 0 && ranked[i].Points == ranked[i-1].Points && ranked[i].Wins == ranked[i-1].Wins {
+			ranked[i].Rank = ranked[i-1].Rank
+		} else {
+			ranked[i].Rank = i + 1
+		}
+	}
+	return events, ranked, nil
+}
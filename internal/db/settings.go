@@ -0,0 +1,272 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SettingKind is the type of value a setting holds. Values are stored as
+// text and checked against the kind when set.
+type SettingKind string
+
+const (
+	SettingBool   SettingKind = "bool"
+	SettingInt    SettingKind = "int"
+	SettingString SettingKind = "string"
+	SettingTime   SettingKind = "time"
+)
+
+// settingTimeLayouts are the forms a time setting is accepted in, read in
+// the server's local time unless they carry an offset. Values are stored as
+// RFC 3339.
+var settingTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// Setting keys
+const (
+	SettingHighContrast         = "high_contrast"
+	SettingWidgetFrameAncestors = "widget_frame_ancestors"
+	SettingLANOnly              = "lan_only"
+	SettingEventName            = "event_name"
+	SettingLeaderboard          = "leaderboard"
+	SettingCeremonyAt           = "ceremony_at"
+	SettingBallotRetentionDays  = "ballot_retention_days"
+	SettingSeason               = "season"
+	SettingSeasonPoints         = "season_points"
+	SettingNarrowMargin         = "narrow_margin"
+)
+
+// SettingSpec describes a runtime setting for /admin/settings and
+// `votigo settings`
+type SettingSpec struct {
+	Key     string
+	Kind    SettingKind
+	Default string
+	Label   string
+	Help    string
+	// Check, if set, further validates a value of the right kind
+	Check func(value string) error
+}
+
+// SettingSpecs lists every known setting in display order. A feature that
+// needs runtime configuration adds its key here.
+var SettingSpecs = []SettingSpec{
+	{
+		Key:     SettingHighContrast,
+		Kind:    SettingBool,
+		Default: "false",
+		Label:   "High contrast",
+		Help:    "Black and white theme with yellow accents on every page",
+	},
+	{
+		Key:     SettingWidgetFrameAncestors,
+		Kind:    SettingString,
+		Default: "*",
+		Label:   "Widget embedders",
+		Help:    "Sites allowed to embed vote widgets in an iframe, space separated (e.g. http://tournament.lan); * for any, 'none' for none",
+	},
+	{
+		Key:     SettingLANOnly,
+		Kind:    SettingBool,
+		Default: "false",
+		Label:   "LAN only",
+		Help:    "Refuse ballots from outside the local network; when off they are accepted but flagged as remote on the poll's admin page",
+	},
+	{
+		Key:     SettingEventName,
+		Kind:    SettingString,
+		Default: "",
+		Label:   "Event name",
+		Help:    "Shown across the top of results cards, e.g. Palm Arcade LAN 2026",
+	},
+	{
+		Key:     SettingLeaderboard,
+		Kind:    SettingBool,
+		Default: "false",
+		Label:   "Leaderboard",
+		Help:    "Publish /leaderboard, ranking voters by how many polls they voted in",
+	},
+	{
+		Key:     SettingCeremonyAt,
+		Kind:    SettingTime,
+		Default: "",
+		Label:   "Ceremony starts",
+		Help:    "Counts down to the awards ceremony at the top of every page and on the display, e.g. 2026-10-17 21:00; empty for no countdown",
+	},
+	{
+		Key:     SettingBallotRetentionDays,
+		Kind:    SettingInt,
+		Default: "0",
+		Label:   "Ballot retention (days)",
+		Help:    "Delete a poll's ballots this many days after it closes, keeping its results; 0 keeps them forever",
+	},
+	{
+		Key:     SettingSeason,
+		Kind:    SettingString,
+		Default: "",
+		Label:   "Season",
+		Help:    "Name of the season this event counts toward, e.g. Palm Arcade 2026; publishes /season, where poll winners add up across events",
+	},
+	{
+		Key:     SettingSeasonPoints,
+		Kind:    SettingString,
+		Default: "3 2 1",
+		Label:   "Season points",
+		Help:    "Points an option earns toward the season for placing first, second and so on when an event is finalized",
+		Check:   func(value string) error { _, err := ParseSeasonPoints(value); return err },
+	},
+	{
+		Key:     SettingNarrowMargin,
+		Kind:    SettingInt,
+		Default: "1",
+		Label:   "Narrow win (votes)",
+		Help:    "Flag a single-choice or approval poll's winner on the ceremony console, results page and `votigo results` when they won by this many votes or fewer, so the MC can say \"by a single vote!\"; 0 to never flag",
+	},
+}
+
+// ErrUnknownSetting means a key is not in SettingSpecs
+var ErrUnknownSetting = errors.New("unknown setting")
+
+// LookupSetting returns the spec for key
+func LookupSetting(key string) (SettingSpec, error) {
+	for _, spec := range SettingSpecs {
+		if spec.Key == key {
+			return spec, nil
+		}
+	}
+	return SettingSpec{}, fmt.Errorf("%w %q", ErrUnknownSetting, key)
+}
+
+// Parse checks value against the setting's kind and returns it in canonical
+// form, e.g. "on" becomes "true" for a bool. An empty int is its default,
+// as is an empty value for a setting with a Check.
+func (s SettingSpec) Parse(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch s.Kind {
+	case SettingBool:
+		switch strings.ToLower(value) {
+		case "on", "yes":
+			return "true", nil
+		case "off", "no", "":
+			return "false", nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s must be true or false", s.Key)
+		}
+		return strconv.FormatBool(b), nil
+	case SettingInt:
+		if value == "" {
+			return s.Default, nil
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a whole number", s.Key)
+		}
+		return strconv.FormatInt(n, 10), nil
+	case SettingTime:
+		if value == "" {
+			return "", nil
+		}
+		for _, layout := range settingTimeLayouts {
+			if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return t.Format(time.RFC3339), nil
+			}
+		}
+		return "", fmt.Errorf("%s must be a date and time like 2026-10-17 21:00", s.Key)
+	}
+	if s.Check != nil {
+		if value == "" {
+			value = s.Default
+		}
+		if err := s.Check(value); err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+// SettingValue returns the stored value for key, or the default if it was
+// never set
+func (q *Queries) SettingValue(ctx context.Context, key string) (string, error) {
+	spec, err := LookupSetting(key)
+	if err != nil {
+		return "", err
+	}
+	setting, err := q.GetSetting(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return spec.Default, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return setting.Value, nil
+}
+
+// SettingBool returns a bool setting
+func (q *Queries) SettingBool(ctx context.Context, key string) (bool, error) {
+	value, err := q.SettingValue(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	return value == "true", nil
+}
+
+// SettingInt returns an int setting
+func (q *Queries) SettingInt(ctx context.Context, key string) (int64, error) {
+	value, err := q.SettingValue(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// SettingTime returns a time setting, or the zero time if it is empty
+func (q *Queries) SettingTime(ctx context.Context, key string) (time.Time, error) {
+	value, err := q.SettingValue(ctx, key)
+	if err != nil || value == "" {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// SettingValues returns every known setting, defaults filled in
+func (q *Queries) SettingValues(ctx context.Context) (map[string]string, error) {
+	values := make(map[string]string, len(SettingSpecs))
+	for _, spec := range SettingSpecs {
+		values[spec.Key] = spec.Default
+	}
+
+	stored, err := q.ListSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, setting := range stored {
+		// Rows for settings a newer version removed are ignored
+		if _, ok := values[setting.Key]; ok {
+			values[setting.Key] = setting.Value
+		}
+	}
+	return values, nil
+}
+
+// SetSetting validates and stores a setting, returning the value as stored
+func (q *Queries) SetSetting(ctx context.Context, key, value string) (string, error) {
+	spec, err := LookupSetting(key)
+	if err != nil {
+		return "", err
+	}
+	value, err = spec.Parse(value)
+	if err != nil {
+		return "", err
+	}
+	return value, q.UpsertSetting(ctx, UpsertSettingParams{Key: key, Value: value})
+}
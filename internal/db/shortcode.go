@@ -0,0 +1,72 @@
+package db
+
+import "strings"
+
+// shortCodeAlphabet leaves out 0/o, 1/i/l so codes survive being read off
+// a sheet or shouted across a hall
+const shortCodeAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+// Short codes are four characters for the first shortCodeSpace IDs. The ID
+// is scrambled by an affine map over that space so consecutive polls don't
+// get look-alike codes; shortCodeInverse undoes the multiplication.
+const (
+	shortCodeLen     = 4
+	shortCodeSpace   = 31 * 31 * 31 * 31
+	shortCodeFactor  = 524287
+	shortCodeInverse = 434122
+	shortCodeOffset  = 271828
+)
+
+// ShortCode returns the short link code for category id. Codes are derived
+// from the ID, so every poll has one without storing it; IDs past the
+// four-character space get longer, unscrambled codes.
+func ShortCode(id int64) string {
+	if id <= 0 {
+		return ""
+	}
+	v, width := id, 0
+	if id < shortCodeSpace {
+		v, width = (id*shortCodeFactor+shortCodeOffset)%shortCodeSpace, shortCodeLen
+	}
+
+	var buf []byte
+	for v > 0 || len(buf) < width {
+		buf = append(buf, shortCodeAlphabet[v%int64(len(shortCodeAlphabet))])
+		v /= int64(len(shortCodeAlphabet))
+	}
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return string(buf)
+}
+
+// ShortCodeID returns the category ID a short code stands for. Codes are
+// read case-insensitively; ok is false for anything ShortCode can't produce.
+func ShortCodeID(code string) (id int64, ok bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if len(code) < shortCodeLen || len(code) > 12 {
+		return 0, false
+	}
+
+	var v int64
+	for _, r := range code {
+		digit := strings.IndexRune(shortCodeAlphabet, r)
+		if digit < 0 {
+			return 0, false
+		}
+		v = v*int64(len(shortCodeAlphabet)) + int64(digit)
+	}
+
+	id = v
+	if len(code) == shortCodeLen {
+		id = ((v - shortCodeOffset + shortCodeSpace) % shortCodeSpace) * shortCodeInverse % shortCodeSpace
+	} else if v < shortCodeSpace {
+		return 0, false
+	}
+	return id, id > 0 && ShortCode(id) == code
+}
+
+// ShortCode returns c's short link code (see ShortCode)
+func (c Category) ShortCode() string {
+	return ShortCode(c.ID)
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/palm-arcade/votigo/internal/slug"
+)
+
+// SlugTaken reports whether a category other than id (0 for one not created
+// yet) already uses slug
+func (q *Queries) SlugTaken(ctx context.Context, slug string, id int64) (bool, error) {
+	n, err := q.CountCategoriesWithSlug(ctx, CountCategoriesWithSlugParams{
+		Slug: sql.NullString{String: slug, Valid: true},
+		ID:   id,
+	})
+	return n > 0, err
+}
+
+// UniqueSlug returns the slug for name, numbered "-2", "-3" and so on past
+// any already used by a category other than id
+func (q *Queries) UniqueSlug(ctx context.Context, name string, id int64) (string, error) {
+	base := slug.Make(name)
+	for n := 1; ; n++ {
+		candidate := slug.Numbered(base, n)
+		taken, err := q.SlugTaken(ctx, candidate, id)
+		if err != nil {
+			return "", fmt.Errorf("check slug: %w", err)
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+}
+
+// CategoryByRef looks up a category by the reference used in voter URLs:
+// its ID, or its slug. Unknown references return ErrNotFound.
+func (q *Queries) CategoryByRef(ctx context.Context, ref string) (Category, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return q.Category(ctx, id)
+	}
+	cat, err := q.GetCategoryBySlug(ctx, sql.NullString{String: ref, Valid: true})
+	return cat, Classify(err)
+}
+
+// Ref returns the reference voter URLs use for c: its slug, or its ID for a
+// poll without one
+func (c Category) Ref() string {
+	if c.Slug.Valid && c.Slug.String != "" {
+		return c.Slug.String
+	}
+	return strconv.FormatInt(c.ID, 10)
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"strings"
+)
+
+// ErrRevoked is returned for the token of a station an admin revoked
+var ErrRevoked = errors.New("this station has been revoked")
+
+// tokenHash is how a station token is stored
+func tokenHash(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// Paired reports whether a kiosk has taken up the station's pairing token
+func (s Station) Paired() bool {
+	return s.PairedAt.Valid
+}
+
+// Revoked reports whether an admin revoked the station
+func (s Station) Revoked() bool {
+	return s.RevokedAt.Valid
+}
+
+// AddStation creates a station called name and returns it with the
+// one-time token a kiosk pairs with. A name already taken is ErrConflict.
+func (q *Queries) AddStation(ctx context.Context, name string) (Station, string, error) {
+	token := rand.Text()
+	st, err := q.CreateStation(ctx, CreateStationParams{Name: strings.TrimSpace(name), PairingHash: tokenHash(token)})
+	return st, token, Classify(err)
+}
+
+// ResetPairing unpairs station id, un-revoking it, and returns it with a
+// new pairing token. A missing station is ErrNotFound.
+func (q *Queries) ResetPairing(ctx context.Context, id int64) (Station, string, error) {
+	st, err := q.Station(ctx, id)
+	if err != nil {
+		return st, "", err
+	}
+	token := rand.Text()
+	return st, token, q.ResetStationPairing(ctx, ResetStationPairingParams{PairingHash: tokenHash(token), ID: id})
+}
+
+// Station looks up a station by ID. A missing one is ErrNotFound.
+func (q *Queries) Station(ctx context.Context, id int64) (Station, error) {
+	st, err := q.GetStation(ctx, id)
+	return st, Classify(err)
+}
+
+// Pair takes up a station's pairing token, which then no longer works, and
+// returns the station with the device token the kiosk presents from now
+// on. An unknown or used token is ErrNotFound. Call it inside a transaction
+// (see InTx) so two kiosks can't pair with one token.
+func (q *Queries) Pair(ctx context.Context, pairingToken string) (Station, string, error) {
+	st, err := q.GetStationByPairingHash(ctx, tokenHash(pairingToken))
+	if err != nil {
+		return st, "", Classify(err)
+	}
+	token := rand.Text()
+	if err := q.PairStation(ctx, PairStationParams{DeviceHash: tokenHash(token), ID: st.ID}); err != nil {
+		return st, "", err
+	}
+	st, err = q.Station(ctx, st.ID)
+	return st, token, err
+}
+
+// StationByToken returns the station a device token was issued to. An
+// unknown token is ErrNotFound and a revoked station's is ErrRevoked.
+func (q *Queries) StationByToken(ctx context.Context, deviceToken string) (Station, error) {
+	st, err := q.GetStationByDeviceHash(ctx, tokenHash(deviceToken))
+	if err != nil {
+		return st, Classify(err)
+	}
+	if st.Revoked() {
+		return st, ErrRevoked
+	}
+	return st, nil
+}
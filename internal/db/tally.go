@@ -0,0 +1,397 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+// TalliedOption is an option with its place in a poll's result
+type TalliedOption struct {
+	Option
+	tally.Standing
+}
+
+// Places returns where each tallied option finished, sharing places
+// between ties (see tally.Places). Before anyone has voted nothing is tied;
+// options are numbered in the order they are listed.
+func Places(tallied []TalliedOption, votes int64) []tally.Place {
+	if votes == 0 {
+		places := make([]tally.Place, len(tallied))
+		for i := range places {
+			places[i].Rank = i + 1
+		}
+		return places
+	}
+	standings := make([]tally.Standing, len(tallied))
+	for i, t := range tallied {
+		standings[i] = t.Standing
+	}
+	return tally.Places(standings)
+}
+
+// Margin is how many votes a poll's winner finished ahead of the
+// runner-up by, out of the ballots cast. A Narrow win is one the MC should
+// mention: by no more votes than the narrow_margin setting.
+type Margin struct {
+	Lead   int64
+	Votes  int64
+	Narrow bool
+}
+
+// Percent is the lead as a whole percentage of the ballots cast
+func (m Margin) Percent() int64 {
+	if m.Votes == 0 {
+		return 0
+	}
+	return m.Lead * 100 / m.Votes
+}
+
+// String says what the winner won by, like "by 1 vote (2% of 40 ballots)"
+func (m Margin) String() string {
+	unit := "votes"
+	if m.Lead == 1 {
+		unit = "vote"
+	}
+	return fmt.Sprintf("by %d %s (%d%% of %d ballots)", m.Lead, unit, m.Percent(), m.Votes)
+}
+
+// CountedByVotes reports whether a poll's result is plain vote counts:
+// single-choice and approval polls that are neither judged nor counted by
+// another method
+func (c Category) CountedByVotes() bool {
+	return c.VoteType != "ranked" && c.Method == "" && !c.Judged()
+}
+
+// NewMargin returns the margin between the first and second place vote
+// counts. There is none before anyone has voted or while first place is
+// tied.
+func NewMargin(first, second, votes, narrow int64) (Margin, bool) {
+	lead := first - second
+	if votes == 0 || lead <= 0 {
+		return Margin{}, false
+	}
+	return Margin{Lead: lead, Votes: votes, Narrow: lead <= narrow}, true
+}
+
+// WinningMargin returns how far the winner of a poll counted by votes (see
+// CountedByVotes) leads its tally, best first. Other polls have none.
+func WinningMargin(cat Category, tallied []TalliedOption, votes, narrow int64) (Margin, bool) {
+	if !cat.CountedByVotes() || len(tallied) == 0 {
+		return Margin{}, false
+	}
+	var second int64
+	if len(tallied) > 1 {
+		second = tallied[1].Score
+	}
+	return NewMargin(tallied[0].Score, second, votes, narrow)
+}
+
+// Ballots loads a poll's current ballots, one per voter. Single and approval
+// selections have no rank and count as first choices. Weights aren't
+// applied; see WeightedBallots.
+func (q *Queries) Ballots(ctx context.Context, categoryID int64) ([]tally.Ballot, error) {
+	ballots, _, err := q.ballots(ctx, categoryID)
+	return ballots, err
+}
+
+// ballots loads a poll's ballots like Ballots, with the ID of the vote each
+// came from. A ballot counted until its official window's end counts as
+// it stood then (see InvalidateBallots).
+func (q *Queries) ballots(ctx context.Context, categoryID int64) ([]tally.Ballot, []int64, error) {
+	ballots, voteIDs, err := q.currentBallots(ctx, categoryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	votes, err := q.ListVotesByCategory(ctx, categoryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !slices.ContainsFunc(votes, func(v Vote) bool { return v.CountedUntil.Valid }) {
+		return ballots, voteIDs, nil
+	}
+
+	cast, err := q.castBallots(ctx, categoryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	ballots, voteIDs = nil, nil
+	for _, v := range votes {
+		versions := cast[v.ID]
+		if len(versions) == 0 || len(versions[len(versions)-1].ballot) == 0 {
+			continue
+		}
+		ballots = append(ballots, versions[len(versions)-1].ballot)
+		voteIDs = append(voteIDs, v.ID)
+	}
+	return ballots, voteIDs, nil
+}
+
+// currentBallots loads a poll's ballots like ballots, each as its voter
+// last cast it
+func (q *Queries) currentBallots(ctx context.Context, categoryID int64) ([]tally.Ballot, []int64, error) {
+	rows, err := q.ListSelectionsByCategory(ctx, categoryID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ballots []tally.Ballot
+	var voteIDs []int64
+	for _, row := range rows {
+		if len(voteIDs) == 0 || row.VoteID != voteIDs[len(voteIDs)-1] {
+			ballots = append(ballots, tally.Ballot{})
+			voteIDs = append(voteIDs, row.VoteID)
+		}
+		rank := int64(1)
+		if row.Rank.Valid {
+			rank = row.Rank.Int64
+		}
+		ballots[len(ballots)-1][row.OptionID] = rank
+	}
+	return ballots, voteIDs, nil
+}
+
+// Tally counts a poll the way its results are published: votes for single
+// and approval polls, points for ranked ones. Every option is listed, best
+// first, including retired options, which keep the votes they had. Ballots
+// are weighted by the poll's tag weights (see WeightedBallots), and a
+// judged poll ranks by its judges' and audience's scores combined (see
+// JudgeScores). A poll whose ballots were purged reads the snapshot taken
+// when they were.
+func (q *Queries) Tally(ctx context.Context, cat Category) ([]TalliedOption, error) {
+	options, err := q.ListOptionsByCategory(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]Option, len(options))
+	for _, o := range options {
+		byID[o.ID] = o
+	}
+
+	snapshot, err := q.ListResultSnapshot(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshot) > 0 {
+		tallied := make([]TalliedOption, len(snapshot))
+		for i, s := range snapshot {
+			tallied[i] = TalliedOption{byID[s.OptionID], tally.Standing{
+				OptionID: s.OptionID, Score: s.Score, FirstPlace: s.FirstPlace, Label: s.Label,
+			}}
+		}
+		return tallied, nil
+	}
+
+	ballots, voteIDs, err := q.ballots(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	return q.count(ctx, cat, options, ballots, voteIDs)
+}
+
+// count ranks options by ballots, each from the vote with the matching ID
+// in voteIDs, weighted and judged the way Tally counts cat
+func (q *Queries) count(ctx context.Context, cat Category, options []Option, ballots []tally.Ballot, voteIDs []int64) ([]TalliedOption, error) {
+	var result tally.Result
+	if cat.Judged() {
+		jury, audience, err := q.judgedResults(ctx, cat, options, ballots, voteIDs)
+		if err != nil {
+			return nil, err
+		}
+		result = tally.Combine(TallyOptions(options), jury, audience, cat.JudgeWeight)
+	} else {
+		weighted, err := q.weightedBallots(ctx, cat, ballots, voteIDs)
+		if err != nil {
+			return nil, err
+		}
+		result = cat.TallyMethod()(TallyOptions(options), weighted)
+	}
+	byID := make(map[int64]Option, len(options))
+	for _, o := range options {
+		byID[o.ID] = o
+	}
+	tallied := make([]TalliedOption, len(result.Ranking))
+	for i, s := range result.Ranking {
+		tallied[i] = TalliedOption{byID[s.OptionID], s}
+	}
+	return tallied, nil
+}
+
+// castBallot is one of the ballots a voter has had in a poll and when it
+// was cast. It stood until the voter's next ballot was.
+type castBallot struct {
+	version int64
+	at      time.Time
+	ballot  tally.Ballot
+}
+
+// castBallots rebuilds every ballot each counted voter in a poll has had,
+// by vote ID and oldest first: the ones they replaced, kept in the vote's
+// history, then their current one. A ballot was cast when the one before
+// it was replaced, and a voter's only ballot when they voted. Their first
+// of several was cast when they last voted before replacing it, going by
+// the audit log. A ballot counted until its official window's end stops
+// at the one that stood then; those cast since are left out.
+func (q *Queries) castBallots(ctx context.Context, categoryID int64) (map[int64][]castBallot, error) {
+	votes, err := q.ListVotesByCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := q.ListSelectionHistoryByCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	times, err := q.ListVoteTimesByCategory(ctx, sql.NullInt64{Int64: categoryID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	current, voteIDs, err := q.currentBallots(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	voted := make(map[string][]time.Time)
+	for _, t := range times {
+		if t.CreatedAt.Valid {
+			voted[t.Actor] = append(voted[t.Actor], t.CreatedAt.Time)
+		}
+	}
+	// Each replaced ballot, and when it was replaced
+	replaced := make(map[int64][]castBallot)
+	for _, h := range history {
+		versions := replaced[h.VoteID]
+		if len(versions) == 0 || versions[len(versions)-1].version != h.Version {
+			versions = append(versions, castBallot{version: h.Version, at: h.ReplacedAt.Time, ballot: tally.Ballot{}})
+		}
+		rank := int64(1)
+		if h.Rank.Valid {
+			rank = h.Rank.Int64
+		}
+		versions[len(versions)-1].ballot[h.OptionID] = rank
+		replaced[h.VoteID] = versions
+	}
+	ballots := make(map[int64]tally.Ballot, len(current))
+	for i, b := range current {
+		ballots[voteIDs[i]] = b
+	}
+
+	cast := make(map[int64][]castBallot, len(votes))
+	for _, v := range votes {
+		if v.Invalid != "" {
+			continue
+		}
+		versions := replaced[v.ID]
+		last := castBallot{version: v.Version, at: v.CreatedAt.Time, ballot: ballots[v.ID]}
+		if len(versions) > 0 {
+			// Shift each replacement time onto the ballot that replaced it
+			last.at = versions[len(versions)-1].at
+			for i := len(versions) - 1; i > 0; i-- {
+				versions[i].at = versions[i-1].at
+			}
+			firstReplaced := versions[0].at
+			for _, at := range voted[v.Nickname] {
+				if at.Before(firstReplaced) {
+					versions[0].at = at
+				}
+			}
+		}
+		versions = append(versions, last)
+		if v.CountedUntil.Valid {
+			versions = slices.DeleteFunc(versions, func(b castBallot) bool { return !b.at.Before(v.CountedUntil.Time) })
+		}
+		cast[v.ID] = versions
+	}
+	return cast, nil
+}
+
+// standingAt returns the ballot that stood just before to among a voter's
+// ballots, oldest first (see castBallots), or their current one for a zero
+// to. It reports false if they hadn't voted yet.
+func standingAt(ballots []castBallot, to time.Time) (castBallot, bool) {
+	if len(ballots) == 0 {
+		return castBallot{}, false
+	}
+	if to.IsZero() {
+		return ballots[len(ballots)-1], true
+	}
+	for i := len(ballots) - 1; i >= 0; i-- {
+		if ballots[i].at.Before(to) {
+			return ballots[i], true
+		}
+	}
+	return castBallot{}, false
+}
+
+// TallyBetween counts cat the way Tally does from only the ballots that
+// stood just before to and were cast at or after from, for seeing how a
+// poll would have gone had it closed earlier or opened later. A zero from
+// or to leaves that end open. A voter who changed their ballot since to
+// counts with the one they had then, and one counted until an earlier time
+// with the one they had at that (see castBallots). Votes is how many
+// ballots were counted, unweighted; a poll whose ballots were purged has
+// none left. Invalidated ballots never count.
+func (q *Queries) TallyBetween(ctx context.Context, cat Category, from, to time.Time) (tallied []TalliedOption, votes int64, err error) {
+	options, err := q.ListOptionsByCategory(ctx, cat.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	cast, err := q.castBallots(ctx, cat.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	var ballots []tally.Ballot
+	var voteIDs []int64
+	for _, voteID := range slices.Sorted(maps.Keys(cast)) {
+		b, ok := standingAt(cast[voteID], to)
+		if !ok || (!from.IsZero() && b.at.Before(from)) {
+			continue
+		}
+		votes++
+		if len(b.ballot) > 0 {
+			ballots = append(ballots, b.ballot)
+			voteIDs = append(voteIDs, voteID)
+		}
+	}
+	tallied, err = q.count(ctx, cat, options, ballots, voteIDs)
+	return tallied, votes, err
+}
+
+// TallyMethod is how the poll's published result is counted: by its method
+// if it has one that is registered, otherwise votes or points by vote type
+// (see DefaultMethod)
+func (c Category) TallyMethod() tally.Method {
+	if m, ok := tally.Methods[c.Method]; ok {
+		return m
+	}
+	if c.VoteType != "ranked" {
+		return tally.Simple
+	}
+	maxRank := int64(3)
+	if c.MaxRank.Valid {
+		maxRank = c.MaxRank.Int64
+	}
+	return tally.Points(maxRank)
+}
+
+// DefaultMethod names how the poll is counted without a method: "votes",
+// or "points" for ranked polls
+func (c Category) DefaultMethod() string {
+	if c.VoteType == "ranked" {
+		return "points"
+	}
+	return "votes"
+}
+
+// TallyOptions lists options in the form the tally package counts
+func TallyOptions(options []Option) []tally.Option {
+	out := make([]tally.Option, len(options))
+	for i, o := range options {
+		out[i] = tally.Option{ID: o.ID, Name: o.Name}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotDraft refuses test mode for a poll that has opened. It comes
+// wrapped as ErrConflict.
+var ErrNotDraft = errors.New("only a draft poll has a test mode")
+
+// Testing reports whether admins may cast test ballots in the poll: it is a
+// draft with test mode on
+func (c Category) Testing() bool {
+	return c.Status == "draft" && c.TestMode
+}
+
+// SetTestMode turns test mode on or off for category id and returns the
+// poll. A poll that isn't a draft is ErrConflict. Test ballots already cast
+// stay until the poll opens.
+func (q *Queries) SetTestMode(ctx context.Context, id int64, on bool) (Category, error) {
+	cat, err := q.Category(ctx, id)
+	if err != nil {
+		return cat, err
+	}
+	if cat.Status != "draft" {
+		return cat, &Error{Kind: ErrConflict, Err: ErrNotDraft}
+	}
+	if err := q.SetCategoryTestMode(ctx, SetCategoryTestModeParams{TestMode: on, ID: id}); err != nil {
+		return cat, err
+	}
+	cat.TestMode = on
+	return cat, nil
+}
+
+// purgeTestBallots deletes the ballots cast in draft poll cat, which can
+// only be test ballots, as it opens, and turns test mode off. The purge is
+// recorded in the audit log as actor when there was anything to delete.
+func purgeTestBallots(ctx context.Context, q *Queries, cat Category, actor string) error {
+	if cat.Status != "draft" {
+		return nil
+	}
+	n, err := q.DeleteVotesByCategory(ctx, cat.ID)
+	if err != nil {
+		return fmt.Errorf("delete test ballots: %w", err)
+	}
+	if err := q.DeleteIdempotencyKeysByCategory(ctx, cat.ID); err != nil {
+		return fmt.Errorf("delete idempotency keys: %w", err)
+	}
+	if err := q.SetCategoryTestMode(ctx, SetCategoryTestModeParams{TestMode: false, ID: cat.ID}); err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+	return q.RecordAudit(ctx, actor, AuditTestPurge, cat.ID, fmt.Sprintf("%d test ballot(s)", n))
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// ForgottenActor replaces a forgotten voter's nickname in the audit log, so
+// vote counts over time survive without identifying anyone.
+const ForgottenActor = "[forgotten]"
+
+// ForgetVoter deletes every ballot cast under nickname in every category,
+// including previous ballot versions and idempotency keys, takes them off
+// the roster and anonymizes their vote events in the audit log. It returns the number of ballots
+// removed. An archived poll's results are final, so ballots there are kept
+// under ForgottenActor and the vote's ID instead. Call it on a Queries bound
+// to a transaction (see WithTx) so a voter is never left half-forgotten.
+func (q *Queries) ForgetVoter(ctx context.Context, nickname string) (int64, error) {
+	if err := q.DeleteVoteSelectionsByNickname(ctx, nickname); err != nil {
+		return 0, fmt.Errorf("delete selections: %w", err)
+	}
+	if err := q.DeleteVoteHistoryByNickname(ctx, nickname); err != nil {
+		return 0, fmt.Errorf("delete history: %w", err)
+	}
+	ballots, err := q.DeleteVotesByNickname(ctx, nickname)
+	if err != nil {
+		return 0, fmt.Errorf("delete votes: %w", err)
+	}
+	_, err = q.AnonymizeArchivedVotes(ctx, AnonymizeArchivedVotesParams{
+		Replacement: ForgottenActor,
+		Nickname:    nickname,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("anonymize archived votes: %w", err)
+	}
+	if err := q.DeleteIdempotencyKeysByNickname(ctx, nickname); err != nil {
+		return 0, fmt.Errorf("delete idempotency keys: %w", err)
+	}
+	if err := q.DeleteAttendee(ctx, nickname); err != nil {
+		return 0, fmt.Errorf("delete attendee: %w", err)
+	}
+	_, err = q.AnonymizeVoteAuditEvents(ctx, AnonymizeVoteAuditEventsParams{
+		Replacement: ForgottenActor,
+		Actor:       nickname,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("anonymize audit events: %w", err)
+	}
+	return ballots, nil
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+// MaxTagWeight bounds how many times one ballot can count
+const MaxTagWeight = 100
+
+// TagWeights counts the ballots of voters with some roster tags more than
+// once, written as tag=weight pairs separated by spaces: "jury=3" counts
+// each jury member's ballot three times. A voter with several weighted tags
+// counts with the largest; everyone else counts once. The empty expression
+// weighs every ballot the same.
+type TagWeights map[string]int64
+
+// ParseTagWeights reads a weights expression. Errors are phrased for
+// showing to an admin.
+func ParseTagWeights(expr string) (TagWeights, error) {
+	w := TagWeights{}
+	for _, term := range strings.Fields(expr) {
+		name, n, ok := strings.Cut(term, "=")
+		if !ok {
+			return nil, fmt.Errorf("Weights: %q should be tag=weight", term)
+		}
+		tag, err := NormalizeTag(name)
+		if err != nil {
+			return nil, fmt.Errorf("Weights: %w", err)
+		}
+		weight, err := strconv.ParseInt(n, 10, 64)
+		if err != nil || weight < 1 || weight > MaxTagWeight {
+			return nil, fmt.Errorf("Weights: %s's weight must be a whole number from 1 to %d", tag, MaxTagWeight)
+		}
+		if _, dup := w[tag]; dup {
+			return nil, fmt.Errorf("Weights: %s is weighted twice", tag)
+		}
+		w[tag] = weight
+	}
+	return w, nil
+}
+
+// String writes w back as an expression, sorted by tag, the form it is
+// stored in
+func (w TagWeights) String() string {
+	terms := make([]string, 0, len(w))
+	for _, tag := range slices.Sorted(maps.Keys(w)) {
+		terms = append(terms, fmt.Sprintf("%s=%d", tag, w[tag]))
+	}
+	return strings.Join(terms, " ")
+}
+
+// Weight is how many times a ballot from a voter with tags counts
+func (w TagWeights) Weight(tags []string) int64 {
+	weight := int64(1)
+	for _, tag := range tags {
+		weight = max(weight, w[tag])
+	}
+	return weight
+}
+
+// WeightedBallots loads cat's ballots like Ballots, repeating each one as
+// many times as cat's weights say its voter's roster tags count for now
+func (q *Queries) WeightedBallots(ctx context.Context, cat Category) ([]tally.Ballot, error) {
+	ballots, voteIDs, err := q.ballots(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	return q.weightedBallots(ctx, cat, ballots, voteIDs)
+}
+
+// weightedBallots weights ballots like WeightedBallots, each from the vote
+// with the matching ID in voteIDs
+func (q *Queries) weightedBallots(ctx context.Context, cat Category, ballots []tally.Ballot, voteIDs []int64) ([]tally.Ballot, error) {
+	weights, err := ParseTagWeights(cat.Weights)
+	if err != nil {
+		return nil, err
+	}
+	if cat.Weights == "" {
+		return ballots, nil
+	}
+	tags, err := q.voteTags(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var weighted []tally.Ballot
+	for i, b := range ballots {
+		for range weights.Weight(tags[voteIDs[i]]) {
+			weighted = append(weighted, b)
+		}
+	}
+	return weighted, nil
+}
+
+// voteTags maps the ID of each vote in a poll to its voter's roster tags now
+func (q *Queries) voteTags(ctx context.Context, categoryID int64) (map[int64][]string, error) {
+	rows, err := q.ListVoteTagsByCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[int64][]string)
+	for _, row := range rows {
+		tags[row.VoteID] = append(tags[row.VoteID], row.Tag)
+	}
+	return tags, nil
+}
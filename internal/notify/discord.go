@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/card"
+)
+
+func init() {
+	Register("discord", func(target string) (Notifier, error) {
+		return ParseDiscord(target)
+	})
+}
+
+// Discord posts events to a Discord channel webhook. Results come with the
+// results card attached as an image.
+type Discord struct {
+	WebhookURL string
+	Templates  Templates
+	Client     *http.Client
+}
+
+// ParseDiscord builds a Discord notifier from a webhook URL of the form
+// https://discord.com/api/webhooks/ID/TOKEN
+func ParseDiscord(target string) (*Discord, error) {
+	// Don't echo the target in errors; it carries the webhook token
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL")
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return nil, fmt.Errorf("webhook must be an http(s) URL")
+	}
+	if !strings.Contains(u.Path, "/api/webhooks/") {
+		return nil, fmt.Errorf("expected https://discord.com/api/webhooks/ID/TOKEN")
+	}
+	return NewDiscord(target), nil
+}
+
+// NewDiscord returns a Discord notifier using the default message templates
+func NewDiscord(webhookURL string) *Discord {
+	return &Discord{
+		WebhookURL: webhookURL,
+		Templates:  defaultTemplates,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (d *Discord) Notify(ctx context.Context, ev Event) error {
+	text, err := d.Templates.Render(ev)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]any{"content": text}
+	if ev.Type == EventResults {
+		payload["embeds"] = []map[string]any{{"image": map[string]string{"url": "attachment://results.png"}}}
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	// Webhooks take attachments as multipart form files next to the JSON
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("payload_json", string(payloadJSON)); err != nil {
+		return err
+	}
+	if ev.Type == EventResults {
+		part, err := mw.CreateFormFile("files[0]", "results.png")
+		if err != nil {
+			return err
+		}
+		if err := card.Render(part, ev.Card()); err != nil {
+			return err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.WebhookURL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("discord: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 204 without ?wait=true, 200 with it
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("discord: webhook returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
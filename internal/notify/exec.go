@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register("exec", func(target string) (Notifier, error) {
+		return NewExec(target)
+	})
+}
+
+// ExecTimeout is how long a hook may run before it is killed
+const ExecTimeout = 10 * time.Second
+
+// execEnv are the variables a hook inherits from votigo's environment.
+// Everything else, such as VOTIGO_DB_KEY and webhook URLs, is withheld.
+var execEnv = []string{"PATH", "HOME", "LANG", "TZ", "SYSTEMROOT"}
+
+// Exec runs a command for each event, for organizers who would rather
+// write a shell script than a plugin. The event is written to its standard
+// input as JSON (see ExecPayload), and VOTIGO_EVENT and VOTIGO_POLL_ID are
+// set so a script can pick the events it wants without parsing it.
+//
+// The command runs without a shell, in the temporary directory, with only
+// the variables in execEnv, and is killed after Timeout. Its output is
+// discarded unless it fails, when the end of its standard error is
+// returned.
+type Exec struct {
+	Path    string
+	Args    []string
+	Timeout time.Duration
+}
+
+// NewExec runs command, a program followed by its arguments separated by
+// spaces. A program without a slash is looked up in PATH now, so a typo
+// fails at startup rather than when the first poll opens.
+func NewExec(command string) (*Exec, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("expected a command")
+	}
+	path, err := exec.LookPath(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	return &Exec{Path: path, Args: fields[1:], Timeout: ExecTimeout}, nil
+}
+
+// ExecPayload is the JSON an Exec hook reads. TotalVotes and Results are
+// only set for results.
+type ExecPayload struct {
+	Event      EventType    `json:"event"` // opened, closed or results
+	Poll       ExecPoll     `json:"poll"`
+	TotalVotes *int64       `json:"total_votes,omitempty"`
+	Results    []ExecResult `json:"results,omitempty"`
+	EventName  string       `json:"event_name,omitempty"`
+	Text       string       `json:"text"` // the announcement chat notifiers post
+}
+
+// ExecPoll identifies the poll an event is about
+type ExecPoll struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	VoteType string `json:"vote_type"`
+}
+
+// ExecResult is one option's final standing, best first. Tied options
+// share a place.
+type ExecResult struct {
+	Place int    `json:"place"`
+	Tied  bool   `json:"tied,omitempty"`
+	Name  string `json:"name"`
+	Score int64  `json:"score"`
+	Unit  string `json:"unit"` // vote, or point for ranked polls
+}
+
+// NewExecPayload describes ev as a hook reads it
+func NewExecPayload(ev Event) (ExecPayload, error) {
+	text, err := defaultTemplates.Render(ev)
+	if err != nil {
+		return ExecPayload{}, err
+	}
+	p := ExecPayload{
+		Event:     ev.Type,
+		Poll:      ExecPoll{ID: ev.CategoryID, Name: ev.Category, VoteType: ev.VoteType},
+		EventName: ev.EventName,
+		Text:      text,
+	}
+	if ev.Type == EventResults {
+		p.TotalVotes = &ev.TotalVotes
+		p.Results = make([]ExecResult, len(ev.Results))
+		for i, r := range ev.Results {
+			p.Results[i] = ExecResult{Place: r.Place, Tied: r.Tied, Name: r.Name, Score: r.Score, Unit: ev.ScoreUnit()}
+		}
+	}
+	return p, nil
+}
+
+func (e *Exec) Notify(ctx context.Context, ev Event) error {
+	payload, err := NewExecPayload(ev)
+	if err != nil {
+		return err
+	}
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, e.Path, e.Args...)
+	cmd.Dir = os.TempDir()
+	cmd.Env = []string{
+		"VOTIGO_EVENT=" + string(ev.Type),
+		"VOTIGO_POLL_ID=" + strconv.FormatInt(ev.CategoryID, 10),
+	}
+	for _, name := range execEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr tailBuffer
+	cmd.Stderr = &stderr
+	// A script's children may hold its output open after it is killed
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("exec: %s killed after %s", e.Path, e.Timeout)
+	}
+	if err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return fmt.Errorf("exec: %s: %w: %s", e.Path, err, msg)
+		}
+		return fmt.Errorf("exec: %s: %w", e.Path, err)
+	}
+	return nil
+}
+
+// tailBuffer keeps the last 512 bytes written to it
+type tailBuffer struct {
+	bytes.Buffer
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	const keep = 512
+	n := len(p)
+	b.Buffer.Write(p)
+	if over := b.Len() - keep; over > 0 {
+		b.Next(over)
+	}
+	return n, nil
+}
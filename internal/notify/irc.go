@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register("irc", func(target string) (Notifier, error) {
+		return ParseIRC(target)
+	})
+}
+
+// ircTimeout bounds a whole announcement when the context has no deadline
+const ircTimeout = 30 * time.Second
+
+// IRC connects to a server, joins a channel, posts the event and quits.
+// Announcements are rare enough that holding a connection open isn't worth
+// the reconnect logic.
+type IRC struct {
+	Addr      string // host:port
+	TLS       bool
+	Nick      string
+	Channel   string
+	Templates Templates
+}
+
+// ParseIRC builds an IRC notifier from a target such as
+// irc://irc.example.org/lanparty or ircs://irc.libera.chat:6697/lanparty?nick=votebot.
+// The channel gets a leading # unless it already has a channel prefix.
+func ParseIRC(target string) (*IRC, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &IRC{
+		Nick:      "votigo",
+		Templates: defaultTemplates,
+	}
+	switch u.Scheme {
+	case "irc":
+		n.Addr = hostPort(u, "6667")
+	case "ircs":
+		n.Addr = hostPort(u, "6697")
+		n.TLS = true
+	default:
+		return nil, fmt.Errorf("target must be an irc:// or ircs:// URL")
+	}
+
+	// "#" starts a URL fragment, so accept the channel either way
+	n.Channel = strings.TrimPrefix(u.Path, "/")
+	if n.Channel == "" {
+		n.Channel = u.Fragment
+	}
+	if n.Channel == "" || u.Hostname() == "" {
+		return nil, fmt.Errorf("expected irc://host[:port]/channel")
+	}
+	if !strings.ContainsAny(n.Channel[:1], "#&") {
+		n.Channel = "#" + n.Channel
+	}
+	if nick := u.Query().Get("nick"); nick != "" {
+		n.Nick = nick
+	}
+	return n, nil
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
+
+func (n *IRC) Notify(ctx context.Context, ev Event) error {
+	text, err := n.Templates.Render(ev)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ircTimeout)
+		defer cancel()
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", n.Addr)
+	if err != nil {
+		return fmt.Errorf("irc: %w", err)
+	}
+	if n.TLS {
+		host, _, _ := net.SplitHostPort(n.Addr)
+		conn = tls.Client(conn, &tls.Config{ServerName: host})
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	r := bufio.NewReader(conn)
+	send := func(format string, args ...any) error {
+		_, err := fmt.Fprintf(conn, format+"\r\n", args...)
+		return err
+	}
+
+	if err := send("NICK %s", n.Nick); err != nil {
+		return fmt.Errorf("irc: %w", err)
+	}
+	if err := send("USER %s 0 * :Votigo announcements", n.Nick); err != nil {
+		return fmt.Errorf("irc: %w", err)
+	}
+
+	// Wait for the welcome (001) before joining, answering PINGs meanwhile
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("irc: registration: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if token, ok := strings.CutPrefix(line, "PING "); ok {
+			send("PONG %s", token)
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if fields[1] == "001" {
+			break
+		}
+		if fields[1] == "433" {
+			return fmt.Errorf("irc: nickname %q is already in use", n.Nick)
+		}
+		if fields[0] == "ERROR" {
+			return fmt.Errorf("irc: %s", line)
+		}
+	}
+
+	if err := send("JOIN %s", n.Channel); err != nil {
+		return fmt.Errorf("irc: %w", err)
+	}
+	// A stray CR in a poll name must not smuggle in extra commands
+	text = strings.ReplaceAll(text, "\r", "")
+	for line := range strings.SplitSeq(text, "\n") {
+		if line == "" {
+			continue
+		}
+		if err := send("PRIVMSG %s :%s", n.Channel, line); err != nil {
+			return fmt.Errorf("irc: %w", err)
+		}
+	}
+	if err := send("QUIT :Votigo"); err != nil {
+		return fmt.Errorf("irc: %w", err)
+	}
+
+	// Let the server close the connection so queued messages are flushed
+	for {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register("matrix", func(target string) (Notifier, error) {
+		return ParseMatrix(target)
+	})
+}
+
+// Matrix posts events as m.text messages to a room through the
+// client-server API
+type Matrix struct {
+	Homeserver  string // base URL, e.g. https://matrix.example.org
+	RoomID      string // e.g. !abcdef:example.org
+	AccessToken string
+	Templates   Templates
+	Client      *http.Client
+}
+
+// ParseMatrix builds a Matrix notifier from a target of the form
+// https://ACCESS_TOKEN@matrix.example.org/!roomid:example.org
+func ParseMatrix(target string) (*Matrix, error) {
+	// Don't echo the target in errors; it carries the access token
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target URL")
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return nil, fmt.Errorf("target must be an http(s) URL")
+	}
+
+	token := u.User.Username()
+	roomID := strings.TrimPrefix(u.Path, "/")
+	if token == "" || !strings.HasPrefix(roomID, "!") {
+		return nil, fmt.Errorf("expected https://ACCESS_TOKEN@homeserver/!roomid:server")
+	}
+
+	return &Matrix{
+		Homeserver:  u.Scheme + "://" + u.Host,
+		RoomID:      roomID,
+		AccessToken: token,
+		Templates:   defaultTemplates,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (m *Matrix) Notify(ctx context.Context, ev Event) error {
+	text, err := m.Templates.Render(ev)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{"msgtype": "m.text", "body": text})
+	if err != nil {
+		return err
+	}
+
+	// A fresh transaction ID per event; the homeserver uses it to drop
+	// retried requests.
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.Homeserver, url.PathEscape(m.RoomID), url.PathEscape("votigo-"+rand.Text()))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.AccessToken)
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("matrix: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("matrix: homeserver returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
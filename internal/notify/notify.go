@@ -0,0 +1,92 @@
+// Package notify announces poll lifecycle events to chat services.
+package notify
+
+import (
+	"context"
+
+	"github.com/palm-arcade/votigo/internal/card"
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// EventType identifies what happened to a poll
+type EventType string
+
+const (
+	EventOpened  EventType = "opened"
+	EventClosed  EventType = "closed"
+	EventResults EventType = "results"
+)
+
+// Event describes a poll lifecycle change. Results, TotalVotes and
+// EventName are only filled in for EventResults.
+type Event struct {
+	Type       EventType
+	CategoryID int64
+	Category   string
+	VoteType   string
+	TotalVotes int64
+	Results    []Result
+	EventName  string // the event_name setting, for results cards
+}
+
+// Result is one option's final standing. Score is a vote count, or Borda
+// points for ranked polls. Place is shared by options that are Tied.
+type Result struct {
+	Name  string
+	Score int64
+	Place int
+	Tied  bool
+}
+
+// ScoreUnit names the unit of Result.Score for the event's vote type
+func (e Event) ScoreUnit() string {
+	if e.VoteType == "ranked" {
+		return "point"
+	}
+	return "vote"
+}
+
+// Card returns the results card for an EventResults event
+func (e Event) Card() card.Card {
+	c := card.Card{Brand: e.EventName, Title: e.Category, Unit: e.ScoreUnit(), TotalVotes: e.TotalVotes}
+	for _, r := range e.Results {
+		c.Results = append(c.Results, card.Result{Name: r.Name, Score: r.Score, Place: r.Place})
+	}
+	return c
+}
+
+// Notifier delivers an event to an external service
+type Notifier interface {
+	Notify(ctx context.Context, ev Event) error
+}
+
+// NewEvent builds an event for a category, tallying its votes for
+// EventResults.
+func NewEvent(ctx context.Context, q *db.Queries, typ EventType, cat db.Category) (Event, error) {
+	ev := Event{
+		Type:       typ,
+		CategoryID: cat.ID,
+		Category:   cat.Name,
+		VoteType:   cat.VoteType,
+	}
+	if typ != EventResults {
+		return ev, nil
+	}
+
+	var err error
+	if ev.TotalVotes, err = q.CountVotesByCategory(ctx, cat.ID); err != nil {
+		return ev, err
+	}
+	if ev.EventName, err = q.SettingValue(ctx, db.SettingEventName); err != nil {
+		return ev, err
+	}
+
+	rows, err := q.Tally(ctx, cat)
+	if err != nil {
+		return ev, err
+	}
+	for i, place := range db.Places(rows, ev.TotalVotes) {
+		ev.Results = append(ev.Results, Result{Name: rows[i].Name, Score: rows[i].Score, Place: place.Rank, Tied: place.Tied})
+	}
+	return ev, nil
+}
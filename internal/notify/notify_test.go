@@ -0,0 +1,430 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"image/png"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/notify"
+)
+
+func TestSlackNotify(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	err := notify.NewSlack(srv.URL).Notify(context.Background(), notify.Event{
+		Type:       notify.EventResults,
+		Category:   "Best Game",
+		VoteType:   "single",
+		TotalVotes: 3,
+		Results: []notify.Result{
+			{Name: "Tetris", Score: 2, Place: 1},
+			{Name: "Doom", Score: 1, Place: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("notify failed: %v", err)
+	}
+
+	want := "Final results for *Best Game* (3 ballots):\n1. Tetris (2 votes)\n2. Doom (1 vote)"
+	if got["text"] != want {
+		t.Errorf("unexpected message:\n%s\nwant:\n%s", got["text"], want)
+	}
+}
+
+func TestSlackNotify_WebhookError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	err := notify.NewSlack(srv.URL).Notify(context.Background(), notify.Event{
+		Type:     notify.EventOpened,
+		Category: "Best Game",
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("expected webhook error to be surfaced, got %v", err)
+	}
+}
+
+func TestDiscordNotify(t *testing.T) {
+	var payload map[string]any
+	var cardWidth int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.Unmarshal([]byte(r.FormValue("payload_json")), &payload); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		file, header, err := r.FormFile("files[0]")
+		if err != nil {
+			t.Errorf("expected the results card to be attached: %v", err)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		defer file.Close()
+		cfg, err := png.DecodeConfig(file)
+		if err != nil || header.Filename != "results.png" {
+			t.Errorf("expected results.png to be a PNG, got %q: %v", header.Filename, err)
+		}
+		cardWidth = cfg.Width
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	err := notify.NewDiscord(srv.URL).Notify(context.Background(), notify.Event{
+		Type:       notify.EventResults,
+		Category:   "Best Game",
+		VoteType:   "single",
+		TotalVotes: 3,
+		Results:    []notify.Result{{Name: "Tetris", Score: 2, Place: 1}, {Name: "Doom", Score: 1, Place: 2}},
+		EventName:  "Palm Arcade LAN",
+	})
+	if err != nil {
+		t.Fatalf("notify failed: %v", err)
+	}
+	if !strings.HasPrefix(payload["content"].(string), "Final results for *Best Game*") {
+		t.Errorf("unexpected message: %v", payload["content"])
+	}
+	if embeds, _ := json.Marshal(payload["embeds"]); !strings.Contains(string(embeds), "attachment://results.png") {
+		t.Errorf("expected the card to be embedded, got %s", embeds)
+	}
+	if cardWidth != 1200 {
+		t.Errorf("expected a 1200 pixel wide card, got %d", cardWidth)
+	}
+}
+
+func TestParseTemplates_Override(t *testing.T) {
+	tmpls, err := notify.ParseTemplates(map[notify.EventType]string{
+		notify.EventOpened: "Go vote on {{.Category}}!",
+	})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	msg, _ := tmpls.Render(notify.Event{Type: notify.EventOpened, Category: "Best Game"})
+	if msg != "Go vote on Best Game!" {
+		t.Errorf("expected override, got %q", msg)
+	}
+	msg, _ = tmpls.Render(notify.Event{Type: notify.EventClosed, Category: "Best Game"})
+	if msg != "Voting has closed for *Best Game*" {
+		t.Errorf("expected default closed template, got %q", msg)
+	}
+
+	if _, err := notify.ParseTemplates(map[notify.EventType]string{notify.EventOpened: "{{.Nope"}); err == nil {
+		t.Error("expected parse error for malformed template")
+	}
+}
+
+func TestRender_Tie(t *testing.T) {
+	tmpls, err := notify.ParseTemplates(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := tmpls.Render(notify.Event{
+		Type:       notify.EventResults,
+		Category:   "Best Game",
+		VoteType:   "single",
+		TotalVotes: 4,
+		Results: []notify.Result{
+			{Name: "Tetris", Score: 2, Place: 1, Tied: true},
+			{Name: "Doom", Score: 2, Place: 1, Tied: true},
+			{Name: "Zool", Score: 0, Place: 3},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Final results for *Best Game* (4 ballots):\n1. Tetris (2 votes, tie)\n1. Doom (2 votes, tie)\n3. Zool (0 votes)"
+	if msg != want {
+		t.Errorf("unexpected message:\n%s\nwant:\n%s", msg, want)
+	}
+}
+
+func TestNewEvent_RankedResults(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	ctx := context.Background()
+	q := db.New(conn)
+	cat, _ := q.CreateCategory(ctx, db.CreateCategoryParams{
+		Name: "Ranked", VoteType: "ranked", Status: "closed", ShowResults: "live",
+	})
+	a, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "A"})
+	b, _ := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: "B"})
+
+	vote, _ := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: "one"})
+	q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: b.ID, Rank: sql.NullInt64{Int64: 1, Valid: true}})
+	q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: a.ID, Rank: sql.NullInt64{Int64: 2, Valid: true}})
+
+	ev, err := notify.NewEvent(ctx, q, notify.EventResults, cat)
+	if err != nil {
+		t.Fatalf("failed to build event: %v", err)
+	}
+	if ev.TotalVotes != 1 || len(ev.Results) != 2 || ev.Results[0].Name != "B" || ev.Results[0].Score != 3 || ev.Results[1].Place != 2 {
+		t.Errorf("unexpected results: %+v", ev)
+	}
+	if ev.ScoreUnit() != "point" {
+		t.Errorf("expected ranked polls to score points, got %q", ev.ScoreUnit())
+	}
+}
+
+func TestNew_Backends(t *testing.T) {
+	for _, spec := range []string{
+		"slack=https://hooks.slack.com/services/T/B/X",
+		"matrix=https://token@matrix.example.org/!room:example.org",
+		"irc=ircs://irc.libera.chat/lanparty",
+		"discord=https://discord.com/api/webhooks/123/abc",
+	} {
+		if _, err := notify.New(spec); err != nil {
+			t.Errorf("New(%q): %v", spec, err)
+		}
+	}
+
+	for _, spec := range []string{
+		"discord=https://example.org",
+		"slack",
+		"matrix=https://matrix.example.org/!room:example.org",
+		"irc=https://irc.example.org/lanparty",
+	} {
+		if _, err := notify.New(spec); err == nil {
+			t.Errorf("New(%q): expected error", spec)
+		}
+	}
+}
+
+func TestParseIRC(t *testing.T) {
+	tests := []struct {
+		target, addr, channel, nick string
+		tls                         bool
+	}{
+		{"irc://irc.example.org/lanparty", "irc.example.org:6667", "#lanparty", "votigo", false},
+		{"ircs://irc.libera.chat:7000/%23retro?nick=votebot", "irc.libera.chat:7000", "#retro", "votebot", true},
+		{"irc://irc.example.org/#lan", "irc.example.org:6667", "#lan", "votigo", false},
+		{"irc://irc.example.org/&local", "irc.example.org:6667", "&local", "votigo", false},
+	}
+	for _, tt := range tests {
+		n, err := notify.ParseIRC(tt.target)
+		if err != nil {
+			t.Errorf("ParseIRC(%q): %v", tt.target, err)
+			continue
+		}
+		if n.Addr != tt.addr || n.Channel != tt.channel || n.Nick != tt.nick || n.TLS != tt.tls {
+			t.Errorf("ParseIRC(%q) = %+v", tt.target, n)
+		}
+	}
+}
+
+func TestIRCNotify(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var lines []string
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				break
+			}
+			line = strings.TrimRight(line, "\r\n")
+			lines = append(lines, line)
+			switch {
+			case strings.HasPrefix(line, "USER "):
+				conn.Write([]byte("PING :irc.test\r\n:irc.test 001 votigo :Welcome\r\n"))
+			case strings.HasPrefix(line, "QUIT"):
+				received <- lines
+				return
+			}
+		}
+		received <- lines
+	}()
+
+	n, err := notify.ParseIRC("irc://" + ln.Addr().String() + "/lanparty")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	err = n.Notify(context.Background(), notify.Event{
+		Type:     notify.EventResults,
+		Category: "Best\r\nQUIT Game",
+		Results:  []notify.Result{{Name: "Tetris", Score: 2, Place: 1}},
+	})
+	if err != nil {
+		t.Fatalf("notify failed: %v", err)
+	}
+
+	lines := <-received
+	want := []string{
+		"NICK votigo",
+		"USER votigo 0 * :Votigo announcements",
+		"PONG :irc.test",
+		"JOIN #lanparty",
+		"PRIVMSG #lanparty :Final results for *Best",
+		"PRIVMSG #lanparty :QUIT Game* (0 ballots):",
+		"PRIVMSG #lanparty :1. Tetris (2 votes)",
+		"QUIT :Votigo",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected IRC session:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestMatrixNotify(t *testing.T) {
+	var path, auth, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		auth = r.Header.Get("Authorization")
+		var msg map[string]string
+		json.NewDecoder(r.Body).Decode(&msg)
+		body = msg["body"]
+		w.Write([]byte(`{"event_id":"$1"}`))
+	}))
+	defer srv.Close()
+
+	target := strings.Replace(srv.URL, "http://", "http://secret@", 1) + "/!room:example.org"
+	m, err := notify.ParseMatrix(target)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := m.Notify(context.Background(), notify.Event{Type: notify.EventOpened, Category: "Best Game"}); err != nil {
+		t.Fatalf("notify failed: %v", err)
+	}
+
+	if !strings.HasPrefix(path, "/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message/votigo-") {
+		t.Errorf("unexpected path %q", path)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("unexpected auth header %q", auth)
+	}
+	if body != "Voting is open for *Best Game*" {
+		t.Errorf("unexpected body %q", body)
+	}
+}
+
+func TestMulti_ContinuesPastFailures(t *testing.T) {
+	var delivered int
+	ok := notifierFunc(func(context.Context, notify.Event) error { delivered++; return nil })
+	boom := errors.New("boom")
+	failing := notifierFunc(func(context.Context, notify.Event) error { return boom })
+
+	err := notify.Multi{failing, ok}.Notify(context.Background(), notify.Event{Type: notify.EventOpened})
+	if !errors.Is(err, boom) || delivered != 1 {
+		t.Errorf("expected error joined and second notifier called, got %v, %d", err, delivered)
+	}
+}
+
+func TestExecNotify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts here are shell scripts")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "event.json")
+	script := filepath.Join(dir, "hook.sh")
+	body := "#!/bin/sh\n" +
+		"[ -z \"$VOTIGO_DB_KEY\" ] || exit 3\n" +
+		"echo \"$VOTIGO_EVENT $VOTIGO_POLL_ID\" > " + out + ".env\n" +
+		"cat > " + out + "\n"
+	if err := os.WriteFile(script, []byte(body), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VOTIGO_DB_KEY", "secret")
+
+	n, err := notify.New("exec=" + script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.Notify(context.Background(), notify.Event{
+		Type:       notify.EventResults,
+		CategoryID: 7,
+		Category:   "Best Game",
+		VoteType:   "ranked",
+		TotalVotes: 2,
+		Results:    []notify.Result{{Name: "Tetris", Score: 3, Place: 1}},
+	})
+	if err != nil {
+		t.Fatalf("notify failed: %v", err)
+	}
+
+	env, _ := os.ReadFile(out + ".env")
+	if string(env) != "results 7\n" {
+		t.Errorf("unexpected environment %q", env)
+	}
+	var got notify.ExecPayload
+	data, _ := os.ReadFile(out)
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid payload %s: %v", data, err)
+	}
+	want := notify.ExecResult{Place: 1, Name: "Tetris", Score: 3, Unit: "point"}
+	if got.Event != notify.EventResults || got.Poll.ID != 7 || got.TotalVotes == nil || *got.TotalVotes != 2 ||
+		len(got.Results) != 1 || got.Results[0] != want || !strings.HasPrefix(got.Text, "Final results") {
+		t.Errorf("unexpected payload %s", data)
+	}
+}
+
+func TestExecNotify_Failures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts here are shell scripts")
+	}
+	if _, err := notify.New("exec=/no/such/hook"); err == nil {
+		t.Error("expected a missing command to fail at startup")
+	}
+
+	failing, err := notify.NewExec("sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	failing.Args = []string{"-c", "echo lights offline >&2; exit 1"}
+	err = failing.Notify(context.Background(), notify.Event{Type: notify.EventOpened})
+	if err == nil || !strings.Contains(err.Error(), "lights offline") {
+		t.Errorf("expected the hook's stderr in the error, got %v", err)
+	}
+
+	slow, err := notify.NewExec("sleep 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow.Timeout = 50 * time.Millisecond
+	start := time.Now()
+	err = slow.Notify(context.Background(), notify.Event{Type: notify.EventOpened})
+	if err == nil || !strings.Contains(err.Error(), "killed after") || time.Since(start) > 2*time.Second {
+		t.Errorf("expected the hook killed after its timeout, got %v after %s", err, time.Since(start))
+	}
+}
+
+// notifierFunc adapts a function to notify.Notifier
+type notifierFunc func(context.Context, notify.Event) error
+
+func (f notifierFunc) Notify(ctx context.Context, ev notify.Event) error { return f(ctx, ev) }
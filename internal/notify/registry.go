@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Factory builds a notifier from a backend-specific target, usually a URL
+type Factory func(target string) (Notifier, error)
+
+var backends = map[string]Factory{}
+
+// Register makes a backend available to New under name. Backends register
+// themselves from init; registering the same name twice panics.
+func Register(name string, f Factory) {
+	if _, dup := backends[name]; dup {
+		panic("notify: backend registered twice: " + name)
+	}
+	backends[name] = f
+}
+
+// Backends lists the registered backend names in sorted order
+func Backends() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// New builds a notifier from a "backend=target" spec, for example
+// "irc=ircs://irc.libera.chat/lanparty".
+func New(spec string) (Notifier, error) {
+	name, target, ok := strings.Cut(spec, "=")
+	if !ok || target == "" {
+		return nil, fmt.Errorf("notifier %q: expected backend=target", spec)
+	}
+	f, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown notifier backend %q (available: %s)", name, strings.Join(Backends(), ", "))
+	}
+	n, err := f(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s notifier: %w", name, err)
+	}
+	return n, nil
+}
+
+// Multi sends each event to every notifier in turn. One failing backend
+// doesn't stop the others; all errors are returned together.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, ev Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, ev); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register("slack", func(target string) (Notifier, error) {
+		if !strings.HasPrefix(target, "https://") && !strings.HasPrefix(target, "http://") {
+			return nil, fmt.Errorf("webhook must be an http(s) URL")
+		}
+		return NewSlack(target), nil
+	})
+}
+
+// Slack posts events to a Slack incoming webhook
+type Slack struct {
+	WebhookURL string
+	Templates  Templates
+	Client     *http.Client
+}
+
+// NewSlack returns a Slack notifier using the default message templates
+func NewSlack(webhookURL string) *Slack {
+	return &Slack{
+		WebhookURL: webhookURL,
+		Templates:  defaultTemplates,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *Slack) Notify(ctx context.Context, ev Event) error {
+	text, err := s.Templates.Render(ev)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack: webhook returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
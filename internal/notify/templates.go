@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultTemplates holds the message text for each event type. Templates
+// execute against an Event.
+var DefaultTemplates = map[EventType]string{
+	EventOpened: `Voting is open for *{{.Category}}*`,
+	EventClosed: `Voting has closed for *{{.Category}}*`,
+	EventResults: `Final results for *{{.Category}}* ({{plural .TotalVotes "ballot"}}):
+{{- range $r := .Results}}
+{{$r.Place}}. {{$r.Name}} ({{plural $r.Score $.ScoreUnit}}{{if $r.Tied}}, tie{{end}})
+{{- else}}
+No votes were cast.
+{{- end}}`,
+}
+
+// defaultTemplates is DefaultTemplates parsed once at startup
+var defaultTemplates = func() Templates {
+	t, err := ParseTemplates(nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}()
+
+// Templates are parsed message templates keyed by event type
+type Templates map[EventType]*template.Template
+
+// ParseTemplates parses message templates, falling back to DefaultTemplates
+// for any event type not in overrides.
+func ParseTemplates(overrides map[EventType]string) (Templates, error) {
+	funcs := template.FuncMap{
+		"add": func(a, b int) int { return a + b },
+		// plural formats a count with its unit, e.g. "1 vote" or "2 votes"
+		"plural": func(n int64, unit string) string {
+			if n == 1 {
+				return "1 " + unit
+			}
+			return fmt.Sprintf("%d %ss", n, unit)
+		},
+	}
+
+	parsed := make(Templates, len(DefaultTemplates))
+	for typ, text := range DefaultTemplates {
+		if o, ok := overrides[typ]; ok {
+			text = o
+		}
+		t, err := template.New(string(typ)).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s template: %w", typ, err)
+		}
+		parsed[typ] = t
+	}
+	return parsed, nil
+}
+
+// Render formats an event using the template for its type
+func (t Templates) Render(ev Event) (string, error) {
+	tmpl, ok := t[ev.Type]
+	if !ok {
+		return "", fmt.Errorf("no template for event %q", ev.Type)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, ev); err != nil {
+		return "", fmt.Errorf("render %s message: %w", ev.Type, err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,188 @@
+// Package oembed checks the video links attached to options and fetches
+// their titles and thumbnails from the provider's oEmbed endpoint
+// (https://oembed.com), so ballots can preview them.
+package oembed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Providers whose links are checked, embedded and previewed. Links to
+// other sites are accepted but only linked to.
+const (
+	YouTube = "YouTube"
+	Vimeo   = "Vimeo"
+)
+
+// DefaultEndpoints are the providers' oEmbed endpoints
+var DefaultEndpoints = map[string]string{
+	YouTube: "https://www.youtube.com/oembed",
+	Vimeo:   "https://vimeo.com/api/oembed.json",
+}
+
+// ErrUnavailable is the provider saying there is no such video, or that it
+// is private or can't be embedded
+var ErrUnavailable = errors.New("the video is private, removed or can't be embedded")
+
+// maxTitle caps titles, which providers let run long
+const maxTitle = 200
+
+var (
+	youtubeID = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoID   = regexp.MustCompile(`^[0-9]{1,12}$`)
+)
+
+// Video is a link to a video. Provider and ID are empty for sites without
+// a known provider.
+type Video struct {
+	Link     string
+	Provider string
+	ID       string
+}
+
+// Parse checks a video link: it must be an http(s) URL and, on a known
+// provider's site, point at a video rather than a channel or the home page.
+// Its errors can be shown to the admin.
+func Parse(link string) (Video, error) {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Video{}, errors.New("the video must be an http(s) link")
+	}
+	v := Video{Link: link}
+	switch strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") {
+	case "youtu.be":
+		v.Provider, v.ID = YouTube, strings.TrimPrefix(u.Path, "/")
+	case "youtube.com", "m.youtube.com", "youtube-nocookie.com":
+		v.Provider = YouTube
+		if u.Path == "/watch" {
+			v.ID = u.Query().Get("v")
+		} else if rest, ok := strings.CutPrefix(u.Path, "/shorts/"); ok {
+			v.ID = rest
+		} else if rest, ok := strings.CutPrefix(u.Path, "/embed/"); ok {
+			v.ID = rest
+		}
+	case "vimeo.com", "player.vimeo.com":
+		v.Provider = Vimeo
+		v.ID = strings.TrimPrefix(strings.TrimPrefix(u.Path, "/video"), "/")
+	}
+	switch {
+	case v.Provider == YouTube && !youtubeID.MatchString(v.ID):
+		return Video{}, errors.New("the YouTube link must be to a video, like https://youtu.be/dQw4w9WgXcQ")
+	case v.Provider == Vimeo && !vimeoID.MatchString(v.ID):
+		return Video{}, errors.New("the Vimeo link must be to a video, like https://vimeo.com/76979871")
+	}
+	return v, nil
+}
+
+// Canonical returns the provider's own link to the video, which previews
+// are fetched and cached by, or the link as given for other sites
+func (v Video) Canonical() string {
+	switch v.Provider {
+	case YouTube:
+		return "https://www.youtube.com/watch?v=" + v.ID
+	case Vimeo:
+		return "https://vimeo.com/" + v.ID
+	}
+	return v.Link
+}
+
+// Embed returns a player that can play the video in a page, or "" for
+// links that can only be followed. Players are the providers' privacy
+// modes, which set no cookies until played.
+func (v Video) Embed() string {
+	switch v.Provider {
+	case YouTube:
+		return "https://www.youtube-nocookie.com/embed/" + v.ID
+	case Vimeo:
+		return "https://player.vimeo.com/video/" + v.ID + "?dnt=1"
+	}
+	return ""
+}
+
+// Preview is what a provider says about a video
+type Preview struct {
+	Provider  string
+	Title     string
+	Author    string
+	Thumbnail string // https URL, or "" if the provider has none
+}
+
+// Client fetches previews
+type Client struct {
+	Endpoints map[string]string // provider to oEmbed endpoint
+	Client    *http.Client
+}
+
+// New returns a client using the providers' own endpoints
+func New() *Client {
+	return &Client{
+		Endpoints: DefaultEndpoints,
+		Client:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Fetch asks the video's provider for its preview. It returns
+// ErrUnavailable when the provider refuses the video, and other errors
+// when it can't be asked, such as with no internet at the venue.
+func (c *Client) Fetch(ctx context.Context, v Video) (Preview, error) {
+	endpoint, ok := c.Endpoints[v.Provider]
+	if !ok {
+		return Preview{}, fmt.Errorf("oembed: no provider for %s", v.Link)
+	}
+	query := url.Values{"url": {v.Canonical()}, "format": {"json"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return Preview{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return Preview{}, fmt.Errorf("oembed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return Preview{}, ErrUnavailable
+	default:
+		return Preview{}, fmt.Errorf("oembed: %s returned %s", v.Provider, resp.Status)
+	}
+
+	var body struct {
+		Title        string `json:"title"`
+		AuthorName   string `json:"author_name"`
+		ThumbnailURL string `json:"thumbnail_url"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body); err != nil {
+		return Preview{}, fmt.Errorf("oembed: %s: %w", v.Provider, err)
+	}
+	p := Preview{
+		Provider: v.Provider,
+		Title:    truncate(strings.TrimSpace(body.Title), maxTitle),
+		Author:   truncate(strings.TrimSpace(body.AuthorName), maxTitle),
+	}
+	if strings.HasPrefix(body.ThumbnailURL, "https://") {
+		p.Thumbnail = body.ThumbnailURL
+	}
+	return p, nil
+}
+
+// truncate cuts s to at most n runes
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
@@ -0,0 +1,94 @@
+package oembed_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/palm-arcade/votigo/internal/oembed"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		link, provider, embed string
+		ok                    bool
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42", oembed.YouTube, "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", true},
+		{"https://youtu.be/dQw4w9WgXcQ", oembed.YouTube, "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", true},
+		{"https://m.youtube.com/shorts/dQw4w9WgXcQ", oembed.YouTube, "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", true},
+		{"https://vimeo.com/76979871", oembed.Vimeo, "https://player.vimeo.com/video/76979871?dnt=1", true},
+		{"https://player.vimeo.com/video/76979871", oembed.Vimeo, "https://player.vimeo.com/video/76979871?dnt=1", true},
+		{"https://example.com/trailer.mp4", "", "", true},
+		{"https://www.youtube.com/@gremlin", "", "", false},
+		{"https://youtu.be/short", "", "", false},
+		{"https://vimeo.com/channels/staffpicks", "", "", false},
+		{"javascript:alert(1)", "", "", false},
+		{"/images/trailer.mp4", "", "", false},
+	}
+	for _, tt := range tests {
+		v, err := oembed.Parse(tt.link)
+		if (err == nil) != tt.ok {
+			t.Errorf("Parse(%q) error = %v, want ok %v", tt.link, err, tt.ok)
+			continue
+		}
+		if v.Provider != tt.provider || v.Embed() != tt.embed {
+			t.Errorf("Parse(%q) = %s %q, want %s %q", tt.link, v.Provider, v.Embed(), tt.provider, tt.embed)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	var asked string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asked = r.URL.Query().Get("url")
+		switch r.URL.Path {
+		case "/ok":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"type":"video","title":" Zool 2 longplay ","author_name":"Amiga Longplays","provider_name":"YouTube","thumbnail_url":"https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg"}`))
+		case "/insecure":
+			w.Write([]byte(`{"title":"Zool","thumbnail_url":"http://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg"}`))
+		case "/private":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	v, err := oembed.Parse("https://youtu.be/dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := func(path string) *oembed.Client {
+		c := oembed.New()
+		c.Endpoints = map[string]string{oembed.YouTube: srv.URL + path}
+		return c
+	}
+
+	p, err := client("/ok").Fetch(t.Context(), v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := oembed.Preview{Provider: oembed.YouTube, Title: "Zool 2 longplay", Author: "Amiga Longplays", Thumbnail: "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg"}
+	if p != want {
+		t.Errorf("expected %+v, got %+v", want, p)
+	}
+	if asked != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" {
+		t.Errorf("expected the canonical link asked for, got %q", asked)
+	}
+
+	if p, err := client("/insecure").Fetch(t.Context(), v); err != nil || p.Thumbnail != "" {
+		t.Errorf("expected a plain http thumbnail dropped, got %+v, %v", p, err)
+	}
+	if _, err := client("/private").Fetch(t.Context(), v); !errors.Is(err, oembed.ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable for a private video, got %v", err)
+	}
+	if _, err := client("/down").Fetch(t.Context(), v); err == nil || errors.Is(err, oembed.ErrUnavailable) {
+		t.Errorf("expected a provider error to be told apart from an unavailable video, got %v", err)
+	}
+	other, _ := oembed.Parse("https://example.com/trailer.mp4")
+	if _, err := client("/ok").Fetch(t.Context(), other); err == nil {
+		t.Error("expected no preview for a site without a provider")
+	}
+}
@@ -0,0 +1,156 @@
+// Package example is a plugin showing each kind of extension votigo has.
+// It isn't built in; uncomment its import in plugins.go to try it, or copy
+// it as the start of a fork's own.
+//
+//   - no-self-votes, a ballot validator: voters can't vote for an option
+//     named after themselves
+//   - f1, a counting method: Formula 1 points, 25 for first down to 1 for
+//     tenth
+//   - log=PATH, a notifier: appends announcements to a file, or stderr
+//   - range="Table 1-8", an option source: numbered options, for
+//     votigo option import
+package example
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/notify"
+	"github.com/palm-arcade/votigo/internal/plugin"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func init() {
+	plugin.RegisterValidator("no-self-votes", plugin.BallotValidatorFunc(NoSelfVotes))
+	plugin.RegisterTallyMethod("f1", F1)
+	plugin.RegisterNotifier("log", func(target string) (notify.Notifier, error) {
+		return NewLog(target)
+	})
+	plugin.RegisterOptionSource("range", func(target string) (plugin.OptionSource, error) {
+		return ParseRange(target)
+	})
+}
+
+// NoSelfVotes refuses a ballot picking an option with the voter's
+// nickname, ignoring case, in any poll
+func NoSelfVotes(ctx context.Context, poll db.Category, b plugin.Ballot) error {
+	for _, c := range b.Choices {
+		if strings.EqualFold(strings.TrimSpace(c.Option.Name), b.Nickname) {
+			return errors.New("You can't vote for yourself")
+		}
+	}
+	return nil
+}
+
+// f1Points are the points for first to tenth place
+var f1Points = []int64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1}
+
+// F1 scores each ranked choice like a Formula 1 finishing position, and
+// every pick on a single or approval ballot as a win. Ties keep the poll's
+// option order.
+func F1(options []tally.Option, ballots []tally.Ballot) tally.Result {
+	points := make(map[int64]int64, len(options))
+	wins := make(map[int64]int64, len(options))
+	for _, b := range ballots {
+		for id, rank := range b {
+			if rank >= 1 && int(rank) <= len(f1Points) {
+				points[id] += f1Points[rank-1]
+			}
+			if rank == 1 {
+				wins[id]++
+			}
+		}
+	}
+
+	ranking := make([]tally.Standing, len(options))
+	for i, o := range options {
+		ranking[i] = tally.Standing{
+			OptionID:   o.ID,
+			Score:      points[o.ID],
+			FirstPlace: wins[o.ID],
+			Label:      fmt.Sprintf("%d pts", points[o.ID]),
+		}
+	}
+	slices.SortStableFunc(ranking, func(a, b tally.Standing) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	return tally.Result{Ranking: ranking}
+}
+
+// Log writes one line per announcement
+type Log struct {
+	mu        sync.Mutex
+	w         io.Writer
+	templates notify.Templates
+}
+
+// NewLog appends announcements to the file at path, or writes them to
+// standard error if path is "stderr"
+func NewLog(path string) (*Log, error) {
+	w := io.Writer(os.Stderr)
+	if path != "stderr" {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		w = f
+	}
+	templates, err := notify.ParseTemplates(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{w: w, templates: templates}, nil
+}
+
+func (l *Log) Notify(ctx context.Context, ev notify.Event) error {
+	text, err := l.templates.Render(ev)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = fmt.Fprintf(l.w, "%s %s\n", time.Now().Format(time.DateTime), strings.ReplaceAll(text, "\n", " / "))
+	return err
+}
+
+// Range is numbered options: Prefix followed by each number from First to
+// Last
+type Range struct {
+	Prefix      string
+	First, Last int
+}
+
+// ParseRange reads a range like "Table 1-8"
+func ParseRange(spec string) (Range, error) {
+	prefix, numbers := "", spec
+	if i := strings.LastIndex(spec, " "); i >= 0 {
+		prefix, numbers = spec[:i+1], spec[i+1:]
+	}
+	first, last, ok := strings.Cut(numbers, "-")
+	r := Range{Prefix: prefix}
+	var err1, err2 error
+	r.First, err1 = strconv.Atoi(first)
+	r.Last, err2 = strconv.Atoi(last)
+	if !ok || err1 != nil || err2 != nil || r.First > r.Last {
+		return r, fmt.Errorf("%q: expected a range like \"Table 1-8\"", spec)
+	}
+	return r, nil
+}
+
+func (r Range) Options(ctx context.Context) ([]string, error) {
+	names := make([]string, 0, r.Last-r.First+1)
+	for n := r.First; n <= r.Last; n++ {
+		names = append(names, r.Prefix+strconv.Itoa(n))
+	}
+	return names, nil
+}
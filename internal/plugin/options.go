@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+func init() {
+	RegisterOptionSource("file", func(path string) (OptionSource, error) {
+		return OptionFile(path), nil
+	})
+}
+
+// OptionSource is somewhere a poll's options can be read from, such as the
+// game server's map list or the tournament's bracket, for votigo option
+// import
+type OptionSource interface {
+	// Options returns the option names in ballot order. Names already on
+	// the poll are skipped by the import, so a source can be imported again
+	// as it grows.
+	Options(ctx context.Context) ([]string, error)
+}
+
+// OptionSourceFactory builds a source from a source-specific target,
+// usually a path or URL
+type OptionSourceFactory func(target string) (OptionSource, error)
+
+var optionSources = map[string]OptionSourceFactory{}
+
+// RegisterOptionSource makes a source available to NewOptionSource under
+// name. Sources register themselves from init; registering the same name
+// twice panics.
+func RegisterOptionSource(name string, f OptionSourceFactory) {
+	if _, dup := optionSources[name]; dup {
+		panic("plugin: option source registered twice: " + name)
+	}
+	optionSources[name] = f
+}
+
+// OptionSources lists the registered option source names in sorted order
+func OptionSources() []string {
+	names := make([]string, 0, len(optionSources))
+	for name := range optionSources {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// NewOptionSource builds a source from a "source=target" spec, for example
+// "file=maps.txt". A spec without a source name is a file.
+func NewOptionSource(spec string) (OptionSource, error) {
+	name, target, ok := strings.Cut(spec, "=")
+	if !ok {
+		name, target = "file", spec
+	}
+	if target == "" {
+		return nil, fmt.Errorf("option source %q: expected source=target", spec)
+	}
+	f, ok := optionSources[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown option source %q (available: %s)", name, strings.Join(OptionSources(), ", "))
+	}
+	src, err := f(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s options: %w", name, err)
+	}
+	return src, nil
+}
+
+// OptionFile reads options from a text file at a path, one per line.
+// Blank lines and lines starting with # are skipped.
+type OptionFile string
+
+func (f OptionFile) Options(ctx context.Context) ([]string, error) {
+	file, err := os.Open(string(f))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	return names, scanner.Err()
+}
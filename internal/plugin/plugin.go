@@ -0,0 +1,104 @@
+// Package plugin is where a fork adds its own behaviour without patching
+// handlers: rules a ballot must follow, ways of counting, places to announce
+// polls and places to read options from. A plugin is a Go package that
+// registers what it provides from init; a blank import in the main package
+// (see plugins.go at the repository root) builds it in. Package
+// plugin/example has one of each.
+//
+// Everything is registered before the server starts and read-only after, so
+// none of the registries lock.
+package plugin
+
+import (
+	"context"
+	"slices"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/notify"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+// Ballot is a ballot a voter submitted, after votigo's own checks: every
+// choice is an option on the poll, ranks are in range and nothing is picked
+// twice
+type Ballot struct {
+	Nickname string // trimmed and lowercased, as the vote form does it
+	Choices  []Choice
+}
+
+// Choice is an option a ballot picks. Rank is 1 for first choice on ranked
+// polls and 0 otherwise.
+type Choice struct {
+	Option db.Option
+	Rank   int64
+}
+
+// BallotValidator refuses ballots a poll's rules don't allow. It is asked
+// about every ballot cast on the vote form, the widget and the API, for
+// every poll, so it should return nil for polls it doesn't apply to. The
+// error's message is shown to the voter, who can change their ballot and
+// try again.
+type BallotValidator interface {
+	ValidateBallot(ctx context.Context, poll db.Category, b Ballot) error
+}
+
+// BallotValidatorFunc lets an ordinary function be a BallotValidator
+type BallotValidatorFunc func(ctx context.Context, poll db.Category, b Ballot) error
+
+func (f BallotValidatorFunc) ValidateBallot(ctx context.Context, poll db.Category, b Ballot) error {
+	return f(ctx, poll, b)
+}
+
+// TallyMethod counts a poll's ballots. Registered methods can be picked as
+// a poll's counting method and by votigo recount.
+type TallyMethod = tally.Method
+
+// Notifier announces polls opening and closing, and their results
+type Notifier = notify.Notifier
+
+var validators = map[string]BallotValidator{}
+
+// RegisterValidator adds v to the checks every ballot goes through. They
+// run in name order; registering the same name twice panics.
+func RegisterValidator(name string, v BallotValidator) {
+	if _, dup := validators[name]; dup {
+		panic("plugin: validator registered twice: " + name)
+	}
+	validators[name] = v
+}
+
+// Validators lists the registered validator names in sorted order
+func Validators() []string {
+	names := make([]string, 0, len(validators))
+	for name := range validators {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ValidateBallot asks every registered validator about b, in name order,
+// and returns the first refusal
+func ValidateBallot(ctx context.Context, poll db.Category, b Ballot) error {
+	for _, name := range Validators() {
+		if err := validators[name].ValidateBallot(ctx, poll, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterTallyMethod makes m available as a counting method under name,
+// which is what polls store, so renaming it leaves those polls counted the
+// default way until they are edited. Registering a name twice, including
+// one of the built-in methods, panics.
+func RegisterTallyMethod(name string, m TallyMethod) {
+	tally.Register(name, m)
+}
+
+// RegisterNotifier makes a notifier backend available to --notify as
+// "name=target", f building it from the target. Registering a name twice
+// panics.
+func RegisterNotifier(name string, f notify.Factory) {
+	notify.Register(name, f)
+}
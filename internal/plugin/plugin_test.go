@@ -0,0 +1,103 @@
+package plugin_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/plugin"
+	"github.com/palm-arcade/votigo/internal/plugin/example"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+func TestValidateBallot(t *testing.T) {
+	poll := db.Category{ID: 1, Name: "Best Player"}
+	ace := db.Option{ID: 1, Name: "Ace"}
+	bob := db.Option{ID: 2, Name: "Bob"}
+
+	// The example registers no-self-votes from init
+	if !slices.Contains(plugin.Validators(), "no-self-votes") {
+		t.Fatalf("expected no-self-votes registered, got %v", plugin.Validators())
+	}
+	err := plugin.ValidateBallot(context.Background(), poll, plugin.Ballot{Nickname: "ace", Choices: []plugin.Choice{{Option: ace}}})
+	if err == nil || !strings.Contains(err.Error(), "yourself") {
+		t.Errorf("expected a vote for yourself refused, got %v", err)
+	}
+	if err := plugin.ValidateBallot(context.Background(), poll, plugin.Ballot{Nickname: "ace", Choices: []plugin.Choice{{Option: bob}}}); err != nil {
+		t.Errorf("expected a vote for someone else accepted, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering no-self-votes twice to panic")
+		}
+	}()
+	plugin.RegisterValidator("no-self-votes", plugin.BallotValidatorFunc(example.NoSelfVotes))
+}
+
+func TestF1(t *testing.T) {
+	if _, ok := tally.Methods["f1"]; !ok {
+		t.Fatal("expected f1 registered as a counting method")
+	}
+	options := []tally.Option{{ID: 1}, {ID: 2}, {ID: 3}}
+	ballots := []tally.Ballot{
+		{1: 1, 2: 2},
+		{2: 1, 1: 2},
+		{2: 1, 3: 2},
+	}
+	result := example.F1(options, ballots)
+	var got []int64
+	for _, s := range result.Ranking {
+		got = append(got, s.Score)
+	}
+	if want := []int64{68, 43, 18}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected scores %v, got %v", want, got)
+	}
+	if winner, _ := result.Winner(); winner != 2 {
+		t.Errorf("expected option 2 to win, got %d", winner)
+	}
+}
+
+func TestNewOptionSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maps.txt")
+	if err := os.WriteFile(path, []byte("# maps\nde_dust2\n\n  cs_office \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, spec := range []string{path, "file=" + path} {
+		src, err := plugin.NewOptionSource(spec)
+		if err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+		got, err := src.Options(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"de_dust2", "cs_office"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v, got %v", spec, want, got)
+		}
+	}
+
+	src, err := plugin.NewOptionSource("range=Table 1-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := src.Options(context.Background())
+	if want := []string{"Table 1", "Table 2", "Table 3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	for spec, msg := range map[string]string{
+		"gsheet=abc":    "unknown option source",
+		"file=":         "expected source=target",
+		"range=Table 3": "expected a range",
+	} {
+		if _, err := plugin.NewOptionSource(spec); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%s: expected an error containing %q, got %v", spec, msg, err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Package publish writes the results of finished polls as a static
+// website, to host on the club webpage after the event without running
+// votigo.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/card"
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/tally"
+	"github.com/palm-arcade/votigo/templates"
+)
+
+// Poll is one finished poll on the site. Winner names the options sharing
+// first place.
+type Poll struct {
+	Category db.Category
+	Votes    int64
+	Winner   string
+	Results  []Result // best first
+}
+
+// Result is one option's standing. Score is votes, or points for ranked
+// polls. Place is shared by options that are Tied.
+type Result struct {
+	Name       string
+	Score      int64
+	Percentage int64
+	Place      int
+	Tied       bool
+}
+
+// page is what the site's templates render
+type page struct {
+	Brand     string
+	Title     string
+	Published time.Time
+	Polls     []Poll
+	*Poll
+}
+
+// Site writes every closed or archived poll's results to dir: an
+// index.html listing them and, per poll, {ref}/index.html with its results
+// table and {ref}/card.png with its results card. Links are relative, so
+// the site works from any path or straight off the disk. Files already in
+// dir are overwritten, others are left alone. It returns the polls
+// published.
+func Site(ctx context.Context, q *db.Queries, dir string) ([]Poll, error) {
+	index, err := parse("index.html")
+	if err != nil {
+		return nil, err
+	}
+	pollPage, err := parse("poll.html")
+	if err != nil {
+		return nil, err
+	}
+
+	brand, err := q.SettingValue(ctx, db.SettingEventName)
+	if err != nil {
+		return nil, err
+	}
+	if brand == "" {
+		brand = "Votigo"
+	}
+
+	polls, err := finishedPolls(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range polls {
+		p := &polls[i]
+		pollDir := filepath.Join(dir, p.Category.Ref())
+		if err := os.MkdirAll(pollDir, 0o755); err != nil {
+			return nil, err
+		}
+		data := page{Brand: brand, Title: p.Category.Name, Published: now, Poll: p}
+		if err := write(pollPage, filepath.Join(pollDir, "index.html"), data); err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Category.Name, err)
+		}
+		if err := writeCard(filepath.Join(pollDir, "card.png"), brand, *p); err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Category.Name, err)
+		}
+	}
+	if err := write(index, filepath.Join(dir, "index.html"), page{Brand: brand, Published: now, Polls: polls}); err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
+
+// finishedPolls tallies every closed or archived poll, in the order they
+// were created
+func finishedPolls(ctx context.Context, q *db.Queries) ([]Poll, error) {
+	categories, err := q.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(categories, func(a, b db.Category) int { return int(a.ID - b.ID) })
+
+	var polls []Poll
+	for _, cat := range categories {
+		if !cat.Finished() {
+			continue
+		}
+		votes, err := q.CountVotesByCategory(ctx, cat.ID)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := q.Tally(ctx, cat)
+		if err != nil {
+			return nil, err
+		}
+
+		// Shares as the results page works them out: a ranked option's is of
+		// the points it would have with every voter ranking it first
+		total := votes
+		if cat.VoteType == "ranked" {
+			maxRank := int64(3)
+			if cat.MaxRank.Valid {
+				maxRank = cat.MaxRank.Int64
+			}
+			total = votes * maxRank
+		}
+		p := Poll{Category: cat, Votes: votes}
+		var winners []string
+		for i, place := range db.Places(rows, votes) {
+			row := rows[i]
+			p.Results = append(p.Results, Result{Name: row.Name, Score: row.Score, Percentage: tally.Share(row.Score, total), Place: place.Rank, Tied: place.Tied})
+			if votes > 0 && place.Rank == 1 {
+				winners = append(winners, row.Name)
+			}
+		}
+		p.Winner = strings.Join(winners, " & ")
+		polls = append(polls, p)
+	}
+	return polls, nil
+}
+
+// parse parses one of the site's pages with its layout
+func parse(name string) (*template.Template, error) {
+	funcs := template.FuncMap{"add": func(a, b int) int { return a + b }}
+	return template.New("layout.html").Funcs(funcs).ParseFS(templates.FS, "site/layout.html", "site/"+name)
+}
+
+// write renders a page to path
+func write(tmpl *template.Template, path string, data page) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// writeCard draws a poll's results card to path, as the results page
+// offers it for download
+func writeCard(path, brand string, p Poll) error {
+	c := card.Card{Brand: brand, Title: p.Category.Name, Unit: "vote", TotalVotes: p.Votes}
+	if p.Category.VoteType == "ranked" {
+		c.Unit = "point"
+	}
+	for _, r := range p.Results {
+		c.Results = append(c.Results, card.Result{Name: r.Name, Score: r.Score, Place: r.Place})
+	}
+
+	var buf bytes.Buffer
+	if err := card.Render(&buf, c); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
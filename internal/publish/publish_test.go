@@ -0,0 +1,97 @@
+package publish_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/publish"
+)
+
+func TestSite(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := t.Context()
+	q := db.New(conn)
+	if _, err := q.SetSetting(ctx, db.SettingEventName, "Palm Arcade LAN"); err != nil {
+		t.Fatal(err)
+	}
+
+	// poll creates a poll with options, the first voted for by voters
+	poll := func(name, status string, voters ...string) db.Category {
+		cat, err := q.CreateCategory(ctx, db.CreateCategoryParams{
+			Name: name, VoteType: "single", Status: status, ShowResults: "after_close",
+			Slug: sql.NullString{String: strings.ToLower(strings.ReplaceAll(name, " ", "-")), Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("failed to create poll: %v", err)
+		}
+		var first db.Option
+		for i, option := range []string{"Doom", "Quake"} {
+			opt, err := q.CreateOption(ctx, db.CreateOptionParams{CategoryID: cat.ID, Name: option})
+			if err != nil {
+				t.Fatalf("failed to create option: %v", err)
+			}
+			if i == 0 {
+				first = opt
+			}
+		}
+		for _, nickname := range voters {
+			vote, err := q.UpsertVote(ctx, db.UpsertVoteParams{CategoryID: cat.ID, Nickname: nickname})
+			if err != nil {
+				t.Fatalf("failed to vote: %v", err)
+			}
+			if err := q.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: first.ID}); err != nil {
+				t.Fatalf("failed to vote: %v", err)
+			}
+		}
+		return cat
+	}
+	poll("Best Game", "closed", "ace", "bob")
+	poll("Best Mod", "archived")
+	poll("Best Map", "open", "ace")
+
+	dir := filepath.Join(t.TempDir(), "site")
+	polls, err := publish.Site(ctx, q, dir)
+	if err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+	if len(polls) != 2 || polls[0].Winner != "Doom" || polls[1].Winner != "" {
+		t.Fatalf("expected the closed and archived polls, Doom winning the first, got %+v", polls)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+		return string(data)
+	}
+	index := read("index.html")
+	for _, want := range []string{"Palm Arcade LAN", `href="best-game/index.html"`, "Winner: Doom", `href="best-mod/index.html"`} {
+		if !strings.Contains(index, want) {
+			t.Errorf("expected the index to contain %q", want)
+		}
+	}
+	if strings.Contains(index, "Best Map") {
+		t.Error("expected the open poll left out")
+	}
+	if page := read("best-game/index.html"); !strings.Contains(page, "2 (100%)") || !strings.Contains(page, `src="card.png"`) {
+		t.Error("expected the poll page to show Doom's votes and the results card")
+	}
+	if card := read("best-game/card.png"); !strings.HasPrefix(card, "\x89PNG") {
+		t.Error("expected a PNG results card")
+	}
+	if page := read("best-mod/index.html"); !strings.Contains(page, "No votes were cast") {
+		t.Error("expected the poll without votes to say so")
+	}
+}
@@ -0,0 +1,463 @@
+// Package qr encodes short text, such as a URL, as a QR code: byte mode,
+// medium error correction, versions 1 to 9 (up to 180 bytes). That covers
+// the links votigo hands to phones and kiosks without a dependency.
+package qr
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// MaxLen is the most bytes Encode takes
+const MaxLen = 180
+
+// quietZone is the light border scanners need around a code, in modules
+const quietZone = 4
+
+// ErrTooLong is returned for text past MaxLen
+var ErrTooLong = errors.New("qr: text too long")
+
+// blocks describes a version's error correction at level M: ecLen codewords
+// per block, and the data codewords in each block
+type blocks struct {
+	ecLen int
+	data  []int
+}
+
+// levelM is the block layout of versions 1 to 9 at error correction level M
+var levelM = []blocks{
+	1: {10, []int{16}},
+	2: {16, []int{28}},
+	3: {26, []int{44}},
+	4: {18, []int{32, 32}},
+	5: {24, []int{43, 43}},
+	6: {16, []int{27, 27, 27, 27}},
+	7: {18, []int{31, 31, 31, 31}},
+	8: {22, []int{38, 38, 39, 39}},
+	9: {22, []int{36, 36, 36, 37, 37}},
+}
+
+// alignment lists the alignment pattern centres of versions 1 to 9
+var alignment = [][]int{
+	1: nil,
+	2: {6, 18},
+	3: {6, 22},
+	4: {6, 26},
+	5: {6, 30},
+	6: {6, 34},
+	7: {6, 22, 38},
+	8: {6, 24, 42},
+	9: {6, 26, 46},
+}
+
+// Code is an encoded QR code, Size modules square, without its quiet zone
+type Code struct {
+	Size     int
+	modules  []bool // dark, row by row
+	function []bool // finder, timing, alignment and format modules
+}
+
+// Encode returns the smallest code holding text, masked for scanning
+func Encode(text string) (*Code, error) {
+	version := 0
+	for v := 1; v < len(levelM); v++ {
+		// Mode and 8-bit count, then the bytes
+		if 2+len(text) <= dataLen(v) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	best, bestPenalty := (*Code)(nil), 0
+	codewords := interleave(version, encodeData(version, text))
+	for mask := range 8 {
+		c := newCode(version)
+		c.placeData(codewords)
+		c.applyMask(mask)
+		c.drawFormat(mask)
+		if p := c.penalty(); best == nil || p < bestPenalty {
+			best, bestPenalty = c, p
+		}
+	}
+	return best, nil
+}
+
+// Dark reports whether the module at column x, row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y*c.Size+x]
+}
+
+// Image draws c with scale pixels per module inside a quiet zone
+func (c *Code) Image(scale int) image.Image {
+	side := (c.Size + 2*quietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := range c.Size {
+		for x := range c.Size {
+			if !c.Dark(x, y) {
+				continue
+			}
+			for py := range scale {
+				for px := range scale {
+					img.SetColorIndex((x+quietZone)*scale+px, (y+quietZone)*scale+py, 1)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// WritePNG writes c as a PNG with scale pixels per module
+func (c *Code) WritePNG(w io.Writer, scale int) error {
+	return png.Encode(w, c.Image(scale))
+}
+
+// dataLen is how many data codewords a version holds
+func dataLen(version int) int {
+	n := 0
+	for _, d := range levelM[version].data {
+		n += d
+	}
+	return n
+}
+
+// encodeData lays text out as byte-mode data codewords, padded to fill
+// version
+func encodeData(version int, text string) []byte {
+	var bits bitBuffer
+	bits.append(0b0100, 4) // byte mode
+	bits.append(len(text), 8)
+	for i := range len(text) {
+		bits.append(int(text[i]), 8)
+	}
+	capacity := dataLen(version) * 8
+	bits.append(0, min(4, capacity-len(bits))) // terminator
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	data := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			data[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return data
+}
+
+// interleave splits data into the version's blocks, adds each block's error
+// correction and interleaves them in the order they are placed
+func interleave(version int, data []byte) []byte {
+	b := levelM[version]
+	divisor := rsDivisor(b.ecLen)
+	var dataBlocks, ecBlocks [][]byte
+	for _, n := range b.data {
+		dataBlocks = append(dataBlocks, data[:n])
+		ecBlocks = append(ecBlocks, rsRemainder(data[:n], divisor))
+		data = data[n:]
+	}
+
+	var out []byte
+	longest := b.data[len(b.data)-1]
+	for i := range longest {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := range b.ecLen {
+		for _, block := range ecBlocks {
+			out = append(out, block[i])
+		}
+	}
+	return out
+}
+
+// newCode draws the function patterns of a version, leaving the format bits
+// reserved
+func newCode(version int) *Code {
+	size := 17 + 4*version
+	c := &Code{Size: size, modules: make([]bool, size*size), function: make([]bool, size*size)}
+
+	for i := range size {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(size-4, 3)
+	c.drawFinder(3, size-4)
+
+	centres := alignment[version]
+	for i, x := range centres {
+		for j, y := range centres {
+			// Not over the finders
+			if (i == 0 && j == 0) || (i == 0 && j == len(centres)-1) || (i == len(centres)-1 && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	c.drawFormat(0) // reserves the format modules until the mask is known
+	if version >= 7 {
+		rem := version
+		for range 12 {
+			rem = rem<<1 ^ (rem>>11)*0x1F25
+		}
+		bits := version<<12 | rem
+		for i := range 18 {
+			dark := bits>>i&1 == 1
+			a, b := size-11+i%3, i/3
+			c.setFunction(a, b, dark)
+			c.setFunction(b, a, dark)
+		}
+	}
+	return c
+}
+
+// drawFinder draws a finder pattern and its separator centred on x, y
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= c.Size || yy < 0 || yy >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawFormat draws both copies of the format bits for level M and mask,
+// and the dark module beside them
+func (c *Code) drawFormat(mask int) {
+	data := mask // level M is 00
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := range 6 {
+		c.setFunction(8, i, bit(i))
+	}
+	c.setFunction(8, 7, bit(6))
+	c.setFunction(8, 8, bit(7))
+	c.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		c.setFunction(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(i))
+	}
+	c.setFunction(8, c.Size-8, true)
+}
+
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y*c.Size+x] = dark
+	c.function[y*c.Size+x] = true
+}
+
+// placeData fills the modules left free by function patterns with
+// codewords, in two-column strips zigzagging up and down from the bottom
+// right
+func (c *Code) placeData(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // skip the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vert := range c.Size {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := range 2 {
+				x := right - j
+				if c.function[y*c.Size+x] || i >= len(codewords)*8 {
+					continue
+				}
+				c.modules[y*c.Size+x] = codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules chosen by mask
+func (c *Code) applyMask(mask int) {
+	for y := range c.Size {
+		for x := range c.Size {
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip && !c.function[y*c.Size+x] {
+				c.modules[y*c.Size+x] = !c.modules[y*c.Size+x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard c is to scan, by the spec's four rules: long runs
+// of one colour, 2×2 blocks, patterns that look like finders, and an
+// uneven balance of dark and light
+func (c *Code) penalty() int {
+	p := 0
+	line := make([]bool, c.Size)
+	for _, vertical := range []bool{false, true} {
+		for a := range c.Size {
+			for b := range c.Size {
+				if vertical {
+					line[b] = c.Dark(a, b)
+				} else {
+					line[b] = c.Dark(b, a)
+				}
+			}
+			p += linePenalty(line)
+		}
+	}
+
+	dark := 0
+	for y := range c.Size {
+		for x := range c.Size {
+			if c.Dark(x, y) {
+				dark++
+			}
+			if x > 0 && y > 0 {
+				d := c.Dark(x, y)
+				if c.Dark(x-1, y) == d && c.Dark(x, y-1) == d && c.Dark(x-1, y-1) == d {
+					p += 3
+				}
+			}
+		}
+	}
+	total := c.Size * c.Size
+	p += abs(dark*20-total*10) / total * 10
+	return p
+}
+
+// finderLike is a finder pattern's cross-section with light space after it
+var finderLike = []bool{true, false, true, true, true, false, true, false, false, false, false}
+
+// linePenalty scores runs and finder-like patterns in one row or column
+func linePenalty(line []bool) int {
+	p, run := 0, 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			p += run - 2
+		}
+		run = 1
+	}
+
+	for i := 0; i+len(finderLike) <= len(line); i++ {
+		forward, backward := true, true
+		for j, want := range finderLike {
+			forward = forward && line[i+j] == want
+			backward = backward && line[i+len(finderLike)-1-j] == want
+		}
+		if forward {
+			p += 40
+		}
+		if backward {
+			p += 40
+		}
+	}
+	return p
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of degree n,
+// highest coefficient first and the leading 1 left out
+func rsDivisor(n int) []byte {
+	result := make([]byte, n)
+	result[n-1] = 1
+	root := byte(1)
+	for range n {
+		for j := range n {
+			result[j] = gfMul(result[j], root)
+			if j+1 < n {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMul(root, 2)
+	}
+	return result
+}
+
+// rsRemainder returns the error correction codewords for data
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= gfMul(d, factor)
+		}
+	}
+	return result
+}
+
+// gfMul multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMul(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// bitBuffer is a sequence of bits, most significant first
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 == 1)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
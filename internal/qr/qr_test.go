@@ -0,0 +1,124 @@
+package qr_test
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/palm-arcade/votigo/internal/qr"
+)
+
+func TestEncodeVersion(t *testing.T) {
+	tests := []struct {
+		text string
+		size int
+	}{
+		{"HELLO", 21},
+		{strings.Repeat("x", 14), 21},
+		{strings.Repeat("x", 15), 25},
+		{"http://192.168.1.10:5000/pair/ABCDEFGHIJKLMNOPQRSTUVWXYZ", 33},
+		{strings.Repeat("x", qr.MaxLen), 53},
+	}
+	for _, tt := range tests {
+		c, err := qr.Encode(tt.text)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", len(tt.text), err)
+		}
+		if c.Size != tt.size {
+			t.Errorf("%d bytes: expected %d modules, got %d", len(tt.text), tt.size, c.Size)
+		}
+	}
+
+	if _, err := qr.Encode(strings.Repeat("x", qr.MaxLen+1)); !errors.Is(err, qr.ErrTooLong) {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestEncodePatterns(t *testing.T) {
+	c, err := qr.Encode("http://votigo.lan/pair/token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Finders in three corners
+	for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+		for dy := range 7 {
+			for dx := range 7 {
+				ring := max(abs(dx-3), abs(dy-3))
+				if c.Dark(corner[0]+dx, corner[1]+dy) != (ring != 2) {
+					t.Fatalf("finder at %v broken at %d,%d", corner, dx, dy)
+				}
+			}
+		}
+	}
+	// Timing patterns between them
+	for i := 8; i < c.Size-8; i++ {
+		if c.Dark(i, 6) != (i%2 == 0) || c.Dark(6, i) != (i%2 == 0) {
+			t.Fatalf("timing pattern broken at %d", i)
+		}
+	}
+
+	// Both copies of the format bits agree and name level M: the top two
+	// data bits, masked with 10101, read 10
+	var first, second int
+	for i := range 6 {
+		first |= bit(c.Dark(8, i)) << i
+	}
+	first |= bit(c.Dark(8, 7))<<6 | bit(c.Dark(8, 8))<<7 | bit(c.Dark(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		first |= bit(c.Dark(14-i, 8)) << i
+	}
+	for i := range 8 {
+		second |= bit(c.Dark(c.Size-1-i, 8)) << i
+	}
+	for i := 8; i < 15; i++ {
+		second |= bit(c.Dark(8, c.Size-15+i)) << i
+	}
+	if first != second {
+		t.Errorf("format copies differ: %015b and %015b", first, second)
+	}
+	if level := first >> 13; level != 0b10 {
+		t.Errorf("expected level M, got format %015b", first)
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	c, err := qr.Encode("HELLO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := c.WritePNG(&buf, 4); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 21 modules and a quiet zone of 4 each side
+	if got := img.Bounds().Dx(); got != (21+8)*4 {
+		t.Errorf("expected a %d pixel image, got %d", (21+8)*4, got)
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Error("expected a light quiet zone")
+	}
+	if r, _, _, _ := img.At(4*4, 4*4).RGBA(); r != 0 {
+		t.Error("expected the finder's corner dark")
+	}
+}
+
+func bit(dark bool) int {
+	if dark {
+		return 1
+	}
+	return 0
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,114 @@
+package roster
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+func init() {
+	Register("csv", func(path string) (Source, error) {
+		return CSVFile(path), nil
+	})
+}
+
+// CSVFile reads the roster from a CSV export at a path, afresh on every
+// sync. See ReadCSV for the columns.
+type CSVFile string
+
+func (f CSVFile) Attendees(ctx context.Context) ([]Attendee, error) {
+	file, err := os.Open(string(f))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadCSV(file)
+}
+
+// ReadCSV reads a roster export whose first row names the columns. It needs
+// a nickname column and uses a seat column if there is one, and a tags or
+// group column, separated by spaces, commas or semicolons, for the
+// attendee's tags; registration systems export more, which are ignored. Blank rows are skipped. Every
+// problem is reported, not just the first, so an export can be fixed in one
+// go.
+func ReadCSV(r io.Reader) ([]Attendee, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the roster is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("the roster isn't valid CSV: %w", err)
+	}
+
+	nicknameCol, seatCol := -1, -1
+	var tagCols []int
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "nickname":
+			nicknameCol = i
+		case "seat":
+			seatCol = i
+		case "tags", "group", "groups":
+			tagCols = append(tagCols, i)
+		}
+	}
+	if nicknameCol < 0 {
+		return nil, errors.New("the first row must name the columns, including nickname")
+	}
+
+	var attendees []Attendee
+	var errs []error
+	seen := make(map[string]int) // nickname to line
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := cr.FieldPos(0)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("the roster isn't valid CSV: %w", err))
+			break
+		}
+		field := func(i int) string {
+			if i >= 0 && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		nickname := normalize(field(nicknameCol))
+		if nickname == "" {
+			if strings.TrimSpace(strings.Join(record, "")) != "" {
+				errs = append(errs, fmt.Errorf("line %d: nickname is empty", line))
+			}
+			continue
+		}
+		if first, dup := seen[nickname]; dup {
+			errs = append(errs, fmt.Errorf("line %d: %s is already on line %d", line, nickname, first))
+			continue
+		}
+		seen[nickname] = line
+		a := Attendee{Nickname: nickname, Seat: field(seatCol)}
+		if tagCols != nil {
+			a.Tags = []string{}
+			for _, i := range tagCols {
+				a.Tags = append(a.Tags, strings.FieldsFunc(field(i), func(r rune) bool {
+					return r == ',' || r == ';' || unicode.IsSpace(r)
+				})...)
+			}
+		}
+		attendees = append(attendees, a)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return attendees, nil
+}
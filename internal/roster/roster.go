@@ -0,0 +1,78 @@
+// Package roster reads the attendee list from the LAN registration system,
+// so turnout can be shown by seat.
+package roster
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Attendee is one registered attendee. Nickname is normalized the way the
+// vote form does it (trimmed and lowercased), so it matches their ballots.
+// Tags are the groups the registration system puts them in, such as crew or
+// participant, as it spells them; nil if the source doesn't say, leaving
+// the tags set in admin alone.
+type Attendee struct {
+	Nickname string
+	Seat     string
+	Tags     []string
+}
+
+// Source is somewhere the roster can be read from, such as an exported CSV
+// file
+type Source interface {
+	Attendees(ctx context.Context) ([]Attendee, error)
+}
+
+// Factory builds a source from a source-specific target, usually a path or
+// URL
+type Factory func(target string) (Source, error)
+
+var sources = map[string]Factory{}
+
+// Register makes a source available to New under name. Sources register
+// themselves from init; registering the same name twice panics.
+func Register(name string, f Factory) {
+	if _, dup := sources[name]; dup {
+		panic("roster: source registered twice: " + name)
+	}
+	sources[name] = f
+}
+
+// Sources lists the registered source names in sorted order
+func Sources() []string {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// New builds a source from a "source=target" spec, for example
+// "csv=registrations.csv". A spec without a source name is a CSV file.
+func New(spec string) (Source, error) {
+	name, target, ok := strings.Cut(spec, "=")
+	if !ok {
+		name, target = "csv", spec
+	}
+	if target == "" {
+		return nil, fmt.Errorf("roster source %q: expected source=target", spec)
+	}
+	f, ok := sources[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown roster source %q (available: %s)", name, strings.Join(Sources(), ", "))
+	}
+	src, err := f(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s roster: %w", name, err)
+	}
+	return src, nil
+}
+
+// normalize trims and lowercases a nickname, as the vote form does
+func normalize(nickname string) string {
+	return strings.ToLower(strings.TrimSpace(nickname))
+}
@@ -0,0 +1,77 @@
+package roster_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/palm-arcade/votigo/internal/roster"
+)
+
+func TestReadCSV(t *testing.T) {
+	sheet := "\ufeffEmail,Nickname,Seat\n" +
+		"ace@example.com, Ace ,A1\n" +
+		"\n" +
+		"bob@example.com,bob\n"
+	got, err := roster.ReadCSV(strings.NewReader(sheet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []roster.Attendee{{Nickname: "ace", Seat: "A1"}, {Nickname: "bob"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Tags come from a tags or group column, or both; without either they
+	// are nil so tags set in admin are kept
+	got, err = roster.ReadCSV(strings.NewReader("nickname,group,tags\nace,Crew,\"player; caster\"\nbob,,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []roster.Attendee{{Nickname: "ace", Tags: []string{"Crew", "player", "caster"}}, {Nickname: "bob", Tags: []string{}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	tests := []struct {
+		sheet string
+		err   string
+	}{
+		{"", "empty"},
+		{"name,seat\nace,A1\n", "including nickname"},
+		{"nickname\nace\nACE\nx@y,\n", "line 3: ace is already on line 2"},
+		{"nickname,seat\n,A1\n", "line 2: nickname is empty"},
+	}
+	for _, tt := range tests {
+		_, err := roster.ReadCSV(strings.NewReader(tt.sheet))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%q: expected an error containing %q, got %v", tt.sheet, tt.err, err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roster.csv")
+	if err := os.WriteFile(path, []byte("nickname,seat\nace,A1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, spec := range []string{path, "csv=" + path} {
+		src, err := roster.New(spec)
+		if err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+		attendees, err := src.Attendees(t.Context())
+		if err != nil || len(attendees) != 1 || attendees[0].Seat != "A1" {
+			t.Errorf("%s: expected ace at A1, got %v, %v", spec, attendees, err)
+		}
+	}
+
+	if _, err := roster.New("http=https://registration.lan/api"); err == nil || !strings.Contains(err.Error(), "available: csv") {
+		t.Errorf("expected an unknown source error listing csv, got %v", err)
+	}
+	if _, err := roster.New("csv="); err == nil {
+		t.Error("expected an error for a source without a target")
+	}
+}
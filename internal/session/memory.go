@@ -0,0 +1,58 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory keeps sessions in a map. They are lost when the server stops.
+type Memory struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+func NewMemory() *Memory {
+	return &Memory{sessions: map[string]Session{}}
+}
+
+func (m *Memory) Get(ctx context.Context, id string) (Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok || s.Expired(time.Now()) {
+		return Session{}, ErrNotFound
+	}
+	return clone(s), nil
+}
+
+func (m *Memory) Save(ctx context.Context, s Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[s.ID] = clone(s)
+	return nil
+}
+
+func (m *Memory) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *Memory) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for id, s := range m.sessions {
+		if s.Expired(now) {
+			delete(m.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,55 @@
+// Package session keeps server-side sessions for admins and returning
+// voters. The store is pluggable: Memory suits tests and throwaway servers,
+// SQLite keeps sessions across a restart in the middle of an event.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"maps"
+	"time"
+)
+
+// ErrNotFound means there is no session with that ID, or it has expired
+var ErrNotFound = errors.New("session not found")
+
+// Session is what the server remembers about one browser between requests
+type Session struct {
+	ID        string
+	Values    map[string]string
+	ExpiresAt time.Time
+}
+
+// New starts a session with a random ID that expires after ttl
+func New(ttl time.Duration) Session {
+	return Session{
+		ID:        rand.Text(),
+		Values:    map[string]string{},
+		ExpiresAt: time.Now().Add(ttl).UTC().Truncate(time.Second),
+	}
+}
+
+// Expired reports whether the session has run out by now
+func (s Session) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// Store saves and loads sessions. Get returns ErrNotFound for expired
+// sessions even if DeleteExpired hasn't removed them yet.
+type Store interface {
+	Get(ctx context.Context, id string) (Session, error)
+	Save(ctx context.Context, s Session) error
+	Delete(ctx context.Context, id string) error
+	// DeleteExpired removes sessions that expired by now, returning how many
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// clone copies a session so callers can't change a stored one in place
+func clone(s Session) Session {
+	s.Values = maps.Clone(s.Values)
+	if s.Values == nil {
+		s.Values = map[string]string{}
+	}
+	return s
+}
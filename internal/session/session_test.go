@@ -0,0 +1,104 @@
+package session_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/session"
+)
+
+func stores(t *testing.T) map[string]session.Store {
+	t.Helper()
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetMaxOpenConns(1)
+	db.QuietMigrations()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return map[string]session.Store{
+		"memory": session.NewMemory(),
+		"sqlite": session.NewSQLite(db.New(conn)),
+	}
+}
+
+func TestStores(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := t.Context()
+
+			s := session.New(time.Hour)
+			s.Values["nickname"] = "alice"
+			if err := store.Save(ctx, s); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+
+			// Changing the caller's copy must not change the stored session
+			s.Values["nickname"] = "mallory"
+
+			got, err := store.Get(ctx, s.ID)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if got.Values["nickname"] != "alice" {
+				t.Errorf("nickname = %q, want alice", got.Values["nickname"])
+			}
+			if !got.ExpiresAt.Equal(s.ExpiresAt) {
+				t.Errorf("ExpiresAt = %v, want %v", got.ExpiresAt, s.ExpiresAt)
+			}
+
+			// Saving again replaces the values
+			got.Values["admin"] = "true"
+			if err := store.Save(ctx, got); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			if again, _ := store.Get(ctx, s.ID); again.Values["admin"] != "true" {
+				t.Errorf("values after resave = %v", again.Values)
+			}
+
+			if err := store.Delete(ctx, s.ID); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, err := store.Get(ctx, s.ID); !errors.Is(err, session.ErrNotFound) {
+				t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStoresExpire(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := t.Context()
+
+			expired := session.New(-time.Minute)
+			live := session.New(time.Hour)
+			for _, s := range []session.Session{expired, live} {
+				if err := store.Save(ctx, s); err != nil {
+					t.Fatalf("Save: %v", err)
+				}
+			}
+
+			if _, err := store.Get(ctx, expired.ID); !errors.Is(err, session.ErrNotFound) {
+				t.Errorf("Get expired: err = %v, want ErrNotFound", err)
+			}
+
+			n, err := store.DeleteExpired(ctx, time.Now())
+			if err != nil {
+				t.Fatalf("DeleteExpired: %v", err)
+			}
+			if n != 1 {
+				t.Errorf("DeleteExpired removed %d sessions, want 1", n)
+			}
+			if _, err := store.Get(ctx, live.ID); err != nil {
+				t.Errorf("Get live: %v", err)
+			}
+		})
+	}
+}
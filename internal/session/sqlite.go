@@ -0,0 +1,62 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// SQLite keeps sessions in the sessions table, so a restarted server still
+// knows who was logged in. Values are stored as a JSON object.
+type SQLite struct {
+	queries *db.Queries
+}
+
+func NewSQLite(queries *db.Queries) *SQLite {
+	return &SQLite{queries: queries}
+}
+
+func (st *SQLite) Get(ctx context.Context, id string) (Session, error) {
+	// SQLite compares times as text, so they are always stored in UTC
+	row, err := st.queries.GetSession(ctx, db.GetSessionParams{
+		ID:        id,
+		ExpiresAt: time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Session{}, ErrNotFound
+	}
+	if err != nil {
+		return Session{}, err
+	}
+
+	s := Session{ID: row.ID, ExpiresAt: row.ExpiresAt}
+	if err := json.Unmarshal([]byte(row.Data), &s.Values); err != nil {
+		return Session{}, fmt.Errorf("session %s: %w", id, err)
+	}
+	return clone(s), nil
+}
+
+func (st *SQLite) Save(ctx context.Context, s Session) error {
+	data, err := json.Marshal(clone(s).Values)
+	if err != nil {
+		return err
+	}
+	return st.queries.UpsertSession(ctx, db.UpsertSessionParams{
+		ID:        s.ID,
+		Data:      string(data),
+		ExpiresAt: s.ExpiresAt.UTC(),
+	})
+}
+
+func (st *SQLite) Delete(ctx context.Context, id string) error {
+	return st.queries.DeleteSession(ctx, id)
+}
+
+func (st *SQLite) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return st.queries.DeleteSessionsExpiredBefore(ctx, now.UTC())
+}
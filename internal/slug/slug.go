@@ -0,0 +1,74 @@
+// Package slug turns names into the short, readable identifiers used in
+// voter URLs and upload filenames.
+package slug
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MaxLen caps generated slugs so voter URLs stay short
+const MaxLen = 60
+
+// Make turns a name into a URL slug: lowercase letters, digits and single
+// hyphens. A name with nothing usable becomes "poll", and an all-digit
+// result gets a "poll-" prefix so it can't be mistaken for an ID.
+func Make(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '\'' || r == '’':
+			// drop apostrophes so "Editor's Choice" reads "editors-choice"
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		default:
+			hyphen = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > MaxLen {
+		slug = strings.TrimRight(slug[:MaxLen], "-")
+	}
+	switch {
+	case slug == "":
+		return "poll"
+	case !strings.ContainsFunc(slug, isLetter):
+		return "poll-" + slug
+	}
+	return slug
+}
+
+// Numbered returns the nth candidate for a slug based on base: base itself,
+// then base numbered "-2", "-3" and so on, shortened to stay within MaxLen
+func Numbered(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	suffix := "-" + strconv.Itoa(n)
+	return strings.TrimRight(base[:min(len(base), MaxLen-len(suffix))], "-") + suffix
+}
+
+// Valid reports whether s is a slug Make could have produced: at most
+// MaxLen lowercase letters, digits and single inner hyphens, with at least
+// one letter.
+func Valid(s string) bool {
+	if s == "" || len(s) > MaxLen || s[0] == '-' || s[len(s)-1] == '-' || strings.Contains(s, "--") {
+		return false
+	}
+	for _, r := range s {
+		if !isLetter(r) && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return strings.ContainsFunc(s, isLetter)
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z'
+}
@@ -0,0 +1,52 @@
+package slug_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/palm-arcade/votigo/internal/slug"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"Best Soundtrack", "best-soundtrack"},
+		{"  Editor's Choice!  ", "editors-choice"},
+		{"Best 2D -- Art", "best-2d-art"},
+		{"2024", "poll-2024"},
+		{"🎮", "poll"},
+		{strings.Repeat("a", 70), strings.Repeat("a", 60)},
+	}
+	for _, tt := range tests {
+		got := slug.Make(tt.name)
+		if got != tt.want {
+			t.Errorf("Make(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if !slug.Valid(got) {
+			t.Errorf("Make(%q) = %q, which Valid rejects", tt.name, got)
+		}
+	}
+
+	for _, s := range []string{"", "7", "-a", "a-", "a--b", "Best", "a b"} {
+		if slug.Valid(s) {
+			t.Errorf("expected Valid(%q) to be false", s)
+		}
+	}
+}
+
+func TestNumbered(t *testing.T) {
+	long := strings.Repeat("a", 59) + "-b"
+	tests := []struct {
+		base string
+		n    int
+		want string
+	}{
+		{"best-game", 1, "best-game"},
+		{"best-game", 2, "best-game-2"},
+		{long[:60], 12, strings.Repeat("a", 57) + "-12"},
+	}
+	for _, tt := range tests {
+		if got := slug.Numbered(tt.base, tt.n); got != tt.want || !slug.Valid(got) {
+			t.Errorf("Numbered(%q, %d) = %q, want %q", tt.base, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,520 @@
+// Package tally counts ballots. Each counting method takes a poll's options
+// and its ballots and ranks every option, best first, so the results page,
+// the API, the CLI and the simulator all count the same way.
+//
+// The package knows nothing about the database; db.Queries.Ballots loads a
+// poll's ballots in the form it takes.
+package tally
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Ballot maps each option a voter picked to its rank, 1 for first. Single
+// and approval ballots rank every pick first.
+type Ballot map[int64]int64
+
+// Option is a choice on the ballot. Methods keep the order options are
+// given in for ties, so pass them in the poll's order.
+type Option struct {
+	ID   int64
+	Name string
+}
+
+// Standing is an option's place under a method. Score is what the method
+// ranks by (votes, points, wins, a rating); Label says it in words.
+// FirstPlace counts the ballots ranking the option first, for methods that
+// break ties on it.
+type Standing struct {
+	OptionID   int64
+	Score      int64
+	FirstPlace int64
+	Label      string
+}
+
+// Result ranks every option, best first, with notes explaining how the
+// method got there (IRV rounds, the Condorcet winner)
+type Result struct {
+	Ranking []Standing
+	Notes   []string
+}
+
+// Method counts ballots one way
+type Method func(options []Option, ballots []Ballot) Result
+
+// Methods are the counting methods a poll can be counted by and `votigo
+// recount` offers, by name. STV elects more than one winner, so it is built
+// with STV instead. Plugins add theirs with Register.
+var Methods = map[string]Method{
+	"borda":     Borda,
+	"irv":       IRV,
+	"condorcet": Condorcet,
+	"elo":       Elo,
+}
+
+// Register makes a counting method available under name. Methods register
+// from init; registering a name twice panics.
+func Register(name string, m Method) {
+	if _, dup := Methods[name]; dup || name == "stv" {
+		panic("tally: method registered twice: " + name)
+	}
+	Methods[name] = m
+}
+
+// MethodNames lists the counting methods in Methods in sorted order
+func MethodNames() []string {
+	names := make([]string, 0, len(Methods))
+	for name := range Methods {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Winner returns the top option, or false if nothing was ranked
+func (r Result) Winner() (int64, bool) {
+	if len(r.Ranking) == 0 {
+		return 0, false
+	}
+	return r.Ranking[0].OptionID, true
+}
+
+// Tied reports whether the method couldn't tell two standings apart: it
+// gave them the same score, first places and label, so only the order the
+// options are listed in puts one above the other
+func (s Standing) Tied(o Standing) bool {
+	return s.Score == o.Score && s.FirstPlace == o.FirstPlace && s.Label == o.Label
+}
+
+// Place is where a standing finished. Rank counts from 1, and standings
+// that tie share the best of their ranks, the next rank skipping as many
+// as shared it (1, 2, 2, 4). Tied marks every standing sharing its rank.
+type Place struct {
+	Rank int
+	Tied bool
+}
+
+// Places returns the place of each standing in a ranking, best first
+func Places(ranking []Standing) []Place {
+	places := make([]Place, len(ranking))
+	for i, s := range ranking {
+		places[i].Rank = i + 1
+		if i > 0 && s.Tied(ranking[i-1]) {
+			places[i] = Place{Rank: places[i-1].Rank, Tied: true}
+			places[i-1].Tied = true
+		}
+	}
+	return places
+}
+
+// Simple is what single-choice and approval polls record: a vote for every
+// option picked
+func Simple(options []Option, ballots []Ballot) Result {
+	votes := make(map[int64]int64, len(options))
+	for _, b := range ballots {
+		for id := range b {
+			votes[id]++
+		}
+	}
+	return Result{
+		Ranking: rankBy(options,
+			func(id int64) int64 { return votes[id] },
+			func(id int64) string { return Plural(votes[id], "vote") }),
+	}
+}
+
+// Points is what ranked polls record: maxRank points for a first choice,
+// one fewer for each place below. Ties go to the option ranked first more
+// often.
+func Points(maxRank int64) Method {
+	return func(options []Option, ballots []Ballot) Result {
+		points := make(map[int64]int64, len(options))
+		firsts := make(map[int64]int64, len(options))
+		for _, b := range ballots {
+			for id, rank := range b {
+				points[id] += max(maxRank-rank+1, 0)
+				if rank == 1 {
+					firsts[id]++
+				}
+			}
+		}
+		sorted := slices.Clone(options)
+		slices.SortStableFunc(sorted, func(a, b Option) int {
+			return cmp.Or(cmp.Compare(points[b.ID], points[a.ID]), cmp.Compare(firsts[b.ID], firsts[a.ID]))
+		})
+		result := Result{Ranking: make([]Standing, len(sorted))}
+		for i, o := range sorted {
+			result.Ranking[i] = Standing{o.ID, points[o.ID], firsts[o.ID], Plural(points[o.ID], "point")}
+		}
+		return result
+	}
+}
+
+// Borda gives n-1 points for a first choice, n-2 for second and so on,
+// where n is the number of options
+func Borda(options []Option, ballots []Ballot) Result {
+	n := int64(len(options))
+	points := make(map[int64]int64, len(options))
+	for _, b := range ballots {
+		for id, rank := range b {
+			points[id] += max(n-rank, 0)
+		}
+	}
+	return Result{
+		Ranking: rankBy(options,
+			func(id int64) int64 { return points[id] },
+			func(id int64) string { return Plural(points[id], "point") }),
+	}
+}
+
+// IRV runs instant runoff rounds. Each ballot counts for its highest ranked
+// option still in the race; the option with the fewest votes is dropped
+// until one has a majority of the ballots still counting. Ties for last
+// drop the option listed later.
+func IRV(options []Option, ballots []Ballot) Result {
+	var result Result
+	if len(options) == 0 {
+		return result
+	}
+	remaining := slices.Clone(options)
+	var eliminated []Standing // in the order they were dropped
+
+	for round := 1; ; round++ {
+		counts := make(map[int64]int64, len(remaining))
+		var active int64
+		for _, b := range ballots {
+			if id, ok := topChoice(b, remaining); ok {
+				counts[id]++
+				active++
+			}
+		}
+
+		sorted := rankBy(remaining,
+			func(id int64) int64 { return counts[id] },
+			func(id int64) string { return fmt.Sprintf("%s in round %d", Plural(counts[id], "vote"), round) })
+
+		parts := make([]string, len(sorted))
+		for i, s := range sorted {
+			parts[i] = fmt.Sprintf("%s %d", optionName(options, s.OptionID), s.Score)
+		}
+		note := fmt.Sprintf("Round %d: %s", round, strings.Join(parts, ", "))
+
+		top := sorted[0]
+		if top.Score*2 > active || len(remaining) == 1 {
+			result.Notes = append(result.Notes, note+" - "+optionName(options, top.OptionID)+" wins")
+			slices.Reverse(eliminated)
+			result.Ranking = append(sorted, eliminated...)
+			return result
+		}
+
+		// rankBy keeps option order for ties, so the last row is the option
+		// listed later among those tied for last
+		last := sorted[len(sorted)-1]
+		result.Notes = append(result.Notes, note+" - "+optionName(options, last.OptionID)+" eliminated")
+		last.Label = fmt.Sprintf("out in round %d (%s)", round, Plural(last.Score, "vote"))
+		eliminated = append(eliminated, last)
+		remaining = slices.DeleteFunc(remaining, func(o Option) bool { return o.ID == last.OptionID })
+	}
+}
+
+// Condorcet compares every pair of options head to head and ranks them by
+// wins minus losses (Copeland). A voter prefers a to b if they ranked a
+// higher, or picked a and not b.
+func Condorcet(options []Option, ballots []Ballot) Result {
+	type record struct{ wins, losses, ties int64 }
+	records := make(map[int64]*record, len(options))
+	for _, o := range options {
+		records[o.ID] = &record{}
+	}
+
+	for i, a := range options {
+		for _, b := range options[i+1:] {
+			var forA, forB int64
+			for _, ballot := range ballots {
+				if prefers(ballot, a.ID, b.ID) {
+					forA++
+				} else if prefers(ballot, b.ID, a.ID) {
+					forB++
+				}
+			}
+			switch {
+			case forA > forB:
+				records[a.ID].wins++
+				records[b.ID].losses++
+			case forB > forA:
+				records[b.ID].wins++
+				records[a.ID].losses++
+			default:
+				records[a.ID].ties++
+				records[b.ID].ties++
+			}
+		}
+	}
+
+	result := Result{
+		Ranking: rankBy(options,
+			func(id int64) int64 { return records[id].wins - records[id].losses },
+			func(id int64) string {
+				r := records[id]
+				return fmt.Sprintf("%d-%d-%d (W-L-T)", r.wins, r.losses, r.ties)
+			}),
+	}
+	if len(options) == 0 {
+		return result
+	}
+
+	top := result.Ranking[0].OptionID
+	if records[top].wins == int64(len(options)-1) {
+		result.Notes = append(result.Notes, "Condorcet winner: "+optionName(options, top)+" beats every other option head to head")
+	} else {
+		result.Notes = append(result.Notes, "No Condorcet winner: no option beats every other head to head")
+	}
+	return result
+}
+
+// STV elects seats winners by single transferable vote. An option reaching
+// the Droop quota is elected and the part of its vote above the quota passes
+// on to the next choices on its ballots; when nobody reaches it, the option
+// with the fewest votes is dropped. Elected options come first in the order
+// they were elected.
+func STV(seats int) Method {
+	return func(options []Option, ballots []Ballot) Result {
+		var result Result
+		seats := min(max(seats, 1), len(options))
+		weights := make([]float64, len(ballots))
+		for i := range weights {
+			weights[i] = 1
+		}
+		quota := float64(len(ballots)/(seats+1) + 1)
+		result.Notes = append(result.Notes, fmt.Sprintf("Quota: %s to elect %d", Plural(int64(quota), "vote"), seats))
+
+		remaining := slices.Clone(options)
+		var elected, eliminated []Standing
+		var counts map[int64]float64
+		for round := 1; len(remaining) > 0; round++ {
+			counts = make(map[int64]float64, len(remaining))
+			holders := make(map[int64][]int, len(remaining))
+			for i, b := range ballots {
+				if id, ok := topChoice(b, remaining); ok && weights[i] > 0 {
+					counts[id] += weights[i]
+					holders[id] = append(holders[id], i)
+				}
+			}
+			sorted := slices.Clone(remaining)
+			slices.SortStableFunc(sorted, func(a, b Option) int { return cmp.Compare(counts[b.ID], counts[a.ID]) })
+
+			parts := make([]string, len(sorted))
+			for i, o := range sorted {
+				parts[i] = fmt.Sprintf("%s %s", o.Name, formatVotes(counts[o.ID]))
+			}
+			note := fmt.Sprintf("Round %d: %s", round, strings.Join(parts, ", "))
+
+			// Once the seats left would take every option still in the race,
+			// they all go through as they stand
+			if len(elected)+len(remaining) <= seats {
+				for _, o := range sorted {
+					elected = append(elected, Standing{OptionID: o.ID, Score: int64(counts[o.ID] + 0.5),
+						Label: fmt.Sprintf("elected in round %d (%s)", round, formatVotes(counts[o.ID]))})
+				}
+				result.Notes = append(result.Notes, note+" - the rest are elected")
+				break
+			}
+
+			if top := sorted[0]; counts[top.ID] >= quota {
+				votes := counts[top.ID]
+				for _, i := range holders[top.ID] {
+					weights[i] *= (votes - quota) / votes
+				}
+				elected = append(elected, Standing{OptionID: top.ID, Score: int64(votes + 0.5),
+					Label: fmt.Sprintf("elected in round %d (%s)", round, formatVotes(votes))})
+				result.Notes = append(result.Notes, note+" - "+top.Name+" elected")
+				remaining = slices.DeleteFunc(remaining, func(o Option) bool { return o.ID == top.ID })
+				if len(elected) == seats {
+					break
+				}
+				continue
+			}
+
+			last := sorted[len(sorted)-1]
+			eliminated = append(eliminated, Standing{OptionID: last.ID, Score: int64(counts[last.ID] + 0.5),
+				Label: fmt.Sprintf("out in round %d (%s)", round, formatVotes(counts[last.ID]))})
+			result.Notes = append(result.Notes, note+" - "+last.Name+" eliminated")
+			remaining = slices.DeleteFunc(remaining, func(o Option) bool { return o.ID == last.ID })
+		}
+
+		// Options neither elected nor dropped, because the seats filled
+		// first, follow the winners by their votes in the last round
+		slices.SortStableFunc(remaining, func(a, b Option) int { return cmp.Compare(counts[b.ID], counts[a.ID]) })
+		for _, o := range remaining {
+			if !slices.ContainsFunc(elected, func(s Standing) bool { return s.OptionID == o.ID }) {
+				elected = append(elected, Standing{OptionID: o.ID, Score: int64(counts[o.ID] + 0.5),
+					Label: fmt.Sprintf("not elected (%s)", formatVotes(counts[o.ID]))})
+			}
+		}
+		slices.Reverse(eliminated)
+		result.Ranking = append(elected, eliminated...)
+		return result
+	}
+}
+
+// eloK is how far one head-to-head result moves a rating
+const eloK = 32
+
+// Elo rates options like players: every ballot plays each pair of options
+// it ranks, in the order ballots were cast, and the preferred option takes
+// rating points from the other. Ratings start at 1500.
+func Elo(options []Option, ballots []Ballot) Result {
+	ratings := make(map[int64]float64, len(options))
+	for _, o := range options {
+		ratings[o.ID] = 1500
+	}
+	for _, b := range ballots {
+		for i, x := range options {
+			for _, y := range options[i+1:] {
+				winner, loser := x.ID, y.ID
+				switch {
+				case prefers(b, x.ID, y.ID):
+				case prefers(b, y.ID, x.ID):
+					winner, loser = y.ID, x.ID
+				default:
+					continue
+				}
+				expected := 1 / (1 + math.Pow(10, (ratings[loser]-ratings[winner])/400))
+				ratings[winner] += eloK * (1 - expected)
+				ratings[loser] -= eloK * (1 - expected)
+			}
+		}
+	}
+	rating := func(id int64) int64 { return int64(ratings[id] + 0.5) }
+	return Result{
+		Ranking: rankBy(options, rating,
+			func(id int64) string { return fmt.Sprintf("%d rating", rating(id)) }),
+		Notes: []string{"Ratings start at 1500; every ballot plays each pair of options it ranks"},
+	}
+}
+
+// Combine ranks options by two counts of the same poll at once, as judged
+// polls do with their judges' ballots and everyone else's. Each count is
+// turned into shares of the points it handed out, so a jury of five weighs
+// as much against two hundred voters as juryWeight, a percentage, says.
+// The combined score is out of 100 points, in tenths of a point; ties go to
+// the option the jury scored higher, then to the one listed first. Both
+// results' scores must add up, like votes or points, for shares to mean
+// anything.
+func Combine(options []Option, jury, audience Result, juryWeight int64) Result {
+	juryShare, audienceShare := shares(jury), shares(audience)
+	combined := make(map[int64]float64, len(options))
+	juryScore := make(map[int64]int64, len(options))
+	firsts := make(map[int64]int64, len(options))
+	for _, s := range jury.Ranking {
+		juryScore[s.OptionID] = s.Score
+		firsts[s.OptionID] += s.FirstPlace
+	}
+	for _, s := range audience.Ranking {
+		firsts[s.OptionID] += s.FirstPlace
+	}
+	for _, o := range options {
+		combined[o.ID] = float64(juryWeight)*juryShare[o.ID] + float64(100-juryWeight)*audienceShare[o.ID]
+	}
+
+	sorted := slices.Clone(options)
+	slices.SortStableFunc(sorted, func(a, b Option) int {
+		return cmp.Or(cmp.Compare(combined[b.ID], combined[a.ID]), cmp.Compare(juryScore[b.ID], juryScore[a.ID]))
+	})
+	result := Result{
+		Ranking: make([]Standing, len(sorted)),
+		Notes:   []string{fmt.Sprintf("Judges decide %d%% of the result and the audience %d%%", juryWeight, 100-juryWeight)},
+	}
+	for i, o := range sorted {
+		tenths := int64(math.Round(combined[o.ID] * 10))
+		result.Ranking[i] = Standing{o.ID, tenths, firsts[o.ID], fmt.Sprintf("%d.%d points", tenths/10, tenths%10)}
+	}
+	return result
+}
+
+// shares is each option's part of the points r handed out, from 0 to 1.
+// Every share is 0 when nothing was handed out.
+func shares(r Result) map[int64]float64 {
+	var total int64
+	for _, s := range r.Ranking {
+		total += max(s.Score, 0)
+	}
+	out := make(map[int64]float64, len(r.Ranking))
+	if total == 0 {
+		return out
+	}
+	for _, s := range r.Ranking {
+		out[s.OptionID] = float64(max(s.Score, 0)) / float64(total)
+	}
+	return out
+}
+
+// rankBy orders options by score, highest first, keeping their given order
+// for ties
+func rankBy(options []Option, score func(id int64) int64, label func(id int64) string) []Standing {
+	sorted := slices.Clone(options)
+	sort.SliceStable(sorted, func(i, j int) bool { return score(sorted[i].ID) > score(sorted[j].ID) })
+	ranking := make([]Standing, len(sorted))
+	for i, o := range sorted {
+		ranking[i] = Standing{OptionID: o.ID, Score: score(o.ID), Label: label(o.ID)}
+	}
+	return ranking
+}
+
+// topChoice returns the best ranked option on b that is still in the race.
+// Options ranked equally go to the one listed first.
+func topChoice(b Ballot, remaining []Option) (int64, bool) {
+	best, bestRank := int64(0), int64(0)
+	for _, o := range remaining {
+		if rank, ok := b[o.ID]; ok && (best == 0 || rank < bestRank) {
+			best, bestRank = o.ID, rank
+		}
+	}
+	return best, best != 0
+}
+
+// prefers reports whether b ranks x above y, or picks x and not y
+func prefers(b Ballot, x, y int64) bool {
+	rx, okx := b[x]
+	ry, oky := b[y]
+	return okx && (!oky || rx < ry)
+}
+
+func optionName(options []Option, id int64) string {
+	for _, o := range options {
+		if o.ID == id {
+			return o.Name
+		}
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+// Plural formats a count with its unit, e.g. "1 vote" or "2 votes"
+func Plural(n int64, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// Share is n as a whole percentage of total, 0 when there is no total
+func Share(n, total int64) int64 {
+	if total == 0 {
+		return 0
+	}
+	return n * 100 / total
+}
+
+// formatVotes formats a transferable vote count, with a decimal only once
+// surplus transfers make it fractional
+func formatVotes(v float64) string {
+	if v == float64(int64(v)) {
+		return Plural(int64(v), "vote")
+	}
+	return fmt.Sprintf("%.2f votes", v)
+}
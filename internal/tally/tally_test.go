@@ -0,0 +1,203 @@
+package tally_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+const a, b, c = 1, 2, 3
+
+var abc = []tally.Option{{ID: a, Name: "A"}, {ID: b, Name: "B"}, {ID: c, Name: "C"}}
+
+// repeat returns n copies of ballot
+func repeat(n int, ballot tally.Ballot) []tally.Ballot {
+	ballots := make([]tally.Ballot, n)
+	for i := range ballots {
+		ballots[i] = ballot
+	}
+	return ballots
+}
+
+// runoff is the textbook case where the plurality leader loses: A leads on
+// first choices, but C's voters prefer B, who wins once C is dropped
+var runoff = slices.Concat(
+	repeat(4, tally.Ballot{a: 1, b: 2}),
+	repeat(3, tally.Ballot{b: 1}),
+	repeat(2, tally.Ballot{c: 1, b: 2}),
+)
+
+func TestMethods(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  tally.Method
+		options []tally.Option
+		ballots []tally.Ballot
+		ranking []int64
+		scores  []int64
+		note    string
+	}{
+		{"simple", tally.Simple, abc,
+			[]tally.Ballot{{a: 1}, {b: 1}, {b: 1}, {a: 1, c: 1}},
+			[]int64{a, b, c}, []int64{2, 2, 1}, ""},
+		{"simple with no ballots", tally.Simple, abc, nil,
+			[]int64{a, b, c}, []int64{0, 0, 0}, ""},
+		{"points", tally.Points(3), abc,
+			[]tally.Ballot{{a: 2, b: 1}, {a: 2, b: 3, c: 1}},
+			[]int64{b, a, c}, []int64{4, 4, 3}, ""},
+		{"points ignores ranks past the limit", tally.Points(1), abc,
+			[]tally.Ballot{{a: 1, b: 2}, {b: 1, c: 2}},
+			[]int64{a, b, c}, []int64{1, 1, 0}, ""},
+		{"borda", tally.Borda, abc,
+			[]tally.Ballot{{a: 1, b: 2}, {b: 1, c: 2}, {b: 1, a: 2}},
+			[]int64{b, a, c}, []int64{5, 3, 1}, ""},
+		{"irv", tally.IRV, abc, runoff,
+			[]int64{b, a, c}, []int64{5, 4, 2}, "Round 2: B 5, A 4 - B wins"},
+		{"irv with a first round majority", tally.IRV, abc,
+			[]tally.Ballot{{c: 1}, {c: 1}, {a: 1}},
+			[]int64{c, a, b}, []int64{2, 1, 0}, "Round 1: C 2, A 1, B 0 - C wins"},
+		{"irv with no options", tally.IRV, nil, runoff, nil, nil, ""},
+		{"condorcet", tally.Condorcet, abc, runoff,
+			[]int64{b, a, c}, []int64{2, 0, -2}, "Condorcet winner: B"},
+		{"condorcet cycle", tally.Condorcet, abc,
+			[]tally.Ballot{{a: 1, b: 2, c: 3}, {b: 1, c: 2, a: 3}, {c: 1, a: 2, b: 3}},
+			[]int64{a, b, c}, []int64{0, 0, 0}, "No Condorcet winner"},
+		{"stv electing one is irv", tally.STV(1), abc, runoff,
+			[]int64{b, a, c}, []int64{5, 4, 2}, "Quota: 5 votes to elect 1"},
+		{"stv lists the unelected by their last count", tally.STV(1), abc,
+			[]tally.Ballot{{a: 1}, {a: 1}, {a: 1}, {c: 1}, {b: 1}},
+			[]int64{a, b, c}, []int64{3, 1, 1}, "A elected"},
+		{"stv transfers surplus votes", tally.STV(2), abc,
+			slices.Concat(repeat(7, tally.Ballot{a: 1, b: 2}), repeat(1, tally.Ballot{b: 1}), repeat(3, tally.Ballot{c: 1})),
+			[]int64{a, b, c}, nil, "A elected"},
+		{"stv with more seats than options", tally.STV(5), abc,
+			[]tally.Ballot{{a: 1}, {b: 1}, {b: 1}},
+			[]int64{b, a, c}, []int64{2, 1, 0}, "the rest are elected"},
+		{"elo", tally.Elo, abc[:2],
+			[]tally.Ballot{{a: 1, b: 2}},
+			[]int64{a, b}, []int64{1516, 1484}, ""},
+		{"elo after an upset", tally.Elo, abc[:2],
+			[]tally.Ballot{{a: 1}, {a: 1}, {b: 1}},
+			[]int64{a, b}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.method(tt.options, tt.ballots)
+			var ranking, scores []int64
+			for _, s := range result.Ranking {
+				ranking = append(ranking, s.OptionID)
+				scores = append(scores, s.Score)
+			}
+			if !slices.Equal(ranking, tt.ranking) {
+				t.Errorf("ranking = %v, want %v", ranking, tt.ranking)
+			}
+			if tt.scores != nil && !slices.Equal(scores, tt.scores) {
+				t.Errorf("scores = %v, want %v", scores, tt.scores)
+			}
+			if tt.note != "" && !slices.ContainsFunc(result.Notes, func(n string) bool { return strings.Contains(n, tt.note) }) {
+				t.Errorf("notes = %q, want one containing %q", result.Notes, tt.note)
+			}
+		})
+	}
+}
+
+func TestLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		method tally.Method
+		want   []string
+	}{
+		{"simple", tally.Simple, []string{"9 votes", "4 votes", "2 votes"}},
+		{"irv", tally.IRV, []string{"5 votes in round 2", "4 votes in round 2", "out in round 1 (2 votes)"}},
+		{"condorcet", tally.Condorcet, []string{"2-0-0 (W-L-T)", "1-1-0 (W-L-T)", "0-2-0 (W-L-T)"}},
+	}
+	for _, tt := range tests {
+		result := tt.method(abc, runoff)
+		var labels []string
+		for _, s := range result.Ranking {
+			labels = append(labels, s.Label)
+		}
+		if !slices.Equal(labels, tt.want) {
+			t.Errorf("%s labels = %q, want %q", tt.name, labels, tt.want)
+		}
+	}
+}
+
+func TestPointsFirstPlace(t *testing.T) {
+	result := tally.Points(3)(abc, runoff)
+	firsts := make(map[int64]int64)
+	for _, s := range result.Ranking {
+		firsts[s.OptionID] = s.FirstPlace
+	}
+	if firsts[a] != 4 || firsts[b] != 3 || firsts[c] != 2 {
+		t.Errorf("first place counts = %v", firsts)
+	}
+}
+
+func TestWinner(t *testing.T) {
+	if _, ok := tally.Simple(nil, nil).Winner(); ok {
+		t.Error("expected no winner without options")
+	}
+	if id, ok := tally.IRV(abc, runoff).Winner(); !ok || id != b {
+		t.Errorf("Winner() = %d, %v, want %d", id, ok, b)
+	}
+}
+
+func TestPlaces(t *testing.T) {
+	d := int64(4)
+	options := append(slices.Clone(abc), tally.Option{ID: d, Name: "D"})
+	// B and C tie for second; D, with no votes, is fourth
+	ballots := slices.Concat(
+		repeat(3, tally.Ballot{a: 1}),
+		repeat(2, tally.Ballot{b: 1}),
+		repeat(2, tally.Ballot{c: 1}),
+	)
+	want := []tally.Place{{Rank: 1}, {Rank: 2, Tied: true}, {Rank: 2, Tied: true}, {Rank: 4}}
+	if got := tally.Places(tally.Simple(options, ballots).Ranking); !slices.Equal(got, want) {
+		t.Errorf("Places() = %v, want %v", got, want)
+	}
+
+	// All three have 6 points, but B was ranked first less often
+	points := tally.Points(3)(abc, []tally.Ballot{{a: 1}, {a: 1}, {b: 1}, {c: 1, b: 2}, {c: 1, b: 3}})
+	want = []tally.Place{{Rank: 1, Tied: true}, {Rank: 1, Tied: true}, {Rank: 3}}
+	if got := tally.Places(points.Ranking); !slices.Equal(got, want) || points.Ranking[2].OptionID != b {
+		t.Errorf("Places() = %v for %v, want %v with B last", got, points.Ranking, want)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	jury := tally.Simple(abc, repeat(2, tally.Ballot{a: 1}))
+	audience := tally.Simple(abc, slices.Concat(repeat(6, tally.Ballot{b: 1}), repeat(4, tally.Ballot{c: 1})))
+
+	for _, tt := range []struct {
+		weight int64
+		want   []string
+	}{
+		{50, []string{"A 50.0 points", "B 30.0 points", "C 20.0 points"}},
+		{20, []string{"B 48.0 points", "C 32.0 points", "A 20.0 points"}},
+	} {
+		result := tally.Combine(abc, jury, audience, tt.weight)
+		var got []string
+		for _, s := range result.Ranking {
+			got = append(got, abc[s.OptionID-1].Name+" "+s.Label)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("weight %d: got %v, want %v", tt.weight, got, tt.want)
+		}
+	}
+
+	// Level on points, B wins on the jury's scores
+	jury = tally.Simple(abc, []tally.Ballot{{b: 1}})
+	audience = tally.Simple(abc, repeat(3, tally.Ballot{a: 1}))
+	if id, _ := tally.Combine(abc, jury, audience, 50).Winner(); id != b {
+		t.Errorf("expected the jury to break the tie for %d, got %d", b, id)
+	}
+
+	// Before the jury scores, the audience's share is all there is
+	result := tally.Combine(abc, tally.Simple(abc, nil), audience, 50)
+	if s := result.Ranking[0]; s.OptionID != a || s.Score != 500 {
+		t.Errorf("expected A on 50.0 points, got %+v", s)
+	}
+}
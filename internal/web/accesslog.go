@@ -0,0 +1,100 @@
+package web
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WithAccessLog writes a line per request to w in the combined log format
+// Apache and nginx use, so tools like goaccess can read it
+func WithAccessLog(w io.Writer) Option {
+	return func(s *Server) {
+		s.accessLog = w
+	}
+}
+
+// statusRecorder remembers the status and size of a response for the access
+// log. Unwrap lets http.ResponseController reach the real writer.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(p)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+func (rec *statusRecorder) Flush() {
+	http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// withAccessLog logs each request once it has been answered
+func (s *Server) withAccessLog(next http.Handler) http.Handler {
+	if s.accessLog == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+
+		if _, err := io.WriteString(s.accessLog, combinedLogLine(r, rec.status, rec.bytes, start)); err != nil {
+			log.Printf("Failed to write access log: %v", err)
+		}
+	})
+}
+
+// combinedLogLine formats a request in the combined log format:
+//
+//	host ident user [time] "request" status bytes "referer" "user-agent"
+//
+// The user is the admin's basic auth name, the only login votigo has.
+func combinedLogLine(r *http.Request, status int, bytes int64, start time.Time) string {
+	host := clientHost(r)
+	user := "-"
+	if name, _, ok := r.BasicAuth(); ok && name != "" {
+		user = name
+	}
+	if status == 0 {
+		// The handler wrote nothing, which net/http sends as an empty 200
+		status = http.StatusOK
+	}
+	size := "-"
+	if bytes > 0 {
+		size = strconv.FormatInt(bytes, 10)
+	}
+
+	return fmt.Sprintf("%s - %s [%s] %s %d %s %s %s\n",
+		host, user, start.Format("02/Jan/2006:15:04:05 -0700"),
+		strconv.Quote(r.Method+" "+r.URL.RequestURI()+" "+r.Proto),
+		status, size,
+		strconv.Quote(orDash(r.Referer())), strconv.Quote(orDash(r.UserAgent())))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,38 @@
+package web
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"sync/atomic"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// WithAdminPasswordHash checks admin logins against a stored hash (see
+// db.HashPassword) instead of the password given to NewServer, which should
+// then be empty
+func WithAdminPasswordHash(h db.PasswordHash) Option {
+	return func(s *Server) {
+		s.adminHash = &adminHash{hash: h}
+	}
+}
+
+// adminHash checks admin passwords against a stored hash. Hashing is slow
+// on purpose and basic auth sends the password with every request, so the
+// password that last matched is remembered as a quick SHA-256.
+type adminHash struct {
+	hash    db.PasswordHash
+	matched atomic.Pointer[[sha256.Size]byte]
+}
+
+func (a *adminHash) matches(password string) bool {
+	sum := sha256.Sum256([]byte(password))
+	if m := a.matched.Load(); m != nil && subtle.ConstantTimeCompare(m[:], sum[:]) == 1 {
+		return true
+	}
+	if !a.hash.Matches(password) {
+		return false
+	}
+	a.matched.Store(&sum)
+	return true
+}
@@ -0,0 +1,43 @@
+package web
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/notify"
+)
+
+// announceTimeout bounds how long a batch of notifications may take
+const announceTimeout = 30 * time.Second
+
+// announce sends lifecycle events for a category to the configured notifier.
+// Delivery happens in the background so a slow webhook never holds up the
+// admin; failures are only logged.
+func (s *Server) announce(categoryID int64, types ...notify.EventType) {
+	notifier := s.currentNotifier()
+	if notifier == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), announceTimeout)
+		defer cancel()
+
+		cat, err := s.queries.Category(ctx, categoryID)
+		if err != nil {
+			log.Printf("Failed to load category %d for notification: %v", categoryID, err)
+			return
+		}
+
+		for _, typ := range types {
+			ev, err := notify.NewEvent(ctx, s.queries, typ, cat)
+			if err == nil {
+				err = notifier.Notify(ctx, ev)
+			}
+			if err != nil {
+				log.Printf("Failed to send %s notification for category %d: %v", typ, categoryID, err)
+			}
+		}
+	}()
+}
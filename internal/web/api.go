@@ -0,0 +1,861 @@
+package web
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// apiPrefix is the root of the versioned JSON API
+const apiPrefix = "/api/v1"
+
+const (
+	// maxResultsWait caps the ?wait= long-poll so proxies don't cut it off
+	maxResultsWait = 60 * time.Second
+	// resultsPollInterval is how often a long-poll re-tallies. Votes can come
+	// from the CLI in another process, so polling the database is the only
+	// reliable signal.
+	resultsPollInterval = time.Second
+	// maxBatchBallots caps the polls one POST /api/v1/ballots may vote in
+	maxBatchBallots = 64
+)
+
+// apiCategories is the body of GET /api/v1/categories
+type apiCategories struct {
+	Categories []apiCategory `json:"categories"`
+}
+
+// apiCategory is a poll as the API lists it. MaxRank is only given for
+// ranked polls, OpensAt and ClosesAt only when planned. ResultsVisible says
+// whether GET /api/v1/results/{id} shows its tally yet.
+type apiCategory struct {
+	ID             int64      `json:"id"`
+	Name           string     `json:"name"`
+	Slug           string     `json:"slug,omitempty"`
+	VoteType       string     `json:"vote_type"`
+	Status         string     `json:"status"`
+	MaxRank        int64      `json:"max_rank,omitempty"`
+	Color          string     `json:"color,omitempty"`
+	Icon           string     `json:"icon,omitempty"`
+	OpensAt        *time.Time `json:"opens_at,omitempty"`
+	ClosesAt       *time.Time `json:"closes_at,omitempty"`
+	ResultsVisible bool       `json:"results_visible"`
+}
+
+// apiCategoryDetail is the body of GET /api/v1/categories/{id}: the poll
+// and the options on its ballot, in ballot order
+type apiCategoryDetail struct {
+	apiCategory
+	Options []apiOption `json:"options"`
+}
+
+// apiOption is an option voters can choose, with the details the ballot
+// shows for it
+type apiOption struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	Image       string   `json:"image,omitempty"`
+	Blurb       string   `json:"blurb,omitempty"`
+	Video       string   `json:"video,omitempty"`
+	Screenshots []string `json:"screenshots,omitempty"`
+}
+
+// apiVoteRequest is the body of POST /api/v1/categories/{id}/votes. For
+// ranked categories Choices lists option IDs in preference order.
+type apiVoteRequest struct {
+	Nickname string  `json:"nickname"`
+	Choices  []int64 `json:"choices"`
+}
+
+// apiVoteResponse answers POST /api/v1/categories/{id}/votes. Held says
+// why a recorded ballot doesn't count as cast (see receiptFor).
+type apiVoteResponse struct {
+	CategoryID int64  `json:"category_id"`
+	Nickname   string `json:"nickname"`
+	Status     string `json:"status"`
+	Receipt    string `json:"receipt,omitempty"`
+	Held       string `json:"held,omitempty"`
+}
+
+// apiBallotsRequest is the body of POST /api/v1/ballots: one voter's
+// ballots for several polls, saved together or not at all
+type apiBallotsRequest struct {
+	Nickname string      `json:"nickname"`
+	Ballots  []apiBallot `json:"ballots"`
+}
+
+// apiBallot is one poll's choices in an apiBallotsRequest, as in
+// apiVoteRequest
+type apiBallot struct {
+	CategoryID int64   `json:"category_id"`
+	Choices    []int64 `json:"choices"`
+}
+
+// apiBallotsResponse answers POST /api/v1/ballots. Status is "recorded" when
+// every ballot was saved and "rejected" when none was; Ballots says why, in
+// the order they were sent.
+type apiBallotsResponse struct {
+	Nickname string            `json:"nickname"`
+	Status   string            `json:"status"`
+	Ballots  []apiBallotResult `json:"ballots"`
+}
+
+// apiBallotResult is one ballot's outcome: "recorded", "rejected" with the
+// Error that stopped the batch, or "not_saved" when it was fine but another
+// was rejected. Held is as in apiVoteResponse.
+type apiBallotResult struct {
+	CategoryID int64  `json:"category_id"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	Receipt    string `json:"receipt,omitempty"`
+	Held       string `json:"held,omitempty"`
+}
+
+// apiResults is the body of GET /api/v1/results/{id}. Results is omitted
+// while the category hides its results until it closes.
+type apiResults struct {
+	CategoryID int64             `json:"category_id"`
+	Name       string            `json:"name"`
+	VoteType   string            `json:"vote_type"`
+	Status     string            `json:"status"`
+	Visible    bool              `json:"visible"`
+	TotalVotes int64             `json:"total_votes"`
+	Results    []apiOptionResult `json:"results,omitempty"`
+}
+
+// apiOptionResult is one option's tally. Ranked categories report Borda
+// points and first-place votes; other types report plain vote counts.
+// Options that tie share a place and are marked tied.
+type apiOptionResult struct {
+	OptionID   int64  `json:"option_id"`
+	Name       string `json:"name"`
+	Place      int    `json:"place"`
+	Tied       bool   `json:"tied,omitempty"`
+	Votes      int64  `json:"votes,omitempty"`
+	Points     int64  `json:"points,omitempty"`
+	FirstPlace int64  `json:"first_place,omitempty"`
+	Image      string `json:"image,omitempty"`
+}
+
+// apiFeed is the body of GET /api/v1/feed: the polls open for voting, for
+// info screens to rotate through
+type apiFeed struct {
+	Polls []apiFeedPoll `json:"polls"`
+}
+
+// apiFeedPoll is one open poll. ClosesAt is the planned closing time, null
+// when none is set; ResultsURL is only given when results are live. ShortURL
+// redirects to VoteURL and makes for a smaller QR code.
+type apiFeedPoll struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	VoteType   string     `json:"vote_type"`
+	Color      string     `json:"color,omitempty"`
+	Icon       string     `json:"icon,omitempty"`
+	Votes      int64      `json:"votes"`
+	ClosesAt   *time.Time `json:"closes_at"`
+	VoteURL    string     `json:"vote_url"`
+	ShortURL   string     `json:"short_url"`
+	ResultsURL string     `json:"results_url,omitempty"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	path, ok := strings.CutPrefix(r.URL.Path, apiPrefix+"/")
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	parts := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	if !negotiateJSON(w, r) {
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && parts[0] == "categories":
+		s.handleAPICategories(w, r)
+	case len(parts) == 2 && parts[0] == "categories":
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			writeAPIError(w, http.StatusNotFound, "Not found")
+			return
+		}
+		s.handleAPICategory(w, r, id)
+	case len(parts) == 3 && parts[0] == "categories" && parts[2] == "votes":
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			writeAPIError(w, http.StatusNotFound, "Not found")
+			return
+		}
+		s.handleAPIVote(w, r, id)
+	case len(parts) == 1 && parts[0] == "feed":
+		s.handleAPIFeed(w, r)
+	case len(parts) == 1 && parts[0] == "ballots":
+		s.handleAPIBallots(w, r)
+	case len(parts) == 2 && parts[0] == "results":
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			writeAPIError(w, http.StatusNotFound, "Not found")
+			return
+		}
+		s.handleAPIResults(w, r, id)
+	default:
+		writeAPIError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// negotiateJSON refuses requests the API can't answer in kind: ones whose
+// Accept header rules out JSON (406) and bodies sent as anything but JSON
+// (415). Requests without the headers are taken to speak JSON. The refusal
+// is JSON all the same, as every API error is.
+func negotiateJSON(w http.ResponseWriter, r *http.Request) bool {
+	if accept := r.Header.Get("Accept"); accept != "" && !acceptsJSON(accept) {
+		writeAPIError(w, http.StatusNotAcceptable, "The API only answers in application/json")
+		return false
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" && r.Method == http.MethodPost {
+		if mediaType, _, err := mime.ParseMediaType(ct); err != nil || mediaType != "application/json" {
+			writeAPIError(w, http.StatusUnsupportedMediaType, "Send the body as application/json")
+			return false
+		}
+	}
+	return true
+}
+
+// acceptsJSON reports whether an Accept header allows application/json,
+// directly or by a wildcard that isn't given q=0
+func acceptsJSON(accept string) bool {
+	for _, media := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(media))
+		if err != nil {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		switch mediaType {
+		case "application/json", "application/*", "*/*":
+			return true
+		}
+	}
+	return false
+}
+
+// handleAPICategories lists the polls voters can see, oldest first: open,
+// closed and, for admins, draft ones. ?status=open or ?status=closed lists
+// only those.
+func (s *Server) handleAPICategories(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status != "" && status != "open" && status != "closed" {
+		writeAPIError(w, http.StatusBadRequest, "status must be open or closed")
+		return
+	}
+
+	categories, err := s.reads.ListCategoriesExcludeArchived(r.Context())
+	if err != nil {
+		log.Printf("Error: failed to list categories: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to load categories")
+		return
+	}
+	list := apiCategories{Categories: []apiCategory{}}
+	for _, cat := range categories {
+		if (cat.Status == "draft" && !s.authorized(r)) || (status != "" && cat.Status != status) {
+			continue
+		}
+		list.Categories = append(list.Categories, newAPICategory(cat))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleAPICategory describes one poll with its ballot's options. Drafts
+// are only shown to admins.
+func (s *Server) handleAPICategory(w http.ResponseWriter, r *http.Request, categoryID int64) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+
+	cat, err := s.reads.Category(r.Context(), categoryID)
+	if errors.Is(err, db.ErrNotFound) || (err == nil && cat.Status == "draft" && !s.authorized(r)) {
+		writeAPIError(w, http.StatusNotFound, "Category not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error: failed to load category %d: %v", categoryID, err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to load category")
+		return
+	}
+	options, err := s.reads.ListBallotOptionsByCategory(r.Context(), cat.ID)
+	if err != nil {
+		log.Printf("Error: failed to load options for category %d: %v", cat.ID, err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to load options")
+		return
+	}
+
+	detail := apiCategoryDetail{apiCategory: newAPICategory(cat), Options: []apiOption{}}
+	for _, o := range options {
+		detail.Options = append(detail.Options, apiOption{
+			ID:          o.ID,
+			Name:        o.Name,
+			Image:       o.Image,
+			Blurb:       o.Blurb,
+			Video:       o.Video,
+			Screenshots: o.ScreenshotURLs(),
+		})
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// newAPICategory describes cat as the API lists it
+func newAPICategory(cat db.Category) apiCategory {
+	c := apiCategory{
+		ID:             cat.ID,
+		Name:           cat.Name,
+		Slug:           cat.Slug.String,
+		VoteType:       cat.VoteType,
+		Status:         cat.Status,
+		Color:          cat.Color,
+		Icon:           cat.Icon,
+		ResultsVisible: cat.ShowResults != "after_close" || cat.Finished(),
+	}
+	if cat.VoteType == "ranked" {
+		c.MaxRank = maxRankFor(cat)
+	}
+	if cat.OpensAt.Valid {
+		opensAt := cat.OpensAt.Time.UTC()
+		c.OpensAt = &opensAt
+	}
+	if cat.ClosesAt.Valid {
+		closesAt := cat.ClosesAt.Time.UTC()
+		c.ClosesAt = &closesAt
+	}
+	return c
+}
+
+// handleAPIVote records a ballot for one category. It applies the same rules
+// as the vote form, so a re-submitted ballot replaces the voter's earlier one.
+// An Idempotency-Key header that was already used returns the original
+// response without recording anything.
+func (s *Server) handleAPIVote(w http.ResponseWriter, r *http.Request, categoryID int64) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	var req apiVoteRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	cat, err := s.queries.Category(r.Context(), categoryID)
+	if errors.Is(err, db.ErrNotFound) {
+		writeAPIError(w, http.StatusNotFound, "Category not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error: failed to load category %d: %v", categoryID, err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to load category")
+		return
+	}
+
+	if err := cat.CheckOpen(); err != nil {
+		writeAPIError(w, http.StatusConflict, "Voting is not open for this category")
+		return
+	}
+
+	nickname := normalizeNickname(req.Nickname)
+	if nickname == "" {
+		writeAPIError(w, http.StatusBadRequest, "Please enter a nickname")
+		return
+	}
+
+	options, err := s.queries.ListBallotOptionsByCategory(r.Context(), cat.ID)
+	if err != nil {
+		log.Printf("Error: failed to load options for category %d: %v", cat.ID, err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to load options")
+		return
+	}
+
+	selections, errMsg := ballot{Nickname: nickname, Choices: req.Choices}.selections(cat, options)
+	if errMsg == "" {
+		errMsg = pluginRefusal(r.Context(), cat, nickname, options, selections)
+	}
+	if errMsg != "" {
+		writeAPIError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	remote, err := s.ballotOrigin(r, cat)
+	if err != nil {
+		writeAPIError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	origin, err := s.requestOrigin(r)
+	if err != nil {
+		writeAPIError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	refused, err := s.ineligible(r.Context(), cat, nickname)
+	if err != nil {
+		log.Printf("Error: failed to check eligibility for category %d: %v", cat.ID, err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to check who can vote")
+		return
+	}
+	if refused != "" {
+		writeAPIError(w, http.StatusForbidden, refused)
+		return
+	}
+
+	// Offline sync may resend a ballot the server already saw; the key from
+	// the rendered form makes the replay a no-op.
+	err = s.castBallot(r.Context(), pendingBallot{
+		cat:            cat,
+		nickname:       nickname,
+		selections:     selections,
+		idempotencyKey: r.Header.Get(idempotencyHeader),
+		remote:         remote,
+		origin:         origin,
+	})
+	if errors.Is(err, db.ErrClosed) {
+		writeAPIError(w, http.StatusConflict, "Voting is not open for this category")
+		return
+	}
+	if errors.Is(err, errIdempotencyKeyReused) {
+		writeAPIError(w, http.StatusUnprocessableEntity, "The Idempotency-Key was already used for a different ballot; send each ballot with its own key")
+		return
+	}
+	if err != nil && !errors.Is(err, errBallotReplayed) {
+		log.Printf("Error: failed to save vote: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to save vote")
+		return
+	}
+
+	receipt, held := s.receiptFor(r.Context(), cat, nickname)
+	writeJSON(w, http.StatusCreated, apiVoteResponse{
+		CategoryID: cat.ID,
+		Nickname:   nickname,
+		Status:     "recorded",
+		Receipt:    receipt,
+		Held:       held,
+	})
+}
+
+// handleAPIBallots records one voter's ballots for several polls in a
+// single transaction, for the multi-question ballot page and offline sync.
+// Each ballot is checked like handleAPIVote's; if any fails, none is saved
+// and the response says which failed and why, with the status code of the
+// first failure. An Idempotency-Key header covers the whole submission: a
+// ballot already recorded under it is not recorded again.
+func (s *Server) handleAPIBallots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	var req apiBallotsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 256<<10)).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	nickname := normalizeNickname(req.Nickname)
+	if nickname == "" {
+		writeAPIError(w, http.StatusBadRequest, "Please enter a nickname")
+		return
+	}
+	if len(req.Ballots) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "No ballots given")
+		return
+	}
+	if len(req.Ballots) > maxBatchBallots {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("At most %d ballots at once", maxBatchBallots))
+		return
+	}
+
+	origin, err := s.requestOrigin(r)
+	if err != nil {
+		writeAPIError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	resp := apiBallotsResponse{Nickname: nickname, Status: "recorded", Ballots: make([]apiBallotResult, len(req.Ballots))}
+	status := http.StatusCreated
+	reject := func(i, code int, message string) {
+		resp.Ballots[i].Status, resp.Ballots[i].Error = "rejected", message
+		if status == http.StatusCreated {
+			status = code
+		}
+	}
+
+	key := r.Header.Get(idempotencyHeader)
+	cats := make([]db.Category, len(req.Ballots))
+	pending := make([]pendingBallot, 0, len(req.Ballots))
+	sent := make([]int, 0, len(req.Ballots)) // where each pending ballot is in req
+	seen := make(map[int64]bool, len(req.Ballots))
+	for i, b := range req.Ballots {
+		resp.Ballots[i].CategoryID = b.CategoryID
+		if seen[b.CategoryID] {
+			reject(i, http.StatusBadRequest, "Only one ballot per category")
+			continue
+		}
+		seen[b.CategoryID] = true
+
+		cat, err := s.queries.Category(r.Context(), b.CategoryID)
+		if errors.Is(err, db.ErrNotFound) {
+			reject(i, http.StatusNotFound, "Category not found")
+			continue
+		}
+		if err != nil {
+			log.Printf("Error: failed to load category %d: %v", b.CategoryID, err)
+			writeAPIError(w, http.StatusInternalServerError, "Failed to load category")
+			return
+		}
+		if err := cat.CheckOpen(); err != nil {
+			reject(i, http.StatusConflict, "Voting is not open for this category")
+			continue
+		}
+
+		options, err := s.queries.ListBallotOptionsByCategory(r.Context(), cat.ID)
+		if err != nil {
+			log.Printf("Error: failed to load options for category %d: %v", cat.ID, err)
+			writeAPIError(w, http.StatusInternalServerError, "Failed to load options")
+			return
+		}
+		selections, errMsg := ballot{Nickname: nickname, Choices: b.Choices}.selections(cat, options)
+		if errMsg == "" {
+			errMsg = pluginRefusal(r.Context(), cat, nickname, options, selections)
+		}
+		if errMsg != "" {
+			reject(i, http.StatusBadRequest, errMsg)
+			continue
+		}
+		remote, err := s.ballotOrigin(r, cat)
+		if err != nil {
+			reject(i, http.StatusForbidden, err.Error())
+			continue
+		}
+		refused, err := s.ineligible(r.Context(), cat, nickname)
+		if err != nil {
+			log.Printf("Error: failed to check eligibility for category %d: %v", cat.ID, err)
+			writeAPIError(w, http.StatusInternalServerError, "Failed to check who can vote")
+			return
+		}
+		if refused != "" {
+			reject(i, http.StatusForbidden, refused)
+			continue
+		}
+
+		// Idempotency keys are claimed per poll
+		var ballotKey string
+		if key != "" {
+			ballotKey = key + ":" + strconv.FormatInt(cat.ID, 10)
+		}
+		cats[i] = cat
+		pending = append(pending, pendingBallot{cat: cat, nickname: nickname, selections: selections, idempotencyKey: ballotKey, remote: remote, origin: origin})
+		sent = append(sent, i)
+	}
+
+	if status == http.StatusCreated {
+		errs := s.castBallots(r.Context(), pending)
+		for _, err := range errs {
+			if err != nil && !errors.Is(err, errBallotReplayed) && !errors.Is(err, errBallotNotSaved) && !errors.Is(err, db.ErrClosed) && !errors.Is(err, errIdempotencyKeyReused) {
+				log.Printf("Error: failed to save votes: %v", err)
+				writeAPIError(w, http.StatusInternalServerError, "Failed to save votes")
+				return
+			}
+		}
+		for j, err := range errs {
+			// A poll may have closed while the ballots waited in the queue
+			switch {
+			case errors.Is(err, db.ErrClosed):
+				reject(sent[j], http.StatusConflict, "Voting is not open for this category")
+			case errors.Is(err, errIdempotencyKeyReused):
+				reject(sent[j], http.StatusUnprocessableEntity, "The Idempotency-Key was already used for a different ballot; send each submission with its own key")
+			}
+		}
+	}
+
+	if status != http.StatusCreated {
+		resp.Status = "rejected"
+		for i := range resp.Ballots {
+			if resp.Ballots[i].Status == "" {
+				resp.Ballots[i].Status = "not_saved"
+			}
+		}
+		writeJSON(w, status, resp)
+		return
+	}
+
+	for i := range resp.Ballots {
+		resp.Ballots[i].Status = "recorded"
+		resp.Ballots[i].Receipt, resp.Ballots[i].Held = s.receiptFor(r.Context(), cats[i], nickname)
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// handleAPIResults serves a category's current tally with an ETag. A client
+// that sends a matching If-None-Match gets 304 Not Modified; adding ?wait=N
+// holds the request open for up to N seconds until the tally changes, so
+// overlays can follow results without hammering the server. Results of
+// finished polls may also be cached for a while (see resultsCacheControl).
+func (s *Server) handleAPIResults(w http.ResponseWriter, r *http.Request, categoryID int64) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	var wait time.Duration
+	if v := r.URL.Query().Get("wait"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 0 {
+			writeAPIError(w, http.StatusBadRequest, "wait must be a number of seconds")
+			return
+		}
+		wait = min(time.Duration(secs)*time.Second, maxResultsWait)
+	}
+
+	cat, err := s.queries.Category(r.Context(), categoryID)
+	if errors.Is(err, db.ErrNotFound) {
+		writeAPIError(w, http.StatusNotFound, "Category not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error: failed to load category %d: %v", categoryID, err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to load category")
+		return
+	}
+
+	body, etag, err := s.resultsSnapshot(r.Context(), categoryID)
+	if err != nil {
+		log.Printf("Error: failed to tally category %d: %v", categoryID, err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to tally results")
+		return
+	}
+
+	inm := r.Header.Get("If-None-Match")
+	if wait > 0 && etagMatches(inm, etag) {
+		deadline := time.NewTimer(wait)
+		defer deadline.Stop()
+		ticker := time.NewTicker(resultsPollInterval)
+		defer ticker.Stop()
+
+	poll:
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-deadline.C:
+				break poll
+			case <-ticker.C:
+				body, etag, err = s.resultsSnapshot(r.Context(), categoryID)
+				if err != nil {
+					log.Printf("Error: failed to tally category %d: %v", categoryID, err)
+					writeAPIError(w, http.StatusInternalServerError, "Failed to tally results")
+					return
+				}
+				if !etagMatches(inm, etag) {
+					break poll
+				}
+			}
+		}
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", resultsCacheControl(cat))
+	if etagMatches(inm, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// feedMaxAge is how long shared caches and info screens may reuse the feed
+// before revalidating it
+const feedMaxAge = 10 * time.Second
+
+// handleAPIFeed lists the open polls with their closing times and absolute
+// vote URLs, for info screens to show with QR codes. It needs no login, may
+// be fetched from another origin, and answers If-None-Match with 304.
+func (s *Server) handleAPIFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+
+	body, etag, err := s.feedSnapshot(r)
+	if err != nil {
+		log.Printf("Error: failed to build feed: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to load polls")
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(feedMaxAge.Seconds())))
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// feedSnapshot encodes the feed, polls closing soonest first, with a strong
+// ETag derived from it
+func (s *Server) feedSnapshot(r *http.Request) ([]byte, string, error) {
+	categories, err := s.reads.ListOpenCategories(r.Context())
+	if err != nil {
+		return nil, "", err
+	}
+	counts, err := s.reads.ListCategoryVoteCounts(r.Context())
+	if err != nil {
+		return nil, "", err
+	}
+	votes := make(map[int64]int64, len(counts))
+	for _, c := range counts {
+		votes[c.ID] = c.VoteCount
+	}
+
+	slices.SortStableFunc(categories, func(a, b db.Category) int {
+		switch {
+		case a.ClosesAt.Valid && b.ClosesAt.Valid:
+			return cmp.Or(a.ClosesAt.Time.Compare(b.ClosesAt.Time), cmp.Compare(a.ID, b.ID))
+		case a.ClosesAt.Valid:
+			return -1
+		case b.ClosesAt.Valid:
+			return 1
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	feed := apiFeed{Polls: []apiFeedPoll{}}
+	for _, cat := range categories {
+		poll := apiFeedPoll{
+			ID:       cat.ID,
+			Name:     cat.Name,
+			VoteType: cat.VoteType,
+			Color:    cat.Color,
+			Icon:     cat.Icon,
+			Votes:    votes[cat.ID],
+			VoteURL:  absoluteURL(r, VoteURL(cat.Ref())),
+			ShortURL: absoluteURL(r, ShortLinkURL(cat.ShortCode())),
+		}
+		if cat.ClosesAt.Valid {
+			closesAt := cat.ClosesAt.Time.UTC()
+			poll.ClosesAt = &closesAt
+		}
+		if cat.ShowResults == "live" {
+			poll.ResultsURL = absoluteURL(r, ResultsURL(cat.Ref()))
+		}
+		feed.Polls = append(feed.Polls, poll)
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(feed); err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), `"` + hex.EncodeToString(sum[:8]) + `"`, nil
+}
+
+// absoluteURL turns a path into a URL on the host the request came in on, so
+// a feed read over the LAN hands out addresses phones on the LAN can reach
+func absoluteURL(r *http.Request, path string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + path
+}
+
+// resultsSnapshot tallies a category and returns the encoded JSON body along
+// with a strong ETag derived from it.
+func (s *Server) resultsSnapshot(ctx context.Context, categoryID int64) ([]byte, string, error) {
+	cat, err := s.reads.Category(ctx, categoryID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	res := apiResults{
+		CategoryID: cat.ID,
+		Name:       cat.Name,
+		VoteType:   cat.VoteType,
+		Status:     cat.Status,
+		Visible:    cat.ShowResults != "after_close" || cat.Finished(),
+	}
+
+	if res.Visible {
+		if res.TotalVotes, err = s.reads.CountVotesByCategory(ctx, cat.ID); err != nil {
+			return nil, "", err
+		}
+
+		rows, err := s.reads.Tally(ctx, cat)
+		if err != nil {
+			return nil, "", err
+		}
+		places := db.Places(rows, res.TotalVotes)
+		for i, row := range rows {
+			result := apiOptionResult{OptionID: row.ID, Name: row.Name, Place: places[i].Rank, Tied: places[i].Tied, Image: row.Image}
+			if cat.VoteType == "ranked" {
+				result.Points, result.FirstPlace = row.Score, row.FirstPlace
+			} else {
+				result.Votes = row.Score
+			}
+			res.Results = append(res.Results, result)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(res); err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), `"` + hex.EncodeToString(sum[:8]) + `"`, nil
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag.
+// Weak validators compare equal to their strong form.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("JSON encode error: %v", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, apiError{Error: message})
+}
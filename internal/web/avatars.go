@@ -0,0 +1,41 @@
+package web
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/avatar"
+)
+
+// avatarURL is where the identicon for nickname is served. The URL carries
+// the avatar's ID, never the nickname.
+func (s *Server) avatarURL(nickname string) string {
+	return AvatarURL(avatar.ID(s.avatarSalt, nickname))
+}
+
+// handleAvatar serves an identicon. An ID always draws the same tile, so
+// browsers may keep it for good.
+func (s *Server) handleAvatar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+	id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/avatars/"), ".png")
+	if !ok || !avatar.Valid(id) {
+		s.notFound(w, r)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := avatar.Render(&buf, id); err != nil {
+		s.renderError(w, "Failed to draw avatar", err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	if _, err := buf.WriteTo(w); err != nil {
+		log.Printf("Failed to send avatar %s: %v", id, err)
+	}
+}
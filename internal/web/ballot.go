@@ -0,0 +1,268 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/plugin"
+)
+
+// ballot is one voter's submission for a category, independent of whether it
+// arrived as a form post or through the JSON API.
+type ballot struct {
+	Nickname string
+	// Choices holds option IDs. For ranked categories they are in preference
+	// order and a 0 marks a rank the voter left blank.
+	Choices []int64
+}
+
+type voteSelection struct {
+	OptionID int64
+	Rank     sql.NullInt64
+}
+
+// maxRankFor returns the number of ranks shown for a ranked category
+func maxRankFor(cat db.Category) int64 {
+	if cat.MaxRank.Valid {
+		return cat.MaxRank.Int64
+	}
+	return 3
+}
+
+// normalizeNickname trims and lowercases a nickname so re-votes match
+func normalizeNickname(nickname string) string {
+	return strings.ToLower(strings.TrimSpace(nickname))
+}
+
+// selections validates the ballot against the category and its options. On
+// invalid input it returns a message suitable for showing to the voter.
+func (b ballot) selections(cat db.Category, options []db.Option) ([]voteSelection, string) {
+	valid := make(map[int64]bool, len(options))
+	for _, o := range options {
+		valid[o.ID] = true
+	}
+
+	var selections []voteSelection
+	seen := make(map[int64]bool)
+
+	switch cat.VoteType {
+	case "single":
+		if len(b.Choices) == 0 {
+			return nil, "Please make a selection"
+		}
+		if len(b.Choices) > 1 {
+			return nil, "Please select only one option"
+		}
+		if !valid[b.Choices[0]] {
+			return nil, "Invalid selection"
+		}
+		selections = append(selections, voteSelection{OptionID: b.Choices[0]})
+
+	case "approval":
+		for _, id := range b.Choices {
+			if !valid[id] {
+				return nil, "Invalid selection"
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			selections = append(selections, voteSelection{OptionID: id})
+		}
+
+	case "ranked":
+		if int64(len(b.Choices)) > maxRankFor(cat) {
+			return nil, fmt.Sprintf("Please rank at most %d choices", maxRankFor(cat))
+		}
+		for i, id := range b.Choices {
+			if id == 0 {
+				continue
+			}
+			if !valid[id] {
+				return nil, "Invalid selection"
+			}
+			if seen[id] {
+				return nil, "Each choice must be different"
+			}
+			seen[id] = true
+			selections = append(selections, voteSelection{
+				OptionID: id,
+				Rank:     sql.NullInt64{Int64: int64(i + 1), Valid: true},
+			})
+		}
+	}
+
+	if len(selections) == 0 {
+		return nil, "Please make at least one selection"
+	}
+	return selections, ""
+}
+
+// pluginRefusal asks the validators plugins registered (see plugin.RegisterValidator)
+// about selections, which passed votigo's own checks, returning the first
+// refusal's message for the voter, or "" if they all accept it
+func pluginRefusal(ctx context.Context, cat db.Category, nickname string, options []db.Option, selections []voteSelection) string {
+	b := plugin.Ballot{Nickname: nickname}
+	for _, sel := range selections {
+		i := slices.IndexFunc(options, func(o db.Option) bool { return o.ID == sel.OptionID })
+		b.Choices = append(b.Choices, plugin.Choice{Option: options[i], Rank: sel.Rank.Int64})
+	}
+	if err := plugin.ValidateBallot(ctx, cat, b); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// ineligible checks nickname may vote in cat under its eligibility rule,
+// going by the roster tags they have now. A voter it leaves out gets a
+// message saying why; err means the check itself failed.
+func (s *Server) ineligible(ctx context.Context, cat db.Category, nickname string) (string, error) {
+	err := s.queries.CheckEligible(ctx, cat, s.nicknames.Seal(nickname))
+	var refused *db.IneligibleError
+	if errors.As(err, &refused) {
+		return refused.Error(), nil
+	}
+	return "", err
+}
+
+// castBallot replaces any earlier vote by the same nickname with b's
+// selections and records the vote in the audit log, atomically. b.remote
+// flags a ballot that came from outside the LAN (see ballotOrigin) and
+// b.origin the kiosk and address it was cast from (see requestOrigin),
+// which the audit event keeps for the station report. b.test lets a ballot
+// into a draft poll in test mode (see db.Category.Testing). A non-empty
+// idempotency key is claimed along with the vote; if it was already used for
+// this voter in this poll, castBallot returns errBallotReplayed and changes
+// nothing, and if it was used for any other ballot, errIdempotencyKeyReused. A poll that
+// closed before the ballot was written returns db.ErrClosed.
+//
+// Ballots go through the write queue (see ballotqueue.go), so a burst of
+// them shares transactions instead of fighting over SQLite's write lock.
+func (s *Server) castBallot(ctx context.Context, b pendingBallot) error {
+	return s.ballotQueue.submit(ctx, b)[0]
+}
+
+// castBallots is castBallot for several ballots at once, all saved or none:
+// if one fails, the others come back as errBallotNotSaved. Replayed ballots
+// don't count as failures.
+func (s *Server) castBallots(ctx context.Context, ballots []pendingBallot) []error {
+	return s.ballotQueue.submit(ctx, ballots...)
+}
+
+// writeBallot does castBallot's work inside the queue's transaction
+func (s *Server) writeBallot(ctx context.Context, qtx *db.Queries, b pendingBallot) error {
+	// The poll may have closed while the ballot waited in the queue
+	cat, err := qtx.Category(ctx, b.cat.ID)
+	if err != nil {
+		return fmt.Errorf("load poll: %w", err)
+	}
+	if err := cat.CheckOpen(); err != nil && !(b.test && cat.Testing()) {
+		return err
+	}
+
+	stored := s.nicknames.Seal(b.nickname)
+
+	if b.idempotencyKey != "" {
+		claimed, err := qtx.ClaimIdempotencyKey(ctx, db.ClaimIdempotencyKeyParams{
+			Key:        b.idempotencyKey,
+			CategoryID: b.cat.ID,
+			Nickname:   stored,
+		})
+		if err != nil {
+			return fmt.Errorf("claim idempotency key: %w", err)
+		}
+		if claimed == 0 {
+			// Only the same voter's ballot in the same poll is a replay;
+			// anything else under the key would be lost without a word
+			prior, err := qtx.GetIdempotencyKey(ctx, b.idempotencyKey)
+			if err != nil {
+				return fmt.Errorf("load idempotency key: %w", err)
+			}
+			if prior.CategoryID != b.cat.ID || prior.Nickname != stored {
+				return errIdempotencyKeyReused
+			}
+			return errBallotReplayed
+		}
+	}
+
+	vote, err := qtx.UpsertVote(ctx, db.UpsertVoteParams{
+		CategoryID: b.cat.ID,
+		Nickname:   stored,
+		Remote:     b.remote,
+	})
+	if err != nil {
+		return fmt.Errorf("upsert vote: %w", err)
+	}
+
+	// Two devices re-voting one nickname seconds apart are left for an admin
+	// to settle rather than won by whichever landed last
+	conflict, err := qtx.RecordRevoteConflict(ctx, vote, b.origin)
+	if err != nil {
+		return fmt.Errorf("check conflict: %w", err)
+	}
+	if conflict {
+		log.Printf("Ballot for poll %d replaced one from another device seconds earlier; flagged as a conflict", b.cat.ID)
+	}
+
+	// A re-vote bumps the version; keep the ballot it replaces
+	if vote.Version > 1 {
+		if err := qtx.ArchiveVoteSelections(ctx, vote.ID); err != nil {
+			return fmt.Errorf("archive selections: %w", err)
+		}
+	}
+
+	if err := qtx.DeleteVoteSelections(ctx, vote.ID); err != nil {
+		return fmt.Errorf("clear selections: %w", err)
+	}
+
+	for _, sel := range b.selections {
+		err = qtx.CreateVoteSelection(ctx, db.CreateVoteSelectionParams{
+			VoteID:   vote.ID,
+			OptionID: sel.OptionID,
+			Rank:     sel.Rank,
+		})
+		if err != nil {
+			return fmt.Errorf("save selection: %w", err)
+		}
+	}
+
+	action := db.AuditVote
+	if b.test {
+		action = db.AuditTestVote
+	}
+	if err := qtx.RecordAuditFrom(ctx, b.origin, stored, action, b.cat.ID, ""); err != nil {
+		return fmt.Errorf("record audit: %w", err)
+	}
+	return nil
+}
+
+// receiptFor returns the receipt code for a voter's current ballot, and
+// held, telling them why it doesn't count as cast if an admin invalidated
+// their ballot or counts it as it stood when the official window closed
+// (see db.InvalidateBallots); voting again changes neither. A failure is
+// logged and yields an empty code, since the vote itself stands.
+func (s *Server) receiptFor(ctx context.Context, cat db.Category, nickname string) (receipt, held string) {
+	vote, err := s.queries.GetVoteByNickname(ctx, db.GetVoteByNicknameParams{
+		CategoryID: cat.ID,
+		Nickname:   s.nicknames.Seal(nickname),
+	})
+	if err == nil {
+		switch {
+		case vote.Invalid != "":
+			held = "An organizer marked your ballot invalid, so it isn't counted unless they restore it"
+		case vote.CountedUntil.Valid:
+			held = "Your ballot counts as it was when voting officially closed, unless an organizer restores it"
+		}
+		if receipt, err = s.queries.BallotReceipt(ctx, vote); err == nil {
+			return receipt, held
+		}
+	}
+	log.Printf("Failed to compute ballot receipt: %v", err)
+	return "", held
+}
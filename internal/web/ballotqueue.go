@@ -0,0 +1,182 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// When the MC says "go" everyone votes at once. SQLite takes one writer at a
+// time, so instead of each request opening its own transaction and waiting
+// on the lock, ballots queue up and whoever holds the writer role commits
+// them in batches. Each request's ballots run under their own savepoint, so
+// one that fails is rolled back alone and the rest of its batch still
+// commits.
+const (
+	ballotQueueSize = 1024 // ballots waiting for a writer
+	maxBallotBatch  = 64   // ballots committed per transaction
+)
+
+// pendingBallot is a ballot waiting in the queue
+type pendingBallot struct {
+	cat            db.Category
+	nickname       string
+	selections     []voteSelection
+	idempotencyKey string
+	remote         bool
+	origin         db.AuditOrigin // the kiosk and address it was cast from
+	test           bool           // an admin's ballot in a draft poll in test mode
+}
+
+// submission is one request's ballots, saved together or not at all. The
+// writer sends each ballot's outcome on done.
+type submission struct {
+	ctx     context.Context
+	ballots []pendingBallot
+	done    chan []error
+}
+
+// errBallotNotSaved is the outcome of a ballot that was fine on its own but
+// was rolled back because another in its submission failed
+var errBallotNotSaved = errors.New("ballot not saved: another in the same submission failed")
+
+// ballotQueue serialises ballot writes without a long-lived goroutine: a
+// submitter that finds no writer at work becomes the writer and drains the
+// queue, including other requests' ballots, before going back to waiting on
+// its own result.
+type ballotQueue struct {
+	pending chan submission
+	writer  chan struct{} // holds a token while someone is writing
+	write   func(ctx context.Context, qtx *db.Queries, b pendingBallot) error
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func newBallotQueue(s *Server) *ballotQueue {
+	return &ballotQueue{
+		pending: make(chan submission, ballotQueueSize),
+		writer:  make(chan struct{}, 1),
+		write:   s.writeBallot,
+		db:      s.db,
+		queries: s.queries,
+	}
+}
+
+// submit queues ballots and waits until they are committed or rejected,
+// returning each one's outcome. Ballots whose ctx ends before they are
+// written are skipped with ctx's error; once queued they are always
+// answered, so the outcome is never in doubt.
+func (q *ballotQueue) submit(ctx context.Context, ballots ...pendingBallot) []error {
+	b := submission{ctx: ctx, ballots: ballots, done: make(chan []error, 1)}
+
+	for queued := false; !queued; {
+		select {
+		case q.pending <- b:
+			queued = true
+		case q.writer <- struct{}{}:
+			// The queue is full and nobody is draining it
+			q.drain()
+			<-q.writer
+		case <-ctx.Done():
+			errs := make([]error, len(ballots))
+			for i := range errs {
+				errs[i] = ctx.Err()
+			}
+			return errs
+		}
+	}
+
+	for {
+		select {
+		case errs := <-b.done:
+			return errs
+		case q.writer <- struct{}{}:
+			q.drain()
+			<-q.writer
+		}
+	}
+}
+
+// drain writes batches until the queue is empty. Only the holder of the
+// writer token calls it.
+func (q *ballotQueue) drain() {
+	for {
+		batch := make([]submission, 0, maxBallotBatch)
+	collect:
+		for len(batch) < maxBallotBatch {
+			select {
+			case b := <-q.pending:
+				batch = append(batch, b)
+			default:
+				break collect
+			}
+		}
+		if len(batch) == 0 {
+			return
+		}
+
+		errs := make([][]error, len(batch))
+		for i, b := range batch {
+			errs[i] = make([]error, len(b.ballots))
+		}
+		err := q.commit(batch, errs)
+		for i, b := range batch {
+			for j := range errs[i] {
+				if err != nil && errs[i][j] == nil {
+					errs[i][j] = err
+				}
+			}
+			b.done <- errs[i]
+		}
+	}
+}
+
+// commit writes batch in one transaction, recording each ballot's own
+// failure in errs. An error from commit itself means nothing was saved.
+func (q *ballotQueue) commit(batch []submission, errs [][]error) error {
+	// Not tied to any one request, so a voter giving up can't take the
+	// others' ballots down with them
+	tx, err := q.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := q.queries.WithTx(tx)
+
+	for i, sub := range batch {
+		if err := sub.ctx.Err(); err != nil {
+			for j := range errs[i] {
+				errs[i][j] = err
+			}
+			continue
+		}
+		if _, err := tx.Exec("SAVEPOINT ballot"); err != nil {
+			return err
+		}
+		failed := false
+		for j, b := range sub.ballots {
+			// A replayed ballot changes nothing, so it fails nothing either
+			errs[i][j] = q.write(sub.ctx, qtx, b)
+			if errs[i][j] != nil && !errors.Is(errs[i][j], errBallotReplayed) {
+				failed = true
+				break
+			}
+		}
+		if failed {
+			for j, err := range errs[i] {
+				if err == nil {
+					errs[i][j] = errBallotNotSaved
+				}
+			}
+			if _, err := tx.Exec("ROLLBACK TO ballot"); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec("RELEASE ballot"); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// maxBroadcastLen keeps announcements to a banner's worth
+const maxBroadcastLen = 200
+
+// defaultBroadcastMinutes is how long a banner stays up unless the admin
+// says otherwise; maxBroadcastMinutes caps it
+const (
+	defaultBroadcastMinutes = 5
+	maxBroadcastMinutes     = 60
+)
+
+// BroadcastEvent is the data of a broadcast event: a banner for every voter
+// and results page, dismissed on its own at ExpiresAt. An empty Message
+// takes the current banner down.
+type BroadcastEvent struct {
+	Message   string
+	ExpiresAt time.Time
+}
+
+// MarshalJSON sends how many seconds the banner has left rather than when
+// it expires, as kiosk clocks can't be trusted to agree with the server's
+func (e BroadcastEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Message string `json:"message"`
+		Seconds int64  `json:"seconds"`
+	}{e.Message, max(int64(time.Until(e.ExpiresAt).Seconds()), 0)})
+}
+
+// broadcast holds the latest announcement, so pages opened while it is up
+// show it too. It lives in memory only, like the display state.
+type broadcast struct {
+	mu      sync.Mutex
+	current BroadcastEvent
+}
+
+// currentBroadcast returns the announcement still up, if any
+func (s *Server) currentBroadcast() (BroadcastEvent, bool) {
+	s.broadcast.mu.Lock()
+	defer s.broadcast.mu.Unlock()
+	ev := s.broadcast.current
+	return ev, ev.Message != "" && time.Now().Before(ev.ExpiresAt)
+}
+
+// setBroadcast replaces the announcement and pushes it to every listening
+// page, returning how many received it
+func (s *Server) setBroadcast(ev BroadcastEvent) int {
+	s.broadcast.mu.Lock()
+	s.broadcast.current = ev
+	s.broadcast.mu.Unlock()
+	return s.publish(EventBroadcast, ev)
+}
+
+// handleAdminBroadcast pushes a banner message to every open voter and
+// results page for a few minutes (minutes, default 5), or takes the current
+// one down when message is empty or clear is set
+func (s *Server) handleAdminBroadcast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	message := strings.Join(strings.Fields(r.FormValue("message")), " ")
+	if r.FormValue("clear") != "" {
+		message = ""
+	}
+	if len(message) > maxBroadcastLen {
+		s.actionError(w, r, http.StatusBadRequest, fmt.Sprintf("Please keep announcements under %d characters", maxBroadcastLen))
+		return
+	}
+	minutes := defaultBroadcastMinutes
+	if v := r.FormValue("minutes"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxBroadcastMinutes {
+			s.actionError(w, r, http.StatusBadRequest, fmt.Sprintf("Show announcements for 1 to %d minutes", maxBroadcastMinutes))
+			return
+		}
+		minutes = n
+	}
+
+	ev := BroadcastEvent{Message: message}
+	detail := "cleared"
+	if message != "" {
+		ev.ExpiresAt = time.Now().Add(time.Duration(minutes) * time.Minute).UTC()
+		detail = fmt.Sprintf("%s (%d min)", message, minutes)
+	}
+	pages := s.setBroadcast(ev)
+	s.audit(r, db.AuditBroadcast, 0, detail)
+
+	if s.isHTMX(r) {
+		if message == "" {
+			showToast(w, toastSuccess, "Announcement taken down")
+		} else {
+			showToast(w, toastSuccess, fmt.Sprintf("Announced on %d page(s)", pages))
+		}
+		return
+	}
+	http.Redirect(w, r, AdminURL(), http.StatusSeeOther)
+}
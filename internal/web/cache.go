@@ -0,0 +1,82 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// How long browsers and proxies may reuse results without asking again.
+// An archived poll can't change any more; a closed one can still be
+// reopened, so it is only cached briefly.
+const (
+	archivedResultsMaxAge = 24 * time.Hour
+	closedResultsMaxAge   = time.Minute
+)
+
+// resultsCacheControl is the Cache-Control header for a poll's results.
+// Results of a poll still being voted on always have to be revalidated.
+func resultsCacheControl(cat db.Category) string {
+	switch cat.Status {
+	case "archived":
+		return fmt.Sprintf("public, max-age=%d", int(archivedResultsMaxAge.Seconds()))
+	case "closed":
+		return fmt.Sprintf("public, max-age=%d", int(closedResultsMaxAge.Seconds()))
+	}
+	return "no-cache"
+}
+
+// resultsPageETag derives the results page's ETag from the tally snapshot's,
+// adding what else the page depends on: the UI, the theme, the poll's skin
+// and the runoff links
+func (s *Server) resultsPageETag(ctx context.Context, cat db.Category, original, runoff *db.Category) (string, error) {
+	_, snapshot, err := s.resultsSnapshot(ctx, cat.ID)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s|%t", snapshot, s.uiMode, s.settingBool(db.SettingHighContrast))
+	fmt.Fprintf(h, "|skin:%s:%x", cat.Skin, sha256.Sum256([]byte(cat.CustomCss)))
+	if original != nil {
+		fmt.Fprintf(h, "|of:%d:%s", original.ID, original.Name)
+	}
+	if runoff != nil {
+		fmt.Fprintf(h, "|runoff:%d:%s:%s", runoff.ID, runoff.Name, runoff.Status)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:8]) + `"`, nil
+}
+
+// cacheFinishedResults sets caching headers on the results page of a
+// finished poll and reports whether the client's copy is still current, in
+// which case it has already been answered with 304 Not Modified
+func (s *Server) cacheFinishedResults(w http.ResponseWriter, r *http.Request, cat db.Category, original, runoff *db.Category) bool {
+	if !cat.Finished() {
+		return false
+	}
+	// Organizers get the admin bar or the view-as-voter banner, which a
+	// cached copy mustn't carry to attendees or the other way round
+	w.Header().Set("Vary", "Authorization, Cookie")
+	if s.viewer(r, &cat) != nil {
+		w.Header().Set("Cache-Control", "private, no-cache")
+		return false
+	}
+	etag, err := s.resultsPageETag(r.Context(), cat, original, runoff)
+	if err != nil {
+		// Serve the page uncached; rendering it will report the error
+		return false
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", resultsCacheControl(cat))
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/palm-arcade/votigo/internal/card"
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// handleResultsCard draws a poll's results card, a PNG for sharing, once its
+// results are visible. It is cached like the results page.
+func (s *Server) handleResultsCard(w http.ResponseWriter, r *http.Request, ref string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+	cat, ok := s.categoryByRef(w, r, ref)
+	if !ok {
+		return
+	}
+	if cat.ShowResults == "after_close" && !cat.Finished() {
+		s.notFound(w, r)
+		return
+	}
+
+	brand := s.setting(db.SettingEventName)
+	_, snapshot, err := s.resultsSnapshot(r.Context(), cat.ID)
+	if err != nil {
+		s.renderError(w, "Failed to tally results", err)
+		return
+	}
+	sum := sha256.Sum256([]byte(snapshot + "|" + brand))
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", resultsCacheControl(cat))
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	votes, results, err := s.tallyResults(r.Context(), cat)
+	if err != nil {
+		s.renderError(w, "Failed to tally results", err)
+		return
+	}
+	c := card.Card{Brand: brand, Title: cat.Name, Unit: "vote", TotalVotes: votes, Live: !cat.Finished()}
+	scored := cat.VoteType == "ranked" || cat.Judged()
+	if scored {
+		c.Unit = "point"
+	}
+	for _, res := range results {
+		score := res.Votes
+		if scored {
+			score = res.Points
+		}
+		c.Results = append(c.Results, card.Result{Name: res.Name, Score: score, Place: res.Rank})
+	}
+
+	var buf bytes.Buffer
+	if err := card.Render(&buf, c); err != nil {
+		s.renderError(w, "Failed to draw results card", err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", fmt.Sprint(buf.Len()))
+	if _, err := buf.WriteTo(w); err != nil {
+		log.Printf("Failed to send results card for category %d: %v", cat.ID, err)
+	}
+}
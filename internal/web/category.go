@@ -0,0 +1,256 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/slug"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+// VoteTypes and ResultVisibilities list the allowed values for a category's
+// vote_type and show_results columns
+var (
+	VoteTypes          = []string{"single", "ranked", "approval"}
+	ResultVisibilities = []string{"live", "after_close"}
+	VotedWalls         = []string{"", "names", "avatars"}
+)
+
+// CategorySettings are the admin-editable fields of a category. The admin
+// form and `votigo poll edit` both go through Normalize, so a category can't
+// be saved from one that the other would reject.
+type CategorySettings struct {
+	Name        string
+	VoteType    string
+	ShowResults string
+	MaxRank     int64 // ranked only; 0 or less means the default of 3
+	Color       string
+	Icon        string
+	DependsOn   int64     // poll this one opens after; 0 for none
+	SeedTopN    int64     // options to copy from DependsOn's top places when it closes
+	OpensAt     time.Time // planned opening time shown on the landing page; zero for none
+	ClosesAt    time.Time // planned closing time shown to voters; zero for none
+	Slug        string    // voter URL slug; empty to generate one from Name
+	Skin        string    // preset look of the voter pages (see Skins); empty for the default
+	CustomCSS   string    // added to the voter pages after the skin
+	RevealSound string    // audio cue URL the display plays on reveal; empty for none
+	VotedWall   string    // who has voted beside the ballot: "names", "avatars" or empty for none
+	Eligibility string    // roster tags voters need or mustn't have (see db.Eligibility); empty for everyone
+	Weights     string    // roster tags whose ballots count more than once (see db.TagWeights); empty for none
+	Method      string    // counting method from tally.Methods; empty for votes or points by vote type
+	Judges      string    // roster tag of judges scored apart from the audience (see db.Category.Judged); empty for none
+	JudgeWeight int64     // percent of a judged poll's result the judges decide; 0 or less means db.DefaultJudgeWeight
+}
+
+// SettingsOf returns the current settings of a category
+func SettingsOf(cat db.Category) CategorySettings {
+	return CategorySettings{
+		Name:        cat.Name,
+		VoteType:    cat.VoteType,
+		ShowResults: cat.ShowResults,
+		MaxRank:     cat.MaxRank.Int64,
+		Color:       cat.Color,
+		Icon:        cat.Icon,
+		DependsOn:   cat.DependsOn.Int64,
+		SeedTopN:    cat.SeedTopN,
+		OpensAt:     cat.OpensAt.Time,
+		ClosesAt:    cat.ClosesAt.Time,
+		Slug:        cat.Slug.String,
+		Skin:        cat.Skin,
+		CustomCSS:   cat.CustomCss,
+		RevealSound: cat.RevealSound,
+		VotedWall:   cat.VotedWall,
+		Eligibility: cat.Eligibility,
+		Weights:     cat.Weights,
+		Method:      cat.Method,
+		Judges:      cat.Judges,
+		JudgeWeight: cat.JudgeWeight,
+	}
+}
+
+// Normalize trims the free-text fields, applies the default max rank and
+// judge weight, rewrites the eligibility and weights expressions and the
+// judges' tag in their stored form and validates the result. Errors are phrased for showing to an admin.
+func (c *CategorySettings) Normalize() error {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Slug = strings.ToLower(strings.TrimSpace(c.Slug))
+	c.Icon = NormalizeCategoryIcon(c.Icon)
+	c.CustomCSS = strings.TrimSpace(c.CustomCSS)
+	c.RevealSound = strings.TrimSpace(c.RevealSound)
+	if c.VoteType == "ranked" && c.MaxRank <= 0 {
+		c.MaxRank = 3
+	}
+	eligibility, err := db.ParseEligibility(c.Eligibility)
+	if err != nil {
+		return err
+	}
+	c.Eligibility = eligibility.String()
+	weights, err := db.ParseTagWeights(c.Weights)
+	if err != nil {
+		return err
+	}
+	c.Weights = weights.String()
+	if c.Judges = strings.TrimSpace(c.Judges); c.Judges != "" {
+		if c.Judges, err = db.NormalizeTag(c.Judges); err != nil {
+			return fmt.Errorf("Judges: %w", err)
+		}
+	}
+	if c.JudgeWeight <= 0 {
+		c.JudgeWeight = db.DefaultJudgeWeight
+	}
+
+	switch {
+	case c.Name == "":
+		return errors.New("Name is required")
+	case !slices.Contains(VoteTypes, c.VoteType):
+		return errors.New("Unknown vote type")
+	case !slices.Contains(ResultVisibilities, c.ShowResults):
+		return errors.New("Unknown results visibility")
+	case !ValidCategoryColor(c.Color):
+		return errors.New("Unknown label color")
+	case c.SeedTopN < 0:
+		return errors.New("Seed count can't be negative")
+	case c.SeedTopN > 0 && c.DependsOn == 0:
+		return errors.New("Pick a poll to open after to seed options from it")
+	case !c.OpensAt.IsZero() && !c.ClosesAt.IsZero() && !c.OpensAt.Before(c.ClosesAt):
+		return errors.New("The planned opening time must be before the closing time")
+	case c.Slug != "" && !slug.Valid(c.Slug):
+		return errors.New("Slugs are lowercase letters, digits and single hyphens, with at least one letter")
+	case !ValidSkin(c.Skin):
+		return errors.New("Unknown skin")
+	case !ValidSoundURL(c.RevealSound):
+		return errors.New("Reveal sound must be an uploaded sound or an http(s) URL")
+	case !slices.Contains(VotedWalls, c.VotedWall):
+		return errors.New("Unknown voted wall")
+	case c.Method != "" && tally.Methods[c.Method] == nil:
+		return errors.New("Unknown counting method")
+	case c.Method == "irv" && c.VoteType == "approval":
+		return errors.New("Instant runoff needs ranked or single-choice ballots")
+	case c.JudgeWeight > 99:
+		return errors.New("Judges can decide from 1 to 99 percent of the result")
+	case c.Judges != "" && slices.Contains([]string{"irv", "condorcet", "elo"}, c.Method):
+		return errors.New("Judged polls combine shares of votes or points, so they can't be counted by " + c.Method)
+	}
+	return CheckCustomCSS(c.CustomCSS)
+}
+
+// CheckDependency checks DependsOn against the database for category id, 0
+// for one not created yet. Errors are phrased for showing to an admin.
+func (c CategorySettings) CheckDependency(ctx context.Context, q *db.Queries, id int64) error {
+	err := q.CheckDependency(ctx, id, c.DependsOn)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return errors.New("The poll to open after no longer exists")
+	case errors.Is(err, db.ErrDependencyCycle):
+		return errors.New("Polls can't open after each other: that would leave them waiting forever")
+	}
+	return err
+}
+
+// AssignSlug fills in a generated slug when none was given, or checks the
+// given one is free, for category id (0 for one not created yet). Errors are
+// phrased for showing to an admin.
+func (c *CategorySettings) AssignSlug(ctx context.Context, q *db.Queries, id int64) error {
+	if c.Slug == "" {
+		slug, err := q.UniqueSlug(ctx, c.Name, id)
+		c.Slug = slug
+		return err
+	}
+
+	taken, err := q.SlugTaken(ctx, c.Slug, id)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return errors.New("Another poll already uses that slug")
+	}
+	return nil
+}
+
+// opensAt returns the opens_at column value
+func (c CategorySettings) opensAt() sql.NullTime {
+	return sql.NullTime{Time: c.OpensAt.UTC(), Valid: !c.OpensAt.IsZero()}
+}
+
+// closesAt returns the closes_at column value
+func (c CategorySettings) closesAt() sql.NullTime {
+	return sql.NullTime{Time: c.ClosesAt.UTC(), Valid: !c.ClosesAt.IsZero()}
+}
+
+// dependsOn returns the depends_on column value
+func (c CategorySettings) dependsOn() sql.NullInt64 {
+	return sql.NullInt64{Int64: c.DependsOn, Valid: c.DependsOn != 0}
+}
+
+// slug returns the slug column value
+func (c CategorySettings) slug() sql.NullString {
+	return sql.NullString{String: c.Slug, Valid: c.Slug != ""}
+}
+
+// maxRank returns the max_rank column value; only ranked categories have one
+func (c CategorySettings) maxRank() sql.NullInt64 {
+	if c.VoteType != "ranked" {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: c.MaxRank, Valid: true}
+}
+
+// CreateParams returns the insert parameters for a new draft category
+func (c CategorySettings) CreateParams() db.CreateCategoryParams {
+	return db.CreateCategoryParams{
+		Name:        c.Name,
+		VoteType:    c.VoteType,
+		Status:      "draft",
+		ShowResults: c.ShowResults,
+		MaxRank:     c.maxRank(),
+		Color:       c.Color,
+		Icon:        c.Icon,
+		DependsOn:   c.dependsOn(),
+		SeedTopN:    c.SeedTopN,
+		ClosesAt:    c.closesAt(),
+		Slug:        c.slug(),
+		OpensAt:     c.opensAt(),
+		Skin:        c.Skin,
+		CustomCss:   c.CustomCSS,
+		RevealSound: c.RevealSound,
+		VotedWall:   c.VotedWall,
+		Eligibility: c.Eligibility,
+		Weights:     c.Weights,
+		Method:      c.Method,
+		Judges:      c.Judges,
+		JudgeWeight: c.JudgeWeight,
+	}
+}
+
+// UpdateParams returns the update parameters for category id
+func (c CategorySettings) UpdateParams(id int64) db.UpdateCategoryParams {
+	return db.UpdateCategoryParams{
+		Name:        c.Name,
+		VoteType:    c.VoteType,
+		ShowResults: c.ShowResults,
+		MaxRank:     c.maxRank(),
+		Color:       c.Color,
+		Icon:        c.Icon,
+		DependsOn:   c.dependsOn(),
+		SeedTopN:    c.SeedTopN,
+		ClosesAt:    c.closesAt(),
+		Slug:        c.slug(),
+		OpensAt:     c.opensAt(),
+		Skin:        c.Skin,
+		CustomCss:   c.CustomCSS,
+		RevealSound: c.RevealSound,
+		VotedWall:   c.VotedWall,
+		Eligibility: c.Eligibility,
+		Weights:     c.Weights,
+		Method:      c.Method,
+		Judges:      c.Judges,
+		JudgeWeight: c.JudgeWeight,
+		ID:          id,
+	}
+}
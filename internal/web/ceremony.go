@@ -0,0 +1,258 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// DisplayState is what the projector display shows: a closed poll, or the
+// waiting screen while CategoryID is 0. A poll's results stay covered until
+// the ceremony console reveals them.
+type DisplayState struct {
+	CategoryID int64 `json:"category_id"`
+	Revealed   bool  `json:"revealed"`
+}
+
+// ceremony holds the display state. It lives in memory only: after a
+// restart the display starts on the waiting screen again.
+type ceremony struct {
+	mu    sync.Mutex
+	state DisplayState
+}
+
+// displayState returns what the display currently shows
+func (s *Server) displayState() DisplayState {
+	s.ceremony.mu.Lock()
+	defer s.ceremony.mu.Unlock()
+	return s.ceremony.state
+}
+
+// setDisplay changes what the display shows and tells the displays
+func (s *Server) setDisplay(state DisplayState) int {
+	s.ceremony.mu.Lock()
+	s.ceremony.state = state
+	s.ceremony.mu.Unlock()
+	return s.publish(EventDisplay, state)
+}
+
+// ceremonyPolls lists the closed polls in the order the console shows them,
+// with their winners
+func (s *Server) ceremonyPolls(ctx context.Context) ([]CeremonyPoll, error) {
+	categories, err := s.queries.ListCategoriesExcludeArchived(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var polls []CeremonyPoll
+	for _, cat := range categories {
+		if cat.Status != "closed" {
+			continue
+		}
+		votes, results, err := s.tallyResults(ctx, cat)
+		if err != nil {
+			return nil, err
+		}
+		poll := CeremonyPoll{Category: cat, Votes: votes}
+		poll.Winner = strings.Join(leaders(results), " & ")
+		poll.Margin = s.winningMargin(cat, votes, results)
+		polls = append(polls, poll)
+	}
+	return polls, nil
+}
+
+// handleAdminCeremony renders the ceremony console: what the display shows,
+// controls to reveal, celebrate and move on, and the closed polls to show
+func (s *Server) handleAdminCeremony(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	polls, err := s.ceremonyPolls(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load polls", err)
+		return
+	}
+
+	data := CeremonyData{
+		Page:       Page{Title: "Ceremony"},
+		Polls:      polls,
+		Display:    s.displayState(),
+		Displays:   s.events.listeners(),
+		CeremonyAt: s.ceremonyAt(),
+	}
+	for i := range polls {
+		if polls[i].Category.ID == data.Display.CategoryID {
+			data.Current = &polls[i]
+		}
+	}
+	s.render(w, "admin/ceremony.html", data)
+}
+
+// handleAdminCeremonyShow puts the closed poll in category_id on the display
+// with its results covered, or the waiting screen if it is 0
+func (s *Server) handleAdminCeremonyShow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	categoryID, err := strconv.ParseInt(r.FormValue("category_id"), 10, 64)
+	if err != nil {
+		s.actionError(w, r, http.StatusBadRequest, "Invalid poll ID")
+		return
+	}
+	if categoryID != 0 {
+		cat, err := s.queries.Category(r.Context(), categoryID)
+		if errors.Is(err, db.ErrNotFound) {
+			s.actionError(w, r, http.StatusNotFound, "Poll not found")
+			return
+		}
+		if err != nil {
+			s.renderActionError(w, r, "Failed to load poll", err)
+			return
+		}
+		if cat.Status != "closed" {
+			s.actionError(w, r, http.StatusConflict, "Only closed polls can go on the display")
+			return
+		}
+	}
+
+	s.setDisplay(DisplayState{CategoryID: categoryID})
+	http.Redirect(w, r, AdminCeremonyURL(), http.StatusSeeOther)
+}
+
+// handleAdminCeremonyReveal uncovers the results of the poll on the display
+func (s *Server) handleAdminCeremonyReveal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	state := s.displayState()
+	if state.CategoryID == 0 {
+		s.actionError(w, r, http.StatusConflict, "Show a poll on the display first")
+		return
+	}
+	state.Revealed = true
+	s.setDisplay(state)
+	http.Redirect(w, r, AdminCeremonyURL(), http.StatusSeeOther)
+}
+
+// handleAdminCeremonyNext moves the display on to the closed poll after the
+// one it shows, covered; after the last it returns to the waiting screen
+func (s *Server) handleAdminCeremonyNext(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	polls, err := s.ceremonyPolls(r.Context())
+	if err != nil {
+		s.renderActionError(w, r, "Failed to load polls", err)
+		return
+	}
+
+	current := s.displayState().CategoryID
+	next := DisplayState{}
+	if current == 0 && len(polls) > 0 {
+		next.CategoryID = polls[0].Category.ID
+	}
+	for i, poll := range polls {
+		if poll.Category.ID == current && i+1 < len(polls) {
+			next.CategoryID = polls[i+1].Category.ID
+		}
+	}
+
+	s.setDisplay(next)
+	http.Redirect(w, r, AdminCeremonyURL(), http.StatusSeeOther)
+}
+
+// handleAdminCelebrate fires confetti and a fanfare on the displays showing
+// the poll in category_id, or on every display if it is 0 or missing
+func (s *Server) handleAdminCelebrate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	ev := CelebrateEvent{}
+	if id := r.FormValue("category_id"); id != "" && id != "0" {
+		categoryID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			s.actionError(w, r, http.StatusBadRequest, "Invalid poll ID")
+			return
+		}
+		cat, err := s.queries.Category(r.Context(), categoryID)
+		if errors.Is(err, db.ErrNotFound) {
+			s.actionError(w, r, http.StatusNotFound, "Poll not found")
+			return
+		}
+		if err != nil {
+			s.renderActionError(w, r, "Failed to load poll", err)
+			return
+		}
+		ev.CategoryID, ev.Name = cat.ID, cat.Name
+		if cat.Finished() {
+			_, results, err := s.tallyResults(r.Context(), cat)
+			if err != nil {
+				s.renderActionError(w, r, "Failed to tally results", err)
+				return
+			}
+			ev.Winner = strings.Join(leaders(results), " & ")
+		}
+	}
+
+	displays := s.publish(EventCelebrate, ev)
+	if s.isHTMX(r) {
+		what := "everyone"
+		if ev.Name != "" {
+			what = ev.Name
+		}
+		showToast(w, toastSuccess, fmt.Sprintf("Celebrating %s on %d display(s)", what, displays))
+		return
+	}
+	http.Redirect(w, r, AdminCeremonyURL(), http.StatusSeeOther)
+}
+
+// handleDisplay renders the projector display. It needs no login: it only
+// shows closed polls, and their results once the console reveals them.
+func (s *Server) handleDisplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	state := s.displayState()
+	data := DisplayPageData{Page: Page{Title: "Display"}, Revealed: state.Revealed}
+	if state.CategoryID != 0 {
+		cat, err := s.queries.Category(r.Context(), state.CategoryID)
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			s.renderError(w, "Failed to load poll", err)
+			return
+		}
+		// A poll deleted since it went on the display leaves the waiting
+		// screen up
+		if err == nil {
+			votes, results, err := s.tallyResults(r.Context(), cat)
+			if err != nil {
+				s.renderError(w, "Failed to tally results", err)
+				return
+			}
+			data.Page = Page{Title: cat.Name, Skin: pageSkin(cat.Skin, cat.CustomCss)}
+			data.Category = &cat
+			data.VoteCount = votes
+			data.Results = results
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	s.render(w, "display.html", data)
+}
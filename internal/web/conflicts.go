@@ -0,0 +1,105 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// voteConflict is an open conflict on the admin category page: two ballots
+// for one nickname from different devices seconds apart. Second is the one
+// that counts until an admin picks. VotedSince is set when the voter has
+// voted again, which leaves only keeping the second.
+type voteConflict struct {
+	ID         int64
+	Nickname   string
+	At         sql.NullTime
+	First      conflictBallot
+	Second     conflictBallot
+	VotedSince bool
+}
+
+// conflictBallot is one side of a conflict. Choices are option names, in
+// rank order for ranked categories.
+type conflictBallot struct {
+	Choices []string
+	Station string
+	Address string
+}
+
+// loadConflicts lists the open conflicts of a category, oldest first, with
+// nicknames revealed
+func (s *Server) loadConflicts(ctx context.Context, categoryID int64) ([]voteConflict, error) {
+	rows, err := s.queries.ListOpenVoteConflicts(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	conflicts := make([]voteConflict, len(rows))
+	for i, row := range rows {
+		c := voteConflict{
+			ID:         row.ID,
+			Nickname:   s.nicknames.Reveal(row.Nickname),
+			At:         row.CreatedAt,
+			First:      conflictBallot{Station: row.FirstStation, Address: row.FirstAddress},
+			Second:     conflictBallot{Station: row.SecondStation, Address: row.SecondAddress},
+			VotedSince: row.CurrentVersion != row.Version,
+		}
+		c.First.Choices, err = s.queries.ListVoteVersionChoices(ctx, db.ListVoteVersionChoicesParams{VoteID: row.VoteID, Version: row.Version - 1})
+		if err != nil {
+			return nil, err
+		}
+		if c.VotedSince {
+			c.Second.Choices, err = s.queries.ListVoteVersionChoices(ctx, db.ListVoteVersionChoicesParams{VoteID: row.VoteID, Version: row.Version})
+		} else {
+			c.Second.Choices, err = s.queries.ListVoteChoices(ctx, row.VoteID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		conflicts[i] = c
+	}
+	return conflicts, nil
+}
+
+// handleAdminConflict resolves a conflict by keeping its first
+// (/admin/conflicts/{id}/first) or second (/admin/conflicts/{id}/second)
+// ballot, then returns to the poll's ballots
+func (s *Server) handleAdminConflict(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/admin/conflicts/")
+	idStr, kept, _ := strings.Cut(rest, "/")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || (kept != db.KeptFirst && kept != db.KeptSecond) {
+		s.notFound(w, r)
+		return
+	}
+
+	var vote db.Vote
+	err = db.InTx(r.Context(), s.db, func(q *db.Queries) error {
+		c, err := q.ResolveConflict(r.Context(), id, kept)
+		if err != nil {
+			return err
+		}
+		if vote, err = q.GetVote(r.Context(), c.VoteID); err != nil {
+			return err
+		}
+		return q.RecordAudit(r.Context(), db.ActorAdmin, db.AuditVoteResolve, vote.CategoryID, "kept the "+kept+" ballot")
+	})
+	if errors.Is(err, db.ErrConflict) {
+		s.renderActionError(w, r, "Cannot resolve the conflict: "+err.Error(), err)
+		return
+	}
+	if err != nil {
+		s.lookupFailed(w, r, "conflict", err)
+		return
+	}
+	http.Redirect(w, r, AdminCategoryURL(vote.CategoryID, "conflicts"), http.StatusSeeOther)
+}
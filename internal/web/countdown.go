@@ -0,0 +1,101 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// maxCountdownMinutes caps how far ahead the console starts a countdown
+const maxCountdownMinutes = 24 * 60
+
+// CountdownEvent is the data of a countdown event: how long until the
+// ceremony starts (the ceremony_at setting), or 0 while no countdown runs.
+// Like BroadcastEvent it carries seconds left rather than a time, as kiosk
+// clocks can't be trusted.
+type CountdownEvent struct {
+	Seconds int64 `json:"seconds"`
+}
+
+// Clock formats the time left the way countdown.js does, e.g. 1:05:09 or
+// 4:07
+func (c CountdownEvent) Clock() string {
+	h, m, sec := c.Seconds/3600, c.Seconds%3600/60, c.Seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+	return fmt.Sprintf("%d:%02d", m, sec)
+}
+
+// ceremonyAt returns when the ceremony starts, or the zero time if no
+// countdown is set
+func (s *Server) ceremonyAt() time.Time {
+	// A value that doesn't parse was stored outside votigo; Lint warns about it
+	at, _ := time.Parse(time.RFC3339, s.setting(db.SettingCeremonyAt))
+	return at
+}
+
+// countdown returns how long until the ceremony starts; templates render it
+// in the page header and on the display
+func (s *Server) countdown() CountdownEvent {
+	at := s.ceremonyAt()
+	if at.IsZero() {
+		return CountdownEvent{}
+	}
+	return CountdownEvent{Seconds: max(int64(time.Until(at).Seconds()), 0)}
+}
+
+// handleAdminCeremonyCountdown sets when the ceremony starts, minutes from
+// now or at a time (at), or stops the countdown when clear is set, and
+// pushes it to every listening page. It is stored as the ceremony_at
+// setting, so it survives a restart.
+func (s *Server) handleAdminCeremonyCountdown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	var value string
+	switch {
+	case r.FormValue("clear") != "":
+	case r.FormValue("minutes") != "":
+		n, err := strconv.Atoi(r.FormValue("minutes"))
+		if err != nil || n < 1 || n > maxCountdownMinutes {
+			s.actionError(w, r, http.StatusBadRequest, fmt.Sprintf("Start the countdown 1 to %d minutes from now", maxCountdownMinutes))
+			return
+		}
+		value = time.Now().Add(time.Duration(n) * time.Minute).Format(time.RFC3339)
+	default:
+		spec, _ := db.LookupSetting(db.SettingCeremonyAt)
+		at, err := spec.Parse(r.FormValue("at"))
+		if err != nil || at == "" {
+			s.actionError(w, r, http.StatusBadRequest, "Pick when the ceremony starts")
+			return
+		}
+		if t, _ := time.Parse(time.RFC3339, at); !t.After(time.Now()) {
+			s.actionError(w, r, http.StatusBadRequest, "That time has already passed")
+			return
+		}
+		value = at
+	}
+
+	if err := s.saveSetting(r, db.SettingCeremonyAt, value); err != nil {
+		s.renderActionError(w, r, "Failed to save the ceremony time", err)
+		return
+	}
+	c := s.countdown()
+	pages := s.publish(EventCountdown, c)
+
+	if s.isHTMX(r) {
+		if c.Seconds == 0 {
+			showToast(w, toastSuccess, "Countdown stopped")
+		} else {
+			showToast(w, toastSuccess, fmt.Sprintf("Counting down from %s on %d page(s)", c.Clock(), pages))
+		}
+		return
+	}
+	http.Redirect(w, r, AdminCeremonyURL(), http.StatusSeeOther)
+}
@@ -0,0 +1,39 @@
+package web
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/palm-arcade/votigo/internal/dataset"
+)
+
+// handleAdminBallotsExport downloads the anonymized ballots of every
+// finished poll (see package dataset) as CSV or, for /admin/ballots.json,
+// JSON, for attendees to run their own analyses
+func (s *Server) handleAdminBallotsExport(w http.ResponseWriter, r *http.Request, format string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+	polls, err := dataset.Finished(r.Context(), s.reads)
+	if err != nil {
+		s.renderError(w, "Failed to load polls", err)
+		return
+	}
+	ds, err := dataset.Build(r.Context(), s.reads, polls)
+	if err != nil {
+		s.renderError(w, "Failed to load ballots", err)
+		return
+	}
+
+	write := dataset.WriteCSV
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	if format == "json" {
+		write = dataset.WriteJSON
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="ballots.`+format+`"`)
+	if err := write(w, ds); err != nil {
+		log.Printf("Failed to write ballot export: %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/oembed"
+)
+
+// Limits on an option's details, so a modal stays a quick read
+const (
+	maxBlurbLength = 2000
+	maxScreenshots = 8
+)
+
+// OptionDetails is what voters can read about an option from the ballot
+// before choosing. The admin form and `votigo option details` both go
+// through Normalize.
+type OptionDetails struct {
+	Blurb       string
+	Video       string   // http(s) link; YouTube and Vimeo links play in the modal
+	Screenshots []string // uploaded images or http(s) URLs
+}
+
+// DetailsOf returns an option's current details
+func DetailsOf(o db.Option) OptionDetails {
+	return OptionDetails{Blurb: o.Blurb, Video: o.Video, Screenshots: o.ScreenshotURLs()}
+}
+
+// Normalize trims the details and checks them, returning an error that
+// can be shown to the admin
+func (d *OptionDetails) Normalize() error {
+	d.Blurb = strings.TrimSpace(strings.ReplaceAll(d.Blurb, "\r\n", "\n"))
+	d.Video = strings.TrimSpace(d.Video)
+	var shots []string
+	for _, u := range d.Screenshots {
+		shots = append(shots, strings.Fields(u)...)
+	}
+	d.Screenshots = shots
+
+	if n := len([]rune(d.Blurb)); n > maxBlurbLength {
+		return fmt.Errorf("the blurb is %d characters; keep it to %d", n, maxBlurbLength)
+	}
+	if d.Video != "" {
+		if _, err := oembed.Parse(d.Video); err != nil {
+			return err
+		}
+	}
+	if len(d.Screenshots) > maxScreenshots {
+		return fmt.Errorf("%d screenshots is too many; keep it to %d", len(d.Screenshots), maxScreenshots)
+	}
+	for _, u := range d.Screenshots {
+		if !ValidImageURL(u) {
+			return fmt.Errorf("screenshot %q must be an uploaded image or an http(s) URL", u)
+		}
+	}
+	return nil
+}
+
+// Empty reports whether there are no details to show
+func (d OptionDetails) Empty() bool {
+	return d.Blurb == "" && d.Video == "" && len(d.Screenshots) == 0
+}
+
+// Params returns the query parameters that store the details on option id
+func (d OptionDetails) Params(id int64) db.SetOptionDetailsParams {
+	return db.SetOptionDetailsParams{
+		Blurb:       d.Blurb,
+		Video:       d.Video,
+		Screenshots: strings.Join(d.Screenshots, "\n"),
+		ID:          id,
+	}
+}
+
+// WithMediaPreviews fetches titles and thumbnails for options' YouTube and
+// Vimeo links through c when admins save them, and checks the videos can
+// be played. Without it links are only checked for their form.
+func WithMediaPreviews(c *oembed.Client) Option {
+	return func(s *Server) {
+		s.media = c
+	}
+}
+
+// RefreshPreview fetches a video link's title and thumbnail from its
+// provider and caches them for ballots, which never go out to the internet
+// themselves. Links to other sites have no preview. A video the provider
+// refuses is oembed.ErrUnavailable, and drops its cached preview; other
+// errors, like no internet at the venue, leave the cache as it was.
+func RefreshPreview(ctx context.Context, q *db.Queries, c *oembed.Client, link string) error {
+	if link == "" {
+		return nil
+	}
+	v, err := oembed.Parse(link)
+	if err != nil || v.Provider == "" {
+		return err
+	}
+	p, err := c.Fetch(ctx, v)
+	if errors.Is(err, oembed.ErrUnavailable) {
+		if err := q.DeleteMediaPreview(ctx, v.Canonical()); err != nil {
+			return err
+		}
+		return fmt.Errorf("%s says %w", v.Provider, err)
+	}
+	if err != nil {
+		return err
+	}
+	return q.UpsertMediaPreview(ctx, db.UpsertMediaPreviewParams{
+		Link:      v.Canonical(),
+		Provider:  p.Provider,
+		Title:     p.Title,
+		Author:    p.Author,
+		Thumbnail: p.Thumbnail,
+	})
+}
+
+// videoOf returns the player and cached preview for an option's video
+// link; either is empty when there is none
+func (s *Server) videoOf(ctx context.Context, link string) (string, db.MediaPreview, error) {
+	v, err := oembed.Parse(link)
+	if link == "" || err != nil || v.Provider == "" {
+		return "", db.MediaPreview{}, nil
+	}
+	preview, err := s.queries.GetMediaPreview(ctx, v.Canonical())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", db.MediaPreview{}, err
+	}
+	return v.Embed(), preview, nil
+}
+
+// paragraphs splits a blurb into paragraphs at blank lines
+func paragraphs(blurb string) []string {
+	var out []string
+	for _, p := range strings.Split(blurb, "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// handleOptionDetails shows an option's details to voters
+// (/vote/{ref}/option/{id}): in the ballot's modal for htmx requests, on a
+// page of their own otherwise. Options of draft polls are only shown to
+// admins.
+func (s *Server) handleOptionDetails(w http.ResponseWriter, r *http.Request, ref, optionID string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+	cat, ok := s.categoryByRef(w, r, ref)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(optionID, 10, 64)
+	if err != nil || (cat.Status == "draft" && !s.authorized(r)) {
+		s.notFound(w, r)
+		return
+	}
+	opt, err := s.queries.Option(r.Context(), id)
+	if errors.Is(err, db.ErrNotFound) || (err == nil && opt.CategoryID != cat.ID) {
+		s.notFound(w, r)
+		return
+	}
+	if err != nil {
+		s.renderError(w, "Failed to load the option", err)
+		return
+	}
+
+	data := OptionPageData{
+		Page:       Page{Title: opt.Name, Skin: pageSkin(cat.Skin, cat.CustomCss)},
+		Category:   cat,
+		Option:     opt,
+		Paragraphs: paragraphs(opt.Blurb),
+	}
+	if data.Embed, data.Preview, err = s.videoOf(r.Context(), opt.Video); err != nil {
+		s.renderError(w, "Failed to load the option", err)
+		return
+	}
+	if s.isHTMX(r) {
+		s.renderPartial(w, "partials/option-details.html", data)
+		return
+	}
+	s.render(w, "option.html", data)
+}
+
+// handleAdminOptionDetails edits an option's details
+// (/admin/option/{id}/details)
+func (s *Server) handleAdminOptionDetails(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		return
+	}
+	id, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/admin/option/"), "/details"), 10, 64)
+	if err != nil {
+		s.notFound(w, r)
+		return
+	}
+	opt, err := s.queries.Option(r.Context(), id)
+	if err != nil {
+		s.lookupFailed(w, r, "option", err)
+		return
+	}
+	cat, err := s.queries.Category(r.Context(), opt.CategoryID)
+	if err != nil {
+		s.lookupFailed(w, r, "category", err)
+		return
+	}
+
+	details := DetailsOf(opt)
+	var formError string
+	if r.Method == http.MethodPost {
+		details = OptionDetails{
+			Blurb:       r.FormValue("blurb"),
+			Video:       r.FormValue("video"),
+			Screenshots: []string{r.FormValue("screenshots")},
+		}
+		if err := details.Normalize(); err != nil {
+			formError = err.Error()
+		} else if err := s.refreshPreview(r.Context(), details.Video); errors.Is(err, oembed.ErrUnavailable) {
+			formError = err.Error()
+		} else {
+			if err := s.queries.SetOptionDetails(r.Context(), details.Params(opt.ID)); err != nil {
+				s.renderActionError(w, r, "Failed to save the details", err)
+				return
+			}
+			s.audit(r, db.AuditOptionDetails, opt.CategoryID, opt.Name)
+			http.Redirect(w, r, AdminCategoryURL(opt.CategoryID, "options"), http.StatusSeeOther)
+			return
+		}
+	}
+
+	data := OptionDetailsPageData{
+		Page:        Page{Title: opt.Name + " details"},
+		Category:    cat,
+		Option:      opt,
+		Blurb:       details.Blurb,
+		Video:       details.Video,
+		Screenshots: strings.Join(details.Screenshots, "\n"),
+		Previews:    s.media != nil,
+		Error:       formError,
+	}
+	if _, data.Preview, err = s.videoOf(r.Context(), opt.Video); err != nil {
+		s.renderError(w, "Failed to load the option", err)
+		return
+	}
+	if formError != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	s.render(w, "admin/option.html", data)
+}
+
+// refreshPreview refreshes a video's cached preview when the server
+// fetches them, logging failures other than the provider refusing it
+func (s *Server) refreshPreview(ctx context.Context, link string) error {
+	if s.media == nil {
+		return nil
+	}
+	err := RefreshPreview(ctx, s.queries, s.media, link)
+	if err != nil && !errors.Is(err, oembed.ErrUnavailable) {
+		log.Printf("No preview for %s: %v", link, err)
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// maxSuggestions caps how many polls a 404 page offers
+const maxSuggestions = 3
+
+// pollSuggestion is a poll the 404 page offers in place of the missing page
+type pollSuggestion struct {
+	Name string
+	URL  string
+}
+
+// notFound answers with a 404: JSON under /api, a toast to HTMX, and
+// otherwise the themed error page, suggesting polls whose names match the
+// last part of the path
+func (s *Server) notFound(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case isAPIPath(r):
+		writeAPIError(w, http.StatusNotFound, "Not found")
+		return
+	case s.isHTMX(r):
+		s.htmxError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+	s.render(w, "error.html", map[string]any{
+		"Title":       "Not Found",
+		"Heading":     "Not Found",
+		"Message":     "There's nothing at " + r.URL.Path,
+		"Suggestions": s.suggestPolls(r),
+	})
+}
+
+// methodNotAllowed answers with a 405 listing the allowed methods, in the
+// same forms as notFound
+func (s *Server) methodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	switch {
+	case isAPIPath(r):
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	case s.isHTMX(r):
+		s.htmxError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	s.render(w, "error.html", map[string]any{
+		"Title":   "Method Not Allowed",
+		"Heading": "Not Allowed",
+		"Message": fmt.Sprintf("%s %s only accepts %s", r.Method, r.URL.Path, strings.Join(allowed, " or ")),
+	})
+}
+
+// errorStatus is the status code for a failure: 404 for a missing row, 409
+// for a conflict or a poll that isn't open, otherwise 500
+func errorStatus(err error) int {
+	err = db.Classify(err)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrConflict), errors.Is(err, db.ErrClosed):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// lookupFailed answers a failed lookup of the poll or option a request
+// names: a 404 if it doesn't exist, otherwise an error saying what couldn't
+// be loaded
+func (s *Server) lookupFailed(w http.ResponseWriter, r *http.Request, what string, err error) {
+	if errors.Is(err, db.ErrNotFound) {
+		s.notFound(w, r)
+		return
+	}
+	s.renderActionError(w, r, "Failed to load "+what, err)
+}
+
+// isAPIPath reports whether r is for the JSON API, whose errors are JSON too
+func isAPIPath(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/")
+}
+
+// suggestPolls treats the last part of a missing path as a poll name
+// ("/vote/best-game") and returns the polls it matches. Voters are only
+// offered open and closed polls; admin pages may suggest drafts too.
+func (s *Server) suggestPolls(r *http.Request) []pollSuggestion {
+	ref := strings.NewReplacer("-", " ", "_", " ", "+", " ").Replace(path.Base(r.URL.Path))
+	if len(strings.TrimSpace(ref)) < 3 {
+		return nil
+	}
+	if _, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return nil
+	}
+
+	polls, err := s.queries.ListCategoriesExcludeArchived(r.Context())
+	if err != nil {
+		log.Printf("Failed to list polls for suggestions: %v", err)
+		return nil
+	}
+
+	admin := strings.HasPrefix(r.URL.Path, "/admin")
+	if !admin {
+		var public []db.Category
+		for _, c := range polls {
+			if c.Status != "draft" {
+				public = append(public, c)
+			}
+		}
+		polls = public
+	}
+
+	var suggestions []pollSuggestion
+	for _, c := range db.MatchCategories(polls, ref) {
+		url := ResultsURL(c.Ref())
+		switch {
+		case admin:
+			url = AdminCategoryURL(c.ID)
+		case c.Status == "open":
+			url = VoteURL(c.Ref())
+		}
+		suggestions = append(suggestions, pollSuggestion{Name: c.Name, URL: url})
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,233 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Realtime event names, sent as the SSE event field
+const (
+	EventCelebrate = "celebrate" // fire confetti and a fanfare on the display
+	EventDisplay   = "display"   // the display shows another poll or reveals results; see DisplayState
+	EventBroadcast = "broadcast" // show or take down an organizer's banner on voter pages; see BroadcastEvent
+	EventCountdown = "countdown" // the ceremony countdown was set or stopped; see CountdownEvent
+)
+
+// eventStreamMax is how long one /events response lasts. Browsers reconnect
+// on their own when it ends, so this only bounds how long a request holds on
+// to its handler timeout, not how long a display stays connected.
+const eventStreamMax = 10 * time.Minute
+
+// eventRetry is how long browsers wait before reconnecting to /events
+const eventRetry = 3 * time.Second
+
+// subscriberBuffer is how many events a display may fall behind by before
+// it is dropped (see eventHub.publish)
+const subscriberBuffer = 16
+
+// eventsPerHost caps the streams one address may hold open. A display needs
+// one; a browser stuck reconnecting in a loop, or a kiosk with a tab per
+// reload, is refused more instead of costing a handler and buffer each.
+const eventsPerHost = 8
+
+// eventHeartbeat is how often a quiet stream gets a comment line, so
+// proxies don't time it out and a display that went away is noticed when
+// the write fails
+const eventHeartbeat = 20 * time.Second
+
+// eventWriteTimeout bounds one write to a stream. A display that stopped
+// reading, like a frozen browser or a laptop gone to sleep, fails it and
+// is let go instead of holding the handler until eventStreamMax.
+const eventWriteTimeout = 10 * time.Second
+
+// errTooManyStreams refuses a stream past eventsPerHost
+var errTooManyStreams = errors.New("too many event streams from this address")
+
+// Event is a message for the pages listening on /events. Data is encoded as
+// JSON.
+type Event struct {
+	Name string
+	Data any
+}
+
+// CelebrateEvent is the data of a celebrate event. CategoryID limits it to
+// the display showing that poll; zero celebrates on every display.
+type CelebrateEvent struct {
+	CategoryID int64  `json:"category_id"`
+	Name       string `json:"name,omitempty"`
+	Winner     string `json:"winner,omitempty"`
+}
+
+// eventHub fans events out to the connected /events streams. Publishing
+// never blocks: a subscriber whose buffer is full is dropped.
+type eventHub struct {
+	mu     sync.Mutex
+	subs   map[chan Event]string // the address each stream is for
+	hosts  map[string]int        // streams per address
+	closed bool
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[chan Event]string), hosts: make(map[string]int)}
+}
+
+// subscribe returns a channel of events for a stream to host and a function
+// to stop receiving them. The channel is closed when the hub is, or when
+// the stream falls too far behind. Past eventsPerHost streams for host it
+// returns errTooManyStreams.
+func (h *eventHub) subscribe(host string) (<-chan Event, func(), error) {
+	ch := make(chan Event, subscriberBuffer)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}, nil
+	}
+	if h.hosts[host] >= eventsPerHost {
+		return nil, nil, errTooManyStreams
+	}
+	h.subs[ch] = host
+	h.hosts[host]++
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(ch)
+	}, nil
+}
+
+// remove ends a stream if it is still subscribed. h.mu must be held.
+func (h *eventHub) remove(ch chan Event) {
+	host, ok := h.subs[ch]
+	if !ok {
+		return
+	}
+	delete(h.subs, ch)
+	close(ch)
+	if h.hosts[host]--; h.hosts[host] == 0 {
+		delete(h.hosts, host)
+	}
+}
+
+// publish sends ev to every subscriber and returns how many received it.
+// A subscriber with a full buffer has stopped keeping up, so rather than
+// quietly missing events it is dropped: its stream ends, and the browser
+// reconnects and starts again from the current display state.
+func (h *eventHub) publish(ev Event) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sent := 0
+	for ch, host := range h.subs {
+		select {
+		case ch <- ev:
+			sent++
+		default:
+			log.Printf("Dropping event stream to %s: %d events behind", host, len(ch))
+			h.remove(ch)
+		}
+	}
+	return sent
+}
+
+// close ends every stream, so shutdown doesn't wait out eventStreamMax
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		h.remove(ch)
+	}
+}
+
+// listeners is how many streams are connected
+func (h *eventHub) listeners() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// publish sends an event to every page listening on /events and returns how
+// many received it
+func (s *Server) publish(name string, data any) int {
+	return s.events.publish(Event{Name: name, Data: data})
+}
+
+// handleEvents streams realtime events as server-sent events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	// Subscribe first so no event falls between the state below and the stream
+	events, unsubscribe, err := s.events.subscribe(clientHost(r))
+	if err != nil {
+		w.Header().Set("Retry-After", strconv.Itoa(int(eventRetry.Seconds())))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	defer unsubscribe()
+
+	rc := http.NewResponseController(w)
+	// Each write gets its own deadline; not every ResponseWriter supports
+	// them, and streams then rely on eventStreamMax alone
+	extend := func() { rc.SetWriteDeadline(time.Now().Add(eventWriteTimeout)) }
+	extend()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keep reverse proxies from holding events back
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetry.Milliseconds())
+	// Start with what the display shows, the countdown and any announcement
+	// still up, so a page that missed events while reconnecting catches up
+	writeEvent(w, Event{Name: EventDisplay, Data: s.displayState()})
+	writeEvent(w, Event{Name: EventCountdown, Data: s.countdown()})
+	if ev, ok := s.currentBroadcast(); ok {
+		writeEvent(w, Event{Name: EventBroadcast, Data: ev})
+	}
+	if err := rc.Flush(); err != nil {
+		log.Printf("Error: event stream can't flush: %v", err)
+		return
+	}
+
+	end := time.NewTimer(eventStreamMax)
+	defer end.Stop()
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-end.C:
+			return
+		case <-heartbeat.C:
+			extend()
+			fmt.Fprint(w, ": ping\n\n")
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			extend()
+			writeEvent(w, ev)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes ev in the server-sent event format
+func writeEvent(w http.ResponseWriter, ev Event) {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		log.Printf("Error: failed to encode %s event: %v", ev.Name, err)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Name, data)
+}
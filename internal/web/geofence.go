@@ -0,0 +1,48 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// errRemoteBallot refuses a ballot from outside the LAN in LAN-only mode.
+// Its message is shown to the voter.
+var errRemoteBallot = errors.New("Voting is only open on the local network")
+
+// clientHost returns the address a request came from, without the port.
+// Votigo runs on the LAN without a proxy, so the peer address is the voter.
+func clientHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// isLANHost reports whether host is a private, loopback or link-local
+// address. Anything unparseable counts as remote.
+func isLANHost(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast())
+}
+
+// ballotOrigin checks where a ballot for cat came from. A ballot from
+// outside the LAN is refused with errRemoteBallot when the lan_only setting
+// is on; otherwise it is logged and reported as remote so the admin page can
+// flag it.
+func (s *Server) ballotOrigin(r *http.Request, cat db.Category) (remote bool, err error) {
+	host := clientHost(r)
+	if isLANHost(host) {
+		return false, nil
+	}
+	if s.settingBool(db.SettingLANOnly) {
+		log.Printf("Refused ballot for poll %d from non-LAN address %s", cat.ID, host)
+		return true, errRemoteBallot
+	}
+	log.Printf("Ballot for poll %d from non-LAN address %s flagged as remote", cat.ID, host)
+	return true, nil
+}
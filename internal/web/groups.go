@@ -0,0 +1,66 @@
+package web
+
+import "net/http"
+
+// handleAdminGroups breaks a poll's turnout down by roster tag
+// (/admin/category/{id}/groups). ?tallies=1 also counts each group's
+// ballots on their own, which shows how a group voted, so it is only done
+// when asked for.
+func (s *Server) handleAdminGroups(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	cat, err := s.queries.Category(r.Context(), id)
+	if err != nil {
+		s.lookupFailed(w, r, "category", err)
+		return
+	}
+	data := GroupsPageData{
+		Page:     Page{Title: cat.Name + " by group"},
+		Category: cat,
+		Tallies:  r.URL.Query().Get("tallies") != "",
+	}
+
+	if data.Purged, err = s.reads.Purged(r.Context(), cat.ID); err != nil {
+		s.renderError(w, "Failed to load the poll", err)
+		return
+	}
+	if data.Purged {
+		s.render(w, "admin/groups.html", data)
+		return
+	}
+	if data.VoteCount, err = s.reads.CountVotesByCategory(r.Context(), cat.ID); err != nil {
+		s.renderError(w, "Failed to count ballots", err)
+		return
+	}
+	if data.OffRoster, err = s.reads.CountOffRosterVotes(r.Context(), cat.ID); err != nil {
+		s.renderError(w, "Failed to count ballots", err)
+		return
+	}
+	groups, err := s.reads.GroupResults(r.Context(), cat, data.Tallies)
+	if err != nil {
+		s.renderError(w, "Failed to break down turnout", err)
+		return
+	}
+	if data.Tallies && data.VoteCount > 0 {
+		tallied, err := s.reads.Tally(r.Context(), cat)
+		if err != nil {
+			s.renderError(w, "Failed to tally results", err)
+			return
+		}
+		if len(tallied) > 0 {
+			data.Winner = &tallied[0]
+		}
+	}
+
+	for _, g := range groups {
+		row := GroupRow{GroupResult: g}
+		if winner, ok := g.Winner(); ok {
+			row.Winner = &winner
+			row.Differs = data.Winner != nil && winner.ID != data.Winner.ID
+		}
+		data.Groups = append(data.Groups, row)
+	}
+	s.render(w, "admin/groups.html", data)
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// voterHistory is one voter's replaced ballots, oldest first
+type voterHistory struct {
+	Nickname string
+	Versions []ballotVersion
+}
+
+// ballotVersion is a superseded ballot. Choices are option names, in rank
+// order for ranked categories.
+type ballotVersion struct {
+	Version    int64
+	Choices    []string
+	ReplacedAt sql.NullTime
+}
+
+// voterBallot is a voter's current ballot on the admin category page.
+// Remote ballots came from outside the LAN and may be contested.
+type voterBallot struct {
+	Nickname string
+	CastAt   sql.NullTime
+	Version  int64
+	Remote   bool
+}
+
+// loadBallots lists the current ballots of a category, oldest first, with
+// nicknames revealed, and counts the remote ones
+func (s *Server) loadBallots(ctx context.Context, categoryID int64) ([]voterBallot, int, error) {
+	votes, err := s.queries.ListVotesByCategory(ctx, categoryID)
+	if err != nil {
+		return nil, 0, err
+	}
+	ballots := make([]voterBallot, len(votes))
+	remote := 0
+	for i, v := range votes {
+		ballots[i] = voterBallot{
+			Nickname: s.nicknames.Reveal(v.Nickname),
+			CastAt:   v.CreatedAt,
+			Version:  v.Version,
+			Remote:   v.Remote,
+		}
+		if v.Remote {
+			remote++
+		}
+	}
+	return ballots, remote, nil
+}
+
+// maxWallVoters bounds how many voters the voted wall lists
+const maxWallVoters = 60
+
+// loadVotedWall lists who has voted in a category, or nil if the category
+// doesn't show a wall. Avatar walls leave nicknames out of the page
+// entirely.
+func (s *Server) loadVotedWall(ctx context.Context, cat db.Category) (*VotedWall, error) {
+	if cat.VotedWall == "" {
+		return nil, nil
+	}
+	votes, err := s.queries.ListVotesByCategory(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	wall := &VotedWall{Avatars: cat.VotedWall == "avatars"}
+	for i := len(votes) - 1; i >= 0 && len(wall.Voters) < maxWallVoters; i-- {
+		nickname := s.nicknames.Reveal(votes[i].Nickname)
+		voter := WallVoter{Avatar: s.avatarURL(nickname)}
+		if !wall.Avatars {
+			voter.Nickname = nickname
+		}
+		wall.Voters = append(wall.Voters, voter)
+	}
+	wall.More = len(votes) - len(wall.Voters)
+	return wall, nil
+}
+
+// groupVoteHistory folds history rows (ordered by nickname, version, rank)
+// into per-voter ballot versions.
+func groupVoteHistory(rows []db.ListVoteHistoryByCategoryRow) []voterHistory {
+	var voters []voterHistory
+	for _, row := range rows {
+		if len(voters) == 0 || voters[len(voters)-1].Nickname != row.Nickname {
+			voters = append(voters, voterHistory{Nickname: row.Nickname})
+		}
+		v := &voters[len(voters)-1]
+		if len(v.Versions) == 0 || v.Versions[len(v.Versions)-1].Version != row.Version {
+			v.Versions = append(v.Versions, ballotVersion{Version: row.Version, ReplacedAt: row.ReplacedAt})
+		}
+		last := &v.Versions[len(v.Versions)-1]
+		last.Choices = append(last.Choices, row.OptionName)
+	}
+	return voters
+}
+
+// loadVoteHistory gathers churn totals and per-voter ballot history for the
+// admin category page.
+func (s *Server) loadVoteHistory(ctx context.Context, categoryID int64) (db.GetVoteChurnRow, []voterHistory, error) {
+	churn, err := s.queries.GetVoteChurn(ctx, categoryID)
+	if err != nil {
+		return churn, nil, err
+	}
+	rows, err := s.queries.ListVoteHistoryByCategory(ctx, categoryID)
+	if err != nil {
+		return churn, nil, err
+	}
+	for i := range rows {
+		rows[i].Nickname = s.nicknames.Reveal(rows[i].Nickname)
+	}
+	return churn, groupVoteHistory(rows), nil
+}
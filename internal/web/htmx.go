@@ -0,0 +1,66 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// toastArea is the element in the modern layout that toasts are added to
+const toastArea = "#toasts"
+
+// Toast kinds, each styled by a toast-<kind> class
+const (
+	toastSuccess = "success"
+	toastError   = "error"
+	toastInfo    = "info"
+)
+
+// toast is a short message shown in the corner of the page
+type toast struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// showToast has the page show a toast once an HTMX response is swapped in,
+// by raising a "toast" event through the HX-Trigger header. It must be
+// called before the response is written.
+func showToast(w http.ResponseWriter, kind, message string) {
+	trigger, _ := json.Marshal(map[string]toast{"toast": {Kind: kind, Message: message}})
+	w.Header().Set("HX-Trigger", string(trigger))
+}
+
+// htmxError answers a failed HTMX action with its status code and an error
+// toast. htmx doesn't swap error responses into the request's own target,
+// so the response retargets itself at the toast area; toasts.js lets such
+// responses through.
+func (s *Server) htmxError(w http.ResponseWriter, status int, message string) {
+	if _, ok := s.partial("partials/toast.html"); !ok {
+		http.Error(w, message, status)
+		return
+	}
+	w.Header().Set("HX-Retarget", toastArea)
+	w.Header().Set("HX-Reswap", "beforeend")
+	w.WriteHeader(status)
+	s.renderPartial(w, "partials/toast.html", toast{Kind: toastError, Message: message})
+}
+
+// actionError reports an admin action that failed: as a toast to HTMX,
+// otherwise as plain text
+func (s *Server) actionError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if s.isHTMX(r) {
+		s.htmxError(w, status, message)
+		return
+	}
+	http.Error(w, message, status)
+}
+
+// renderActionError is renderError for handlers that HTMX calls too
+func (s *Server) renderActionError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	if !s.isHTMX(r) {
+		s.renderError(w, message, err)
+		return
+	}
+	log.Printf("Error: %s: %v", message, err)
+	s.htmxError(w, errorStatus(err), message)
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// Idempotency keys are embedded in each rendered ballot so that htmx retries
+// and double-clicks replay the first response instead of racing UpsertVote.
+const (
+	idempotencyKeyTTL   = 24 * time.Hour
+	idempotencyKeyField = "idempotency_key"
+	idempotencyHeader   = "Idempotency-Key"
+)
+
+// errBallotReplayed means the submission's idempotency key was already used,
+// so the ballot has been recorded and must not be processed again.
+var errBallotReplayed = errors.New("ballot already submitted")
+
+// errIdempotencyKeyReused means the submission's idempotency key was already
+// used for a ballot in another poll or under another nickname. Nothing was
+// recorded; the client must send this ballot under a key of its own.
+var errIdempotencyKeyReused = errors.New("idempotency key already used for a different ballot")
+
+// newIdempotencyKey returns a random key for a freshly rendered ballot
+func newIdempotencyKey() string {
+	return rand.Text()
+}
+
+// replayedBallot reports whether key was already used for nickname's ballot
+// in cat. A key used by anyone else isn't a replay; writeBallot refuses it.
+func (s *Server) replayedBallot(ctx context.Context, cat db.Category, key, nickname string) bool {
+	if key == "" {
+		return false
+	}
+	prior, err := s.queries.GetIdempotencyKey(ctx, key)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Failed to look up idempotency key: %v", err)
+		}
+		return false
+	}
+	return prior.CategoryID == cat.ID && prior.Nickname == s.nicknames.Seal(nickname)
+}
@@ -0,0 +1,327 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif" // decode GIF uploads
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/slug"
+)
+
+// maxImageBytes bounds an uploaded option image
+const maxImageBytes = 10 << 20
+
+// maxImagePixels bounds the size an uploaded image may decode to, so a small
+// file can't claim gigabytes of memory
+const maxImagePixels = 40_000_000
+
+// Uploaded images are scaled to fit within imageSide pixels; thumbnails,
+// shown on ballots and results, within thumbSide
+const (
+	imageSide = 1600
+	thumbSide = 256
+)
+
+// imageExts are the formats uploaded images are stored in: photos stay JPEG,
+// everything else becomes PNG so transparency survives
+var imageExts = []string{".jpg", ".png"}
+
+// WithImageDir keeps uploaded option images, and their thumbnails, in dir and
+// serves them under /images/. Without it images can't be uploaded, though
+// options can still show images hosted elsewhere.
+func WithImageDir(dir string) Option {
+	return func(s *Server) {
+		s.imageDir = dir
+	}
+}
+
+// validImageName reports whether name is one upload could have produced: a
+// slug with a stored extension, so it can't leave the image directory
+func validImageName(name string) bool {
+	ext := path.Ext(name)
+	return slices.Contains(imageExts, ext) && slug.Valid(strings.TrimSuffix(name, ext))
+}
+
+// ValidImageURL reports whether u is empty, an uploaded image or an http(s)
+// URL, the images an option may show
+func ValidImageURL(u string) bool {
+	if u == "" {
+		return true
+	}
+	if name, ok := strings.CutPrefix(u, "/images/"); ok {
+		return validImageName(name)
+	}
+	parsed, err := url.Parse(u)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// thumbnailURL returns the thumbnail of an option's image. Images hosted
+// elsewhere have none, so they are shown as they are.
+func thumbnailURL(u string) string {
+	if name, ok := strings.CutPrefix(u, "/images/"); ok {
+		return ImageThumbURL(name)
+	}
+	return u
+}
+
+// handleImage serves an uploaded image or its thumbnail. Names carry a hash
+// of the content, so a name never changes meaning and browsers may keep it
+// for good.
+func (s *Server) handleImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+	file := strings.TrimPrefix(r.URL.Path, "/images/")
+	name := strings.TrimPrefix(file, "thumbs/")
+	if s.imageDir == "" || !validImageName(name) {
+		s.notFound(w, r)
+		return
+	}
+	if _, err := os.Stat(filepath.Join(s.imageDir, filepath.FromSlash(file))); err != nil {
+		s.notFound(w, r)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeFileFS(w, r, os.DirFS(s.imageDir), file)
+}
+
+// handleAdminOptionImage sets the image an option shows: an uploaded file,
+// an image hosted elsewhere, or none if "remove" is set or both are empty
+func (s *Server) handleAdminOptionImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/admin/option/"), "/image"), 10, 64)
+	if err != nil {
+		s.notFound(w, r)
+		return
+	}
+	opt, err := s.queries.Option(r.Context(), id)
+	if errors.Is(err, db.ErrNotFound) {
+		s.actionError(w, r, http.StatusNotFound, "Option not found")
+		return
+	}
+	if err != nil {
+		s.renderActionError(w, r, "Failed to load option", err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageBytes+64<<10)
+	imageURL := strings.TrimSpace(r.FormValue("image_url"))
+	file, header, err := r.FormFile("image")
+	switch {
+	case err == nil:
+		defer file.Close()
+		if s.imageDir == "" {
+			s.actionError(w, r, http.StatusConflict, "Start the server with --image-dir to upload images")
+			return
+		}
+		var message string
+		imageURL, message, err = s.storeImage(file, header.Filename)
+		if message != "" {
+			s.actionError(w, r, http.StatusBadRequest, message)
+			return
+		}
+		if err != nil {
+			s.renderActionError(w, r, "Failed to store image", err)
+			return
+		}
+	case errors.As(err, new(*http.MaxBytesError)):
+		s.actionError(w, r, http.StatusRequestEntityTooLarge, "Images are limited to 10 MB")
+		return
+	case !errors.Is(err, http.ErrMissingFile) && !errors.Is(err, http.ErrNotMultipart):
+		s.actionError(w, r, http.StatusBadRequest, "Failed to read the upload")
+		return
+	}
+	if r.FormValue("remove") != "" {
+		imageURL = ""
+	}
+	if !ValidImageURL(imageURL) {
+		s.actionError(w, r, http.StatusBadRequest, "Image must be an uploaded image or an http(s) URL")
+		return
+	}
+
+	if err := s.queries.SetOptionImage(r.Context(), db.SetOptionImageParams{Image: imageURL, ID: opt.ID}); err != nil {
+		s.renderActionError(w, r, "Failed to set image", err)
+		return
+	}
+	if imageURL != opt.Image {
+		s.releaseImage(r.Context(), opt.Image)
+	}
+	detail := opt.Name + ": " + imageURL
+	if imageURL == "" {
+		detail = opt.Name + ": removed"
+	}
+	s.audit(r, db.AuditOptionImage, opt.CategoryID, detail)
+	http.Redirect(w, r, AdminCategoryURL(opt.CategoryID, "options"), http.StatusSeeOther)
+}
+
+// storeImage decodes an uploaded image, scales it and a thumbnail down to
+// size and stores both, returning the image's URL. Uploads that aren't
+// usable images get a message for the admin instead.
+func (s *Server) storeImage(r io.Reader, filename string) (u, message string, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		if errors.As(err, new(*http.MaxBytesError)) {
+			return "", "Images are limited to 10 MB", nil
+		}
+		return "", "", err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", "Images must be JPEG, PNG or GIF files", nil
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return "", "That image is too large; keep it under 40 megapixels", nil
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", "That image couldn't be read", nil
+	}
+
+	ext := ".png"
+	if format == "jpeg" {
+		ext = ".jpg"
+	}
+	full, err := encodeImage(scaleDown(src, imageSide), ext)
+	if err != nil {
+		return "", "", err
+	}
+	thumb, err := encodeImage(scaleDown(src, thumbSide), ext)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Leave room in the slug for the hash
+	base := slug.Make(strings.TrimSuffix(path.Base(filename), path.Ext(filename)))
+	if len(base) > 40 {
+		base = strings.TrimRight(base[:40], "-")
+	}
+	sum := sha256.Sum256(full)
+	name := base + "-" + hex.EncodeToString(sum[:5]) + ext
+
+	if err := os.MkdirAll(filepath.Join(s.imageDir, "thumbs"), 0o755); err != nil {
+		return "", "", err
+	}
+	if err := writeFileAtomic(filepath.Join(s.imageDir, "thumbs", name), thumb); err != nil {
+		return "", "", err
+	}
+	if err := writeFileAtomic(filepath.Join(s.imageDir, name), full); err != nil {
+		return "", "", err
+	}
+	return ImageURL(name), "", nil
+}
+
+// encodeImage encodes img in the format ext names
+func encodeImage(img image.Image, ext string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if ext == ".jpg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	return buf.Bytes(), err
+}
+
+// writeFileAtomic writes data to name through a temporary file, so the
+// file is never served half written
+func writeFileAtomic(name string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// scaleDown shrinks img to fit within side pixels, averaging the source
+// pixels each output pixel covers. Images that already fit are returned as
+// they are.
+func scaleDown(img image.Image, side int) image.Image {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw <= side && sh <= side {
+		return img
+	}
+	dw, dh := side, sh*side/sw
+	if sh > sw {
+		dw, dh = sw*side/sh, side
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+
+	src := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			o := y*dst.Stride + x*4
+			for i := range sum {
+				dst.Pix[o+i] = uint8(sum[i] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// releaseImage deletes an uploaded image, and its thumbnail, once no option
+// shows it. Options seeded from another share its image.
+func (s *Server) releaseImage(ctx context.Context, u string) {
+	name, ok := strings.CutPrefix(u, "/images/")
+	if !ok || s.imageDir == "" || !validImageName(name) {
+		return
+	}
+	if n, err := s.queries.CountOptionsByImage(ctx, u); err != nil || n > 0 {
+		return
+	}
+	for _, file := range []string{filepath.Join(s.imageDir, name), filepath.Join(s.imageDir, "thumbs", name)} {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Failed to delete image %s: %v", file, err)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package web
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// maxImportBytes bounds an uploaded poll sheet
+const maxImportBytes = 1 << 20
+
+// ImportedPoll is a poll read from an import sheet, with its options in
+// sheet order. Line is where the poll first appears, for error messages.
+type ImportedPoll struct {
+	Settings CategorySettings
+	Options  []string
+	Line     int
+}
+
+// ImportPageData renders admin/import.html. With a sheet but no Errors it
+// is the preview, and CSV is posted back to confirm the import.
+type ImportPageData struct {
+	Page
+	CSV     string
+	Polls   []ImportedPoll
+	Options int
+	Errors  []string
+}
+
+// importColumns are the columns an import sheet may have. Only category is
+// required; a sheet without an option column creates empty polls.
+var importColumns = []string{"category", "type", "option", "show_results", "max_rank", "color", "icon"}
+
+// parseImport reads a sheet of polls and options, one option per row. Rows
+// naming the same category add to one poll, whose settings come from the
+// first row that gives them. Every problem is reported, not just the first,
+// so a sheet can be fixed in one go.
+func parseImport(r io.Reader, existing []db.Category) ([]ImportedPoll, []string) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, []string{"The sheet is empty"}
+	}
+	if err != nil {
+		return nil, []string{"The sheet isn't valid CSV: " + err.Error()}
+	}
+
+	col := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "poll" {
+			name = "category"
+		}
+		if !slices.Contains(importColumns, name) {
+			return nil, []string{fmt.Sprintf("Unknown column %q; use %s", name, strings.Join(importColumns, ", "))}
+		}
+		col[name] = i
+	}
+	if _, ok := col["category"]; !ok {
+		return nil, []string{"The first row must name the columns, including category"}
+	}
+
+	var polls []ImportedPoll
+	var errs []string
+	index := make(map[string]int)
+	for _, c := range existing {
+		index[strings.ToLower(c.Name)] = -1
+	}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := cr.FieldPos(0)
+		if err != nil {
+			errs = append(errs, "The sheet isn't valid CSV: "+err.Error())
+			break
+		}
+		field := func(name string) string {
+			if i, ok := col[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		name := field("category")
+		if name == "" {
+			if strings.TrimSpace(strings.Join(record, "")) != "" {
+				errs = append(errs, fmt.Sprintf("Line %d: category is empty", line))
+			}
+			continue
+		}
+		i, seen := index[strings.ToLower(name)]
+		if i < 0 {
+			errs = append(errs, fmt.Sprintf("Line %d: a poll named %q already exists", line, name))
+			continue
+		}
+		if !seen {
+			settings := CategorySettings{Name: name, VoteType: "single", ShowResults: "after_close"}
+			if v := field("type"); v != "" {
+				settings.VoteType = strings.ToLower(v)
+			}
+			if v := field("show_results"); v != "" {
+				settings.ShowResults = strings.ToLower(v)
+			}
+			if v := field("max_rank"); v != "" {
+				n, err := strconv.ParseInt(v, 10, 64)
+				if err != nil || n <= 0 {
+					errs = append(errs, fmt.Sprintf("Line %d: max_rank must be a positive whole number", line))
+				}
+				settings.MaxRank = n
+			}
+			settings.Color = strings.ToLower(field("color"))
+			settings.Icon = field("icon")
+			if err := settings.Normalize(); err != nil {
+				errs = append(errs, fmt.Sprintf("Line %d: %s", line, err))
+			}
+			i = len(polls)
+			index[strings.ToLower(name)] = i
+			polls = append(polls, ImportedPoll{Settings: settings, Line: line})
+		} else if v := strings.ToLower(field("type")); v != "" && v != polls[i].Settings.VoteType {
+			errs = append(errs, fmt.Sprintf("Line %d: %s was already given type %s on line %d", line, name, polls[i].Settings.VoteType, polls[i].Line))
+		}
+
+		p := &polls[i]
+		if option := field("option"); option != "" {
+			if slices.ContainsFunc(p.Options, func(o string) bool { return strings.EqualFold(o, option) }) {
+				errs = append(errs, fmt.Sprintf("Line %d: %s lists %q twice", line, name, option))
+				continue
+			}
+			p.Options = append(p.Options, option)
+		}
+	}
+	if len(polls) == 0 && len(errs) == 0 {
+		errs = append(errs, "The sheet has no polls")
+	}
+	return polls, errs
+}
+
+// handleAdminImport creates polls and their options from an uploaded CSV
+// sheet. The upload shows a preview; confirming posts the sheet back and
+// creates everything in one transaction, so a failure leaves nothing half
+// imported.
+func (s *Server) handleAdminImport(w http.ResponseWriter, r *http.Request) {
+	data := ImportPageData{Page: Page{Title: "Import polls"}}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		s.render(w, "admin/import.html", data)
+		return
+	case http.MethodPost:
+	default:
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead, http.MethodPost)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes+64<<10)
+	if file, _, err := r.FormFile("sheet"); err == nil {
+		sheet, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			data.Errors = []string{"Sheets are limited to 1 MB"}
+		}
+		data.CSV = string(sheet)
+	} else if errors.As(err, new(*http.MaxBytesError)) {
+		data.Errors = []string{"Sheets are limited to 1 MB"}
+	} else {
+		data.CSV = r.FormValue("csv")
+	}
+	if data.Errors == nil && strings.TrimSpace(data.CSV) == "" {
+		data.Errors = []string{"Choose a CSV file to import"}
+	}
+	if data.Errors != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		s.render(w, "admin/import.html", data)
+		return
+	}
+
+	existing, err := s.queries.ListCategoriesExcludeArchived(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to list polls", err)
+		return
+	}
+	data.Polls, data.Errors = parseImport(strings.NewReader(data.CSV), existing)
+	for _, p := range data.Polls {
+		data.Options += len(p.Options)
+	}
+	if data.Errors != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		s.render(w, "admin/import.html", data)
+		return
+	}
+	if r.FormValue("confirm") == "" {
+		s.render(w, "admin/import.html", data)
+		return
+	}
+
+	created, err := s.importPolls(r, data.Polls)
+	if err != nil {
+		s.renderError(w, "Failed to import polls", err)
+		return
+	}
+	for i, cat := range created {
+		s.audit(r, db.AuditCategoryCreate, cat.ID, cat.Name+" (imported)")
+		for _, name := range data.Polls[i].Options {
+			s.audit(r, db.AuditOptionAdd, cat.ID, name)
+		}
+	}
+	http.Redirect(w, r, AdminURL(), http.StatusSeeOther)
+}
+
+// importPolls creates imported polls, as drafts, and their options in one
+// transaction
+func (s *Server) importPolls(r *http.Request, polls []ImportedPoll) ([]db.Category, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	qtx := s.queries.WithTx(tx)
+
+	created := make([]db.Category, 0, len(polls))
+	for _, p := range polls {
+		settings := p.Settings
+		if err := settings.AssignSlug(r.Context(), qtx, 0); err != nil {
+			return nil, err
+		}
+		cat, err := qtx.CreateCategory(r.Context(), settings.CreateParams())
+		if err != nil {
+			return nil, err
+		}
+		for i, name := range p.Options {
+			_, err := qtx.CreateOption(r.Context(), db.CreateOptionParams{
+				CategoryID: cat.ID,
+				Name:       name,
+				SortOrder:  sql.NullInt64{Int64: int64(i), Valid: true},
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		created = append(created, cat)
+	}
+	return created, tx.Commit()
+}
@@ -0,0 +1,126 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// handleAdminBallots lists a poll's ballots with when and where each was
+// cast (/admin/category/{id}/ballots), and invalidates the ones cast
+// outside the official window or from banned addresses or nicknames.
+// Invalid ballots are kept but not counted; posting restore={ballot}
+// counts one again, or counts one held at the window's end as it stands
+// now.
+func (s *Server) handleAdminBallots(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		return
+	}
+	cat, err := s.queries.Category(r.Context(), id)
+	if err != nil {
+		s.lookupFailed(w, r, "category", err)
+		return
+	}
+	data := BallotsPageData{Page: Page{Title: cat.Name + " ballots"}, Category: cat}
+
+	if r.Method == http.MethodPost {
+		if restore := r.FormValue("restore"); restore != "" {
+			voteID, err := strconv.ParseInt(restore, 10, 64)
+			if err == nil {
+				err = db.RestoreBallot(r.Context(), s.db, cat.ID, voteID, db.ActorAdmin)
+			}
+			if errors.Is(err, db.ErrConflict) {
+				s.renderActionError(w, r, "Cannot restore the ballot: "+err.Error(), err)
+				return
+			}
+			if err != nil {
+				s.renderActionError(w, r, "Failed to restore the ballot", err)
+				return
+			}
+			http.Redirect(w, r, AdminCategoryBallotsURL(cat.ID), http.StatusSeeOther)
+			return
+		}
+
+		data.From = strings.TrimSpace(r.FormValue("from"))
+		data.To = strings.TrimSpace(r.FormValue("to"))
+		data.Addresses = strings.TrimSpace(r.FormValue("addresses"))
+		data.Nicknames = strings.TrimSpace(r.FormValue("nicknames"))
+		data.Reason = strings.TrimSpace(r.FormValue("reason"))
+		filter, formError := s.ballotFilter(data)
+		if formError == "" {
+			_, _, err := db.InvalidateBallots(r.Context(), s.db, cat.ID, filter, data.Reason, db.ActorAdmin)
+			switch {
+			case errors.Is(err, db.ErrConflict):
+				formError = err.Error()
+			case err != nil:
+				s.renderActionError(w, r, "Failed to invalidate ballots", err)
+				return
+			default:
+				http.Redirect(w, r, AdminCategoryBallotsURL(cat.ID), http.StatusSeeOther)
+				return
+			}
+		}
+		data.Error = formError
+	}
+
+	if data.Purged, err = s.queries.Purged(r.Context(), cat.ID); err != nil {
+		s.renderError(w, "Failed to load the poll", err)
+		return
+	}
+	ballots, err := s.queries.ListVoteOrigins(r.Context(), cat.ID)
+	if err != nil {
+		s.renderError(w, "Failed to load ballots", err)
+		return
+	}
+	for _, b := range ballots {
+		data.Ballots = append(data.Ballots, BallotRow{ListVoteOriginsRow: b, Voter: s.nicknames.Reveal(b.Nickname)})
+		if b.Invalid != "" {
+			data.Invalid++
+		}
+	}
+
+	if data.Error != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	s.render(w, "admin/ballots.html", data)
+}
+
+// ballotFilter reads the invalidate form, returning what's wrong with it
+// if anything
+func (s *Server) ballotFilter(data BallotsPageData) (db.BallotFilter, string) {
+	var filter db.BallotFilter
+	var err error
+	if data.Reason == "" {
+		return filter, "Say why the ballots are invalid"
+	}
+	if data.From != "" {
+		if filter.From, err = parseLocalTime(data.From, time.Local); err != nil {
+			return filter, "Enter the window's start like 2026-10-17 18:00"
+		}
+	}
+	if data.To != "" {
+		if filter.To, err = parseLocalTime(data.To, time.Local); err != nil {
+			return filter, "Enter the window's end like 2026-10-17 22:00"
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, "The window must start before it ends"
+	}
+	if filter.Addresses, err = db.ParseAddresses(data.Addresses); err != nil {
+		return filter, err.Error()
+	}
+	for _, n := range strings.FieldsFunc(data.Nicknames, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if n = normalizeNickname(n); n != "" {
+			filter.Nicknames = append(filter.Nicknames, s.nicknames.Seal(n))
+		}
+	}
+	if filter.Empty() {
+		return filter, "Give the official window, addresses or nicknames whose ballots are invalid"
+	}
+	return filter, ""
+}
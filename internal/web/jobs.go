@@ -0,0 +1,225 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// Jobs configures the maintenance the server runs in the background while
+// it is up. A zero interval turns a job off.
+type Jobs struct {
+	CleanupEvery time.Duration // purge expired sessions and idempotency keys
+	ArchiveEvery time.Duration // look for closed polls to archive
+	ArchiveAfter time.Duration // how long a poll stays closed before it is archived
+	PurgeEvery   time.Duration // look for finished polls past the ballot_retention_days setting
+	VacuumEvery  time.Duration // compact the database
+	BackupEvery  time.Duration // snapshot the database into BackupDir
+	BackupDir    string
+	BackupKeep   int // snapshots to keep; older ones are deleted
+}
+
+// DefaultJobs keeps the database tidy over a weekend-long event. Backups
+// are off until a directory is configured.
+var DefaultJobs = Jobs{
+	CleanupEvery: time.Hour,
+	ArchiveEvery: 10 * time.Minute,
+	ArchiveAfter: 24 * time.Hour,
+	PurgeEvery:   time.Hour,
+	VacuumEvery:  24 * time.Hour,
+	BackupEvery:  time.Hour,
+	BackupKeep:   24,
+}
+
+// WithJobs replaces DefaultJobs
+func WithJobs(j Jobs) Option {
+	return func(s *Server) {
+		s.jobs = j
+	}
+}
+
+// Background job names, for RunJob
+const (
+	JobCleanup = "cleanup"
+	JobArchive = "archive"
+	JobPurge   = "purge"
+	JobVacuum  = "vacuum"
+	JobBackup  = "backup"
+)
+
+// backupTimeFormat names snapshots so they sort oldest first
+const backupTimeFormat = "20060102-150405"
+
+type job struct {
+	name  string
+	every time.Duration
+	run   func(ctx context.Context, now time.Time) error
+}
+
+// allJobs returns every background job with its configured interval
+func (s *Server) allJobs() []job {
+	return []job{
+		{JobCleanup, s.jobs.CleanupEvery, s.cleanupExpired},
+		{JobArchive, s.jobs.ArchiveEvery, s.archiveClosed},
+		{JobPurge, s.jobs.PurgeEvery, s.purgeExpired},
+		{JobVacuum, s.jobs.VacuumEvery, s.vacuum},
+		{JobBackup, s.jobs.BackupEvery, s.backup},
+	}
+}
+
+// runJobs runs each enabled job at startup and then on its interval until
+// ctx is cancelled. A failing run is logged and retried next time round.
+func (s *Server) runJobs(ctx context.Context) {
+	for _, j := range s.allJobs() {
+		switch {
+		case j.every <= 0:
+			continue
+		case j.name == JobArchive && s.jobs.ArchiveAfter <= 0:
+			continue
+		case j.name == JobBackup && s.jobs.BackupDir == "":
+			continue
+		}
+
+		go func() {
+			ticker := time.NewTicker(j.every)
+			defer ticker.Stop()
+
+			for {
+				if err := j.run(ctx, time.Now().UTC()); err != nil && ctx.Err() == nil {
+					log.Printf("Job %s failed: %v", j.name, err)
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+}
+
+// RunJob runs the named background job once, now, whether or not it is
+// scheduled
+func (s *Server) RunJob(ctx context.Context, name string) error {
+	for _, j := range s.allJobs() {
+		if j.name == name {
+			return j.run(ctx, time.Now().UTC())
+		}
+	}
+	return fmt.Errorf("unknown job %q", name)
+}
+
+// cleanupExpired deletes expired sessions and idempotency keys
+func (s *Server) cleanupExpired(ctx context.Context, now time.Time) error {
+	sessions, err := s.queries.DeleteSessionsExpiredBefore(ctx, now)
+	if err != nil {
+		return fmt.Errorf("delete expired sessions: %w", err)
+	}
+	cutoff := sql.NullTime{Time: now.Add(-idempotencyKeyTTL), Valid: true}
+	keys, err := s.queries.DeleteIdempotencyKeysBefore(ctx, cutoff)
+	if err != nil {
+		return fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	if sessions+keys > 0 {
+		log.Printf("Purged %d expired sessions and %d idempotency keys", sessions, keys)
+	}
+	return nil
+}
+
+// archiveClosed archives polls that have been closed for ArchiveAfter, so
+// the dashboard only lists what is still in play
+func (s *Server) archiveClosed(ctx context.Context, now time.Time) error {
+	after := s.jobs.ArchiveAfter
+	if after <= 0 {
+		after = DefaultJobs.ArchiveAfter
+	}
+	polls, err := s.queries.ListCategoriesClosedBefore(ctx, sql.NullTime{Time: now.Add(-after), Valid: true})
+	if err != nil {
+		return fmt.Errorf("list closed polls: %w", err)
+	}
+	for _, cat := range polls {
+		if err := s.queries.ArchiveCategory(ctx, cat.ID); err != nil {
+			return fmt.Errorf("archive %s: %w", cat.Name, err)
+		}
+		detail := "closed for over " + after.String()
+		if err := s.queries.RecordAudit(ctx, db.ActorServer, db.AuditCategoryArchive, cat.ID, detail); err != nil {
+			log.Printf("Failed to record audit event %s: %v", db.AuditCategoryArchive, err)
+		}
+		log.Printf("Archived %s, %s", cat.Name, detail)
+	}
+	return nil
+}
+
+// purgeExpired deletes the ballots of polls finished longer ago than the
+// ballot_retention_days setting, keeping their results. It does nothing
+// while the setting is 0.
+func (s *Server) purgeExpired(ctx context.Context, now time.Time) error {
+	retention, err := s.queries.BallotRetention(ctx)
+	if err != nil || retention == 0 {
+		return err
+	}
+	purged, err := db.PurgeExpiredBallots(ctx, s.db, db.ActorServer, retention, now)
+	for _, p := range purged {
+		log.Printf("Purged %d ballot(s) from %s", p.Ballots, p.Category.Name)
+	}
+	return err
+}
+
+// vacuum compacts the database. It waits for a quiet moment: VACUUM locks
+// the whole database, so it is skipped while any poll is open.
+func (s *Server) vacuum(ctx context.Context, now time.Time) error {
+	polls, err := s.queries.ListOpenCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("list open polls: %w", err)
+	}
+	if len(polls) > 0 {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("vacuum: %w", err)
+	}
+	return nil
+}
+
+// backup snapshots the database into BackupDir with VACUUM INTO, which is
+// safe while the server is writing, then deletes all but the newest
+// BackupKeep snapshots
+func (s *Server) backup(ctx context.Context, now time.Time) error {
+	dir := s.jobs.BackupDir
+	if dir == "" {
+		return fmt.Errorf("no backup directory configured")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, "votigo-"+now.Format(backupTimeFormat)+".db")
+	if _, err := s.db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("back up to %s: %w", path, err)
+	}
+
+	snapshots, err := filepath.Glob(filepath.Join(dir, "votigo-*.db"))
+	if err != nil {
+		return err
+	}
+	keep := max(s.jobs.BackupKeep, 1)
+	if len(snapshots) <= keep {
+		return nil
+	}
+	slices.SortFunc(snapshots, strings.Compare)
+	for _, old := range snapshots[:len(snapshots)-keep] {
+		if err := os.Remove(old); err != nil {
+			return fmt.Errorf("remove old backup: %w", err)
+		}
+	}
+	return nil
+}
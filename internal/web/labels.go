@@ -0,0 +1,52 @@
+package web
+
+import (
+	"html/template"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxIconRunes bounds category icons; enough for an emoji with modifiers
+const maxIconRunes = 8
+
+// CategoryColor is a named label color admins can assign to a category
+type CategoryColor struct {
+	Name string
+	Hex  string
+}
+
+// CategoryColors lists the label palette in display order. Colors are stored
+// by name so the palette can be retuned without touching the database.
+var CategoryColors = []CategoryColor{
+	{Name: "green", Hex: "#22c55e"},
+	{Name: "amber", Hex: "#f59e0b"},
+	{Name: "red", Hex: "#ef4444"},
+	{Name: "blue", Hex: "#3b82f6"},
+	{Name: "purple", Hex: "#a855f7"},
+	{Name: "pink", Hex: "#ec4899"},
+	{Name: "cyan", Hex: "#06b6d4"},
+}
+
+// ValidCategoryColor reports whether name is empty (no label) or in the palette
+func ValidCategoryColor(name string) bool {
+	return name == "" || colorHex(name) != ""
+}
+
+// NormalizeCategoryIcon trims an icon and truncates it to maxIconRunes
+func NormalizeCategoryIcon(icon string) string {
+	icon = strings.TrimSpace(icon)
+	if utf8.RuneCountInString(icon) <= maxIconRunes {
+		return icon
+	}
+	return string([]rune(icon)[:maxIconRunes])
+}
+
+// colorHex resolves a palette name to its hex value, or "" if unknown
+func colorHex(name string) template.CSS {
+	for _, c := range CategoryColors {
+		if c.Name == name {
+			return template.CSS(c.Hex)
+		}
+	}
+	return ""
+}
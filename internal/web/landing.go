@@ -0,0 +1,88 @@
+package web
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// maxLandingWinners caps how many recently closed polls the landing page
+// shows winners for
+const maxLandingWinners = 3
+
+// loadLanding gathers what the home page shows while no poll is open: the
+// draft polls still to come, soonest planned opening first, and the winners
+// of the polls closed most recently.
+func (s *Server) loadLanding(ctx context.Context, now time.Time) (*LandingData, error) {
+	polls, err := s.queries.ListCategoriesExcludeArchived(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]db.Category, len(polls))
+	for _, c := range polls {
+		byID[c.ID] = c
+	}
+
+	landing := &LandingData{}
+	for _, c := range polls {
+		if c.Status != "draft" {
+			continue
+		}
+		item := ScheduledPoll{Category: c}
+		if after, ok := byID[c.DependsOn.Int64]; ok && c.DependsOn.Valid {
+			item.OpensAfter = &after
+		}
+		landing.Schedule = append(landing.Schedule, item)
+	}
+
+	// Polls with a planned time first, in order, then the rest as created
+	slices.SortStableFunc(landing.Schedule, func(a, b ScheduledPoll) int {
+		at, bt := a.Category.OpensAt, b.Category.OpensAt
+		switch {
+		case at.Valid && bt.Valid:
+			return at.Time.Compare(bt.Time)
+		case at.Valid:
+			return -1
+		case bt.Valid:
+			return 1
+		}
+		return cmp.Compare(a.Category.ID, b.Category.ID)
+	})
+	for i, item := range landing.Schedule {
+		if item.Category.OpensAt.Valid && item.Category.OpensAt.Time.After(now) {
+			landing.Next = &landing.Schedule[i]
+			break
+		}
+	}
+
+	closed, err := s.queries.ListRecentlyClosedCategories(ctx, maxLandingWinners)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range closed {
+		_, results, err := s.tallyResults(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if names := leaders(results); len(names) > 0 {
+			landing.Winners = append(landing.Winners, PollWinner{Category: c, Names: names})
+		}
+	}
+	return landing, nil
+}
+
+// leaders returns the names of the options sharing first place; none if
+// nobody voted
+func leaders(results []ResultRow) []string {
+	var names []string
+	for _, r := range results {
+		if r.Rank != 1 || (r.Votes == 0 && r.Points == 0) {
+			break
+		}
+		names = append(names, r.Name)
+	}
+	return names
+}
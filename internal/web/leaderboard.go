@@ -0,0 +1,165 @@
+package web
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// maxLeaderboardEntries bounds the public leaderboard; the admin export
+// lists everyone
+const maxLeaderboardEntries = 100
+
+// LeaderboardEntry is one voter's participation across the event. Streak is
+// the longest run of polls, in the order they were created, voted in one
+// after another.
+type LeaderboardEntry struct {
+	Rank     int
+	Nickname string
+	Avatar   string
+	Polls    int
+	Streak   int
+	LastVote time.Time
+}
+
+// LeaderboardPageData renders leaderboard.html. TotalPolls counts every poll
+// that has opened, so Polls can be read as "n of TotalPolls".
+type LeaderboardPageData struct {
+	Page
+	Entries    []LeaderboardEntry
+	TotalPolls int
+}
+
+// loadLeaderboard ranks voters by how many polls they voted in, then by
+// their longest streak. Voters level on both share a rank and are listed by
+// who got there first.
+func (s *Server) loadLeaderboard(ctx context.Context) ([]LeaderboardEntry, int, error) {
+	polls, err := s.queries.ListStartedCategoryIDs(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	position := make(map[int64]int, len(polls))
+	for i, id := range polls {
+		position[id] = i
+	}
+	rows, err := s.queries.ListParticipation(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Rows come grouped by voter, in poll order
+	var entries []LeaderboardEntry
+	var last, run int
+	for i, row := range rows {
+		if i == 0 || row.Nickname != rows[i-1].Nickname {
+			nickname := s.nicknames.Reveal(row.Nickname)
+			entries = append(entries, LeaderboardEntry{Nickname: nickname, Avatar: s.avatarURL(nickname)})
+			run = 0
+		}
+		e := &entries[len(entries)-1]
+		pos := position[row.CategoryID]
+		if run > 0 && pos == last+1 {
+			run++
+		} else {
+			run = 1
+		}
+		last = pos
+		e.Polls++
+		e.Streak = max(e.Streak, run)
+		if row.CreatedAt.Valid && row.CreatedAt.Time.After(e.LastVote) {
+			e.LastVote = row.CreatedAt.Time
+		}
+	}
+
+	slices.SortStableFunc(entries, func(a, b LeaderboardEntry) int {
+		return cmp.Or(
+			cmp.Compare(b.Polls, a.Polls),
+			cmp.Compare(b.Streak, a.Streak),
+			a.LastVote.Compare(b.LastVote),
+		)
+	})
+	for i := range entries {
+		if i > 0 && entries[i].Polls == entries[i-1].Polls && entries[i].Streak == entries[i-1].Streak {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+	return entries, len(polls), nil
+}
+
+// handleLeaderboard shows the voters who took part in the most polls, if
+// the leaderboard setting publishes it
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+	if !s.settingBool(db.SettingLeaderboard) && !s.authorized(r) {
+		s.notFound(w, r)
+		return
+	}
+
+	entries, total, err := s.loadLeaderboard(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load leaderboard", err)
+		return
+	}
+	if len(entries) > maxLeaderboardEntries {
+		entries = entries[:maxLeaderboardEntries]
+	}
+	s.render(w, "leaderboard.html", LeaderboardPageData{
+		Page:       Page{Title: "Leaderboard"},
+		Entries:    entries,
+		TotalPolls: total,
+	})
+}
+
+// handleAdminLeaderboardExport downloads the whole leaderboard as CSV, for
+// handing out participation prizes
+func (s *Server) handleAdminLeaderboardExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+	entries, total, err := s.loadLeaderboard(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load leaderboard", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="leaderboard.csv"`)
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"rank", "nickname", "polls", "of", "longest_streak", "last_vote"})
+	for _, e := range entries {
+		lastVote := ""
+		if !e.LastVote.IsZero() {
+			lastVote = e.LastVote.UTC().Format(time.RFC3339)
+		}
+		cw.Write([]string{
+			strconv.Itoa(e.Rank), csvSafe(e.Nickname), strconv.Itoa(e.Polls),
+			strconv.Itoa(total), strconv.Itoa(e.Streak), lastVote,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Printf("Failed to write leaderboard export: %v", err)
+	}
+}
+
+// csvSafe keeps a nickname from being read as a formula when the export is
+// opened in a spreadsheet
+func csvSafe(s string) string {
+	if s != "" && (s[0] == '=' || s[0] == '+' || s[0] == '-' || s[0] == '@') {
+		return "'" + s
+	}
+	return s
+}
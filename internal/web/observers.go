@@ -0,0 +1,143 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// Observer links last defaultObserverHours unless the admin picks another
+// time, up to maxObserverHours
+const (
+	defaultObserverHours = 48
+	maxObserverHours     = 30 * 24
+)
+
+// handleObserve shows an observer link's holder every poll's standings and
+// turnout, including results voters can't see yet, and nothing they can
+// change. The token in the path is the only credential, so the page asks
+// not to be indexed or passed on as a referrer. HTMX requests get the polls
+// alone, for the page to refresh itself.
+func (s *Server) handleObserve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	token := strings.TrimPrefix(r.URL.Path, "/observe/")
+	observer, err := s.queries.ObserverByToken(r.Context(), token, time.Now())
+	if errors.Is(err, db.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		s.render(w, "error.html", map[string]any{
+			"Message": "This observer link has expired or was withdrawn. Ask an organizer for a new one.",
+		})
+		return
+	}
+	if err != nil {
+		s.renderError(w, "Failed to check this observer link", err)
+		return
+	}
+
+	categories, err := s.queries.ListCategoriesExcludeArchived(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load polls", err)
+		return
+	}
+	data := ObservePageData{
+		Page:     Page{Title: "Observer"},
+		Observer: observer,
+		URL:      ObserveURL(token),
+	}
+	for _, cat := range categories {
+		if cat.Status == "draft" {
+			continue
+		}
+		poll := ObservedPoll{Category: cat}
+		if poll.Votes, err = s.reads.CountVotesByCategory(r.Context(), cat.ID); err != nil {
+			s.renderError(w, "Failed to count ballots", err)
+			return
+		}
+		tallied, err := s.reads.Tally(r.Context(), cat)
+		if err != nil {
+			s.renderError(w, "Failed to tally results", err)
+			return
+		}
+		for i, place := range db.Places(tallied, poll.Votes) {
+			poll.Results = append(poll.Results, PlacedOption{tallied[i], place})
+		}
+		if poll.Groups, err = s.reads.GroupResults(r.Context(), cat, false); err != nil {
+			s.renderError(w, "Failed to break down turnout", err)
+			return
+		}
+		data.Polls = append(data.Polls, poll)
+	}
+
+	if s.isHTMX(r) {
+		s.renderPartial(w, "partials/observe.html", data)
+		return
+	}
+	s.render(w, "observe.html", data)
+}
+
+// handleAdminObservers makes observer links for sponsors and judges who
+// should see results and turnout without the admin password. A new link is
+// shown straight away with its QR code; it isn't stored, so it can't be
+// listed later, only withdrawn along with all the others.
+func (s *Server) handleAdminObservers(w http.ResponseWriter, r *http.Request) {
+	data := ObserversPageData{Page: Page{Title: "Observer links"}, Hours: defaultObserverHours}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		label := strings.TrimSpace(r.FormValue("label"))
+		hours, err := strconv.Atoi(r.FormValue("hours"))
+		switch {
+		case label == "":
+			data.Error = "Please say who the link is for"
+		case len(label) > db.MaxObserverLabel:
+			data.Error = "Please keep labels under " + strconv.Itoa(db.MaxObserverLabel) + " characters"
+		case err != nil || hours < 1 || hours > maxObserverHours:
+			data.Error = "Links can last from 1 hour to " + strconv.Itoa(maxObserverHours/24) + " days"
+		default:
+			o := db.Observer{Label: label, Expires: time.Now().Add(time.Duration(hours) * time.Hour).Truncate(time.Second)}
+			token, err := s.queries.SignObserver(r.Context(), o)
+			if err != nil {
+				s.renderError(w, "Failed to make observer link", err)
+				return
+			}
+			s.audit(r, db.AuditObserverLink, 0, o.Label+" until "+o.Expires.Format(time.DateTime))
+			link := absoluteURL(r, ObserveURL(token))
+			data.Link = &ObserverLink{Observer: o, URL: link, QR: qrDataURL(link)}
+		}
+		if data.Error != "" {
+			data.Label = label
+			data.Hours = hours
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	default:
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		return
+	}
+	s.render(w, "admin/observers.html", data)
+}
+
+// handleAdminObserversRevoke withdraws every observer link made so far
+func (s *Server) handleAdminObserversRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	if err := s.queries.RevokeObservers(r.Context()); err != nil {
+		s.renderActionError(w, r, "Failed to revoke observer links", err)
+		return
+	}
+	s.audit(r, db.AuditObserverRevoke, 0, "all links")
+	http.Redirect(w, r, AdminObserversURL(), http.StatusSeeOther)
+}
@@ -0,0 +1,56 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// handleAdminPreview renders a poll's ballot as voters will see it, but
+// read-only, so its layout can be checked before opening. HTMX requests
+// get the form alone for the admin page's preview panel; others get the
+// whole vote page. Unsaved settings sent along from the admin form (any
+// request with vote_type) are previewed in place of the saved ones, as long
+// as they would save.
+func (s *Server) handleAdminPreview(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	cat, err := s.queries.Category(r.Context(), id)
+	if err != nil {
+		s.lookupFailed(w, r, "category", err)
+		return
+	}
+	if r.URL.Query().Has("vote_type") {
+		if settings := categorySettingsFromForm(r); settings.Normalize() == nil {
+			cat = settings.preview(cat)
+		}
+	}
+
+	options, err := s.queries.ListBallotOptionsByCategory(r.Context(), cat.ID)
+	if err != nil {
+		s.renderActionError(w, r, "Failed to load options", err)
+		return
+	}
+	data := newVotePage(cat, options, false)
+	data.Preview = true
+	if s.isHTMX(r) {
+		s.renderPartial(w, "partials/vote-form.html", data)
+		return
+	}
+	data.Viewer = s.viewer(r, &cat)
+	s.render(w, "vote.html", data)
+}
+
+// preview returns cat with the settings that change how its ballot looks
+func (c CategorySettings) preview(cat db.Category) db.Category {
+	cat.Name = c.Name
+	cat.VoteType = c.VoteType
+	cat.MaxRank = c.maxRank()
+	cat.Color = c.Color
+	cat.Icon = c.Icon
+	cat.Skin = c.Skin
+	cat.CustomCss = c.CustomCSS
+	return cat
+}
@@ -0,0 +1,45 @@
+package web
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+)
+
+// quickOrder puts the polls an organizer is most likely to act on first:
+// open ones to close, then drafts to open
+var quickOrder = map[string]int{"open": 0, "draft": 1, "closed": 2}
+
+// handleAdminQuick shows big open and close buttons and ballot counts for
+// every poll, laid out for a phone, for organizers walking the hall. HTMX
+// requests get the list alone, for the page to refresh itself.
+func (s *Server) handleAdminQuick(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	categories, err := s.queries.ListCategoriesExcludeArchived(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load polls", err)
+		return
+	}
+	stats, err := s.loadPollStats(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load vote counts", err)
+		return
+	}
+
+	data := QuickPageData{Page: Page{Title: "Quick controls"}}
+	for _, cat := range categories {
+		data.Polls = append(data.Polls, QuickPoll{Category: cat, Stats: stats[cat.ID]})
+	}
+	slices.SortStableFunc(data.Polls, func(a, b QuickPoll) int {
+		return cmp.Compare(quickOrder[a.Category.Status], quickOrder[b.Category.Status])
+	})
+
+	if s.isHTMX(r) {
+		s.renderPartial(w, "partials/quick.html", data)
+		return
+	}
+	s.render(w, "admin/quick.html", data)
+}
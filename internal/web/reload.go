@@ -0,0 +1,70 @@
+package web
+
+import (
+	"html/template"
+
+	"github.com/palm-arcade/votigo/internal/notify"
+)
+
+// WithTemplateDir parses templates from dir, laid out like the templates
+// package (modern/, legacy/), instead of those built in. Reload parses them
+// again, so template changes show without restarting: for development.
+func WithTemplateDir(dir string) Option {
+	return func(s *Server) {
+		s.templateDir = dir
+	}
+}
+
+// Reload picks up changes made while the server runs: settings are read
+// again on the next render, and templates from WithTemplateDir are parsed
+// again. A template that no longer parses is reported and the ones already
+// loaded are kept. Connected clients, event streams included, are not
+// disturbed.
+func (s *Server) Reload() error {
+	s.settings.mu.Lock()
+	s.settings.values = nil
+	s.settings.mu.Unlock()
+
+	if s.templateDir == "" {
+		return nil
+	}
+	tmpls, partials, err := s.parseTemplates()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.templates, s.partials = tmpls, partials
+	s.mu.Unlock()
+	return nil
+}
+
+// SetNotifier replaces the notifier set by WithNotifier; announcements
+// already on their way finish with the old one
+func (s *Server) SetNotifier(n notify.Notifier) {
+	s.mu.Lock()
+	s.notifier = n
+	s.mu.Unlock()
+}
+
+// page returns the page template called name
+func (s *Server) page(name string) (*template.Template, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.templates[name]
+	return t, ok
+}
+
+// partial returns the partial template called name
+func (s *Server) partial(name string) (*template.Template, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.partials[name]
+	return t, ok
+}
+
+// currentNotifier returns the notifier to announce with, nil for none
+func (s *Server) currentNotifier() notify.Notifier {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.notifier
+}
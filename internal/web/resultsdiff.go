@@ -0,0 +1,137 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// resultsHistoryLen is how many recent tallies of each poll are kept for
+// working out what changed since a results table last polled
+const resultsHistoryLen = 8
+
+// resultsHistory keeps the last few tallies of each poll by results version
+// (see db.Category.ResultsVersion), so a results table polling with
+// ?since= can be sent just the places that changed
+type resultsHistory struct {
+	mu    sync.Mutex
+	polls map[int64][]resultsSnapshot // oldest first
+}
+
+// resultsSnapshot is a poll's tally at one results version
+type resultsSnapshot struct {
+	version int64
+	rows    []ResultRow
+}
+
+// record remembers rows as the tally of poll id at version
+func (h *resultsHistory) record(id, version int64, rows []ResultRow) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.polls == nil {
+		h.polls = make(map[int64][]resultsSnapshot)
+	}
+	snaps := h.polls[id]
+	for _, snap := range snaps {
+		if snap.version == version {
+			return
+		}
+	}
+	if len(snaps) == resultsHistoryLen {
+		snaps = snaps[1:]
+	}
+	h.polls[id] = append(snaps, resultsSnapshot{version: version, rows: rows})
+}
+
+// at returns the tally of poll id at version, if it is still kept
+func (h *resultsHistory) at(id, version int64) ([]ResultRow, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, snap := range h.polls[id] {
+		if snap.version == version {
+			return snap.rows, true
+		}
+	}
+	return nil, false
+}
+
+// changedPlaces lists the places of d whose row differs from old, or
+// returns false when the number of rows changed and only a whole table will
+// do
+func changedPlaces(old []ResultRow, d ResultsPageData) ([]ResultPlace, bool) {
+	if len(old) != len(d.Results) {
+		return nil, false
+	}
+	var changed []ResultPlace
+	for _, place := range d.Places() {
+		if place.ResultRow != old[place.Place] {
+			place.Swap = true
+			changed = append(changed, place)
+		}
+	}
+	return changed, true
+}
+
+// versionedTally tallies cat and returns the results version the tally is
+// for, or -1 when a ballot landed while it was being counted and it can't
+// be pinned to one. Pinned tallies are kept for changedPlaces.
+func (s *Server) versionedTally(ctx context.Context, cat db.Category) (int64, []ResultRow, int64, error) {
+	total, rows, err := s.tallyResults(ctx, cat)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	after, err := s.reads.Category(ctx, cat.ID)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	if after.ResultsVersion != cat.ResultsVersion {
+		return total, rows, -1, nil
+	}
+	s.results.record(cat.ID, cat.ResultsVersion, rows)
+	return total, rows, cat.ResultsVersion, nil
+}
+
+// handleResultsTable serves the live results table. Without ?since= it is
+// the table itself, for pages from before polling by version. With it,
+// the answer is 204 No Content if the poll's results version hasn't moved,
+// and otherwise out-of-band swaps of only the places that changed (the
+// whole table when they can't be told apart), along with the poller for the
+// new version.
+func (s *Server) handleResultsTable(w http.ResponseWriter, r *http.Request, ref string) {
+	cat, err := s.queries.CategoryByRef(r.Context(), ref)
+	if err != nil {
+		s.lookupFailed(w, r, "poll", err)
+		return
+	}
+
+	polling := r.URL.Query().Has("since")
+	since, err := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+	known := polling && err == nil
+	if known && since == cat.ResultsVersion {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	voteCount, results, version, err := s.versionedTally(r.Context(), cat)
+	if err != nil {
+		http.Error(w, "Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := ResultsPageData{
+		Category:  cat,
+		VoteCount: voteCount,
+		Results:   results,
+		Version:   version,
+		Poll:      polling,
+	}
+	if known {
+		if old, ok := s.results.at(cat.ID, since); ok {
+			data.Changed, data.Partial = changedPlaces(old, data)
+		}
+	}
+	s.renderPartial(w, "partials/results-table.html", data)
+}
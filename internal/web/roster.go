@@ -0,0 +1,94 @@
+package web
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// handleAdminRoster lists the attendees on the roster with the tags that
+// polls' eligibility rules go by, for admins to edit
+func (s *Server) handleAdminRoster(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	attendees, err := s.queries.ListAttendees(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load the roster", err)
+		return
+	}
+	tags, err := s.queries.ListAttendeeTags(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load roster tags", err)
+		return
+	}
+
+	byAttendee := make(map[int64][]string)
+	counts := make(map[string]int)
+	for _, t := range tags {
+		byAttendee[t.AttendeeID] = append(byAttendee[t.AttendeeID], t.Tag)
+		counts[t.Tag]++
+	}
+	data := RosterPageData{Page: Page{Title: "Roster"}}
+	for _, a := range attendees {
+		data.Attendees = append(data.Attendees, RosterAttendee{
+			ID:       a.ID,
+			Nickname: s.nicknames.Reveal(a.Nickname),
+			Seat:     a.Seat,
+			Tags:     byAttendee[a.ID],
+		})
+	}
+	for tag, n := range counts {
+		data.Tags = append(data.Tags, TagCount{Tag: tag, Count: n})
+	}
+	slices.SortFunc(data.Tags, func(a, b TagCount) int { return cmp.Compare(a.Tag, b.Tag) })
+	s.render(w, "admin/roster.html", data)
+}
+
+// handleAdminRosterTags replaces an attendee's tags
+// (/admin/roster/{id}/tags) with the space or comma separated tags posted.
+// Ballots already cast stand; eligibility is checked as ballots arrive.
+func (s *Server) handleAdminRosterTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	idStr, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/admin/roster/"), "/tags")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if !ok || err != nil {
+		s.notFound(w, r)
+		return
+	}
+	tags, err := db.ParseTags(r.FormValue("tags"))
+	if err != nil {
+		s.actionError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	attendee, err := s.queries.GetAttendee(r.Context(), id)
+	if err != nil {
+		s.lookupFailed(w, r, "attendee", db.Classify(err))
+		return
+	}
+	err = db.InTx(r.Context(), s.db, func(q *db.Queries) error {
+		return q.SetAttendeeTags(r.Context(), id, tags)
+	})
+	if err != nil {
+		s.renderActionError(w, r, "Failed to save tags", err)
+		return
+	}
+	// The nickname is left out of the audit detail, as for forgotten voters
+	s.audit(r, db.AuditRosterTags, 0, fmt.Sprintf("attendee %d: %s", id, strings.Join(tags, " ")))
+
+	if s.isHTMX(r) {
+		showToast(w, toastSuccess, "Saved tags for "+s.nicknames.Reveal(attendee.Nickname))
+		return
+	}
+	http.Redirect(w, r, AdminRosterURL(), http.StatusSeeOther)
+}
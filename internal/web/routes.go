@@ -6,10 +6,24 @@ import "fmt"
 // Route pattern constants
 const (
 	PathHome        = "/"
-	PathVote        = "/vote/%d"
-	PathResults     = "/results/%d"
+	PathVote        = "/vote/%v"
+	PathVoteWidget  = "/vote/%v/widget"
+	PathOptionDetails = "/vote/%v/option/%d"
+	PathResults     = "/results/%v"
 	PathResultsList = "/results"
-	PathResultsTable = "/results/%d/table"
+	PathResultsTable = "/results/%v/table"
+	PathResultsCard = "/results/%v/card.png"
+	PathShortLink   = "/c/%s"
+	PathEvents      = "/events"
+	PathDisplay     = "/display"
+	PathSound       = "/sounds/%s"
+	PathImage       = "/images/%s"
+	PathImageThumb  = "/images/thumbs/%s"
+	PathAvatar      = "/avatars/%s.png"
+	PathLeaderboard = "/leaderboard"
+	PathSeason      = "/season"
+	PathPair        = "/pair/%s"
+	PathObserve     = "/observe/%s"
 
 	PathAdmin            = "/admin"
 	PathAdminCategory    = "/admin/category/%d"
@@ -17,30 +31,143 @@ const (
 	PathAdminCategoryOpen = "/admin/category/%d/open"
 	PathAdminCategoryClose = "/admin/category/%d/close"
 	PathAdminCategoryArchive = "/admin/category/%d/archive"
+	PathAdminCategorySeed = "/admin/category/%d/seed"
+	PathAdminCategoryRunoff = "/admin/category/%d/runoff"
+	PathAdminCategoryTest = "/admin/category/%d/test"
+	PathAdminCategoryPreview = "/admin/category/%d/preview"
+	PathAdminCategoryGroups = "/admin/category/%d/groups"
+	PathAdminCategoryWindow = "/admin/category/%d/window"
+	PathAdminCategoryBallots = "/admin/category/%d/ballots"
 	PathAdminAddOption   = "/admin/category/%d/option/add"
 	PathAdminRemoveOption = "/admin/category/%d/option/%d/remove"
 	PathAdminOption      = "/admin/option/%d"
+	PathAdminRetireOption = "/admin/option/%d/retire"
+	PathAdminOptionImage = "/admin/option/%d/image"
+	PathAdminOptionDetails = "/admin/option/%d/details"
+	PathAdminActivity    = "/admin/activity"
+	PathAdminHighContrast = "/admin/high-contrast"
+	PathAdminForgetVoter = "/admin/voters/forget"
+	PathAdminSettings    = "/admin/settings"
+	PathAdminSearch      = "/admin/search"
+	PathAdminLinks       = "/admin/links"
+	PathAdminVoterView   = "/admin/voter-view"
+	PathAdminCeremony    = "/admin/ceremony"
+	PathAdminCelebrate   = "/admin/ceremony/celebrate"
+	PathAdminCeremonyShow = "/admin/ceremony/show"
+	PathAdminCeremonyReveal = "/admin/ceremony/reveal"
+	PathAdminCeremonyNext = "/admin/ceremony/next"
+	PathAdminCeremonyCountdown = "/admin/ceremony/countdown"
+	PathAdminSounds      = "/admin/sounds"
+	PathAdminDeleteSound = "/admin/sounds/delete"
+	PathAdminLeaderboardExport = "/admin/leaderboard.csv"
+	PathAdminBallotsExport = "/admin/ballots.%s"
+	PathAdminImport      = "/admin/import"
+	PathAdminStations    = "/admin/stations"
+	PathAdminStationPair = "/admin/stations/%d/pair"
+	PathAdminStationRevoke = "/admin/stations/%d/revoke"
+	PathAdminStationReport = "/admin/stations/report"
+	PathAdminConflict      = "/admin/conflicts/%d/%s"
+	PathAdminSeatmap       = "/admin/seatmap"
+	PathAdminRoster        = "/admin/roster"
+	PathAdminRosterTags    = "/admin/roster/%d/tags"
+	PathAdminBroadcast     = "/admin/broadcast"
+	PathAdminQuick         = "/admin/quick"
+	PathAdminObservers     = "/admin/observers"
+	PathAdminObserversRevoke = "/admin/observers/revoke"
+	PathAdminSeason        = "/admin/season"
+	PathAdminSeasonRemove  = "/admin/season/%d/remove"
+
+	PathAPICategories    = "/api/v1/categories"
+	PathAPICategory      = "/api/v1/categories/%d"
+	PathAPICategoryVotes = "/api/v1/categories/%d/votes"
+	PathAPIResults       = "/api/v1/results/%d"
+	PathAPIFeed          = "/api/v1/feed"
+	PathAPIBallots       = "/api/v1/ballots"
 )
 
+// CategoryRef is what voter-facing URLs accept for a poll: its ID or its
+// slug (see db.Category.Ref)
+type CategoryRef interface {
+	~int64 | ~string
+}
+
 // Type-safe URL builders
 func HomeURL() string {
 	return PathHome
 }
 
-func VoteURL(categoryID int64) string {
-	return fmt.Sprintf(PathVote, categoryID)
+func VoteURL[R CategoryRef](category R) string {
+	return fmt.Sprintf(PathVote, category)
+}
+
+func VoteWidgetURL[R CategoryRef](category R) string {
+	return fmt.Sprintf(PathVoteWidget, category)
+}
+
+func OptionDetailsURL[R CategoryRef](category R, optionID int64) string {
+	return fmt.Sprintf(PathOptionDetails, category, optionID)
 }
 
-func ResultsURL(categoryID int64) string {
-	return fmt.Sprintf(PathResults, categoryID)
+func ResultsURL[R CategoryRef](category R) string {
+	return fmt.Sprintf(PathResults, category)
 }
 
 func ResultsListURL() string {
 	return PathResultsList
 }
 
-func ResultsTableURL(categoryID int64) string {
-	return fmt.Sprintf(PathResultsTable, categoryID)
+func ResultsTableURL[R CategoryRef](category R) string {
+	return fmt.Sprintf(PathResultsTable, category)
+}
+
+func ResultsCardURL[R CategoryRef](category R) string {
+	return fmt.Sprintf(PathResultsCard, category)
+}
+
+func ShortLinkURL(code string) string {
+	return fmt.Sprintf(PathShortLink, code)
+}
+
+func EventsURL() string {
+	return PathEvents
+}
+
+func DisplayURL() string {
+	return PathDisplay
+}
+
+func SoundURL(name string) string {
+	return fmt.Sprintf(PathSound, name)
+}
+
+func ImageURL(name string) string {
+	return fmt.Sprintf(PathImage, name)
+}
+
+func ImageThumbURL(name string) string {
+	return fmt.Sprintf(PathImageThumb, name)
+}
+
+func AvatarURL(id string) string {
+	return fmt.Sprintf(PathAvatar, id)
+}
+
+func LeaderboardURL() string {
+	return PathLeaderboard
+}
+
+func SeasonURL() string {
+	return PathSeason
+}
+
+func PairURL(token string) string {
+	return fmt.Sprintf(PathPair, token)
+}
+
+// ObserveURL is the read-only results and turnout page an observer link
+// token (see db.SignObserver) opens
+func ObserveURL(token string) string {
+	return fmt.Sprintf(PathObserve, token)
 }
 
 func AdminURL() string {
@@ -71,6 +198,34 @@ func AdminCategoryArchiveURL(categoryID int64) string {
 	return fmt.Sprintf(PathAdminCategoryArchive, categoryID)
 }
 
+func AdminCategorySeedURL(categoryID int64) string {
+	return fmt.Sprintf(PathAdminCategorySeed, categoryID)
+}
+
+func AdminCategoryRunoffURL(categoryID int64) string {
+	return fmt.Sprintf(PathAdminCategoryRunoff, categoryID)
+}
+
+func AdminCategoryTestURL(categoryID int64) string {
+	return fmt.Sprintf(PathAdminCategoryTest, categoryID)
+}
+
+func AdminCategoryPreviewURL(categoryID int64) string {
+	return fmt.Sprintf(PathAdminCategoryPreview, categoryID)
+}
+
+func AdminCategoryGroupsURL(categoryID int64) string {
+	return fmt.Sprintf(PathAdminCategoryGroups, categoryID)
+}
+
+func AdminCategoryWindowURL(categoryID int64) string {
+	return fmt.Sprintf(PathAdminCategoryWindow, categoryID)
+}
+
+func AdminCategoryBallotsURL(categoryID int64) string {
+	return fmt.Sprintf(PathAdminCategoryBallots, categoryID)
+}
+
 func AdminAddOptionURL(categoryID int64) string {
 	return fmt.Sprintf(PathAdminAddOption, categoryID)
 }
@@ -82,3 +237,174 @@ func AdminRemoveOptionURL(categoryID int64, optionID int64) string {
 func AdminOptionURL(optionID int64) string {
 	return fmt.Sprintf(PathAdminOption, optionID)
 }
+
+func AdminRetireOptionURL(optionID int64) string {
+	return fmt.Sprintf(PathAdminRetireOption, optionID)
+}
+
+func AdminOptionImageURL(optionID int64) string {
+	return fmt.Sprintf(PathAdminOptionImage, optionID)
+}
+
+func AdminOptionDetailsURL(optionID int64) string {
+	return fmt.Sprintf(PathAdminOptionDetails, optionID)
+}
+
+func AdminActivityURL() string {
+	return PathAdminActivity
+}
+
+func AdminHighContrastURL() string {
+	return PathAdminHighContrast
+}
+
+func AdminForgetVoterURL() string {
+	return PathAdminForgetVoter
+}
+
+func AdminSettingsURL() string {
+	return PathAdminSettings
+}
+
+func AdminSearchURL() string {
+	return PathAdminSearch
+}
+
+func AdminLinksURL() string {
+	return PathAdminLinks
+}
+
+func AdminVoterViewURL() string {
+	return PathAdminVoterView
+}
+
+func AdminCeremonyURL() string {
+	return PathAdminCeremony
+}
+
+func AdminCelebrateURL() string {
+	return PathAdminCelebrate
+}
+
+func AdminCeremonyShowURL() string {
+	return PathAdminCeremonyShow
+}
+
+func AdminCeremonyRevealURL() string {
+	return PathAdminCeremonyReveal
+}
+
+func AdminCeremonyNextURL() string {
+	return PathAdminCeremonyNext
+}
+
+func AdminCeremonyCountdownURL() string {
+	return PathAdminCeremonyCountdown
+}
+
+func AdminSoundsURL() string {
+	return PathAdminSounds
+}
+
+func AdminDeleteSoundURL() string {
+	return PathAdminDeleteSound
+}
+
+func AdminLeaderboardExportURL() string {
+	return PathAdminLeaderboardExport
+}
+
+func AdminBallotsExportURL(format string) string {
+	return fmt.Sprintf(PathAdminBallotsExport, format)
+}
+
+func AdminImportURL() string {
+	return PathAdminImport
+}
+
+func AdminStationsURL() string {
+	return PathAdminStations
+}
+
+func AdminStationPairURL(stationID int64) string {
+	return fmt.Sprintf(PathAdminStationPair, stationID)
+}
+
+func AdminStationRevokeURL(stationID int64) string {
+	return fmt.Sprintf(PathAdminStationRevoke, stationID)
+}
+
+func AdminStationReportURL() string {
+	return PathAdminStationReport
+}
+
+func AdminBroadcastURL() string {
+	return PathAdminBroadcast
+}
+
+// AdminSeatmapURL shows turnout by seat in poll categoryID, or in the
+// latest open poll for 0
+func AdminSeatmapURL(categoryID int64) string {
+	if categoryID == 0 {
+		return PathAdminSeatmap
+	}
+	return fmt.Sprintf("%s?poll=%d", PathAdminSeatmap, categoryID)
+}
+
+func AdminQuickURL() string {
+	return PathAdminQuick
+}
+
+func AdminObserversURL() string {
+	return PathAdminObservers
+}
+
+func AdminObserversRevokeURL() string {
+	return PathAdminObserversRevoke
+}
+
+func AdminSeasonURL() string {
+	return PathAdminSeason
+}
+
+func AdminSeasonRemoveURL(eventID int64) string {
+	return fmt.Sprintf(PathAdminSeasonRemove, eventID)
+}
+
+func AdminRosterURL() string {
+	return PathAdminRoster
+}
+
+func AdminRosterTagsURL(attendeeID int64) string {
+	return fmt.Sprintf(PathAdminRosterTags, attendeeID)
+}
+
+// AdminConflictURL resolves a vote conflict by keeping db.KeptFirst or
+// db.KeptSecond
+func AdminConflictURL(conflictID int64, kept string) string {
+	return fmt.Sprintf(PathAdminConflict, conflictID, kept)
+}
+
+func APICategoriesURL() string {
+	return PathAPICategories
+}
+
+func APICategoryURL(categoryID int64) string {
+	return fmt.Sprintf(PathAPICategory, categoryID)
+}
+
+func APICategoryVotesURL(categoryID int64) string {
+	return fmt.Sprintf(PathAPICategoryVotes, categoryID)
+}
+
+func APIResultsURL(categoryID int64) string {
+	return fmt.Sprintf(PathAPIResults, categoryID)
+}
+
+func APIFeedURL() string {
+	return PathAPIFeed
+}
+
+func APIBallotsURL() string {
+	return PathAPIBallots
+}
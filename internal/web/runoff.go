@@ -0,0 +1,121 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// runoffLinks returns the poll cat is a runoff of and cat's own runoff, for
+// linking their results pages. A runoff still in draft isn't linked.
+func (s *Server) runoffLinks(ctx context.Context, cat db.Category) (original, runoff *db.Category) {
+	if cat.RunoffOf.Valid {
+		if c, err := s.queries.Category(ctx, cat.RunoffOf.Int64); err == nil {
+			original = &c
+		}
+	}
+	if c, err := s.queries.GetRunoff(ctx, sql.NullInt64{Int64: cat.ID, Valid: true}); err == nil && c.Status != "draft" {
+		runoff = &c
+	}
+	return original, runoff
+}
+
+// runoffData is what the admin category page shows about runoffs: the
+// runoff already created for cat, or why one is needed and between which
+// options
+func (s *Server) runoffData(ctx context.Context, cat db.Category) map[string]any {
+	data := map[string]any{}
+	if cat.RunoffOf.Valid {
+		if c, err := s.queries.Category(ctx, cat.RunoffOf.Int64); err == nil {
+			data["RunoffOf"] = c
+		}
+	}
+	runoff, err := s.queries.GetRunoff(ctx, sql.NullInt64{Int64: cat.ID, Valid: true})
+	if err == nil {
+		data["Runoff"] = runoff
+		return data
+	}
+
+	reason, options, err := s.queries.RunoffCandidates(ctx, cat)
+	if err != nil {
+		log.Printf("Failed to check category %d for a runoff: %v", cat.ID, err)
+	}
+	if reason != "" {
+		names := make([]string, len(options))
+		for i, o := range options {
+			names[i] = o.Name
+		}
+		data["RunoffReason"], data["RunoffOptions"] = reason, strings.Join(names, ", ")
+	}
+	return data
+}
+
+// handleAdminRunoff creates a runoff for a closed single-choice poll that
+// ended in a tie or without a majority, then shows it. If the poll already
+// has a runoff, that one is shown instead.
+func (s *Server) handleAdminRunoff(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodPost {
+		s.notFound(w, r)
+		return
+	}
+
+	cat, err := s.queries.Category(r.Context(), id)
+	if err != nil {
+		s.lookupFailed(w, r, "poll", err)
+		return
+	}
+
+	existing, err := s.queries.GetRunoff(r.Context(), sql.NullInt64{Int64: id, Valid: true})
+	if err == nil {
+		http.Redirect(w, r, AdminCategoryURL(existing.ID), http.StatusSeeOther)
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		s.renderError(w, "Failed to look up runoff", err)
+		return
+	}
+
+	reason, options, err := s.queries.RunoffCandidates(r.Context(), cat)
+	if err != nil {
+		s.renderError(w, "Failed to tally results", err)
+		return
+	}
+	if reason == "" {
+		s.categoryError(w, r, cat, "No runoff needed",
+			"No runoff needed: only a closed single-choice poll that ends in a tie or without a majority gets one")
+		return
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.renderError(w, "Database error", err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	runoff, added, err := qtx.CreateRunoff(r.Context(), cat, options)
+	if err != nil {
+		s.renderError(w, "Failed to create runoff", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.renderError(w, "Failed to create runoff", err)
+		return
+	}
+
+	s.audit(r, db.AuditCategoryRunoff, cat.ID, fmt.Sprintf("%s (%s)", runoff.Name, reason))
+	s.audit(r, db.AuditCategoryCreate, runoff.ID, runoff.Name)
+	for _, o := range added {
+		s.audit(r, db.AuditOptionSeed, runoff.ID, fmt.Sprintf("%s (from %s)", o.Name, cat.Name))
+	}
+
+	http.Redirect(w, r, AdminCategoryURL(runoff.ID), http.StatusSeeOther)
+}
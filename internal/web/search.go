@@ -0,0 +1,41 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// maxSearchResults caps how many polls the command palette lists
+const maxSearchResults = 8
+
+// handleAdminSearch serves the results of the dashboard's command palette:
+// polls matching q, each with its open/close actions. The palette is part
+// of the modern UI only.
+func (s *Server) handleAdminSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	if _, ok := s.partial("partials/search-results.html"); !ok {
+		s.notFound(w, r)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	polls, err := s.queries.ListCategoriesExcludeArchived(r.Context())
+	if err != nil {
+		s.renderActionError(w, r, "Failed to search polls", err)
+		return
+	}
+	if query != "" {
+		polls = db.MatchCategories(polls, query)
+	}
+
+	s.renderPartial(w, "partials/search-results.html", SearchResultsData{
+		Query:   query,
+		Polls:   polls[:min(len(polls), maxSearchResults)],
+		NewPoll: query == "" || strings.HasPrefix("new poll", strings.ToLower(query)),
+	})
+}
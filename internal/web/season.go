@@ -0,0 +1,164 @@
+package web
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// SeasonPageData renders season.html. Each standing's Events line up with
+// Events.
+type SeasonPageData struct {
+	Page
+	Season    string
+	Events    []db.SeasonEvent
+	Standings []db.SeasonStanding
+}
+
+// AdminSeasonPageData renders admin/season.html. Pending are the finished
+// polls finalizing would score now; Open counts polls still open, which are
+// scored with whichever event they finish in. Name refills the form after
+// an Error.
+type AdminSeasonPageData struct {
+	SeasonPageData
+	Points  string
+	Pending []db.Category
+	Open    int
+	Name    string
+	Error   string
+}
+
+// loadSeason fills in the season's name, events and standings
+func (s *Server) loadSeason(ctx context.Context) (SeasonPageData, error) {
+	name := s.setting(db.SettingSeason)
+	data := SeasonPageData{Page: Page{Title: cmp.Or(name, "Season")}, Season: name}
+	var err error
+	data.Events, data.Standings, err = s.queries.Season(ctx)
+	return data, err
+}
+
+// handleSeason shows the season leaderboard: the points poll winners have
+// earned across every finalized event, if the season setting names one
+func (s *Server) handleSeason(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+	if s.setting(db.SettingSeason) == "" && !s.authorized(r) {
+		s.notFound(w, r)
+		return
+	}
+
+	data, err := s.loadSeason(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load the season", err)
+		return
+	}
+	s.render(w, "season.html", data)
+}
+
+// handleAdminSeason previews the polls the next event will score and
+// finalizes it: every finished poll's placings are recorded toward the
+// season with the points the season_points setting awards, and the polls
+// are archived
+func (s *Server) handleAdminSeason(w http.ResponseWriter, r *http.Request) {
+	var name string
+	var formError string
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		name = s.setting(db.SettingEventName)
+	case http.MethodPost:
+		name = strings.TrimSpace(r.FormValue("name"))
+		points, err := db.ParseSeasonPoints(s.setting(db.SettingSeasonPoints))
+		switch {
+		case name == "":
+			formError = "Please name the event"
+		case err != nil:
+			formError = "Fix the season points setting first: " + err.Error()
+		default:
+			_, _, err := db.FinalizeEvent(r.Context(), s.db, name, points, db.ActorAdmin)
+			if errors.Is(err, db.ErrNothingToFinalize) {
+				formError = "No finished polls are waiting to be scored. Close the event's polls first."
+				break
+			}
+			if err != nil {
+				s.renderActionError(w, r, "Failed to finalize the event", err)
+				return
+			}
+			http.Redirect(w, r, AdminSeasonURL(), http.StatusSeeOther)
+			return
+		}
+	default:
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead, http.MethodPost)
+		return
+	}
+
+	season, err := s.loadSeason(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load the season", err)
+		return
+	}
+	data := AdminSeasonPageData{
+		SeasonPageData: season,
+		Points:         s.setting(db.SettingSeasonPoints),
+		Name:           name,
+		Error:          formError,
+	}
+	data.Title = "Season"
+	if data.Pending, err = s.queries.ListUnscoredCategories(r.Context()); err != nil {
+		s.renderError(w, "Failed to load polls", err)
+		return
+	}
+	open, err := s.queries.ListOpenCategories(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load polls", err)
+		return
+	}
+	data.Open = len(open)
+
+	if formError != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	s.render(w, "admin/season.html", data)
+}
+
+// handleAdminSeasonRemove takes a finalized event out of the season. Its
+// polls stay archived but count as unscored again, so the next event
+// finalized picks them up.
+func (s *Server) handleAdminSeasonRemove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/admin/season/")
+	idStr, action, _ := strings.Cut(rest, "/")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || action != "remove" {
+		s.notFound(w, r)
+		return
+	}
+
+	events, err := s.queries.ListSeasonEvents(r.Context())
+	if err != nil {
+		s.renderActionError(w, r, "Failed to load the season", err)
+		return
+	}
+	i := slices.IndexFunc(events, func(e db.SeasonEvent) bool { return e.ID == id })
+	if i < 0 {
+		s.notFound(w, r)
+		return
+	}
+	if _, err := s.queries.DeleteSeasonEvent(r.Context(), id); err != nil {
+		s.renderActionError(w, r, "Failed to remove the event", err)
+		return
+	}
+	s.audit(r, db.AuditSeasonRemove, 0, events[i].Name)
+	http.Redirect(w, r, AdminSeasonURL(), http.StatusSeeOther)
+}
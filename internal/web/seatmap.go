@@ -0,0 +1,107 @@
+package web
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// handleAdminSeatmap shows the roster's seats coloured by whether their
+// attendee has voted in an open poll (?poll=ID, by default the latest
+// opened), so runners can go and nudge the tables that haven't. HTMX
+// requests get the map alone, for the page to refresh itself.
+func (s *Server) handleAdminSeatmap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	open, err := s.reads.ListOpenCategories(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load polls", err)
+		return
+	}
+	data := SeatmapPageData{Page: Page{Title: "Seat map"}, Open: open}
+	if len(open) > 0 {
+		data.Poll = &open[0]
+	}
+	if v := r.URL.Query().Get("poll"); v != "" {
+		id, _ := strconv.ParseInt(v, 10, 64)
+		i := slices.IndexFunc(open, func(c db.Category) bool { return c.ID == id })
+		if i < 0 {
+			s.notFound(w, r)
+			return
+		}
+		data.Poll = &open[i]
+	}
+
+	if data.Poll != nil {
+		rows, err := s.reads.ListSeatTurnout(r.Context(), data.Poll.ID)
+		if err != nil {
+			s.renderError(w, "Failed to load the roster", err)
+			return
+		}
+		s.fillSeatmap(&data, rows)
+	}
+	if s.isHTMX(r) {
+		s.renderPartial(w, "partials/seatmap.html", data)
+		return
+	}
+	s.render(w, "admin/seatmap.html", data)
+}
+
+// fillSeatmap lays out the roster's seats in rows and counts turnout
+func (s *Server) fillSeatmap(data *SeatmapPageData, rows []db.ListSeatTurnoutRow) {
+	byRow := make(map[string]int) // row name to index in data.Rows
+	for _, row := range rows {
+		seat := Seat{Label: row.Seat, Nickname: s.nicknames.Reveal(row.Nickname), Voted: row.Votes > 0}
+		data.Total++
+		if seat.Voted {
+			data.Voted++
+		}
+		if seat.Label == "" {
+			data.Unseated = append(data.Unseated, seat)
+			continue
+		}
+		name, _ := splitSeat(seat.Label)
+		i, ok := byRow[name]
+		if !ok {
+			i = len(data.Rows)
+			byRow[name] = i
+			data.Rows = append(data.Rows, SeatRow{Name: name})
+		}
+		data.Rows[i].Seats = append(data.Rows[i].Seats, seat)
+	}
+
+	slices.SortFunc(data.Rows, func(a, b SeatRow) int { return compareSeats(a.Name, b.Name) })
+	for _, row := range data.Rows {
+		slices.SortFunc(row.Seats, func(a, b Seat) int { return compareSeats(a.Label, b.Label) })
+	}
+}
+
+// splitSeat splits a seat label into its row, what comes before its
+// trailing number, and that number: "B12" is seat 12 in row B and
+// "Table 3-4" seat 4 at table 3. A label without a number is a row of its
+// own.
+func splitSeat(label string) (row string, n int) {
+	i := len(label)
+	for i > 0 && label[i-1] >= '0' && label[i-1] <= '9' {
+		i--
+	}
+	if i == len(label) {
+		return label, 0
+	}
+	n, _ = strconv.Atoi(label[i:])
+	return strings.TrimRight(label[:i], " -_./:#"), n
+}
+
+// compareSeats orders seat labels by row, then by number, so B2 comes
+// before B10
+func compareSeats(a, b string) int {
+	rowA, nA := splitSeat(a)
+	rowB, nB := splitSeat(b)
+	return cmp.Or(strings.Compare(rowA, rowB), cmp.Compare(nA, nB), strings.Compare(a, b))
+}
@@ -2,15 +2,28 @@
 package web
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
 	"log"
+	"maps"
 	"net/http"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/notify"
+	"github.com/palm-arcade/votigo/internal/oembed"
+	"github.com/palm-arcade/votigo/internal/tally"
 	"github.com/palm-arcade/votigo/static"
 	"github.com/palm-arcade/votigo/templates"
 )
@@ -25,80 +38,244 @@ const (
 type Server struct {
 	db            *sql.DB
 	queries       *db.Queries
+	reads         *db.Queries // results and tallies; queries unless WithReadDB
 	templates     map[string]*template.Template
 	partials      map[string]*template.Template
 	adminPassword string
+	adminHash     *adminHash // set by WithAdminPasswordHash
 	uiMode        UIMode
+	settings      settingsCache
+	nicknames     *db.NicknameCipher
+	notifier      notify.Notifier
+	timeouts      Timeouts
+	jobs          Jobs
+	ballotQueue   *ballotQueue
+	events        *eventHub
+	ceremony      ceremony
+	broadcast     broadcast
+	results       resultsHistory
+	soundDir      string
+	imageDir      string
+	media         *oembed.Client // fetches video previews; see WithMediaPreviews
+	avatarSalt    []byte
+	accessLog     io.Writer
+	templateDir   string // parse templates from here, not templates.FS; see WithTemplateDir
+
+	// mu guards what Reload and SetNotifier replace while requests use them
+	mu sync.RWMutex
+
+	startHighContrast bool
 }
 
-func NewServer(database *sql.DB, adminPassword string, uiMode UIMode) (*Server, error) {
+// Option configures optional Server behaviour
+type Option func(*Server)
+
+// WithHighContrast turns the high-contrast theme on at startup. It is saved
+// like any other setting, so admins can still toggle it from the dashboard.
+func WithHighContrast(on bool) Option {
+	return func(s *Server) {
+		s.startHighContrast = on
+	}
+}
+
+// WithNicknameCipher encrypts voter nicknames at rest. It must match the
+// cipher returned by db.SetupEncryption for the same database.
+func WithNicknameCipher(c *db.NicknameCipher) Option {
+	return func(s *Server) {
+		s.nicknames = c
+	}
+}
+
+// WithNotifier announces polls opening and closing, with final results,
+// through n
+func WithNotifier(n notify.Notifier) Option {
+	return func(s *Server) {
+		s.notifier = n
+	}
+}
+
+// WithReadDB serves results and tallies from conn, a read-only handle on the
+// same database (see db.OpenReadOnly), so projectors refreshing results
+// don't hold up ballots being written
+func WithReadDB(conn *sql.DB) Option {
+	return func(s *Server) {
+		s.reads = db.New(conn)
+	}
+}
+
+func NewServer(database *sql.DB, adminPassword string, uiMode UIMode, opts ...Option) (*Server, error) {
+	s := &Server{
+		db:            database,
+		queries:       db.New(database),
+		adminPassword: adminPassword,
+		uiMode:        uiMode,
+		timeouts:      DefaultTimeouts,
+		jobs:          DefaultJobs,
+		events:        newEventHub(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.reads == nil {
+		s.reads = s.queries
+	}
+	s.ballotQueue = newBallotQueue(s)
+
+	salt, err := s.queries.GetAvatarSalt(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load avatar salt: %w", err)
+	}
+	s.avatarSalt = salt
+
+	if s.startHighContrast {
+		if _, err := s.queries.SetSetting(context.Background(), db.SettingHighContrast, "true"); err != nil {
+			return nil, fmt.Errorf("failed to enable high contrast: %w", err)
+		}
+	}
+
+	if s.templates, s.partials, err = s.parseTemplates(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// parseTemplates parses the pages, widgets and partials for s's UI mode
+func (s *Server) parseTemplates() (tmpls, partials map[string]*template.Template, err error) {
+	var files fs.FS = templates.FS
+	if s.templateDir != "" {
+		files = os.DirFS(s.templateDir)
+	}
 	funcMap := template.FuncMap{
-		"add": func(a, b int) int { return a + b },
+		"add":            func(a, b int) int { return a + b },
+		"highContrast":   func() bool { return s.settingBool(db.SettingHighContrast) },
+		"countdown":      s.countdown,
+		"colorHex":       colorHex,
+		"categoryColors": func() []CategoryColor { return CategoryColors },
+		"skins":          func() []Skin { return Skins },
+		"percent":        tally.Share,
+		"kilobytes":      func(n int64) int64 { return (n + 1023) / 1024 },
+		"thumbnail":      thumbnailURL,
+		"avatar":         s.avatarURL,
 	}
 
-	templateDir := string(uiMode)
+	templateDir := string(s.uiMode)
 
-	tmpls := make(map[string]*template.Template)
-	partials := make(map[string]*template.Template)
+	tmpls = make(map[string]*template.Template)
+	partials = make(map[string]*template.Template)
 
 	// List of page templates to load with layout
 	pages := []string{
 		"home.html",
 		"vote.html",
+		"option.html",
 		"results.html",
 		"results-list.html",
+		"leaderboard.html",
+		"season.html",
 		"error.html",
 		"admin/dashboard.html",
 		"admin/category.html",
-	}
-
-	layoutContent, err := templates.FS.ReadFile(templateDir + "/layout.html")
+		"admin/option.html",
+		"admin/settings.html",
+		"admin/links.html",
+		"admin/ceremony.html",
+		"admin/import.html",
+		"admin/stations.html",
+		"admin/station-report.html",
+		"admin/seatmap.html",
+		"admin/roster.html",
+		"admin/groups.html",
+		"admin/window.html",
+		"admin/ballots.html",
+		"admin/quick.html",
+		"admin/observers.html",
+		"admin/season.html",
+		"observe.html",
+	}
+
+	layoutContent, err := fs.ReadFile(files, templateDir+"/layout.html")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read layout: %w", err)
+		return nil, nil, fmt.Errorf("failed to read layout: %w", err)
 	}
 
 	for _, page := range pages {
-		pageContent, err := templates.FS.ReadFile(templateDir + "/" + page)
+		pageContent, err := fs.ReadFile(files, templateDir+"/"+page)
 		if err != nil {
 			continue
 		}
 
 		t, err := template.New(page).Funcs(funcMap).Parse(string(layoutContent) + string(pageContent))
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		tmpls[page] = t
 	}
 
-	// Load partials for modern UI (htmx responses)
-	if uiMode == UIModeModern {
-		partialFiles := []string{
-			"partials/vote-form.html",
-			"partials/option-row.html",
-			"partials/results-table.html",
-			"partials/status-badge.html",
+	// Widgets are standalone pages for other sites to embed in an iframe.
+	// They skip the layout and reuse blocks from the page they shrink. The
+	// projector display skips the layout too but stands alone.
+	widgets := map[string]string{
+		"widget.html":  "vote.html",
+		"display.html": "",
+	}
+	for widget, page := range widgets {
+		content, err := fs.ReadFile(files, templateDir+"/"+widget)
+		if err != nil {
+			continue
 		}
-		for _, partial := range partialFiles {
-			content, err := templates.FS.ReadFile("modern/" + partial)
+		var pageContent []byte
+		if page != "" {
+			pageContent, err = fs.ReadFile(files, templateDir+"/"+page)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read %s for %s: %w", page, widget, err)
+			}
+		}
+		t, err := template.New(widget).Funcs(funcMap).Parse(string(content) + string(pageContent))
+		if err != nil {
+			return nil, nil, err
+		}
+		tmpls[widget] = t
+	}
+
+	// Load partials for modern UI (htmx responses). Partials invoke blocks
+	// defined in page templates, so each is parsed alongside its page;
+	// those with no page stand alone.
+	if s.uiMode == UIModeModern {
+		partialFiles := map[string]string{
+			"partials/vote-form.html":      "vote.html",
+			"partials/option-details.html": "option.html",
+			"partials/option-row.html":     "admin/category.html",
+			"partials/option-rows.html":    "admin/category.html",
+			"partials/results-table.html":  "results.html",
+			"partials/status-badge.html":   "admin/dashboard.html",
+			"partials/activity-feed.html":  "admin/dashboard.html",
+			"partials/toast.html":          "",
+			"partials/search-results.html": "admin/dashboard.html",
+			"partials/seatmap.html":        "admin/seatmap.html",
+			"partials/quick.html":          "admin/quick.html",
+			"partials/observe.html":        "observe.html",
+		}
+		for partial, page := range partialFiles {
+			content, err := fs.ReadFile(files, "modern/"+partial)
 			if err != nil {
 				continue
 			}
-			t, err := template.New(partial).Funcs(funcMap).Parse(string(content))
+			var pageContent []byte
+			if page != "" {
+				pageContent, err = fs.ReadFile(files, "modern/"+page)
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to read %s for %s: %w", page, partial, err)
+				}
+			}
+			t, err := template.New(partial).Funcs(funcMap).Parse(string(content) + string(pageContent))
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			partials[partial] = t
 		}
 	}
 
-	return &Server{
-		db:            database,
-		queries:       db.New(database),
-		templates:     tmpls,
-		partials:      partials,
-		adminPassword: adminPassword,
-		uiMode:        uiMode,
-	}, nil
+	return tmpls, partials, nil
 }
 
 // Handler returns the HTTP handler for testing purposes
@@ -108,67 +285,143 @@ func (s *Server) Handler() http.Handler {
 	// Static files (for modern UI)
 	if s.uiMode == UIModeModern {
 		mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(static.FS))))
+		mux.HandleFunc("/sw.js", s.handleServiceWorker)
 	}
 
 	// Voter routes
 	mux.HandleFunc("/", s.handleHome)
 	mux.HandleFunc("/vote/", s.handleVote)
 	mux.HandleFunc("/results/", s.handleResults)
+	mux.HandleFunc("/c/", s.handleShortLink)
+	mux.HandleFunc("/events", s.handleEvents)
+	mux.HandleFunc("/display", s.handleDisplay)
+	mux.HandleFunc("/sounds/", s.handleSound)
+	mux.HandleFunc("/images/", s.handleImage)
+	mux.HandleFunc("/avatars/", s.handleAvatar)
+	mux.HandleFunc("/leaderboard", s.handleLeaderboard)
+	mux.HandleFunc("/season", s.handleSeason)
+	mux.HandleFunc("/pair/", s.handlePair)
+	mux.HandleFunc("/observe/", s.handleObserve)
+
+	// JSON API (offline ballot sync, results for overlays)
+	mux.HandleFunc("/api/", s.handleAPI)
 
 	// Admin routes
 	mux.HandleFunc("/admin", s.handleAdmin)
 	mux.HandleFunc("/admin/", s.handleAdmin)
 
-	return mux
+	return s.withAccessLog(s.withTimeouts(mux))
+}
+
+// Start serves on port until ctx is cancelled, then stops accepting
+// connections and gives in-flight requests up to the drain timeout to finish
+// before closing whatever is left
+func (s *Server) Start(ctx context.Context, port int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.runJobs(ctx)
+
+	srv := s.HTTPServer(":" + strconv.Itoa(port))
+	// Event streams would otherwise hold up the drain until they time out
+	srv.RegisterOnShutdown(s.events.close)
+	return s.listen(ctx, srv)
 }
 
-func (s *Server) Start(port int) error {
-	addr := ":" + strconv.Itoa(port)
-	log.Printf("Starting server on http://0.0.0.0%s", addr)
-	return http.ListenAndServe(addr, s.Handler())
+// listen runs srv until ctx is cancelled, then shuts it down, giving
+// in-flight requests up to the drain timeout
+func (s *Server) listen(ctx context.Context, srv *http.Server) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+
+	log.Printf("Starting server on http://0.0.0.0%s", srv.Addr)
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, waiting up to %s for requests to finish", s.timeouts.Drain)
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), s.timeouts.Drain)
+	defer cancelDrain()
+	if err := srv.Shutdown(drainCtx); err != nil {
+		log.Printf("Requests still running after %s, closing their connections", s.timeouts.Drain)
+		return srv.Close()
+	}
+	return nil
+}
+
+// handleServiceWorker serves the PWA service worker from the site root so
+// its scope covers every voter page, not just /static/.
+func (s *Server) handleServiceWorker(w http.ResponseWriter, r *http.Request) {
+	content, err := static.FS.ReadFile("sw.js")
+	if err != nil {
+		s.notFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(content)
 }
 
 func (s *Server) render(w http.ResponseWriter, name string, data any) {
-	t, ok := s.templates[name]
+	t, ok := s.page(name)
 	if !ok {
 		log.Printf("Template not found: %s", name)
 		http.Error(w, "Template not found", http.StatusInternalServerError)
 		return
 	}
-	err := t.Execute(w, data)
-	if err != nil {
-		log.Printf("Template error: %v", err)
-	}
+	execute(w, t, data)
 }
 
+// renderError logs err and shows message on the error page, with the status
+// errorStatus gives err
 func (s *Server) renderError(w http.ResponseWriter, message string, err error) {
 	log.Printf("Error: %s: %v", message, err)
-	w.WriteHeader(http.StatusInternalServerError)
+	w.WriteHeader(errorStatus(err))
 	s.render(w, "error.html", map[string]any{
 		"Message": message,
 	})
 }
 
+// audit records an admin action in the audit log. Failures are logged
+// rather than surfaced, since the action itself has already succeeded.
+func (s *Server) audit(r *http.Request, action string, categoryID int64, detail string) {
+	if err := s.queries.RecordAudit(r.Context(), db.ActorAdmin, action, categoryID, detail); err != nil {
+		log.Printf("Failed to record audit event %s: %v", action, err)
+	}
+}
+
 func (s *Server) isHTMX(r *http.Request) bool {
 	return r.Header.Get("HX-Request") == "true"
 }
 
 func (s *Server) renderPartial(w http.ResponseWriter, name string, data any) {
-	t, ok := s.partials[name]
+	t, ok := s.partial(name)
 	if !ok {
 		log.Printf("Partial not found: %s", name)
 		http.Error(w, "Partial not found", http.StatusInternalServerError)
 		return
 	}
-	err := t.Execute(w, data)
-	if err != nil {
-		log.Printf("Partial error: %v", err)
+	execute(w, t, data)
+}
+
+// execute renders into a buffer first, so a template error is answered
+// with a 500 instead of half a page
+func execute(w http.ResponseWriter, t *template.Template, data any) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		log.Printf("Template error in %s: %v", t.Name(), err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
 	}
+	buf.WriteTo(w)
 }
 
 func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/" {
-		http.NotFound(w, r)
+		s.notFound(w, r)
 		return
 	}
 
@@ -178,207 +431,230 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.render(w, "home.html", map[string]any{
-		"Categories": categories,
-	})
+	data := HomePageData{Categories: categories}
+	data.Viewer = s.viewer(r, nil)
+	if len(categories) == 0 {
+		data.Landing, err = s.loadLanding(r.Context(), time.Now())
+		if err != nil {
+			s.renderError(w, "Failed to load schedule", err)
+			return
+		}
+	}
+	s.render(w, "home.html", data)
 }
 
 func (s *Server) handleVote(w http.ResponseWriter, r *http.Request) {
-	// Extract ID from /vote/{id}
-	idStr := r.URL.Path[len("/vote/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		http.NotFound(w, r)
+	// Extract the ID or slug from /vote/{ref} or /vote/{ref}/widget
+	ref, widget := strings.CutSuffix(r.URL.Path[len("/vote/"):], "/widget")
+	if ref, optionID, ok := strings.Cut(ref, "/option/"); ok {
+		s.handleOptionDetails(w, r, ref, optionID)
 		return
 	}
-
-	cat, err := s.queries.GetCategory(r.Context(), id)
-	if err != nil {
-		s.renderError(w, "Category not found", err)
+	cat, ok := s.categoryByRef(w, r, ref)
+	if !ok {
 		return
 	}
 
-	if cat.Status != "open" {
+	page := "vote.html"
+	if widget {
+		page = "widget.html"
+		s.allowFraming(w)
+	}
+
+	// Admins try out a draft poll's ballot in test mode; the widget is
+	// for voters only
+	adminTest := cat.Testing() && !widget && s.authorized(r)
+	if cat.Status != "open" && !adminTest {
+		if widget {
+			data := newVotePage(cat, nil, widget)
+			data.Message = "Voting is not open for this poll"
+			s.render(w, page, data)
+			return
+		}
 		s.render(w, "error.html", map[string]any{
 			"Message": "Voting is not open for this category",
 		})
 		return
 	}
 
-	options, err := s.queries.ListOptionsByCategory(r.Context(), id)
+	options, err := s.queries.ListBallotOptionsByCategory(r.Context(), cat.ID)
 	if err != nil {
 		s.renderError(w, "Failed to load options", err)
 		return
 	}
 
 	if r.Method == http.MethodPost {
-		s.handleVoteSubmit(w, r, cat, options)
+		s.handleVoteSubmit(w, r, cat, options, widget)
 		return
 	}
 
-	// Build ranks slice for ranked voting
-	var ranks []int
-	maxRank := int64(3)
-	if cat.MaxRank.Valid {
-		maxRank = cat.MaxRank.Int64
-	}
-	if cat.VoteType == "ranked" {
-		ranks = make([]int, maxRank)
+	data := newVotePage(cat, options, widget)
+	data.IdempotencyKey = newIdempotencyKey()
+	if !widget {
+		data.Viewer = s.viewer(r, &cat)
+		if data.Wall, err = s.loadVotedWall(r.Context(), cat); err != nil {
+			s.renderError(w, "Failed to load voters", err)
+			return
+		}
 	}
+	s.render(w, page, data)
+}
 
-	s.render(w, "vote.html", map[string]any{
-		"Category": cat,
-		"Options":  options,
-		"Ranks":    ranks,
-		"MaxRank":  maxRank,
-	})
+// categoryByRef looks up the poll a voter URL names by ID or slug, writing
+// the error page when there isn't one. An unknown slug is a 404 that can
+// suggest polls by name.
+func (s *Server) categoryByRef(w http.ResponseWriter, r *http.Request, ref string) (db.Category, bool) {
+	cat, err := s.queries.CategoryByRef(r.Context(), ref)
+	if err != nil {
+		_, notID := strconv.ParseInt(ref, 10, 64)
+		if notID != nil && errors.Is(err, db.ErrNotFound) {
+			s.notFound(w, r)
+		} else {
+			s.renderError(w, "Category not found", err)
+		}
+		return cat, false
+	}
+	return cat, true
 }
 
 func (s *Server) handleVoteSubmit(w http.ResponseWriter, r *http.Request,
-	cat db.Category, options []db.Option) {
+	cat db.Category, options []db.Option, widget bool) {
 
 	r.ParseForm()
 
-	// Helper to build vote form data with error
-	maxRank := int64(3)
-	if cat.MaxRank.Valid {
-		maxRank = cat.MaxRank.Int64
-	}
-	var ranks []int
-	if cat.VoteType == "ranked" {
-		ranks = make([]int, maxRank)
+	maxRank := maxRankFor(cat)
+
+	// The key is only claimed once a ballot is accepted, so a form re-rendered
+	// with an error keeps it for the corrected submission.
+	idempotencyKey := r.FormValue(idempotencyKeyField)
+	if idempotencyKey == "" {
+		idempotencyKey = newIdempotencyKey()
 	}
 
-	renderVoteError := func(nickname, errMsg string) {
-		data := map[string]any{
-			"Category": cat,
-			"Options":  options,
-			"Nickname": nickname,
-			"Ranks":    ranks,
-			"MaxRank":  maxRank,
-			"Error":    errMsg,
-		}
-		if s.isHTMX(r) {
+	renderVoteForm := func(data VotePageData) {
+		switch {
+		case s.isHTMX(r):
 			s.renderPartial(w, "partials/vote-form.html", data)
-		} else {
+		case widget:
+			s.render(w, "widget.html", data)
+		default:
+			data.Viewer = s.viewer(r, &cat)
+			wall, err := s.loadVotedWall(r.Context(), cat)
+			if err != nil {
+				s.renderError(w, "Failed to load voters", err)
+				return
+			}
+			data.Wall = wall
 			s.render(w, "vote.html", data)
 		}
 	}
 
-	nickname := strings.TrimSpace(r.FormValue("nickname"))
+	renderVoteError := func(nickname, errMsg string) {
+		data := newVotePage(cat, options, widget)
+		data.Nickname, data.Error, data.IdempotencyKey = nickname, errMsg, idempotencyKey
+		renderVoteForm(data)
+	}
+
+	renderVoteSuccess := func(nickname string) {
+		data := newVotePage(cat, nil, widget)
+		data.Success = "Vote recorded! Thank you, " + nickname
+		data.Receipt, data.Held = s.receiptFor(r.Context(), cat, nickname)
+		renderVoteForm(data)
+	}
+
+	nickname := normalizeNickname(r.FormValue("nickname"))
 	if nickname == "" {
 		renderVoteError("", "Please enter a nickname")
 		return
 	}
-	nickname = strings.ToLower(nickname)
-
-	// Collect selections based on vote type
-	type selection struct {
-		OptionID int64
-		Rank     sql.NullInt64
-	}
-	var selections []selection
 
-	switch cat.VoteType {
-	case "single":
-		choiceStr := r.FormValue("choice")
-		if choiceStr == "" {
-			renderVoteError(nickname, "Please make a selection")
-			return
+	// A retried or double-clicked submission gets the original response
+	if s.replayedBallot(r.Context(), cat, r.FormValue(idempotencyKeyField), nickname) {
+		if s.isHTMX(r) {
+			showToast(w, toastInfo, "This ballot was already recorded")
 		}
-		optID, _ := strconv.ParseInt(choiceStr, 10, 64)
-		selections = append(selections, selection{OptionID: optID})
+		renderVoteSuccess(nickname)
+		return
+	}
 
-	case "approval":
-		choices := r.Form["choice"]
-		if len(choices) == 0 {
-			renderVoteError(nickname, "Please make at least one selection")
-			return
-		}
-		for _, c := range choices {
+	// Collect choices. Ranked forms post either one select per rank or, from
+	// the keyboard-orderable list, every option in preference order.
+	var choices []int64
+	if ranking := r.Form["ranking"]; cat.VoteType == "ranked" && len(ranking) > 0 {
+		for _, c := range ranking[:min(int64(len(ranking)), maxRank)] {
 			optID, _ := strconv.ParseInt(c, 10, 64)
-			selections = append(selections, selection{OptionID: optID})
+			choices = append(choices, optID)
 		}
-
-	case "ranked":
-		seen := make(map[int64]bool)
+	} else if cat.VoteType == "ranked" {
 		for i := int64(1); i <= maxRank; i++ {
-			val := r.FormValue(fmt.Sprintf("rank%d", i))
-			if val == "" {
-				continue
-			}
-			optID, _ := strconv.ParseInt(val, 10, 64)
-			if seen[optID] {
-				renderVoteError(nickname, "Each choice must be different")
-				return
-			}
-			seen[optID] = true
-			selections = append(selections, selection{
-				OptionID: optID,
-				Rank:     sql.NullInt64{Int64: i, Valid: true},
-			})
+			optID, _ := strconv.ParseInt(r.FormValue(fmt.Sprintf("rank%d", i)), 10, 64)
+			choices = append(choices, optID)
 		}
-		if len(selections) == 0 {
-			renderVoteError(nickname, "Please make at least one selection")
-			return
+	} else {
+		for _, c := range r.Form["choice"] {
+			optID, _ := strconv.ParseInt(c, 10, 64)
+			choices = append(choices, optID)
 		}
 	}
 
-	// Start transaction
-	tx, err := s.db.Begin()
-	if err != nil {
-		s.renderError(w, "Database error", err)
+	selections, errMsg := ballot{Nickname: nickname, Choices: choices}.selections(cat, options)
+	if errMsg == "" {
+		errMsg = pluginRefusal(r.Context(), cat, nickname, options, selections)
+	}
+	if errMsg != "" {
+		renderVoteError(nickname, errMsg)
 		return
 	}
-	defer tx.Rollback()
-
-	qtx := s.queries.WithTx(tx)
 
-	// Upsert vote
-	vote, err := qtx.UpsertVote(r.Context(), db.UpsertVoteParams{
-		CategoryID: cat.ID,
-		Nickname:   nickname,
-	})
+	remote, err := s.ballotOrigin(r, cat)
 	if err != nil {
-		s.renderError(w, "Failed to save vote", err)
+		renderVoteError(nickname, err.Error())
 		return
 	}
-
-	// Clear old selections
-	err = qtx.DeleteVoteSelections(r.Context(), vote.ID)
+	origin, err := s.requestOrigin(r)
 	if err != nil {
-		s.renderError(w, "Failed to update vote", err)
+		renderVoteError(nickname, err.Error())
 		return
 	}
-
-	// Insert new selections
-	for _, sel := range selections {
-		err = qtx.CreateVoteSelection(r.Context(), db.CreateVoteSelectionParams{
-			VoteID:   vote.ID,
-			OptionID: sel.OptionID,
-			Rank:     sel.Rank,
-		})
-		if err != nil {
-			s.renderError(w, "Failed to save selection", err)
-			return
-		}
+	refused, err := s.ineligible(r.Context(), cat, nickname)
+	if err != nil {
+		s.renderActionError(w, r, "Failed to check who can vote", err)
+		return
 	}
-
-	if err := tx.Commit(); err != nil {
-		s.renderError(w, "Failed to save vote", err)
+	if refused != "" {
+		renderVoteError(nickname, refused)
 		return
 	}
 
-	data := map[string]any{
-		"Category": cat,
-		"Success":  "Vote recorded! Thank you, " + nickname,
+	err = s.castBallot(r.Context(), pendingBallot{
+		cat:            cat,
+		nickname:       nickname,
+		selections:     selections,
+		idempotencyKey: r.FormValue(idempotencyKeyField),
+		remote:         remote,
+		origin:         origin,
+		test:           cat.Testing(),
+	})
+	if errors.Is(err, db.ErrClosed) {
+		s.renderActionError(w, r, "Voting closed before your vote was saved", err)
+		return
+	}
+	if errors.Is(err, errIdempotencyKeyReused) {
+		// The form's key went to another ballot; a fresh one lets this
+		// one through when sent again
+		idempotencyKey = newIdempotencyKey()
+		renderVoteError(nickname, "This ballot form was already used for a different vote. Check your choices and vote again.")
+		return
+	}
+	if err != nil && !errors.Is(err, errBallotReplayed) {
+		s.renderActionError(w, r, "Failed to save vote", err)
+		return
 	}
 
 	if s.isHTMX(r) {
-		s.renderPartial(w, "partials/vote-form.html", data)
-	} else {
-		s.render(w, "vote.html", data)
+		showToast(w, toastSuccess, "Vote recorded for "+cat.Name)
 	}
+	renderVoteSuccess(nickname)
 }
 
 func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
@@ -390,177 +666,61 @@ func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Remove leading slash for ID parsing
+	// Remove leading slash to leave the ID or slug
 	path = strings.TrimPrefix(path, "/")
 
-	// Check for /results/{id}/table
-	if strings.HasSuffix(path, "/table") {
-		idStr := strings.TrimSuffix(path, "/table")
-		id, err := strconv.ParseInt(idStr, 10, 64)
-		if err != nil {
-			http.NotFound(w, r)
-			return
-		}
-		s.handleResultsTable(w, r, id)
+	// Check for /results/{ref}/table
+	if ref, ok := strings.CutSuffix(path, "/table"); ok {
+		s.handleResultsTable(w, r, ref)
 		return
 	}
 
-	// Regular results page /results/{id}
-	id, err := strconv.ParseInt(path, 10, 64)
-	if err != nil {
-		http.NotFound(w, r)
+	// Check for /results/{ref}/card.png
+	if ref, ok := strings.CutSuffix(path, "/card.png"); ok {
+		s.handleResultsCard(w, r, ref)
 		return
 	}
 
-	cat, err := s.queries.GetCategory(r.Context(), id)
-	if err != nil {
-		s.renderError(w, "Category not found", err)
+	// Regular results page /results/{ref}
+	cat, ok := s.categoryByRef(w, r, path)
+	if !ok {
 		return
 	}
 
 	// Check visibility
-	if cat.ShowResults == "after_close" && cat.Status != "closed" {
-		s.render(w, "results.html", map[string]any{
-			"Category":   cat,
-			"NotVisible": true,
+	if cat.ShowResults == "after_close" && !cat.Finished() {
+		s.render(w, "results.html", ResultsPageData{
+			Page:       Page{Title: cat.Name, Viewer: s.viewer(r, &cat), Skin: pageSkin(cat.Skin, cat.CustomCss)},
+			Category:   cat,
+			NotVisible: true,
 		})
 		return
 	}
 
-	totalVotes, _ := s.queries.CountVotesByCategory(r.Context(), id)
-
-	type Result struct {
-		OptionName string
-		VoteCount  int64
-		Percentage int64
-	}
-	var results []Result
-
-	if cat.VoteType == "ranked" {
-		maxRank := sql.NullInt64{Int64: 3, Valid: true}
-		if cat.MaxRank.Valid {
-			maxRank = cat.MaxRank
-		}
-		rows, err := s.queries.TallyRanked(r.Context(), db.TallyRankedParams{
-			MaxRank:    maxRank,
-			CategoryID: id,
-		})
-		if err != nil {
-			s.renderError(w, "Failed to tally results", err)
-			return
-		}
-		for _, row := range rows {
-			// Points is interface{} due to COALESCE
-			points := int64(0)
-			if row.Points != nil {
-				switch v := row.Points.(type) {
-				case int64:
-					points = v
-				case float64:
-					points = int64(v)
-				}
-			}
-			percentage := int64(0)
-			if totalVotes > 0 {
-				percentage = (points * 100) / (totalVotes * maxRank.Int64)
-			}
-			results = append(results, Result{
-				OptionName: row.Name,
-				VoteCount:  points,
-				Percentage: percentage,
-			})
-		}
-	} else {
-		rows, err := s.queries.TallySimple(r.Context(), id)
-		if err != nil {
-			s.renderError(w, "Failed to tally results", err)
-			return
-		}
-		for _, row := range rows {
-			percentage := int64(0)
-			if totalVotes > 0 {
-				percentage = (row.Votes * 100) / totalVotes
-			}
-			results = append(results, Result{
-				OptionName: row.Name,
-				VoteCount:  row.Votes,
-				Percentage: percentage,
-			})
-		}
+	original, runoff := s.runoffLinks(r.Context(), cat)
+	if s.cacheFinishedResults(w, r, cat, original, runoff) {
+		return
 	}
 
-	s.render(w, "results.html", map[string]any{
-		"Category":   cat,
-		"TotalVotes": totalVotes,
-		"Results":    results,
-	})
-}
-
-func (s *Server) handleResultsTable(w http.ResponseWriter, r *http.Request, id int64) {
-	cat, err := s.queries.GetCategory(r.Context(), id)
+	voteCount, results, version, err := s.versionedTally(r.Context(), cat)
 	if err != nil {
-		http.Error(w, "Not found", http.StatusNotFound)
+		s.renderError(w, "Failed to tally results", err)
 		return
 	}
 
-	voteCount, _ := s.queries.CountVotesByCategory(r.Context(), id)
-
-	type Result struct {
-		Name       string
-		Votes      int64
-		Points     int64
-		FirstPlace int64
+	data := ResultsPageData{
+		Page:      Page{Title: cat.Name, Viewer: s.viewer(r, &cat), Skin: pageSkin(cat.Skin, cat.CustomCss)},
+		Category:  cat,
+		VoteCount: voteCount,
+		Results:   results,
+		Version:   version,
+		RunoffOf:  original,
+		Runoff:    runoff,
 	}
-	var results []Result
-
-	if cat.VoteType == "ranked" {
-		maxRank := sql.NullInt64{Int64: 3, Valid: true}
-		if cat.MaxRank.Valid {
-			maxRank = cat.MaxRank
-		}
-		rows, err := s.queries.TallyRanked(r.Context(), db.TallyRankedParams{
-			MaxRank:    maxRank,
-			CategoryID: id,
-		})
-		if err != nil {
-			http.Error(w, "Error", http.StatusInternalServerError)
-			return
-		}
-		for _, row := range rows {
-			points := int64(0)
-			if row.Points != nil {
-				switch v := row.Points.(type) {
-				case int64:
-					points = v
-				case float64:
-					points = int64(v)
-				}
-			}
-			results = append(results, Result{
-				Name:       row.Name,
-				Points:     points,
-				FirstPlace: row.FirstPlaceVotes,
-			})
-		}
-	} else {
-		rows, err := s.queries.TallySimple(r.Context(), id)
-		if err != nil {
-			http.Error(w, "Error", http.StatusInternalServerError)
-			return
-		}
-		for _, row := range rows {
-			results = append(results, Result{
-				Name:  row.Name,
-				Votes: row.Votes,
-			})
-		}
+	if cat.Finished() {
+		data.Margin = s.winningMargin(cat, voteCount, results)
 	}
-
-	s.renderPartial(w, "partials/results-table.html", map[string]any{
-		"Category":  cat,
-		"VoteCount": voteCount,
-		"Results":   results,
-	})
+	s.render(w, "results.html", data)
 }
 
 func (s *Server) handleResultsList(w http.ResponseWriter, r *http.Request) {
@@ -571,14 +731,15 @@ func (s *Server) handleResultsList(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.render(w, "results-list.html", map[string]any{
-		"Categories": categories,
+		"Categories":  categories,
+		"Leaderboard": s.settingBool(db.SettingLeaderboard),
+		"Season":      s.setting(db.SettingSeason),
 	})
 }
 
 func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
 	// Basic auth check
-	user, pass, ok := r.BasicAuth()
-	if !ok || user != "admin" || pass != s.adminPassword {
+	if !s.authorized(r) {
 		w.Header().Set("WWW-Authenticate", `Basic realm="Admin"`)
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
@@ -590,12 +751,82 @@ func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
 	switch {
 	case path == "/admin" || path == "/admin/":
 		s.handleAdminDashboard(w, r)
+	case path == "/admin/activity":
+		s.handleAdminActivity(w, r)
+	case path == "/admin/high-contrast":
+		s.handleAdminHighContrast(w, r)
+	case path == "/admin/settings":
+		s.handleAdminSettings(w, r)
+	case path == "/admin/search":
+		s.handleAdminSearch(w, r)
+	case path == "/admin/links":
+		s.handleAdminLinks(w, r)
+	case path == "/admin/voter-view":
+		s.handleAdminVoterView(w, r)
+	case path == "/admin/ceremony":
+		s.handleAdminCeremony(w, r)
+	case path == "/admin/ceremony/celebrate":
+		s.handleAdminCelebrate(w, r)
+	case path == "/admin/ceremony/show":
+		s.handleAdminCeremonyShow(w, r)
+	case path == "/admin/ceremony/reveal":
+		s.handleAdminCeremonyReveal(w, r)
+	case path == "/admin/ceremony/next":
+		s.handleAdminCeremonyNext(w, r)
+	case path == "/admin/ceremony/countdown":
+		s.handleAdminCeremonyCountdown(w, r)
+	case path == "/admin/sounds":
+		s.handleAdminSounds(w, r)
+	case path == "/admin/sounds/delete":
+		s.handleAdminDeleteSound(w, r)
+	case path == "/admin/import":
+		s.handleAdminImport(w, r)
+	case path == "/admin/leaderboard.csv":
+		s.handleAdminLeaderboardExport(w, r)
+	case path == "/admin/ballots.csv":
+		s.handleAdminBallotsExport(w, r, "csv")
+	case path == "/admin/ballots.json":
+		s.handleAdminBallotsExport(w, r, "json")
+	case path == "/admin/voters/forget":
+		s.handleAdminForgetVoter(w, r)
+	case path == "/admin/stations":
+		s.handleAdminStations(w, r)
+	case path == "/admin/stations/report":
+		s.handleAdminStationReport(w, r)
+	case strings.HasPrefix(path, "/admin/stations/"):
+		s.handleAdminStation(w, r)
+	case strings.HasPrefix(path, "/admin/conflicts/"):
+		s.handleAdminConflict(w, r)
+	case path == "/admin/broadcast":
+		s.handleAdminBroadcast(w, r)
+	case path == "/admin/seatmap":
+		s.handleAdminSeatmap(w, r)
+	case path == "/admin/quick":
+		s.handleAdminQuick(w, r)
+	case path == "/admin/observers":
+		s.handleAdminObservers(w, r)
+	case path == "/admin/observers/revoke":
+		s.handleAdminObserversRevoke(w, r)
+	case path == "/admin/season":
+		s.handleAdminSeason(w, r)
+	case strings.HasPrefix(path, "/admin/season/"):
+		s.handleAdminSeasonRemove(w, r)
+	case path == "/admin/roster":
+		s.handleAdminRoster(w, r)
+	case strings.HasPrefix(path, "/admin/roster/"):
+		s.handleAdminRosterTags(w, r)
 	case strings.HasPrefix(path, "/admin/category/"):
 		s.handleAdminCategory(w, r)
+	case strings.HasPrefix(path, "/admin/option/") && strings.HasSuffix(path, "/retire"):
+		s.handleAdminRetireOption(w, r)
+	case strings.HasPrefix(path, "/admin/option/") && strings.HasSuffix(path, "/image"):
+		s.handleAdminOptionImage(w, r)
+	case strings.HasPrefix(path, "/admin/option/") && strings.HasSuffix(path, "/details"):
+		s.handleAdminOptionDetails(w, r)
 	case strings.HasPrefix(path, "/admin/option/"):
 		s.handleAdminDeleteOption(w, r)
 	default:
-		http.NotFound(w, r)
+		s.notFound(w, r)
 	}
 }
 
@@ -606,11 +837,165 @@ func (s *Server) handleAdminDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.render(w, "admin/dashboard.html", map[string]any{
-		"Categories": categories,
+	stats, err := s.loadPollStats(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load vote counts", err)
+		return
+	}
+
+	warnings, err := s.reads.Lint(r.Context(), time.Now())
+	if err != nil {
+		s.renderError(w, "Failed to check polls", err)
+		return
+	}
+
+	activity, err := s.loadActivity(r)
+	if err != nil {
+		s.renderError(w, "Failed to load activity", err)
+		return
+	}
+
+	s.render(w, "admin/dashboard.html", AdminDashboardData{
+		Categories:   categories,
+		Stats:        stats,
+		Warnings:     warnings,
+		Activity:     activity,
+		HighContrast: s.settingBool(db.SettingHighContrast),
 	})
 }
 
+// handleAdminActivity serves the dashboard activity sidebar for htmx polling
+func (s *Server) handleAdminActivity(w http.ResponseWriter, r *http.Request) {
+	activity, err := s.loadActivity(r)
+	if err != nil {
+		log.Printf("Failed to load activity: %v", err)
+		http.Error(w, "Failed to load activity", http.StatusInternalServerError)
+		return
+	}
+
+	s.renderPartial(w, "partials/activity-feed.html", activity)
+}
+
+// handleAdminHighContrast toggles the high-contrast theme for every page
+func (s *Server) handleAdminHighContrast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	if err := s.saveSetting(r, db.SettingHighContrast, r.FormValue("enabled")); err != nil {
+		s.renderError(w, "Failed to save setting", err)
+		return
+	}
+
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// handleAdminForgetVoter removes every ballot a voter has cast and anonymizes
+// their audit trail, all in one transaction.
+func (s *Server) handleAdminForgetVoter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	nickname := normalizeNickname(r.FormValue("nickname"))
+	if nickname == "" {
+		if s.isHTMX(r) {
+			s.htmxError(w, http.StatusBadRequest, "Nickname is required")
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		s.render(w, "error.html", map[string]any{
+			"Message": "Nickname is required",
+		})
+		return
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.renderActionError(w, r, "Database error", err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	ballots, err := qtx.ForgetVoter(r.Context(), s.nicknames.Seal(nickname))
+	if err != nil {
+		s.renderActionError(w, r, "Failed to forget voter", err)
+		return
+	}
+
+	// The nickname itself is deliberately left out of the audit detail
+	detail := fmt.Sprintf("%d ballots", ballots)
+	if err := qtx.RecordAudit(r.Context(), db.ActorAdmin, db.AuditVoterForget, 0, detail); err != nil {
+		s.renderActionError(w, r, "Failed to forget voter", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.renderActionError(w, r, "Failed to forget voter", err)
+		return
+	}
+
+	if s.isHTMX(r) {
+		showToast(w, toastSuccess, fmt.Sprintf("Forgot %s: %d ballot(s) deleted", nickname, ballots))
+		return
+	}
+
+	http.Redirect(w, r, AdminURL(), http.StatusSeeOther)
+}
+
+// loadActivity gathers recent audit log data for the dashboard sidebar
+// loadPollStats counts every poll's ballots and finds its latest vote in
+// two queries, however many polls the dashboard lists
+func (s *Server) loadPollStats(ctx context.Context) (map[int64]PollStats, error) {
+	counts, err := s.reads.ListCategoryVoteStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := s.reads.ListLatestVoteEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[int64]PollStats, len(counts))
+	for _, c := range counts {
+		stats[c.CategoryID] = PollStats{Votes: c.Votes, RemoteVotes: c.RemoteVotes}
+	}
+	for _, e := range latest {
+		st := stats[e.CategoryID.Int64]
+		st.LastVoteAt = e.CreatedAt
+		stats[e.CategoryID.Int64] = st
+	}
+	return stats, nil
+}
+
+func (s *Server) loadActivity(r *http.Request) (ActivityData, error) {
+	votesPerMinute, err := s.queries.ListVotesPerMinute(r.Context())
+	if err != nil {
+		return ActivityData{}, err
+	}
+
+	actions, err := s.queries.ListRecentAdminActions(r.Context(), 10)
+	if err != nil {
+		return ActivityData{}, err
+	}
+
+	// Scale bars against the busiest minute in the window
+	var peak int64
+	for _, m := range votesPerMinute {
+		peak = max(peak, m.Votes)
+	}
+
+	return ActivityData{
+		VotesPerMinute: votesPerMinute,
+		PeakVotes:      peak,
+		Actions:        actions,
+	}, nil
+}
+
 func (s *Server) handleAdminCategory(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 
@@ -623,13 +1008,13 @@ func (s *Server) handleAdminCategory(w http.ResponseWriter, r *http.Request) {
 	// Extract category ID and action
 	parts := strings.Split(strings.TrimPrefix(path, "/admin/category/"), "/")
 	if len(parts) == 0 {
-		http.NotFound(w, r)
+		s.notFound(w, r)
 		return
 	}
 
 	id, err := strconv.ParseInt(parts[0], 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		s.notFound(w, r)
 		return
 	}
 
@@ -647,6 +1032,20 @@ func (s *Server) handleAdminCategory(w http.ResponseWriter, r *http.Request) {
 		s.handleAdminReopen(w, r, id)
 	case "archive":
 		s.handleAdminArchive(w, r, id)
+	case "seed":
+		s.handleAdminSeed(w, r, id)
+	case "runoff":
+		s.handleAdminRunoff(w, r, id)
+	case "test":
+		s.handleAdminTestMode(w, r, id)
+	case "preview":
+		s.handleAdminPreview(w, r, id)
+	case "groups":
+		s.handleAdminGroups(w, r, id)
+	case "window":
+		s.handleAdminWindow(w, r, id)
+	case "ballots":
+		s.handleAdminBallots(w, r, id)
 	case "option":
 		s.handleAdminAddOption(w, r, id)
 	default:
@@ -657,225 +1056,408 @@ func (s *Server) handleAdminCategory(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleAdminCategoryNew(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
 		r.ParseForm()
-		name := r.FormValue("name")
-		voteType := r.FormValue("vote_type")
-		showResults := r.FormValue("show_results")
-		maxRankStr := r.FormValue("max_rank")
-
-		var maxRank sql.NullInt64
-		if voteType == "ranked" {
-			mr, _ := strconv.ParseInt(maxRankStr, 10, 64)
-			if mr <= 0 {
-				mr = 3
-			}
-			maxRank = sql.NullInt64{Int64: mr, Valid: true}
+		settings := categorySettingsFromForm(r)
+
+		err := settings.Normalize()
+		if err == nil {
+			err = settings.CheckDependency(r.Context(), s.queries, 0)
+		}
+		if err == nil {
+			err = settings.AssignSlug(r.Context(), s.queries, 0)
+		}
+		if err != nil {
+			s.renderCategory(w, r, map[string]any{
+				"Error": err.Error(),
+			})
+			return
 		}
 
-		cat, err := s.queries.CreateCategory(r.Context(), db.CreateCategoryParams{
-			Name:        name,
-			VoteType:    voteType,
-			Status:      "draft",
-			ShowResults: showResults,
-			MaxRank:     maxRank,
-		})
+		cat, err := s.queries.CreateCategory(r.Context(), settings.CreateParams())
 		if err != nil {
-			s.render(w, "admin/category.html", map[string]any{
+			s.renderCategory(w, r, map[string]any{
 				"Error": "Failed to create category",
 			})
 			return
 		}
+		s.audit(r, db.AuditCategoryCreate, cat.ID, cat.Name)
 		http.Redirect(w, r, AdminCategoryURL(cat.ID), http.StatusSeeOther)
 		return
 	}
 
-	s.render(w, "admin/category.html", nil)
+	s.renderCategory(w, r, map[string]any{})
+}
+
+// closesAtLayout is the value format of a datetime-local input
+const closesAtLayout = "2006-01-02T15:04"
+
+// parseLocalTime reads a datetime-local input's value in loc. Browsers
+// without datetime-local show a text box; accept a space there.
+func parseLocalTime(value string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation(closesAtLayout, strings.Replace(strings.TrimSpace(value), " ", "T", 1), loc)
+}
+
+// categorySettingsFromForm reads the create/edit category form. An
+// unparseable max_rank falls back to the default, and an unparseable
+// depends_on, seed_top_n, opens_at or closes_at to none.
+func categorySettingsFromForm(r *http.Request) CategorySettings {
+	maxRank, _ := strconv.ParseInt(r.FormValue("max_rank"), 10, 64)
+	dependsOn, _ := strconv.ParseInt(r.FormValue("depends_on"), 10, 64)
+	seedTopN, _ := strconv.ParseInt(r.FormValue("seed_top_n"), 10, 64)
+	judgeWeight, _ := strconv.ParseInt(r.FormValue("judge_weight"), 10, 64)
+	plannedTime := func(field string) time.Time {
+		t, _ := parseLocalTime(r.FormValue(field), time.Local)
+		return t
+	}
+	return CategorySettings{
+		Name:        r.FormValue("name"),
+		VoteType:    r.FormValue("vote_type"),
+		ShowResults: r.FormValue("show_results"),
+		MaxRank:     maxRank,
+		Color:       r.FormValue("color"),
+		Icon:        r.FormValue("icon"),
+		DependsOn:   dependsOn,
+		SeedTopN:    seedTopN,
+		OpensAt:     plannedTime("opens_at"),
+		ClosesAt:    plannedTime("closes_at"),
+		Slug:        r.FormValue("slug"),
+		Skin:        r.FormValue("skin"),
+		CustomCSS:   r.FormValue("custom_css"),
+		RevealSound: r.FormValue("reveal_sound"),
+		VotedWall:   r.FormValue("voted_wall"),
+		Eligibility: r.FormValue("eligibility"),
+		Weights:     r.FormValue("weights"),
+		Method:      strings.TrimSpace(r.FormValue("method")),
+		Judges:      r.FormValue("judges"),
+		JudgeWeight: judgeWeight,
+	}
+}
+
+// renderCategory renders the create/edit category page, adding the polls
+// the category can be set to open after and the closed ones it can be seeded
+// from
+func (s *Server) renderCategory(w http.ResponseWriter, r *http.Request, data map[string]any) {
+	var self int64
+	if cat, ok := data["Category"].(db.Category); ok {
+		self = cat.ID
+	}
+	polls, err := s.queries.ListCategoriesExcludeArchived(r.Context())
+	if err != nil {
+		log.Printf("Failed to list polls: %v", err)
+	}
+	polls = slices.DeleteFunc(polls, func(c db.Category) bool { return c.ID == self })
+
+	var sources []db.Category
+	for _, c := range polls {
+		if c.Finished() {
+			sources = append(sources, c)
+		}
+	}
+	data["Polls"], data["SeedSources"] = polls, sources
+
+	sounds, err := s.listSounds()
+	if err != nil {
+		log.Printf("Failed to list sounds: %v", err)
+	}
+	data["Sounds"] = sounds
+	data["Methods"] = tally.MethodNames()
+
+	if cat, ok := data["Category"].(db.Category); ok {
+		maps.Copy(data, s.runoffData(r.Context(), cat))
+		data["WidgetURL"] = absoluteURL(r, VoteWidgetURL(cat.ID))
+	}
+	s.render(w, "admin/category.html", data)
+}
+
+// categoryError reports a category action that can't go ahead: as a toast
+// to HTMX, otherwise on the category page
+func (s *Server) categoryError(w http.ResponseWriter, r *http.Request, cat db.Category, short, message string) {
+	if s.isHTMX(r) {
+		s.htmxError(w, http.StatusBadRequest, short)
+		return
+	}
+	options, _ := s.queries.ListOptionVotesByCategory(r.Context(), cat.ID)
+	s.renderCategory(w, r, map[string]any{
+		"Category": cat,
+		"Options":  options,
+		"Error":    message,
+	})
+}
+
+// seedDependents copies cat's top options into the draft polls that open
+// after it, now that it has closed, and records each in the audit log.
+// Closing stands even if seeding fails.
+func (s *Server) seedDependents(r *http.Request, cat db.Category) {
+	seeded, err := s.queries.SeedDependents(r.Context(), cat)
+	if err != nil {
+		log.Printf("Failed to seed polls that open after %d: %v", cat.ID, err)
+	}
+	for _, p := range seeded {
+		for _, o := range p.Options {
+			s.audit(r, db.AuditOptionSeed, p.Category.ID, fmt.Sprintf("%s (from %s)", o.Name, cat.Name))
+		}
+	}
 }
 
 func (s *Server) handleAdminCategoryEdit(w http.ResponseWriter, r *http.Request, id int64) {
-	cat, err := s.queries.GetCategory(r.Context(), id)
+	cat, err := s.queries.Category(r.Context(), id)
 	if err != nil {
-		http.NotFound(w, r)
+		s.lookupFailed(w, r, "poll", err)
 		return
 	}
 
-	options, _ := s.queries.ListOptionsByCategory(r.Context(), id)
+	options, _ := s.queries.ListOptionVotesByCategory(r.Context(), id)
 
 	if r.Method == http.MethodPost {
 		r.ParseForm()
-		name := strings.TrimSpace(r.FormValue("name"))
-		voteType := r.FormValue("vote_type")
-		showResults := r.FormValue("show_results")
-		maxRankStr := r.FormValue("max_rank")
+		settings := categorySettingsFromForm(r)
 
-		if name == "" {
-			s.render(w, "admin/category.html", map[string]any{
+		err := settings.Normalize()
+		if err == nil {
+			err = settings.CheckDependency(r.Context(), s.queries, id)
+		}
+		if err == nil {
+			err = settings.AssignSlug(r.Context(), s.queries, id)
+		}
+		if err != nil {
+			s.renderCategory(w, r, map[string]any{
 				"Category": cat,
 				"Options":  options,
-				"Error":    "Name is required",
+				"Error":    err.Error(),
 			})
 			return
 		}
 
-		var maxRank sql.NullInt64
-		if voteType == "ranked" {
-			mr, _ := strconv.ParseInt(maxRankStr, 10, 64)
-			if mr <= 0 {
-				mr = 3
-			}
-			maxRank = sql.NullInt64{Int64: mr, Valid: true}
-		}
-
-		err := s.queries.UpdateCategory(r.Context(), db.UpdateCategoryParams{
-			Name:        name,
-			VoteType:    voteType,
-			ShowResults: showResults,
-			MaxRank:     maxRank,
-			ID:          id,
-		})
+		err = s.queries.UpdateCategory(r.Context(), settings.UpdateParams(id))
 		if err != nil {
-			s.render(w, "admin/category.html", map[string]any{
+			s.renderCategory(w, r, map[string]any{
 				"Category": cat,
 				"Options":  options,
 				"Error":    "Failed to update category",
 			})
 			return
 		}
+		s.audit(r, db.AuditCategoryUpdate, id, settings.Name)
 
 		http.Redirect(w, r, AdminURL(), http.StatusSeeOther)
 		return
 	}
 
-	s.render(w, "admin/category.html", map[string]any{
-		"Category": cat,
-		"Options":  options,
+	churn, history, err := s.loadVoteHistory(r.Context(), id)
+	if err != nil {
+		s.renderError(w, "Failed to load vote history", err)
+		return
+	}
+	ballots, remote, err := s.loadBallots(r.Context(), id)
+	if err != nil {
+		s.renderError(w, "Failed to load ballots", err)
+		return
+	}
+	conflicts, err := s.loadConflicts(r.Context(), id)
+	if err != nil {
+		s.renderError(w, "Failed to load vote conflicts", err)
+		return
+	}
+
+	s.renderCategory(w, r, map[string]any{
+		"Category":      cat,
+		"Options":       options,
+		"Churn":         churn,
+		"History":       history,
+		"Ballots":       ballots,
+		"RemoteBallots": remote,
+		"Conflicts":     conflicts,
 	})
 }
 
 func (s *Server) handleAdminOpen(w http.ResponseWriter, r *http.Request, id int64) {
 	if r.Method != http.MethodPost {
-		http.NotFound(w, r)
+		s.notFound(w, r)
 		return
 	}
 
-	count, _ := s.queries.CountOptionsByCategory(r.Context(), id)
-	if count == 0 {
-		if s.isHTMX(r) {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("Add options first"))
-			return
-		}
-		cat, _ := s.queries.GetCategory(r.Context(), id)
-		options, _ := s.queries.ListOptionsByCategory(r.Context(), id)
-		s.render(w, "admin/category.html", map[string]any{
-			"Category": cat,
-			"Options":  options,
-			"Error":    "Cannot open voting: add at least one option first",
-		})
+	cat, err := db.OpenCategory(r.Context(), s.db, id, db.ActorAdmin)
+	if err != nil {
+		s.transitionFailed(w, r, id, "Cannot open voting", "Failed to open category", err)
 		return
 	}
-
-	s.queries.UpdateCategoryStatus(r.Context(), db.UpdateCategoryStatusParams{
-		Status: "open",
-		ID:     id,
-	})
+	s.announce(id, notify.EventOpened)
 
 	if s.isHTMX(r) {
-		cat, _ := s.queries.GetCategory(r.Context(), id)
+		showToast(w, toastSuccess, "Voting is open for "+cat.Name)
 		s.renderPartial(w, "partials/status-badge.html", cat)
 		return
 	}
 
-	http.Redirect(w, r, AdminURL(), http.StatusSeeOther)
+	http.Redirect(w, r, localNext(r, AdminURL()), http.StatusSeeOther)
 }
 
 func (s *Server) handleAdminClose(w http.ResponseWriter, r *http.Request, id int64) {
 	if r.Method != http.MethodPost {
-		http.NotFound(w, r)
+		s.notFound(w, r)
 		return
 	}
 
-	s.queries.UpdateCategoryStatus(r.Context(), db.UpdateCategoryStatusParams{
-		Status: "closed",
-		ID:     id,
-	})
+	cat, err := db.CloseCategory(r.Context(), s.db, id, db.ActorAdmin)
+	if err != nil {
+		s.transitionFailed(w, r, id, "Cannot close voting", "Failed to close category", err)
+		return
+	}
+	s.seedDependents(r, cat)
+	s.announce(id, notify.EventClosed, notify.EventResults)
 
 	if s.isHTMX(r) {
-		cat, _ := s.queries.GetCategory(r.Context(), id)
+		showToast(w, toastSuccess, "Closed "+cat.Name)
 		s.renderPartial(w, "partials/status-badge.html", cat)
 		return
 	}
 
-	http.Redirect(w, r, AdminURL(), http.StatusSeeOther)
+	http.Redirect(w, r, localNext(r, AdminURL()), http.StatusSeeOther)
 }
 
 func (s *Server) handleAdminReopen(w http.ResponseWriter, r *http.Request, id int64) {
 	if r.Method != http.MethodPost {
-		http.NotFound(w, r)
-		return
-	}
-
-	cat, err := s.queries.GetCategory(r.Context(), id)
-	if err != nil {
-		http.NotFound(w, r)
+		s.notFound(w, r)
 		return
 	}
 
-	// Verify poll is closed
-	if cat.Status != "closed" {
+	cat, err := db.ReopenCategory(r.Context(), s.db, id, db.ActorAdmin)
+	if errors.Is(err, db.ErrNotClosed) {
 		if s.isHTMX(r) {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("Poll must be closed to reopen"))
+			s.htmxError(w, http.StatusBadRequest, "Poll must be closed to reopen")
 			return
 		}
 		http.Redirect(w, r, AdminURL(), http.StatusSeeOther)
 		return
 	}
+	if err != nil {
+		s.transitionFailed(w, r, id, "Cannot reopen poll", "Failed to reopen category", err)
+		return
+	}
+	s.announce(id, notify.EventOpened)
 
-	// Validate poll has options
-	count, _ := s.queries.CountOptionsByCategory(r.Context(), id)
-	if count == 0 {
-		if s.isHTMX(r) {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("Add options first"))
-			return
-		}
-		options, _ := s.queries.ListOptionsByCategory(r.Context(), id)
-		s.render(w, "admin/category.html", map[string]any{
-			"Category": cat,
-			"Options":  options,
-			"Error":    "Cannot reopen poll: add at least one option first",
-		})
+	if s.isHTMX(r) {
+		showToast(w, toastSuccess, "Reopened "+cat.Name)
+		s.renderPartial(w, "partials/status-badge.html", cat)
 		return
 	}
 
-	s.queries.UpdateCategoryStatus(r.Context(), db.UpdateCategoryStatusParams{
-		Status: "open",
-		ID:     id,
-	})
+	http.Redirect(w, r, localNext(r, AdminURL()), http.StatusSeeOther)
+}
+
+// localNext is the page a form asked to go back to with its next field,
+// if that is a path on this site, otherwise fallback
+func localNext(r *http.Request, fallback string) string {
+	n := r.FormValue("next")
+	if strings.HasPrefix(n, "/") && !strings.HasPrefix(n, "//") && !strings.HasPrefix(n, "/\\") {
+		return n
+	}
+	return fallback
+}
+
+// transitionFailed answers an open, close or reopen that didn't happen: the
+// poll page explaining why for a poll that can't change state (prefixed by
+// refused), otherwise failed as an error
+func (s *Server) transitionFailed(w http.ResponseWriter, r *http.Request, id int64, refused, failed string, err error) {
+	var waiting *db.WaitingError
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		s.notFound(w, r)
+		return
+	case errors.Is(err, db.ErrNoOptions):
+		cat, _ := s.queries.Category(r.Context(), id)
+		s.categoryError(w, r, cat, "Add options first", refused+": add at least one option first")
+		return
+	case errors.Is(err, db.ErrStarted), errors.Is(err, db.ErrNotOpen):
+		cat, _ := s.queries.Category(r.Context(), id)
+		s.categoryError(w, r, cat, "Poll is "+cat.Status, fmt.Sprintf("%s: the poll is %s", refused, cat.Status))
+		return
+	case errors.As(err, &waiting):
+		cat, _ := s.queries.Category(r.Context(), id)
+		s.categoryError(w, r, cat, "Opens after "+waiting.Prev.Name,
+			fmt.Sprintf("%s: this poll opens after %s closes", refused, waiting.Prev.Name))
+		return
+	}
+	log.Printf("%s %d: %v", failed, id, err)
+	s.actionError(w, r, http.StatusInternalServerError, failed)
+}
+
+// handleAdminSeed copies the top options of a closed poll into a draft one,
+// e.g. to set up a runoff between the leaders
+func (s *Server) handleAdminSeed(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodPost {
+		s.notFound(w, r)
+		return
+	}
+
+	cat, err := s.queries.Category(r.Context(), id)
+	if err != nil {
+		s.lookupFailed(w, r, "poll", err)
+		return
+	}
+
+	sourceID, _ := strconv.ParseInt(r.FormValue("source_id"), 10, 64)
+	topN, _ := strconv.ParseInt(r.FormValue("top_n"), 10, 64)
+	source, err := s.queries.Category(r.Context(), sourceID)
+	if err != nil {
+		s.categoryError(w, r, cat, "Pick a poll", "Cannot seed options: pick a closed poll to seed from")
+		return
+	}
+	if topN < 1 {
+		s.categoryError(w, r, cat, "Seed at least 1", "Cannot seed options: seed at least one option")
+		return
+	}
+
+	added, err := s.queries.SeedFromResults(r.Context(), cat, source, topN)
+	for _, o := range added {
+		s.audit(r, db.AuditOptionSeed, id, fmt.Sprintf("%s (from %s)", o.Name, source.Name))
+	}
+	if errors.Is(err, db.ErrSeedTargetNotDraft) || errors.Is(err, db.ErrSeedSourceOpen) {
+		s.categoryError(w, r, cat, "Cannot seed", "Cannot seed options: "+err.Error())
+		return
+	}
+	if err != nil {
+		s.renderActionError(w, r, "Failed to seed options", err)
+		return
+	}
 
 	if s.isHTMX(r) {
-		cat, _ := s.queries.GetCategory(r.Context(), id)
-		s.renderPartial(w, "partials/status-badge.html", cat)
+		// Append the new rows to the options list
+		options, _ := s.queries.ListOptionVotesByCategory(r.Context(), id)
+		options = slices.DeleteFunc(options, func(o db.ListOptionVotesByCategoryRow) bool {
+			return !slices.ContainsFunc(added, func(a db.Option) bool { return a.ID == o.ID })
+		})
+		if len(added) == 0 {
+			showToast(w, toastInfo, "Every option from "+source.Name+" is already here")
+		} else {
+			names := make([]string, len(added))
+			for i, o := range added {
+				names[i] = o.Name
+			}
+			showToast(w, toastSuccess, "Seeded from "+source.Name+": "+strings.Join(names, ", "))
+		}
+		s.renderPartial(w, "partials/option-rows.html", options)
 		return
 	}
 
-	http.Redirect(w, r, AdminURL(), http.StatusSeeOther)
+	http.Redirect(w, r, AdminCategoryURL(id, "options"), http.StatusSeeOther)
 }
 
 func (s *Server) handleAdminArchive(w http.ResponseWriter, r *http.Request, id int64) {
 	if r.Method != http.MethodPost {
-		http.NotFound(w, r)
+		s.notFound(w, r)
 		return
 	}
 
 	if err := s.queries.ArchiveCategory(r.Context(), id); err != nil {
 		log.Printf("Failed to archive category %d: %v", id, err)
-		http.Error(w, "Failed to archive category", http.StatusInternalServerError)
+		s.actionError(w, r, http.StatusInternalServerError, "Failed to archive category")
 		return
 	}
+	s.audit(r, db.AuditCategoryArchive, id, "")
 
 	if s.isHTMX(r) {
-		cat, _ := s.queries.GetCategory(r.Context(), id)
+		cat, _ := s.queries.Category(r.Context(), id)
+		showToast(w, toastSuccess, "Archived "+cat.Name)
 		s.renderPartial(w, "partials/status-badge.html", cat)
 		return
 	}
@@ -883,9 +1465,35 @@ func (s *Server) handleAdminArchive(w http.ResponseWriter, r *http.Request, id i
 	http.Redirect(w, r, AdminURL(), http.StatusSeeOther)
 }
 
+// handleAdminTestMode turns test mode on (enabled=on) or off for a draft
+// poll, so admins can vote on it before it opens
+func (s *Server) handleAdminTestMode(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodPost {
+		s.notFound(w, r)
+		return
+	}
+
+	on := r.FormValue("enabled") == "on"
+	cat, err := s.queries.SetTestMode(r.Context(), id, on)
+	if errors.Is(err, db.ErrConflict) {
+		s.renderActionError(w, r, "Cannot change test mode: "+err.Error(), err)
+		return
+	}
+	if err != nil {
+		s.lookupFailed(w, r, "category", err)
+		return
+	}
+	detail := "off"
+	if on {
+		detail = "on"
+	}
+	s.audit(r, db.AuditTestMode, id, cat.Name+": "+detail)
+	http.Redirect(w, r, AdminCategoryURL(id, "ballots"), http.StatusSeeOther)
+}
+
 func (s *Server) handleAdminAddOption(w http.ResponseWriter, r *http.Request, categoryID int64) {
 	if r.Method != http.MethodPost {
-		http.NotFound(w, r)
+		s.notFound(w, r)
 		return
 	}
 
@@ -893,7 +1501,7 @@ func (s *Server) handleAdminAddOption(w http.ResponseWriter, r *http.Request, ca
 	name := strings.TrimSpace(r.FormValue("option_name"))
 	if name == "" {
 		if s.isHTMX(r) {
-			w.WriteHeader(http.StatusBadRequest)
+			s.htmxError(w, http.StatusBadRequest, "Enter a name for the option")
 			return
 		}
 		http.Redirect(w, r, AdminCategoryURL(categoryID), http.StatusSeeOther)
@@ -901,17 +1509,42 @@ func (s *Server) handleAdminAddOption(w http.ResponseWriter, r *http.Request, ca
 	}
 
 	count, _ := s.queries.CountOptionsByCategory(r.Context(), categoryID)
-	s.queries.CreateOption(r.Context(), db.CreateOptionParams{
+	_, err := s.queries.CreateOption(r.Context(), db.CreateOptionParams{
 		CategoryID: categoryID,
 		Name:       name,
 		SortOrder:  sql.NullInt64{Int64: count, Valid: true},
 	})
+	if err := db.Classify(err); errors.Is(err, db.ErrConflict) {
+		message := name + " is already an option"
+		if s.isHTMX(r) {
+			s.htmxError(w, http.StatusConflict, message)
+			return
+		}
+		cat, _ := s.queries.Category(r.Context(), categoryID)
+		options, _ := s.queries.ListOptionVotesByCategory(r.Context(), categoryID)
+		w.WriteHeader(http.StatusConflict)
+		s.renderCategory(w, r, map[string]any{
+			"Category": cat,
+			"Options":  options,
+			"Error":    message,
+		})
+		return
+	} else if err != nil {
+		log.Printf("Failed to add option to category %d: %v", categoryID, err)
+		if s.isHTMX(r) {
+			s.htmxError(w, http.StatusInternalServerError, "Failed to add "+name)
+			return
+		}
+	} else {
+		s.audit(r, db.AuditOptionAdd, categoryID, name)
+	}
 
 	if s.isHTMX(r) {
 		// Get the newly created option
-		options, _ := s.queries.ListOptionsByCategory(r.Context(), categoryID)
+		options, _ := s.queries.ListOptionVotesByCategory(r.Context(), categoryID)
 		if len(options) > 0 {
 			newOpt := options[len(options)-1]
+			showToast(w, toastSuccess, "Added "+newOpt.Name)
 			s.renderPartial(w, "partials/option-row.html", newOpt)
 		}
 		return
@@ -923,7 +1556,7 @@ func (s *Server) handleAdminAddOption(w http.ResponseWriter, r *http.Request, ca
 func (s *Server) handleAdminDeleteOption(w http.ResponseWriter, r *http.Request) {
 	// Accept both POST and DELETE
 	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
-		http.NotFound(w, r)
+		s.notFound(w, r)
 		return
 	}
 
@@ -934,27 +1567,110 @@ func (s *Server) handleAdminDeleteOption(w http.ResponseWriter, r *http.Request)
 
 	id, err := strconv.ParseInt(path, 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		s.notFound(w, r)
 		return
 	}
 
-	opt, err := s.queries.GetOption(r.Context(), id)
+	opt, err := s.queries.Option(r.Context(), id)
 	if err != nil {
 		if s.isHTMX(r) {
-			w.WriteHeader(http.StatusNotFound)
+			s.htmxError(w, http.StatusNotFound, "That option no longer exists")
 			return
 		}
 		http.Redirect(w, r, AdminURL(), http.StatusSeeOther)
 		return
 	}
 
-	s.queries.DeleteOption(r.Context(), id)
+	// Deleting an option deletes the votes for it; retiring keeps them
+	votes, err := s.queries.CountSelectionsByOption(r.Context(), id)
+	if err != nil {
+		s.renderActionError(w, r, "Failed to count votes", err)
+		return
+	}
+	if votes > 0 && r.FormValue("force") == "" {
+		message := fmt.Sprintf("%q has %d vote(s). Retire it to hide it from ballots and keep its votes, or force delete to remove them.", opt.Name, votes)
+		if s.isHTMX(r) {
+			s.htmxError(w, http.StatusConflict, message)
+			return
+		}
+		cat, _ := s.queries.Category(r.Context(), opt.CategoryID)
+		options, _ := s.queries.ListOptionVotesByCategory(r.Context(), opt.CategoryID)
+		w.WriteHeader(http.StatusConflict)
+		s.renderCategory(w, r, map[string]any{
+			"Category": cat,
+			"Options":  options,
+			"Error":    message,
+		})
+		return
+	}
+
+	if err := s.queries.DeleteOption(r.Context(), id); err != nil {
+		log.Printf("Failed to delete option %d: %v", id, err)
+		if s.isHTMX(r) {
+			s.htmxError(w, http.StatusInternalServerError, "Failed to delete "+opt.Name)
+			return
+		}
+	} else {
+		detail := opt.Name
+		if votes > 0 {
+			detail = fmt.Sprintf("%s (%d votes)", opt.Name, votes)
+		}
+		s.audit(r, db.AuditOptionRemove, opt.CategoryID, detail)
+		s.releaseImage(r.Context(), opt.Image)
+	}
 
 	if s.isHTMX(r) {
 		// Return empty response - htmx will remove the element
+		showToast(w, toastSuccess, "Deleted "+opt.Name)
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	http.Redirect(w, r, AdminCategoryURL(opt.CategoryID, "options"), http.StatusSeeOther)
 }
+
+// handleAdminRetireOption hides an option from ballots while keeping the
+// votes already cast for it in the results
+func (s *Server) handleAdminRetireOption(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.notFound(w, r)
+		return
+	}
+
+	path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/admin/option/"), "/retire")
+	id, err := strconv.ParseInt(path, 10, 64)
+	if err != nil {
+		s.notFound(w, r)
+		return
+	}
+
+	opt, err := s.queries.Option(r.Context(), id)
+	if err != nil {
+		s.lookupFailed(w, r, "option", err)
+		return
+	}
+
+	kind, message := toastInfo, opt.Name+" was already retired"
+	if !opt.RetiredAt.Valid {
+		if err := s.queries.RetireOption(r.Context(), id); err != nil {
+			s.renderActionError(w, r, "Failed to retire option", err)
+			return
+		}
+		s.audit(r, db.AuditOptionRetire, opt.CategoryID, opt.Name)
+		kind, message = toastSuccess, "Retired "+opt.Name+"; its votes still count"
+	}
+
+	if s.isHTMX(r) {
+		showToast(w, kind, message)
+		options, _ := s.queries.ListOptionVotesByCategory(r.Context(), opt.CategoryID)
+		for _, o := range options {
+			if o.ID == id {
+				s.renderPartial(w, "partials/option-row.html", o)
+				return
+			}
+		}
+		return
+	}
+
+	http.Redirect(w, r, AdminCategoryURL(opt.CategoryID, "options"), http.StatusSeeOther)
+}
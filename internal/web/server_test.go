@@ -1,15 +1,36 @@
 package web_test
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"image/png"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/notify"
+	"github.com/palm-arcade/votigo/internal/oembed"
 	"github.com/palm-arcade/votigo/internal/web"
+	"github.com/palm-arcade/votigo/templates"
 )
 
 const testAdminPassword = "testpass"
@@ -522,6 +543,74 @@ func TestHandleVoteSubmit_NicknameCaseInsensitive(t *testing.T) {
 // RESULTS PAGE TESTS
 // ====================
 
+func TestVoteWidget(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+
+			cat := createTestCategory(t, queries, "Best Booth", "single", "open", "live")
+			opt := createTestOption(t, queries, cat.ID, "Retro Corner")
+			handler := srv.Handler()
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, web.VoteWidgetURL(cat.ID), nil))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rr.Code)
+			}
+			if got := rr.Header().Get("Content-Security-Policy"); got != "frame-ancestors *" {
+				t.Errorf("expected default frame-ancestors *, got %q", got)
+			}
+			body := rr.Body.String()
+			if !strings.Contains(body, `action="`+web.VoteWidgetURL(cat.ID)+`"`) || !strings.Contains(body, "Retro Corner") {
+				t.Errorf("expected ballot posting back to the widget, got %s", body)
+			}
+			if strings.Contains(body, "ADMIN") || strings.Contains(body, "Admin</a>") {
+				t.Error("expected no site navigation in the widget")
+			}
+
+			// The full vote page stays unframeable by default
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, web.VoteURL(cat.ID), nil))
+			if got := rr.Header().Get("Content-Security-Policy"); got != "" {
+				t.Errorf("expected no CSP on the vote page, got %q", got)
+			}
+
+			form := url.Values{"nickname": {"embedder"}, "choice": {strconv.FormatInt(opt.ID, 10)}}
+			req := httptest.NewRequest(http.MethodPost, web.VoteWidgetURL(cat.ID), strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if !strings.Contains(rr.Body.String(), "VOTE RECORDED") || strings.Contains(rr.Body.String(), "Back to all votes") {
+				t.Errorf("expected compact success message, got %s", rr.Body.String())
+			}
+			if count, _ := queries.CountVotesByCategory(t.Context(), cat.ID); count != 1 {
+				t.Errorf("expected 1 vote, got %d", count)
+			}
+
+			form = url.Values{db.SettingWidgetFrameAncestors: {"'self' http://tournament.lan bad;src"}}
+			req = httptest.NewRequest(http.MethodPost, web.AdminSettingsURL(), strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected settings to save, got %d", rr.Code)
+			}
+			queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{Status: "closed", ID: cat.ID})
+
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, web.VoteWidgetURL(cat.ID), nil))
+			if got := rr.Header().Get("Content-Security-Policy"); got != "frame-ancestors 'self' http://tournament.lan" {
+				t.Errorf("expected configured frame-ancestors, got %q", got)
+			}
+			if !strings.Contains(rr.Body.String(), "Voting is not open") {
+				t.Error("expected closed message inside the widget")
+			}
+		})
+	}
+}
+
 func TestHandleResultsList(t *testing.T) {
 	srv, queries, conn := testServer(t)
 	defer conn.Close()
@@ -734,6 +823,58 @@ func TestAdminAuth_Success(t *testing.T) {
 	}
 }
 
+func TestAdminAuth_StoredHash(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	queries := db.New(conn)
+	if _, err := queries.AdminPassword(context.Background()); !errors.Is(err, db.ErrNotFound) {
+		t.Fatalf("expected no stored password in a new database, got %v", err)
+	}
+	hash, err := db.HashPassword("generated")
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	if err := queries.SetAdminPassword(context.Background(), hash); err != nil {
+		t.Fatalf("failed to store password: %v", err)
+	}
+	stored, err := queries.AdminPassword(context.Background())
+	if err != nil {
+		t.Fatalf("failed to load password: %v", err)
+	}
+
+	srv, err := web.NewServer(conn, "", web.UIModeModern, web.WithAdminPasswordHash(stored))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := srv.Handler()
+
+	// Twice, the second time from the remembered match
+	for _, tc := range []struct {
+		pass string
+		want int
+	}{
+		{"generated", http.StatusOK},
+		{"generated", http.StatusOK},
+		{"", http.StatusUnauthorized},
+		{"wrong", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.SetBasicAuth("admin", tc.pass)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != tc.want {
+			t.Errorf("password %q: expected status %d, got %d", tc.pass, tc.want, rr.Code)
+		}
+	}
+}
+
 // ====================
 // ADMIN DASHBOARD TESTS
 // ====================
@@ -786,6 +927,116 @@ func TestAdminDashboard_ExcludesArchived(t *testing.T) {
 	}
 }
 
+func TestAdminDashboard_ShowsWarnings(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	handler := srv.Handler()
+	dashboard := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.SetBasicAuth("admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rr.Code)
+		}
+		return rr.Body.String()
+	}
+
+	cat := createTestCategory(t, queries, "Empty Poll", "single", "open", "live")
+	body := dashboard()
+	if !strings.Contains(body, "1 warning") || !strings.Contains(body, "open with 0 option(s) to choose from") {
+		t.Errorf("expected a warning for the open poll without options, got:\n%s", body)
+	}
+
+	createTestOption(t, queries, cat.ID, "Doom")
+	createTestOption(t, queries, cat.ID, "Quake")
+	if body := dashboard(); strings.Contains(body, "warning") {
+		t.Error("expected no warnings once the poll has options")
+	}
+}
+
+// ====================
+// ADMIN ACTIVITY FEED TESTS
+// ====================
+
+func TestAdminDashboard_ShowsActivity(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Activity Poll", "single", "draft", "live")
+	opt := createTestOption(t, queries, cat.ID, "Option A")
+
+	handler := srv.Handler()
+
+	// Open via admin so an action is recorded
+	req := httptest.NewRequest(http.MethodPost, "/admin/category/1/open", nil)
+	req.SetBasicAuth("admin", testAdminPassword)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Cast a vote so the votes-per-minute feed has data
+	form := url.Values{}
+	form.Set("nickname", "voter1")
+	form.Set("choice", strconv.FormatInt(opt.ID, 10))
+	req = httptest.NewRequest(http.MethodPost, "/vote/1", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.SetBasicAuth("admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	if !strings.Contains(body, "category.open") {
+		t.Error("expected open action in activity feed")
+	}
+	if strings.Contains(body, "No recent votes") {
+		t.Error("expected vote rate in activity feed")
+	}
+}
+
+func TestAdminActivity_Partial(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Activity Poll", "single", "draft", "live")
+	queries.RecordAudit(t.Context(), db.ActorAdmin, db.AuditOptionAdd, cat.ID, "Fresh Option")
+
+	handler := srv.Handler()
+	req := httptest.NewRequest(http.MethodGet, "/admin/activity", nil)
+	req.Header.Set("HX-Request", "true")
+	req.SetBasicAuth("admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rr.Code)
+	}
+
+	body := rr.Body.String()
+	if !strings.Contains(body, "Fresh Option") {
+		t.Error("expected recorded action in activity partial")
+	}
+	if strings.Contains(body, "<html") {
+		t.Error("activity partial should not include layout")
+	}
+}
+
+func TestAdminActivity_RequiresAuth(t *testing.T) {
+	srv, _, conn := testServerModern(t)
+	defer conn.Close()
+
+	handler := srv.Handler()
+	req := httptest.NewRequest(http.MethodGet, "/admin/activity", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rr.Code)
+	}
+}
+
 // ====================
 // ADMIN CATEGORY CREATE TESTS
 // ====================
@@ -864,6 +1115,94 @@ func TestAdminCategoryNew_CreateRanked(t *testing.T) {
 	}
 }
 
+func TestAdminCategoryNew_CreateWithLabel(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	handler := srv.Handler()
+	form := url.Values{}
+	form.Set("name", "Food Votes")
+	form.Set("vote_type", "single")
+	form.Set("show_results", "live")
+	form.Set("color", "amber")
+	form.Set("icon", " 🍕 ")
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/category/new", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	cats, _ := queries.ListCategories(t.Context())
+	if len(cats) != 1 {
+		t.Fatalf("expected 1 category, got %d", len(cats))
+	}
+	if cats[0].Color != "amber" {
+		t.Errorf("expected color 'amber', got '%s'", cats[0].Color)
+	}
+	if cats[0].Icon != "🍕" {
+		t.Errorf("expected icon '🍕', got '%s'", cats[0].Icon)
+	}
+}
+
+func TestAdminCategoryNew_RejectsUnknownColor(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	handler := srv.Handler()
+	form := url.Values{}
+	form.Set("name", "Bad Color")
+	form.Set("vote_type", "single")
+	form.Set("show_results", "live")
+	form.Set("color", "chartreuse")
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/category/new", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if !strings.Contains(rr.Body.String(), "Unknown label color") {
+		t.Error("expected unknown color error")
+	}
+	cats, _ := queries.ListCategories(t.Context())
+	if len(cats) != 0 {
+		t.Errorf("expected no category to be created, got %d", len(cats))
+	}
+}
+
+func TestCategoryLabel_RenderedOnHome(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+
+			cat := createTestCategory(t, queries, "Game Awards", "single", "open", "live")
+			queries.UpdateCategory(t.Context(), db.UpdateCategoryParams{
+				Name:        cat.Name,
+				VoteType:    cat.VoteType,
+				ShowResults: cat.ShowResults,
+				Color:       "purple",
+				Icon:        "🏆",
+				ID:          cat.ID,
+			})
+
+			handler := srv.Handler()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			body := rr.Body.String()
+			if !strings.Contains(body, "#a855f7") {
+				t.Error("expected label color on home page")
+			}
+			if !strings.Contains(body, "🏆") {
+				t.Error("expected label icon on home page")
+			}
+		})
+	}
+}
+
 // ====================
 // ADMIN CATEGORY EDIT TESTS
 // ====================
@@ -955,6 +1294,48 @@ func TestAdminCategoryEdit_EmptyName(t *testing.T) {
 	}
 }
 
+func TestAdminCategoryEdit_RejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		field, value, want string
+	}{
+		{"vote_type", "plurality", "Unknown vote type"},
+		{"show_results", "never", "Unknown results visibility"},
+		{"color", "chartreuse", "Unknown label color"},
+		{"weights", "jury=0", "Weights: "},
+		{"judges", "the jury", "Judges: "},
+		{"judge_weight", "100", "Judges can decide from 1 to 99 percent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			srv, queries, conn := testServer(t)
+			defer conn.Close()
+
+			createTestCategory(t, queries, "Original", "single", "draft", "live")
+
+			form := url.Values{}
+			form.Set("name", "Renamed")
+			form.Set("vote_type", "single")
+			form.Set("show_results", "live")
+			form.Set(tt.field, tt.value)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/category/1", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetBasicAuth("admin", testAdminPassword)
+			rr := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(rr, req)
+
+			if !strings.Contains(rr.Body.String(), tt.want) {
+				t.Errorf("expected %q error", tt.want)
+			}
+			cat, _ := queries.GetCategory(t.Context(), 1)
+			if cat.Name != "Original" {
+				t.Errorf("expected category to be unchanged, got name %q", cat.Name)
+			}
+		})
+	}
+}
+
 func TestAdminCategoryEdit_NotFound(t *testing.T) {
 	srv, _, conn := testServer(t)
 	defer conn.Close()
@@ -1210,6 +1591,48 @@ func TestAdminAddOption_EmptyName(t *testing.T) {
 	}
 }
 
+func TestAdminAddOption_Duplicate(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Test Poll", "single", "draft", "live")
+	createTestOption(t, queries, cat.ID, "Tetris")
+
+	form := url.Values{}
+	form.Set("option_name", "TETRIS")
+	req := httptest.NewRequest(http.MethodPost, "/admin/category/1/option", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetBasicAuth("admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a name differing only in case, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "TETRIS is already an option") {
+		t.Errorf("expected a message naming the duplicate, got %q", rr.Body.String())
+	}
+
+	// Without htmx the category page is shown again with the message
+	req = httptest.NewRequest(http.MethodPost, "/admin/category/1/option", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("admin", testAdminPassword)
+	rr = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected 409 without htmx, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "TETRIS is already an option") {
+		t.Errorf("expected the category page to name the duplicate, got %q", rr.Body.String())
+	}
+	opts, _ := queries.ListOptionsByCategory(t.Context(), cat.ID)
+	if len(opts) != 1 {
+		t.Errorf("expected 1 option, got %d", len(opts))
+	}
+}
+
 func TestAdminAddOption_GetNotAllowed(t *testing.T) {
 	srv, queries, conn := testServer(t)
 	defer conn.Close()
@@ -1321,68 +1744,488 @@ func TestAdminDeleteOption_GetNotAllowed(t *testing.T) {
 	}
 }
 
-// ====================
-// HTMX ENDPOINT TESTS
-// ====================
+// voteFor casts a single-choice ballot through the vote form
+func voteFor(t *testing.T, handler http.Handler, categoryID, optionID int64, nickname string) *httptest.ResponseRecorder {
+	t.Helper()
+	form := url.Values{"nickname": {nickname}, "choice": {strconv.FormatInt(optionID, 10)}}
+	req := httptest.NewRequest(http.MethodPost, web.VoteURL(categoryID), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
 
-func TestHTMX_VoteSubmit(t *testing.T) {
+func TestVoteReceipt(t *testing.T) {
 	srv, queries, conn := testServerModern(t)
 	defer conn.Close()
 
 	cat := createTestCategory(t, queries, "Test Poll", "single", "open", "live")
-	createTestOption(t, queries, cat.ID, "Option A")
+	first := createTestOption(t, queries, cat.ID, "First")
+	second := createTestOption(t, queries, cat.ID, "Second")
 
 	handler := srv.Handler()
-	form := url.Values{}
-	form.Set("nickname", "HTMXVoter")
-	form.Set("choice", "1")
+	rr := voteFor(t, handler, cat.ID, first.ID, "player1")
 
-	req := httptest.NewRequest(http.MethodPost, "/vote/1", strings.NewReader(form.Encode()))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("HX-Request", "true")
-	rr := httptest.NewRecorder()
-	handler.ServeHTTP(rr, req)
+	vote, err := queries.GetVoteByNickname(t.Context(), db.GetVoteByNicknameParams{CategoryID: cat.ID, Nickname: "player1"})
+	if err != nil {
+		t.Fatalf("failed to load vote: %v", err)
+	}
+	receipt, err := queries.BallotReceipt(t.Context(), vote)
+	if err != nil {
+		t.Fatalf("failed to compute receipt: %v", err)
+	}
+	if !strings.Contains(rr.Body.String(), receipt) {
+		t.Errorf("expected confirmation to show receipt %s", receipt)
+	}
 
-	if rr.Code != http.StatusOK {
-		t.Errorf("expected status 200, got %d", rr.Code)
+	// Changing the ballot changes the receipt
+	voteFor(t, handler, cat.ID, second.ID, "player1")
+	vote, _ = queries.GetVoteByNickname(t.Context(), db.GetVoteByNicknameParams{CategoryID: cat.ID, Nickname: "player1"})
+	changed, _ := queries.BallotReceipt(t.Context(), vote)
+	if changed == receipt {
+		t.Error("expected a re-vote to get a new receipt")
 	}
 
-	// Verify vote was recorded
-	count, _ := queries.CountVotesByCategory(t.Context(), cat.ID)
-	if count != 1 {
-		t.Errorf("expected 1 vote, got %d", count)
+	// Receipts computed from a whole category's selections agree
+	selections, err := queries.ListSelectionsByCategory(t.Context(), cat.ID)
+	if err != nil {
+		t.Fatalf("failed to load selections: %v", err)
+	}
+	if got := db.Receipt(vote, selections); got != changed {
+		t.Errorf("expected Receipt to match BallotReceipt %s, got %s", changed, got)
 	}
 }
 
-func TestHTMX_AddOption(t *testing.T) {
-	srv, queries, conn := testServerModern(t)
+func TestAdminDeleteOption_WithVotesNeedsForce(t *testing.T) {
+	srv, queries, conn := testServer(t)
 	defer conn.Close()
 
-	createTestCategory(t, queries, "Test Poll", "single", "draft", "live")
+	cat := createTestCategory(t, queries, "Test Poll", "single", "open", "live")
+	opt := createTestOption(t, queries, cat.ID, "Popular")
 
 	handler := srv.Handler()
-	form := url.Values{}
-	form.Set("option_name", "HTMX Option")
+	voteFor(t, handler, cat.ID, opt.ID, "player1")
 
-	req := httptest.NewRequest(http.MethodPost, "/admin/category/1/option", strings.NewReader(form.Encode()))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("HX-Request", "true")
+	req := httptest.NewRequest(http.MethodPost, web.AdminOptionURL(opt.ID), nil)
 	req.SetBasicAuth("admin", testAdminPassword)
 	rr := httptest.NewRecorder()
 	handler.ServeHTTP(rr, req)
 
-	// Note: Response is 200 even if partial template has errors (template error is logged)
-	// The important thing is verifying the database operation succeeded
-	opts, _ := queries.ListOptionsByCategory(t.Context(), 1)
-	if len(opts) != 1 {
-		t.Fatalf("expected 1 option, got %d", len(opts))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rr.Code)
 	}
-	if opts[0].Name != "HTMX Option" {
-		t.Errorf("expected option name 'HTMX Option', got '%s'", opts[0].Name)
+	if !strings.Contains(rr.Body.String(), "Retire") {
+		t.Error("expected the page to offer retiring the option")
+	}
+	if _, err := queries.GetOption(t.Context(), opt.ID); err != nil {
+		t.Fatal("expected option with votes to be kept")
 	}
-}
 
-func TestHTMX_DeleteOption(t *testing.T) {
+	req = httptest.NewRequest(http.MethodPost, web.AdminOptionURL(opt.ID), strings.NewReader("force=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("admin", testAdminPassword)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect (303) for forced delete, got %d", rr.Code)
+	}
+	if _, err := queries.GetOption(t.Context(), opt.ID); err == nil {
+		t.Error("expected forced delete to remove the option")
+	}
+}
+
+func TestAdminRetireOption(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Test Poll", "single", "open", "live")
+	retired := createTestOption(t, queries, cat.ID, "Withdrawn Game")
+	kept := createTestOption(t, queries, cat.ID, "Still Running")
+
+	handler := srv.Handler()
+	voteFor(t, handler, cat.ID, retired.ID, "player1")
+
+	req := httptest.NewRequest(http.MethodPost, web.AdminRetireOptionURL(retired.ID), nil)
+	req.Header.Set("HX-Request", "true")
+	req.SetBasicAuth("admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "RETIRED") {
+		t.Error("expected the option row to be marked retired")
+	}
+
+	// Hidden from the ballot
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, web.VoteURL(cat.ID), nil)
+	if strings.Contains(rr.Body.String(), "Withdrawn Game") {
+		t.Error("expected retired option to be hidden from the ballot")
+	}
+	if !strings.Contains(rr.Body.String(), "Still Running") {
+		t.Error("expected active option on the ballot")
+	}
+
+	// New votes for it are rejected
+	voteFor(t, handler, cat.ID, retired.ID, "player2")
+	if n, _ := queries.CountVotesByCategory(t.Context(), cat.ID); n != 1 {
+		t.Errorf("expected vote for a retired option to be rejected, got %d votes", n)
+	}
+
+	// Existing votes still count
+	tally, err := queries.TallySimple(t.Context(), cat.ID)
+	if err != nil {
+		t.Fatalf("failed to tally: %v", err)
+	}
+	if len(tally) != 2 || tally[0].ID != retired.ID || tally[0].Votes != 1 {
+		t.Errorf("expected retired option to keep its vote in the results, got %+v", tally)
+	}
+
+	// Retiring every option leaves nothing to vote on
+	if err := queries.RetireOption(t.Context(), kept.ID); err != nil {
+		t.Fatalf("failed to retire option: %v", err)
+	}
+	if n, _ := queries.CountBallotOptionsByCategory(t.Context(), cat.ID); n != 0 {
+		t.Errorf("expected no ballot options, got %d", n)
+	}
+}
+
+func TestCategoryDependency(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+	handler := srv.Handler()
+
+	admin := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	heats := createTestCategory(t, queries, "Heats", "single", "open", "after_close")
+	tetris := createTestOption(t, queries, heats.ID, "Tetris")
+	doom := createTestOption(t, queries, heats.ID, "Doom")
+	myst := createTestOption(t, queries, heats.ID, "Myst")
+	createTestOption(t, queries, heats.ID, "Pong")
+	for i, optID := range []int64{tetris.ID, tetris.ID, tetris.ID, doom.ID, doom.ID, myst.ID, myst.ID} {
+		voteFor(t, handler, heats.ID, optID, "player"+strconv.Itoa(i))
+	}
+
+	rr := admin(web.AdminCategoryNewURL(), url.Values{
+		"name":         {"Grand Champion"},
+		"vote_type":    {"single"},
+		"show_results": {"after_close"},
+		"depends_on":   {strconv.FormatInt(heats.ID, 10)},
+		"seed_top_n":   {"2"},
+	})
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect after create, got %d: %s", rr.Code, rr.Body.String())
+	}
+	final, _ := queries.GetCategory(t.Context(), heats.ID+1)
+	if final.Name != "Grand Champion" || final.DependsOn.Int64 != heats.ID || final.SeedTopN != 2 {
+		t.Fatalf("expected dependency to be saved, got %+v", final)
+	}
+	createTestOption(t, queries, final.ID, "Doom")
+
+	// Can't open before the heats close
+	rr = admin(web.AdminCategoryOpenURL(final.ID), nil)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "Opens after Heats") {
+		t.Errorf("expected open to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// A poll can't wait on one that waits on it
+	rr = admin(web.AdminCategoryURL(heats.ID), url.Values{
+		"name":         {"Heats"},
+		"vote_type":    {"single"},
+		"show_results": {"after_close"},
+		"depends_on":   {strconv.FormatInt(final.ID, 10)},
+	})
+	if !strings.Contains(rr.Body.String(), "waiting forever") {
+		t.Errorf("expected cycle to be rejected, got %d", rr.Code)
+	}
+
+	// Closing the heats seeds the top 2, including Myst tied for second;
+	// Doom was already there and Pong got no votes
+	if rr := admin(web.AdminCategoryCloseURL(heats.ID), nil); rr.Code != http.StatusOK {
+		t.Fatalf("expected close to succeed, got %d", rr.Code)
+	}
+	options, _ := queries.ListOptionsByCategory(t.Context(), final.ID)
+	var names []string
+	for _, o := range options {
+		names = append(names, o.Name)
+	}
+	if got := strings.Join(names, ","); got != "Doom,Tetris,Myst" {
+		t.Errorf("expected seeded options Doom,Tetris,Myst, got %s", got)
+	}
+
+	events, _ := queries.ListRecentAdminActions(t.Context(), 10)
+	seeded := 0
+	for _, e := range events {
+		if e.Action == db.AuditOptionSeed && e.CategoryID.Int64 == final.ID {
+			seeded++
+		}
+	}
+	if seeded != 2 {
+		t.Errorf("expected 2 seed audit events, got %d", seeded)
+	}
+
+	if rr := admin(web.AdminCategoryOpenURL(final.ID), nil); rr.Code != http.StatusOK {
+		t.Errorf("expected open to succeed once the heats closed, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAdminSeedFromResults(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+	handler := srv.Handler()
+
+	heats := createTestCategory(t, queries, "Heats", "single", "open", "after_close")
+	tetris := createTestOption(t, queries, heats.ID, "Tetris")
+	doom := createTestOption(t, queries, heats.ID, "Doom")
+	createTestOption(t, queries, heats.ID, "Myst")
+	voteFor(t, handler, heats.ID, tetris.ID, "player1")
+	voteFor(t, handler, heats.ID, tetris.ID, "player2")
+	voteFor(t, handler, heats.ID, doom.ID, "player3")
+	runoff := createTestCategory(t, queries, "Runoff", "single", "draft", "after_close")
+
+	seed := func(source int64, topN string) *httptest.ResponseRecorder {
+		form := url.Values{"source_id": {strconv.FormatInt(source, 10)}, "top_n": {topN}}
+		req := httptest.NewRequest(http.MethodPost, web.AdminCategorySeedURL(runoff.ID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Not while the heats are still open
+	rr := seed(heats.ID, "2")
+	if !strings.Contains(rr.Body.String(), "Cannot seed options") {
+		t.Errorf("expected seeding from an open poll to be refused, got %d", rr.Code)
+	}
+
+	queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{Status: "closed", ID: heats.ID})
+	rr = seed(heats.ID, "2")
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	options, _ := queries.ListOptionsByCategory(t.Context(), runoff.ID)
+	if len(options) != 2 || options[0].Name != "Tetris" || options[1].Name != "Doom" {
+		t.Fatalf("expected Tetris and Doom to be seeded, got %+v", options)
+	}
+	if options[0].SeededFrom.Int64 != tetris.ID || options[1].SeededFrom.Int64 != doom.ID {
+		t.Errorf("expected seeded options to record their source, got %+v", options)
+	}
+
+	// Seeding again adds nothing new
+	seed(heats.ID, "2")
+	if n, _ := queries.CountOptionsByCategory(t.Context(), runoff.ID); n != 2 {
+		t.Errorf("expected no duplicate options, got %d", n)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, web.AdminCategoryURL(runoff.ID), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if !strings.Contains(rr.Body.String(), "from Heats") {
+		t.Error("expected seeded options to show where they came from")
+	}
+
+	events, _ := queries.ListRecentAdminActions(t.Context(), 10)
+	if len(events) == 0 || events[0].Action != db.AuditOptionSeed || events[0].Detail != "Doom (from Heats)" {
+		t.Errorf("expected seed audit events, got %+v", events)
+	}
+}
+
+func TestRunoffCandidates(t *testing.T) {
+	tests := []struct {
+		name   string
+		votes  []int // votes for options A, B, C
+		reason string
+		want   string
+	}{
+		{"clear winner", []int{3, 1, 0}, "", ""},
+		{"tie for first", []int{2, 2, 1}, db.RunoffTie, "A,B"},
+		{"no majority", []int{2, 1, 1}, db.RunoffNoMajority, "A,B,C"},
+		{"exactly half", []int{3, 2, 1}, db.RunoffNoMajority, "A,B"},
+		{"no votes", []int{0, 0, 0}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, queries, conn := testServerModern(t)
+			defer conn.Close()
+			handler := srv.Handler()
+
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "after_close")
+			voter := 0
+			for i, name := range []string{"A", "B", "C"} {
+				opt := createTestOption(t, queries, cat.ID, name)
+				for range tt.votes[i] {
+					voter++
+					voteFor(t, handler, cat.ID, opt.ID, "player"+strconv.Itoa(voter))
+				}
+			}
+			queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{Status: "closed", ID: cat.ID})
+			cat, _ = queries.GetCategory(t.Context(), cat.ID)
+
+			reason, options, err := queries.RunoffCandidates(t.Context(), cat)
+			if err != nil {
+				t.Fatalf("RunoffCandidates: %v", err)
+			}
+			var names []string
+			for _, o := range options {
+				names = append(names, o.Name)
+			}
+			if reason != tt.reason || strings.Join(names, ",") != tt.want {
+				t.Errorf("got %q %v, want %q %s", reason, names, tt.reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminRunoff(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+	handler := srv.Handler()
+
+	admin := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	cat := createTestCategory(t, queries, "Best Game", "single", "open", "after_close")
+	tetris := createTestOption(t, queries, cat.ID, "Tetris")
+	doom := createTestOption(t, queries, cat.ID, "Doom")
+	createTestOption(t, queries, cat.ID, "Myst")
+	voteFor(t, handler, cat.ID, tetris.ID, "player1")
+	voteFor(t, handler, cat.ID, doom.ID, "player2")
+
+	// Nothing to offer while the poll is open
+	if rr := admin(http.MethodPost, web.AdminCategoryRunoffURL(cat.ID)); !strings.Contains(rr.Body.String(), "No runoff needed") {
+		t.Errorf("expected no runoff for an open poll, got %d", rr.Code)
+	}
+
+	queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{Status: "closed", ID: cat.ID})
+	if rr := admin(http.MethodGet, web.AdminCategoryURL(cat.ID)); !strings.Contains(rr.Body.String(), "Create Runoff") {
+		t.Error("expected the tie to offer a runoff")
+	}
+
+	rr := admin(http.MethodPost, web.AdminCategoryRunoffURL(cat.ID))
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d: %s", rr.Code, rr.Body.String())
+	}
+	runoff, err := queries.GetRunoff(t.Context(), sql.NullInt64{Int64: cat.ID, Valid: true})
+	if err != nil {
+		t.Fatalf("expected a runoff to be created: %v", err)
+	}
+	if rr.Header().Get("Location") != web.AdminCategoryURL(runoff.ID) {
+		t.Errorf("expected redirect to the runoff, got %s", rr.Header().Get("Location"))
+	}
+	if runoff.Name != "Best Game (runoff)" || runoff.Status != "draft" || runoff.VoteType != "single" || runoff.Ref() != "best-game-runoff" {
+		t.Errorf("unexpected runoff %+v", runoff)
+	}
+	options, _ := queries.ListOptionsByCategory(t.Context(), runoff.ID)
+	if len(options) != 2 || options[0].Name != "Tetris" || options[1].Name != "Doom" {
+		t.Errorf("expected the runoff between Tetris and Doom, got %+v", options)
+	}
+
+	// A second click goes to the same runoff
+	if rr := admin(http.MethodPost, web.AdminCategoryRunoffURL(cat.ID)); rr.Header().Get("Location") != web.AdminCategoryURL(runoff.ID) {
+		t.Errorf("expected redirect to the existing runoff, got %d %s", rr.Code, rr.Header().Get("Location"))
+	}
+	if n, _ := queries.CountOptionsByCategory(t.Context(), runoff.ID+1); n != 0 {
+		t.Error("expected no second runoff")
+	}
+
+	// Results pages link to each other once the runoff opens
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, web.ResultsURL(cat.ID), nil)
+	if strings.Contains(rr.Body.String(), "Decided by a runoff") {
+		t.Error("expected a draft runoff not to be linked")
+	}
+	queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{Status: "open", ID: runoff.ID})
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, web.ResultsURL(cat.ID), nil)
+	if !strings.Contains(rr.Body.String(), web.VoteURL(runoff.Ref())) {
+		t.Error("expected results to link to the open runoff")
+	}
+	queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{Status: "closed", ID: runoff.ID})
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, web.ResultsURL(runoff.ID), nil)
+	if !strings.Contains(rr.Body.String(), "Runoff of") || !strings.Contains(rr.Body.String(), web.ResultsURL(cat.ID)) {
+		t.Error("expected runoff results to link back to the original poll")
+	}
+}
+
+// ====================
+// HTMX ENDPOINT TESTS
+// ====================
+
+func TestHTMX_VoteSubmit(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Test Poll", "single", "open", "live")
+	createTestOption(t, queries, cat.ID, "Option A")
+
+	handler := srv.Handler()
+	form := url.Values{}
+	form.Set("nickname", "HTMXVoter")
+	form.Set("choice", "1")
+
+	req := httptest.NewRequest(http.MethodPost, "/vote/1", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rr.Code)
+	}
+
+	// Verify vote was recorded
+	count, _ := queries.CountVotesByCategory(t.Context(), cat.ID)
+	if count != 1 {
+		t.Errorf("expected 1 vote, got %d", count)
+	}
+}
+
+func TestHTMX_AddOption(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	createTestCategory(t, queries, "Test Poll", "single", "draft", "live")
+
+	handler := srv.Handler()
+	form := url.Values{}
+	form.Set("option_name", "HTMX Option")
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/category/1/option", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetBasicAuth("admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	// Note: Response is 200 even if partial template has errors (template error is logged)
+	// The important thing is verifying the database operation succeeded
+	opts, _ := queries.ListOptionsByCategory(t.Context(), 1)
+	if len(opts) != 1 {
+		t.Fatalf("expected 1 option, got %d", len(opts))
+	}
+	if opts[0].Name != "HTMX Option" {
+		t.Errorf("expected option name 'HTMX Option', got '%s'", opts[0].Name)
+	}
+}
+
+func TestHTMX_DeleteOption(t *testing.T) {
 	srv, queries, conn := testServerModern(t)
 	defer conn.Close()
 
@@ -1450,6 +2293,30 @@ func TestHTMX_OpenCategoryNoOptions(t *testing.T) {
 	}
 }
 
+func TestHTMX_OpenCategoryArchived(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	createTestCategory(t, queries, "Old Poll", "single", "archived", "live")
+	createTestOption(t, queries, 1, "Option")
+
+	handler := srv.Handler()
+	for _, action := range []string{"open", "close"} {
+		req := httptest.NewRequest(http.MethodPost, "/admin/category/1/"+action, nil)
+		req.Header.Set("HX-Request", "true")
+		req.SetBasicAuth("admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 to %s an archived poll, got %d", action, rr.Code)
+		}
+	}
+	if cat, _ := queries.GetCategory(t.Context(), 1); cat.Status != "archived" {
+		t.Errorf("expected the poll to stay archived, got %q", cat.Status)
+	}
+}
+
 func TestHTMX_CloseCategory(t *testing.T) {
 	srv, queries, conn := testServerModern(t)
 	defer conn.Close()
@@ -1555,9 +2422,12 @@ func TestHandleVote_CategoryNotFound(t *testing.T) {
 	rr := httptest.NewRecorder()
 	handler.ServeHTTP(rr, req)
 
-	// Returns error page, not 404
-	if rr.Code != http.StatusInternalServerError {
-		t.Errorf("expected status 500 for nonexistent category, got %d", rr.Code)
+	// A missing poll is a 404 on the error page that names it
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for nonexistent category, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "Category not found") {
+		t.Error("expected the error page to say the category wasn't found")
 	}
 }
 
@@ -1570,46 +2440,136 @@ func TestHandleResults_CategoryNotFound(t *testing.T) {
 	rr := httptest.NewRecorder()
 	handler.ServeHTTP(rr, req)
 
-	// Returns error page
-	if rr.Code != http.StatusInternalServerError {
-		t.Errorf("expected status 500 for nonexistent category, got %d", rr.Code)
+	// A missing poll is a 404 on the error page that names it
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for nonexistent category, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "Category not found") {
+		t.Error("expected the error page to say the category wasn't found")
 	}
 }
 
-func TestAdminReopen_NoOptions(t *testing.T) {
+func TestAdminLookupErrors(t *testing.T) {
 	srv, queries, conn := testServer(t)
 	defer conn.Close()
-
-	// Create closed category with no options
-	createTestCategory(t, queries, "Closed Poll", "single", "closed", "live")
-
 	handler := srv.Handler()
-	req := httptest.NewRequest(http.MethodPost, "/admin/category/1/reopen", nil)
-	req.SetBasicAuth("admin", testAdminPassword)
+	cat := createTestCategory(t, queries, "Best Game", "single", "closed", "live")
+
+	req := httptest.NewRequest(http.MethodGet, web.AdminCategoryURL(999), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
 	rr := httptest.NewRecorder()
 	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing poll, got %d", rr.Code)
+	}
 
-	// Should show form with error
-	body := rr.Body.String()
-	if !strings.Contains(body, "option") {
-		t.Error("expected error about needing options")
+	req = httptest.NewRequest(http.MethodPost, web.AdminCategoryCloseURL(999), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	req.Header.Set("HX-Request", "true")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 closing a missing poll, got %d", rr.Code)
 	}
 
-	// Status should remain closed
-	cat, _ := queries.GetCategory(t.Context(), 1)
-	if cat.Status != "closed" {
-		t.Errorf("expected status to remain 'closed', got '%s'", cat.Status)
+	// A failing database is an error, not a missing poll
+	conn.Close()
+	req = httptest.NewRequest(http.MethodGet, web.AdminCategoryURL(cat.ID), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 when the database fails, got %d", rr.Code)
 	}
 }
 
-func TestHTMX_ReopenNoOptions(t *testing.T) {
-	srv, queries, conn := testServerModern(t)
-	defer conn.Close()
+func TestErrorPages(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
 
-	createTestCategory(t, queries, "Closed Poll", "single", "closed", "live")
-	// No options
+			game := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			createTestCategory(t, queries, "Best Gadget", "single", "draft", "live")
+			handler := srv.Handler()
 
-	handler := srv.Handler()
+			rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, "/vote/best-game", nil)
+			if rr.Code != http.StatusNotFound {
+				t.Fatalf("expected status 404, got %d", rr.Code)
+			}
+			body := rr.Body.String()
+			if !strings.Contains(body, "Not Found") || !strings.Contains(body, "VOTIGO") {
+				t.Error("expected the themed not found page")
+			}
+			if !strings.Contains(body, `href="`+web.VoteURL(game.ID)+`"`) {
+				t.Error("expected the matching open poll to be suggested")
+			}
+			if strings.Contains(body, "Best Gadget") {
+				t.Error("expected drafts not to be suggested to voters")
+			}
+
+			// Admins are offered drafts too, linked to their admin pages
+			req := httptest.NewRequest(http.MethodGet, "/admin/best-gadget", nil)
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != http.StatusNotFound || !strings.Contains(rr.Body.String(), "Best Gadget") {
+				t.Errorf("expected a 404 suggesting the draft, got %d", rr.Code)
+			}
+
+			rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, "/api/v2/polls", nil)
+			if rr.Code != http.StatusNotFound || !strings.Contains(rr.Body.String(), `"error"`) {
+				t.Errorf("expected a JSON 404 under /api, got %d %s", rr.Code, rr.Body.String())
+			}
+
+			req = httptest.NewRequest(http.MethodGet, web.AdminHighContrastURL(), nil)
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != http.StatusMethodNotAllowed || rr.Header().Get("Allow") != http.MethodPost {
+				t.Errorf("expected 405 allowing POST, got %d (Allow %q)", rr.Code, rr.Header().Get("Allow"))
+			}
+			if !strings.Contains(rr.Body.String(), "Not Allowed") {
+				t.Error("expected the themed method not allowed page")
+			}
+		})
+	}
+}
+
+func TestAdminReopen_NoOptions(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	// Create closed category with no options
+	createTestCategory(t, queries, "Closed Poll", "single", "closed", "live")
+
+	handler := srv.Handler()
+	req := httptest.NewRequest(http.MethodPost, "/admin/category/1/reopen", nil)
+	req.SetBasicAuth("admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	// Should show form with error
+	body := rr.Body.String()
+	if !strings.Contains(body, "option") {
+		t.Error("expected error about needing options")
+	}
+
+	// Status should remain closed
+	cat, _ := queries.GetCategory(t.Context(), 1)
+	if cat.Status != "closed" {
+		t.Errorf("expected status to remain 'closed', got '%s'", cat.Status)
+	}
+}
+
+func TestHTMX_ReopenNoOptions(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	createTestCategory(t, queries, "Closed Poll", "single", "closed", "live")
+	// No options
+
+	handler := srv.Handler()
 	req := httptest.NewRequest(http.MethodPost, "/admin/category/1/reopen", nil)
 	req.Header.Set("HX-Request", "true")
 	req.SetBasicAuth("admin", testAdminPassword)
@@ -2076,6 +3036,71 @@ func TestHandleResultsTable_NotFound(t *testing.T) {
 	}
 }
 
+func TestHandleResultsTable_Since(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Best Booth", "single", "open", "live")
+	first := createTestOption(t, queries, cat.ID, "Retro Corner")
+	second := createTestOption(t, queries, cat.ID, "Pinball Alley")
+	handler := srv.Handler()
+	vote := func(nickname string, opt db.Option) {
+		t.Helper()
+		rr := postJSON(t, handler, web.APICategoryVotesURL(cat.ID), `{"nickname":"`+nickname+`","choices":[`+strconv.FormatInt(opt.ID, 10)+`]}`)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("vote failed: %d %s", rr.Code, rr.Body.String())
+		}
+	}
+	get := func(url string) *httptest.ResponseRecorder {
+		return makeRequest(t, handler.ServeHTTP, http.MethodGet, url, nil)
+	}
+	version := func() int64 {
+		c, err := queries.Category(t.Context(), cat.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.ResultsVersion
+	}
+	vote("one", first)
+	vote("two", second)
+
+	// The page polls from the version it shows
+	v := version()
+	rr := get(web.ResultsURL(cat.ID))
+	if want := "/table?since=" + strconv.FormatInt(v, 10); !strings.Contains(rr.Body.String(), want) {
+		t.Fatalf("expected the page to poll with %q", want)
+	}
+	table := web.ResultsTableURL(cat.ID)
+	if rr := get(table + "?since=" + strconv.FormatInt(v, 10)); rr.Code != http.StatusNoContent || rr.Body.Len() != 0 {
+		t.Fatalf("expected 204 with nothing changed, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// A vote for the runner-up swaps both places, and nothing else
+	vote("three", second)
+	vote("four", second)
+	if version() == v {
+		t.Fatal("expected votes to bump the results version")
+	}
+	rr = get(table + "?since=" + strconv.FormatInt(v, 10))
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK || !strings.Contains(body, `id="results-row-0"`) || !strings.Contains(body, `id="results-row-1"`) {
+		t.Fatalf("expected both rows swapped, got %d: %s", rr.Code, body)
+	}
+	if strings.Contains(body, `id="results-table"`) || !strings.Contains(body, "since="+strconv.FormatInt(version(), 10)) {
+		t.Errorf("expected only rows and the next poller, got %s", body)
+	}
+
+	// A version no longer kept, or none, gets the whole table
+	rr = get(table + "?since=")
+	if !strings.Contains(rr.Body.String(), `id="results-table" hx-swap-oob="innerHTML"`) {
+		t.Errorf("expected the whole table for an unknown version, got %s", rr.Body.String())
+	}
+	rr = get(table)
+	if body := rr.Body.String(); strings.Contains(body, "hx-swap-oob") || !strings.Contains(body, "Pinball Alley") {
+		t.Errorf("expected the plain table without since, got %s", body)
+	}
+}
+
 func TestHandleResultsTable_InvalidID(t *testing.T) {
 	srv, _, conn := testServerModern(t)
 	defer conn.Close()
@@ -2104,6 +3129,23 @@ func TestRouteHelpers(t *testing.T) {
 		{"ResultsListURL", web.ResultsListURL, "/results"},
 		{"AdminURL", web.AdminURL, "/admin"},
 		{"AdminCategoryNewURL", web.AdminCategoryNewURL, "/admin/category/new"},
+		{"APIFeedURL", web.APIFeedURL, "/api/v1/feed"},
+		{"APICategoriesURL", web.APICategoriesURL, "/api/v1/categories"},
+		{"AdminSearchURL", web.AdminSearchURL, "/admin/search"},
+		{"AdminLinksURL", web.AdminLinksURL, "/admin/links"},
+		{"AdminVoterViewURL", web.AdminVoterViewURL, "/admin/voter-view"},
+		{"EventsURL", web.EventsURL, "/events"},
+		{"AdminCeremonyURL", web.AdminCeremonyURL, "/admin/ceremony"},
+		{"AdminCelebrateURL", web.AdminCelebrateURL, "/admin/ceremony/celebrate"},
+		{"DisplayURL", web.DisplayURL, "/display"},
+		{"LeaderboardURL", web.LeaderboardURL, "/leaderboard"},
+		{"AdminCeremonyShowURL", web.AdminCeremonyShowURL, "/admin/ceremony/show"},
+		{"AdminCeremonyRevealURL", web.AdminCeremonyRevealURL, "/admin/ceremony/reveal"},
+		{"AdminCeremonyNextURL", web.AdminCeremonyNextURL, "/admin/ceremony/next"},
+		{"AdminSoundsURL", web.AdminSoundsURL, "/admin/sounds"},
+		{"AdminDeleteSoundURL", web.AdminDeleteSoundURL, "/admin/sounds/delete"},
+		{"AdminLeaderboardExportURL", web.AdminLeaderboardExportURL, "/admin/leaderboard.csv"},
+		{"AdminImportURL", web.AdminImportURL, "/admin/import"},
 	}
 
 	for _, tt := range tests {
@@ -2123,14 +3165,19 @@ func TestRouteHelpersWithID(t *testing.T) {
 		id       int64
 		expected string
 	}{
-		{"VoteURL", web.VoteURL, 42, "/vote/42"},
-		{"ResultsURL", web.ResultsURL, 42, "/results/42"},
-		{"ResultsTableURL", web.ResultsTableURL, 42, "/results/42/table"},
+		{"VoteURL", web.VoteURL[int64], 42, "/vote/42"},
+		{"VoteWidgetURL", web.VoteWidgetURL[int64], 42, "/vote/42/widget"},
+		{"ResultsURL", web.ResultsURL[int64], 42, "/results/42"},
+		{"ResultsTableURL", web.ResultsTableURL[int64], 42, "/results/42/table"},
+		{"ResultsCardURL", web.ResultsCardURL[int64], 42, "/results/42/card.png"},
 		{"AdminCategoryOpenURL", web.AdminCategoryOpenURL, 42, "/admin/category/42/open"},
 		{"AdminCategoryCloseURL", web.AdminCategoryCloseURL, 42, "/admin/category/42/close"},
 		{"AdminCategoryArchiveURL", web.AdminCategoryArchiveURL, 42, "/admin/category/42/archive"},
+		{"AdminCategorySeedURL", web.AdminCategorySeedURL, 42, "/admin/category/42/seed"},
+		{"AdminCategoryRunoffURL", web.AdminCategoryRunoffURL, 42, "/admin/category/42/runoff"},
 		{"AdminAddOptionURL", web.AdminAddOptionURL, 42, "/admin/category/42/option/add"},
 		{"AdminOptionURL", web.AdminOptionURL, 42, "/admin/option/42"},
+		{"AdminOptionImageURL", web.AdminOptionImageURL, 42, "/admin/option/42/image"},
 	}
 
 	for _, tt := range tests {
@@ -2143,6 +3190,29 @@ func TestRouteHelpersWithID(t *testing.T) {
 	}
 }
 
+func TestRouteHelpersWithSlug(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(string) string
+		expected string
+	}{
+		{"VoteURL", web.VoteURL[string], "/vote/best-game"},
+		{"VoteWidgetURL", web.VoteWidgetURL[string], "/vote/best-game/widget"},
+		{"ResultsURL", web.ResultsURL[string], "/results/best-game"},
+		{"ResultsTableURL", web.ResultsTableURL[string], "/results/best-game/table"},
+		{"ResultsCardURL", web.ResultsCardURL[string], "/results/best-game/card.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.fn("best-game")
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
 func TestAdminCategoryURL(t *testing.T) {
 	// Without anchor
 	result := web.AdminCategoryURL(42)
@@ -2199,3 +3269,4943 @@ func TestResultsRouteTrailingSlash(t *testing.T) {
 		t.Errorf("expected status 301 redirect for /results, got %d", rr.Code)
 	}
 }
+
+// ====================
+// PWA AND VOTE API TESTS
+// ====================
+
+func postJSON(t *testing.T, handler http.Handler, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAPIVote_RankedBallot(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Ranked Poll", "ranked", "open", "live")
+	a := createTestOption(t, queries, cat.ID, "A")
+	b := createTestOption(t, queries, cat.ID, "B")
+
+	path := web.APICategoryVotesURL(cat.ID)
+	body := `{"nickname":" Offline ","choices":[` + strconv.FormatInt(b.ID, 10) + `,` + strconv.FormatInt(a.ID, 10) + `]}`
+	rr := postJSON(t, srv.Handler(), path, body)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON content type, got %q", ct)
+	}
+	if !strings.Contains(rr.Body.String(), `"nickname":"offline"`) {
+		t.Errorf("expected normalized nickname in response, got %s", rr.Body.String())
+	}
+
+	tally, _ := queries.TallyRanked(t.Context(), db.TallyRankedParams{
+		MaxRank:    sql.NullInt64{Int64: 3, Valid: true},
+		CategoryID: cat.ID,
+	})
+	if len(tally) == 0 || tally[0].ID != b.ID {
+		t.Errorf("expected B ranked first, got %+v", tally)
+	}
+
+	// Re-syncing the same ballot replaces rather than duplicates it
+	postJSON(t, srv.Handler(), path, body)
+	count, _ := queries.CountVotesByCategory(t.Context(), cat.ID)
+	if count != 1 {
+		t.Errorf("expected 1 vote after resync, got %d", count)
+	}
+}
+
+func TestAPIVote_Rejections(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	open := createTestCategory(t, queries, "Open", "single", "open", "live")
+	opt := createTestOption(t, queries, open.ID, "A")
+	closed := createTestCategory(t, queries, "Closed", "single", "closed", "live")
+	other := createTestCategory(t, queries, "Other", "single", "open", "live")
+	foreign := createTestOption(t, queries, other.ID, "Foreign")
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"bad json", web.APICategoryVotesURL(open.ID), `{`, http.StatusBadRequest},
+		{"missing nickname", web.APICategoryVotesURL(open.ID), `{"choices":[` + strconv.FormatInt(opt.ID, 10) + `]}`, http.StatusBadRequest},
+		{"option from another category", web.APICategoryVotesURL(open.ID), `{"nickname":"x","choices":[` + strconv.FormatInt(foreign.ID, 10) + `]}`, http.StatusBadRequest},
+		{"closed category", web.APICategoryVotesURL(closed.ID), `{"nickname":"x","choices":[1]}`, http.StatusConflict},
+		{"unknown category", web.APICategoryVotesURL(999), `{"nickname":"x","choices":[1]}`, http.StatusNotFound},
+		{"unknown endpoint", "/api/v1/nope", `{}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := postJSON(t, srv.Handler(), tt.path, tt.body)
+			if rr.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), `"error"`) {
+				t.Errorf("expected JSON error body, got %s", rr.Body.String())
+			}
+		})
+	}
+
+	count, _ := queries.CountVotesByCategory(t.Context(), open.ID)
+	if count != 0 {
+		t.Errorf("expected no votes recorded, got %d", count)
+	}
+}
+
+func TestAPICategories(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+	handler := srv.Handler()
+
+	open := createTestCategory(t, queries, "Best Game", "ranked", "open", "after_close")
+	tetris := createTestOption(t, queries, open.ID, "Tetris")
+	doom := createTestOption(t, queries, open.ID, "Doom")
+	queries.RetireOption(t.Context(), doom.ID)
+	queries.SetOptionDetails(t.Context(), db.SetOptionDetailsParams{ID: tetris.ID, Blurb: "Falling blocks", Screenshots: "/images/a.png\n/images/b.png"})
+	closed := createTestCategory(t, queries, "Best Map", "single", "closed", "live")
+	draft := createTestCategory(t, queries, "Best Mod", "single", "draft", "live")
+
+	get := func(path string, admin bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if admin {
+			addBasicAuth(req, "admin", testAdminPassword)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	type category struct {
+		ID             int64  `json:"id"`
+		Name           string `json:"name"`
+		Status         string `json:"status"`
+		MaxRank        int64  `json:"max_rank"`
+		ResultsVisible bool   `json:"results_visible"`
+		Options        []struct {
+			ID          int64    `json:"id"`
+			Name        string   `json:"name"`
+			Blurb       string   `json:"blurb"`
+			Screenshots []string `json:"screenshots"`
+		} `json:"options"`
+	}
+	list := func(path string, admin bool) []string {
+		var body struct {
+			Categories []category `json:"categories"`
+		}
+		rr := get(path, admin)
+		if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("expected a list for %s, got %d: %s", path, rr.Code, rr.Body.String())
+		}
+		var names []string
+		for _, c := range body.Categories {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+
+	if got := list(web.APICategoriesURL(), false); !slices.Equal(got, []string{"Best Game", "Best Map"}) {
+		t.Errorf("expected the open and closed polls, got %v", got)
+	}
+	if got := list(web.APICategoriesURL()+"?status=open", false); !slices.Equal(got, []string{"Best Game"}) {
+		t.Errorf("expected only the open poll, got %v", got)
+	}
+	if got := list(web.APICategoriesURL(), true); len(got) != 3 {
+		t.Errorf("expected admins to see the draft too, got %v", got)
+	}
+
+	var cat category
+	rr := get(web.APICategoryURL(open.ID), false)
+	if err := json.Unmarshal(rr.Body.Bytes(), &cat); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if cat.ID != open.ID || cat.MaxRank != 3 || cat.ResultsVisible || len(cat.Options) != 1 ||
+		cat.Options[0].Name != "Tetris" || cat.Options[0].Blurb != "Falling blocks" || len(cat.Options[0].Screenshots) != 2 {
+		t.Errorf("expected the poll with its one ballot option, got %+v", cat)
+	}
+	if rr := get(web.APICategoryURL(closed.ID), false); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"results_visible":true`) {
+		t.Errorf("expected the closed poll's results visible, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := get(web.APICategoryURL(draft.ID), false); rr.Code != http.StatusNotFound {
+		t.Errorf("expected a draft to be hidden from voters, got %d", rr.Code)
+	}
+	if rr := get(web.APICategoryURL(draft.ID), true); rr.Code != http.StatusOK {
+		t.Errorf("expected admins to see a draft, got %d", rr.Code)
+	}
+
+	// Every refusal is a JSON error
+	request := func(method, path, contentType, accept string) *http.Request {
+		req := httptest.NewRequest(method, path, strings.NewReader("nickname=x"))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		return req
+	}
+	refused := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"unknown poll", request(http.MethodGet, web.APICategoryURL(999), "", ""), http.StatusNotFound},
+		{"bad status", request(http.MethodGet, web.APICategoriesURL()+"?status=archived", "", ""), http.StatusBadRequest},
+		{"write", request(http.MethodPost, web.APICategoriesURL(), "", ""), http.StatusMethodNotAllowed},
+		{"html wanted", request(http.MethodGet, web.APICategoriesURL(), "", "text/html"), http.StatusNotAcceptable},
+		{"form body", request(http.MethodPost, web.APICategoryVotesURL(open.ID), "application/x-www-form-urlencoded", ""), http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range refused {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, tt.req)
+		if rr.Code != tt.status || rr.Header().Get("Content-Type") != "application/json" || !strings.Contains(rr.Body.String(), `"error"`) {
+			t.Errorf("%s: expected a %d JSON error, got %d %s: %s", tt.name, tt.status, rr.Code, rr.Header().Get("Content-Type"), rr.Body.String())
+		}
+	}
+
+	// Browsers asking for anything still get JSON
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, request(http.MethodGet, web.APICategoriesURL(), "", "text/html,application/xhtml+xml,*/*;q=0.8"))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected a wildcard Accept to get JSON, got %d", rr.Code)
+	}
+}
+
+func TestAPIVote_MethodNotAllowed(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Open", "single", "open", "live")
+
+	rr := makeRequest(t, srv.Handler().ServeHTTP, http.MethodGet, web.APICategoryVotesURL(cat.ID), nil)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rr.Code)
+	}
+	if allow := rr.Header().Get("Allow"); allow != http.MethodPost {
+		t.Errorf("expected Allow: POST, got %q", allow)
+	}
+}
+
+func TestPWA_ManifestAndServiceWorker(t *testing.T) {
+	srv, _, conn := testServerModern(t)
+	defer conn.Close()
+
+	handler := srv.Handler()
+
+	rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, "/", nil)
+	body := rr.Body.String()
+	if !strings.Contains(body, `rel="manifest"`) || !strings.Contains(body, "/static/js/offline.js") {
+		t.Error("expected layout to link the manifest and offline script")
+	}
+
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, "/static/manifest.json", nil)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"start_url"`) {
+		t.Errorf("expected manifest, got %d", rr.Code)
+	}
+
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, "/sw.js", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected service worker at root scope, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/javascript") {
+		t.Errorf("expected javascript content type, got %q", ct)
+	}
+}
+
+func TestPWA_VoteFormCarriesBallotMetadata(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Ranked Poll", "ranked", "open", "live")
+	createTestOption(t, queries, cat.ID, "A")
+
+	rr := makeRequest(t, srv.Handler().ServeHTTP, http.MethodGet, web.VoteURL(cat.ID), nil)
+	body := rr.Body.String()
+	if !strings.Contains(body, `data-category-id="`+strconv.FormatInt(cat.ID, 10)+`"`) ||
+		!strings.Contains(body, `data-vote-type="ranked"`) {
+		t.Error("expected vote form to expose category id and vote type for offline queueing")
+	}
+}
+
+func TestPWA_NoServiceWorkerInLegacyMode(t *testing.T) {
+	srv, _, conn := testServer(t)
+	defer conn.Close()
+
+	rr := makeRequest(t, srv.Handler().ServeHTTP, http.MethodGet, "/sw.js", nil)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for service worker in legacy mode, got %d", rr.Code)
+	}
+}
+
+// ====================
+// ACCESSIBILITY TESTS
+// ====================
+
+func TestHandleVoteSubmit_RankedOrderedList(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat, err := queries.CreateCategory(t.Context(), db.CreateCategoryParams{
+		Name:        "Ranked Poll",
+		VoteType:    "ranked",
+		Status:      "open",
+		ShowResults: "live",
+		MaxRank:     sql.NullInt64{Int64: 2, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+	a := createTestOption(t, queries, cat.ID, "A")
+	b := createTestOption(t, queries, cat.ID, "B")
+	c := createTestOption(t, queries, cat.ID, "C")
+
+	// The orderable list posts every option; only the top MaxRank count
+	form := url.Values{}
+	form.Set("nickname", "keyboard")
+	for _, o := range []db.Option{c, a, b} {
+		form.Add("ranking", strconv.FormatInt(o.ID, 10))
+	}
+
+	rr := makeRequest(t, srv.Handler().ServeHTTP, http.MethodPost, web.VoteURL(cat.ID), form)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "VOTE RECORDED") {
+		t.Fatalf("expected vote recorded, got %d", rr.Code)
+	}
+
+	tally, _ := queries.TallyRanked(t.Context(), db.TallyRankedParams{
+		MaxRank:    cat.MaxRank,
+		CategoryID: cat.ID,
+	})
+	points := make(map[int64]any)
+	for _, row := range tally {
+		points[row.ID] = row.Points
+	}
+	if points[c.ID] != int64(2) || points[a.ID] != int64(1) || points[b.ID] != int64(0) {
+		t.Errorf("expected C=2 A=1 B=0, got %v", points)
+	}
+}
+
+func TestHandleVote_AccessibleMarkup(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Ranked Poll", "ranked", "open", "live")
+	createTestOption(t, queries, cat.ID, "A")
+
+	rr := makeRequest(t, srv.Handler().ServeHTTP, http.MethodGet, web.VoteURL(cat.ID), nil)
+	body := rr.Body.String()
+
+	for _, want := range []string{
+		`<label for="nickname"`,
+		`id="nickname"`,
+		`<legend`,
+		`name="ranking"`,
+		`aria-live="polite"`,
+		`href="#main"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected vote page to contain %q", want)
+		}
+	}
+}
+
+func TestHandleVoteSubmit_ErrorAnnounced(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Single Poll", "single", "open", "live")
+	createTestOption(t, queries, cat.ID, "A")
+
+	form := url.Values{}
+	form.Set("nickname", "")
+	req := httptest.NewRequest(http.MethodPost, web.VoteURL(cat.ID), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	if !strings.Contains(body, `role="alert"`) || !strings.Contains(body, `aria-invalid="true"`) {
+		t.Error("expected validation error to be announced and the field marked invalid")
+	}
+}
+
+func TestAdminHighContrast_Toggle(t *testing.T) {
+	tests := []struct {
+		mode   web.UIMode
+		marker string
+	}{
+		{web.UIModeLegacy, "#ffff00"},
+		{web.UIModeModern, "high-contrast"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			srv, _, conn := testServerWithMode(t, tt.mode)
+			defer conn.Close()
+
+			handler := srv.Handler()
+
+			form := url.Values{}
+			form.Set("enabled", "on")
+			req := httptest.NewRequest(http.MethodPost, web.AdminHighContrastURL(), strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected status 303, got %d", rr.Code)
+			}
+
+			rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, "/", nil)
+			if !strings.Contains(rr.Body.String(), tt.marker) {
+				t.Error("expected voter pages to use the high contrast theme")
+			}
+
+			req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if !strings.Contains(rr.Body.String(), "setting.update") {
+				t.Error("expected toggle to appear in the activity feed")
+			}
+		})
+	}
+}
+
+func TestAdminHighContrast_RequiresAuthAndPost(t *testing.T) {
+	srv, _, conn := testServer(t)
+	defer conn.Close()
+
+	handler := srv.Handler()
+
+	rr := makeRequest(t, handler.ServeHTTP, http.MethodPost, web.AdminHighContrastURL(), url.Values{"enabled": {"on"}})
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rr.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, web.AdminHighContrastURL(), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rr.Code)
+	}
+}
+
+func TestWithHighContrast(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	srv, err := web.NewServer(conn, testAdminPassword, web.UIModeModern, web.WithHighContrast(true))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	rr := makeRequest(t, srv.Handler().ServeHTTP, http.MethodGet, "/", nil)
+	if !strings.Contains(rr.Body.String(), "high-contrast") {
+		t.Error("expected body to carry the high-contrast class")
+	}
+}
+
+func TestAdminSettings(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	handler := srv.Handler()
+
+	req := httptest.NewRequest(http.MethodGet, web.AdminSettingsURL(), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `name="high_contrast"`) {
+		t.Error("expected a field for every setting")
+	}
+
+	// An invalid value is rejected and nothing is saved
+	form := url.Values{"high_contrast": {"maybe"}}
+	req = httptest.NewRequest(http.MethodPost, web.AdminSettingsURL(), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "must be true or false") {
+		t.Error("expected the validation error to be shown")
+	}
+
+	form = url.Values{"high_contrast": {"true"}}
+	req = httptest.NewRequest(http.MethodPost, web.AdminSettingsURL(), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected status 303, got %d", rr.Code)
+	}
+
+	on, err := queries.SettingBool(context.Background(), db.SettingHighContrast)
+	if err != nil || !on {
+		t.Fatalf("expected high contrast to be saved, got %v (%v)", on, err)
+	}
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, "/", nil)
+	if !strings.Contains(rr.Body.String(), "high-contrast") {
+		t.Error("expected the saved setting to apply to voter pages")
+	}
+
+	// A new server on the same database keeps the setting
+	srv2, err := web.NewServer(conn, testAdminPassword, web.UIModeLegacy)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	rr = makeRequest(t, srv2.Handler().ServeHTTP, http.MethodGet, "/", nil)
+	if !strings.Contains(rr.Body.String(), "#ffff00") {
+		t.Error("expected the setting to survive a restart")
+	}
+
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, web.AdminSettingsURL(), nil)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without auth, got %d", rr.Code)
+	}
+}
+
+// ====================
+// IDEMPOTENCY KEY TESTS
+// ====================
+
+func TestHandleVoteSubmit_ReplayedIdempotencyKey(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Single Poll", "single", "open", "live")
+	a := createTestOption(t, queries, cat.ID, "A")
+	b := createTestOption(t, queries, cat.ID, "B")
+
+	handler := srv.Handler()
+
+	submit := func(nickname string, choice int64) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Set("idempotency_key", "ballot-key-1")
+		form.Set("nickname", nickname)
+		form.Set("choice", strconv.FormatInt(choice, 10))
+		req := httptest.NewRequest(http.MethodPost, web.VoteURL(cat.ID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := submit("Player1", a.ID)
+	replay := submit("Player1", b.ID)
+
+	if replay.Code != http.StatusOK || !strings.Contains(replay.Body.String(), "VOTE RECORDED") {
+		t.Fatalf("expected replay to return the success response, got %d", replay.Code)
+	}
+	if first.Body.String() != replay.Body.String() {
+		t.Error("expected replay to match the original response")
+	}
+
+	// The replay must not have been processed
+	tally, _ := queries.TallySimple(t.Context(), cat.ID)
+	for _, row := range tally {
+		if row.ID == b.ID && row.Votes != 0 {
+			t.Error("expected replayed ballot to be ignored")
+		}
+	}
+
+	var events int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM audit_events WHERE action = 'vote'").Scan(&events); err != nil {
+		t.Fatalf("failed to count audit events: %v", err)
+	}
+	if events != 1 {
+		t.Errorf("expected 1 vote audit event, got %d", events)
+	}
+}
+
+func TestHandleVoteSubmit_IdempotencyKeyReused(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+
+			cat := createTestCategory(t, queries, "Single Poll", "single", "open", "live")
+			a := createTestOption(t, queries, cat.ID, "A")
+			handler := srv.Handler()
+
+			submit := func(nickname string) *httptest.ResponseRecorder {
+				form := url.Values{}
+				form.Set("idempotency_key", "shared-key")
+				form.Set("nickname", nickname)
+				form.Set("choice", strconv.FormatInt(a.ID, 10))
+				return makeRequest(t, handler.ServeHTTP, http.MethodPost, web.VoteURL(cat.ID), form)
+			}
+
+			submit("player1")
+			// Another voter sending the same form isn't a replay of the first ballot
+			rr := submit("player2")
+			if strings.Contains(strings.ToLower(rr.Body.String()), "vote recorded") {
+				t.Error("expected a ballot under a key another voter used not to be shown as recorded")
+			}
+			if !strings.Contains(rr.Body.String(), "already used for a different vote") {
+				t.Errorf("expected the voter told to vote again, got %q", rr.Body.String())
+			}
+			if n, _ := queries.CountVotesByCategory(t.Context(), cat.ID); n != 1 {
+				t.Errorf("expected only the first ballot saved, got %d", n)
+			}
+		})
+	}
+}
+
+func TestHandleVoteSubmit_InvalidatedVoter(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+
+			cat := createTestCategory(t, queries, "Single Poll", "single", "open", "live")
+			a := createTestOption(t, queries, cat.ID, "A")
+			handler := srv.Handler()
+
+			submit := func() *httptest.ResponseRecorder {
+				form := url.Values{}
+				form.Set("nickname", "mallory")
+				form.Set("choice", strconv.FormatInt(a.ID, 10))
+				return makeRequest(t, handler.ServeHTTP, http.MethodPost, web.VoteURL(cat.ID), form)
+			}
+
+			if rr := submit(); strings.Contains(rr.Body.String(), "marked your ballot invalid") {
+				t.Error("expected a counted ballot not to be called invalid")
+			}
+			if _, _, err := db.InvalidateBallots(t.Context(), conn, cat.ID, db.BallotFilter{Nicknames: []string{"mallory"}}, "banned", db.ActorAdmin); err != nil {
+				t.Fatal(err)
+			}
+
+			// Voting again doesn't make the ballot count, and says so
+			rr := submit()
+			if !strings.Contains(strings.ToLower(rr.Body.String()), "vote recorded") {
+				t.Fatalf("expected the ballot recorded, got %q", rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), "marked your ballot invalid") {
+				t.Errorf("expected the voter told their ballot is held as invalid, got %q", rr.Body.String())
+			}
+			if n, _ := queries.CountVotesByCategory(t.Context(), cat.ID); n != 0 {
+				t.Errorf("expected the ballot still not counted, got %d", n)
+			}
+
+			body := fmt.Sprintf(`{"nickname": "mallory", "choices": [%d]}`, a.ID)
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/categories/%d/votes", cat.ID), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			var resp struct {
+				Status string `json:"status"`
+				Held   string `json:"held"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("%v: %s", err, rr.Body.String())
+			}
+			if rr.Code != http.StatusCreated || resp.Status != "recorded" || !strings.Contains(resp.Held, "invalid") {
+				t.Errorf("expected the API to record the ballot and say it's held, got %d %+v", rr.Code, resp)
+			}
+		})
+	}
+}
+
+func TestHandleVote_FormCarriesIdempotencyKey(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Single Poll", "single", "open", "live")
+	createTestOption(t, queries, cat.ID, "A")
+
+	rr := makeRequest(t, srv.Handler().ServeHTTP, http.MethodGet, web.VoteURL(cat.ID), nil)
+	if !strings.Contains(rr.Body.String(), `name="idempotency_key" value="`) {
+		t.Error("expected ballot to include an idempotency key")
+	}
+
+	// A rejected ballot keeps its key so the corrected one is still deduplicated
+	form := url.Values{}
+	form.Set("idempotency_key", "kept-key")
+	form.Set("nickname", "")
+	rr = makeRequest(t, srv.Handler().ServeHTTP, http.MethodPost, web.VoteURL(cat.ID), form)
+	if !strings.Contains(rr.Body.String(), `value="kept-key"`) {
+		t.Error("expected error form to keep the idempotency key")
+	}
+}
+
+func TestAPIVote_IdempotencyKeyHeader(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Approval Poll", "approval", "open", "live")
+	a := createTestOption(t, queries, cat.ID, "A")
+	b := createTestOption(t, queries, cat.ID, "B")
+
+	handler := srv.Handler()
+	send := func(choice int64) *httptest.ResponseRecorder {
+		body := `{"nickname":"sync","choices":[` + strconv.FormatInt(choice, 10) + `]}`
+		req := httptest.NewRequest(http.MethodPost, web.APICategoryVotesURL(cat.ID), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "offline-1")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := send(a.ID); rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	if rr := send(b.ID); rr.Code != http.StatusCreated {
+		t.Fatalf("expected replay to succeed, got %d", rr.Code)
+	}
+
+	tally, _ := queries.TallySimple(t.Context(), cat.ID)
+	for _, row := range tally {
+		if row.ID == b.ID && row.Votes != 0 {
+			t.Error("expected replayed API ballot to be ignored")
+		}
+	}
+}
+
+func TestAPIVote_IdempotencyKeyReused(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	first := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+	second := createTestCategory(t, queries, "Best Map", "single", "open", "live")
+	tetris := createTestOption(t, queries, first.ID, "Tetris")
+	dm4 := createTestOption(t, queries, second.ID, "dm4")
+
+	handler := srv.Handler()
+	send := func(cat db.Category, nickname string, choice int64) *httptest.ResponseRecorder {
+		body := `{"nickname":"` + nickname + `","choices":[` + strconv.FormatInt(choice, 10) + `]}`
+		req := httptest.NewRequest(http.MethodPost, web.APICategoryVotesURL(cat.ID), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "kiosk-1")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := send(first, "alice", tetris.ID); rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	// The key already went to alice's Best Game ballot, so neither of these
+	// is a replay of it; both must be refused rather than dropped
+	for name, rr := range map[string]*httptest.ResponseRecorder{
+		"another poll":     send(second, "alice", dm4.ID),
+		"another nickname": send(first, "bob", tetris.ID),
+	} {
+		if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), `"error"`) || strings.Contains(rr.Body.String(), "recorded") {
+			t.Errorf("%s: expected a 422 error, got %d: %s", name, rr.Code, rr.Body.String())
+		}
+	}
+	if n, _ := queries.CountVotesByCategory(t.Context(), second.ID); n != 0 {
+		t.Errorf("expected no ballot in Best Map, got %d", n)
+	}
+	if n, _ := queries.CountVotesByCategory(t.Context(), first.ID); n != 1 {
+		t.Errorf("expected only alice's ballot in Best Game, got %d", n)
+	}
+}
+
+func TestAPIBallots(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	single := createTestCategory(t, queries, "Best Booth", "single", "open", "live")
+	booth := createTestOption(t, queries, single.ID, "Retro Corner")
+	ranked := createTestCategory(t, queries, "Top Maps", "ranked", "open", "live")
+	dust := createTestOption(t, queries, ranked.ID, "de_dust2")
+	gulch := createTestOption(t, queries, ranked.ID, "Blood Gulch")
+	closed := createTestCategory(t, queries, "Snacks", "single", "closed", "live")
+	pizza := createTestOption(t, queries, closed.ID, "Pizza")
+
+	handler := srv.Handler()
+	send := func(body string) (*httptest.ResponseRecorder, map[string]any) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, web.APIBallotsURL(), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "sync-1")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		var resp map[string]any
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("expected JSON, got %d: %s", rr.Code, rr.Body.String())
+		}
+		return rr, resp
+	}
+	id := strconv.FormatInt
+	votes := func(cat db.Category) int64 {
+		n, _ := queries.CountVotesByCategory(t.Context(), cat.ID)
+		return n
+	}
+
+	// One bad ballot keeps the good ones from being saved
+	rr, resp := send(`{"nickname":"Sync","ballots":[` +
+		`{"category_id":` + id(single.ID, 10) + `,"choices":[` + id(booth.ID, 10) + `]},` +
+		`{"category_id":` + id(ranked.ID, 10) + `,"choices":[` + id(dust.ID, 10) + `,` + id(dust.ID, 10) + `]},` +
+		`{"category_id":` + id(closed.ID, 10) + `,"choices":[` + id(pizza.ID, 10) + `]}]}`)
+	if rr.Code != http.StatusBadRequest || resp["status"] != "rejected" {
+		t.Fatalf("expected the batch rejected with 400, got %d: %s", rr.Code, rr.Body.String())
+	}
+	results := resp["ballots"].([]any)
+	for i, want := range []string{"not_saved", "rejected", "rejected"} {
+		if got := results[i].(map[string]any)["status"]; got != want {
+			t.Errorf("ballot %d: expected %s, got %v", i, want, got)
+		}
+	}
+	if msg := results[1].(map[string]any)["error"]; msg != "Each choice must be different" {
+		t.Errorf("expected the ranked ballot's own error, got %v", msg)
+	}
+	if votes(single) != 0 {
+		t.Fatal("expected nothing saved from a rejected batch")
+	}
+
+	// A good batch saves every ballot, with a receipt for each
+	good := `{"nickname":"Sync","ballots":[` +
+		`{"category_id":` + id(single.ID, 10) + `,"choices":[` + id(booth.ID, 10) + `]},` +
+		`{"category_id":` + id(ranked.ID, 10) + `,"choices":[` + id(gulch.ID, 10) + `,` + id(dust.ID, 10) + `]}]}`
+	rr, resp = send(good)
+	if rr.Code != http.StatusCreated || resp["status"] != "recorded" || resp["nickname"] != "sync" {
+		t.Fatalf("expected the batch recorded, got %d: %s", rr.Code, rr.Body.String())
+	}
+	for _, r := range resp["ballots"].([]any) {
+		if r := r.(map[string]any); r["status"] != "recorded" || r["receipt"] == "" {
+			t.Errorf("expected a recorded ballot with a receipt, got %v", r)
+		}
+	}
+	if votes(single) != 1 || votes(ranked) != 1 {
+		t.Errorf("expected one ballot in each poll, got %d and %d", votes(single), votes(ranked))
+	}
+
+	// Resending under the same key changes nothing
+	version := func() int64 {
+		c, _ := queries.Category(t.Context(), ranked.ID)
+		return c.ResultsVersion
+	}
+	before := version()
+	if rr, _ := send(good); rr.Code != http.StatusCreated {
+		t.Fatalf("expected the replay to succeed, got %d", rr.Code)
+	}
+	if version() != before {
+		t.Error("expected a replayed batch to leave the ballots alone")
+	}
+
+	for _, body := range []string{`{"nickname":"","ballots":[]}`, `{"nickname":"x","ballots":[]}`, `not json`} {
+		req := httptest.NewRequest(http.MethodPost, web.APIBallotsURL(), strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, rr.Code)
+		}
+	}
+}
+
+// ====================
+// VOTE HISTORY TESTS
+// ====================
+
+func TestHandleVoteSubmit_ReVoteKeepsHistory(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Single Poll", "single", "open", "live")
+	a := createTestOption(t, queries, cat.ID, "Alpha")
+	b := createTestOption(t, queries, cat.ID, "Bravo")
+	createTestOption(t, queries, cat.ID, "Charlie")
+
+	handler := srv.Handler()
+	for _, choice := range []int64{a.ID, b.ID} {
+		form := url.Values{}
+		form.Set("nickname", "FlipFlop")
+		form.Set("choice", strconv.FormatInt(choice, 10))
+		makeRequest(t, handler.ServeHTTP, http.MethodPost, web.VoteURL(cat.ID), form)
+	}
+
+	history, err := queries.ListVoteHistoryByCategory(t.Context(), cat.ID)
+	if err != nil {
+		t.Fatalf("failed to list history: %v", err)
+	}
+	if len(history) != 1 || history[0].Version != 1 || history[0].OptionName != "Alpha" {
+		t.Fatalf("expected v1 Alpha in history, got %+v", history)
+	}
+
+	churn, _ := queries.GetVoteChurn(t.Context(), cat.ID)
+	if churn.Voters != 1 || churn.ChangedVoters != 1 || churn.Changes != 1 {
+		t.Errorf("unexpected churn: %+v", churn)
+	}
+
+	// Current tally only reflects the latest ballot
+	tally, _ := queries.TallySimple(t.Context(), cat.ID)
+	for _, row := range tally {
+		if row.ID == a.ID && row.Votes != 0 {
+			t.Error("expected replaced ballot to be excluded from the tally")
+		}
+	}
+}
+
+func TestAdminCategory_ShowsVoteChanges(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+
+			cat := createTestCategory(t, queries, "Ranked Poll", "ranked", "open", "live")
+			a := createTestOption(t, queries, cat.ID, "Alpha")
+			b := createTestOption(t, queries, cat.ID, "Bravo")
+
+			handler := srv.Handler()
+			for _, first := range []db.Option{a, b} {
+				form := url.Values{}
+				form.Set("nickname", "waverer")
+				form.Set("rank1", strconv.FormatInt(first.ID, 10))
+				makeRequest(t, handler.ServeHTTP, http.MethodPost, web.VoteURL(cat.ID), form)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, web.AdminCategoryURL(cat.ID), nil)
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			body := rr.Body.String()
+			if !strings.Contains(body, "1 of 1 voters changed their vote") {
+				t.Error("expected churn summary on admin category page")
+			}
+			if !strings.Contains(body, "waverer") || !strings.Contains(body, "v1:") {
+				t.Error("expected voter's previous ballot on admin category page")
+			}
+		})
+	}
+}
+
+// ====================
+// FORGET VOTER TESTS
+// ====================
+
+func TestAdminForgetVoter(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	first := createTestCategory(t, queries, "First", "single", "open", "live")
+	a := createTestOption(t, queries, first.ID, "A")
+	second := createTestCategory(t, queries, "Second", "single", "open", "live")
+	b := createTestOption(t, queries, second.ID, "B")
+
+	handler := srv.Handler()
+	vote := func(nickname string, cat db.Category, opt db.Option) {
+		form := url.Values{}
+		form.Set("nickname", nickname)
+		form.Set("choice", strconv.FormatInt(opt.ID, 10))
+		makeRequest(t, handler.ServeHTTP, http.MethodPost, web.VoteURL(cat.ID), form)
+	}
+	vote("Leaver", first, a)
+	vote("Leaver", first, a) // re-vote leaves history behind
+	vote("Leaver", second, b)
+	vote("Stayer", first, a)
+
+	form := url.Values{}
+	form.Set("nickname", " LEAVER ")
+	req := httptest.NewRequest(http.MethodPost, web.AdminForgetVoterURL(), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected status 303, got %d", rr.Code)
+	}
+
+	for _, q := range []string{
+		"SELECT COUNT(*) FROM votes WHERE nickname = 'leaver'",
+		"SELECT COUNT(*) FROM vote_selection_history",
+		"SELECT COUNT(*) FROM audit_events WHERE actor = 'leaver'",
+	} {
+		var n int
+		if err := conn.QueryRow(q).Scan(&n); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		if n != 0 {
+			t.Errorf("%s: expected 0, got %d", q, n)
+		}
+	}
+
+	var anonymized int
+	conn.QueryRow("SELECT COUNT(*) FROM audit_events WHERE actor = ?", db.ForgottenActor).Scan(&anonymized)
+	if anonymized != 3 {
+		t.Errorf("expected 3 anonymized vote events, got %d", anonymized)
+	}
+
+	voters, _ := queries.ListVotersByCategory(t.Context(), first.ID)
+	if len(voters) != 1 || voters[0] != "stayer" {
+		t.Errorf("expected other voters to be untouched, got %v", voters)
+	}
+
+	var detail string
+	conn.QueryRow("SELECT detail FROM audit_events WHERE action = ?", db.AuditVoterForget).Scan(&detail)
+	if detail != "2 ballots" {
+		t.Errorf("expected audit detail without nickname, got %q", detail)
+	}
+}
+
+func TestAdminForgetVoter_RequiresNickname(t *testing.T) {
+	srv, _, conn := testServerModern(t)
+	defer conn.Close()
+
+	req := httptest.NewRequest(http.MethodPost, web.AdminForgetVoterURL(), strings.NewReader("nickname=+"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+// ====================
+// ENCRYPTED NICKNAME TESTS
+// ====================
+
+func TestEncryptedNicknames(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	nicknames, err := db.SetupEncryption(t.Context(), conn, "secret")
+	if err != nil {
+		t.Fatalf("failed to set up encryption: %v", err)
+	}
+
+	srv, err := web.NewServer(conn, testAdminPassword, web.UIModeLegacy, web.WithNicknameCipher(nicknames))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	queries := db.New(conn)
+
+	cat := createTestCategory(t, queries, "Poll", "single", "open", "live")
+	a := createTestOption(t, queries, cat.ID, "A")
+	b := createTestOption(t, queries, cat.ID, "B")
+
+	handler := srv.Handler()
+	for _, opt := range []db.Option{a, b} {
+		form := url.Values{}
+		form.Set("nickname", "Secretive")
+		form.Set("choice", strconv.FormatInt(opt.ID, 10))
+		rr := makeRequest(t, handler.ServeHTTP, http.MethodPost, web.VoteURL(cat.ID), form)
+		if !strings.Contains(rr.Body.String(), "VOTE RECORDED") {
+			t.Fatalf("expected vote to be recorded")
+		}
+	}
+
+	// Re-vote still matched the same (encrypted) voter
+	count, _ := queries.CountVotesByCategory(t.Context(), cat.ID)
+	if count != 1 {
+		t.Errorf("expected 1 vote, got %d", count)
+	}
+
+	for _, q := range []string{
+		"SELECT COUNT(*) FROM votes WHERE nickname LIKE '%secretive%'",
+		"SELECT COUNT(*) FROM audit_events WHERE actor LIKE '%secretive%'",
+	} {
+		var n int
+		conn.QueryRow(q).Scan(&n)
+		if n != 0 {
+			t.Errorf("%s: expected no plaintext nicknames, got %d", q, n)
+		}
+	}
+
+	// Admins still see the real nickname
+	req := httptest.NewRequest(http.MethodGet, web.AdminCategoryURL(cat.ID), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if !strings.Contains(rr.Body.String(), "secretive") {
+		t.Error("expected decrypted nickname in vote history")
+	}
+}
+
+// ====================
+// RESULTS API TESTS
+// ====================
+
+func getResults(handler http.Handler, path, etag string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAPIResults_ETag(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Ranked Poll", "ranked", "open", "live")
+	a := createTestOption(t, queries, cat.ID, "Alpha")
+	b := createTestOption(t, queries, cat.ID, "Bravo")
+
+	handler := srv.Handler()
+	postJSON(t, handler, web.APICategoryVotesURL(cat.ID),
+		`{"nickname":"one","choices":[`+strconv.FormatInt(b.ID, 10)+`,`+strconv.FormatInt(a.ID, 10)+`]}`)
+
+	rr := getResults(handler, web.APIResultsURL(cat.ID), "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header")
+	}
+
+	var res struct {
+		TotalVotes int64 `json:"total_votes"`
+		Results    []struct {
+			Name       string `json:"name"`
+			Points     int64  `json:"points"`
+			FirstPlace int64  `json:"first_place"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if res.TotalVotes != 1 || len(res.Results) != 2 || res.Results[0].Name != "Bravo" || res.Results[0].FirstPlace != 1 {
+		t.Errorf("unexpected results: %+v", res)
+	}
+
+	if rr := getResults(handler, web.APIResultsURL(cat.ID), etag); rr.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for matching If-None-Match, got %d", rr.Code)
+	}
+
+	postJSON(t, handler, web.APICategoryVotesURL(cat.ID),
+		`{"nickname":"two","choices":[`+strconv.FormatInt(a.ID, 10)+`]}`)
+
+	rr = getResults(handler, web.APIResultsURL(cat.ID), etag)
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") == etag {
+		t.Errorf("expected fresh results after a vote, got %d", rr.Code)
+	}
+}
+
+func TestResultsTies(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+
+			cat := createTestCategory(t, queries, "Best Booth", "single", "open", "live")
+			a := createTestOption(t, queries, cat.ID, "Alpha")
+			b := createTestOption(t, queries, cat.ID, "Bravo")
+			createTestOption(t, queries, cat.ID, "Charlie")
+			handler := srv.Handler()
+			voteFor(t, handler, cat.ID, a.ID, "one")
+			voteFor(t, handler, cat.ID, b.ID, "two")
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, web.ResultsURL(cat.ID), nil))
+			if body := rr.Body.String(); !strings.Contains(body, "Tied for place 1") {
+				t.Errorf("expected the shared first place marked on the results page, got:\n%s", body)
+			}
+
+			var res struct {
+				Results []struct {
+					Name  string `json:"name"`
+					Place int    `json:"place"`
+					Tied  bool   `json:"tied"`
+				} `json:"results"`
+			}
+			rr = getResults(handler, web.APIResultsURL(cat.ID), "")
+			if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if len(res.Results) != 3 || res.Results[0].Place != 1 || !res.Results[0].Tied ||
+				res.Results[1].Place != 1 || !res.Results[1].Tied || res.Results[2].Place != 3 || res.Results[2].Tied {
+				t.Errorf("expected Alpha and Bravo tied for first and Charlie third, got %+v", res.Results)
+			}
+		})
+	}
+}
+
+func TestWinningMargin(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+
+			// Best Booth is won 2-1, Best Map 3-0
+			poll := func(name string, votes ...int) db.Category {
+				cat := createTestCategory(t, queries, name, "single", "open", "live")
+				for i, n := range votes {
+					opt := createTestOption(t, queries, cat.ID, fmt.Sprintf("Option %d", i+1))
+					for v := range n {
+						voteFor(t, handler, cat.ID, opt.ID, fmt.Sprintf("voter%d%d", i, v))
+					}
+				}
+				queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{ID: cat.ID, Status: "closed"})
+				return cat
+			}
+			narrow := poll("Best Booth", 2, 1)
+			clear := poll("Best Map", 3, 0)
+
+			get := func(path string) string {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				addBasicAuth(req, "admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return strings.ToLower(rr.Body.String())
+			}
+			if body := get(web.ResultsURL(narrow.ID)); !strings.Contains(body, "won by 1 vote (33% of 3 ballots)") || !strings.Contains(body, "narrow win") {
+				t.Errorf("expected the one-vote win flagged on the results page, got:\n%s", body)
+			}
+			if body := get(web.ResultsURL(clear.ID)); !strings.Contains(body, "won by 3 votes") || strings.Contains(body, "narrow win") {
+				t.Errorf("expected a clear win not flagged, got:\n%s", body)
+			}
+			if body := get(web.AdminCeremonyURL()); strings.Count(body, "narrow win") != 1 {
+				t.Errorf("expected the console to flag only Best Booth, got:\n%s", body)
+			}
+		})
+	}
+}
+
+func TestAPIResults_LongPoll(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Single Poll", "single", "open", "live")
+	a := createTestOption(t, queries, cat.ID, "Alpha")
+
+	handler := srv.Handler()
+	etag := getResults(handler, web.APIResultsURL(cat.ID), "").Header().Get("ETag")
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- getResults(handler, web.APIResultsURL(cat.ID)+"?wait=10", etag)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	postJSON(t, handler, web.APICategoryVotesURL(cat.ID),
+		`{"nickname":"late","choices":[`+strconv.FormatInt(a.ID, 10)+`]}`)
+
+	select {
+	case rr := <-done:
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"total_votes":1`) {
+			t.Errorf("expected long-poll to return the new tally, got %d %s", rr.Code, rr.Body.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("long-poll did not return after a vote")
+	}
+
+	// Nothing changes: the wait runs out and the client keeps its copy
+	start := time.Now()
+	rr := getResults(handler, web.APIResultsURL(cat.ID)+"?wait=1", getResults(handler, web.APIResultsURL(cat.ID), "").Header().Get("ETag"))
+	if rr.Code != http.StatusNotModified || time.Since(start) < time.Second {
+		t.Errorf("expected 304 after waiting, got %d in %v", rr.Code, time.Since(start))
+	}
+}
+
+func TestAPIResults_HiddenAndErrors(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Secret Poll", "single", "open", "after_close")
+	createTestOption(t, queries, cat.ID, "Alpha")
+
+	handler := srv.Handler()
+	rr := getResults(handler, web.APIResultsURL(cat.ID), "")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"visible":false`) || strings.Contains(rr.Body.String(), "Alpha") {
+		t.Errorf("expected hidden results, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := getResults(handler, web.APIResultsURL(9999), ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown category, got %d", rr.Code)
+	}
+	if rr := getResults(handler, web.APIResultsURL(cat.ID)+"?wait=soon", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid wait, got %d", rr.Code)
+	}
+	if rr := postJSON(t, handler, web.APIResultsURL(cat.ID), `{}`); rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", rr.Code)
+	}
+}
+
+func TestResultsCaching(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Best Booth", "single", "open", "after_close")
+	opt := createTestOption(t, queries, cat.ID, "Retro Corner")
+	handler := srv.Handler()
+	postJSON(t, handler, web.APICategoryVotesURL(cat.ID), `{"nickname":"one","choices":[`+strconv.FormatInt(opt.ID, 10)+`]}`)
+
+	// Open polls are never cached
+	if rr := getResults(handler, web.ResultsURL(cat.ID), ""); rr.Header().Get("ETag") != "" {
+		t.Errorf("expected no ETag while voting is open, got %q", rr.Header().Get("ETag"))
+	}
+
+	queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{Status: "closed", ID: cat.ID})
+	rr := getResults(handler, web.ResultsURL(cat.ID), "")
+	closedETag := rr.Header().Get("ETag")
+	if closedETag == "" || rr.Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Fatalf("expected a briefly cached closed poll, got ETag %q, Cache-Control %q", closedETag, rr.Header().Get("Cache-Control"))
+	}
+	if rr := getResults(handler, web.ResultsURL(cat.ID), closedETag); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("expected 304 with no body for a matching ETag, got %d", rr.Code)
+	}
+
+	// Archiving is final, and an after_close poll keeps its results visible
+	queries.ArchiveCategory(t.Context(), cat.ID)
+	rr = getResults(handler, web.ResultsURL(cat.ID), closedETag)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Retro Corner") {
+		t.Fatalf("expected archived results to be shown afresh, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Cache-Control"); got != "public, max-age=86400" {
+		t.Errorf("expected archived results cached for a day, got %q", got)
+	}
+
+	rr = getResults(handler, web.APIResultsURL(cat.ID), "")
+	if !strings.Contains(rr.Body.String(), `"visible":true`) || rr.Header().Get("Cache-Control") != "public, max-age=86400" {
+		t.Errorf("expected cacheable visible results from the API, got %q: %s", rr.Header().Get("Cache-Control"), rr.Body.String())
+	}
+}
+
+func TestServerTimeouts(t *testing.T) {
+	_, queries, conn := testServer(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Best Booth", "single", "open", "live")
+	srv, err := web.NewServer(conn, testAdminPassword, web.UIModeLegacy, web.WithTimeouts(web.Timeouts{Handler: time.Nanosecond}))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	hs := srv.HTTPServer(":0")
+	if hs.ReadHeaderTimeout != web.DefaultTimeouts.ReadHeader || hs.IdleTimeout != web.DefaultTimeouts.Idle {
+		t.Errorf("expected unset timeouts to keep their defaults, got %v and %v", hs.ReadHeaderTimeout, hs.IdleTimeout)
+	}
+	if hs.MaxHeaderBytes == 0 || hs.MaxHeaderBytes >= http.DefaultMaxHeaderBytes {
+		t.Errorf("expected a tighter header limit than the default, got %d", hs.MaxHeaderBytes)
+	}
+
+	// A handler past its deadline can't query the database
+	handler := srv.Handler()
+	if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, "/", nil); rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected the expired handler to fail, got %d", rr.Code)
+	}
+
+	// Long-polls are given as long as they may wait
+	if rr := getResults(handler, web.APIResultsURL(cat.ID)+"?wait=0", ""); rr.Code != http.StatusOK {
+		t.Errorf("expected the long-poll to get its own deadline, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	_, _, conn := testServer(t)
+	defer conn.Close()
+
+	var accessLog strings.Builder
+	srv, err := web.NewServer(conn, testAdminPassword, web.UIModeLegacy, web.WithAccessLog(&accessLog))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := srv.Handler()
+
+	req := httptest.NewRequest(http.MethodGet, "/?page=2", nil)
+	req.RemoteAddr = "192.168.1.23:51234"
+	req.Header.Set("Referer", "http://tournament.lan/")
+	req.Header.Set("User-Agent", `Phone "Browser"`)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/nope", nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSuffix(accessLog.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %q", accessLog.String())
+	}
+	if !strings.HasPrefix(lines[0], "192.168.1.23 - - [") ||
+		!strings.Contains(lines[0], `] "GET /?page=2 HTTP/1.1" 200 `) ||
+		!strings.HasSuffix(lines[0], ` "http://tournament.lan/" "Phone \"Browser\""`) {
+		t.Errorf("unexpected combined log line: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], " - admin [") || !strings.Contains(lines[1], `"GET /admin/nope HTTP/1.1" 404 `) ||
+		!strings.HasSuffix(lines[1], ` "-" "-"`) {
+		t.Errorf("unexpected admin log line: %s", lines[1])
+	}
+}
+
+func TestAPIFeed(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+
+	later := createTestCategory(t, queries, "No Deadline", "single", "open", "after_close")
+	closesAt := time.Date(2026, 3, 14, 21, 30, 0, 0, time.UTC)
+	soon, err := queries.CreateCategory(t.Context(), db.CreateCategoryParams{
+		Name:        "Best Cosplay",
+		VoteType:    "single",
+		Status:      "open",
+		ShowResults: "live",
+		ClosesAt:    sql.NullTime{Time: closesAt, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+	createTestCategory(t, queries, "Draft Poll", "single", "draft", "live")
+	opt := createTestOption(t, queries, soon.ID, "Alpha")
+
+	handler := srv.Handler()
+	postJSON(t, handler, web.APICategoryVotesURL(soon.ID), `{"nickname":"one","choices":[`+strconv.FormatInt(opt.ID, 10)+`]}`)
+
+	rr := getResults(handler, web.APIFeedURL(), "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("expected CORS header *, got %q", got)
+	}
+	if got := rr.Header().Get("Cache-Control"); !strings.HasPrefix(got, "public") {
+		t.Errorf("expected public Cache-Control, got %q", got)
+	}
+
+	var feed struct {
+		Polls []struct {
+			ID         int64      `json:"id"`
+			Name       string     `json:"name"`
+			Votes      int64      `json:"votes"`
+			ClosesAt   *time.Time `json:"closes_at"`
+			VoteURL    string     `json:"vote_url"`
+			ResultsURL string     `json:"results_url"`
+		} `json:"polls"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("failed to decode feed: %v", err)
+	}
+	if len(feed.Polls) != 2 {
+		t.Fatalf("expected 2 open polls, got %+v", feed.Polls)
+	}
+
+	// Polls with a closing time come first
+	first, second := feed.Polls[0], feed.Polls[1]
+	if first.ID != soon.ID || second.ID != later.ID {
+		t.Errorf("expected %q then %q, got %q then %q", soon.Name, later.Name, first.Name, second.Name)
+	}
+	if first.ClosesAt == nil || !first.ClosesAt.Equal(closesAt) || second.ClosesAt != nil {
+		t.Errorf("expected closing time only on the first poll, got %v and %v", first.ClosesAt, second.ClosesAt)
+	}
+	if first.Votes != 1 {
+		t.Errorf("expected 1 vote, got %d", first.Votes)
+	}
+	if want := "http://example.com" + web.VoteURL(soon.ID); first.VoteURL != want {
+		t.Errorf("expected vote URL %q, got %q", want, first.VoteURL)
+	}
+	if first.ResultsURL == "" || second.ResultsURL != "" {
+		t.Errorf("expected results URL only for live results, got %q and %q", first.ResultsURL, second.ResultsURL)
+	}
+
+	etag := rr.Header().Get("ETag")
+	if rr := getResults(handler, web.APIFeedURL(), etag); rr.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for matching ETag, got %d", rr.Code)
+	}
+	if rr := postJSON(t, handler, web.APIFeedURL(), `{}`); rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", rr.Code)
+	}
+}
+
+// ====================
+// NOTIFICATION TESTS
+// ====================
+
+// recordingNotifier captures announced events for assertions
+type recordingNotifier struct {
+	events chan notify.Event
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, ev notify.Event) error {
+	n.events <- ev
+	return nil
+}
+
+func (n *recordingNotifier) next(t *testing.T) notify.Event {
+	t.Helper()
+	select {
+	case ev := <-n.events:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification")
+		return notify.Event{}
+	}
+}
+
+func TestAdminLifecycle_Announces(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	notifier := &recordingNotifier{events: make(chan notify.Event, 4)}
+	srv, err := web.NewServer(conn, testAdminPassword, web.UIModeLegacy, web.WithNotifier(notifier))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	queries := db.New(conn)
+
+	cat := createTestCategory(t, queries, "Best Game", "single", "draft", "after_close")
+	opt := createTestOption(t, queries, cat.ID, "Tetris")
+	handler := srv.Handler()
+
+	post := func(path string, form url.Values) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code >= 400 {
+			t.Fatalf("POST %s: unexpected status %d", path, rr.Code)
+		}
+	}
+
+	post(web.AdminCategoryOpenURL(cat.ID), nil)
+	if ev := notifier.next(t); ev.Type != notify.EventOpened || ev.Category != "Best Game" {
+		t.Errorf("expected opened event, got %+v", ev)
+	}
+
+	post(web.VoteURL(cat.ID), url.Values{"nickname": {"p1"}, "choice": {strconv.FormatInt(opt.ID, 10)}})
+	post(web.AdminCategoryCloseURL(cat.ID), nil)
+
+	if ev := notifier.next(t); ev.Type != notify.EventClosed {
+		t.Errorf("expected closed event, got %+v", ev)
+	}
+	ev := notifier.next(t)
+	if ev.Type != notify.EventResults || ev.TotalVotes != 1 || len(ev.Results) != 1 || ev.Results[0].Score != 1 {
+		t.Errorf("expected final results, got %+v", ev)
+	}
+}
+
+func TestViewModelPages(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+
+			single := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			tetris := createTestOption(t, queries, single.ID, "Tetris")
+			createTestOption(t, queries, single.ID, "Doom")
+			ranked := createTestCategory(t, queries, "Best Snack", "ranked", "open", "live")
+			createTestOption(t, queries, ranked.ID, "Chips")
+			hidden := createTestCategory(t, queries, "Best Costume", "single", "open", "after_close")
+			closed := createTestCategory(t, queries, "Best Map", "single", "closed", "live")
+
+			handler := srv.Handler()
+			if rr := voteFor(t, handler, single.ID, tetris.ID, "player1"); rr.Code != http.StatusOK {
+				t.Fatalf("vote: status %d", rr.Code)
+			}
+
+			pages := []struct {
+				path string
+				want string
+			}{
+				{web.VoteURL(single.ID), "Tetris"},
+				{web.VoteURL(ranked.ID), "Chips"},
+				{web.VoteWidgetURL(single.ID), "Tetris"},
+				{web.VoteWidgetURL(closed.ID), "Voting is not open"},
+				{web.ResultsURL(single.ID), "Tetris"},
+				{web.ResultsURL(ranked.ID), "Best Snack"},
+				{web.ResultsURL(hidden.ID), "Best Costume"},
+				{web.AdminURL(), "Best Game"},
+			}
+			for _, p := range pages {
+				req := httptest.NewRequest(http.MethodGet, p.path, nil)
+				addBasicAuth(req, "admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+
+				body := rr.Body.String()
+				if rr.Code != http.StatusOK {
+					t.Errorf("GET %s: status %d: %s", p.path, rr.Code, body)
+					continue
+				}
+				if !strings.Contains(body, p.want) {
+					t.Errorf("GET %s: expected %q in page", p.path, p.want)
+				}
+				if strings.Contains(body, "<no value>") {
+					t.Errorf("GET %s: page renders a missing field", p.path)
+				}
+			}
+
+			// A re-rendered ballot keeps what the voter typed
+			rr := makeRequest(t, handler.ServeHTTP, http.MethodPost, web.VoteURL(single.ID), url.Values{"nickname": {"player2"}})
+			if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "player2") {
+				t.Errorf("invalid ballot: status %d, nickname not kept", rr.Code)
+			}
+		})
+	}
+}
+
+func TestHTMXErrorToasts(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+
+	cat := createTestCategory(t, queries, "Empty Poll", "single", "draft", "live")
+	handler := srv.Handler()
+
+	htmxPost := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("HX-Request", "true")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		want   string
+	}{
+		{"open without options", http.MethodPost, web.AdminCategoryOpenURL(cat.ID), http.StatusBadRequest, "Add options first"},
+		{"add blank option", http.MethodPost, web.AdminCategoryURL(cat.ID) + "/option", http.StatusBadRequest, "Enter a name"},
+		{"delete missing option", http.MethodDelete, web.AdminOptionURL(999), http.StatusNotFound, "no longer exists"},
+		{"unknown admin page", http.MethodPost, "/admin/nope", http.StatusNotFound, "Not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := htmxPost(tt.method, tt.path)
+			if rr.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rr.Code)
+			}
+			if got := rr.Header().Get("HX-Retarget"); got != "#toasts" {
+				t.Errorf("HX-Retarget = %q, want #toasts", got)
+			}
+			if got := rr.Header().Get("HX-Reswap"); got != "beforeend" {
+				t.Errorf("HX-Reswap = %q, want beforeend", got)
+			}
+			body := rr.Body.String()
+			if !strings.Contains(body, `role="alert"`) || !strings.Contains(body, tt.want) {
+				t.Errorf("expected an error toast containing %q, got %q", tt.want, body)
+			}
+		})
+	}
+
+	// The layout has somewhere for the toasts to go
+	req := httptest.NewRequest(http.MethodGet, web.AdminURL(), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if !strings.Contains(rr.Body.String(), `id="toasts"`) || !strings.Contains(rr.Body.String(), "/static/js/toasts.js") {
+		t.Error("expected the layout to include the toast area and script")
+	}
+}
+
+func TestHTMXSuccessToasts(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+	handler := srv.Handler()
+
+	heats := createTestCategory(t, queries, "Heats", "single", "draft", "live")
+	tetris := createTestOption(t, queries, heats.ID, "Tetris")
+	final := createTestCategory(t, queries, "Final", "single", "draft", "live")
+
+	htmxPost := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	toastOf := func(t *testing.T, rr *httptest.ResponseRecorder) (kind, message string) {
+		t.Helper()
+		var trigger struct {
+			Toast struct {
+				Kind    string `json:"kind"`
+				Message string `json:"message"`
+			} `json:"toast"`
+		}
+		if err := json.Unmarshal([]byte(rr.Header().Get("HX-Trigger")), &trigger); err != nil {
+			t.Fatalf("expected a toast in HX-Trigger, got %q", rr.Header().Get("HX-Trigger"))
+		}
+		return trigger.Toast.Kind, trigger.Toast.Message
+	}
+
+	rr := htmxPost(web.AdminCategoryOpenURL(heats.ID), nil)
+	if kind, msg := toastOf(t, rr); rr.Code != http.StatusOK || kind != "success" || msg != "Voting is open for Heats" {
+		t.Errorf("open: status %d, toast %s %q", rr.Code, kind, msg)
+	}
+
+	rr = htmxPost(web.VoteURL(heats.ID), url.Values{"nickname": {"player1"}, "choice": {strconv.FormatInt(tetris.ID, 10)}})
+	if kind, _ := toastOf(t, rr); kind != "success" {
+		t.Errorf("vote: expected a success toast, got %s", kind)
+	}
+
+	htmxPost(web.AdminCategoryCloseURL(heats.ID), nil)
+
+	// Seeding answers with the new option rows instead of redirecting
+	rr = htmxPost(web.AdminCategorySeedURL(final.ID), url.Values{"source_id": {strconv.FormatInt(heats.ID, 10)}, "top_n": {"1"}})
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Tetris") {
+		t.Fatalf("seed: expected the seeded row, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if kind, msg := toastOf(t, rr); kind != "success" || msg != "Seeded from Heats: Tetris" {
+		t.Errorf("seed: toast %s %q", kind, msg)
+	}
+	rr = htmxPost(web.AdminCategorySeedURL(final.ID), url.Values{"source_id": {strconv.FormatInt(heats.ID, 10)}, "top_n": {"1"}})
+	if kind, _ := toastOf(t, rr); kind != "info" {
+		t.Errorf("seeding again: expected an info toast, got %s", kind)
+	}
+
+	rr = htmxPost("/admin/voters/forget", url.Values{"nickname": {"player1"}})
+	if kind, msg := toastOf(t, rr); rr.Code != http.StatusOK || kind != "success" || !strings.Contains(msg, "1 ballot") {
+		t.Errorf("forget: status %d, toast %s %q", rr.Code, kind, msg)
+	}
+
+	// Full-page requests still redirect, without a toast header
+	form := url.Values{"nickname": {"player2"}}
+	req := httptest.NewRequest(http.MethodPost, "/admin/voters/forget", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("HX-Trigger") != "" {
+		t.Errorf("forget without htmx: status %d, HX-Trigger %q", rr.Code, rr.Header().Get("HX-Trigger"))
+	}
+}
+
+func TestAdminSearch(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+	handler := srv.Handler()
+
+	game := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+	createTestCategory(t, queries, "Best Snack", "single", "draft", "live")
+	createTestCategory(t, queries, "Costume Contest", "single", "closed", "live")
+
+	search := func(q string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, web.AdminSearchURL()+"?q="+url.QueryEscape(q), nil)
+		req.Header.Set("HX-Request", "true")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := search("best")
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if !strings.Contains(body, "Best Game") || !strings.Contains(body, "Best Snack") || strings.Contains(body, "Costume Contest") {
+		t.Errorf("expected only the Best polls, got %s", body)
+	}
+	if !strings.Contains(body, web.AdminCategoryCloseURL(game.ID)) || !strings.Contains(body, `aria-keyshortcuts="c"`) {
+		t.Error("expected the open poll to offer a close action with its shortcut")
+	}
+	if strings.Contains(body, "New poll") {
+		t.Error("expected no new-poll command for an unrelated query")
+	}
+
+	// Typos still find the poll
+	if body := search("costme").Body.String(); !strings.Contains(body, "Costume Contest") {
+		t.Errorf("expected a fuzzy match, got %s", body)
+	}
+	if body := search("new").Body.String(); !strings.Contains(body, "New poll") {
+		t.Error("expected the new-poll command for \"new\"")
+	}
+	if body := search("zzzz").Body.String(); !strings.Contains(body, "No polls match") {
+		t.Errorf("expected an empty state, got %s", body)
+	}
+
+	// Admin only, GET only
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, web.AdminSearchURL(), nil)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without auth, got %d", rr.Code)
+	}
+	req := httptest.NewRequest(http.MethodPost, web.AdminSearchURL(), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", rr.Code)
+	}
+
+	// The dashboard carries the palette
+	req = httptest.NewRequest(http.MethodGet, web.AdminURL(), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if !strings.Contains(rr.Body.String(), `id="command-palette"`) || !strings.Contains(rr.Body.String(), "/static/js/palette.js") {
+		t.Error("expected the dashboard to include the command palette")
+	}
+}
+
+func TestAdminSearch_LegacyNotFound(t *testing.T) {
+	srv, _, conn := testServer(t)
+	defer conn.Close()
+
+	req := httptest.NewRequest(http.MethodGet, web.AdminSearchURL()+"?q=best", nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 in the legacy UI, got %d", rr.Code)
+	}
+}
+
+func TestCategorySlugs(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+	handler := srv.Handler()
+
+	admin := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	create := func(name, slug string) *httptest.ResponseRecorder {
+		return admin(web.AdminCategoryNewURL(), url.Values{
+			"name": {name}, "slug": {slug}, "vote_type": {"single"}, "show_results": {"live"},
+		})
+	}
+
+	// Slugs are generated from the name and numbered past clashes
+	if rr := create("Best Soundtrack", ""); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect after create, got %d: %s", rr.Code, rr.Body.String())
+	}
+	create("Best Soundtrack!", "")
+	first, _ := queries.GetCategoryBySlug(t.Context(), sql.NullString{String: "best-soundtrack", Valid: true})
+	second, _ := queries.GetCategoryBySlug(t.Context(), sql.NullString{String: "best-soundtrack-2", Valid: true})
+	if first.ID == 0 || second.ID != first.ID+1 {
+		t.Fatalf("expected generated slugs, got %+v and %+v", first, second)
+	}
+
+	// A chosen slug must be well formed and free
+	if rr := create("Other", "Not A Slug"); !strings.Contains(rr.Body.String(), "Slugs are lowercase") {
+		t.Errorf("expected a malformed slug to be refused, got %d", rr.Code)
+	}
+	if rr := create("Other", "best-soundtrack"); !strings.Contains(rr.Body.String(), "already uses that slug") {
+		t.Errorf("expected a taken slug to be refused, got %d", rr.Code)
+	}
+
+	// Renaming a poll's slug, or clearing it to regenerate one
+	rr := admin(web.AdminCategoryURL(second.ID), url.Values{
+		"name": {"Best Score"}, "slug": {"score"}, "vote_type": {"single"}, "show_results": {"live"},
+	})
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect after edit, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if cat, _ := queries.GetCategory(t.Context(), second.ID); cat.Ref() != "score" {
+		t.Errorf("expected the chosen slug, got %q", cat.Ref())
+	}
+	admin(web.AdminCategoryURL(second.ID), url.Values{
+		"name": {"Best Score"}, "slug": {""}, "vote_type": {"single"}, "show_results": {"live"},
+	})
+	if cat, _ := queries.GetCategory(t.Context(), second.ID); cat.Ref() != "best-score" {
+		t.Errorf("expected a slug from the new name, got %q", cat.Ref())
+	}
+
+	// Voter pages answer to both the slug and the ID, and link by slug
+	queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{Status: "open", ID: first.ID})
+	tetris := createTestOption(t, queries, first.ID, "Tetris")
+	for _, path := range []string{
+		web.VoteURL("best-soundtrack"), web.VoteURL(first.ID),
+		web.VoteWidgetURL("best-soundtrack"), web.ResultsURL("best-soundtrack"),
+		web.ResultsTableURL("best-soundtrack"),
+	} {
+		if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, path, nil); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Tetris") {
+			t.Errorf("expected %s to show the poll, got %d", path, rr.Code)
+		}
+	}
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, web.HomeURL(), nil)
+	if !strings.Contains(rr.Body.String(), `href="`+web.VoteURL("best-soundtrack")+`"`) {
+		t.Error("expected the home page to link to the poll by slug")
+	}
+
+	// Votes cast through the slug URL count
+	form := url.Values{"nickname": {"alice"}, "choice": {strconv.FormatInt(tetris.ID, 10)}}
+	makeRequest(t, handler.ServeHTTP, http.MethodPost, web.VoteURL("best-soundtrack"), form)
+	if n, _ := queries.CountVotesByCategory(t.Context(), first.ID); n != 1 {
+		t.Errorf("expected the vote to be recorded, got %d", n)
+	}
+
+	// An unknown slug is a 404 that suggests polls by name
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, web.VoteURL("best-soundtrak"), nil)
+	if rr.Code != http.StatusNotFound || !strings.Contains(rr.Body.String(), "Best Soundtrack") {
+		t.Errorf("expected a 404 with suggestions, got %d", rr.Code)
+	}
+}
+
+func TestShortLinks(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+	handler := srv.Handler()
+
+	draft := createTestCategory(t, queries, "Best Costume", "single", "draft", "live")
+	open := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+	closed := createTestCategory(t, queries, "Best Snack", "single", "closed", "live")
+
+	// Open polls go to the ballot, finished ones to results; codes are
+	// case-insensitive
+	for _, tt := range []struct {
+		path, location string
+	}{
+		{web.ShortLinkURL(open.ShortCode()), web.VoteURL(open.ID)},
+		{web.ShortLinkURL(strings.ToUpper(open.ShortCode())), web.VoteURL(open.ID)},
+		{web.ShortLinkURL(closed.ShortCode()), web.ResultsURL(closed.ID)},
+	} {
+		rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, tt.path, nil)
+		if rr.Code != http.StatusFound || rr.Header().Get("Location") != tt.location {
+			t.Errorf("expected %s to redirect to %s, got %d %s", tt.path, tt.location, rr.Code, rr.Header().Get("Location"))
+		}
+	}
+	for _, path := range []string{web.ShortLinkURL(draft.ShortCode()), web.ShortLinkURL("zzzz"), web.ShortLinkURL("")} {
+		if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, path, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("expected 404 for %s, got %d", path, rr.Code)
+		}
+	}
+
+	// The printable sheet lists polls that are open or about to be
+	req := httptest.NewRequest(http.MethodGet, web.AdminLinksURL(), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if !strings.Contains(body, draft.ShortCode()) || !strings.Contains(body, "http://example.com"+web.ShortLinkURL(open.ShortCode())) {
+		t.Errorf("expected the draft and open polls on the sheet, got %s", body)
+	}
+	if strings.Contains(body, "Best Snack") {
+		t.Error("expected closed polls to be left off the sheet")
+	}
+
+	// Info screens get the short link in the feed
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, web.APIFeedURL(), nil)
+	if !strings.Contains(rr.Body.String(), `"short_url":"http://example.com`+web.ShortLinkURL(open.ShortCode())+`"`) {
+		t.Errorf("expected the feed to include the short link, got %s", rr.Body.String())
+	}
+}
+
+func TestStations(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			opt := createTestOption(t, queries, cat.ID, "Tetris")
+
+			admin := func(path string, form url.Values) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				addBasicAuth(req, "admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+			pairingLink := regexp.MustCompile(`http://example.com(/pair/[A-Z0-9]+)`)
+
+			// Adding a station shows its pairing link and QR code once
+			rr := admin(web.AdminStationsURL(), url.Values{"name": {"Stage kiosk"}})
+			link := pairingLink.FindStringSubmatch(rr.Body.String())
+			if rr.Code != http.StatusOK || link == nil || !strings.Contains(rr.Body.String(), "data:image/png;base64,") {
+				t.Fatalf("expected a pairing link and QR code, got %d: %s", rr.Code, rr.Body.String())
+			}
+			if rr := admin(web.AdminStationsURL(), url.Values{"name": {"stage kiosk"}}); rr.Code != http.StatusBadRequest {
+				t.Errorf("expected a clashing name refused, got %d", rr.Code)
+			}
+
+			// The link pairs one device, once
+			rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, link[1], nil)
+			var device *http.Cookie
+			for _, c := range rr.Result().Cookies() {
+				if c.Name == "votigo_station" {
+					device = c
+				}
+			}
+			if rr.Code != http.StatusSeeOther || device == nil {
+				t.Fatalf("expected pairing to set the station cookie, got %d", rr.Code)
+			}
+			if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, link[1], nil); rr.Code != http.StatusNotFound {
+				t.Errorf("expected a used pairing link to be refused, got %d", rr.Code)
+			}
+
+			// Ballots from the kiosk are tagged with its station
+			vote := func(nickname string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, web.VoteURL(cat.ID), strings.NewReader(url.Values{
+					"nickname": {nickname}, "choice": {strconv.FormatInt(opt.ID, 10)},
+				}.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.AddCookie(device)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+			recorded := func(rr *httptest.ResponseRecorder) bool {
+				return strings.Contains(strings.ToLower(rr.Body.String()), "vote recorded")
+			}
+			if rr := vote("alice"); rr.Code != http.StatusOK || !recorded(rr) {
+				t.Fatalf("expected the kiosk's ballot recorded, got %d: %s", rr.Code, rr.Body.String())
+			}
+			var station string
+			err := conn.QueryRow(`SELECT s.name FROM audit_events a JOIN stations s ON s.id = a.station_id WHERE a.action = 'vote'`).Scan(&station)
+			if err != nil || station != "Stage kiosk" {
+				t.Fatalf("expected the vote tagged with the station, got %q, %v", station, err)
+			}
+
+			// A revoked station's ballots are refused, by form and API alike
+			stations, _ := queries.ListStations(t.Context())
+			if rr := admin(web.AdminStationRevokeURL(stations[0].ID), nil); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected revoking to redirect, got %d", rr.Code)
+			}
+			if rr := vote("bob"); recorded(rr) || !strings.Contains(rr.Body.String(), "taken out of service") {
+				t.Errorf("expected the revoked kiosk's ballot refused, got %d", rr.Code)
+			}
+			req := httptest.NewRequest(http.MethodPost, web.APICategoryVotesURL(cat.ID), strings.NewReader(`{"nickname":"bob","choices":[`+strconv.FormatInt(opt.ID, 10)+`]}`))
+			req.Header.Set("Authorization", "Bearer "+device.Value)
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != http.StatusForbidden {
+				t.Errorf("expected the API to refuse a revoked station's token, got %d", rr.Code)
+			}
+
+			// Pairing again brings it back with a new link; the old token stays dead
+			rr = admin(web.AdminStationPairURL(stations[0].ID), nil)
+			if pairingLink.FindStringSubmatch(rr.Body.String()) == nil {
+				t.Fatalf("expected a new pairing link, got %d", rr.Code)
+			}
+			if rr := vote("bob"); !strings.Contains(rr.Body.String(), "no longer paired") {
+				t.Errorf("expected the old device token refused, got %d", rr.Code)
+			}
+		})
+	}
+}
+
+func TestStationReport(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			opt := createTestOption(t, queries, cat.ID, "Tetris")
+
+			// A ballot from a paired kiosk keeps its station and address
+			st, token, err := queries.AddStation(t.Context(), "Stage kiosk")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var device string
+			err = db.InTx(t.Context(), conn, func(q *db.Queries) error {
+				_, device, err = q.Pair(t.Context(), token)
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodPost, web.APICategoryVotesURL(cat.ID), strings.NewReader(`{"nickname":"alice","choices":[`+strconv.FormatInt(opt.ID, 10)+`]}`))
+			req.Header.Set("Authorization", "Bearer "+device)
+			req.RemoteAddr = "192.168.1.20:5000"
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("expected the kiosk's ballot recorded, got %d: %s", rr.Code, rr.Body.String())
+			}
+			var address string
+			if err := conn.QueryRow(`SELECT address FROM audit_events WHERE action = 'vote' AND station_id = ?`, st.ID).Scan(&address); err != nil || address != "192.168.1.20" {
+				t.Fatalf("expected the ballot's address kept, got %q, %v", address, err)
+			}
+
+			// A touchscreen voting every few seconds from no station is flagged
+			for i := range 8 {
+				_, err := conn.Exec(`INSERT INTO audit_events (actor, action, category_id, address, created_at) VALUES ('ghost', 'vote', ?, '192.168.1.99', datetime(strftime('%Y-%m-%d %H:%M:00', 'now', '-10 minutes'), ?))`,
+					cat.ID, "+"+strconv.Itoa(i*5)+" seconds")
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			req = httptest.NewRequest(http.MethodGet, web.AdminStationReportURL(), nil)
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			body := rr.Body.String()
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected the report, got %d: %s", rr.Code, body)
+			}
+			for _, want := range []string{"Stage kiosk", "192.168.1.20", "192.168.1.99"} {
+				if !strings.Contains(body, want) {
+					t.Errorf("expected %q in the report", want)
+				}
+			}
+			if n := strings.Count(body, "BURST"); n != 1 {
+				t.Errorf("expected only the ghost address flagged, got %d flags", n)
+			}
+		})
+	}
+}
+
+func TestVoteConflicts(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			tetris := createTestOption(t, queries, cat.ID, "Tetris")
+			doom := createTestOption(t, queries, cat.ID, "Doom")
+
+			vote := func(nickname string, opt db.Option, addr string) {
+				t.Helper()
+				req := httptest.NewRequest(http.MethodPost, web.VoteURL(cat.ID), strings.NewReader(url.Values{
+					"nickname": {nickname}, "choice": {strconv.FormatInt(opt.ID, 10)},
+				}.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.RemoteAddr = addr + ":5000"
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				if rr.Code != http.StatusOK {
+					t.Fatalf("vote by %s: got %d: %s", nickname, rr.Code, rr.Body.String())
+				}
+			}
+			admin := func(method, path string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, path, nil)
+				addBasicAuth(req, "admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+			choice := func(nickname string) int64 {
+				t.Helper()
+				v, err := queries.GetVoteByNickname(t.Context(), db.GetVoteByNicknameParams{CategoryID: cat.ID, Nickname: nickname})
+				if err != nil {
+					t.Fatal(err)
+				}
+				sels, err := queries.ListSelectionsByVote(t.Context(), v.ID)
+				if err != nil || len(sels) != 1 {
+					t.Fatalf("expected one selection for %s, got %v, %v", nickname, sels, err)
+				}
+				return sels[0].OptionID
+			}
+
+			// Changing one's mind on the same device is no conflict
+			vote("bob", tetris, "192.168.1.10")
+			vote("bob", doom, "192.168.1.10")
+			// Two devices voting as one nickname seconds apart are
+			vote("alice", tetris, "192.168.1.10")
+			vote("alice", doom, "192.168.1.11")
+
+			var ids []int64
+			rows, err := conn.Query(`SELECT id FROM vote_conflicts ORDER BY id`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for rows.Next() {
+				var id int64
+				rows.Scan(&id)
+				ids = append(ids, id)
+			}
+			rows.Close()
+			if len(ids) != 1 {
+				t.Fatalf("expected one conflict, got %d", len(ids))
+			}
+
+			rr := admin(http.MethodGet, web.AdminCategoryURL(cat.ID))
+			body := rr.Body.String()
+			if !strings.Contains(body, web.AdminConflictURL(ids[0], db.KeptFirst)) || !strings.Contains(body, "192.168.1.11") {
+				t.Fatalf("expected the conflict on the poll's admin page, got %d", rr.Code)
+			}
+			if choice("alice") != doom.ID {
+				t.Error("expected the second ballot to count until resolved")
+			}
+
+			// Keeping the first ballot restores it; both stay in the history
+			if rr := admin(http.MethodPost, web.AdminConflictURL(ids[0], db.KeptFirst)); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected resolving to redirect, got %d: %s", rr.Code, rr.Body.String())
+			}
+			if choice("alice") != tetris.ID {
+				t.Error("expected the first ballot restored")
+			}
+			var versions int
+			conn.QueryRow(`SELECT COUNT(DISTINCT h.version) FROM vote_selection_history h JOIN votes v ON v.id = h.vote_id WHERE v.nickname = 'alice'`).Scan(&versions)
+			if versions != 2 {
+				t.Errorf("expected both replaced ballots kept in the history, got %d", versions)
+			}
+			if rr := admin(http.MethodPost, web.AdminConflictURL(ids[0], db.KeptSecond)); rr.Code != http.StatusConflict {
+				t.Errorf("expected a resolved conflict refused, got %d", rr.Code)
+			}
+			if body := admin(http.MethodGet, web.AdminCategoryURL(cat.ID)).Body.String(); strings.Contains(body, "/admin/conflicts/") {
+				t.Error("expected no open conflicts left")
+			}
+
+			// Once the voter has voted again, only the latest ballot can stand
+			vote("carol", tetris, "192.168.1.10")
+			vote("carol", doom, "192.168.1.12")
+			vote("carol", doom, "192.168.1.12")
+			var id int64
+			conn.QueryRow(`SELECT MAX(id) FROM vote_conflicts`).Scan(&id)
+			if rr := admin(http.MethodPost, web.AdminConflictURL(id, db.KeptFirst)); rr.Code != http.StatusConflict {
+				t.Errorf("expected keeping a stale first ballot refused, got %d", rr.Code)
+			}
+			if rr := admin(http.MethodPost, web.AdminConflictURL(id, db.KeptSecond)); rr.Code != http.StatusSeeOther {
+				t.Errorf("expected dismissing to redirect, got %d", rr.Code)
+			}
+			if choice("carol") != doom.ID {
+				t.Error("expected the latest ballot to stand")
+			}
+
+			// An archived poll's results are final
+			vote("dave", tetris, "192.168.1.10")
+			vote("dave", doom, "192.168.1.12")
+			if err := conn.QueryRow(`SELECT id FROM vote_conflicts WHERE resolved_at IS NULL`).Scan(&id); err != nil {
+				t.Fatalf("expected dave's ballots to conflict: %v", err)
+			}
+			queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{ID: cat.ID, Status: "archived"})
+			if rr := admin(http.MethodPost, web.AdminConflictURL(id, db.KeptFirst)); rr.Code != http.StatusConflict {
+				t.Errorf("expected resolving a conflict in an archived poll refused, got %d", rr.Code)
+			}
+			if choice("dave") != doom.ID {
+				t.Error("expected the archived poll's ballot left as it was")
+			}
+		})
+	}
+}
+
+func TestCategoryTestMode(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			cat := createTestCategory(t, queries, "Best Game", "single", "draft", "live")
+			opt := createTestOption(t, queries, cat.ID, "Tetris")
+
+			request := func(method, path string, form url.Values, admin bool) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				if admin {
+					addBasicAuth(req, "admin", testAdminPassword)
+				}
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+			ballot := url.Values{"nickname": {"alice"}, "choice": {strconv.FormatInt(opt.ID, 10)}}
+			countVotes := func() int64 {
+				n, _ := queries.CountVotesByCategory(t.Context(), cat.ID)
+				return n
+			}
+
+			if rr := request(http.MethodPost, web.AdminCategoryTestURL(cat.ID), url.Values{"enabled": {"on"}}, true); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected turning test mode on to redirect, got %d: %s", rr.Code, rr.Body.String())
+			}
+
+			// Only admins see and vote on a draft in test mode
+			if rr := request(http.MethodGet, web.VoteURL(cat.ID), nil, false); strings.Contains(rr.Body.String(), "TEST MODE") {
+				t.Error("expected voters kept off a draft in test mode")
+			}
+			request(http.MethodPost, web.VoteURL(cat.ID), ballot, false)
+			if n := countVotes(); n != 0 {
+				t.Fatalf("expected a voter's ballot refused, got %d", n)
+			}
+			if rr := request(http.MethodGet, web.VoteURL(cat.ID), nil, true); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "TEST MODE") {
+				t.Fatalf("expected the ballot flagged as a test for admins, got %d", rr.Code)
+			}
+			request(http.MethodPost, web.VoteURL(cat.ID), ballot, true)
+			if n := countVotes(); n != 1 {
+				t.Fatalf("expected the admin's test ballot recorded, got %d", n)
+			}
+			var action string
+			conn.QueryRow(`SELECT action FROM audit_events WHERE actor = 'alice'`).Scan(&action)
+			if action != db.AuditTestVote {
+				t.Errorf("expected the ballot audited as a test, got %q", action)
+			}
+			if !strings.Contains(request(http.MethodGet, web.AdminCategoryURL(cat.ID), nil, true).Body.String(), "TEST</span>") {
+				t.Error("expected the admin page to flag the test ballot")
+			}
+
+			// Opening the poll throws test ballots away and ends test mode
+			if rr := request(http.MethodPost, web.AdminCategoryOpenURL(cat.ID), nil, true); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected opening to redirect, got %d", rr.Code)
+			}
+			if n := countVotes(); n != 0 {
+				t.Errorf("expected test ballots purged on open, got %d", n)
+			}
+			opened, _ := queries.Category(t.Context(), cat.ID)
+			if opened.TestMode {
+				t.Error("expected test mode off once open")
+			}
+			if rr := request(http.MethodPost, web.AdminCategoryTestURL(cat.ID), url.Values{"enabled": {"on"}}, true); rr.Code != http.StatusConflict {
+				t.Errorf("expected test mode refused for an open poll, got %d", rr.Code)
+			}
+		})
+	}
+}
+
+func TestAdminBallotPreview(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			cat := createTestCategory(t, queries, "Best Game", "single", "draft", "live")
+			createTestOption(t, queries, cat.ID, "Tetris")
+			createTestOption(t, queries, cat.ID, "Doom")
+
+			preview := func(query string, htmx bool) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, web.AdminCategoryPreviewURL(cat.ID)+query, nil)
+				addBasicAuth(req, "admin", testAdminPassword)
+				if htmx {
+					req.Header.Set("HX-Request", "true")
+				}
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+
+			// The draft's ballot renders with nowhere to submit it
+			rr := preview("", false)
+			body := rr.Body.String()
+			if rr.Code != http.StatusOK || !strings.Contains(body, "PREVIEW") || !strings.Contains(body, "Tetris") || !strings.Contains(body, `name="choice"`) {
+				t.Fatalf("expected a preview of the single-choice ballot, got %d: %s", rr.Code, body)
+			}
+			if strings.Contains(body, `action="/vote/`) {
+				t.Error("expected the preview not to submit anywhere")
+			}
+
+			// Unsaved settings from the admin form are previewed as they would save
+			edits := "?" + url.Values{"name": {"Best Game"}, "vote_type": {"ranked"}, "max_rank": {"2"}, "show_results": {"live"}}.Encode()
+			body = preview(edits, false).Body.String()
+			if !strings.Contains(body, `name="rank2"`) || strings.Contains(body, `name="rank3"`) {
+				t.Errorf("expected a two-rank ballot from the unsaved settings")
+			}
+			if body := preview("?vote_type=bogus", false).Body.String(); !strings.Contains(body, `name="choice"`) {
+				t.Errorf("expected invalid settings to fall back to the saved ones")
+			}
+
+			if mode == web.UIModeModern {
+				rr := preview("", true)
+				if body := rr.Body.String(); rr.Code != http.StatusOK || !strings.Contains(body, "inert") || strings.Contains(body, "<html") || strings.Contains(body, "hx-post") {
+					t.Errorf("expected the read-only form alone for the preview panel, got %d: %s", rr.Code, body)
+				}
+			}
+			req := httptest.NewRequest(http.MethodGet, web.AdminCategoryURL(cat.ID), nil)
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if !strings.Contains(rr.Body.String(), web.AdminCategoryPreviewURL(cat.ID)) {
+				t.Error("expected the admin page to link the preview")
+			}
+		})
+	}
+}
+
+func TestLandingPage(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+
+			// A finished poll with a tie at the top, and two still to come
+			heats := createTestCategory(t, queries, "Heats", "single", "open", "after_close")
+			tetris := createTestOption(t, queries, heats.ID, "Tetris")
+			doom := createTestOption(t, queries, heats.ID, "Doom")
+			createTestOption(t, queries, heats.ID, "Myst")
+			voteFor(t, handler, heats.ID, tetris.ID, "alice")
+			voteFor(t, handler, heats.ID, doom.ID, "bob")
+			queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{Status: "closed", ID: heats.ID})
+
+			final := createTestCategory(t, queries, "Grand Final", "single", "draft", "live")
+			queries.UpdateCategory(t.Context(), db.UpdateCategoryParams{
+				Name: final.Name, VoteType: "single", ShowResults: "live",
+				DependsOn: sql.NullInt64{Int64: heats.ID, Valid: true}, ID: final.ID,
+			})
+			opensAt := time.Now().Add(time.Hour).Truncate(time.Minute)
+			cosplay := createTestCategory(t, queries, "Best Cosplay", "single", "draft", "live")
+			queries.UpdateCategory(t.Context(), db.UpdateCategoryParams{
+				Name: cosplay.Name, VoteType: "single", ShowResults: "live",
+				OpensAt: sql.NullTime{Time: opensAt.UTC(), Valid: true}, ID: cosplay.ID,
+			})
+
+			rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.HomeURL(), nil)
+			body := rr.Body.String()
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rr.Code, body)
+			}
+			if !strings.Contains(body, "Next up: Best Cosplay at "+opensAt.Local().Format("15:04")) {
+				t.Error("expected the next planned opening")
+			}
+			if !strings.Contains(body, "After Heats") {
+				t.Error("expected the schedule to show what the final waits on")
+			}
+			if strings.Index(body, "Best Cosplay") > strings.Index(body, "Grand Final") {
+				t.Error("expected polls with a planned time first")
+			}
+			if !strings.Contains(body, "Tetris &amp; Doom") || strings.Contains(body, "Myst") {
+				t.Error("expected the tied winners of the closed poll")
+			}
+
+			// Once a poll opens the page is just the ballot list again
+			queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{Status: "open", ID: cosplay.ID})
+			rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, web.HomeURL(), nil)
+			if strings.Contains(rr.Body.String(), "Recent winners") {
+				t.Error("expected no landing page while a poll is open")
+			}
+		})
+	}
+}
+
+func TestRemoteBallots(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+	handler := srv.Handler()
+
+	cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+	tetris := createTestOption(t, queries, cat.ID, "Tetris")
+
+	voteFrom := func(addr, nickname string) *httptest.ResponseRecorder {
+		form := url.Values{"nickname": {nickname}, "choice": {strconv.FormatInt(tetris.ID, 10)}}
+		req := httptest.NewRequest(http.MethodPost, web.VoteURL(cat.ID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = addr
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	admin := func(method, path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Off the LAN is accepted but flagged; a LAN re-vote clears the flag
+	voteFrom("192.168.1.20:5000", "alice")
+	voteFrom("[fd00::7]:5000", "bob")
+	voteFrom("203.0.113.9:5000", "carol")
+	voteFrom("203.0.113.9:5000", "dave")
+	voteFrom("10.0.0.4:5000", "dave")
+
+	votes, _ := queries.ListVotesByCategory(t.Context(), cat.ID)
+	remote := map[string]bool{}
+	for _, v := range votes {
+		remote[v.Nickname] = v.Remote
+	}
+	if len(votes) != 4 || !remote["carol"] || remote["alice"] || remote["bob"] || remote["dave"] {
+		t.Errorf("expected only carol's ballot to be remote, got %v", remote)
+	}
+
+	body := admin(http.MethodGet, web.AdminCategoryURL(cat.ID), nil).Body.String()
+	if !strings.Contains(body, "1 ballot came from outside the local network") || strings.Count(body, ">REMOTE<") != 1 {
+		t.Errorf("expected the admin page to flag one remote ballot, got %s", body)
+	}
+
+	// In LAN-only mode remote ballots are refused
+	if rr := admin(http.MethodPost, web.AdminSettingsURL(), url.Values{"lan_only": {"true"}, "widget_frame_ancestors": {"*"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected settings to save, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := voteFrom("203.0.113.9:5000", "erin"); !strings.Contains(rr.Body.String(), "Voting is only open on the local network") {
+		t.Errorf("expected the form vote to be refused, got %d", rr.Code)
+	}
+	req := httptest.NewRequest(http.MethodPost, web.APICategoryVotesURL(cat.ID), strings.NewReader(`{"nickname":"erin","choices":[`+strconv.FormatInt(tetris.ID, 10)+`]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "203.0.113.9:5000"
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 from the API, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if n, _ := queries.CountVotesByCategory(t.Context(), cat.ID); n != 4 {
+		t.Errorf("expected no new ballots, got %d", n)
+	}
+	voteFrom("127.0.0.1:5000", "erin")
+	if n, _ := queries.CountVotesByCategory(t.Context(), cat.ID); n != 5 {
+		t.Errorf("expected a LAN vote to go through, got %d ballots", n)
+	}
+}
+
+func TestBackgroundJobs(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	queries := db.New(conn)
+	backups := t.TempDir()
+	jobs := web.DefaultJobs
+	jobs.BackupDir = backups
+	srv, err := web.NewServer(conn, testAdminPassword, web.UIModeModern, web.WithJobs(jobs))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	ctx := t.Context()
+
+	t.Run("cleanup", func(t *testing.T) {
+		now := time.Now().UTC()
+		for id, expires := range map[string]time.Time{"stale": now.Add(-time.Minute), "fresh": now.Add(time.Hour)} {
+			if err := queries.UpsertSession(ctx, db.UpsertSessionParams{ID: id, Data: "{}", ExpiresAt: expires}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		cat := createTestCategory(t, queries, "Keys", "single", "open", "live")
+		for key, age := range map[string]time.Duration{"old": 25 * time.Hour, "new": time.Minute} {
+			if _, err := conn.Exec("INSERT INTO idempotency_keys (key, category_id, nickname, created_at) VALUES (?, ?, 'alice', ?)",
+				key, cat.ID, now.Add(-age)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := srv.RunJob(ctx, web.JobCleanup); err != nil {
+			t.Fatalf("cleanup failed: %v", err)
+		}
+		if _, err := queries.GetSession(ctx, db.GetSessionParams{ID: "fresh", ExpiresAt: now}); err != nil {
+			t.Errorf("expected the live session to survive: %v", err)
+		}
+		var sessions, keys int
+		conn.QueryRow("SELECT COUNT(*) FROM sessions").Scan(&sessions)
+		conn.QueryRow("SELECT COUNT(*) FROM idempotency_keys").Scan(&keys)
+		if sessions != 1 || keys != 1 {
+			t.Errorf("expected 1 session and 1 key left, got %d and %d", sessions, keys)
+		}
+		if _, err := queries.GetIdempotencyKey(ctx, "new"); err != nil {
+			t.Errorf("expected the recent key to survive: %v", err)
+		}
+	})
+
+	// The purge job picks up the polls archived here
+	var stale, recent db.Category
+	t.Run("archive", func(t *testing.T) {
+		closeAt := func(cat db.Category, at time.Time) {
+			if _, err := conn.Exec("INSERT INTO audit_events (actor, action, category_id, created_at) VALUES ('admin', ?, ?, ?)",
+				db.AuditCategoryClose, cat.ID, at.UTC()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		stale = createTestCategory(t, queries, "Stale", "single", "closed", "live")
+		closeAt(stale, time.Now().Add(-25*time.Hour))
+		recent = createTestCategory(t, queries, "Recent", "single", "closed", "live")
+		closeAt(recent, time.Now().Add(-2*time.Hour))
+		reclosed := createTestCategory(t, queries, "Reclosed", "single", "closed", "live")
+		closeAt(reclosed, time.Now().Add(-48*time.Hour))
+		closeAt(reclosed, time.Now().Add(-time.Hour))
+		open := createTestCategory(t, queries, "Open", "single", "open", "live")
+		closeAt(open, time.Now().Add(-48*time.Hour))
+
+		if err := srv.RunJob(ctx, web.JobArchive); err != nil {
+			t.Fatalf("archive failed: %v", err)
+		}
+		for cat, want := range map[db.Category]string{stale: "archived", recent: "closed", reclosed: "closed", open: "open"} {
+			got, _ := queries.GetCategory(ctx, cat.ID)
+			if got.Status != want {
+				t.Errorf("%s: expected %s, got %s", cat.Name, want, got.Status)
+			}
+		}
+		var actor string
+		conn.QueryRow("SELECT actor FROM audit_events WHERE category_id = ? AND action = ?", stale.ID, db.AuditCategoryArchive).Scan(&actor)
+		if actor != db.ActorServer {
+			t.Errorf("expected the archive to be audited as %q, got %q", db.ActorServer, actor)
+		}
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		purged := func(cat db.Category) bool {
+			t.Helper()
+			ok, err := queries.Purged(ctx, cat.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return ok
+		}
+		if err := srv.RunJob(ctx, web.JobPurge); err != nil {
+			t.Fatalf("purge failed: %v", err)
+		}
+		if purged(stale) {
+			t.Error("expected ballots kept while ballot_retention_days is 0")
+		}
+
+		if _, err := queries.SetSetting(ctx, db.SettingBallotRetentionDays, "1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.RunJob(ctx, web.JobPurge); err != nil {
+			t.Fatalf("purge failed: %v", err)
+		}
+		if !purged(stale) || purged(recent) {
+			t.Errorf("expected only the poll closed over a day ago purged, got %v and %v", purged(stale), purged(recent))
+		}
+		var actor string
+		conn.QueryRow("SELECT actor FROM audit_events WHERE category_id = ? AND action = ?", stale.ID, db.AuditBallotPurge).Scan(&actor)
+		if actor != db.ActorServer {
+			t.Errorf("expected the purge to be audited as %q, got %q", db.ActorServer, actor)
+		}
+	})
+
+	t.Run("vacuum", func(t *testing.T) {
+		if err := srv.RunJob(ctx, web.JobVacuum); err != nil {
+			t.Fatalf("vacuum failed: %v", err)
+		}
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		if err := srv.RunJob(ctx, web.JobBackup); err != nil {
+			t.Fatalf("backup failed: %v", err)
+		}
+		snapshots, _ := filepath.Glob(filepath.Join(backups, "votigo-*.db"))
+		if len(snapshots) != 1 {
+			t.Fatalf("expected one snapshot, got %v", snapshots)
+		}
+		snapshot, err := db.Open(snapshots[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer snapshot.Close()
+		var archived int
+		if err := snapshot.QueryRow("SELECT COUNT(*) FROM categories WHERE status = 'archived'").Scan(&archived); err != nil || archived != 1 {
+			t.Errorf("expected the snapshot to hold the archived poll, got %d, %v", archived, err)
+		}
+	})
+
+	if err := srv.RunJob(ctx, "reindex"); err == nil {
+		t.Error("expected an unknown job to fail")
+	}
+}
+
+func TestConcurrentBallots(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+	// Every connection to :memory: is a separate database
+	conn.SetMaxOpenConns(1)
+	handler := srv.Handler()
+
+	cat := createTestCategory(t, queries, "Best Game", "approval", "open", "live")
+	tetris := createTestOption(t, queries, cat.ID, "Tetris")
+	doom := createTestOption(t, queries, cat.ID, "Doom")
+
+	const voters = 40
+	codes := make(chan int, voters*2)
+	var wg sync.WaitGroup
+	for i := range voters * 2 {
+		wg.Go(func() {
+			// Each voter votes twice; the second ballot adds Doom
+			choices := strconv.FormatInt(tetris.ID, 10)
+			if i >= voters {
+				choices += "," + strconv.FormatInt(doom.ID, 10)
+			}
+			body := `{"nickname":"voter` + strconv.Itoa(i%voters) + `","choices":[` + choices + `]}`
+			req := httptest.NewRequest(http.MethodPost, web.APICategoryVotesURL(cat.ID), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Idempotency-Key", "key-"+strconv.Itoa(i))
+			req.RemoteAddr = "192.168.1.20:5000"
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			codes <- rr.Code
+		})
+	}
+	wg.Wait()
+	close(codes)
+
+	for code := range codes {
+		if code != http.StatusCreated {
+			t.Errorf("expected every ballot to be recorded, got %d", code)
+		}
+	}
+	if n, _ := queries.CountVotesByCategory(t.Context(), cat.ID); n != voters {
+		t.Errorf("expected %d voters, got %d", voters, n)
+	}
+	var audited, keys int
+	conn.QueryRow("SELECT COUNT(*) FROM audit_events WHERE action = ?", db.AuditVote).Scan(&audited)
+	conn.QueryRow("SELECT COUNT(*) FROM idempotency_keys").Scan(&keys)
+	if audited != voters*2 || keys != voters*2 {
+		t.Errorf("expected %d audit events and keys, got %d and %d", voters*2, audited, keys)
+	}
+	votes, _ := queries.ListVotesByCategory(t.Context(), cat.ID)
+	for _, v := range votes {
+		if v.Version < 1 || v.Version > 2 {
+			t.Errorf("%s: unexpected version %d", v.Nickname, v.Version)
+		}
+	}
+}
+
+func TestReadConnection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "votigo.db")
+	conn, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if err := db.EnableWAL(conn); err != nil {
+		t.Fatalf("failed to enable WAL: %v", err)
+	}
+	reads, err := db.OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("failed to open read-only: %v", err)
+	}
+	defer reads.Close()
+
+	srv, err := web.NewServer(conn, testAdminPassword, web.UIModeModern, web.WithReadDB(reads))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := srv.Handler()
+	queries := db.New(conn)
+	cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+	tetris := createTestOption(t, queries, cat.ID, "Tetris")
+	voteFor(t, handler, cat.ID, tetris.ID, "alice")
+
+	// A write held open doesn't stop results being read
+	tx, err := conn.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DELETE FROM vote_selections"); err != nil {
+		t.Fatal(err)
+	}
+
+	rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.APIResultsURL(cat.ID), nil)
+	var res struct {
+		TotalVotes int64 `json:"total_votes"`
+		Results    []struct {
+			Votes int64 `json:"votes"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil || res.TotalVotes != 1 || len(res.Results) != 1 || res.Results[0].Votes != 1 {
+		t.Errorf("expected the committed vote in the results, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = makeRequest(t, handler.ServeHTTP, http.MethodGet, web.ResultsURL(cat.ID), nil)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Tetris") {
+		t.Errorf("expected the results page to render, got %d", rr.Code)
+	}
+}
+
+func TestDashboardVoteStats(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeModern, web.UIModeLegacy} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+
+			busy := createTestCategory(t, queries, "Busy Poll", "single", "open", "live")
+			opt := createTestOption(t, queries, busy.ID, "Tetris")
+			quiet := createTestCategory(t, queries, "Quiet Poll", "single", "open", "live")
+			createTestOption(t, queries, quiet.ID, "Doom")
+
+			for i, addr := range []string{"10.0.0.1:5000", "10.0.0.2:5000", "203.0.113.9:5000"} {
+				form := url.Values{"nickname": {"voter" + strconv.Itoa(i)}, "choice": {strconv.FormatInt(opt.ID, 10)}}
+				req := httptest.NewRequest(http.MethodPost, web.VoteURL(busy.ID), strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.RemoteAddr = addr
+				handler.ServeHTTP(httptest.NewRecorder(), req)
+			}
+
+			stats, err := queries.ListCategoryVoteStats(t.Context())
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []db.ListCategoryVoteStatsRow{{CategoryID: busy.ID, Votes: 3, RemoteVotes: 1}, {CategoryID: quiet.ID}}
+			if !slices.Equal(stats, want) {
+				t.Errorf("expected stats %+v, got %+v", want, stats)
+			}
+			latest, err := queries.ListLatestVoteEvents(t.Context())
+			if err != nil || len(latest) != 1 || latest[0].CategoryID.Int64 != busy.ID {
+				t.Errorf("expected one latest vote, for the busy poll, got %+v, %v", latest, err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, web.AdminURL(), nil)
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			body := rr.Body.String()
+			if rr.Code != http.StatusOK || !strings.Contains(body, "Votes") || !strings.Contains(body, "last ") {
+				t.Errorf("expected the dashboard to show vote stats, got %d", rr.Code)
+			}
+			if !strings.Contains(body, "1 remote") && !strings.Contains(body, "1 REMOTE") {
+				t.Error("expected the dashboard to count the remote ballot")
+			}
+		})
+	}
+}
+
+func TestViewAsVoter(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeModern, web.UIModeLegacy} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			createTestOption(t, queries, cat.ID, "Tetris")
+			finished := createTestCategory(t, queries, "Old Poll", "single", "closed", "live")
+
+			get := func(path string, admin bool, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if admin {
+					addBasicAuth(req, "admin", testAdminPassword)
+				}
+				for _, c := range cookies {
+					req.AddCookie(c)
+				}
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+			toggle := func(enabled, next string) *httptest.ResponseRecorder {
+				form := url.Values{"enabled": {enabled}, "next": {next}}
+				req := httptest.NewRequest(http.MethodPost, web.AdminVoterViewURL(), strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				addBasicAuth(req, "admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+
+			// Attendees see neither the admin bar nor the banner
+			body := get(web.VoteURL(cat.ID), false).Body.String()
+			if strings.Contains(body, "Edit poll") || strings.Contains(body, "as a voter") {
+				t.Error("expected no organizer bar for attendees")
+			}
+
+			// The organizer gets the admin bar
+			body = get(web.VoteURL(cat.ID), true).Body.String()
+			if !strings.Contains(body, "Edit poll") || !strings.Contains(body, web.AdminCategoryURL(cat.ID)) || !strings.Contains(body, "View as voter") {
+				t.Error("expected the admin bar for the organizer")
+			}
+
+			rr := toggle("on", web.VoteURL(cat.ID))
+			if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != web.VoteURL(cat.ID) {
+				t.Fatalf("expected a redirect to the ballot, got %d %s", rr.Code, rr.Header().Get("Location"))
+			}
+			cookies := rr.Result().Cookies()
+			if len(cookies) != 1 || cookies[0].Value == "" {
+				t.Fatalf("expected the voter view cookie, got %v", cookies)
+			}
+
+			body = get(web.VoteURL(cat.ID), true, cookies[0]).Body.String()
+			if strings.Contains(body, "Edit poll") || !strings.Contains(body, "Exit voter view") {
+				t.Error("expected the voter view banner instead of the admin bar")
+			}
+			if !strings.Contains(body, "Tetris") {
+				t.Error("expected the ballot itself")
+			}
+
+			// Finished results aren't cached for organizers
+			rr = get(web.ResultsURL(finished.ID), true, cookies[0])
+			if rr.Header().Get("ETag") != "" || !strings.Contains(rr.Header().Get("Cache-Control"), "private") {
+				t.Errorf("expected organizer results to be private, got %q", rr.Header().Get("Cache-Control"))
+			}
+			rr = get(web.ResultsURL(finished.ID), false)
+			if rr.Header().Get("ETag") == "" || rr.Header().Get("Vary") == "" {
+				t.Errorf("expected attendee results to be cached per viewer, got %v", rr.Header())
+			}
+
+			// Only paths on this site are followed
+			for _, next := range []string{"//evil.example", "https://evil.example", `/\evil.example`} {
+				if loc := toggle("on", next).Header().Get("Location"); loc != web.HomeURL() {
+					t.Errorf("next %q: expected a redirect home, got %q", next, loc)
+				}
+			}
+
+			rr = toggle("off", "")
+			if rr.Header().Get("Location") != web.AdminURL() {
+				t.Errorf("expected a redirect to the dashboard, got %q", rr.Header().Get("Location"))
+			}
+			if cookies := rr.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+				t.Errorf("expected the cookie to be cleared, got %v", cookies)
+			}
+		})
+	}
+}
+
+func TestCategorySkins(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+
+			admin := func(path string, form url.Values) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				addBasicAuth(req, "admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+			skinned := createTestCategory(t, queries, "Skinned", "single", "open", "live")
+			plain := createTestCategory(t, queries, "Plain", "single", "open", "live")
+			settings := func(skin, css string) url.Values {
+				return url.Values{
+					"name": {"Skinned"}, "vote_type": {"single"}, "show_results": {"live"},
+					"skin": {skin}, "custom_css": {css},
+				}
+			}
+
+			if rr := admin(web.AdminCategoryURL(skinned.ID), settings("neon", "h1 { color: teal; }")); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected redirect after saving a skin, got %d: %s", rr.Code, rr.Body.String())
+			}
+
+			// The skin and custom CSS style only that poll's voter pages
+			for _, path := range []string{web.VoteURL(skinned.ID), web.ResultsURL(skinned.ID)} {
+				body := makeRequest(t, handler.ServeHTTP, http.MethodGet, path, nil).Body.String()
+				if !strings.Contains(body, "#ff2a6d") || !strings.Contains(body, "h1 { color: teal; }") {
+					t.Errorf("expected %s to carry the neon skin and custom CSS", path)
+				}
+			}
+			for _, path := range []string{web.VoteURL(plain.ID), web.ResultsURL(plain.ID)} {
+				if body := makeRequest(t, handler.ServeHTTP, http.MethodGet, path, nil).Body.String(); strings.Contains(body, "#ff2a6d") {
+					t.Errorf("expected %s to keep the default look", path)
+				}
+			}
+
+			// CSS that could close the style element, and unknown skins, are refused
+			if rr := admin(web.AdminCategoryURL(skinned.ID), settings("", "</style><script>alert(1)</script>")); !strings.Contains(rr.Body.String(), "Custom CSS can") {
+				t.Errorf("expected markup in custom CSS to be refused, got %d", rr.Code)
+			}
+			if rr := admin(web.AdminCategoryURL(skinned.ID), settings("vaporwave", "")); !strings.Contains(rr.Body.String(), "Unknown skin") {
+				t.Errorf("expected an unknown skin to be refused, got %d", rr.Code)
+			}
+			if cat, _ := queries.GetCategory(t.Context(), skinned.ID); cat.Skin != "neon" || cat.CustomCss != "h1 { color: teal; }" {
+				t.Errorf("expected refused edits to leave the skin alone, got %q and %q", cat.Skin, cat.CustomCss)
+			}
+		})
+	}
+}
+
+func TestCeremonyCelebrate(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+	handler := srv.Handler()
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	cat := createTestCategory(t, queries, "Best Game", "single", "open", "after_close")
+	opt := createTestOption(t, queries, cat.ID, "Doom")
+	voteFor(t, handler, cat.ID, opt.ID, "player1")
+	queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{ID: cat.ID, Status: "closed"})
+
+	// A display connects to the event stream
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+web.EventsURL(), nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to connect to events: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q", ct)
+	}
+	lines := bufio.NewScanner(resp.Body)
+	lines.Scan() // retry: ...
+
+	// The console lists the closed poll with its winner and the display
+	console := httptest.NewRequest(http.MethodGet, web.AdminCeremonyURL(), nil)
+	addBasicAuth(console, "admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, console)
+	if body := rr.Body.String(); !strings.Contains(body, "Doom") || !strings.Contains(body, "1 display(s)") {
+		t.Errorf("expected the console to list the winner and one display, got %d", rr.Code)
+	}
+
+	form := url.Values{"category_id": {strconv.FormatInt(cat.ID, 10)}}
+	celebrate := httptest.NewRequest(http.MethodPost, web.AdminCelebrateURL(), strings.NewReader(form.Encode()))
+	celebrate.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	celebrate.Header.Set("HX-Request", "true")
+	addBasicAuth(celebrate, "admin", testAdminPassword)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, celebrate)
+	if !strings.Contains(rr.Header().Get("HX-Trigger"), "1 display(s)") {
+		t.Errorf("expected a toast counting one display, got %q", rr.Header().Get("HX-Trigger"))
+	}
+
+	var event, data string
+	for lines.Scan() {
+		line := lines.Text()
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			event = name
+		}
+		if payload, ok := strings.CutPrefix(line, "data: "); ok && event == web.EventCelebrate {
+			data = payload
+			break
+		}
+	}
+	if event != web.EventCelebrate {
+		t.Fatalf("expected a celebrate event, got %q", event)
+	}
+	var got web.CelebrateEvent
+	if err := json.Unmarshal([]byte(data), &got); err != nil || got.CategoryID != cat.ID || got.Winner != "Doom" {
+		t.Errorf("expected the winner of poll %d, got %s (%v)", cat.ID, data, err)
+	}
+
+	// Voters can't celebrate
+	req = httptest.NewRequest(http.MethodPost, web.AdminCelebrateURL(), nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected celebrating without auth to be refused, got %d", rr.Code)
+	}
+}
+
+func TestAdminBroadcast(t *testing.T) {
+	srv, _, conn := testServerModern(t)
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+	handler := srv.Handler()
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	listen := func() *bufio.Scanner {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+web.EventsURL(), nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to connect to events: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return bufio.NewScanner(resp.Body)
+	}
+	type announcement struct {
+		Message string `json:"message"`
+		Seconds int64  `json:"seconds"`
+	}
+	// next reads a stream up to its next broadcast event
+	next := func(lines *bufio.Scanner) announcement {
+		var event string
+		for lines.Scan() {
+			line := lines.Text()
+			if name, ok := strings.CutPrefix(line, "event: "); ok {
+				event = name
+			}
+			if payload, ok := strings.CutPrefix(line, "data: "); ok && event == web.EventBroadcast {
+				var a announcement
+				if err := json.Unmarshal([]byte(payload), &a); err != nil {
+					t.Fatalf("failed to decode broadcast %q: %v", payload, err)
+				}
+				return a
+			}
+		}
+		t.Fatal("expected a broadcast event")
+		return announcement{}
+	}
+	broadcast := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, web.AdminBroadcastURL(), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// A voter page is open when the announcement goes out
+	first := listen()
+	rr := broadcast(url.Values{"message": {"  Pizza is   here "}, "minutes": {"15"}})
+	if !strings.Contains(rr.Header().Get("HX-Trigger"), "1 page(s)") {
+		t.Errorf("expected a toast counting one page, got %q", rr.Header().Get("HX-Trigger"))
+	}
+	if got := next(first); got.Message != "Pizza is here" || got.Seconds < 14*60 || got.Seconds > 15*60 {
+		t.Errorf("expected the announcement for 15 minutes, got %+v", got)
+	}
+
+	// A page opened afterwards starts with it
+	second := listen()
+	if got := next(second); got.Message != "Pizza is here" {
+		t.Errorf("expected a new page to start with the announcement, got %+v", got)
+	}
+
+	// Taking it down sends an empty message
+	rr = broadcast(url.Values{"message": {"Pizza is here"}, "clear": {"1"}})
+	if !strings.Contains(rr.Header().Get("HX-Trigger"), "taken down") {
+		t.Errorf("expected a toast for taking it down, got %q", rr.Header().Get("HX-Trigger"))
+	}
+	if got := next(second); got.Message != "" {
+		t.Errorf("expected the announcement taken down, got %+v", got)
+	}
+
+	for _, form := range []url.Values{
+		{"message": {strings.Repeat("x", 201)}},
+		{"message": {"Pizza"}, "minutes": {"0"}},
+		{"message": {"Pizza"}, "minutes": {"61"}},
+	} {
+		if rr := broadcast(form); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected %v to be refused, got %d", form, rr.Code)
+		}
+	}
+
+	// Voters can't broadcast
+	req := httptest.NewRequest(http.MethodPost, web.AdminBroadcastURL(), nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected broadcasting without auth to be refused, got %d", rr.Code)
+	}
+}
+
+func TestCeremonyCountdown(t *testing.T) {
+	srv, queries, conn := testServerModern(t)
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+	handler := srv.Handler()
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	get := func(path string) string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Body.String()
+	}
+	countdown := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, web.AdminCeremonyCountdownURL(), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if body := get(web.HomeURL()); !strings.Contains(body, `data-countdown="0" hidden`) {
+		t.Error("expected the countdown hidden until it is set")
+	}
+
+	// A display is listening when the console starts the countdown
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+web.EventsURL(), nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to connect to events: %v", err)
+	}
+	defer resp.Body.Close()
+	lines := bufio.NewScanner(resp.Body)
+	// next reads the stream up to its next countdown event
+	next := func() web.CountdownEvent {
+		var event string
+		for lines.Scan() {
+			line := lines.Text()
+			if name, ok := strings.CutPrefix(line, "event: "); ok {
+				event = name
+			}
+			if payload, ok := strings.CutPrefix(line, "data: "); ok && event == web.EventCountdown {
+				var c web.CountdownEvent
+				if err := json.Unmarshal([]byte(payload), &c); err != nil {
+					t.Fatalf("failed to decode countdown %q: %v", payload, err)
+				}
+				return c
+			}
+		}
+		t.Fatal("expected a countdown event")
+		return web.CountdownEvent{}
+	}
+	if c := next(); c.Seconds != 0 {
+		t.Errorf("expected the stream to start with no countdown, got %+v", c)
+	}
+
+	rr := countdown(url.Values{"minutes": {"30"}})
+	if !strings.Contains(rr.Header().Get("HX-Trigger"), "on 1 page(s)") {
+		t.Errorf("expected a toast counting one page, got %q", rr.Header().Get("HX-Trigger"))
+	}
+	if c := next(); c.Seconds < 29*60 || c.Seconds > 30*60 {
+		t.Errorf("expected a 30 minute countdown, got %+v", c)
+	}
+	at, err := queries.SettingTime(t.Context(), db.SettingCeremonyAt)
+	if err != nil || time.Until(at) < 29*time.Minute {
+		t.Errorf("expected the ceremony time stored in 30 minutes, got %v (%v)", at, err)
+	}
+
+	// The header on every page and the display count down
+	for _, path := range []string{web.HomeURL(), web.DisplayURL()} {
+		if body := get(path); !strings.Contains(body, `Ceremony in <span data-countdown-clock class="tabular-nums">29:5`) {
+			t.Errorf("expected %s to count down from 29:5x", path)
+		}
+	}
+
+	// A time in the past is refused, and stopping clears the setting
+	past := time.Now().Add(-time.Hour).Format("2006-01-02T15:04")
+	for _, form := range []url.Values{{"minutes": {"0"}}, {"at": {past}}, {"at": {"tonight"}}} {
+		if rr := countdown(form); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected %v to be refused, got %d", form, rr.Code)
+		}
+	}
+	countdown(url.Values{"clear": {"1"}})
+	if c := next(); c.Seconds != 0 {
+		t.Errorf("expected the countdown stopped, got %+v", c)
+	}
+	if at, _ := queries.SettingTime(t.Context(), db.SettingCeremonyAt); !at.IsZero() {
+		t.Errorf("expected no ceremony time, got %v", at)
+	}
+
+	// Voters can't start one
+	req = httptest.NewRequest(http.MethodPost, web.AdminCeremonyCountdownURL(), nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected starting a countdown without auth to be refused, got %d", rr.Code)
+	}
+}
+
+func TestEventsPerHostLimit(t *testing.T) {
+	srv, _, conn := testServerModern(t)
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	connect := func() *http.Response {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+web.EventsURL(), nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to connect to events: %v", err)
+		}
+		return resp
+	}
+
+	var streams []*http.Response
+	for range 8 {
+		resp := connect()
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected stream %d to connect, got %d", len(streams)+1, resp.StatusCode)
+		}
+		streams = append(streams, resp)
+	}
+
+	resp := connect()
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("expected a 429 with Retry-After past the limit, got %d", resp.StatusCode)
+	}
+
+	// Closing a stream frees its place
+	streams[0].Body.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp := connect()
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected a place after closing a stream, got %d", resp.StatusCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCeremonyDisplay(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+
+			admin := func(path string, form url.Values) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				addBasicAuth(req, "admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+			display := func() string {
+				rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.DisplayURL(), nil)
+				if rr.Code != http.StatusOK {
+					t.Fatalf("expected the display to render, got %d", rr.Code)
+				}
+				return rr.Body.String()
+			}
+			closedPoll := func(name, winner string) db.Category {
+				cat := createTestCategory(t, queries, name, "single", "open", "after_close")
+				opt := createTestOption(t, queries, cat.ID, winner)
+				voteFor(t, handler, cat.ID, opt.ID, "player1")
+				queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{ID: cat.ID, Status: "closed"})
+				return cat
+			}
+			first := closedPoll("Best Game", "Doom")
+			closedPoll("Best Map", "Dust")
+			open := createTestCategory(t, queries, "Still Open", "single", "open", "live")
+
+			if body := display(); !strings.Contains(body, "The ceremony starts soon") {
+				t.Errorf("expected the waiting screen before the ceremony")
+			}
+
+			// Showing a poll covers its results until they are revealed
+			if rr := admin(web.AdminCeremonyShowURL(), url.Values{"category_id": {strconv.FormatInt(first.ID, 10)}}); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected redirect after showing a poll, got %d: %s", rr.Code, rr.Body.String())
+			}
+			if body := display(); !strings.Contains(body, "Best Game") || !strings.Contains(body, "And the winner is") {
+				t.Errorf("expected the display to show Best Game, covered")
+			}
+			admin(web.AdminCeremonyRevealURL(), nil)
+			if body := display(); !strings.Contains(body, "Doom") || strings.Contains(body, "And the winner is") {
+				t.Errorf("expected the display to reveal Doom")
+			}
+
+			// Next moves through the closed polls, then back to waiting
+			admin(web.AdminCeremonyNextURL(), nil)
+			if body := display(); !strings.Contains(body, "Best Map") || !strings.Contains(body, "And the winner is") {
+				t.Errorf("expected next to show Best Map, covered")
+			}
+			req := httptest.NewRequest(http.MethodGet, web.AdminCeremonyURL(), nil)
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if body := rr.Body.String(); !strings.Contains(body, "Results covered") || !strings.Contains(body, "Dust") {
+				t.Errorf("expected the console to show Best Map covered")
+			}
+			admin(web.AdminCeremonyNextURL(), nil)
+			if body := display(); !strings.Contains(body, "The ceremony starts soon") {
+				t.Errorf("expected the waiting screen after the last poll")
+			}
+
+			// Only closed polls go on the display, and only once one is up
+			// can it be revealed
+			if rr := admin(web.AdminCeremonyShowURL(), url.Values{"category_id": {strconv.FormatInt(open.ID, 10)}}); rr.Code != http.StatusConflict {
+				t.Errorf("expected showing an open poll to be refused, got %d", rr.Code)
+			}
+			if rr := admin(web.AdminCeremonyRevealURL(), nil); rr.Code != http.StatusConflict {
+				t.Errorf("expected revealing the waiting screen to be refused, got %d", rr.Code)
+			}
+
+			// The display is public; its controls aren't
+			req = httptest.NewRequest(http.MethodPost, web.AdminCeremonyNextURL(), nil)
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != http.StatusUnauthorized {
+				t.Errorf("expected ceremony controls to need auth, got %d", rr.Code)
+			}
+		})
+	}
+}
+
+func TestRevealSounds(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	queries := db.New(conn)
+	sounds := t.TempDir()
+	srv, err := web.NewServer(conn, testAdminPassword, web.UIModeModern, web.WithSoundDir(sounds))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := srv.Handler()
+
+	upload := func(filename string, content []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("sound", filename)
+		part.Write(content)
+		mw.Close()
+		req := httptest.NewRequest(http.MethodPost, web.AdminSoundsURL(), &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	admin := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	mp3 := append([]byte("ID3\x03\x00\x00\x00\x00\x00\x00"), make([]byte, 1024)...)
+
+	// Uploads are named from the file name and never replace each other
+	if rr := upload("Drum Roll.MP3", mp3); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect after upload, got %d: %s", rr.Code, rr.Body.String())
+	}
+	upload("drum roll.mp3", mp3)
+	for _, name := range []string{"drum-roll.mp3", "drum-roll-2.mp3"} {
+		rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.SoundURL(name), nil)
+		if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "audio/mpeg" {
+			t.Errorf("expected %s to be served as audio, got %d %q", name, rr.Code, rr.Header().Get("Content-Type"))
+		}
+	}
+
+	// Only audio, by extension and by content
+	if rr := upload("page.mp3", []byte("<!DOCTYPE html><script>alert(1)</script>")); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected HTML posing as audio to be refused, got %d", rr.Code)
+	}
+	if rr := upload("tool.exe", mp3); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected an .exe to be refused, got %d", rr.Code)
+	}
+	if entries, _ := os.ReadDir(sounds); len(entries) != 2 {
+		t.Errorf("expected only the two sounds on disk, got %d files", len(entries))
+	}
+	for _, path := range []string{"/sounds/..%2fsecret.mp3", "/sounds/missing.mp3", "/sounds/"} {
+		if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, path, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("expected %s to be not found, got %d", path, rr.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, web.AdminSettingsURL(), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if !strings.Contains(rr.Body.String(), "/sounds/drum-roll-2.mp3") {
+		t.Errorf("expected the settings page to list the uploaded sounds")
+	}
+
+	// A poll's reveal sound is preloaded on the display
+	cat := createTestCategory(t, queries, "Best Game", "single", "closed", "after_close")
+	settings := func(sound string) url.Values {
+		return url.Values{"name": {"Best Game"}, "vote_type": {"single"}, "show_results": {"after_close"}, "reveal_sound": {sound}}
+	}
+	if rr := admin(web.AdminCategoryURL(cat.ID), settings("javascript:alert(1)")); !strings.Contains(rr.Body.String(), "Reveal sound must be") {
+		t.Errorf("expected a script URL to be refused as a sound, got %d", rr.Code)
+	}
+	if rr := admin(web.AdminCategoryURL(cat.ID), settings("/sounds/drum-roll.mp3")); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect after setting the reveal sound, got %d: %s", rr.Code, rr.Body.String())
+	}
+	admin(web.AdminCeremonyShowURL(), url.Values{"category_id": {strconv.FormatInt(cat.ID, 10)}})
+	if body := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.DisplayURL(), nil).Body.String(); !strings.Contains(body, `<audio id="reveal-sound" preload="auto" src="/sounds/drum-roll.mp3">`) {
+		t.Errorf("expected the display to preload the reveal sound")
+	}
+
+	if rr := admin(web.AdminDeleteSoundURL(), url.Values{"name": {"drum-roll.mp3"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect after deleting a sound, got %d", rr.Code)
+	}
+	if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.SoundURL("drum-roll.mp3"), nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected a deleted sound to be gone, got %d", rr.Code)
+	}
+}
+
+func TestOptionImages(t *testing.T) {
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	queries := db.New(conn)
+	images := t.TempDir()
+	srv, err := web.NewServer(conn, testAdminPassword, web.UIModeModern, web.WithImageDir(images))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := srv.Handler()
+
+	cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+	opt := createTestOption(t, queries, cat.ID, "Doom")
+
+	upload := func(filename string, content []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("image", filename)
+		part.Write(content)
+		mw.Close()
+		req := httptest.NewRequest(http.MethodPost, web.AdminOptionImageURL(opt.ID), &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	setURL := func(u string) *httptest.ResponseRecorder {
+		form := url.Values{"image_url": {u}}
+		req := httptest.NewRequest(http.MethodPost, web.AdminOptionImageURL(opt.ID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		addBasicAuth(req, "admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	decode := func(path string) image.Config {
+		t.Helper()
+		rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, path, nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected %s to be served, got %d", path, rr.Code)
+		}
+		if cc := rr.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+			t.Errorf("expected %s to be cached for good, got %q", path, cc)
+		}
+		cfg, err := png.DecodeConfig(rr.Body)
+		if err != nil {
+			t.Fatalf("expected %s to be a PNG: %v", path, err)
+		}
+		return cfg
+	}
+
+	var pic bytes.Buffer
+	png.Encode(&pic, image.NewNRGBA(image.Rect(0, 0, 2000, 1000)))
+
+	// Uploads are scaled down, with a thumbnail beside them
+	if rr := upload("Doom Box Art.png", pic.Bytes()); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect after upload, got %d: %s", rr.Code, rr.Body.String())
+	}
+	opt, _ = queries.GetOption(context.Background(), opt.ID)
+	name, ok := strings.CutPrefix(opt.Image, "/images/")
+	if !ok || !strings.HasPrefix(name, "doom-box-art-") || !strings.HasSuffix(name, ".png") {
+		t.Fatalf("expected the option to show the uploaded image, got %q", opt.Image)
+	}
+	if cfg := decode(web.ImageURL(name)); cfg.Width != 1600 || cfg.Height != 800 {
+		t.Errorf("expected the image scaled to 1600x800, got %dx%d", cfg.Width, cfg.Height)
+	}
+	if cfg := decode(web.ImageThumbURL(name)); cfg.Width != 256 || cfg.Height != 128 {
+		t.Errorf("expected a 256x128 thumbnail, got %dx%d", cfg.Width, cfg.Height)
+	}
+	if body := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.VoteURL(cat.ID), nil).Body.String(); !strings.Contains(body, web.ImageThumbURL(name)) {
+		t.Errorf("expected the ballot to show the thumbnail")
+	}
+	if body := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.ResultsURL(cat.ID), nil).Body.String(); !strings.Contains(body, web.ImageThumbURL(name)) {
+		t.Errorf("expected the results to show the thumbnail")
+	}
+	req := httptest.NewRequest(http.MethodGet, web.AdminCategoryURL(cat.ID), nil)
+	addBasicAuth(req, "admin", testAdminPassword)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if !strings.Contains(rr.Body.String(), web.ImageThumbURL(name)) {
+		t.Errorf("expected the admin option row to show the thumbnail")
+	}
+
+	// Only images, and only URLs that can't run script
+	if rr := upload("doom.png", []byte("<!DOCTYPE html><script>alert(1)</script>")); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected HTML posing as an image to be refused, got %d", rr.Code)
+	}
+	if rr := setURL("javascript:alert(1)"); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected a script URL to be refused as an image, got %d", rr.Code)
+	}
+	for _, path := range []string{"/images/..%2fsecret.png", "/images/thumbs/thumbs%2fx.png", "/images/missing.png", "/images/"} {
+		if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, path, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("expected %s to be not found, got %d", path, rr.Code)
+		}
+	}
+
+	// Linking an image elsewhere releases the upload no option shows any more
+	if rr := setURL("https://example.com/doom.png"); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect after linking an image, got %d: %s", rr.Code, rr.Body.String())
+	}
+	opt, _ = queries.GetOption(context.Background(), opt.ID)
+	if opt.Image != "https://example.com/doom.png" {
+		t.Errorf("expected the linked image, got %q", opt.Image)
+	}
+	for _, file := range []string{filepath.Join(images, name), filepath.Join(images, "thumbs", name)} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be deleted, got %v", file, err)
+		}
+	}
+}
+
+func TestResultsCard(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeModern, web.UIModeLegacy} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, _ := testServerWithMode(t, mode)
+			handler := srv.Handler()
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "after_close")
+			doom := createTestOption(t, queries, cat.ID, "Doom")
+			createTestOption(t, queries, cat.ID, "Tetris")
+			voteFor(t, handler, cat.ID, doom.ID, "alice")
+
+			// Hidden like the results themselves until the poll closes
+			if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.ResultsCardURL(cat.ID), nil); rr.Code != http.StatusNotFound {
+				t.Errorf("expected no card before results are visible, got %d", rr.Code)
+			}
+
+			queries.UpdateCategoryStatus(t.Context(), db.UpdateCategoryStatusParams{Status: "closed", ID: cat.ID})
+			rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.ResultsCardURL(cat.Ref()), nil)
+			if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "image/png" {
+				t.Fatalf("expected a PNG card, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+			}
+			if cfg, err := png.DecodeConfig(bytes.NewReader(rr.Body.Bytes())); err != nil || cfg.Width != 1200 || cfg.Height != 630 {
+				t.Errorf("expected a 1200x630 card, got %dx%d: %v", cfg.Width, cfg.Height, err)
+			}
+
+			etag := rr.Header().Get("ETag")
+			req := httptest.NewRequest(http.MethodGet, web.ResultsCardURL(cat.ID), nil)
+			req.Header.Set("If-None-Match", etag)
+			cached := httptest.NewRecorder()
+			handler.ServeHTTP(cached, req)
+			if etag == "" || cached.Code != http.StatusNotModified {
+				t.Errorf("expected 304 for a current card, got %d (ETag %q)", cached.Code, etag)
+			}
+
+			body := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.ResultsURL(cat.ID), nil).Body.String()
+			if !strings.Contains(body, web.ResultsCardURL(cat.Ref())) {
+				t.Errorf("expected the results page to link to the card")
+			}
+		})
+	}
+}
+
+func TestAvatars(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeModern, web.UIModeLegacy} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, _ := testServerWithMode(t, mode)
+			handler := srv.Handler()
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			doom := createTestOption(t, queries, cat.ID, "Doom")
+			voteFor(t, handler, cat.ID, doom.ID, "alice")
+			voteFor(t, handler, cat.ID, doom.ID, "bob")
+
+			req := httptest.NewRequest(http.MethodGet, web.AdminCategoryURL(cat.ID), nil)
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			avatars := regexp.MustCompile(`/avatars/[0-9a-f]{16}\.png`).FindAllString(rr.Body.String(), -1)
+			if len(avatars) != 2 || avatars[0] == avatars[1] {
+				t.Fatalf("expected a distinct avatar beside each ballot, got %v", avatars)
+			}
+			if strings.Contains(avatars[0], "alice") || strings.Contains(avatars[1], "bob") {
+				t.Errorf("expected avatar URLs not to carry nicknames, got %v", avatars)
+			}
+
+			img := makeRequest(t, handler.ServeHTTP, http.MethodGet, avatars[0], nil)
+			if img.Code != http.StatusOK || img.Header().Get("Content-Type") != "image/png" {
+				t.Fatalf("expected the avatar to be a PNG, got %d %q", img.Code, img.Header().Get("Content-Type"))
+			}
+			if !strings.Contains(img.Header().Get("Cache-Control"), "immutable") {
+				t.Errorf("expected avatars to be cached for good, got %q", img.Header().Get("Cache-Control"))
+			}
+			for _, path := range []string{"/avatars/alice.png", "/avatars/0123456789abcdef", "/avatars/"} {
+				if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, path, nil); rr.Code != http.StatusNotFound {
+					t.Errorf("expected %s to be not found, got %d", path, rr.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestVotedWall(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeModern, web.UIModeLegacy} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, _ := testServerWithMode(t, mode)
+			handler := srv.Handler()
+			admin := func(form url.Values) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, web.AdminCategoryURL(1), strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				addBasicAuth(req, "admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+			setWall := func(wall string) *httptest.ResponseRecorder {
+				return admin(url.Values{
+					"name": {"Best Game"}, "vote_type": {"single"}, "show_results": {"after_close"},
+					"voted_wall": {wall},
+				})
+			}
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "after_close")
+			doom := createTestOption(t, queries, cat.ID, "Doom Eternal")
+			voteFor(t, handler, cat.ID, doom.ID, "alice")
+			voteFor(t, handler, cat.ID, doom.ID, "bob")
+
+			ballot := func() string {
+				return makeRequest(t, handler.ServeHTTP, http.MethodGet, web.VoteURL(cat.ID), nil).Body.String()
+			}
+			if body := ballot(); strings.Contains(body, "Who has voted") || strings.Contains(body, "alice") {
+				t.Error("expected no wall until the poll turns one on")
+			}
+
+			if rr := setWall("names"); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected redirect after turning on the wall, got %d: %s", rr.Code, rr.Body.String())
+			}
+			body := ballot()
+			if !strings.Contains(body, "Who has voted") || !strings.Contains(body, "alice") || !strings.Contains(body, "bob") {
+				t.Errorf("expected the wall to name both voters")
+			}
+			if strings.Index(body, "bob") > strings.Index(body, "alice") {
+				t.Errorf("expected the newest voter first")
+			}
+			if strings.Count(body, "Doom Eternal") != 1 {
+				t.Errorf("expected the wall not to show anyone's choice")
+			}
+
+			if rr := setWall("avatars"); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected redirect after switching to avatars, got %d", rr.Code)
+			}
+			body = ballot()
+			if strings.Contains(body, "alice") || strings.Contains(body, "bob") {
+				t.Errorf("expected an avatar wall to leave nicknames out")
+			}
+			if n := len(regexp.MustCompile(`/avatars/[0-9a-f]{16}\.png`).FindAllString(body, -1)); n != 2 {
+				t.Errorf("expected an avatar per voter, got %d", n)
+			}
+
+			// The wall shows beside a ballot re-rendered with an error, too
+			form := url.Values{"nickname": {"carol"}}
+			if rr := makeRequest(t, handler.ServeHTTP, http.MethodPost, web.VoteURL(cat.ID), form); !strings.Contains(rr.Body.String(), "Who has voted") {
+				t.Errorf("expected the wall beside a rejected ballot")
+			}
+			if body := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.VoteWidgetURL(cat.ID), nil).Body.String(); strings.Contains(body, "Who has voted") {
+				t.Errorf("expected embedded ballots to leave the wall out")
+			}
+
+			if rr := setWall("everyone"); !strings.Contains(rr.Body.String(), "Unknown voted wall") {
+				t.Errorf("expected an unknown wall to be refused, got %d", rr.Code)
+			}
+		})
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeModern, web.UIModeLegacy} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, _ := testServerWithMode(t, mode)
+			handler := srv.Handler()
+			var options []int64
+			for _, name := range []string{"Best Game", "Best Snack", "Best Map"} {
+				cat := createTestCategory(t, queries, name, "single", "open", "live")
+				options = append(options, createTestOption(t, queries, cat.ID, "Doom").ID)
+			}
+			draft := createTestCategory(t, queries, "Best Draft", "single", "draft", "live")
+
+			ballots := map[string][]int{"alice": {0, 1, 2}, "bob": {0, 2}, "dave": {0, 2}, "carol": {1}}
+			for _, nick := range []string{"alice", "bob", "dave", "carol"} {
+				for _, i := range ballots[nick] {
+					voteFor(t, handler, int64(i+1), options[i], nick)
+				}
+			}
+			if _, err := queries.UpsertVote(t.Context(), db.UpsertVoteParams{CategoryID: draft.ID, Nickname: "carol"}); err != nil {
+				t.Fatalf("failed to create draft vote: %v", err)
+			}
+
+			if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.LeaderboardURL(), nil); rr.Code != http.StatusNotFound {
+				t.Errorf("expected the leaderboard to stay hidden until published, got %d", rr.Code)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, web.AdminLeaderboardExportURL(), nil)
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/csv") {
+				t.Fatalf("expected a CSV export, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+			}
+			records, err := csv.NewReader(rr.Body).ReadAll()
+			if err != nil {
+				t.Fatalf("failed to read export: %v", err)
+			}
+			var got []string
+			for _, rec := range records[1:] {
+				got = append(got, strings.Join(rec[:5], " "))
+			}
+			want := []string{"1 alice 3 3 3", "2 bob 2 3 1", "2 dave 2 3 1", "4 carol 1 3 1"}
+			if !slices.Equal(got, want) {
+				t.Errorf("expected ranks %q, got %q", want, got)
+			}
+			if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.AdminLeaderboardExportURL(), nil); rr.Code != http.StatusUnauthorized {
+				t.Errorf("expected the export to need admin auth, got %d", rr.Code)
+			}
+
+			form := url.Values{db.SettingLeaderboard: {"true"}}
+			req = httptest.NewRequest(http.MethodPost, web.AdminSettingsURL(), strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			addBasicAuth(req, "admin", testAdminPassword)
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected the leaderboard setting to save, got %d", rr.Code)
+			}
+			body := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.LeaderboardURL(), nil).Body.String()
+			if !strings.Contains(body, "alice") || strings.Index(body, "alice") > strings.Index(body, "carol") {
+				t.Errorf("expected alice to top the published leaderboard")
+			}
+			if body := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.ResultsListURL()+"/", nil).Body.String(); !strings.Contains(body, `href="/leaderboard"`) {
+				t.Errorf("expected the results list to link to the leaderboard")
+			}
+		})
+	}
+}
+
+func TestAdminImport(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeModern, web.UIModeLegacy} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, _ := testServerWithMode(t, mode)
+			handler := srv.Handler()
+			createTestCategory(t, queries, "Best Snack", "single", "open", "live")
+
+			upload := func(sheet string) *httptest.ResponseRecorder {
+				var body bytes.Buffer
+				mw := multipart.NewWriter(&body)
+				part, _ := mw.CreateFormFile("sheet", "polls.csv")
+				part.Write([]byte(sheet))
+				mw.Close()
+				req := httptest.NewRequest(http.MethodPost, web.AdminImportURL(), &body)
+				req.Header.Set("Content-Type", mw.FormDataContentType())
+				addBasicAuth(req, "admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+			confirm := func(sheet string) *httptest.ResponseRecorder {
+				form := url.Values{"csv": {sheet}, "confirm": {"1"}}
+				req := httptest.NewRequest(http.MethodPost, web.AdminImportURL(), strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				addBasicAuth(req, "admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+			polls := func() []db.Category {
+				cats, err := queries.ListCategoriesExcludeArchived(t.Context())
+				if err != nil {
+					t.Fatalf("failed to list polls: %v", err)
+				}
+				return cats
+			}
+
+			sheet := "\ufeffCategory,Type,Option,Icon\n" +
+				"Best Game,single,Doom,🎮\n" +
+				"Best Game,,Quake,\n" +
+				"Best Map,ranked,de_dust2,\n" +
+				"Best Map,ranked,\"Facing Worlds, CTF\",\n" +
+				"Best Game,single,Half-Life,\n"
+
+			// Uploading only previews the sheet
+			rr := upload(sheet)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected a preview, got %d: %s", rr.Code, rr.Body.String())
+			}
+			body := rr.Body.String()
+			for _, want := range []string{"Best Game", "Best Map", "Facing Worlds, CTF", "Create 2 polls"} {
+				if !strings.Contains(body, want) {
+					t.Errorf("expected the preview to show %q", want)
+				}
+			}
+			if n := len(polls()); n != 1 {
+				t.Fatalf("expected a preview not to create polls, got %d", n)
+			}
+
+			if rr := confirm(sheet); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected redirect after importing, got %d: %s", rr.Code, rr.Body.String())
+			}
+			cats := polls()
+			if len(cats) != 3 {
+				t.Fatalf("expected 2 imported polls, got %d", len(cats)-1)
+			}
+			game, gameMap := cats[1], cats[2]
+			if game.Name != "Best Game" || game.Status != "draft" || game.Icon != "🎮" || gameMap.VoteType != "ranked" {
+				t.Errorf("expected imported draft polls with their settings, got %+v and %+v", game, gameMap)
+			}
+			var names []string
+			options, _ := queries.ListOptionsByCategory(t.Context(), game.ID)
+			for _, o := range options {
+				names = append(names, o.Name)
+			}
+			if want := []string{"Doom", "Quake", "Half-Life"}; !slices.Equal(names, want) {
+				t.Errorf("expected options %q in sheet order, got %q", want, names)
+			}
+
+			// A sheet with any problem imports nothing and lists every problem
+			bad := "category,type,option\n" +
+				"Best Snack,single,Chips\n" +
+				"Best Band,plural,Rush\n" +
+				"Best Level,single,E1M1\n" +
+				"Best Level,single,e1m1\n" +
+				"Best Level,ranked,E1M2\n"
+			rr = confirm(bad)
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected a bad sheet to be refused, got %d", rr.Code)
+			}
+			for _, want := range []string{"Line 2:", "already exists", "Line 3: Unknown vote type", "twice", "already given type single"} {
+				if !strings.Contains(rr.Body.String(), want) {
+					t.Errorf("expected the errors to mention %q", want)
+				}
+			}
+			if n := len(polls()); n != 3 {
+				t.Errorf("expected a bad sheet to create nothing, got %d polls", n)
+			}
+
+			if rr := upload("name,choice\nBest Game,Doom\n"); !strings.Contains(rr.Body.String(), "Unknown column") {
+				t.Errorf("expected unknown columns to be refused, got %d", rr.Code)
+			}
+		})
+	}
+}
+
+func TestTenants(t *testing.T) {
+	main, mainQueries, conn := testServer(t)
+	defer conn.Close()
+	createTestCategory(t, mainQueries, "Main Hall", "single", "open", "live")
+
+	retroConn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer retroConn.Close()
+	if err := db.Migrate(retroConn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	retro, err := web.NewServer(retroConn, "pac", web.UIModeLegacy)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	createTestCategory(t, db.New(retroConn), "Retro Room", "single", "open", "live")
+
+	tenants := web.NewTenants(main)
+	tenants.Add(retro, "retro.lan", "10.0.0.3", "Arcade.LAN:8080")
+	handler := tenants.Handler()
+	get := func(host, path, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = host
+		if password != "" {
+			req.SetBasicAuth("admin", password)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for host, want := range map[string]string{
+		"RETRO.lan:5000": "Retro Room",
+		"10.0.0.3":       "Retro Room",
+		"arcade.lan":     "Retro Room",
+		"arcade.lan:80":  "Retro Room",
+		"votigo.lan":     "Main Hall",
+		"":               "Main Hall",
+	} {
+		body := get(host, web.HomeURL(), "").Body.String()
+		if !strings.Contains(body, want) {
+			t.Errorf("expected host %q to see %s", host, want)
+		}
+	}
+	if rr := get("retro.lan", web.AdminURL(), testAdminPassword); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected the main password to be refused by another event, got %d", rr.Code)
+	}
+	if rr := get("retro.lan", web.AdminURL(), "pac"); rr.Code != http.StatusOK {
+		t.Errorf("expected the event's own password to work, got %d", rr.Code)
+	}
+	if n := len(tenants.Servers()); n != 2 {
+		t.Errorf("expected 2 servers, got %d", n)
+	}
+}
+
+func TestReloadTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, templates.FS); err != nil {
+		t.Fatalf("failed to copy templates: %v", err)
+	}
+	conn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer conn.Close()
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	srv, err := web.NewServer(conn, testAdminPassword, web.UIModeModern, web.WithTemplateDir(dir))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	handler := srv.Handler()
+	home := func() string {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected home page, got %d", rr.Code)
+		}
+		return rr.Body.String()
+	}
+
+	layout := filepath.Join(dir, "modern", "layout.html")
+	content, err := os.ReadFile(layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(content), "<body", "Edited while running<body", 1)
+	if err := os.WriteFile(layout, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(home(), "Edited while running") {
+		t.Fatal("expected the edit to wait for Reload")
+	}
+	if err := srv.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if !strings.Contains(home(), "Edited while running") {
+		t.Error("expected Reload to pick up the edited layout")
+	}
+
+	// A template that stops parsing leaves the loaded ones in place
+	if err := os.WriteFile(layout, []byte("{{ broken"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Reload(); err == nil {
+		t.Error("expected Reload to report the broken template")
+	}
+	if !strings.Contains(home(), "Edited while running") {
+		t.Error("expected the last good templates to stay")
+	}
+}
+
+func TestAdminSeatmap(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			get := func(url string, htmx bool) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, url, nil)
+				req.SetBasicAuth("admin", testAdminPassword)
+				if htmx {
+					req.Header.Set("HX-Request", "true")
+				}
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+
+			if rr := get(web.AdminSeatmapURL(0), false); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "No poll is open") {
+				t.Fatalf("expected no open poll, got %d", rr.Code)
+			}
+
+			draft := createTestCategory(t, queries, "Draft Poll", "single", "draft", "live")
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			for nickname, seat := range map[string]string{"alice": "B10", "bob": "B2", "carol": "A1", "dave": ""} {
+				if err := queries.UpsertAttendee(t.Context(), db.UpsertAttendeeParams{Nickname: nickname, Seat: seat}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, nickname := range []string{"alice", "dave", "stranger"} {
+				if _, err := queries.UpsertVote(t.Context(), db.UpsertVoteParams{CategoryID: cat.ID, Nickname: nickname}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			rr := get(web.AdminSeatmapURL(0), false)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rr.Code)
+			}
+			body := rr.Body.String()
+			if !strings.Contains(body, "2 of 4 voted (50%)") {
+				t.Errorf("expected turnout counted over the roster only, got:\n%s", body)
+			}
+			a1, b2, b10 := strings.Index(body, "A1: carol"), strings.Index(body, "B2: bob"), strings.Index(body, "B10: alice")
+			if a1 < 0 || !(a1 < b2 && b2 < b10) {
+				t.Errorf("expected seats in row and number order, got A1 at %d, B2 at %d, B10 at %d", a1, b2, b10)
+			}
+			if !strings.Contains(body, "No seat:") || !strings.Contains(body, "dave") {
+				t.Error("expected the unseated attendee listed")
+			}
+
+			if rr := get(web.AdminSeatmapURL(draft.ID), false); rr.Code != http.StatusNotFound {
+				t.Errorf("expected 404 for a poll that isn't open, got %d", rr.Code)
+			}
+			if mode == web.UIModeModern {
+				rr := get(web.AdminSeatmapURL(cat.ID), true)
+				if body := rr.Body.String(); rr.Code != http.StatusOK || strings.Contains(body, "<html") || !strings.Contains(body, "2 of 4 voted") {
+					t.Errorf("expected the map alone for htmx, got %d:\n%s", rr.Code, body)
+				}
+			}
+		})
+	}
+}
+
+func TestEligibility(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+	handler := srv.Handler()
+	admin := func(method, path string, form url.Values) *httptest.ResponseRecorder {
+		var req *http.Request
+		if form != nil {
+			req = httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			req = httptest.NewRequest(method, path, nil)
+		}
+		req.SetBasicAuth("admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, nickname := range []string{"ace", "bob", "cat"} {
+		if err := queries.UpsertAttendee(t.Context(), db.UpsertAttendeeParams{Nickname: nickname}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	attendees, err := queries.ListAttendees(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]int64)
+	for _, a := range attendees {
+		ids[a.Nickname] = a.ID
+	}
+	for _, tagged := range []struct{ nickname, tags string }{{"ace", "Participant"}, {"bob", "participant, crew"}} {
+		rr := admin(http.MethodPost, web.AdminRosterTagsURL(ids[tagged.nickname]), url.Values{"tags": {tagged.tags}})
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected tags saved for %s, got %d: %s", tagged.nickname, rr.Code, rr.Body.String())
+		}
+	}
+	if rr := admin(http.MethodPost, web.AdminRosterTagsURL(ids["cat"]), url.Values{"tags": {"a/b"}}); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected a bad tag refused, got %d", rr.Code)
+	}
+	if rr := admin(http.MethodPost, web.AdminRosterTagsURL(999), url.Values{"tags": {"crew"}}); rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown attendee, got %d", rr.Code)
+	}
+	var detail string
+	conn.QueryRow("SELECT detail FROM audit_events WHERE action = ? ORDER BY id DESC", db.AuditRosterTags).Scan(&detail)
+	if want := fmt.Sprintf("attendee %d: crew participant", ids["bob"]); detail != want {
+		t.Errorf("expected audit detail %q, got %q", want, detail)
+	}
+
+	rr := admin(http.MethodGet, web.AdminRosterURL(), nil)
+	if body := rr.Body.String(); rr.Code != http.StatusOK || !strings.Contains(body, "participant (2)") || !strings.Contains(body, "crew (1)") {
+		t.Errorf("expected the roster with tag counts, got %d:\n%s", rr.Code, body)
+	}
+
+	// The category form checks the expression and stores it tidied
+	cat := createTestCategory(t, queries, "Fan Pick", "single", "draft", "live")
+	form := url.Values{"name": {"Fan Pick"}, "vote_type": {"single"}, "show_results": {"live"}, "eligibility": {"player|"}}
+	if rr := admin(http.MethodPost, web.AdminCategoryURL(cat.ID), form); rr.Code == http.StatusSeeOther || !strings.Contains(rr.Body.String(), "Eligibility: ") {
+		t.Errorf("expected a bad expression refused, got %d", rr.Code)
+	}
+	form.Set("eligibility", "!CREW participant")
+	if rr := admin(http.MethodPost, web.AdminCategoryURL(cat.ID), form); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected the poll saved, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if cat, _ = queries.Category(t.Context(), cat.ID); cat.Eligibility != "participant !crew" {
+		t.Errorf("expected the expression stored tidied, got %q", cat.Eligibility)
+	}
+	opt := createTestOption(t, queries, cat.ID, "A")
+	if _, err := db.OpenCategory(t.Context(), conn, cat.ID, db.ActorAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		nickname string
+		refusal  string
+	}{
+		{"ace", ""},
+		{"bob", "Attendees tagged crew can't vote in this poll"},
+		{"cat", "Only attendees tagged participant can vote in this poll"},
+		{"stranger", "Only attendees tagged participant can vote in this poll"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nickname, func(t *testing.T) {
+			rr := makeRequest(t, handler.ServeHTTP, http.MethodPost, web.VoteURL(cat.ID), url.Values{
+				"nickname": {tt.nickname},
+				"choice":   {strconv.FormatInt(opt.ID, 10)},
+			})
+			if body := rr.Body.String(); tt.refusal != "" && !strings.Contains(body, html.EscapeString(tt.refusal)) {
+				t.Errorf("expected the form to refuse with %q, got %d:\n%s", tt.refusal, rr.Code, body)
+			}
+
+			body := fmt.Sprintf(`{"nickname":%q,"choices":[%d]}`, tt.nickname, opt.ID)
+			rr = postJSON(t, handler, web.APICategoryVotesURL(cat.ID), body)
+			want := http.StatusCreated
+			if tt.refusal != "" {
+				want = http.StatusForbidden
+			}
+			if rr.Code != want || !strings.Contains(rr.Body.String(), tt.refusal) {
+				t.Errorf("expected the API to answer %d with %q, got %d: %s", want, tt.refusal, rr.Code, rr.Body.String())
+			}
+		})
+	}
+	if count, _ := queries.CountVotesByCategory(t.Context(), cat.ID); count != 1 {
+		t.Errorf("expected only ace's ballot, got %d", count)
+	}
+}
+
+func TestAdminGroups(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			get := func(url string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, url, nil)
+				req.SetBasicAuth("admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			a := createTestOption(t, queries, cat.ID, "Alpha")
+			b := createTestOption(t, queries, cat.ID, "Bravo")
+			for nickname, tag := range map[string]string{"ace": "crew", "bob": "participant", "cat": "participant", "dan": "participant"} {
+				if err := queries.UpsertAttendee(t.Context(), db.UpsertAttendeeParams{Nickname: nickname}); err != nil {
+					t.Fatal(err)
+				}
+				attendee, err := queries.GetAttendeeByNickname(t.Context(), nickname)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := queries.SetAttendeeTags(t.Context(), attendee.ID, []string{tag}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for nickname, option := range map[string]int64{"ace": a.ID, "bob": b.ID, "cat": b.ID, "stranger": a.ID} {
+				vote, err := queries.UpsertVote(t.Context(), db.UpsertVoteParams{CategoryID: cat.ID, Nickname: nickname})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := queries.CreateVoteSelection(t.Context(), db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: option}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			rr := get(web.AdminCategoryGroupsURL(cat.ID))
+			body := rr.Body.String()
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rr.Code)
+			}
+			for _, want := range []string{"1 of 1 (100%)", "2 of 3 (66%)", "1 from voters not on the roster"} {
+				if !strings.Contains(body, want) {
+					t.Errorf("expected %q in the breakdown, got:\n%s", want, body)
+				}
+			}
+			if strings.Contains(body, "differs") {
+				t.Error("expected no group tallies unless asked for")
+			}
+
+			// Participants pick Bravo, which leads; the crew's Alpha doesn't
+			body = get(web.AdminCategoryGroupsURL(cat.ID) + "?tallies=1").Body.String()
+			if !strings.Contains(body, "Overall leader") || !strings.Contains(body, "Bravo") {
+				t.Errorf("expected the overall leader, got:\n%s", body)
+			}
+			if n := strings.Count(body, "differs"); n != 1 {
+				t.Errorf("expected only the crew to differ, got %d:\n%s", n, body)
+			}
+
+			if rr := get(web.AdminCategoryGroupsURL(999)); rr.Code != http.StatusNotFound {
+				t.Errorf("expected 404 for an unknown poll, got %d", rr.Code)
+			}
+			if rr := get(web.AdminCategoryURL(cat.ID)); !strings.Contains(rr.Body.String(), web.AdminCategoryGroupsURL(cat.ID)) {
+				t.Error("expected the poll's admin page to link to the breakdown")
+			}
+		})
+	}
+}
+
+func TestAdminBallotsExport(t *testing.T) {
+	srv, queries, conn := testServer(t)
+	defer conn.Close()
+	handler := srv.Handler()
+
+	cat := createTestCategory(t, queries, "Best Game", "single", "closed", "live")
+	opt := createTestOption(t, queries, cat.ID, "Alpha")
+	vote, err := queries.UpsertVote(t.Context(), db.UpsertVoteParams{CategoryID: cat.ID, Nickname: "secretive"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := queries.CreateVoteSelection(t.Context(), db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: opt.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.AdminBallotsExportURL("csv"), nil); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected the export behind admin auth, got %d", rr.Code)
+	}
+	for format, contentType := range map[string]string{"csv": "text/csv; charset=utf-8", "json": "application/json"} {
+		req := httptest.NewRequest(http.MethodGet, web.AdminBallotsExportURL(format), nil)
+		req.SetBasicAuth("admin", testAdminPassword)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		body := rr.Body.String()
+		if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != contentType {
+			t.Fatalf("%s: expected status 200 with %s, got %d with %s", format, contentType, rr.Code, rr.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(body, "Best Game") || !strings.Contains(body, "Alpha") || strings.Contains(body, "secretive") {
+			t.Errorf("%s: expected the ballot without its nickname, got:\n%s", format, body)
+		}
+	}
+}
+
+func TestAdminQuick(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			do := func(method, target string, form url.Values, htmx bool) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.SetBasicAuth("admin", testAdminPassword)
+				if htmx {
+					req.Header.Set("HX-Request", "true")
+				}
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+
+			createTestCategory(t, queries, "Done Poll", "single", "closed", "live")
+			draft := createTestCategory(t, queries, "Draft Poll", "single", "draft", "live")
+			createTestOption(t, queries, draft.ID, "Doom")
+			open := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			for _, nickname := range []string{"alice", "bob"} {
+				if _, err := queries.UpsertVote(t.Context(), db.UpsertVoteParams{CategoryID: open.ID, Nickname: nickname}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			rr := do(http.MethodGet, web.AdminQuickURL(), nil, false)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rr.Code)
+			}
+			body := rr.Body.String()
+			o, d, c := strings.Index(body, "Best Game"), strings.Index(body, "Draft Poll"), strings.Index(body, "Done Poll")
+			if o < 0 || !(o < d && d < c) {
+				t.Errorf("expected open, draft then closed polls, got them at %d, %d, %d", o, d, c)
+			}
+			if !strings.Contains(body, "Close voting") || !strings.Contains(body, "Open voting") {
+				t.Error("expected open and close buttons")
+			}
+
+			// Opening from the page comes back to it
+			rr = do(http.MethodPost, web.AdminCategoryOpenURL(draft.ID), url.Values{"next": {web.AdminQuickURL()}}, false)
+			if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != web.AdminQuickURL() {
+				t.Errorf("expected a redirect back to the quick controls, got %d to %q", rr.Code, rr.Header().Get("Location"))
+			}
+			if cat, _ := queries.GetCategory(t.Context(), draft.ID); cat.Status != "open" {
+				t.Errorf("expected the draft opened, got %s", cat.Status)
+			}
+			rr = do(http.MethodPost, web.AdminCategoryCloseURL(open.ID), url.Values{"next": {"//evil.example"}}, false)
+			if rr.Header().Get("Location") != web.AdminURL() {
+				t.Errorf("expected an off-site next ignored, got %q", rr.Header().Get("Location"))
+			}
+
+			if mode == web.UIModeModern {
+				rr := do(http.MethodGet, web.AdminQuickURL(), nil, true)
+				if body := rr.Body.String(); rr.Code != http.StatusOK || strings.Contains(body, "<html") || !strings.Contains(body, "Draft Poll") {
+					t.Errorf("expected the poll list alone for htmx, got %d:\n%s", rr.Code, body)
+				}
+			}
+		})
+	}
+}
+
+func TestObserverLinks(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			do := func(method, target string, form url.Values, admin bool) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				if admin {
+					req.SetBasicAuth("admin", testAdminPassword)
+				}
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+
+			createTestCategory(t, queries, "Secret Draft", "single", "draft", "live")
+			hidden := createTestCategory(t, queries, "Hidden Poll", "single", "open", "after_close")
+			createTestOption(t, queries, hidden.ID, "Doom")
+
+			rr := do(http.MethodPost, web.AdminObserversURL(), url.Values{"label": {""}, "hours": {"48"}}, true)
+			if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "who the link is for") {
+				t.Errorf("expected a missing label refused, got %d", rr.Code)
+			}
+			rr = do(http.MethodPost, web.AdminObserversURL(), url.Values{"label": {"Judges"}, "hours": {"9999"}}, true)
+			if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "Judges") {
+				t.Errorf("expected too long a link refused with the label kept, got %d", rr.Code)
+			}
+
+			rr = do(http.MethodPost, web.AdminObserversURL(), url.Values{"label": {"Judges"}, "hours": {"2"}}, true)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rr.Code)
+			}
+			link := regexp.MustCompile(`/observe/[A-Za-z0-9_.-]+`).FindString(rr.Body.String())
+			if link == "" {
+				t.Fatalf("expected an observer link, got:\n%s", rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), "data:image/png;base64,") {
+				t.Error("expected a QR code for the link")
+			}
+
+			rr = do(http.MethodGet, link, nil, false)
+			body := rr.Body.String()
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200 without a password, got %d", rr.Code)
+			}
+			if !strings.Contains(body, "Hidden Poll") || !strings.Contains(body, "Doom") || !strings.Contains(body, "Judges") {
+				t.Errorf("expected results voters can't see yet, got:\n%s", body)
+			}
+			if strings.Contains(body, "Secret Draft") || strings.Contains(body, `method="POST"`) {
+				t.Error("expected no drafts and nothing to submit")
+			}
+			if rr.Header().Get("Referrer-Policy") != "no-referrer" {
+				t.Error("expected the link kept out of referrers")
+			}
+
+			if mode == web.UIModeModern {
+				req := httptest.NewRequest(http.MethodGet, link, nil)
+				req.Header.Set("HX-Request", "true")
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				if body := rr.Body.String(); rr.Code != http.StatusOK || strings.Contains(body, "<html") || !strings.Contains(body, "Hidden Poll") {
+					t.Errorf("expected the polls alone for htmx, got %d:\n%s", rr.Code, body)
+				}
+			}
+
+			if rr := do(http.MethodPost, link, nil, false); rr.Code != http.StatusMethodNotAllowed {
+				t.Errorf("expected POST refused, got %d", rr.Code)
+			}
+			if rr := do(http.MethodGet, link+"x", nil, false); rr.Code != http.StatusNotFound {
+				t.Errorf("expected a tampered link refused, got %d", rr.Code)
+			}
+			if rr := do(http.MethodGet, strings.Replace(link, "/observe/", "/admin/", 1), nil, false); rr.Code != http.StatusUnauthorized {
+				t.Errorf("expected the link to grant nothing under /admin, got %d", rr.Code)
+			}
+
+			rr = do(http.MethodPost, web.AdminObserversRevokeURL(), nil, true)
+			if rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected a redirect, got %d", rr.Code)
+			}
+			if rr := do(http.MethodGet, link, nil, false); rr.Code != http.StatusNotFound {
+				t.Errorf("expected a revoked link refused, got %d", rr.Code)
+			}
+		})
+	}
+}
+
+func TestJudgedResults(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+
+			cat := createTestCategory(t, queries, "Best Cosplay", "single", "open", "live")
+			a := createTestOption(t, queries, cat.ID, "Alpha")
+			b := createTestOption(t, queries, cat.ID, "Bravo")
+
+			form := url.Values{"name": {cat.Name}, "vote_type": {"single"}, "show_results": {"live"}, "judges": {" Jury "}, "judge_weight": {"50"}}
+			req := httptest.NewRequest(http.MethodPost, web.AdminCategoryURL(cat.ID), strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetBasicAuth("admin", testAdminPassword)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			cat, _ = queries.GetCategory(t.Context(), cat.ID)
+			if cat.Judges != "jury" || cat.JudgeWeight != 50 {
+				t.Fatalf("expected judges tagged jury deciding 50%%, got %q and %d", cat.Judges, cat.JudgeWeight)
+			}
+
+			if err := queries.UpsertAttendee(t.Context(), db.UpsertAttendeeParams{Nickname: "judy"}); err != nil {
+				t.Fatal(err)
+			}
+			judge, err := queries.GetAttendeeByNickname(t.Context(), "judy")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := queries.SetAttendeeTags(t.Context(), judge.ID, []string{"jury"}); err != nil {
+				t.Fatal(err)
+			}
+			for nickname, option := range map[string]int64{"judy": a.ID, "ace": a.ID, "bob": b.ID, "cat": b.ID} {
+				vote, err := queries.UpsertVote(t.Context(), db.UpsertVoteParams{CategoryID: cat.ID, Nickname: nickname})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := queries.CreateVoteSelection(t.Context(), db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: option}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The judge's vote is all of the jury's half; the audience split 1-2
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, web.ResultsURL(cat.ID), nil))
+			body := rr.Body.String()
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rr.Code)
+			}
+			for _, want := range []string{"Judges", "Audience", "66.7 points", "33.3 points", "2 votes"} {
+				if !strings.Contains(body, want) {
+					t.Errorf("expected %q in the results, got:\n%s", want, body)
+				}
+			}
+			if strings.Index(body, "Alpha") > strings.Index(body, "Bravo") {
+				t.Error("expected the judges to put Alpha ahead of the audience's Bravo")
+			}
+		})
+	}
+}
+
+func TestSeason(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			do := func(method, target string, form url.Values, admin bool) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				if admin {
+					req.SetBasicAuth("admin", testAdminPassword)
+				}
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+
+			if rr := do(http.MethodGet, web.SeasonURL(), nil, false); rr.Code != http.StatusNotFound {
+				t.Errorf("expected no season page until the season is named, got %d", rr.Code)
+			}
+
+			rr := do(http.MethodPost, web.AdminSeasonURL(), url.Values{"name": {" "}}, true)
+			if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "name the event") {
+				t.Errorf("expected an unnamed event refused, got %d", rr.Code)
+			}
+			rr = do(http.MethodPost, web.AdminSeasonURL(), url.Values{"name": {"Winter LAN"}}, true)
+			if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "No finished polls") {
+				t.Errorf("expected nothing to finalize without finished polls, got %d", rr.Code)
+			}
+
+			cat := createTestCategory(t, queries, "Best Game", "single", "closed", "live")
+			doom := createTestOption(t, queries, cat.ID, "Doom")
+			createTestOption(t, queries, cat.ID, "Quake")
+			vote, err := queries.UpsertVote(t.Context(), db.UpsertVoteParams{CategoryID: cat.ID, Nickname: "alice"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := queries.CreateVoteSelection(t.Context(), db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: doom.ID}); err != nil {
+				t.Fatal(err)
+			}
+			createTestCategory(t, queries, "Best Snack", "single", "open", "live")
+
+			body := do(http.MethodGet, web.AdminSeasonURL(), nil, true).Body.String()
+			if !strings.Contains(body, "Best Game") || !strings.Contains(body, "1 poll is still open") {
+				t.Errorf("expected the finished poll previewed and the open one noted, got:\n%s", body)
+			}
+			rr = do(http.MethodPost, web.AdminSeasonURL(), url.Values{"name": {"Winter LAN"}}, true)
+			if rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected a redirect, got %d: %s", rr.Code, rr.Body.String())
+			}
+			if cat, _ := queries.GetCategory(t.Context(), cat.ID); cat.Status != "archived" {
+				t.Errorf("expected the scored poll archived, got %s", cat.Status)
+			}
+
+			rr = do(http.MethodPost, web.AdminSettingsURL(), url.Values{db.SettingSeason: {"Palm Arcade 2026"}}, true)
+			if rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected the season setting to save, got %d", rr.Code)
+			}
+			rr = do(http.MethodGet, web.SeasonURL(), nil, false)
+			body = rr.Body.String()
+			if rr.Code != http.StatusOK || !strings.Contains(body, "Palm Arcade 2026") || !strings.Contains(body, "Winter LAN") {
+				t.Fatalf("expected the published season, got %d:\n%s", rr.Code, body)
+			}
+			if !strings.Contains(body, "Doom") || strings.Contains(body, "Quake") {
+				t.Errorf("expected Doom's points and nothing for Quake, which got no votes")
+			}
+			if body := do(http.MethodGet, web.ResultsListURL()+"/", nil, false).Body.String(); !strings.Contains(body, `href="/season"`) {
+				t.Error("expected the results list to link to the season")
+			}
+
+			events, err := queries.ListSeasonEvents(t.Context())
+			if err != nil || len(events) != 1 {
+				t.Fatalf("expected one event, got %v (%v)", events, err)
+			}
+			if rr := do(http.MethodPost, web.AdminSeasonRemoveURL(events[0].ID+1), nil, true); rr.Code != http.StatusNotFound {
+				t.Errorf("expected an unknown event not found, got %d", rr.Code)
+			}
+			if rr := do(http.MethodPost, web.AdminSeasonRemoveURL(events[0].ID), nil, true); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected a redirect, got %d", rr.Code)
+			}
+			if body := do(http.MethodGet, web.SeasonURL(), nil, false).Body.String(); strings.Contains(body, "Doom") {
+				t.Error("expected the removed event's points gone")
+			}
+		})
+	}
+}
+
+func TestAdminWindow(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			get := func(target string, admin bool) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, target, nil)
+				if admin {
+					req.SetBasicAuth("admin", testAdminPassword)
+				}
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+
+			cat := createTestCategory(t, queries, "Best Game", "single", "closed", "live")
+			doom := createTestOption(t, queries, cat.ID, "Doom")
+			quake := createTestOption(t, queries, cat.ID, "Quake")
+			cutoff := time.Date(2026, 10, 17, 22, 0, 0, 0, time.Local)
+			for i, ballot := range []struct {
+				option int64
+				at     time.Time
+			}{
+				{doom.ID, cutoff.Add(-2 * time.Hour)},
+				{doom.ID, cutoff.Add(-time.Hour)},
+				{quake.ID, cutoff.Add(3 * time.Hour)},
+				{quake.ID, cutoff.Add(4 * time.Hour)},
+				{quake.ID, cutoff.Add(5 * time.Hour)},
+			} {
+				vote, err := queries.UpsertVote(t.Context(), db.UpsertVoteParams{CategoryID: cat.ID, Nickname: "voter" + strconv.Itoa(i)})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := queries.CreateVoteSelection(t.Context(), db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: ballot.option}); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := conn.Exec("UPDATE votes SET created_at = ? WHERE id = ?", ballot.at.UTC(), vote.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if rr := get(web.AdminCategoryWindowURL(cat.ID), false); rr.Code != http.StatusUnauthorized {
+				t.Errorf("expected the split to need admin auth, got %d", rr.Code)
+			}
+			rr := get(web.AdminCategoryWindowURL(cat.ID), true)
+			if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Oct 17 20:00") {
+				t.Errorf("expected when ballots were cast, got %d:\n%s", rr.Code, rr.Body.String())
+			}
+			if rr := get(web.AdminCategoryWindowURL(cat.ID)+"?at=tonight", true); rr.Code != http.StatusBadRequest {
+				t.Errorf("expected a bad time refused, got %d", rr.Code)
+			}
+
+			rr = get(web.AdminCategoryWindowURL(cat.ID)+"?at=2026-10-17T22:00", true)
+			body := rr.Body.String()
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rr.Code)
+			}
+			before := strings.Index(body, "Before Oct 17 22:00")
+			after := strings.Index(body, "From Oct 17 22:00")
+			all := strings.Index(body, "All ballots")
+			if before < 0 || after < before || all < after {
+				t.Fatalf("expected the before, after and all columns in order, got:\n%s", body)
+			}
+			if got := strings.Count(body, "different winner"); got != 1 {
+				t.Errorf("expected the ballots before the split to pick a different winner, flagged once, got %d", got)
+			}
+			if !strings.Contains(body[before:after], "2 ballots") || !strings.Contains(body[after:all], "3 ballots") {
+				t.Errorf("expected 2 ballots before the split and 3 after")
+			}
+		})
+	}
+}
+
+func TestAdminBallots(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			cat := createTestCategory(t, queries, "Best Game", "single", "closed", "live")
+			do := func(method string, form url.Values, admin bool) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, web.AdminCategoryBallotsURL(cat.ID), strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				if admin {
+					req.SetBasicAuth("admin", testAdminPassword)
+				}
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+
+			doom := createTestOption(t, queries, cat.ID, "Doom")
+			quake := createTestOption(t, queries, cat.ID, "Quake")
+			closes := time.Date(2026, 10, 17, 22, 0, 0, 0, time.Local)
+			var late int64
+			for _, ballot := range []struct {
+				nickname string
+				option   int64
+				at       time.Time
+				address  string
+			}{
+				{"alice", doom.ID, closes.Add(-2 * time.Hour), "10.0.0.2"},
+				{"bob", doom.ID, closes.Add(-time.Hour), "10.0.0.3"},
+				{"carol", quake.ID, closes.Add(-time.Hour), "10.0.9.4"},
+				{"dave", quake.ID, closes.Add(-time.Hour), "10.0.9.5"},
+				{"erin", quake.ID, closes.Add(time.Hour), "10.0.0.6"},
+			} {
+				vote, err := queries.UpsertVote(t.Context(), db.UpsertVoteParams{CategoryID: cat.ID, Nickname: ballot.nickname})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := queries.CreateVoteSelection(t.Context(), db.CreateVoteSelectionParams{VoteID: vote.ID, OptionID: ballot.option}); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := conn.Exec("UPDATE votes SET created_at = ? WHERE id = ?", ballot.at.UTC(), vote.ID); err != nil {
+					t.Fatal(err)
+				}
+				if err := queries.RecordAuditFrom(t.Context(), db.AuditOrigin{Address: ballot.address}, ballot.nickname, db.AuditVote, cat.ID, ""); err != nil {
+					t.Fatal(err)
+				}
+				late = vote.ID
+			}
+			leader := func() string {
+				t.Helper()
+				tallied, err := queries.Tally(t.Context(), cat)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return tallied[0].Name + " " + tallied[0].Label
+			}
+
+			if rr := do(http.MethodGet, nil, false); rr.Code != http.StatusUnauthorized {
+				t.Errorf("expected the ballots page to need admin auth, got %d", rr.Code)
+			}
+			rr := do(http.MethodGet, nil, true)
+			if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "10.0.9.4") || !strings.Contains(rr.Body.String(), "carol") {
+				t.Errorf("expected ballots listed with voters and addresses, got %d:\n%s", rr.Code, rr.Body.String())
+			}
+
+			if rr := do(http.MethodPost, url.Values{"to": {"2026-10-17T22:00"}}, true); rr.Code != http.StatusBadRequest {
+				t.Errorf("expected a missing reason refused, got %d", rr.Code)
+			}
+			if rr := do(http.MethodPost, url.Values{"reason": {"why not"}}, true); rr.Code != http.StatusBadRequest {
+				t.Errorf("expected invalidating nothing refused, got %d", rr.Code)
+			}
+			if rr := do(http.MethodPost, url.Values{"addresses": {"the lab"}, "reason": {"stuffing"}}, true); rr.Code != http.StatusBadRequest {
+				t.Errorf("expected a bad address refused, got %d", rr.Code)
+			}
+			if got := leader(); got != "Quake 3 votes" {
+				t.Fatalf("expected refused forms to change nothing, got %q", got)
+			}
+
+			rr = do(http.MethodPost, url.Values{
+				"to":        {"2026-10-17 22:00"},
+				"addresses": {"10.0.9.0/24"},
+				"nicknames": {"Nobody\n"},
+				"reason":    {"late or stuffed"},
+			}, true)
+			if rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected a redirect after invalidating, got %d: %s", rr.Code, rr.Body.String())
+			}
+			if got := leader(); got != "Doom 2 votes" {
+				t.Errorf("expected the late and stuffed ballots left out, got %q", got)
+			}
+			rr = do(http.MethodGet, nil, true)
+			if got := strings.Count(rr.Body.String(), "Invalid: late or stuffed"); got != 3 {
+				t.Errorf("expected 3 ballots shown invalid with the reason, got %d", got)
+			}
+
+			if rr := do(http.MethodPost, url.Values{"restore": {strconv.FormatInt(late, 10)}}, true); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected a redirect after restoring, got %d", rr.Code)
+			}
+			if n, _ := queries.CountVotesByCategory(t.Context(), cat.ID); n != 3 {
+				t.Errorf("expected 3 ballots counted after restoring one, got %d", n)
+			}
+			var actions []string
+			rows, err := conn.Query("SELECT action FROM audit_events WHERE action LIKE 'ballot.%' ORDER BY id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var action string
+				rows.Scan(&action)
+				actions = append(actions, action)
+			}
+			if want := []string{db.AuditBallotInvalid, db.AuditBallotRestore}; !slices.Equal(actions, want) {
+				t.Errorf("expected audit actions %v, got %v", want, actions)
+			}
+		})
+	}
+}
+
+func TestOptionDetails(t *testing.T) {
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			srv, queries, conn := testServerWithMode(t, mode)
+			defer conn.Close()
+			handler := srv.Handler()
+			do := func(method, target string, form url.Values, admin, htmx bool) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				if admin {
+					req.SetBasicAuth("admin", testAdminPassword)
+				}
+				if htmx {
+					req.Header.Set("HX-Request", "true")
+				}
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			doom := createTestOption(t, queries, cat.ID, "Doom")
+			quake := createTestOption(t, queries, cat.ID, "Quake")
+
+			if rr := do(http.MethodPost, web.AdminOptionDetailsURL(doom.ID), url.Values{"blurb": {"x"}}, false, false); rr.Code != http.StatusUnauthorized {
+				t.Errorf("expected details behind admin auth, got %d", rr.Code)
+			}
+			for _, form := range []url.Values{
+				{"blurb": {strings.Repeat("x", 2001)}},
+				{"video": {"/uploads/clip.mp4"}},
+				{"screenshots": {"javascript:alert(1)"}},
+			} {
+				if rr := do(http.MethodPost, web.AdminOptionDetailsURL(doom.ID), form, true, false); rr.Code != http.StatusBadRequest {
+					t.Errorf("expected %v rejected, got %d", form, rr.Code)
+				}
+			}
+			rr := do(http.MethodPost, web.AdminOptionDetailsURL(doom.ID), url.Values{
+				"blurb":       {"Rip and tear.\r\n\r\nUntil it is done."},
+				"video":       {"https://youtu.be/dQw4w9WgXcQ"},
+				"screenshots": {"/images/e1m1.png\nhttps://example.com/e1m2.png"},
+			}, true, false)
+			if rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected a redirect after saving, got %d: %s", rr.Code, rr.Body.String())
+			}
+			if rr := do(http.MethodGet, web.AdminOptionDetailsURL(doom.ID), nil, true, false); !strings.Contains(rr.Body.String(), "Until it is done.") {
+				t.Errorf("expected the form filled in with the saved details, got:\n%s", rr.Body.String())
+			}
+
+			body := do(http.MethodGet, web.VoteURL(cat.Ref()), nil, false, false).Body.String()
+			if !strings.Contains(body, web.OptionDetailsURL(cat.Ref(), doom.ID)) || strings.Contains(body, web.OptionDetailsURL(cat.Ref(), quake.ID)) {
+				t.Errorf("expected an info link for Doom only, got:\n%s", body)
+			}
+
+			rr = do(http.MethodGet, web.OptionDetailsURL(cat.Ref(), doom.ID), nil, false, false)
+			if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Until it is done.") || !strings.Contains(rr.Body.String(), "dQw4w9WgXcQ") {
+				t.Errorf("expected the details page, got %d:\n%s", rr.Code, rr.Body.String())
+			}
+			if mode == web.UIModeModern {
+				if !strings.Contains(rr.Body.String(), "youtube-nocookie.com/embed/dQw4w9WgXcQ") {
+					t.Errorf("expected the YouTube video embedded, got:\n%s", rr.Body.String())
+				}
+				rr = do(http.MethodGet, web.OptionDetailsURL(cat.Ref(), doom.ID), nil, false, true)
+				if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Rip and tear.") || strings.Contains(rr.Body.String(), "<html") {
+					t.Errorf("expected the modal's partial for htmx, got %d:\n%s", rr.Code, rr.Body.String())
+				}
+			}
+
+			other := createTestCategory(t, queries, "Best Map", "single", "open", "live")
+			if rr := do(http.MethodGet, web.OptionDetailsURL(other.Ref(), doom.ID), nil, false, false); rr.Code != http.StatusNotFound {
+				t.Errorf("expected another poll's option to be 404, got %d", rr.Code)
+			}
+			draft := createTestCategory(t, queries, "Secret Poll", "single", "draft", "live")
+			hidden := createTestOption(t, queries, draft.ID, "Hexen")
+			if rr := do(http.MethodGet, web.OptionDetailsURL(draft.Ref(), hidden.ID), nil, false, false); rr.Code != http.StatusNotFound {
+				t.Errorf("expected a draft poll's option hidden from voters, got %d", rr.Code)
+			}
+			if rr := do(http.MethodGet, web.OptionDetailsURL(draft.Ref(), hidden.ID), nil, true, false); rr.Code != http.StatusOK {
+				t.Errorf("expected a draft poll's option shown to admins, got %d", rr.Code)
+			}
+		})
+	}
+}
+
+func TestMediaPreviews(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("url") {
+		case "https://www.youtube.com/watch?v=dQw4w9WgXcQ":
+			w.Write([]byte(`{"title":"Zool 2 longplay","author_name":"Amiga Longplays","thumbnail_url":"https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg"}`))
+		case "https://www.youtube.com/watch?v=private0000":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer provider.Close()
+
+	for _, mode := range []web.UIMode{web.UIModeLegacy, web.UIModeModern} {
+		t.Run(string(mode), func(t *testing.T) {
+			conn, err := db.Open(":memory:")
+			if err != nil {
+				t.Fatalf("failed to open db: %v", err)
+			}
+			defer conn.Close()
+			if err := db.Migrate(conn); err != nil {
+				t.Fatalf("failed to migrate: %v", err)
+			}
+			queries := db.New(conn)
+			media := oembed.New()
+			media.Endpoints = map[string]string{oembed.YouTube: provider.URL}
+			srv, err := web.NewServer(conn, testAdminPassword, mode, web.WithMediaPreviews(media))
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+			handler := srv.Handler()
+			save := func(id int64, video string) *httptest.ResponseRecorder {
+				form := url.Values{"blurb": {"A 1993 platformer."}, "video": {video}}
+				req := httptest.NewRequest(http.MethodPost, web.AdminOptionDetailsURL(id), strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.SetBasicAuth("admin", testAdminPassword)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr
+			}
+
+			cat := createTestCategory(t, queries, "Best Game", "single", "open", "live")
+			zool := createTestOption(t, queries, cat.ID, "Zool 2")
+			if rr := save(zool.ID, "https://www.youtube.com/channel/UC123"); rr.Code != http.StatusBadRequest {
+				t.Errorf("expected a YouTube link that isn't a video rejected, got %d", rr.Code)
+			}
+			if rr := save(zool.ID, "https://youtu.be/private0000"); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "YouTube says the video is private") {
+				t.Errorf("expected a private video rejected, got %d:\n%s", rr.Code, rr.Body.String())
+			}
+			if rr := save(zool.ID, "https://youtu.be/dQw4w9WgXcQ"); rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected the video saved, got %d:\n%s", rr.Code, rr.Body.String())
+			}
+			preview, err := queries.GetMediaPreview(t.Context(), "https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+			if err != nil || preview.Title != "Zool 2 longplay" || preview.Author != "Amiga Longplays" {
+				t.Errorf("expected the preview cached, got %+v, %v", preview, err)
+			}
+			rr := makeRequest(t, handler.ServeHTTP, http.MethodGet, web.OptionDetailsURL(cat.Ref(), zool.ID), nil)
+			if !strings.Contains(rr.Body.String(), "Zool 2 longplay") {
+				t.Errorf("expected the video's title on the option page, got:\n%s", rr.Body.String())
+			}
+
+			// Without internet the details are still saved, just without a preview
+			doom := createTestOption(t, queries, cat.ID, "Doom")
+			if rr := save(doom.ID, "https://youtu.be/unreachable"); rr.Code != http.StatusSeeOther {
+				t.Errorf("expected the video saved while the provider is down, got %d", rr.Code)
+			}
+			if _, err := queries.GetMediaPreview(t.Context(), "https://www.youtube.com/watch?v=unreachable"); !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("expected no preview while the provider is down, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// settingsTTL bounds how long a change made with `votigo settings set` in
+// another process takes to reach a running server
+const settingsTTL = 2 * time.Second
+
+// settingsCache keeps runtime settings in memory so templates can read them
+// on every render without a query each time
+type settingsCache struct {
+	mu       sync.Mutex
+	values   map[string]string
+	loadedAt time.Time
+}
+
+// setting returns the current value of a setting, reloading all of them if
+// the cache is stale. If reloading fails the previous values are kept.
+func (s *Server) setting(key string) string {
+	c := &s.settings
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.values == nil || time.Since(c.loadedAt) > settingsTTL {
+		values, err := s.queries.SettingValues(context.Background())
+		if err != nil {
+			log.Printf("Failed to load settings: %v", err)
+		} else {
+			c.values = values
+		}
+		c.loadedAt = time.Now()
+	}
+
+	if value, ok := c.values[key]; ok {
+		return value
+	}
+	spec, _ := db.LookupSetting(key)
+	return spec.Default
+}
+
+func (s *Server) settingBool(key string) bool {
+	return s.setting(key) == "true"
+}
+
+// saveSetting stores a setting, records it in the audit log and makes it
+// visible to the next render
+func (s *Server) saveSetting(r *http.Request, key, value string) error {
+	value, err := s.queries.SetSetting(r.Context(), key, value)
+	if err != nil {
+		return err
+	}
+	s.audit(r, db.AuditSettingUpdate, 0, key+"="+value)
+
+	s.settings.mu.Lock()
+	s.settings.values = nil
+	s.settings.mu.Unlock()
+	return nil
+}
+
+// settingField is one row of the settings form
+type settingField struct {
+	db.SettingSpec
+	Value string
+	Error string
+}
+
+// handleAdminSettings shows every runtime setting and saves the ones that
+// changed. Invalid values re-render the form and nothing is saved.
+func (s *Server) handleAdminSettings(w http.ResponseWriter, r *http.Request) {
+	values, err := s.queries.SettingValues(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load settings", err)
+		return
+	}
+
+	fields := make([]settingField, len(db.SettingSpecs))
+	for i, spec := range db.SettingSpecs {
+		fields[i] = settingField{SettingSpec: spec, Value: values[spec.Key]}
+	}
+
+	sounds, err := s.listSounds()
+	if err != nil {
+		log.Printf("Failed to list sounds: %v", err)
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.render(w, "admin/settings.html", map[string]any{
+			"Settings":   fields,
+			"Saved":      r.URL.Query().Get("saved") != "",
+			"Sounds":     sounds,
+			"SoundsOpen": s.soundDir != "",
+		})
+		return
+	case http.MethodPost:
+	default:
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		return
+	}
+
+	// Unchecked checkboxes are not submitted, which Parse reads as false
+	invalid := false
+	for i := range fields {
+		f := &fields[i]
+		raw := r.FormValue(f.Key)
+		value, err := f.Parse(raw)
+		if err != nil {
+			f.Error, f.Value, invalid = err.Error(), raw, true
+			continue
+		}
+		f.Value = value
+	}
+	if invalid {
+		w.WriteHeader(http.StatusBadRequest)
+		s.render(w, "admin/settings.html", map[string]any{
+			"Settings":   fields,
+			"Sounds":     sounds,
+			"SoundsOpen": s.soundDir != "",
+		})
+		return
+	}
+
+	for _, f := range fields {
+		if f.Value == values[f.Key] {
+			continue
+		}
+		if err := s.saveSetting(r, f.Key, f.Value); err != nil {
+			s.renderError(w, fmt.Sprintf("Failed to save %s", f.Label), err)
+			return
+		}
+		// Open pages follow the countdown without reloading
+		if f.Key == db.SettingCeremonyAt {
+			s.publish(EventCountdown, s.countdown())
+		}
+	}
+
+	http.Redirect(w, r, AdminSettingsURL()+"?saved=1", http.StatusSeeOther)
+}
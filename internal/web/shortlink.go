@@ -0,0 +1,64 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// handleShortLink redirects /c/{code} to the poll's ballot while it is open
+// and to its results once voting is over. Drafts and unknown codes are 404s.
+// The redirect is temporary since where a code leads changes with the poll.
+func (s *Server) handleShortLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+
+	id, ok := db.ShortCodeID(strings.TrimPrefix(r.URL.Path, "/c/"))
+	if !ok {
+		s.notFound(w, r)
+		return
+	}
+	cat, err := s.queries.Category(r.Context(), id)
+	if err != nil || cat.Status == "draft" {
+		s.notFound(w, r)
+		return
+	}
+
+	target := ResultsURL(cat.Ref())
+	if cat.Status == "open" {
+		target = VoteURL(cat.Ref())
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// handleAdminLinks renders the printable sheet of short links for every
+// draft and open poll, to put up around the venue before voting starts
+func (s *Server) handleAdminLinks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	categories, err := s.queries.ListCategoriesExcludeArchived(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load polls", err)
+		return
+	}
+
+	data := LinkSheetData{Page: Page{Title: "Short Links"}}
+	for _, cat := range categories {
+		if cat.Finished() {
+			continue
+		}
+		code := cat.ShortCode()
+		data.Links = append(data.Links, ShortLink{
+			Category: cat,
+			Code:     code,
+			URL:      absoluteURL(r, ShortLinkURL(code)),
+		})
+	}
+	s.render(w, "admin/links.html", data)
+}
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"errors"
+	"html/template"
+	"strings"
+)
+
+// maxCustomCSS bounds a poll's custom CSS; it is meant for a few tweaks,
+// not a whole theme
+const maxCustomCSS = 4 << 10
+
+// Skin is a preset look for a poll's ballot and results pages. Skins style
+// plain elements so they work with both UIs.
+type Skin struct {
+	Name  string
+	Label string
+	CSS   template.CSS
+}
+
+// Skins lists the presets in display order. Polls store the name, so a
+// skin's CSS can be retuned without touching the database.
+var Skins = []Skin{
+	{
+		Name:  "crt",
+		Label: "CRT scanlines",
+		CSS: `body { background: radial-gradient(ellipse at center, #0b1a0b 0%, #000 80%); color: #8cff8c; text-shadow: 0 0 4px rgba(80, 255, 80, 0.6); }
+body::after { content: ""; position: fixed; inset: 0; pointer-events: none; z-index: 60; background: repeating-linear-gradient(to bottom, rgba(0, 0, 0, 0.25) 0, rgba(0, 0, 0, 0.25) 1px, transparent 1px, transparent 3px); }
+h1, h2, h3 { color: #b6ffb6; letter-spacing: 0.08em; }`,
+	},
+	{
+		Name:  "neon",
+		Label: "Neon",
+		CSS: `body { background: #0d0221; color: #f0e6ff; }
+h1, h2, h3 { color: #ff2a6d; text-shadow: 0 0 6px #ff2a6d, 0 0 18px #ff2a6d; }
+a { color: #05d9e8; }`,
+	},
+	{
+		Name:  "paper",
+		Label: "Paper ballot",
+		CSS: `body { background: #f4ecd8; color: #2b2118; font-family: Georgia, "Times New Roman", serif; text-shadow: none; }
+h1, h2, h3 { color: #2b2118; }
+a { color: #7a2e0e; }`,
+	},
+}
+
+// skinCSS returns the CSS of the named skin, or "" if there is none
+func skinCSS(name string) template.CSS {
+	for _, s := range Skins {
+		if s.Name == name {
+			return s.CSS
+		}
+	}
+	return ""
+}
+
+// ValidSkin reports whether name is empty (the default look) or a preset
+func ValidSkin(name string) bool {
+	return name == "" || skinCSS(name) != ""
+}
+
+// CheckCustomCSS rejects CSS that could break out of the page's <style>
+// element or is too long. Only admins set it, but a stray "</style>" would
+// still take the page down with it. Errors are phrased for showing to an
+// admin.
+func CheckCustomCSS(css string) error {
+	switch {
+	case len(css) > maxCustomCSS:
+		return errors.New("Custom CSS is limited to 4 KB")
+	case strings.Contains(css, "<"):
+		return errors.New("Custom CSS can't contain <")
+	}
+	return nil
+}
+
+// pageSkin is the CSS a poll's voter pages add after the site's own: its
+// skin, then its custom CSS so that can adjust the skin
+func pageSkin(skin, custom string) template.CSS {
+	css := skinCSS(skin)
+	if custom != "" {
+		css += "\n" + template.CSS(custom)
+	}
+	return css
+}
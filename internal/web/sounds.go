@@ -0,0 +1,228 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/slug"
+)
+
+// maxSoundBytes bounds an uploaded sound; cues are short stings, not songs
+const maxSoundBytes = 5 << 20
+
+// soundExts are the audio formats sounds may be uploaded in. Files are
+// served with the type their extension implies.
+var soundExts = []string{".mp3", ".ogg", ".wav", ".m4a", ".webm"}
+
+// Sound is an uploaded audio cue in the sound directory
+type Sound struct {
+	Name string
+	URL  string
+	Size int64
+}
+
+// WithSoundDir keeps uploaded audio cues in dir and serves them under
+// /sounds/. Without it sounds can't be uploaded, though polls can still use
+// cues hosted elsewhere.
+func WithSoundDir(dir string) Option {
+	return func(s *Server) {
+		s.soundDir = dir
+	}
+}
+
+// validSoundName reports whether name is one upload could have produced: a
+// slug with an allowed extension, so it can't leave the sound directory
+func validSoundName(name string) bool {
+	ext := path.Ext(name)
+	return slices.Contains(soundExts, ext) && slug.Valid(strings.TrimSuffix(name, ext))
+}
+
+// ValidSoundURL reports whether u is empty, an uploaded sound or an http(s)
+// URL, the cues a poll may play
+func ValidSoundURL(u string) bool {
+	if u == "" {
+		return true
+	}
+	if name, ok := strings.CutPrefix(u, "/sounds/"); ok {
+		return validSoundName(name)
+	}
+	parsed, err := url.Parse(u)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// listSounds returns the uploaded sounds by name, or none if there is no
+// sound directory
+func (s *Server) listSounds() ([]Sound, error) {
+	if s.soundDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(s.soundDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sounds []Sound
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !validSoundName(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		sounds = append(sounds, Sound{Name: e.Name(), URL: SoundURL(e.Name()), Size: info.Size()})
+	}
+	return sounds, nil
+}
+
+// handleSound serves an uploaded sound. Uploads never replace a file, so a
+// name only changes meaning if the sound is deleted and uploaded again; an
+// hour's caching keeps the display from fetching it on every reveal.
+func (s *Server) handleSound(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/sounds/")
+	if s.soundDir == "" || !validSoundName(name) {
+		s.notFound(w, r)
+		return
+	}
+	if _, err := os.Stat(filepath.Join(s.soundDir, name)); err != nil {
+		s.notFound(w, r)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeFileFS(w, r, os.DirFS(s.soundDir), name)
+}
+
+// handleAdminSounds stores an uploaded sound under a name made from its
+// file name, numbered past any sound already using it
+func (s *Server) handleAdminSounds(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	if s.soundDir == "" {
+		s.actionError(w, r, http.StatusConflict, "Start the server with --sound-dir to upload sounds")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxSoundBytes+64<<10)
+	file, header, err := r.FormFile("sound")
+	if err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			s.actionError(w, r, http.StatusRequestEntityTooLarge, "Sounds are limited to 5 MB")
+			return
+		}
+		s.actionError(w, r, http.StatusBadRequest, "Choose a sound file to upload")
+		return
+	}
+	defer file.Close()
+
+	ext := strings.ToLower(path.Ext(header.Filename))
+	base := slug.Make(strings.TrimSuffix(path.Base(header.Filename), path.Ext(header.Filename)))
+	if !slices.Contains(soundExts, ext) {
+		s.actionError(w, r, http.StatusBadRequest, "Sounds must be "+strings.Join(soundExts, ", ")+" files")
+		return
+	}
+	if base == "" {
+		base = "sound"
+	}
+
+	// The extension decides the served type, but refuse anything that is
+	// plainly a document rather than audio
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	if strings.HasPrefix(http.DetectContentType(head[:n]), "text/") {
+		s.actionError(w, r, http.StatusBadRequest, "That file doesn't look like audio")
+		return
+	}
+
+	if err := os.MkdirAll(s.soundDir, 0o755); err != nil {
+		s.renderActionError(w, r, "Failed to store sound", err)
+		return
+	}
+	out, name, err := createUnique(s.soundDir, base, ext)
+	if err != nil {
+		s.renderActionError(w, r, "Failed to store sound", err)
+		return
+	}
+	_, err = io.Copy(out, io.MultiReader(bytes.NewReader(head[:n]), file))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filepath.Join(s.soundDir, name))
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			s.actionError(w, r, http.StatusRequestEntityTooLarge, "Sounds are limited to 5 MB")
+			return
+		}
+		s.renderActionError(w, r, "Failed to store sound", err)
+		return
+	}
+
+	s.audit(r, db.AuditSoundUpload, 0, name)
+	http.Redirect(w, r, AdminSettingsURL()+"#sounds", http.StatusSeeOther)
+}
+
+// createUnique creates base+ext in dir, or base-2+ext and so on if taken
+func createUnique(dir, base, ext string) (*os.File, string, error) {
+	for i := 1; i < 1000; i++ {
+		name := base + ext
+		if i > 1 {
+			name = base + "-" + strconv.Itoa(i) + ext
+		}
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		return f, name, err
+	}
+	return nil, "", fmt.Errorf("too many sounds named %s%s", base, ext)
+}
+
+// handleAdminDeleteSound removes an uploaded sound. Polls still pointing at
+// it simply play nothing on reveal.
+func (s *Server) handleAdminDeleteSound(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	name := r.FormValue("name")
+	if s.soundDir == "" || !validSoundName(name) {
+		s.actionError(w, r, http.StatusNotFound, "Sound not found")
+		return
+	}
+	if err := os.Remove(filepath.Join(s.soundDir, name)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			s.actionError(w, r, http.StatusNotFound, "Sound not found")
+			return
+		}
+		log.Printf("Failed to delete sound %s: %v", name, err)
+		s.actionError(w, r, http.StatusInternalServerError, "Failed to delete sound")
+		return
+	}
+
+	s.audit(r, db.AuditSoundDelete, 0, name)
+	http.Redirect(w, r, AdminSettingsURL()+"#sounds", http.StatusSeeOther)
+}
@@ -0,0 +1,119 @@
+package web
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// reportBucketSizes are the bucket widths the station report picks from:
+// the narrowest that fits the event in maxReportBuckets columns
+var reportBucketSizes = []time.Duration{
+	time.Minute, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 6 * time.Hour, 24 * time.Hour,
+}
+
+// maxReportBuckets keeps the station report's rows narrow enough to read
+const maxReportBuckets = 24
+
+// burstBallotsPerMinute is how many ballots one source must cast in a
+// minute to be flagged. Voters at a kiosk take longer than ten seconds each
+// to type a nickname and choose, so a faster source is likely a stuck or
+// ghost-touching screen.
+const burstBallotsPerMinute = 6
+
+// unknownSource names ballots recorded with neither a station nor an
+// address, from before addresses were kept
+const unknownSource = "Unknown"
+
+// handleAdminStationReport shows how many ballots each station, and each
+// address voting from no station, cast over the event, so a kiosk
+// registering ghost votes stands out
+func (s *Server) handleAdminStationReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	rows, err := s.reads.ListBallotOrigins(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load ballots", err)
+		return
+	}
+	data := stationReport(rows)
+	data.Page = Page{Title: "Ballots by station"}
+	s.render(w, "admin/station-report.html", data)
+}
+
+// stationReport groups ballots by station, or by address for those cast at
+// no station, and counts them per bucket and per minute
+func stationReport(rows []db.ListBallotOriginsRow) StationReportData {
+	data := StationReportData{BurstRate: burstBallotsPerMinute}
+	if len(rows) == 0 {
+		return data
+	}
+
+	first, last := rows[0].CreatedAt.Time, rows[len(rows)-1].CreatedAt.Time
+	data.Every = reportBucketSizes[len(reportBucketSizes)-1]
+	for _, every := range reportBucketSizes {
+		if last.Truncate(every).Sub(first.Truncate(every)) < maxReportBuckets*every {
+			data.Every = every
+			break
+		}
+	}
+	start := first.Truncate(data.Every)
+	for t := start; !t.After(last); t = t.Add(data.Every) {
+		data.Buckets = append(data.Buckets, t)
+	}
+
+	type tally struct {
+		source    *BallotSource
+		perMinute map[time.Time]int64
+	}
+	tallies := make(map[any]*tally)
+	for _, row := range rows {
+		var key any = row.Address
+		name := row.Address
+		if row.StationID.Valid {
+			key, name = row.StationID.Int64, row.StationName
+		}
+		if name == "" {
+			name = unknownSource
+		}
+		t := tallies[key]
+		if t == nil {
+			t = &tally{
+				source:    &BallotSource{Name: name, Station: row.StationID.Valid, Counts: make([]int64, len(data.Buckets))},
+				perMinute: make(map[time.Time]int64),
+			}
+			tallies[key] = t
+		}
+
+		src := t.source
+		at := row.CreatedAt.Time
+		src.Total++
+		src.Counts[int(at.Sub(start)/data.Every)]++
+		minute := at.Truncate(time.Minute)
+		t.perMinute[minute]++
+		if n := t.perMinute[minute]; n > src.PeakMinute {
+			src.PeakMinute, src.PeakAt = n, minute
+		}
+		if src.Station && row.Address != "" && !slices.Contains(src.Addresses, row.Address) {
+			src.Addresses = append(src.Addresses, row.Address)
+		}
+	}
+
+	for _, t := range tallies {
+		src := t.source
+		src.Burst = src.PeakMinute >= burstBallotsPerMinute
+		data.Peak = max(data.Peak, slices.Max(src.Counts))
+		data.Sources = append(data.Sources, *src)
+	}
+	// Busiest first
+	slices.SortFunc(data.Sources, func(a, b BallotSource) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), cmp.Compare(a.Name, b.Name))
+	})
+	return data
+}
@@ -0,0 +1,226 @@
+package web
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/qr"
+)
+
+// stationCookie holds a paired kiosk's device token
+const stationCookie = "votigo_station"
+
+// stationCookieAge keeps kiosks paired across reboots for the whole event
+const stationCookieAge = 365 * 24 * time.Hour
+
+// maxStationName keeps station names short enough for reports
+const maxStationName = 60
+
+// Why a ballot from a station is refused. The messages are shown to voters.
+var (
+	errStationUnpaired = errors.New("This kiosk is no longer paired; ask an organizer to pair it again")
+	errStationRevoked  = errors.New("This kiosk has been taken out of service")
+)
+
+// requestOrigin returns where a request came from: its client address and
+// the station whose device token is in the station cookie or, for API
+// clients, an Authorization: Bearer header. The station is 0 for requests
+// from no station. A token that was revoked or replaced by pairing again is
+// an error, so ballots from a kiosk an admin took out of service are
+// refused.
+func (s *Server) requestOrigin(r *http.Request) (db.AuditOrigin, error) {
+	origin := db.AuditOrigin{Address: clientHost(r)}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		c, err := r.Cookie(stationCookie)
+		if err != nil {
+			return origin, nil
+		}
+		token = c.Value
+	}
+
+	st, err := s.queries.StationByToken(r.Context(), token)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return origin, errStationUnpaired
+	case errors.Is(err, db.ErrRevoked):
+		return origin, errStationRevoked
+	case err != nil:
+		log.Printf("Failed to look up station: %v", err)
+		return origin, errors.New("Failed to check this kiosk's pairing")
+	}
+	origin.StationID = st.ID
+	return origin, nil
+}
+
+// handlePair pairs the device that opened a station's pairing link, usually
+// by scanning its QR code on the admin stations page. The link works once;
+// the device keeps a token in a cookie that tags its ballots from then on.
+func (s *Server) handlePair(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	pairingToken := strings.TrimPrefix(r.URL.Path, "/pair/")
+	var st db.Station
+	var token string
+	err := db.InTx(r.Context(), s.db, func(q *db.Queries) error {
+		var err error
+		if st, token, err = q.Pair(r.Context(), pairingToken); err != nil {
+			return err
+		}
+		from := db.AuditOrigin{StationID: st.ID, Address: clientHost(r)}
+		return q.RecordAuditFrom(r.Context(), from, db.ActorStation, db.AuditStationPair, 0, st.Name)
+	})
+	if errors.Is(err, db.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		s.render(w, "error.html", map[string]any{
+			"Message": "This pairing link has already been used or was withdrawn. Ask an organizer for a new one.",
+		})
+		return
+	}
+	if err != nil {
+		s.renderError(w, "Failed to pair this device", err)
+		return
+	}
+
+	log.Printf("Paired station %s", st.Name)
+	http.SetCookie(w, &http.Cookie{
+		Name:     stationCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(stationCookieAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, HomeURL(), http.StatusSeeOther)
+}
+
+// handleAdminStations lists the kiosk stations and adds new ones. A new
+// station's pairing QR code is shown straight away, as its token is not
+// stored and can't be shown again.
+func (s *Server) handleAdminStations(w http.ResponseWriter, r *http.Request) {
+	data := StationsPageData{Page: Page{Title: "Stations"}}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		name := strings.TrimSpace(r.FormValue("name"))
+		switch {
+		case name == "":
+			data.Error = "Please name the station"
+		case len(name) > maxStationName:
+			data.Error = "Please keep station names under " + strconv.Itoa(maxStationName) + " characters"
+		default:
+			st, token, err := s.queries.AddStation(r.Context(), name)
+			if errors.Is(err, db.ErrConflict) {
+				data.Error = "There is already a station called " + name
+				break
+			}
+			if err != nil {
+				s.renderError(w, "Failed to add station", err)
+				return
+			}
+			s.audit(r, db.AuditStationAdd, 0, st.Name)
+			data.Pairing = stationPairing(r, st, token)
+		}
+	default:
+		s.methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		return
+	}
+
+	stations, err := s.queries.ListStations(r.Context())
+	if err != nil {
+		s.renderError(w, "Failed to load stations", err)
+		return
+	}
+	data.Stations = stations
+	if data.Error != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	s.render(w, "admin/stations.html", data)
+}
+
+// handleAdminStation pairs a station again (/admin/stations/{id}/pair),
+// which unpairs its current device, or revokes it
+// (/admin/stations/{id}/revoke) so its ballots are refused
+func (s *Server) handleAdminStation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/admin/stations/")
+	idStr, action, _ := strings.Cut(rest, "/")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		s.notFound(w, r)
+		return
+	}
+
+	switch action {
+	case "pair":
+		st, token, err := s.queries.ResetPairing(r.Context(), id)
+		if err != nil {
+			s.lookupFailed(w, r, "station", err)
+			return
+		}
+		stations, err := s.queries.ListStations(r.Context())
+		if err != nil {
+			s.renderError(w, "Failed to load stations", err)
+			return
+		}
+		s.audit(r, db.AuditStationPair, 0, st.Name+": new pairing link")
+		s.render(w, "admin/stations.html", StationsPageData{
+			Page:     Page{Title: "Stations"},
+			Stations: stations,
+			Pairing:  stationPairing(r, st, token),
+		})
+	case "revoke":
+		st, err := s.queries.Station(r.Context(), id)
+		if err != nil {
+			s.lookupFailed(w, r, "station", err)
+			return
+		}
+		if _, err := s.queries.RevokeStation(r.Context(), id); err != nil {
+			s.renderActionError(w, r, "Failed to revoke station", err)
+			return
+		}
+		s.audit(r, db.AuditStationRevoke, 0, st.Name)
+		http.Redirect(w, r, AdminStationsURL(), http.StatusSeeOther)
+	default:
+		s.notFound(w, r)
+	}
+}
+
+// stationPairing builds the pairing link for a station's token and its QR
+// code, on the host the admin is using
+func stationPairing(r *http.Request, st db.Station, token string) *StationPairing {
+	p := &StationPairing{Station: st, URL: absoluteURL(r, PairURL(token))}
+	p.QR = qrDataURL(p.URL)
+	return p
+}
+
+// qrDataURL draws a QR code of link as a data: URL of a PNG, or returns ""
+// if it can't, in which case pages show the link alone
+func qrDataURL(link string) template.URL {
+	code, err := qr.Encode(link)
+	if err != nil {
+		log.Printf("Failed to encode QR code: %v", err)
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := code.WritePNG(&buf, 6); err != nil {
+		log.Printf("Failed to draw QR code: %v", err)
+		return ""
+	}
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
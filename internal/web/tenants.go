@@ -0,0 +1,84 @@
+package web
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Tenants serves several independent events from one port, picking the
+// Server by the host name a request was sent to. Each Server keeps its own
+// database, admin password and theme; requests for host names no event
+// claims go to the default Server.
+type Tenants struct {
+	def   *Server
+	hosts map[string]*Server
+}
+
+// NewTenants serves def for every host name until Add claims some
+func NewTenants(def *Server) *Tenants {
+	return &Tenants{def: def, hosts: make(map[string]*Server)}
+}
+
+// Add serves s for the given host names, ignoring case and any port
+func (t *Tenants) Add(s *Server, hosts ...string) {
+	for _, host := range hosts {
+		t.hosts[TenantHost(host)] = s
+	}
+}
+
+// TenantHost is the host name Tenants routes host by: lowercased, without
+// any port
+func TenantHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// Servers lists every Server, the default first, each once
+func (t *Tenants) Servers() []*Server {
+	servers := []*Server{t.def}
+	for _, s := range t.hosts {
+		if !slices.Contains(servers, s) {
+			servers = append(servers, s)
+		}
+	}
+	return servers
+}
+
+// Handler routes each request to its event's handler
+func (t *Tenants) Handler() http.Handler {
+	handlers := make(map[*Server]http.Handler)
+	for _, s := range t.Servers() {
+		handlers[s] = s.Handler()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, ok := t.hosts[TenantHost(r.Host)]
+		if !ok {
+			s = t.def
+		}
+		handlers[s].ServeHTTP(w, r)
+	})
+}
+
+// Start serves every event on port until ctx is cancelled, running each
+// one's background jobs, and drains requests like Server.Start. Timeouts
+// and the drain period are the default Server's.
+func (t *Tenants) Start(ctx context.Context, port int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	srv := t.def.HTTPServer(":" + strconv.Itoa(port))
+	srv.Handler = t.Handler()
+	for _, s := range t.Servers() {
+		s.runJobs(ctx)
+		srv.RegisterOnShutdown(s.events.close)
+	}
+	log.Printf("Serving %d events", len(t.Servers()))
+	return t.def.listen(ctx, srv)
+}
@@ -0,0 +1,100 @@
+package web
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxHeaderBytes caps request headers; nothing votigo serves needs more
+const maxHeaderBytes = 32 << 10
+
+// Timeouts bounds how long the server waits on slow clients and slow
+// handlers, so a few stalled phones on the LAN can't tie up a small host
+type Timeouts struct {
+	ReadHeader time.Duration // to receive a request's headers
+	Read       time.Duration // to receive a whole request, body included
+	Write      time.Duration // to send a response
+	Idle       time.Duration // before closing an idle keep-alive connection
+	Handler    time.Duration // deadline on a handler's context
+	Drain      time.Duration // for in-flight requests to finish on shutdown
+}
+
+// DefaultTimeouts suit a LAN party: generous for phones on busy wifi, short
+// enough that stalled connections are dropped quickly
+var DefaultTimeouts = Timeouts{
+	ReadHeader: 5 * time.Second,
+	Read:       15 * time.Second,
+	Write:      20 * time.Second,
+	Idle:       60 * time.Second,
+	Handler:    15 * time.Second,
+	Drain:      10 * time.Second,
+}
+
+// WithTimeouts replaces DefaultTimeouts. Zero fields keep their default.
+func WithTimeouts(t Timeouts) Option {
+	return func(s *Server) {
+		d := DefaultTimeouts
+		s.timeouts = Timeouts{
+			ReadHeader: cmp.Or(t.ReadHeader, d.ReadHeader),
+			Read:       cmp.Or(t.Read, d.Read),
+			Write:      cmp.Or(t.Write, d.Write),
+			Idle:       cmp.Or(t.Idle, d.Idle),
+			Handler:    cmp.Or(t.Handler, d.Handler),
+			Drain:      cmp.Or(t.Drain, d.Drain),
+		}
+		// A handler allowed to run longer than writes may take would have
+		// its response cut off
+		if s.timeouts.Write <= s.timeouts.Handler {
+			s.timeouts.Write = s.timeouts.Handler + 5*time.Second
+		}
+	}
+}
+
+// HTTPServer returns the http.Server Start runs, with the configured
+// timeouts and header limit
+func (s *Server) HTTPServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: s.timeouts.ReadHeader,
+		ReadTimeout:       s.timeouts.Read,
+		WriteTimeout:      s.timeouts.Write,
+		IdleTimeout:       s.timeouts.Idle,
+		MaxHeaderBytes:    maxHeaderBytes,
+	}
+}
+
+// withTimeouts puts a deadline on every request's context, which also
+// cancels its database queries. Long-running routes get theirs from
+// routeTimeout and have the write deadline pushed back to match.
+func (s *Server) withTimeouts(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := s.timeouts.Handler
+		if long, ok := routeTimeout(r); ok {
+			timeout = long + s.timeouts.Handler
+			// Not every ResponseWriter supports deadlines; the server's
+			// WriteTimeout then still applies
+			http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + s.timeouts.Write))
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// routeTimeout reports how long a request may legitimately take beyond the
+// usual handler timeout: a results long-poll waits for up to maxResultsWait
+// and an event stream stays open for eventStreamMax
+func routeTimeout(r *http.Request) (time.Duration, bool) {
+	if r.URL.Path == PathEvents {
+		return eventStreamMax, true
+	}
+	if strings.HasPrefix(r.URL.Path, apiPrefix+"/results/") && r.URL.Query().Has("wait") {
+		return maxResultsWait, true
+	}
+	return 0, false
+}
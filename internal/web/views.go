@@ -0,0 +1,581 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"html/template"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/palm-arcade/votigo/internal/db"
+	"github.com/palm-arcade/votigo/internal/tally"
+)
+
+// View models are the data the busiest pages render. A template that
+// names a field its view model lacks fails to execute, and render turns
+// that into a 500, so a renamed field breaks tests instead of quietly
+// rendering blanks the way a missing map key does.
+
+// Page holds what the layout reads from every page
+type Page struct {
+	Title  string
+	Viewer *Viewer      // voter pages only; see Server.viewer
+	Skin   template.CSS // the poll's skin and custom CSS; see pageSkin
+}
+
+// Viewer is set on a voter page when an organizer is looking at it. Admin
+// shows the admin bar, with a link to EditURL for the poll on the page;
+// VoterView hides it behind the view-as-voter banner instead. Path is the
+// page itself, to come back to after toggling.
+type Viewer struct {
+	Admin     bool
+	VoterView bool
+	EditURL   string
+	Path      string
+}
+
+// HomePageData renders home.html. Landing is only loaded when no poll is
+// open, in place of an empty list.
+type HomePageData struct {
+	Page
+	Categories []db.Category
+	Landing    *LandingData
+}
+
+// LandingData is the home page's schedule of polls still to come and the
+// latest winners. Next is the soonest planned opening that hasn't passed.
+type LandingData struct {
+	Schedule []ScheduledPoll
+	Next     *ScheduledPoll
+	Winners  []PollWinner
+}
+
+// ScheduledPoll is a draft poll on the landing page. OpensAfter is the poll
+// it waits on, if any.
+type ScheduledPoll struct {
+	Category   db.Category
+	OpensAfter *db.Category
+}
+
+// PollWinner is a recently closed poll and the options tied for its top spot
+type PollWinner struct {
+	Category db.Category
+	Names    []string
+}
+
+// VotePageData renders vote.html, widget.html and the vote-form partial.
+// Success replaces the form once a ballot is recorded, with Held saying
+// why it doesn't count as cast, if it doesn't (see receiptFor); Message
+// replaces it in a widget whose poll isn't open.
+type VotePageData struct {
+	Page
+	Category       db.Category
+	Options        []db.Option
+	Ranks          []int
+	MaxRank        int64
+	Nickname       string
+	Error          string
+	Success        string
+	Held           string
+	Receipt        string
+	Message        string
+	IdempotencyKey string
+	Widget         bool
+	Preview        bool // read-only, for an admin checking the layout
+	Wall           *VotedWall
+}
+
+// OptionPageData renders option.html and, in the modern UI, the ballot's
+// more-info modal: an option's blurb split into Paragraphs, its
+// screenshots and its video, with Embed the player for it if it has one
+// and Preview its cached title and thumbnail (empty Link if none)
+type OptionPageData struct {
+	Page
+	Category   db.Category
+	Option     db.Option
+	Paragraphs []string
+	Embed      string
+	Preview    db.MediaPreview
+}
+
+// OptionDetailsPageData renders admin/option.html. Blurb, Video and
+// Screenshots (one per line) refill the form after an Error. Preview is
+// the saved video's cached title and thumbnail, fetched only when
+// Previews is set.
+type OptionDetailsPageData struct {
+	Page
+	Category    db.Category
+	Option      db.Option
+	Blurb       string
+	Video       string
+	Screenshots string
+	Preview     db.MediaPreview
+	Previews    bool
+	Error       string
+}
+
+// VotedWall lists who has voted beside a ballot, newest first, for polls
+// that show one. It never says how anyone voted.
+type VotedWall struct {
+	Avatars bool // show avatars only, not nicknames
+	Voters  []WallVoter
+	More    int // voters past the ones listed
+}
+
+// WallVoter is one voter on the wall. Nickname is empty on avatar walls.
+type WallVoter struct {
+	Nickname string
+	Avatar   string
+}
+
+// ResultsPageData renders results.html and the results-table partial.
+// NotVisible hides the tally of a poll that only shows results once closed.
+// Version is the results version the tally is for, -1 if unknown; Poll
+// marks an answer to a ?since= poll, sent as out-of-band swaps, and Partial
+// one that swaps only the Changed places. Margin is what a finished poll was
+// won by, for polls counted by votes.
+type ResultsPageData struct {
+	Page
+	Category   db.Category
+	NotVisible bool
+	VoteCount  int64
+	Results    []ResultRow
+	Margin     *db.Margin
+	Version    int64
+	Poll       bool
+	Partial    bool
+	Changed    []ResultPlace
+	RunoffOf   *db.Category
+	Runoff     *db.Category
+}
+
+// Places lists every row of the results table
+func (d ResultsPageData) Places() []ResultPlace {
+	places := make([]ResultPlace, len(d.Results))
+	for i, row := range d.Results {
+		places[i] = ResultPlace{Place: i, Ranked: d.Category.VoteType == "ranked", Judged: d.Category.Judged(), ResultRow: row}
+	}
+	return places
+}
+
+// ResultPlace is a row of the results table on its own, counting places
+// from 0. Swap sends it as an out-of-band swap of the row already shown.
+type ResultPlace struct {
+	Place  int
+	Ranked bool
+	Judged bool
+	Swap   bool
+	ResultRow
+}
+
+// ResultRow is one option's standing. Rank is its place, shared with the
+// options it is Tied with. Ranked polls fill in Points and FirstPlace,
+// other types Votes; Percentage is the share of all votes, or of the most
+// points possible. Judged polls fill in Points with the combined points
+// out of 100, and Jury, Audience and Total with each side's score and the
+// combined one in words.
+type ResultRow struct {
+	Name       string
+	Image      string
+	Rank       int
+	Tied       bool
+	Votes      int64
+	Points     int64
+	FirstPlace int64
+	Percentage int64
+	Jury       string
+	Audience   string
+	Total      string
+}
+
+// AdminDashboardData renders admin/dashboard.html. Warnings are the polls
+// and settings Lint finds misconfigured.
+type AdminDashboardData struct {
+	Page
+	Categories   []db.Category
+	Stats        map[int64]PollStats // by category ID
+	Warnings     []db.Warning
+	Activity     ActivityData
+	HighContrast bool
+}
+
+// PollStats sums up a poll's ballots for its dashboard row
+type PollStats struct {
+	Votes       int64
+	RemoteVotes int64
+	LastVoteAt  sql.NullTime
+}
+
+// SearchResultsData renders the command palette's results. NewPoll offers
+// the new-poll command when the query could mean it.
+type SearchResultsData struct {
+	Query   string
+	Polls   []db.Category
+	NewPoll bool
+}
+
+// CeremonyData renders admin/ceremony.html. Current is the poll on the
+// display, if any; Displays is how many pages are listening for events, so
+// the console shows whether the projector is.
+type CeremonyData struct {
+	Page
+	Polls      []CeremonyPoll
+	Display    DisplayState
+	Current    *CeremonyPoll
+	Displays   int
+	CeremonyAt time.Time // zero while no countdown is set
+}
+
+// CeremonyPoll is a closed poll on the ceremony console. Margin is what
+// the winner won by, for polls counted by votes.
+type CeremonyPoll struct {
+	Category db.Category
+	Votes    int64
+	Winner   string
+	Margin   *db.Margin
+}
+
+// DisplayPageData renders display.html, the projector page. Category is nil
+// on the waiting screen.
+type DisplayPageData struct {
+	Page
+	Category  *db.Category
+	VoteCount int64
+	Results   []ResultRow
+	Revealed  bool
+}
+
+// LinkSheetData renders admin/links.html, the printable sheet of short
+// links for polls that are open or about to be
+type LinkSheetData struct {
+	Page
+	Links []ShortLink
+}
+
+// ShortLink is one poll on the link sheet. URL is absolute, built from the
+// host the sheet was requested on.
+type ShortLink struct {
+	Category db.Category
+	Code     string
+	URL      string
+}
+
+// StationsPageData renders admin/stations.html. Pairing is set just after a
+// station is added or paired again, the only time its token can be shown.
+type StationsPageData struct {
+	Page
+	Stations []db.Station
+	Pairing  *StationPairing
+	Error    string
+}
+
+// StationPairing is a station's one-time pairing link and its QR code, a
+// data: URL of a PNG, for a kiosk to open or scan
+type StationPairing struct {
+	Station db.Station
+	URL     string
+	QR      template.URL
+}
+
+// StationReportData renders admin/station-report.html: ballots per source
+// in time buckets of Every, starting at each of Buckets. Peak is the fullest
+// bucket of any source, which the bars are scaled against.
+type StationReportData struct {
+	Page
+	Every     time.Duration
+	Buckets   []time.Time
+	Sources   []BallotSource
+	Peak      int64
+	BurstRate int64
+}
+
+// Interval describes Every for the report's axis, such as "15 minutes"
+func (d StationReportData) Interval() string {
+	n, unit := int64(d.Every/time.Minute), "minute"
+	if d.Every >= time.Hour {
+		n, unit = int64(d.Every/time.Hour), "hour"
+	}
+	if n == 1 {
+		return "1 " + unit
+	}
+	return strconv.FormatInt(n, 10) + " " + unit + "s"
+}
+
+// BallotSource is one station's ballots, or those from one address outside
+// any station. Counts has one entry per report bucket. A source is Burst
+// when its busiest minute, PeakMinute at PeakAt, reached the report's
+// BurstRate, which no queue of voters at one device manages.
+type BallotSource struct {
+	Name       string
+	Station    bool
+	Addresses  []string // where a station's ballots came from
+	Total      int64
+	Counts     []int64
+	PeakMinute int64
+	PeakAt     time.Time
+	Burst      bool
+}
+
+// ActivityData renders the dashboard's activity sidebar and its partial.
+// PeakVotes is the busiest minute, which the bars are scaled against.
+type ActivityData struct {
+	VotesPerMinute []db.ListVotesPerMinuteRow
+	PeakVotes      int64
+	Actions        []db.ListRecentAdminActionsRow
+}
+
+// HasDetails reports whether any option on the ballot has details to show
+func (d VotePageData) HasDetails() bool {
+	return slices.ContainsFunc(d.Options, db.Option.HasDetails)
+}
+
+// newVotePage starts the data for a category's ballot
+func newVotePage(cat db.Category, options []db.Option, widget bool) VotePageData {
+	maxRank := maxRankFor(cat)
+	var ranks []int
+	if cat.VoteType == "ranked" {
+		ranks = make([]int, maxRank)
+	}
+	return VotePageData{
+		Page:     Page{Title: cat.Name, Skin: pageSkin(cat.Skin, cat.CustomCss)},
+		Category: cat,
+		Options:  options,
+		Ranks:    ranks,
+		MaxRank:  maxRank,
+		Widget:   widget,
+	}
+}
+
+// tallyResults counts a category's votes and ranks its options
+func (s *Server) tallyResults(ctx context.Context, cat db.Category) (int64, []ResultRow, error) {
+	total, err := s.reads.CountVotesByCategory(ctx, cat.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rows, err := s.reads.Tally(ctx, cat)
+	if err != nil {
+		return 0, nil, err
+	}
+	var scores map[int64]db.JudgeScore
+	if cat.Judged() {
+		if scores, err = s.reads.JudgeScores(ctx, cat); err != nil {
+			return 0, nil, err
+		}
+	}
+	places := db.Places(rows, total)
+	results := make([]ResultRow, len(rows))
+	for i, row := range rows {
+		results[i] = ResultRow{Name: row.Name, Image: row.Image, Rank: places[i].Rank, Tied: places[i].Tied}
+		if cat.Judged() {
+			// Combined scores are tenths of a point out of 100
+			results[i].Points = (row.Score + 5) / 10
+			results[i].FirstPlace = row.FirstPlace
+			results[i].Percentage = results[i].Points
+			results[i].Jury = scores[row.ID].Jury.Label
+			results[i].Audience = scores[row.ID].Audience.Label
+			results[i].Total = row.Label
+		} else if cat.VoteType == "ranked" {
+			results[i].Points = row.Score
+			results[i].FirstPlace = row.FirstPlace
+			results[i].Percentage = tally.Share(row.Score, total*maxRankFor(cat))
+		} else {
+			results[i].Votes = row.Score
+			results[i].Percentage = tally.Share(row.Score, total)
+		}
+	}
+	return total, results, nil
+}
+
+// winningMargin is what the leader of a poll counted by votes leads by, nil
+// for other polls, ties and polls nobody voted in
+func (s *Server) winningMargin(cat db.Category, votes int64, results []ResultRow) *db.Margin {
+	if !cat.CountedByVotes() || len(results) == 0 {
+		return nil
+	}
+	var second int64
+	if len(results) > 1 {
+		second = results[1].Votes
+	}
+	narrow, _ := strconv.ParseInt(s.setting(db.SettingNarrowMargin), 10, 64)
+	m, ok := db.NewMargin(results[0].Votes, second, votes, narrow)
+	if !ok {
+		return nil
+	}
+	return &m
+}
+
+// SeatmapPageData renders admin/seatmap.html: the roster's seats row by
+// row, each marked by whether its attendee has voted in Poll, one of the
+// Open polls. Poll is nil when none is open. Unseated are attendees the
+// roster gives no seat.
+type SeatmapPageData struct {
+	Page
+	Open     []db.Category
+	Poll     *db.Category
+	Rows     []SeatRow
+	Unseated []Seat
+	Voted    int64
+	Total    int64
+}
+
+// SeatRow is a row or table of seats, in seat number order
+type SeatRow struct {
+	Name  string
+	Seats []Seat
+}
+
+// Seat is an attendee's seat on the seat map
+type Seat struct {
+	Label    string
+	Nickname string
+	Voted    bool
+}
+
+// RosterPageData renders admin/roster.html: everyone on the roster with
+// their tags, and how many attendees have each tag in use
+type RosterPageData struct {
+	Page
+	Attendees []RosterAttendee
+	Tags      []TagCount
+}
+
+// RosterAttendee is an attendee on the roster page
+type RosterAttendee struct {
+	ID       int64
+	Nickname string
+	Seat     string
+	Tags     []string
+}
+
+// TagCount is a roster tag and how many attendees have it
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// GroupsPageData renders admin/groups.html: a poll's turnout by roster tag
+// and, with Tallies, each group's winner beside the poll's own, to see
+// whether crew and attendees disagree
+type GroupsPageData struct {
+	Page
+	Category  db.Category
+	Groups    []GroupRow
+	VoteCount int64
+	OffRoster int64             // ballots from voters not on the roster
+	Tallies   bool              // whether each group's ballots were counted
+	Winner    *db.TalliedOption // the poll's leader, when Tallies and anyone voted
+	Purged    bool              // the ballots are gone, so there is nothing to break down
+}
+
+// WindowPageData renders admin/window.html: a poll's result split at a
+// time the admin picks. Windows holds the ballots cast before At, those
+// cast from At on, and all of them, once At is chosen; First and Last are
+// when the poll's first and last ballots were cast, for picking it.
+type WindowPageData struct {
+	Page
+	Category db.Category
+	Input    string // the time as entered, or the poll's planned closing time
+	At       time.Time
+	Error    string
+	First    time.Time
+	Last     time.Time
+	Windows  []WindowResult
+	Purged   bool // the ballots are gone, so there is nothing to split
+}
+
+// WindowResult is a poll counted from the ballots cast in one window
+type WindowResult struct {
+	Label   string
+	Votes   int64
+	Tally   []db.TalliedOption
+	Differs bool // its leader isn't the leader of all the ballots
+}
+
+// BallotsPageData renders admin/ballots.html: every ballot in a poll with
+// where it came from, and a form to invalidate some. From, To, Addresses,
+// Nicknames and Reason refill the form after an Error.
+type BallotsPageData struct {
+	Page
+	Category  db.Category
+	Ballots   []BallotRow
+	Invalid   int
+	From      string
+	To        string
+	Addresses string
+	Nicknames string
+	Reason    string
+	Error     string
+	Purged    bool // the ballots are gone, so there is nothing to invalidate
+}
+
+// BallotRow is one ballot on the ballots page, its nickname revealed
+type BallotRow struct {
+	db.ListVoteOriginsRow
+	Voter string
+}
+
+// GroupRow is one roster tag on the groups page
+type GroupRow struct {
+	db.GroupResult
+	Winner  *db.TalliedOption // the option the group put first, when tallied
+	Differs bool              // the group's winner isn't the poll's
+}
+
+// QuickPageData renders admin/quick.html, the controls for an organizer's
+// phone: every poll that isn't archived, open ones first, then drafts, then
+// closed
+type QuickPageData struct {
+	Page
+	Polls []QuickPoll
+}
+
+// QuickPoll is a poll on the quick controls with its ballots so far
+type QuickPoll struct {
+	Category db.Category
+	Stats    PollStats
+}
+
+// ObservePageData renders observe.html, the read-only page an observer
+// link opens: every poll past draft with its standings and turnout by
+// roster tag, whether or not voters can see its results yet. URL is the
+// page itself, for refreshing.
+type ObservePageData struct {
+	Page
+	Observer db.Observer
+	URL      string
+	Polls    []ObservedPoll
+}
+
+// ObservedPoll is a poll on the observer page
+type ObservedPoll struct {
+	Category db.Category
+	Votes    int64
+	Results  []PlacedOption
+	Groups   []db.GroupResult
+}
+
+// PlacedOption is a tallied option with the place it shares with any it
+// ties with
+type PlacedOption struct {
+	db.TalliedOption
+	tally.Place
+}
+
+// ObserversPageData renders admin/observers.html. Link is set just after
+// one is made; Label and Hours refill the form after an Error.
+type ObserversPageData struct {
+	Page
+	Link  *ObserverLink
+	Label string
+	Hours int
+	Error string
+}
+
+// ObserverLink is a new observer link and its QR code, a data: URL of a PNG
+type ObserverLink struct {
+	db.Observer
+	URL string
+	QR  template.URL
+}
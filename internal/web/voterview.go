@@ -0,0 +1,65 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// voterViewCookie marks an organizer's browser as viewing the site as a
+// voter. It only hides the admin bar, so there's nothing to protect.
+const voterViewCookie = "votigo_voter_view"
+
+// authorized reports whether r carries the admin credentials
+func (s *Server) authorized(r *http.Request) bool {
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != "admin" {
+		return false
+	}
+	if s.adminHash != nil {
+		return s.adminHash.matches(pass)
+	}
+	return pass == s.adminPassword
+}
+
+// viewer returns who is looking at a voter page, for the layout's admin bar
+// or view-as-voter banner. It is nil for attendees, who see neither. cat is
+// the poll on the page, if there is one.
+func (s *Server) viewer(r *http.Request, cat *db.Category) *Viewer {
+	_, err := r.Cookie(voterViewCookie)
+	v := &Viewer{Admin: s.authorized(r), VoterView: err == nil, Path: r.URL.RequestURI()}
+	if !v.Admin && !v.VoterView {
+		return nil
+	}
+	if cat != nil {
+		v.EditURL = AdminCategoryURL(cat.ID)
+	}
+	return v
+}
+
+// handleAdminVoterView turns view-as-voter on or off for this browser, then
+// returns to the page given in next: the voter page to check, or the one the
+// organizer was on
+func (s *Server) handleAdminVoterView(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	on := r.FormValue("enabled") == "on"
+	cookie := &http.Cookie{
+		Name:     voterViewCookie,
+		Value:    "1",
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	next := HomeURL()
+	if !on {
+		cookie.Value, cookie.MaxAge = "", -1
+		next = AdminURL()
+	}
+	http.SetCookie(w, cookie)
+
+	http.Redirect(w, r, localNext(r, next), http.StatusSeeOther)
+}
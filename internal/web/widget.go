@@ -0,0 +1,35 @@
+package web
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/palm-arcade/votigo/internal/db"
+)
+
+// allowFraming lets the sites in the widget_frame_ancestors setting show
+// the response in an iframe
+func (s *Server) allowFraming(w http.ResponseWriter) {
+	w.Header().Set("Content-Security-Policy", "frame-ancestors "+frameAncestors(s.setting(db.SettingWidgetFrameAncestors)))
+}
+
+// frameAncestors turns the space-separated setting into a CSP source list.
+// Sources that could end the directive early, or that are quoted keywords
+// other than 'self' and 'none', are dropped; nothing left means 'none'.
+func frameAncestors(value string) string {
+	var sources []string
+	for _, src := range strings.Fields(value) {
+		switch {
+		case src == "'self'" || src == "'none'":
+		case strings.ContainsAny(src, `;,'"`) || strings.IndexFunc(src, unicode.IsControl) >= 0:
+			continue
+		}
+		sources = append(sources, src)
+	}
+	if len(sources) == 0 || slices.Contains(sources, "'none'") {
+		return "'none'"
+	}
+	return strings.Join(sources, " ")
+}
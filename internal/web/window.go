@@ -0,0 +1,94 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// handleAdminWindow splits a poll's result at a chosen time
+// (/admin/category/{id}/window?at=), counting the ballots cast before it and
+// those cast after it apart, beside the result from all of them. It shows
+// what a poll left open overnight would have looked like had it closed on
+// time. Without ?at= it suggests the poll's planned closing time.
+func (s *Server) handleAdminWindow(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodGet {
+		s.methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+	cat, err := s.queries.Category(r.Context(), id)
+	if err != nil {
+		s.lookupFailed(w, r, "category", err)
+		return
+	}
+	data := WindowPageData{
+		Page:     Page{Title: cat.Name + " by time"},
+		Category: cat,
+		Input:    strings.TrimSpace(r.URL.Query().Get("at")),
+	}
+	if data.Input == "" && cat.ClosesAt.Valid {
+		data.Input = cat.ClosesAt.Time.Local().Format(closesAtLayout)
+	}
+
+	if data.Purged, err = s.reads.Purged(r.Context(), cat.ID); err != nil {
+		s.renderError(w, "Failed to load the poll", err)
+		return
+	}
+	if data.Purged {
+		s.render(w, "admin/window.html", data)
+		return
+	}
+	votes, err := s.reads.ListVotesByCategory(r.Context(), cat.ID)
+	if err != nil {
+		s.renderError(w, "Failed to load ballots", err)
+		return
+	}
+	for _, v := range votes {
+		if !v.CreatedAt.Valid {
+			continue
+		}
+		if data.First.IsZero() || v.CreatedAt.Time.Before(data.First) {
+			data.First = v.CreatedAt.Time
+		}
+		if v.CreatedAt.Time.After(data.Last) {
+			data.Last = v.CreatedAt.Time
+		}
+	}
+
+	if r.URL.Query().Get("at") == "" {
+		s.render(w, "admin/window.html", data)
+		return
+	}
+	data.At, err = parseLocalTime(data.Input, time.Local)
+	if err != nil {
+		data.Error = "Enter a date and time like 2026-10-17 02:00"
+		w.WriteHeader(http.StatusBadRequest)
+		s.render(w, "admin/window.html", data)
+		return
+	}
+
+	label := data.At.Format("Jan 2 15:04")
+	windows := []struct {
+		label    string
+		from, to time.Time
+	}{
+		{"Before " + label, time.Time{}, data.At},
+		{"From " + label, data.At, time.Time{}},
+		{"All ballots", time.Time{}, time.Time{}},
+	}
+	for _, win := range windows {
+		tallied, n, err := s.reads.TallyBetween(r.Context(), cat, win.from, win.to)
+		if err != nil {
+			s.renderError(w, "Failed to tally results", err)
+			return
+		}
+		data.Windows = append(data.Windows, WindowResult{Label: win.label, Votes: n, Tally: tallied})
+	}
+	if all := data.Windows[len(data.Windows)-1]; all.Votes > 0 {
+		for i := range data.Windows[:len(data.Windows)-1] {
+			win := &data.Windows[i]
+			win.Differs = win.Votes > 0 && win.Tally[0].ID != all.Tally[0].ID
+		}
+	}
+	s.render(w, "admin/window.html", data)
+}
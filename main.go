@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/alecthomas/kong"
 	"github.com/palm-arcade/votigo/cmd"
 )
@@ -8,12 +10,15 @@ import (
 func main() {
 	var cli cmd.CLI
 	cmdCtx := &cmd.Context{}
-	ctx := kong.Parse(&cli,
+	parser := kong.Must(&cli,
 		kong.Name("votigo"),
 		kong.Description("Voting app for Palms Arcade Retro LAN"),
 		kong.UsageOnError(),
 		kong.Bind(cmdCtx),
 	)
-	err := ctx.Run(cmdCtx)
-	ctx.FatalIfErrorf(err)
+	ctx, err := parser.Parse(os.Args[1:])
+	if err == nil {
+		err = ctx.Run(cmdCtx)
+	}
+	parser.FatalIfErrorf(cmd.CommandError(err))
 }
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE audit_events (
+  id          INTEGER PRIMARY KEY,
+  actor       TEXT NOT NULL,
+  action      TEXT NOT NULL,
+  category_id INTEGER,
+  detail      TEXT NOT NULL DEFAULT '',
+  created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_audit_events_created ON audit_events(created_at);
+
+-- +goose Down
+DROP INDEX idx_audit_events_created;
+DROP TABLE audit_events;
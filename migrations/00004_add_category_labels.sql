@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE categories ADD COLUMN color TEXT NOT NULL DEFAULT '';
+ALTER TABLE categories ADD COLUMN icon TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN icon;
+ALTER TABLE categories DROP COLUMN color;
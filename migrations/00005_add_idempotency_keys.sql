@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE idempotency_keys (
+  key         TEXT PRIMARY KEY,
+  category_id INTEGER NOT NULL,
+  nickname    TEXT NOT NULL,
+  created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_idempotency_keys_created ON idempotency_keys(created_at);
+
+-- +goose Down
+DROP INDEX idx_idempotency_keys_created;
+DROP TABLE idempotency_keys;
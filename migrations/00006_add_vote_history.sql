@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE votes ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE vote_selection_history (
+  id          INTEGER PRIMARY KEY,
+  vote_id     INTEGER NOT NULL,
+  version     INTEGER NOT NULL,
+  option_id   INTEGER NOT NULL,
+  rank        INTEGER,
+  replaced_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (vote_id) REFERENCES votes(id) ON DELETE CASCADE,
+  FOREIGN KEY (option_id) REFERENCES options(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_vote_selection_history_vote ON vote_selection_history(vote_id);
+
+-- +goose Down
+DROP INDEX idx_vote_selection_history_vote;
+DROP TABLE vote_selection_history;
+ALTER TABLE votes DROP COLUMN version;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE encryption_meta (
+  id          INTEGER PRIMARY KEY CHECK (id = 1),
+  salt        BLOB NOT NULL,
+  check_value TEXT NOT NULL,
+  created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE encryption_meta;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE options ADD COLUMN retired_at DATETIME;
+
+-- +goose Down
+ALTER TABLE options DROP COLUMN retired_at;
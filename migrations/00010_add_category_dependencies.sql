@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE categories ADD COLUMN depends_on INTEGER REFERENCES categories(id) ON DELETE SET NULL;
+ALTER TABLE categories ADD COLUMN seed_top_n INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN seed_top_n;
+ALTER TABLE categories DROP COLUMN depends_on;
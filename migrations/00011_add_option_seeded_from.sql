@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE options ADD COLUMN seeded_from INTEGER REFERENCES options(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE options DROP COLUMN seeded_from;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE categories ADD COLUMN runoff_of INTEGER REFERENCES categories(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN runoff_of;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE categories ADD COLUMN closes_at DATETIME;
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN closes_at;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE sessions (
+  id         TEXT PRIMARY KEY,
+  data       TEXT NOT NULL DEFAULT '{}',
+  expires_at DATETIME NOT NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sessions_expires ON sessions(expires_at);
+
+-- +goose Down
+DROP INDEX idx_sessions_expires;
+DROP TABLE sessions;
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE categories ADD COLUMN slug TEXT;
+
+-- Give existing polls the slug their name would get where that is simple
+-- enough to work out in SQL; the rest get one the next time they are edited
+WITH candidates AS (
+  SELECT id, trim(lower(replace(replace(replace(trim(name), ' ', '-'), '''', ''), '.', '')), '-') AS slug
+  FROM categories
+)
+UPDATE categories SET slug = candidates.slug
+FROM candidates
+WHERE candidates.id = categories.id
+  AND candidates.slug GLOB '*[a-z]*'
+  AND candidates.slug NOT GLOB '*[^a-z0-9-]*'
+  AND instr(candidates.slug, '--') = 0;
+
+-- Polls whose names clash keep only their ID until renamed
+UPDATE categories SET slug = NULL
+WHERE slug IS NOT NULL
+  AND id NOT IN (SELECT MIN(id) FROM categories WHERE slug IS NOT NULL GROUP BY slug);
+
+CREATE UNIQUE INDEX idx_categories_slug ON categories(slug);
+
+-- +goose Down
+DROP INDEX idx_categories_slug;
+ALTER TABLE categories DROP COLUMN slug;
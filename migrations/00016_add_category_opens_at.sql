@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE categories ADD COLUMN opens_at DATETIME;
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN opens_at;
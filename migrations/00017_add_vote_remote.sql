@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE votes ADD COLUMN remote BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE votes DROP COLUMN remote;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE categories ADD COLUMN skin TEXT NOT NULL DEFAULT '';
+ALTER TABLE categories ADD COLUMN custom_css TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN custom_css;
+ALTER TABLE categories DROP COLUMN skin;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE categories ADD COLUMN reveal_sound TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN reveal_sound;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE options ADD COLUMN image TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE options DROP COLUMN image;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE avatar_salt (
+  id   INTEGER PRIMARY KEY CHECK (id = 1),
+  salt BLOB NOT NULL
+);
+INSERT INTO avatar_salt (id, salt) VALUES (1, randomblob(16));
+
+-- +goose Down
+DROP TABLE avatar_salt;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE categories ADD COLUMN voted_wall TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN voted_wall;
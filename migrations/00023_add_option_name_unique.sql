@@ -0,0 +1,47 @@
+-- +goose Up
+-- Options in a poll whose names differ only in case are merged into one,
+-- preferring an option still on the ballot, then the oldest. Votes for the
+-- others move to it; a voter who picked more than one keeps their best rank.
+CREATE TEMP TABLE option_merges AS
+SELECT id, first_value(id) OVER (
+         PARTITION BY category_id, name COLLATE NOCASE
+         ORDER BY retired_at IS NOT NULL, id
+       ) AS keep
+FROM options;
+DELETE FROM option_merges WHERE id = keep;
+
+UPDATE vote_selections
+SET option_id = (SELECT m.keep FROM option_merges m WHERE m.id = vote_selections.option_id)
+WHERE option_id IN (SELECT id FROM option_merges);
+DELETE FROM vote_selections WHERE id IN (
+  SELECT id FROM (
+    SELECT id, row_number() OVER (
+             PARTITION BY vote_id, option_id ORDER BY rank IS NULL, rank, id
+           ) AS n
+    FROM vote_selections
+  ) WHERE n > 1
+);
+
+UPDATE vote_selection_history
+SET option_id = (SELECT m.keep FROM option_merges m WHERE m.id = vote_selection_history.option_id)
+WHERE option_id IN (SELECT id FROM option_merges);
+DELETE FROM vote_selection_history WHERE id IN (
+  SELECT id FROM (
+    SELECT id, row_number() OVER (
+             PARTITION BY vote_id, version, option_id ORDER BY rank IS NULL, rank, id
+           ) AS n
+    FROM vote_selection_history
+  ) WHERE n > 1
+);
+
+UPDATE options
+SET seeded_from = (SELECT m.keep FROM option_merges m WHERE m.id = options.seeded_from)
+WHERE seeded_from IN (SELECT id FROM option_merges);
+
+DELETE FROM options WHERE id IN (SELECT id FROM option_merges);
+DROP TABLE option_merges;
+
+CREATE UNIQUE INDEX idx_options_category_name ON options(category_id, name COLLATE NOCASE);
+
+-- +goose Down
+DROP INDEX idx_options_category_name;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Foreign keys were only enforced on the first pooled connection, so rows
+-- can point at polls, votes or options that are gone. Do here what the
+-- cascades would have done: clear optional references and delete rows that
+-- can't stand without their parent.
+UPDATE categories SET depends_on = NULL
+WHERE depends_on IS NOT NULL AND depends_on NOT IN (SELECT id FROM categories);
+UPDATE categories SET runoff_of = NULL
+WHERE runoff_of IS NOT NULL AND runoff_of NOT IN (SELECT id FROM categories);
+UPDATE audit_events SET category_id = NULL
+WHERE category_id IS NOT NULL AND category_id NOT IN (SELECT id FROM categories);
+
+DELETE FROM options WHERE category_id NOT IN (SELECT id FROM categories);
+DELETE FROM votes WHERE category_id NOT IN (SELECT id FROM categories);
+DELETE FROM idempotency_keys WHERE category_id NOT IN (SELECT id FROM categories);
+
+UPDATE options SET seeded_from = NULL
+WHERE seeded_from IS NOT NULL AND seeded_from NOT IN (SELECT id FROM options);
+
+DELETE FROM vote_selections
+WHERE vote_id NOT IN (SELECT id FROM votes) OR option_id NOT IN (SELECT id FROM options);
+DELETE FROM vote_selection_history
+WHERE vote_id NOT IN (SELECT id FROM votes) OR option_id NOT IN (SELECT id FROM options);
+
+-- +goose Down
+-- Deleted orphans can't be restored
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE admin_credentials (
+  id         INTEGER PRIMARY KEY CHECK (id = 1),
+  salt       BLOB NOT NULL,
+  hash       BLOB NOT NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE admin_credentials;
@@ -0,0 +1,88 @@
+-- +goose Up
+-- results_version goes up whenever a poll's tally could change, so results
+-- pages polling with ?since= can be told nothing did. Triggers catch every
+-- writer: the server, the CLI and other tools.
+ALTER TABLE categories ADD COLUMN results_version INTEGER NOT NULL DEFAULT 0;
+
+-- +goose StatementBegin
+CREATE TRIGGER votes_insert_results_version AFTER INSERT ON votes
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.category_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER votes_update_results_version AFTER UPDATE ON votes
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.category_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER votes_delete_results_version AFTER DELETE ON votes
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = OLD.category_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER vote_selections_insert_results_version AFTER INSERT ON vote_selections
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = (SELECT category_id FROM votes WHERE id = NEW.vote_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER vote_selections_update_results_version AFTER UPDATE ON vote_selections
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = (SELECT category_id FROM votes WHERE id = NEW.vote_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER vote_selections_delete_results_version AFTER DELETE ON vote_selections
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = (SELECT category_id FROM votes WHERE id = OLD.vote_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER options_insert_results_version AFTER INSERT ON options
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.category_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER options_update_results_version AFTER UPDATE ON options
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.category_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER options_delete_results_version AFTER DELETE ON options
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = OLD.category_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER categories_update_results_version AFTER UPDATE OF vote_type, max_rank ON categories
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER votes_insert_results_version;
+DROP TRIGGER votes_update_results_version;
+DROP TRIGGER votes_delete_results_version;
+DROP TRIGGER vote_selections_insert_results_version;
+DROP TRIGGER vote_selections_update_results_version;
+DROP TRIGGER vote_selections_delete_results_version;
+DROP TRIGGER options_insert_results_version;
+DROP TRIGGER options_update_results_version;
+DROP TRIGGER options_delete_results_version;
+DROP TRIGGER categories_update_results_version;
+ALTER TABLE categories DROP COLUMN results_version;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Stations are kiosks paired with the server. A new station has a one-time
+-- pairing token for its QR code; pairing swaps it for the device token the
+-- kiosk keeps. Only SHA-256 hashes of the tokens are stored. Stations are
+-- revoked rather than deleted, so audit events keep naming them.
+CREATE TABLE stations (
+  id           INTEGER PRIMARY KEY,
+  name         TEXT NOT NULL UNIQUE COLLATE NOCASE,
+  pairing_hash BLOB UNIQUE,
+  device_hash  BLOB UNIQUE,
+  created_at   DATETIME DEFAULT CURRENT_TIMESTAMP,
+  paired_at    DATETIME,
+  revoked_at   DATETIME
+);
+
+ALTER TABLE audit_events ADD COLUMN station_id INTEGER;
+
+-- +goose Down
+ALTER TABLE audit_events DROP COLUMN station_id;
+DROP TABLE stations;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- The client address a ballot came from, for the per-station ballot report.
+-- Events from before it, and those not made over HTTP, have none.
+ALTER TABLE audit_events ADD COLUMN address TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE audit_events DROP COLUMN address;
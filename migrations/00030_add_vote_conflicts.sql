@@ -0,0 +1,22 @@
+-- +goose Up
+-- A conflict is a re-vote that replaced, within seconds, a ballot for the
+-- same nickname cast from another station or address. The later ballot is
+-- version of the vote; the earlier one is version - 1 in
+-- vote_selection_history. Both stand until an admin keeps one ('first' or
+-- 'second').
+CREATE TABLE vote_conflicts (
+  id                INTEGER PRIMARY KEY,
+  vote_id           INTEGER NOT NULL,
+  version           INTEGER NOT NULL,
+  first_station_id  INTEGER,
+  first_address     TEXT NOT NULL DEFAULT '',
+  second_station_id INTEGER,
+  second_address    TEXT NOT NULL DEFAULT '',
+  created_at        DATETIME DEFAULT CURRENT_TIMESTAMP,
+  resolved_at       DATETIME,
+  kept              TEXT NOT NULL DEFAULT '',
+  FOREIGN KEY (vote_id) REFERENCES votes(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE vote_conflicts;
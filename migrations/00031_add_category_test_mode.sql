@@ -0,0 +1,7 @@
+-- +goose Up
+-- Test mode lets admins vote on a draft poll to try its ballot. Those
+-- ballots are deleted when the poll opens.
+ALTER TABLE categories ADD COLUMN test_mode BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN test_mode;
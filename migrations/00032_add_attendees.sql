@@ -0,0 +1,13 @@
+-- +goose Up
+-- The roster of attendees, synced from the LAN registration system, maps
+-- nicknames (stored like votes.nickname, so sealed when the database is
+-- encrypted) to seats for turnout views. It is replaced on every sync.
+CREATE TABLE attendees (
+  id        INTEGER PRIMARY KEY,
+  nickname  TEXT NOT NULL UNIQUE,
+  seat      TEXT NOT NULL DEFAULT '',
+  synced_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE attendees;
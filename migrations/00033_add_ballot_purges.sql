@@ -0,0 +1,26 @@
+-- +goose Up
+-- The retention policy deletes a finished poll's ballots, keeping its
+-- results: ballot_purges records how many there were, and result_snapshots
+-- the tally they made, in order.
+CREATE TABLE ballot_purges (
+  category_id INTEGER PRIMARY KEY,
+  votes       INTEGER NOT NULL,
+  purged_at   DATETIME DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE TABLE result_snapshots (
+  category_id INTEGER NOT NULL,
+  option_id   INTEGER NOT NULL,
+  position    INTEGER NOT NULL,
+  score       INTEGER NOT NULL,
+  first_place INTEGER NOT NULL,
+  label       TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (category_id, option_id),
+  FOREIGN KEY (category_id) REFERENCES ballot_purges(category_id) ON DELETE CASCADE,
+  FOREIGN KEY (option_id) REFERENCES options(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE result_snapshots;
+DROP TABLE ballot_purges;
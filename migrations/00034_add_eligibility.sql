@@ -0,0 +1,16 @@
+-- +goose Up
+-- A poll's eligibility limits who may vote in it by the tags admins give
+-- attendees on the roster, e.g. "participant !crew" (see db.Eligibility).
+-- Tags belong to the attendee, so they survive roster syncs.
+ALTER TABLE categories ADD COLUMN eligibility TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE attendee_tags (
+  attendee_id INTEGER NOT NULL,
+  tag         TEXT NOT NULL,
+  PRIMARY KEY (attendee_id, tag),
+  FOREIGN KEY (attendee_id) REFERENCES attendees(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE attendee_tags;
+ALTER TABLE categories DROP COLUMN eligibility;
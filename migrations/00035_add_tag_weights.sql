@@ -0,0 +1,42 @@
+-- +goose Up
+-- A poll's weights count the ballots of voters with some roster tags more
+-- than once, e.g. "jury=3" (see db.TagWeights). Changing them, or the tags
+-- of any attendee, can change a weighted poll's result.
+ALTER TABLE categories ADD COLUMN weights TEXT NOT NULL DEFAULT '';
+
+DROP TRIGGER categories_update_results_version;
+
+-- +goose StatementBegin
+CREATE TRIGGER categories_update_results_version AFTER UPDATE OF vote_type, max_rank, weights ON categories
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER attendee_tags_insert_results_version AFTER INSERT ON attendee_tags
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE weights != '';
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER attendee_tags_delete_results_version AFTER DELETE ON attendee_tags
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE weights != '';
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER attendee_tags_insert_results_version;
+DROP TRIGGER attendee_tags_delete_results_version;
+DROP TRIGGER categories_update_results_version;
+
+-- +goose StatementBegin
+CREATE TRIGGER categories_update_results_version AFTER UPDATE OF vote_type, max_rank ON categories
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+ALTER TABLE categories DROP COLUMN weights;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- A poll's method counts its ballots by one of tally.Methods, built in or
+-- added by a plugin, instead of votes or points by vote type. Changing it
+-- changes the result.
+ALTER TABLE categories ADD COLUMN method TEXT NOT NULL DEFAULT '';
+
+DROP TRIGGER categories_update_results_version;
+
+-- +goose StatementBegin
+CREATE TRIGGER categories_update_results_version AFTER UPDATE OF vote_type, max_rank, weights, method ON categories
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER categories_update_results_version;
+
+-- +goose StatementBegin
+CREATE TRIGGER categories_update_results_version AFTER UPDATE OF vote_type, max_rank, weights ON categories
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+ALTER TABLE categories DROP COLUMN method;
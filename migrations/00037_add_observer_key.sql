@@ -0,0 +1,11 @@
+-- +goose Up
+-- Observer links are signed with this key, so they need no table of their
+-- own; replacing it withdraws every link handed out.
+CREATE TABLE observer_key (
+  id  INTEGER PRIMARY KEY CHECK (id = 1),
+  key BLOB NOT NULL
+);
+INSERT INTO observer_key (id, key) VALUES (1, randomblob(32));
+
+-- +goose Down
+DROP TABLE observer_key;
@@ -0,0 +1,61 @@
+-- +goose Up
+-- A judged poll names the roster tag of its judges, whose ballots are
+-- counted apart from everyone else's and count for judge_weight percent of
+-- the result (see tally.Combine). Changing either, or the tags of any
+-- attendee, can change a judged poll's result.
+ALTER TABLE categories ADD COLUMN judges TEXT NOT NULL DEFAULT '';
+ALTER TABLE categories ADD COLUMN judge_weight INTEGER NOT NULL DEFAULT 50;
+
+DROP TRIGGER categories_update_results_version;
+DROP TRIGGER attendee_tags_insert_results_version;
+DROP TRIGGER attendee_tags_delete_results_version;
+
+-- +goose StatementBegin
+CREATE TRIGGER categories_update_results_version AFTER UPDATE OF vote_type, max_rank, weights, method, judges, judge_weight ON categories
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER attendee_tags_insert_results_version AFTER INSERT ON attendee_tags
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE weights != '' OR judges != '';
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER attendee_tags_delete_results_version AFTER DELETE ON attendee_tags
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE weights != '' OR judges != '';
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER categories_update_results_version;
+DROP TRIGGER attendee_tags_insert_results_version;
+DROP TRIGGER attendee_tags_delete_results_version;
+
+-- +goose StatementBegin
+CREATE TRIGGER categories_update_results_version AFTER UPDATE OF vote_type, max_rank, weights, method ON categories
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER attendee_tags_insert_results_version AFTER INSERT ON attendee_tags
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE weights != '';
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER attendee_tags_delete_results_version AFTER DELETE ON attendee_tags
+BEGIN
+  UPDATE categories SET results_version = results_version + 1 WHERE weights != '';
+END;
+-- +goose StatementEnd
+
+ALTER TABLE categories DROP COLUMN judge_weight;
+ALTER TABLE categories DROP COLUMN judges;
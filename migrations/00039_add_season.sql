@@ -0,0 +1,24 @@
+-- +goose Up
+-- A season runs events one after another in the same database. Finalizing
+-- an event records where every option placed in its finished polls and the
+-- points that earned; rows keep the poll and option names so the season
+-- survives the polls being deleted.
+CREATE TABLE season_events (
+  id           INTEGER PRIMARY KEY,
+  name         TEXT NOT NULL,
+  finalized_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE season_points (
+  event_id    INTEGER NOT NULL REFERENCES season_events(id) ON DELETE CASCADE,
+  category_id INTEGER NOT NULL,
+  poll        TEXT NOT NULL,
+  place       INTEGER NOT NULL,
+  name        TEXT NOT NULL,
+  points      INTEGER NOT NULL
+);
+CREATE INDEX idx_season_points_category ON season_points(category_id);
+
+-- +goose Down
+DROP TABLE season_points;
+DROP TABLE season_events;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Why an admin invalidated a ballot, or empty while it counts. Invalid
+-- ballots are kept, and restored by clearing the reason, but tallies and
+-- vote counts leave them out.
+ALTER TABLE votes ADD COLUMN invalid TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE votes DROP COLUMN invalid;
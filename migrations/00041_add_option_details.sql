@@ -0,0 +1,11 @@
+-- +goose Up
+-- More about an option for voters deciding: a blurb, a video link and
+-- screenshot URLs, one per line, shown from the ballot
+ALTER TABLE options ADD COLUMN blurb TEXT NOT NULL DEFAULT '';
+ALTER TABLE options ADD COLUMN video TEXT NOT NULL DEFAULT '';
+ALTER TABLE options ADD COLUMN screenshots TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE options DROP COLUMN screenshots;
+ALTER TABLE options DROP COLUMN video;
+ALTER TABLE options DROP COLUMN blurb;
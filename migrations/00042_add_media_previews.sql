@@ -0,0 +1,16 @@
+-- +goose Up
+-- Titles and thumbnails of options' video links, fetched from the video
+-- site's oEmbed endpoint when the link is saved, so ballots show them
+-- without going out to the internet. Keyed by the site's own link to the
+-- video, so options sharing a video share its preview.
+CREATE TABLE media_previews (
+  link       TEXT PRIMARY KEY,
+  provider   TEXT NOT NULL,
+  title      TEXT NOT NULL DEFAULT '',
+  author     TEXT NOT NULL DEFAULT '',
+  thumbnail  TEXT NOT NULL DEFAULT '',
+  fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE media_previews;
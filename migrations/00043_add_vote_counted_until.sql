@@ -0,0 +1,9 @@
+-- +goose Up
+-- When an admin's official window closed, for a ballot cast inside it whose
+-- voter changed it after: the ballot counts as it stood then, rebuilt from
+-- vote_selection_history (db.InvalidateBallots). NULL counts the current
+-- ballot; restoring the ballot clears it.
+ALTER TABLE votes ADD COLUMN counted_until DATETIME;
+
+-- +goose Down
+ALTER TABLE votes DROP COLUMN counted_until;
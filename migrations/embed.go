@@ -1,3 +1,20 @@
+// Package migrations holds the database schema history, applied in order by
+// goose.
+//
+// Schema changes are SQL files. Data migrations that SQL can't express, like
+// filling in slugs with the rules the app uses, are Go files named the same
+// way (00025_backfill_category_slugs.go) that register their functions with
+// goose.AddMigrationContext in init. Both kinds run in order, each in its
+// own transaction, so a failed backfill leaves the database at the version
+// before it.
+//
+// Go migrations mustn't use internal/db: it imports this package, and its
+// queries follow the latest schema rather than the one at the migration's
+// version. Write SQL against the tables as they were, and keep any logic
+// worth sharing with the app in a package both can import (internal/slug).
+// Read the rows to change before writing, and write only rows that still
+// need it, so a backfill is safe to run on a database partly filled by a
+// newer version of the app.
 package migrations
 
 import "embed"
@@ -0,0 +1,8 @@
+package main
+
+// Plugins register ballot validators, counting methods, notifiers and option
+// sources from init, so importing one here builds it in. See
+// internal/plugin.
+import (
+// _ "github.com/palm-arcade/votigo/internal/plugin/example"
+)
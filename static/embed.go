@@ -2,5 +2,5 @@ package static
 
 import "embed"
 
-//go:embed css/*.css js/*.js fonts/*.woff2
+//go:embed css/*.css js/*.js fonts/*.woff2 icons/*.svg manifest.json sw.js
 var FS embed.FS
@@ -0,0 +1,43 @@
+// Organizer announcements on voter and results pages. The dashboard's
+// broadcast box publishes a "broadcast" event on /events; the banner shows
+// its message for the seconds it has left, or until the voter dismisses it.
+// Every stream starts with the announcement still up, so pages opened
+// afterwards show it too.
+(function () {
+  'use strict';
+
+  var banner = document.getElementById('broadcast');
+  if (!banner || !window.EventSource) {
+    return;
+  }
+  var text = banner.querySelector('[data-broadcast-message]');
+  var timer = null;
+  // The message the voter closed, so reconnecting doesn't bring it back
+  var dismissed = null;
+
+  function hide() {
+    banner.hidden = true;
+    clearTimeout(timer);
+  }
+
+  function show(announcement) {
+    hide();
+    if (!announcement.message || announcement.seconds <= 0 || announcement.message === dismissed) {
+      return;
+    }
+    text.textContent = announcement.message;
+    banner.hidden = false;
+    timer = setTimeout(hide, announcement.seconds * 1000);
+  }
+
+  banner.querySelector('[data-broadcast-dismiss]').addEventListener('click', function () {
+    dismissed = text.textContent;
+    hide();
+  });
+
+  // One stream per page, shared with display.js and celebrate.js
+  var source = window.votigoEvents || (window.votigoEvents = new EventSource('/events'));
+  source.addEventListener('broadcast', function (msg) {
+    show(JSON.parse(msg.data));
+  });
+})();
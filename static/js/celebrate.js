@@ -0,0 +1,123 @@
+// Celebrations on the projector display. The ceremony console publishes a
+// "celebrate" event on /events; the results page showing that poll (or every
+// display, for category 0) fires confetti, plays a short fanfare and shows
+// the winner. Browsers only allow sound after someone has clicked or pressed
+// a key on the page, so click the display once when setting it up.
+(function () {
+  'use strict';
+
+  var display = document.querySelector('[data-display]');
+  if (!display || !window.EventSource) {
+    return;
+  }
+  var categoryID = Number(display.getAttribute('data-display'));
+
+  var COLORS = ['#39ff14', '#ffb000', '#ff3860', '#3ea6ff', '#c86bff', '#ff6bcb', '#00e5ff'];
+  var DURATION = 5000;
+  var BANNER_LIFETIME = 8000;
+
+  var reducedMotion = window.matchMedia && window.matchMedia('(prefers-reduced-motion: reduce)').matches;
+
+  var audio = null;
+  function unlockAudio() {
+    var Ctx = window.AudioContext || window.webkitAudioContext;
+    if (!audio && Ctx) {
+      audio = new Ctx();
+    }
+    if (audio && audio.state === 'suspended') {
+      audio.resume();
+    }
+  }
+  document.addEventListener('click', unlockAudio);
+  document.addEventListener('keydown', unlockAudio);
+
+  // A rising arpeggio ending on a held chord
+  function fanfare() {
+    if (!audio || audio.state !== 'running') {
+      return;
+    }
+    var notes = [[523.25, 0, 0.15], [659.25, 0.15, 0.15], [783.99, 0.3, 0.15], [1046.5, 0.45, 0.8], [783.99, 0.45, 0.8], [659.25, 0.45, 0.8]];
+    var start = audio.currentTime + 0.05;
+    notes.forEach(function (n) {
+      var osc = audio.createOscillator();
+      var gain = audio.createGain();
+      osc.type = 'square';
+      osc.frequency.value = n[0];
+      gain.gain.setValueAtTime(0.08, start + n[1]);
+      gain.gain.exponentialRampToValueAtTime(0.001, start + n[1] + n[2]);
+      osc.connect(gain).connect(audio.destination);
+      osc.start(start + n[1]);
+      osc.stop(start + n[1] + n[2]);
+    });
+  }
+
+  function confetti() {
+    if (reducedMotion) {
+      return;
+    }
+    var canvas = document.createElement('canvas');
+    canvas.setAttribute('aria-hidden', 'true');
+    canvas.style.cssText = 'position:fixed;inset:0;width:100%;height:100%;pointer-events:none;z-index:70';
+    canvas.width = window.innerWidth;
+    canvas.height = window.innerHeight;
+    document.body.appendChild(canvas);
+    var ctx = canvas.getContext('2d');
+
+    var pieces = [];
+    for (var i = 0; i < 200; i++) {
+      pieces.push({
+        x: Math.random() * canvas.width,
+        y: -20 - Math.random() * canvas.height / 2,
+        vx: (Math.random() - 0.5) * 4,
+        vy: 2 + Math.random() * 4,
+        size: 6 + Math.random() * 6,
+        spin: Math.random() * Math.PI,
+        color: COLORS[i % COLORS.length],
+      });
+    }
+
+    var began = performance.now();
+    function frame(now) {
+      ctx.clearRect(0, 0, canvas.width, canvas.height);
+      pieces.forEach(function (p) {
+        p.x += p.vx;
+        p.y += p.vy;
+        p.vy += 0.05;
+        p.spin += 0.1;
+        ctx.fillStyle = p.color;
+        ctx.fillRect(p.x, p.y, p.size, p.size * Math.abs(Math.cos(p.spin)));
+      });
+      if (now - began < DURATION) {
+        requestAnimationFrame(frame);
+      } else {
+        canvas.remove();
+      }
+    }
+    requestAnimationFrame(frame);
+  }
+
+  function announce(ev) {
+    var banner = document.getElementById('celebration');
+    if (!banner || !ev.winner) {
+      return;
+    }
+    banner.textContent = '🏆 ' + ev.winner;
+    banner.hidden = false;
+    clearTimeout(banner.hideTimer);
+    banner.hideTimer = setTimeout(function () {
+      banner.hidden = true;
+    }, BANNER_LIFETIME);
+  }
+
+  // One stream per page, shared with display.js
+  var source = window.votigoEvents || (window.votigoEvents = new EventSource('/events'));
+  source.addEventListener('celebrate', function (msg) {
+    var ev = JSON.parse(msg.data);
+    if (ev.category_id && ev.category_id !== categoryID) {
+      return;
+    }
+    confetti();
+    fanfare();
+    announce(ev);
+  });
+})();
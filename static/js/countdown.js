@@ -0,0 +1,60 @@
+// Countdown to the ceremony in the page header and on the display. The
+// server renders the seconds left into #countdown; this keeps the clock
+// ticking and hides it at zero. Pages that already listen on /events
+// (voter pages and the display) also follow the ceremony console as it
+// starts or stops the countdown.
+(function () {
+  'use strict';
+
+  var el = document.getElementById('countdown');
+  if (!el) {
+    return;
+  }
+  var clock = el.querySelector('[data-countdown-clock]');
+  var ends = 0;
+  var timer = null;
+
+  function pad(n) {
+    return n < 10 ? '0' + n : String(n);
+  }
+
+  // Matches CountdownEvent.Clock: 1:05:09 or 4:07
+  function format(seconds) {
+    var h = Math.floor(seconds / 3600);
+    var m = Math.floor(seconds % 3600 / 60);
+    var s = seconds % 60;
+    return (h > 0 ? h + ':' + pad(m) : String(m)) + ':' + pad(s);
+  }
+
+  function tick() {
+    var left = Math.max(0, Math.round((ends - Date.now()) / 1000));
+    clock.textContent = format(left);
+    if (left === 0) {
+      clearInterval(timer);
+      el.hidden = true;
+    }
+  }
+
+  function start(seconds) {
+    clearInterval(timer);
+    el.hidden = !(seconds > 0);
+    if (el.hidden) {
+      return;
+    }
+    ends = Date.now() + seconds * 1000;
+    tick();
+    timer = setInterval(tick, 1000);
+  }
+
+  start(Number(el.dataset.countdown));
+
+  // Deferred scripts have all run by now, so a page's shared stream is open
+  document.addEventListener('DOMContentLoaded', function () {
+    if (!window.votigoEvents) {
+      return;
+    }
+    window.votigoEvents.addEventListener('countdown', function (msg) {
+      start(JSON.parse(msg.data).seconds);
+    });
+  });
+})();
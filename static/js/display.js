@@ -0,0 +1,57 @@
+// Projector display (/display), driven from the ceremony console over
+// /events. Showing another poll reloads the page, which the server renders
+// for the new state; revealing uncovers the results from last place up and
+// plays the poll's reveal sound, preloaded with the page. Every stream
+// starts with the current state, so a display that lost its connection
+// catches up when it reconnects.
+(function () {
+  'use strict';
+
+  var display = document.querySelector('[data-display]');
+  if (!display || !window.EventSource) {
+    return;
+  }
+  var categoryID = Number(display.getAttribute('data-display'));
+  var revealed = display.getAttribute('data-revealed') === 'true';
+
+  var STEP = 1500;
+
+  // Browsers only allow sound once someone has clicked the page
+  function playCue() {
+    var cue = document.getElementById('reveal-sound');
+    if (cue) {
+      cue.currentTime = 0;
+      cue.play().catch(function (err) {
+        console.warn('votigo: reveal sound blocked', err);
+      });
+    }
+  }
+
+  function reveal() {
+    playCue();
+    var teaser = document.getElementById('display-teaser');
+    var rows = Array.prototype.slice.call(display.querySelectorAll('[data-place]')).reverse();
+    rows.forEach(function (row, i) {
+      setTimeout(function () {
+        row.hidden = false;
+        if (i === rows.length - 1 && teaser) {
+          teaser.remove();
+        }
+      }, i * STEP);
+    });
+  }
+
+  // One stream per page, shared with celebrate.js
+  var source = window.votigoEvents || (window.votigoEvents = new EventSource('/events'));
+  source.addEventListener('display', function (msg) {
+    var state = JSON.parse(msg.data);
+    if (state.category_id !== categoryID || (revealed && !state.revealed)) {
+      window.location.reload();
+      return;
+    }
+    if (state.revealed && !revealed) {
+      revealed = true;
+      reveal();
+    }
+  });
+})();
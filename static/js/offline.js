@@ -0,0 +1,162 @@
+// Offline ballot queue for the modern UI. When a vote can't reach the server
+// the ballot is kept in localStorage and replayed through the JSON API once
+// the connection returns.
+(function () {
+  'use strict';
+
+  var KEY = 'votigo.pendingBallots';
+
+  if ('serviceWorker' in navigator) {
+    window.addEventListener('load', function () {
+      navigator.serviceWorker.register('/sw.js').catch(function (err) {
+        console.warn('votigo: service worker registration failed', err);
+      });
+    });
+  }
+
+  function load() {
+    try {
+      return JSON.parse(localStorage.getItem(KEY)) || [];
+    } catch (e) {
+      return [];
+    }
+  }
+
+  function save(queue) {
+    localStorage.setItem(KEY, JSON.stringify(queue));
+    showStatus(queue.length ? queue.length + ' ballot(s) waiting for the network' : '');
+  }
+
+  function showStatus(text) {
+    var el = document.getElementById('offline-status');
+    if (!el) return;
+    el.textContent = text;
+    el.hidden = text === '';
+  }
+
+  // ballotFromForm encodes a vote form the way the JSON API expects:
+  // ranked forms become an ordered list with 0 for a skipped rank.
+  function ballotFromForm(form) {
+    var data = new FormData(form);
+    var choices = [];
+    var ranking = data.getAll('ranking');
+    if (ranking.length) {
+      // Keyboard-orderable list: every option in order, only the top count
+      ranking.slice(0, Number(form.dataset.maxRank) || ranking.length).forEach(function (v) {
+        choices.push(Number(v));
+      });
+    } else if (form.dataset.voteType === 'ranked') {
+      for (var i = 1; data.has('rank' + i); i++) {
+        choices.push(Number(data.get('rank' + i)) || 0);
+      }
+    } else {
+      data.getAll('choice').forEach(function (v) { choices.push(Number(v)); });
+    }
+    return {
+      categoryId: Number(form.dataset.categoryId),
+      nickname: String(data.get('nickname') || '').trim(),
+      choices: choices,
+      key: String(data.get('idempotency_key') || '')
+    };
+  }
+
+  // enqueue keeps only the latest ballot per voter and category, matching
+  // the server, where a re-vote replaces the earlier one.
+  function enqueue(ballot) {
+    var queue = load().filter(function (b) {
+      return !(b.categoryId === ballot.categoryId &&
+        b.nickname.toLowerCase() === ballot.nickname.toLowerCase());
+    });
+    queue.push(ballot);
+    save(queue);
+  }
+
+  function showQueued(form, ballot) {
+    var target = document.getElementById('vote-form');
+    if (!target) return;
+    var box = document.createElement('div');
+    box.className = 'text-center py-8 space-y-4';
+    box.setAttribute('role', 'status');
+    var title = document.createElement('h2');
+    title.className = 'font-arcade text-lg text-arcade-amber glow-amber';
+    title.textContent = 'VOTE SAVED OFFLINE';
+    var msg = document.createElement('p');
+    msg.className = 'text-neutral-400';
+    msg.textContent = 'No connection right now, ' + ballot.nickname +
+      '. Your vote will be sent automatically when the network is back.';
+    box.appendChild(title);
+    box.appendChild(msg);
+    target.replaceChildren(box);
+  }
+
+  var syncing = false;
+
+  function sync() {
+    if (syncing || !navigator.onLine) return;
+    var queue = load();
+    if (!queue.length) return;
+    syncing = true;
+
+    var ballot = queue[0];
+    var headers = { 'Content-Type': 'application/json' };
+    // The form's key lets the server ignore a ballot that did arrive before
+    // the connection dropped
+    if (ballot.key) headers['Idempotency-Key'] = ballot.key;
+
+    fetch('/api/v1/categories/' + ballot.categoryId + '/votes', {
+      method: 'POST',
+      headers: headers,
+      body: JSON.stringify({ nickname: ballot.nickname, choices: ballot.choices })
+    }).then(function (res) {
+      // A 4xx means the server will never accept this ballot (e.g. the
+      // category closed while we were offline), so drop it rather than retry.
+      if (res.ok || (res.status >= 400 && res.status < 500)) {
+        if (!res.ok) {
+          res.json().then(function (body) {
+            console.warn('votigo: queued ballot rejected:', body.error);
+          }).catch(function () {});
+        }
+        save(load().slice(1));
+        syncing = false;
+        sync();
+        return;
+      }
+      syncing = false;
+    }).catch(function () {
+      syncing = false;
+    });
+  }
+
+  function isVoteForm(elt) {
+    return elt && elt.matches && elt.matches('form[data-category-id]');
+  }
+
+  function queueFromEvent(evt) {
+    var form = evt.detail.elt;
+    if (!isVoteForm(form)) return false;
+    var ballot = ballotFromForm(form);
+    if (!ballot.nickname) return false;
+    enqueue(ballot);
+    showQueued(form, ballot);
+    return true;
+  }
+
+  // Skip the round trip entirely when the browser already knows it's offline
+  document.addEventListener('htmx:beforeRequest', function (evt) {
+    if (!navigator.onLine && queueFromEvent(evt)) {
+      evt.preventDefault();
+    }
+  });
+
+  // The request failed at the network level (no HTTP response at all)
+  document.addEventListener('htmx:sendError', queueFromEvent);
+
+  window.addEventListener('online', sync);
+  document.addEventListener('visibilitychange', function () {
+    if (document.visibilityState === 'visible') sync();
+  });
+  document.addEventListener('DOMContentLoaded', function () {
+    save(load());
+    sync();
+  });
+})();
@@ -0,0 +1,103 @@
+// Keyboard-first admin dashboard. "/" or Ctrl+K opens the command palette,
+// which searches polls through /admin/search. Inside a palette result or a
+// dashboard row, O opens (or reopens) that poll, C closes it and A archives
+// it; N starts a new poll from anywhere. Shortcut targets are marked with
+// aria-keyshortcuts in the templates, so screen readers announce them too.
+(function () {
+  'use strict';
+
+  var palette, input, results;
+
+  function typing(el) {
+    return el && (el.tagName === 'INPUT' || el.tagName === 'TEXTAREA' ||
+      el.tagName === 'SELECT' || el.isContentEditable);
+  }
+
+  function openPalette() {
+    if (!palette.open) {
+      palette.showModal();
+    }
+    input.select();
+    htmx.trigger(input, 'search');
+  }
+
+  function items() {
+    return Array.prototype.slice.call(results.querySelectorAll('[data-shortcuts]'));
+  }
+
+  // move focuses the next or previous palette result, wrapping around
+  function move(step) {
+    var list = items();
+    if (!list.length) {
+      return;
+    }
+    var current = list.indexOf(document.activeElement.closest('[data-shortcuts]'));
+    if (current === -1) {
+      current = step > 0 ? -1 : 0;
+    }
+    list[(current + step + list.length) % list.length].focus();
+  }
+
+  // shortcut clicks the element bound to key in the focused result or row,
+  // falling back to the page-wide shortcuts
+  function shortcut(key) {
+    var selector = '[aria-keyshortcuts~="' + key + '"]';
+    var scope = document.activeElement && document.activeElement.closest('[data-shortcuts]');
+    var target = (scope && scope.querySelector(selector)) ||
+      document.querySelector('[data-global-shortcut]' + selector);
+    if (!target) {
+      return false;
+    }
+    target.click();
+    return true;
+  }
+
+  document.addEventListener('keydown', function (evt) {
+    if (!palette) {
+      return;
+    }
+    if ((evt.key === 'k' && (evt.ctrlKey || evt.metaKey)) || (evt.key === '/' && !typing(evt.target))) {
+      evt.preventDefault();
+      openPalette();
+      return;
+    }
+    if (palette.open && (evt.key === 'ArrowDown' || evt.key === 'ArrowUp')) {
+      evt.preventDefault();
+      move(evt.key === 'ArrowDown' ? 1 : -1);
+      return;
+    }
+    if (evt.key === 'Enter' && palette.open) {
+      var item = evt.target === input ? items()[0] : evt.target.closest('[data-shortcuts]');
+      var link = item && item.querySelector('a');
+      if (link && (evt.target === input || evt.target === item)) {
+        evt.preventDefault();
+        link.click();
+      }
+      return;
+    }
+    if (evt.ctrlKey || evt.metaKey || evt.altKey || typing(evt.target)) {
+      return;
+    }
+    if (shortcut(evt.key.toLowerCase())) {
+      evt.preventDefault();
+    }
+  });
+
+  // An action run from the palette changes the poll's status; search again
+  // so the result shows its new actions
+  document.addEventListener('htmx:afterRequest', function (evt) {
+    if (palette && palette.open && results.contains(evt.detail.elt) && evt.detail.elt !== input) {
+      htmx.trigger(input, 'search');
+    }
+  });
+
+  document.addEventListener('DOMContentLoaded', function () {
+    palette = document.getElementById('command-palette');
+    if (!palette) {
+      return;
+    }
+    input = document.getElementById('palette-input');
+    results = document.getElementById('palette-results');
+    document.getElementById('palette-open').addEventListener('click', openPalette);
+  });
+})();
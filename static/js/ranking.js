@@ -0,0 +1,74 @@
+// Keyboard-orderable ranking for ranked vote forms. Without JS the form falls
+// back to one <select> per rank; with it, voters reorder a list and the form
+// posts every option ID in preference order as repeated "ranking" fields.
+(function () {
+  'use strict';
+
+  function enhance(root) {
+    root.querySelectorAll('[data-ranking]').forEach(function (box) {
+      if (box.dataset.enhanced) return;
+      box.dataset.enhanced = 'true';
+
+      var form = box.closest('form');
+      var selects = form.querySelector('[data-ranking-selects]');
+      if (selects) {
+        selects.hidden = true;
+        selects.querySelectorAll('select').forEach(function (s) { s.disabled = true; });
+      }
+      box.querySelectorAll('input[name="ranking"]').forEach(function (i) { i.disabled = false; });
+      box.hidden = false;
+
+      var list = box.querySelector('ol');
+      var status = box.querySelector('[data-ranking-status]');
+      var maxRank = Number(form.dataset.maxRank) || list.children.length;
+
+      function refresh() {
+        Array.prototype.forEach.call(list.children, function (li, i) {
+          li.querySelector('[data-position]').textContent = '#' + (i + 1);
+          li.dataset.counted = String(i < maxRank);
+          li.setAttribute('aria-label', li.dataset.name + ', position ' + (i + 1) +
+            ' of ' + list.children.length + (i < maxRank ? '' : ', not counted'));
+        });
+      }
+
+      function move(li, delta) {
+        var items = Array.prototype.slice.call(list.children);
+        var to = items.indexOf(li) + delta;
+        if (to < 0 || to >= items.length) return;
+        if (delta < 0) {
+          list.insertBefore(li, items[to]);
+        } else {
+          list.insertBefore(li, items[to].nextSibling);
+        }
+        refresh();
+        li.focus();
+        status.textContent = li.dataset.name + ' moved to position ' + (to + 1);
+      }
+
+      list.addEventListener('click', function (e) {
+        var btn = e.target.closest('[data-move]');
+        if (btn) move(btn.closest('li'), Number(btn.dataset.move));
+      });
+
+      list.addEventListener('keydown', function (e) {
+        var li = e.target.closest('li');
+        if (!li || e.target !== li) return;
+        if (e.key === 'ArrowUp' || e.key === 'ArrowDown') {
+          e.preventDefault();
+          if (e.altKey) {
+            move(li, e.key === 'ArrowUp' ? -1 : 1);
+          } else {
+            var next = e.key === 'ArrowUp' ? li.previousElementSibling : li.nextElementSibling;
+            if (next) next.focus();
+          }
+        }
+      });
+
+      refresh();
+    });
+  }
+
+  document.addEventListener('DOMContentLoaded', function () { enhance(document); });
+  // Re-enhance after htmx swaps the vote form (e.g. to show a validation error)
+  document.addEventListener('htmx:load', function (e) { enhance(e.target); });
+})();
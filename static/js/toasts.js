@@ -0,0 +1,56 @@
+// Toasts for the modern UI. Handlers raise a "toast" event through the
+// HX-Trigger header after an htmx action succeeds; failed actions answer
+// with an error status and an HX-Retarget header pointing at the toast area,
+// which htmx would otherwise refuse to swap. Toasts fade out on their own.
+(function () {
+  'use strict';
+
+  var LIFETIME = 6000;
+
+  // Mirrors templates/modern/partials/toast.html
+  var CLASSES = {
+    success: 'toast toast-success',
+    error: 'toast toast-error',
+    info: 'toast toast-info',
+  };
+
+  function expire(toast) {
+    toast.setAttribute('data-expires', '');
+    setTimeout(function () {
+      toast.remove();
+    }, LIFETIME);
+  }
+
+  function show(kind, message) {
+    var area = document.getElementById('toasts');
+    if (!area || !message) {
+      return;
+    }
+    var toast = document.createElement('div');
+    toast.className = CLASSES[kind] || CLASSES.info;
+    toast.setAttribute('role', kind === 'error' ? 'alert' : 'status');
+    toast.textContent = message;
+    area.appendChild(toast);
+    expire(toast);
+  }
+
+  document.addEventListener('toast', function (evt) {
+    show(evt.detail.kind, evt.detail.message);
+  });
+
+  document.addEventListener('htmx:beforeSwap', function (evt) {
+    var xhr = evt.detail.xhr;
+    if (xhr.status >= 400 && xhr.getResponseHeader('HX-Retarget')) {
+      evt.detail.shouldSwap = true;
+      evt.detail.isError = false;
+    }
+  });
+
+  document.addEventListener('htmx:afterSwap', function (evt) {
+    if (evt.detail.target.id !== 'toasts') {
+      return;
+    }
+    var toasts = evt.detail.target.querySelectorAll('.toast:not([data-expires])');
+    Array.prototype.forEach.call(toasts, expire);
+  });
+})();
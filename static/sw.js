@@ -0,0 +1,67 @@
+// Votigo service worker: keeps the voter pages available when the LAN drops.
+// Ballots cast while offline are queued by /static/js/offline.js, not here.
+
+const CACHE = 'votigo-v4';
+
+const SHELL = [
+  '/',
+  '/static/css/styles.css',
+  '/static/js/htmx.min.js',
+  '/static/js/offline.js',
+  '/static/js/ranking.js',
+  '/static/js/toasts.js',
+  '/static/fonts/PressStart2P-Regular.woff2',
+  '/static/fonts/IBMPlexMono-Regular.woff2',
+  '/static/fonts/IBMPlexMono-Medium.woff2',
+  '/static/icons/icon.svg',
+  '/static/manifest.json',
+];
+
+self.addEventListener('install', (event) => {
+  event.waitUntil(
+    caches.open(CACHE).then((cache) => cache.addAll(SHELL)).then(() => self.skipWaiting())
+  );
+});
+
+self.addEventListener('activate', (event) => {
+  event.waitUntil(
+    caches.keys()
+      .then((keys) => Promise.all(keys.filter((k) => k !== CACHE).map((k) => caches.delete(k))))
+      .then(() => self.clients.claim())
+  );
+});
+
+self.addEventListener('fetch', (event) => {
+  const req = event.request;
+  const url = new URL(req.url);
+
+  if (req.method !== 'GET' || url.origin !== self.location.origin) {
+    return;
+  }
+  // Admin pages and the API must always reflect the live database
+  if (url.pathname.startsWith('/admin') || url.pathname.startsWith('/api/')) {
+    return;
+  }
+
+  if (url.pathname.startsWith('/static/')) {
+    event.respondWith(caches.match(req).then((hit) => hit || fetch(req)));
+    return;
+  }
+
+  // Only full page loads are cached; htmx fragments share URLs with pages
+  if (req.mode !== 'navigate') {
+    return;
+  }
+
+  event.respondWith(
+    fetch(req)
+      .then((res) => {
+        if (res.ok) {
+          const copy = res.clone();
+          caches.open(CACHE).then((cache) => cache.put(req, copy));
+        }
+        return res;
+      })
+      .catch(() => caches.match(req).then((hit) => hit || caches.match('/')))
+  );
+});
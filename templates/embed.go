@@ -2,5 +2,5 @@ package templates
 
 import "embed"
 
-//go:embed legacy/*.html legacy/admin/*.html modern/*.html modern/admin/*.html modern/partials/*.html
+//go:embed legacy/*.html legacy/admin/*.html modern/*.html modern/admin/*.html modern/partials/*.html site/*.html
 var FS embed.FS